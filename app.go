@@ -8,9 +8,13 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/matt0x6f/irc-client/cascade"
+	"github.com/matt0x6f/irc-client/internal/backup"
+	"github.com/matt0x6f/irc-client/internal/bouncer"
+	"github.com/matt0x6f/irc-client/internal/control"
 	"github.com/matt0x6f/irc-client/internal/dcc"
 	"github.com/matt0x6f/irc-client/internal/events"
 	"github.com/matt0x6f/irc-client/internal/irc"
@@ -47,9 +51,7 @@ type App struct {
 	stsUpgrading           map[int64]bool           // Networks mid-STS-upgrade; suppresses auto-reconnect (by network ID)
 	intentionalDisconnect  map[int64]bool           // Networks the user deliberately disconnected; suppresses auto-reconnect once (by network ID)
 	mu                     sync.RWMutex
-	channelListCache       map[int64]channelListCacheEntry // Cached LIST results keyed by network ID
-	channelListCacheMu     sync.RWMutex                    // Guards channelListCache; separate from mu to avoid contention
-	userMetaEmitMu         sync.Mutex                      // Guards coalesced frontend roster updates below.
+	userMetaEmitMu         sync.Mutex // Guards coalesced frontend roster updates below.
 	userMetaPending        map[string]map[string]interface{}
 	userMetaFlushSet       bool
 	channelRosterEmitMu    sync.Mutex // Guards trailing-edge frontend NAMES completion batches.
@@ -60,10 +62,21 @@ type App struct {
 	startupCancel          context.CancelFunc
 	startupWg              sync.WaitGroup
 	shutdownOnce           sync.Once                      // Ensure shutdown only runs once
+	localCoreOnce          sync.Once                      // Guards startLocalCore
+	remoteCore             string                         // attach URL the main window loaded; "" when running locally
+	control                *control.Server                // local automation socket; nil until runLocalCore, guarded by mu
+	bouncer                atomic.Pointer[bouncer.Server] // listener for other IRC clients; nil when disabled
+	bouncerErr             string                         // why the bouncer last failed to start; guarded by mu
+	backupMu               sync.Mutex                     // serializes manual and scheduled backups
+	backupErr              string                         // why the last scheduled backup failed; guarded by mu
 	emitFn                 func(name string, data ...any) // test seam; nil in production
 	pendingNetworkPrefill  *NetworkPrefill                // deep-link Add Network prefill; consumed by the settings window
 	frontendReady          bool                           // set once the webview drains pending deep links
 	pendingDeepLink        *PendingDeepLink               // cold-start deep link buffered until the webview is ready
+
+	// queuedRegistrations holds RegisterAccount calls waiting for their network
+	// to connect; guarded by mu.
+	queuedRegistrations map[int64]irc.AccountRegistration
 }
 
 // stsTarget is a pending plaintext→TLS upgrade: a host advertised STS over an
@@ -75,13 +88,6 @@ type stsTarget struct {
 	port int
 }
 
-// channelListCacheEntry holds a network's last-fetched LIST result together with
-// the time it was fetched, so the frontend can decide whether it is still fresh.
-type channelListCacheEntry struct {
-	channels  []map[string]interface{}
-	fetchedAt time.Time
-}
-
 // NewApp creates a new App application struct
 func NewApp() (*App, error) {
 	// baseDir is the root for all persistent data (DB, plugins). It defaults to
@@ -102,6 +108,25 @@ func NewApp() (*App, error) {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	// IRC secrets live in an encrypted file store under dataDir rather than the
+	// OS keychain. Release builds are ad-hoc signed, and macOS binds keychain
+	// ACLs to the binary's code signature (cdhash) — so every app update orphaned
+	// the stored SASL password and forced re-entry. The file store depends only
+	// on dataDir, so secrets survive updates. See internal/security/file_backend.go.
+	secretBackend, err := security.NewSecretBackend(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize credential store: %w", err)
+	}
+	creds := security.NewCredentialStore(secretBackend)
+
+	// A restore staged by RestoreBackup replaces the database and assets
+	// before anything opens them; its secrets come back from the file Stage
+	// encrypted under the credential key.
+	restoredSecrets, restored, err := backup.ApplyPending(baseDir, stagedSecretFile(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply staged restore: %w", err)
+	}
+
 	stor, err := storage.NewStorage(dbPath, 100, 5*time.Second)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize storage: %w", err)
@@ -112,16 +137,13 @@ func NewApp() (*App, error) {
 	applyLogConfig(stor, baseDir)
 
 	eventBus := events.NewEventBus()
-	// IRC secrets live in an encrypted file store under dataDir rather than the
-	// OS keychain. Release builds are ad-hoc signed, and macOS binds keychain
-	// ACLs to the binary's code signature (cdhash) — so every app update orphaned
-	// the stored SASL password and forced re-entry. The file store depends only
-	// on dataDir, so secrets survive updates. See internal/security/file_backend.go.
-	secretBackend, err := security.NewSecretBackend(baseDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize credential store: %w", err)
+	if restored {
+		if err := finishRestore(creds, baseDir, restoredSecrets); err != nil {
+			_ = stor.Close()
+			return nil, err
+		}
+		logger.Log.Info().Msg("Restored data directory from backup")
 	}
-	creds := security.NewCredentialStore(secretBackend)
 
 	pluginDir := filepath.Join(baseDir, "plugins")
 	pluginMgr := plugin.NewManager(eventBus, pluginDir)
@@ -143,7 +165,6 @@ func NewApp() (*App, error) {
 		stsUpgrades:           make(map[int64]stsTarget),
 		stsUpgrading:          make(map[int64]bool),
 		intentionalDisconnect: make(map[int64]bool),
-		channelListCache:      make(map[int64]channelListCacheEntry),
 		userMetaPending:       make(map[string]map[string]interface{}),
 		channelRosterPending:  make(map[string]map[string]interface{}),
 	}
@@ -160,7 +181,7 @@ func NewApp() (*App, error) {
 		return client, ok
 	}
 	app.scriptMgr = script.NewManager(eventBus, scriptDir, script.Host{
-		Send: app.SendMessage,
+		Send: app.sendScriptMessage,
 		SelfNick: func(networkID int64) string {
 			nick, _ := app.GetCurrentNick(networkID)
 			return nick
@@ -201,19 +222,29 @@ func NewApp() (*App, error) {
 			}
 			return status
 		},
+		Seen: func(networkID int64, nick string) cascade.Seen {
+			e, err := app.storage.GetSeen(networkID, app.nickKey(networkID, nick))
+			if err != nil || e == nil {
+				return cascade.Seen{}
+			}
+			return cascade.Seen{
+				Known: true, Nick: e.Nick, Action: e.Action, Channel: e.Channel, Target: e.Target,
+				Message: e.Message, Host: e.Host, Account: e.Account, Time: cascade.NewTime(e.SeenAt / 1000),
+			}
+		},
 		Notice: func(networkID int64, target, message string) error {
 			client, ok := scriptClient(networkID)
 			if !ok {
 				return fmt.Errorf("network not connected")
 			}
-			return client.SendNotice(target, message)
+			return client.SendAutomatedNotice(target, message)
 		},
 		Action: func(networkID int64, target, message string) error {
 			client, ok := scriptClient(networkID)
 			if !ok {
 				return fmt.Errorf("network not connected")
 			}
-			return client.SendAction(target, message)
+			return client.SendAutomatedAction(target, message)
 		},
 		Join: func(networkID int64, channel, key string) error {
 			client, ok := scriptClient(networkID)
@@ -312,6 +343,30 @@ func (a *App) ServiceStartup(ctx context.Context, _ application.ServiceOptions)
 		a.notifier.SetFocused(false)
 	})
 
+	// Poll for self-updates in the background (no-op on dev builds where the
+	// updater was never configured). Surfaces the updater window only when a
+	// newer release is found — see startPeriodicUpdateCheck.
+	a.startPeriodicUpdateCheck()
+
+	// With a remote core configured, the core owns the IRC connections, scripts
+	// and plugins; this process only hosts the window attached to it. The local
+	// side starts if the user detaches (see detachRemoteCore).
+	if a.remoteCoreConfigured() {
+		logger.Log.Info().Msg("Remote core configured; not starting local networks")
+		return nil
+	}
+	a.startLocalCore()
+	return nil
+}
+
+// startLocalCore starts everything that makes this process a core: wake and
+// deep-link handling, plugins, scripts, auto-connect and the background
+// sweepers. It runs at most once.
+func (a *App) startLocalCore() {
+	a.localCoreOnce.Do(a.runLocalCore)
+}
+
+func (a *App) runLocalCore() {
 	// On system wake, sockets are usually dead but not yet detected by the library
 	// ping loop; force every auto-connect network to reconnect so the UI recovers
 	// promptly instead of waiting up to KeepAlive+Timeout. This callback runs off
@@ -365,12 +420,23 @@ func (a *App) ServiceStartup(ctx context.Context, _ application.ServiceOptions)
 	// Sweep expired invite TTLs every 5 minutes so badges clear automatically.
 	a.startInviteSweeper()
 
-	// Poll for self-updates in the background (no-op on dev builds where the
-	// updater was never configured). Surfaces the updater window only when a
-	// newer release is found — see startPeriodicUpdateCheck.
-	a.startPeriodicUpdateCheck()
+	// Carry out scheduled ban/exception/invex removals ("unban in 2h").
+	a.startChannelListExpiry()
 
-	return nil
+	// Send messages queued with /later.
+	a.startScheduledMessages()
+
+	// Local automation API for cascade-cli and shell scripts.
+	a.startControlSocket()
+
+	// Listener for other IRC clients, when enabled in settings.
+	_ = a.startBouncer()
+
+	// Scheduled backups, when enabled in settings.
+	a.startBackupScheduler()
+
+	// Channel statistics aggregates, kept up to date with the message log.
+	a.startChannelStatsRefresher()
 }
 
 // ServiceShutdown is the v3 service lifecycle hook, replacing v2's OnShutdown.
@@ -387,6 +453,8 @@ func (a *App) ServiceShutdown() error {
 		if a.startupCancel != nil {
 			a.startupCancel()
 		}
+		a.stopControlSocket()
+		a.stopBouncer()
 
 		// Wait for startup goroutines to finish
 		logger.Log.Debug().Msg("Waiting for startup goroutines to finish")
@@ -604,6 +672,22 @@ func (a *App) GetMessageByMsgID(networkID int64, msgid string) (storage.Message,
 	return a.storage.GetMessageByMsgID(networkID, msgid)
 }
 
+// GetAccountMessages retrieves up to `limit` of the latest messages sent while
+// logged in as a services account, whatever nick the sender used, in
+// chronological order.
+func (a *App) GetAccountMessages(networkID int64, account string, limit int) ([]storage.Message, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+	return a.storage.GetAccountMessages(networkID, account, limit)
+}
+
+// GetAccountNicks lists the nicks a services account has sent messages under
+// on a network, most recently used first.
+func (a *App) GetAccountNicks(networkID int64, account string) ([]storage.AccountNick, error) {
+	return a.storage.GetAccountNicks(networkID, account)
+}
+
 // GetSetting returns a persisted UI/app preference by key. A missing key
 // returns an empty string (not an error) so the frontend can apply its default.
 // Used for preferences like theme mode and accent that can't live in the
@@ -1180,7 +1264,7 @@ func (a *App) SetPrivateMessageOpen(networkID int64, targetUser string, isOpen b
 	a.mu.RLock()
 	client, exists := a.ircClients[networkID]
 	a.mu.RUnlock()
-	if exists {
+	if _, direct := directChatPeer(targetUser); exists && !direct {
 		client.MonitorReconcileNick(targetUser)
 	}
 
@@ -1481,75 +1565,6 @@ func (a *App) SearchMessages(query string, networkID *int64, limit int) ([]stora
 	return a.storage.SearchMessages(query, networkID, limit)
 }
 
-// ChannelListCacheResult is the cached LIST result returned to the frontend.
-// FetchedAt is unix milliseconds; Found is false when no cache exists for the network.
-type ChannelListCacheResult struct {
-	Channels  []map[string]interface{} `json:"channels"`
-	FetchedAt int64                    `json:"fetchedAt"`
-	Found     bool                     `json:"found"`
-}
-
-// GetCachedChannelList returns the last-fetched LIST result for a network, if any.
-// It never triggers a fetch — callers use RequestChannelList to force a refresh.
-func (a *App) GetCachedChannelList(networkID int64) ChannelListCacheResult {
-	a.channelListCacheMu.RLock()
-	defer a.channelListCacheMu.RUnlock()
-
-	entry, ok := a.channelListCache[networkID]
-	if !ok {
-		return ChannelListCacheResult{Found: false}
-	}
-	return ChannelListCacheResult{
-		Channels:  entry.channels,
-		FetchedAt: entry.fetchedAt.UnixMilli(),
-		Found:     true,
-	}
-}
-
-// channelsFromEventData normalizes the channel slice carried on a LISTEND event
-// (a []interface{} of map[string]interface{}) into the typed form the cache stores.
-func channelsFromEventData(raw interface{}) []map[string]interface{} {
-	items, ok := raw.([]interface{})
-	if !ok {
-		return nil
-	}
-	channels := make([]map[string]interface{}, 0, len(items))
-	for _, it := range items {
-		if m, ok := it.(map[string]interface{}); ok {
-			channels = append(channels, m)
-		}
-	}
-	return channels
-}
-
-// cacheChannelList stores a freshly-fetched LIST result for a network, stamped with
-// the current time. Called when a LISTEND event completes.
-func (a *App) cacheChannelList(networkID int64, channels []map[string]interface{}) {
-	a.channelListCacheMu.Lock()
-	defer a.channelListCacheMu.Unlock()
-	a.channelListCache[networkID] = channelListCacheEntry{
-		channels:  channels,
-		fetchedAt: time.Now(),
-	}
-}
-
-// RequestChannelList sends the LIST command to request available channels from the server
-func (a *App) RequestChannelList(networkID int64) error {
-	a.mu.RLock()
-	client, exists := a.ircClients[networkID]
-	a.mu.RUnlock()
-
-	if !exists {
-		return fmt.Errorf("network not connected")
-	}
-
-	if !client.IsConnected() {
-		return fmt.Errorf("network not connected")
-	}
-
-	return client.SendRawCommand("LIST")
-}
-
 // Greet returns a greeting for the given name (kept for compatibility)
 func (a *App) Greet(name string) string {
 	return fmt.Sprintf("Hello %s, It's show time!", name)
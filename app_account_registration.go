@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/logger"
+)
+
+// GetAccountRegistrationSupport reports what the network's server allows for
+// draft/account-registration. It is the zero value (unsupported) until the
+// network has connected this session.
+func (a *App) GetAccountRegistrationSupport(networkID int64) (irc.AccountRegistrationSupport, error) {
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if !exists {
+		return irc.AccountRegistrationSupport{}, nil
+	}
+	return client.AccountRegistrationSupport(), nil
+}
+
+// RegisterAccount creates an account on the network with REGISTER. An empty
+// account registers the current nick. On a live connection it is sent now;
+// otherwise it is queued and the network connected, so a server that allows
+// before-connect gets it during the handshake. The outcome arrives as the
+// "account-registration" event, and a registered account is saved as the
+// network's SASL PLAIN login.
+func (a *App) RegisterAccount(networkID int64, account, email, password string) error {
+	reg := irc.AccountRegistration{
+		Account:  strings.TrimSpace(account),
+		Email:    strings.TrimSpace(email),
+		Password: password,
+	}
+	if strings.ContainsAny(reg.Account+reg.Email+reg.Password, " \r\n") {
+		return fmt.Errorf("the account, email and password must not contain spaces or line breaks")
+	}
+
+	a.mu.Lock()
+	client, exists := a.ircClients[networkID]
+	if !exists || !client.IsConnected() {
+		if a.queuedRegistrations == nil {
+			a.queuedRegistrations = make(map[int64]irc.AccountRegistration)
+		}
+		a.queuedRegistrations[networkID] = reg
+	}
+	a.mu.Unlock()
+	if exists && client.IsConnected() {
+		return client.RegisterAccount(reg)
+	}
+	return a.ConnectSavedNetwork(networkID)
+}
+
+// VerifyAccount sends the verification code the server asked for after
+// RegisterAccount.
+func (a *App) VerifyAccount(networkID int64, code string) error {
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("network %d is not connected", networkID)
+	}
+	return client.VerifyAccount(code)
+}
+
+// takeQueuedRegistration hands connectNetwork the registration RegisterAccount
+// queued for the network, if any.
+func (a *App) takeQueuedRegistration(networkID int64) (irc.AccountRegistration, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	reg, ok := a.queuedRegistrations[networkID]
+	delete(a.queuedRegistrations, networkID)
+	return reg, ok
+}
+
+// saveRegisteredAccount makes a newly registered account the network's SASL
+// PLAIN login, with the password in the credential store, so the next
+// connection logs in without services.
+func (a *App) saveRegisteredAccount(networkID int64, account, password string) error {
+	n, err := a.storage.GetNetwork(networkID)
+	if err != nil {
+		return fmt.Errorf("save registered account: %w", err)
+	}
+	n.SASLEnabled = true
+	n.SASLMechanism = stringPtr("PLAIN")
+	n.SASLUsername = stringPtr(account)
+	if err := a.secureNetworkSecrets(n, "", password); err != nil {
+		return fmt.Errorf("save registered account: %w", err)
+	}
+	logger.Log.Info().Int64("network_id", networkID).Str("account", account).Msg("Saved registered account as SASL login")
+	return nil
+}
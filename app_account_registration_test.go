@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/security"
+)
+
+func TestRegisteredAccountBecomesSASLPlainLogin(t *testing.T) {
+	a := newCredsTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "RegisterNet")
+
+	if err := a.saveRegisteredAccount(net.ID, "robodan", "hunter2"); err != nil {
+		t.Fatalf("saveRegisteredAccount: %v", err)
+	}
+	got, err := a.storage.GetNetwork(net.ID)
+	if err != nil {
+		t.Fatalf("GetNetwork: %v", err)
+	}
+	if !got.SASLEnabled || derefStr(got.SASLMechanism) != "PLAIN" || derefStr(got.SASLUsername) != "robodan" {
+		t.Fatalf("SASL settings = %v %q %q", got.SASLEnabled, derefStr(got.SASLMechanism), derefStr(got.SASLUsername))
+	}
+	if got.SASLPassword != nil {
+		t.Errorf("the password was left in the database column: %q", *got.SASLPassword)
+	}
+	if pw := a.creds.Resolve(net.ID, security.FieldSASLPassword, ""); pw != "hunter2" {
+		t.Errorf("credential store SASL password = %q", pw)
+	}
+}
+
+func TestRegisterAccountQueuesUntilConnected(t *testing.T) {
+	a := newCredsTestApp(t)
+	if err := a.RegisterAccount(1, "robo dan", "", "hunter2"); err == nil {
+		t.Error("RegisterAccount accepted an account with a space")
+	}
+	// Network 999 doesn't exist, so connecting fails, but the registration
+	// stays queued for the next connection.
+	if err := a.RegisterAccount(999, " robodan ", "", "hunter2"); err == nil {
+		t.Error("RegisterAccount connected a missing network")
+	}
+	reg, ok := a.takeQueuedRegistration(999)
+	if !ok || reg != (irc.AccountRegistration{Account: "robodan", Password: "hunter2"}) {
+		t.Fatalf("queued = %+v, %v", reg, ok)
+	}
+	if _, ok := a.takeQueuedRegistration(999); ok {
+		t.Error("the registration was handed out twice")
+	}
+}
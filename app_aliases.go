@@ -0,0 +1,346 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// maxAliasDepth bounds how many aliases one typed command may pass through.
+// Cycles are caught directly; the bound stops long chains of distinct aliases.
+const maxAliasDepth = 8
+
+// AliasDefinition is a user-defined command as the settings UI edits it.
+type AliasDefinition struct {
+	NetworkID int64  `json:"networkId"` // 0 for an alias available on every network
+	Name      string `json:"name"`      // without the slash
+	Expansion string `json:"expansion"` // commands separated by ';', e.g. "/me slaps $1 with a trout"
+}
+
+// GetCommandAliases returns every alias, global ones first.
+func (a *App) GetCommandAliases() ([]AliasDefinition, error) {
+	aliases, err := a.storage.ListCommandAliases()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]AliasDefinition, len(aliases))
+	for i, alias := range aliases {
+		out[i] = AliasDefinition{Name: alias.Name, Expansion: alias.Expansion}
+		if alias.NetworkID != nil {
+			out[i].NetworkID = *alias.NetworkID
+		}
+	}
+	return out, nil
+}
+
+// SaveCommandAlias creates or replaces an alias. Built-in command names are
+// refused; a network alias overrides a global alias of the same name.
+func (a *App) SaveCommandAlias(alias AliasDefinition) error {
+	name := strings.TrimPrefix(strings.TrimSpace(alias.Name), "/")
+	if name == "" || strings.ContainsAny(name, " \t/$;") {
+		return fmt.Errorf("alias names are a single word without '/', '$' or ';'")
+	}
+	if _, ok := a.commands.Lookup(name); ok {
+		return fmt.Errorf("/%s is a built-in command", strings.ToLower(name))
+	}
+	record := storage.CommandAlias{Name: name, Expansion: strings.TrimSpace(alias.Expansion)}
+	if alias.NetworkID != 0 {
+		record.NetworkID = &alias.NetworkID
+	}
+	if err := a.storage.SaveCommandAlias(record); err != nil {
+		return err
+	}
+	a.emit("aliases:changed")
+	return nil
+}
+
+// DeleteCommandAlias removes an alias; networkID 0 names the global one.
+func (a *App) DeleteCommandAlias(networkID int64, name string) error {
+	var scope *int64
+	if networkID != 0 {
+		scope = &networkID
+	}
+	if err := a.storage.DeleteCommandAlias(scope, strings.TrimPrefix(name, "/")); err != nil {
+		return err
+	}
+	a.emit("aliases:changed")
+	return nil
+}
+
+// cmdAlias lists the aliases this network sees, shows one, or defines one.
+func cmdAlias(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	scope, args := aliasScopeFlag(networkID, args)
+	switch len(args) {
+	case 0:
+		aliases, err := a.GetCommandAliases()
+		if err != nil {
+			return err
+		}
+		var lines []string
+		for _, alias := range aliases {
+			if alias.NetworkID != 0 && alias.NetworkID != networkID {
+				continue
+			}
+			lines = append(lines, formatAlias(alias))
+		}
+		if len(lines) == 0 {
+			lines = []string{"No aliases defined. Use /alias name expansion to add one."}
+		}
+		return a.PrintLocalLines(networkID, buffer, lines)
+	case 1:
+		alias, ok := a.lookupAlias(networkID, args[0])
+		if !ok {
+			return fmt.Errorf("no alias named /%s", strings.ToLower(strings.TrimPrefix(args[0], "/")))
+		}
+		info := AliasDefinition{Name: alias.Name, Expansion: alias.Expansion}
+		if alias.NetworkID != nil {
+			info.NetworkID = *alias.NetworkID
+		}
+		return a.PrintLocalLines(networkID, buffer, []string{formatAlias(info)})
+	}
+	return a.SaveCommandAlias(AliasDefinition{NetworkID: scope, Name: args[0], Expansion: strings.Join(args[1:], " ")})
+}
+
+func cmdUnalias(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	scope, args := aliasScopeFlag(networkID, args)
+	if len(args) != 1 {
+		return fmt.Errorf("usage: /unalias [-network] name")
+	}
+	return a.DeleteCommandAlias(scope, args[0])
+}
+
+// aliasScopeFlag strips a leading -network flag, returning the scope it
+// selects: the current network with the flag, global (0) without.
+func aliasScopeFlag(networkID int64, args []string) (int64, []string) {
+	if len(args) > 0 && strings.EqualFold(args[0], "-network") {
+		return networkID, args[1:]
+	}
+	return 0, args
+}
+
+func formatAlias(alias AliasDefinition) string {
+	line := fmt.Sprintf("/%s → %s", strings.ToLower(alias.Name), alias.Expansion)
+	if alias.NetworkID != 0 {
+		line += " (this network)"
+	}
+	return line
+}
+
+// aliasCommandInfos lists aliases for GetCommands, one entry per name. The
+// description is the expansion; a name defined only for some networks says so.
+func (a *App) aliasCommandInfos() []CommandInfo {
+	if a.storage == nil {
+		return nil
+	}
+	aliases, err := a.storage.ListCommandAliases()
+	if err != nil {
+		return nil
+	}
+	var out []CommandInfo
+	seen := make(map[string]bool)
+	for _, alias := range aliases {
+		if seen[alias.Name] {
+			continue
+		}
+		seen[alias.Name] = true
+		description := alias.Expansion
+		if alias.NetworkID != nil {
+			if network, err := a.storage.GetNetwork(*alias.NetworkID); err == nil {
+				description += " (" + network.Name + " only)"
+			}
+		}
+		out = append(out, CommandInfo{
+			Name: alias.Name, Aliases: []string{}, Category: string(CategoryAlias),
+			Description: description, Source: "alias",
+		})
+	}
+	return out
+}
+
+// lookupAlias finds the alias a network sees under name: its own, else the
+// global one.
+func (a *App) lookupAlias(networkID int64, name string) (storage.CommandAlias, bool) {
+	if a.storage == nil {
+		return storage.CommandAlias{}, false
+	}
+	aliases, err := a.storage.ListCommandAliases()
+	if err != nil {
+		return storage.CommandAlias{}, false
+	}
+	var global *storage.CommandAlias
+	for i, alias := range aliases {
+		if !strings.EqualFold(alias.Name, name) {
+			continue
+		}
+		if alias.NetworkID == nil {
+			global = &aliases[i]
+		} else if *alias.NetworkID == networkID {
+			return alias, true
+		}
+	}
+	if global != nil {
+		return *global, true
+	}
+	return storage.CommandAlias{}, false
+}
+
+// runAlias expands alias for one invocation and runs the resulting commands in
+// order, stopping at the first error. stack holds the aliases already being
+// expanded, so an alias that reaches itself again fails instead of looping.
+func (a *App) runAlias(client *irc.IRCClient, networkID int64, buffer string, alias storage.CommandAlias, args, stack []string) error {
+	if slices.Contains(stack, alias.Name) {
+		return fmt.Errorf("alias /%s calls itself", strings.ToLower(alias.Name))
+	}
+	if len(stack) >= maxAliasDepth {
+		return fmt.Errorf("aliases nested more than %d deep", maxAliasDepth)
+	}
+	stack = append(stack[:len(stack):len(stack)], alias.Name)
+
+	for _, line := range expandAlias(alias.Expansion, args, a.aliasVariables(client, networkID, buffer)) {
+		if !strings.HasPrefix(line, "/") {
+			if err := a.sendAliasText(client, networkID, buffer, line); err != nil {
+				return err
+			}
+			continue
+		}
+		parts := strings.Fields(line[1:])
+		if len(parts) == 0 {
+			continue
+		}
+		cmdArgs := aliasPaneArgs(client, parts[0], buffer, parts[1:])
+		if err := a.runCommand(client, networkID, buffer, parts[0], cmdArgs, line[1:], stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aliasVariables are the named $variables an expansion may use.
+func (a *App) aliasVariables(client *irc.IRCClient, networkID int64, buffer string) map[string]string {
+	vars := map[string]string{"chan": "", "me": "", "nick": "", "network": "", "target": ""}
+	if buffer != "status" {
+		vars["target"] = buffer
+	}
+	if client != nil {
+		vars["nick"] = client.CurrentNick()
+		vars["me"] = vars["nick"]
+		if client.IsChannelName(buffer) {
+			vars["chan"] = buffer
+		}
+	}
+	if a.storage != nil {
+		if network, err := a.storage.GetNetwork(networkID); err == nil {
+			vars["network"] = network.Name
+		}
+	}
+	return vars
+}
+
+// aliasPaneArgs gives commands from an expansion the same pane defaults the
+// input box applies to typed ones: /me acts in the current pane and /part
+// leaves the current channel when none is named.
+func aliasPaneArgs(client *irc.IRCClient, name, buffer string, args []string) []string {
+	if buffer == "status" {
+		return args
+	}
+	switch strings.ToUpper(name) {
+	case "ME", "ACTION":
+		return append([]string{buffer}, args...)
+	case "PART", "LEAVE":
+		if client != nil && client.IsChannelName(buffer) && (len(args) == 0 || !client.IsChannelName(args[0])) {
+			return append([]string{buffer}, args...)
+		}
+	}
+	return args
+}
+
+// sendAliasText sends an expansion line without a leading slash to the pane,
+// as if it had been typed there.
+func (a *App) sendAliasText(client *irc.IRCClient, networkID int64, buffer, text string) error {
+	if buffer == "status" {
+		return fmt.Errorf("alias text %q needs a channel or query to be sent to", text)
+	}
+	if peer, ok := directChatPeer(buffer); ok {
+		return a.sendDirectChat(networkID, peer, text, false)
+	}
+	return client.SendMessage(buffer, text)
+}
+
+// expandAlias substitutes an invocation's arguments and variables into an
+// alias body and splits it into commands. $1..$n are single arguments, $n- is
+// argument n onwards and $* is every argument; $name looks up vars. A missing
+// argument expands to nothing. ';' separates commands, "\;" is a literal
+// semicolon and "$$" a literal dollar sign.
+func expandAlias(body string, args []string, vars map[string]string) []string {
+	var commands []string
+	var cur strings.Builder
+	flush := func() {
+		if line := strings.TrimSpace(cur.String()); line != "" {
+			commands = append(commands, line)
+		}
+		cur.Reset()
+	}
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '\\' && i+1 < len(body) && body[i+1] == ';':
+			cur.WriteByte(';')
+			i++
+		case c == ';':
+			flush()
+		case c == '$' && i+1 < len(body):
+			if n := expandAliasVariable(body[i+1:], args, vars, &cur); n > 0 {
+				i += n
+			} else {
+				cur.WriteByte('$')
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return commands
+}
+
+// expandAliasVariable writes the value of the variable at the start of s (the
+// text after a '$') and reports how many bytes it used, or 0 when s does not
+// start a variable.
+func expandAliasVariable(s string, args []string, vars map[string]string, out *strings.Builder) int {
+	switch {
+	case s[0] == '$':
+		out.WriteByte('$')
+		return 1
+	case s[0] == '*':
+		out.WriteString(strings.Join(args, " "))
+		return 1
+	case s[0] >= '1' && s[0] <= '9':
+		end := 1
+		for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+			end++
+		}
+		n, _ := strconv.Atoi(s[:end])
+		if end < len(s) && s[end] == '-' {
+			if n <= len(args) {
+				out.WriteString(strings.Join(args[n-1:], " "))
+			}
+			return end + 1
+		}
+		if n <= len(args) {
+			out.WriteString(args[n-1])
+		}
+		return end
+	}
+	end := 0
+	for end < len(s) && (s[end] >= 'a' && s[end] <= 'z' || s[end] >= 'A' && s[end] <= 'Z') {
+		end++
+	}
+	if value, ok := vars[strings.ToLower(s[:end])]; ok && end > 0 {
+		out.WriteString(value)
+		return end
+	}
+	return 0
+}
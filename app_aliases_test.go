@@ -0,0 +1,89 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandAlias(t *testing.T) {
+	vars := map[string]string{"nick": "me", "chan": "#go", "network": "Libera", "target": "#go"}
+	cases := []struct {
+		body string
+		args []string
+		want []string
+	}{
+		{"/me slaps $1 with a trout", []string{"bob", "extra"}, []string{"/me slaps bob with a trout"}},
+		{"/msg NickServ $*", []string{"identify", "secret"}, []string{"/msg NickServ identify secret"}},
+		{"/kick $chan $1 $2-", []string{"troll", "go", "away"}, []string{"/kick #go troll go away"}},
+		{"/kick $chan $1 $2-", []string{"troll"}, []string{"/kick #go troll"}},
+		{"/msg $1 hi from $nick on $network; /join $2", []string{"bob", "#a"}, []string{"/msg bob hi from me on Libera", "/join #a"}},
+		{"hello\\; world;;  ", nil, []string{"hello; world"}},
+		{"costs $$5, $unknown stays", nil, []string{"costs $5, $unknown stays"}},
+		{"$10 $1", []string{"a"}, []string{"a"}},
+	}
+	for _, c := range cases {
+		if got := expandAlias(c.body, c.args, vars); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("expandAlias(%q, %q) = %q, want %q", c.body, c.args, got, c.want)
+		}
+	}
+}
+
+func TestAliasLookupPrefersNetworkScope(t *testing.T) {
+	a := newTestApp(t)
+	a.commands = buildBuiltinRegistry()
+	a.emitFn = func(string, ...any) {}
+	net := makeAppTestNetwork(t, a.storage, "AliasApp")
+
+	if err := a.SaveCommandAlias(AliasDefinition{Name: "/ns", Expansion: "/msg NickServ $*"}); err != nil {
+		t.Fatalf("SaveCommandAlias(global): %v", err)
+	}
+	if err := a.SaveCommandAlias(AliasDefinition{NetworkID: net.ID, Name: "ns", Expansion: "/msg NS $*"}); err != nil {
+		t.Fatalf("SaveCommandAlias(network): %v", err)
+	}
+	if alias, ok := a.lookupAlias(net.ID, "NS"); !ok || alias.Expansion != "/msg NS $*" {
+		t.Errorf("lookup on the network = %+v, %v; want the network alias", alias, ok)
+	}
+	if alias, ok := a.lookupAlias(net.ID+1, "ns"); !ok || alias.Expansion != "/msg NickServ $*" {
+		t.Errorf("lookup elsewhere = %+v, %v; want the global alias", alias, ok)
+	}
+
+	var infos []CommandInfo
+	for _, c := range a.GetCommands() {
+		if c.Source == "alias" {
+			infos = append(infos, c)
+		}
+	}
+	if len(infos) != 1 || infos[0].Name != "NS" || infos[0].Category != string(CategoryAlias) {
+		t.Errorf("alias commands = %+v, want one NS entry", infos)
+	}
+
+	if err := a.SaveCommandAlias(AliasDefinition{Name: "join", Expansion: "/quote JOIN $1"}); err == nil {
+		t.Error("SaveCommandAlias accepted a built-in command name")
+	}
+}
+
+func TestAliasLoopIsRefused(t *testing.T) {
+	a := newTestApp(t)
+	a.commands = buildBuiltinRegistry()
+	a.emitFn = func(string, ...any) {}
+	for name, expansion := range map[string]string{"ping1": "/ping2", "ping2": "/ping1 $*"} {
+		if err := a.SaveCommandAlias(AliasDefinition{Name: name, Expansion: expansion}); err != nil {
+			t.Fatalf("SaveCommandAlias: %v", err)
+		}
+	}
+	err := a.dispatchCommand(nil, 1, "status", "ping1", nil, "ping1")
+	if err == nil || !strings.Contains(err.Error(), "calls itself") {
+		t.Fatalf("dispatch of a looping alias = %v, want a loop error", err)
+	}
+}
+
+func TestAliasPaneDefaults(t *testing.T) {
+	got := aliasPaneArgs(nil, "me", "#go", []string{"waves"})
+	if !reflect.DeepEqual(got, []string{"#go", "waves"}) {
+		t.Errorf("/me in a channel = %q", got)
+	}
+	if got := aliasPaneArgs(nil, "me", "status", []string{"bob", "waves"}); !reflect.DeepEqual(got, []string{"bob", "waves"}) {
+		t.Errorf("/me in status = %q", got)
+	}
+}
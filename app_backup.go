@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/backup"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/security"
+)
+
+const (
+	// settingBackup holds the scheduled backup configuration as JSON.
+	settingBackup = "backup.schedule"
+	// backupPassphraseSecret names the scheduled backups' passphrase in the
+	// credential store.
+	backupPassphraseSecret = "backup-passphrase"
+
+	defaultBackupIntervalHours = 24
+	defaultBackupKeep          = 7
+	backupCheckInterval        = 15 * time.Minute
+)
+
+// BackupSettings configures scheduled backups.
+type BackupSettings struct {
+	Enabled       bool   `json:"enabled"`
+	Directory     string `json:"directory"`     // where backups are written; <data dir>/backups when empty
+	IntervalHours int    `json:"intervalHours"` // time between scheduled backups
+	Keep          int    `json:"keep"`          // scheduled backups kept; older ones are deleted
+	HasPassphrase bool   `json:"hasPassphrase"` // a passphrase is stored (it is never returned)
+}
+
+// BackupInfo describes a backup that was written or staged for restore.
+// createdAt is an RFC 3339 string rather than time.Time, which the binding
+// generator does not model.
+type BackupInfo struct {
+	Path          string `json:"path"`
+	CreatedAt     string `json:"createdAt"`
+	AppVersion    string `json:"appVersion"`
+	SchemaVersion int    `json:"schemaVersion"`
+}
+
+// BackupStatus reports the newest backup and whether a restore is waiting for
+// a restart.
+type BackupStatus struct {
+	Directory      string `json:"directory"`
+	LastBackup     string `json:"lastBackup"` // RFC 3339; "" when there is none
+	LastError      string `json:"lastError"`  // why the last scheduled backup failed, if it did
+	RestorePending bool   `json:"restorePending"`
+}
+
+// backupSettings loads the schedule; unreadable settings leave it disabled.
+func (a *App) backupSettings() BackupSettings {
+	var settings BackupSettings
+	if value, _ := a.storage.GetSetting(settingBackup); strings.TrimSpace(value) != "" {
+		if err := json.Unmarshal([]byte(value), &settings); err != nil {
+			logger.Log.Warn().Err(err).Msg("Ignoring unreadable backup settings")
+			settings = BackupSettings{}
+		}
+	}
+	if settings.IntervalHours <= 0 {
+		settings.IntervalHours = defaultBackupIntervalHours
+	}
+	if settings.Keep <= 0 {
+		settings.Keep = defaultBackupKeep
+	}
+	settings.HasPassphrase = a.creds.AppSecret(backupPassphraseSecret) != ""
+	return settings
+}
+
+func (a *App) GetBackupSettings() BackupSettings { return a.backupSettings() }
+
+// UpdateBackupSettings stores the schedule. An empty passphrase keeps the
+// stored one; scheduled backups need one.
+func (a *App) UpdateBackupSettings(settings BackupSettings, passphrase string) error {
+	settings.Directory = strings.TrimSpace(settings.Directory)
+	settings.HasPassphrase = false
+	if settings.IntervalHours <= 0 {
+		settings.IntervalHours = defaultBackupIntervalHours
+	}
+	if settings.Keep <= 0 {
+		settings.Keep = defaultBackupKeep
+	}
+	if settings.Directory != "" && !filepath.IsAbs(settings.Directory) {
+		return fmt.Errorf("backup directory must be an absolute path")
+	}
+
+	if passphrase == "" {
+		passphrase = a.creds.AppSecret(backupPassphraseSecret)
+	}
+	if settings.Enabled && passphrase == "" {
+		return fmt.Errorf("scheduled backups need a passphrase")
+	}
+	if passphrase != a.creds.AppSecret(backupPassphraseSecret) {
+		if err := a.creds.StoreAppSecret(backupPassphraseSecret, passphrase); err != nil {
+			return fmt.Errorf("store backup passphrase: %w", err)
+		}
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	if err := a.storage.SetSetting(settingBackup, string(encoded)); err != nil {
+		return err
+	}
+	a.emit("setting:changed", map[string]string{"key": settingBackup, "value": string(encoded)})
+	return nil
+}
+
+func (a *App) backupDirectory(settings BackupSettings) string {
+	if settings.Directory != "" {
+		return settings.Directory
+	}
+	return filepath.Join(a.dataDir, backup.Dir)
+}
+
+// GetBackupStatus reports the newest backup in the backup directory.
+func (a *App) GetBackupStatus() BackupStatus {
+	dir := a.backupDirectory(a.backupSettings())
+	status := BackupStatus{Directory: dir, RestorePending: backup.HasPending(a.dataDir)}
+	if t, ok := backup.Latest(dir); ok {
+		status.LastBackup = t.Format(time.RFC3339)
+	}
+	a.mu.RLock()
+	status.LastError = a.backupErr
+	a.mu.RUnlock()
+	return status
+}
+
+// CreateBackup writes an encrypted backup of the data directory while the app
+// keeps running. An empty path writes a new file in the backup directory; an
+// empty passphrase uses the stored one.
+func (a *App) CreateBackup(path, passphrase string) (BackupInfo, error) {
+	if passphrase == "" {
+		passphrase = a.creds.AppSecret(backupPassphraseSecret)
+	}
+	if passphrase == "" {
+		return BackupInfo{}, fmt.Errorf("a backup passphrase is required")
+	}
+	path = strings.TrimSpace(path)
+	if path == "" {
+		path = filepath.Join(a.backupDirectory(a.backupSettings()), backup.FileName(time.Now()))
+	}
+
+	a.backupMu.Lock()
+	defer a.backupMu.Unlock()
+	secrets, err := a.creds.Entries()
+	if err != nil {
+		return BackupInfo{}, fmt.Errorf("read credentials: %w", err)
+	}
+	manifest, err := backup.CreateFile(path, backup.Source{
+		DataDir:    a.dataDir,
+		Storage:    a.storage,
+		Secrets:    secrets,
+		AppVersion: version,
+	}, passphrase)
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	logger.Log.Info().Str("path", path).Msg("Backup written")
+	return backupInfo(path, manifest), nil
+}
+
+// RestoreBackup checks and stages the backup at path. It replaces the current
+// data the next time Cascade starts; the replaced files are kept in the
+// backups directory.
+func (a *App) RestoreBackup(path, passphrase string) (BackupInfo, error) {
+	f, err := os.Open(strings.TrimSpace(path))
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	defer f.Close()
+	manifest, err := backup.Stage(f, passphrase, a.dataDir, stagedSecretFile(a.creds))
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	logger.Log.Info().Str("path", path).Msg("Restore staged; it is applied at the next start")
+	return backupInfo(path, manifest), nil
+}
+
+// CancelRestore discards a staged restore.
+func (a *App) CancelRestore() error {
+	return backup.FinishPending(a.dataDir)
+}
+
+func backupInfo(path string, m backup.Manifest) BackupInfo {
+	return BackupInfo{
+		Path:          path,
+		CreatedAt:     m.CreatedAt.Format(time.RFC3339),
+		AppVersion:    m.AppVersion,
+		SchemaVersion: m.SchemaVersion,
+	}
+}
+
+// stagedSecretFile opens the file a staged restore keeps its secrets in,
+// encrypted under this machine's credential key.
+func stagedSecretFile(creds *security.CredentialStore) backup.SecretOpener {
+	return func(path string) (backup.SecretFile, error) {
+		f, err := creds.FileAt(path)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+}
+
+// finishRestore writes a restored backup's secrets into this machine's
+// credential store, re-encrypting them under its own key, and clears the
+// staged restore.
+func finishRestore(creds *security.CredentialStore, dataDir string, secrets map[string]string) error {
+	if err := creds.ReplaceEntries(secrets); err != nil {
+		return fmt.Errorf("restore credentials: %w", err)
+	}
+	return backup.FinishPending(dataDir)
+}
+
+// startBackupScheduler takes a backup whenever the newest one in the backup
+// directory is older than the configured interval, then prunes old ones.
+func (a *App) startBackupScheduler() {
+	a.startupWg.Add(1)
+	go func() {
+		defer a.startupWg.Done()
+		ticker := time.NewTicker(backupCheckInterval)
+		defer ticker.Stop()
+		for {
+			a.runScheduledBackup()
+			select {
+			case <-a.startupCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (a *App) runScheduledBackup() {
+	settings := a.backupSettings()
+	if !settings.Enabled {
+		return
+	}
+	dir := a.backupDirectory(settings)
+	if last, ok := backup.Latest(dir); ok && time.Since(last) < time.Duration(settings.IntervalHours)*time.Hour {
+		return
+	}
+	passphrase := a.creds.AppSecret(backupPassphraseSecret)
+	var err error
+	if passphrase == "" {
+		err = errors.New("no backup passphrase is stored")
+	} else if _, err = a.CreateBackup(filepath.Join(dir, backup.FileName(time.Now())), passphrase); err == nil {
+		err = backup.Rotate(dir, settings.Keep)
+	}
+
+	a.mu.Lock()
+	a.backupErr = ""
+	if err != nil {
+		a.backupErr = err.Error()
+	}
+	a.mu.Unlock()
+	if err != nil {
+		logger.Log.Warn().Err(err).Msg("Scheduled backup failed")
+	}
+}
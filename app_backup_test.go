@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/backup"
+	"github.com/matt0x6f/irc-client/internal/events"
+	"github.com/matt0x6f/irc-client/internal/security"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// newBackupTestApp is an App over a real data directory with a file-backed
+// credential store under key.
+func newBackupTestApp(t *testing.T, key []byte) *App {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := storage.NewStorage(filepath.Join(dir, backup.DatabaseFile), 100, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return &App{
+		dataDir:  dir,
+		storage:  s,
+		eventBus: events.NewEventBus(),
+		creds:    security.NewCredentialStore(security.NewFileBackendWithKey(filepath.Join(dir, "credentials.enc"), key)),
+	}
+}
+
+func TestBackupRestoreRekeysCredentials(t *testing.T) {
+	src := newBackupTestApp(t, bytes.Repeat([]byte{0x11}, 32))
+	network := makeAppTestNetwork(t, src.storage, "Libera")
+	if _, err := src.creds.Store(network.ID, security.FieldSASLPassword, "saslpw"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.CreateBackup("", ""); err == nil {
+		t.Fatal("CreateBackup without a passphrase succeeded")
+	}
+	info, err := src.CreateBackup("", "passphrase")
+	if err != nil {
+		t.Fatalf("CreateBackup: %v", err)
+	}
+	if filepath.Dir(info.Path) != filepath.Join(src.dataDir, backup.Dir) {
+		t.Errorf("backup written to %s", info.Path)
+	}
+
+	dstKey := bytes.Repeat([]byte{0x22}, 32)
+	dst := newBackupTestApp(t, dstKey)
+	if _, err := dst.RestoreBackup(info.Path, "passphrase"); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if !dst.GetBackupStatus().RestorePending {
+		t.Fatal("restore not reported as pending")
+	}
+
+	pending := filepath.Join(dst.dataDir, backup.PendingDir)
+	if err := filepath.WalkDir(pending, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if data, err := os.ReadFile(p); err != nil || bytes.Contains(data, []byte("saslpw")) {
+			t.Errorf("staged %s holds the SASL password in the clear (%v)", p, err)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// What NewApp does at the next start.
+	_ = dst.storage.Close()
+	creds := security.NewCredentialStore(security.NewFileBackendWithKey(filepath.Join(dst.dataDir, "credentials.enc"), dstKey))
+	secrets, applied, err := backup.ApplyPending(dst.dataDir, stagedSecretFile(creds))
+	if err != nil || !applied {
+		t.Fatalf("ApplyPending = %v, %v", applied, err)
+	}
+	if err := finishRestore(creds, dst.dataDir, secrets); err != nil {
+		t.Fatalf("finishRestore: %v", err)
+	}
+	if got := creds.Resolve(network.ID, security.FieldSASLPassword, ""); got != "saslpw" {
+		t.Errorf("restored SASL password = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dst.dataDir, backup.PendingDir)); !os.IsNotExist(err) {
+		t.Errorf("staged restore not cleared: %v", err)
+	}
+}
+
+func TestScheduledBackupRotates(t *testing.T) {
+	a := newBackupTestApp(t, bytes.Repeat([]byte{0x11}, 32))
+	dir := filepath.Join(t.TempDir(), "backups")
+	if err := a.UpdateBackupSettings(BackupSettings{Enabled: true, Directory: dir, Keep: 1}, ""); err == nil {
+		t.Fatal("enabled schedule without a passphrase was accepted")
+	}
+	if err := a.UpdateBackupSettings(BackupSettings{Enabled: true, Directory: dir, Keep: 1}, "passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	old := filepath.Join(dir, backup.FileName(time.Now().Add(-48*time.Hour)))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(old, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a.runScheduledBackup()
+	if status := a.GetBackupStatus(); status.LastError != "" || status.LastBackup == "" {
+		t.Fatalf("status = %+v", status)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("rotation kept the oldest backup")
+	}
+}
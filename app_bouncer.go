@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/bouncer"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+const (
+	// settingBouncer holds the bouncer listener configuration as JSON.
+	settingBouncer = "bouncer.listener"
+	// bouncerPasswordSecret names the listener's PASS secret in the credential store.
+	bouncerPasswordSecret = "bouncer-password"
+)
+
+// BouncerSettings configures the listener other IRC clients attach to.
+type BouncerSettings struct {
+	Enabled            bool     `json:"enabled"`
+	Listen             string   `json:"listen"`             // host:port; bouncer.DefaultListen when empty
+	TLSCert            string   `json:"tlsCert"`            // PEM certificate file; plaintext when empty
+	TLSKey             string   `json:"tlsKey"`             // PEM private key file
+	ClientFingerprints []string `json:"clientFingerprints"` // SHA-256 fingerprints of trusted client certificates
+	ReplayLimit        int      `json:"replayLimit"`        // messages replayed per buffer; 0 for the default
+	HasPassword        bool     `json:"hasPassword"`        // a password is stored (it is never returned)
+}
+
+// BouncerStatus reports whether the listener is running and who is attached.
+type BouncerStatus struct {
+	Running bool            `json:"running"`
+	Address string          `json:"address"`
+	Error   string          `json:"error"` // why the last start failed, if it did
+	Clients []BouncerClient `json:"clients"`
+}
+
+// BouncerClient is an attached client. since is an RFC 3339 string rather
+// than time.Time, which the binding generator does not model.
+type BouncerClient struct {
+	NetworkID int64  `json:"networkId"`
+	Network   string `json:"network"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	Since     string `json:"since"`
+}
+
+// bouncerSettings loads the listener configuration; unreadable settings leave
+// it disabled.
+func (a *App) bouncerSettings() BouncerSettings {
+	var settings BouncerSettings
+	if value, _ := a.storage.GetSetting(settingBouncer); strings.TrimSpace(value) != "" {
+		if err := json.Unmarshal([]byte(value), &settings); err != nil {
+			logger.Log.Warn().Err(err).Msg("Ignoring unreadable bouncer settings")
+			settings = BouncerSettings{}
+		}
+	}
+	if settings.ClientFingerprints == nil {
+		settings.ClientFingerprints = []string{}
+	}
+	settings.HasPassword = a.creds.AppSecret(bouncerPasswordSecret) != ""
+	return settings
+}
+
+func (a *App) GetBouncerSettings() BouncerSettings { return a.bouncerSettings() }
+
+// UpdateBouncerSettings stores the listener configuration and restarts it. An
+// empty password keeps the stored one.
+func (a *App) UpdateBouncerSettings(settings BouncerSettings, password string) error {
+	settings.Listen = strings.TrimSpace(settings.Listen)
+	settings.TLSCert = strings.TrimSpace(settings.TLSCert)
+	settings.TLSKey = strings.TrimSpace(settings.TLSKey)
+	fingerprints := make([]string, 0, len(settings.ClientFingerprints))
+	for _, fp := range settings.ClientFingerprints {
+		if fp = strings.TrimSpace(fp); fp != "" {
+			fingerprints = append(fingerprints, bouncer.NormalizeFingerprint(fp))
+		}
+	}
+	settings.ClientFingerprints = fingerprints
+	settings.HasPassword = false
+
+	password = strings.TrimSpace(password)
+	if password == "" {
+		password = a.creds.AppSecret(bouncerPasswordSecret)
+	}
+	if settings.Enabled {
+		if err := bouncerConfig(settings, password).Validate(); err != nil {
+			return err
+		}
+	}
+	if password != a.creds.AppSecret(bouncerPasswordSecret) {
+		if err := a.creds.StoreAppSecret(bouncerPasswordSecret, password); err != nil {
+			return fmt.Errorf("store bouncer password: %w", err)
+		}
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	if err := a.storage.SetSetting(settingBouncer, string(encoded)); err != nil {
+		return err
+	}
+	a.emit("setting:changed", map[string]string{"key": settingBouncer, "value": string(encoded)})
+
+	a.stopBouncer()
+	if settings.Enabled {
+		return a.startBouncer()
+	}
+	return nil
+}
+
+// GetBouncerStatus reports the listener's state and the attached clients.
+func (a *App) GetBouncerStatus() BouncerStatus {
+	a.mu.RLock()
+	status := BouncerStatus{Error: a.bouncerErr, Clients: []BouncerClient{}}
+	a.mu.RUnlock()
+	if server := a.bouncer.Load(); server != nil {
+		status.Running = true
+		if addr := server.Addr(); addr != nil {
+			status.Address = addr.String()
+		}
+		for _, c := range server.Clients() {
+			status.Clients = append(status.Clients, BouncerClient{
+				NetworkID: c.NetworkID,
+				Network:   c.Network,
+				Name:      c.Name,
+				Address:   c.Address,
+				Since:     c.Since.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	return status
+}
+
+func bouncerConfig(settings BouncerSettings, password string) bouncer.Config {
+	return bouncer.Config{
+		Listen:             settings.Listen,
+		TLSCert:            settings.TLSCert,
+		TLSKey:             settings.TLSKey,
+		Password:           password,
+		ClientFingerprints: settings.ClientFingerprints,
+		ReplayLimit:        settings.ReplayLimit,
+	}
+}
+
+// startBouncer starts the listener when it is enabled. The error is also kept
+// for GetBouncerStatus, since at startup nobody is there to see it.
+func (a *App) startBouncer() error {
+	settings := a.bouncerSettings()
+	if !settings.Enabled {
+		return nil
+	}
+	server, err := bouncer.NewServer(bouncerConfig(settings, a.creds.AppSecret(bouncerPasswordSecret)), bouncerBackend{a}, a.storage)
+	if err == nil {
+		err = server.Listen()
+	}
+	a.mu.Lock()
+	a.bouncerErr = ""
+	if err != nil {
+		a.bouncerErr = err.Error()
+	}
+	a.mu.Unlock()
+	if err != nil {
+		logger.Log.Warn().Err(err).Msg("Bouncer listener unavailable")
+		return err
+	}
+	a.bouncer.Store(server)
+	logger.Log.Info().Str("addr", server.Addr().String()).Msg("Bouncer listening")
+	return nil
+}
+
+func (a *App) stopBouncer() {
+	if server := a.bouncer.Swap(nil); server != nil {
+		_ = server.Close()
+	}
+}
+
+// relayToBouncer passes a network's inbound line to the attached clients. It
+// runs on the session's read goroutine for every line.
+func (a *App) relayToBouncer(networkID int64, msg ircmsg.Message) {
+	if server := a.bouncer.Load(); server != nil {
+		server.Relay(networkID, msg)
+	}
+}
+
+// bouncerBackend gives the listener the app's networks and live sessions.
+type bouncerBackend struct{ a *App }
+
+func (b bouncerBackend) Network(ref string) (*storage.Network, error) {
+	return b.a.resolveControlNetwork(ref)
+}
+
+func (b bouncerBackend) Upstream(networkID int64) bouncer.Upstream {
+	b.a.mu.RLock()
+	client := b.a.ircClients[networkID]
+	b.a.mu.RUnlock()
+	if client == nil || !client.IsConnected() {
+		return nil // a typed nil would not compare equal to nil
+	}
+	return client
+}
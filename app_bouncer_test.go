@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestBouncerBackendUpstreamIsNilWithoutASession(t *testing.T) {
+	a := newTestApp(t)
+	libera := makeAppTestNetwork(t, a.storage, "Libera")
+	backend := bouncerBackend{a}
+
+	if up := backend.Upstream(libera.ID); up != nil {
+		t.Fatalf("Upstream = %#v, want a nil interface for a network with no session", up)
+	}
+	if got, err := backend.Network("libera"); err != nil || got.ID != libera.ID {
+		t.Errorf("Network(libera) = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// ChannelListCacheResult is a network's stored channel directory as returned
+// to the frontend. FetchedAt is unix milliseconds; Found is false when the
+// network's channels have never been listed. Filter is the LIST argument of the
+// latest fetch, empty for a full listing, and NewCount counts the channels that
+// fetch was the first to list.
+type ChannelListCacheResult struct {
+	Channels  []storage.ChannelDirectoryEntry `json:"channels"`
+	FetchedAt int64                           `json:"fetchedAt"`
+	Found     bool                            `json:"found"`
+	Filter    string                          `json:"filter"`
+	NewCount  int                             `json:"newCount"`
+}
+
+// GetCachedChannelList returns the network's stored channel directory, if any.
+// It never triggers a fetch — callers use RequestChannelList to refresh it.
+func (a *App) GetCachedChannelList(networkID int64) ChannelListCacheResult {
+	res, err := a.channelDirectory(networkID)
+	if err != nil {
+		logger.Log.Warn().Err(err).Int64("network_id", networkID).Msg("failed to read channel directory")
+		return ChannelListCacheResult{Channels: []storage.ChannelDirectoryEntry{}}
+	}
+	return res
+}
+
+func (a *App) channelDirectory(networkID int64) (ChannelListCacheResult, error) {
+	fetch, err := a.storage.GetChannelDirectoryFetch(networkID)
+	if err != nil || fetch == nil {
+		return ChannelListCacheResult{Channels: []storage.ChannelDirectoryEntry{}}, err
+	}
+	channels, err := a.storage.SearchChannelDirectory(networkID, storage.ChannelDirectoryQuery{})
+	if err != nil {
+		return ChannelListCacheResult{}, err
+	}
+	res := ChannelListCacheResult{
+		Channels:  channels,
+		FetchedAt: fetch.FetchedAt,
+		Found:     true,
+		Filter:    fetch.Filter,
+	}
+	for _, ch := range channels {
+		if ch.New {
+			res.NewCount++
+		}
+	}
+	return res, nil
+}
+
+// SearchChannelDirectory searches the network's stored channel directory
+// without asking the server. filter uses /list syntax (see
+// irc.ParseChannelListFilter); channel and topic age conditions are ignored,
+// since LIST replies carry no times. newOnly keeps channels the latest fetch
+// was the first to list. A limit of 0 returns every match.
+func (a *App) SearchChannelDirectory(networkID int64, filter string, newOnly bool, limit int) ([]storage.ChannelDirectoryEntry, error) {
+	f, err := irc.ParseChannelListFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return a.storage.SearchChannelDirectory(networkID, storage.ChannelDirectoryQuery{
+		Text:         f.Text,
+		MinUsers:     f.MinUsers,
+		MaxUsers:     f.MaxUsers,
+		Masks:        f.Masks,
+		ExcludeMasks: f.ExcludeMasks,
+		NewOnly:      newOnly,
+		Limit:        limit,
+	})
+}
+
+// RequestChannelList asks the server for its channel list. filter uses /list
+// syntax; whatever part of it the server's ELIST support allows is applied
+// server-side, so a narrow filter avoids downloading every channel. An empty
+// filter fetches the full list, which also drops channels that have gone.
+func (a *App) RequestChannelList(networkID int64, filter string) error {
+	f, err := irc.ParseChannelListFilter(filter)
+	if err != nil {
+		return err
+	}
+	client, err := a.connectedClient(networkID)
+	if err != nil {
+		return err
+	}
+	if !client.IsConnected() {
+		return fmt.Errorf("network not connected")
+	}
+	_, err = client.RequestChannelList(f)
+	return err
+}
+
+// RequestNewChannels refreshes the directory with the channels created since
+// the last full fetch. Servers whose ELIST lacks creation-time filtering, and
+// networks never fully fetched, get a full fetch instead.
+func (a *App) RequestNewChannels(networkID int64) error {
+	client, err := a.connectedClient(networkID)
+	if err != nil {
+		return err
+	}
+	if !client.IsConnected() {
+		return fmt.Errorf("network not connected")
+	}
+	fetch, err := a.storage.GetChannelDirectoryFetch(networkID)
+	if err != nil {
+		return err
+	}
+	var f irc.ChannelListFilter
+	if fetch != nil && fetch.FullFetchedAt > 0 && client.SupportsListCondition("C") {
+		since := time.Since(time.UnixMilli(fetch.FullFetchedAt))
+		f.CreatedWithin = max(1, int(math.Ceil(since.Minutes())))
+	}
+	_, err = client.RequestChannelList(f)
+	return err
+}
+
+// storeChannelList merges a finished LIST into the network's directory and
+// tells the frontend, sending the whole updated directory.
+func (a *App) storeChannelList(networkID int64, items []irc.ChannelListItem, filter string) {
+	channels := make([]storage.ChannelDirectoryEntry, len(items))
+	for i, item := range items {
+		channels[i] = storage.ChannelDirectoryEntry{
+			Channel: item.Channel,
+			Users:   int64(item.Users),
+			Topic:   item.Topic,
+			Modes:   item.Modes,
+		}
+	}
+	removed, err := a.storage.StoreChannelDirectory(networkID, filter, time.Now(), channels)
+	if err != nil {
+		logger.Log.Warn().Err(err).Int64("network_id", networkID).Msg("failed to store channel list")
+		return
+	}
+	dir, err := a.channelDirectory(networkID)
+	if err != nil {
+		logger.Log.Warn().Err(err).Int64("network_id", networkID).Msg("failed to read channel directory")
+		return
+	}
+	a.emit("channel-list", map[string]interface{}{
+		"type": irc.EventChannelListEnd,
+		"data": map[string]interface{}{
+			"networkId": networkID,
+			"channels":  dir.Channels,
+			"fetchedAt": dir.FetchedAt,
+			"filter":    filter,
+			"listed":    len(items),
+			"newCount":  dir.NewCount,
+			"removed":   removed,
+		},
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
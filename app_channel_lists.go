@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// channelListExpiryInterval is how often stored list entries are checked for a
+// due client-side expiry. Expiries live in the database, so one that falls due
+// while the app is closed or the network is down is carried out on the next
+// pass after we are back in the channel.
+const channelListExpiryInterval = 30 * time.Second
+
+var channelListModeNames = map[rune]string{'b': "ban", 'e': "exception", 'I': "invite exception"}
+
+// GetChannelListEntries returns the stored ban (b), exception (e) and invite
+// exception (I) entries for a channel. mode is optional; empty returns all.
+func (a *App) GetChannelListEntries(networkID int64, channel, mode string) ([]storage.ChannelListEntry, error) {
+	return a.storage.ListChannelListEntries(networkID, a.channelListKey(networkID, channel), mode)
+}
+
+// RefreshChannelLists asks the server for every tracked list mode it supports.
+// Each list is stored when it finishes loading and announced as a
+// "channel.listmode" message event.
+func (a *App) RefreshChannelLists(networkID int64, channel string) error {
+	client, err := a.connectedChannelListClient(networkID)
+	if err != nil {
+		return err
+	}
+	listModes, _, _, _ := client.GetChanModeClasses()
+	for _, mode := range irc.TrackedListModes {
+		if !strings.ContainsRune(listModes, mode) {
+			continue
+		}
+		if err := client.RequestChannelListMode(channel, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddChannelListEntries adds masks to one of a channel's list modes, batched to
+// the server's MODES limit. A positive expiresInSeconds schedules their removal;
+// zero keeps them until removed by hand.
+func (a *App) AddChannelListEntries(networkID int64, channel, mode string, masks []string, expiresInSeconds int64) error {
+	modeLetter, masks, err := parseChannelListRequest(mode, masks)
+	if err != nil {
+		return err
+	}
+	if expiresInSeconds < 0 {
+		return fmt.Errorf("expiry must not be negative")
+	}
+	client, err := a.connectedChannelListClient(networkID)
+	if err != nil {
+		return err
+	}
+	changes := make([]irc.ListModeChange, len(masks))
+	for i, mask := range masks {
+		changes[i] = irc.ListModeChange{Add: true, Mode: modeLetter, Mask: mask}
+	}
+	if err := client.SetChannelListModes(channel, changes); err != nil {
+		return err
+	}
+
+	// Record the entries now rather than waiting for the MODE echo so the
+	// expiry is stored even if the echo races a restart.
+	now := time.Now()
+	var expiresAt *time.Time
+	if expiresInSeconds > 0 {
+		t := now.Add(time.Duration(expiresInSeconds) * time.Second)
+		expiresAt = &t
+	}
+	key := client.FoldKey(channel)
+	for _, mask := range masks {
+		if err := a.storage.UpsertChannelListEntry(storage.ChannelListEntry{
+			NetworkID: networkID, Channel: channel, ChannelKey: key, Mode: mode, Mask: mask,
+			SetBy: client.CurrentNick(), SetAt: &now,
+		}); err != nil {
+			return err
+		}
+		if err := a.storage.SetChannelListEntryExpiry(networkID, key, mode, mask, expiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveChannelListEntries removes masks from one of a channel's list modes,
+// batched to the server's MODES limit.
+func (a *App) RemoveChannelListEntries(networkID int64, channel, mode string, masks []string) error {
+	modeLetter, masks, err := parseChannelListRequest(mode, masks)
+	if err != nil {
+		return err
+	}
+	client, err := a.connectedChannelListClient(networkID)
+	if err != nil {
+		return err
+	}
+	changes := make([]irc.ListModeChange, len(masks))
+	for i, mask := range masks {
+		changes[i] = irc.ListModeChange{Add: false, Mode: modeLetter, Mask: mask}
+	}
+	if err := client.SetChannelListModes(channel, changes); err != nil {
+		return err
+	}
+	key := client.FoldKey(channel)
+	for _, mask := range masks {
+		if err := a.storage.DeleteChannelListEntry(networkID, key, mode, mask); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetChannelListEntryExpiry schedules the removal of an existing entry
+// expiresInSeconds from now, or cancels a scheduled removal when it is zero.
+func (a *App) SetChannelListEntryExpiry(networkID int64, channel, mode, mask string, expiresInSeconds int64) error {
+	if expiresInSeconds < 0 {
+		return fmt.Errorf("expiry must not be negative")
+	}
+	var expiresAt *time.Time
+	if expiresInSeconds > 0 {
+		t := time.Now().Add(time.Duration(expiresInSeconds) * time.Second)
+		expiresAt = &t
+	}
+	return a.storage.SetChannelListEntryExpiry(networkID, a.channelListKey(networkID, channel), mode, mask, expiresAt)
+}
+
+// parseChannelListRequest validates a tracked list mode letter and drops blank
+// masks. Whether the server supports the mode is checked when sending.
+func parseChannelListRequest(mode string, masks []string) (rune, []string, error) {
+	letter, size := utf8.DecodeRuneInString(mode)
+	if size == 0 || size != len(mode) || !strings.ContainsRune(irc.TrackedListModes, letter) {
+		return 0, nil, fmt.Errorf("unsupported list mode %q", mode)
+	}
+	cleaned := make([]string, 0, len(masks))
+	for _, mask := range masks {
+		if mask = strings.TrimSpace(mask); mask != "" {
+			cleaned = append(cleaned, mask)
+		}
+	}
+	if len(cleaned) == 0 {
+		return 0, nil, fmt.Errorf("at least one mask is required")
+	}
+	return letter, cleaned, nil
+}
+
+func (a *App) connectedChannelListClient(networkID int64) (*irc.IRCClient, error) {
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if !exists || !client.IsConnected() {
+		return nil, fmt.Errorf("not connected to network %d", networkID)
+	}
+	return client, nil
+}
+
+// channelListKey folds a channel name the way the IRC client keys stored lists.
+// With no client it falls back to the protocol-default rfc1459 mapping.
+func (a *App) channelListKey(networkID int64, channel string) string {
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if exists {
+		return client.FoldKey(channel)
+	}
+	return irc.CaseFold("", channel)
+}
+
+// startChannelListExpiry runs expireChannelListEntries on a fixed cadence until
+// shutdown.
+func (a *App) startChannelListExpiry() {
+	a.startupWg.Add(1)
+	go func() {
+		defer a.startupWg.Done()
+		ticker := time.NewTicker(channelListExpiryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.startupCtx.Done():
+				return
+			case now := <-ticker.C:
+				a.expireChannelListEntries(now)
+			}
+		}
+	}()
+}
+
+// expireChannelListEntries removes every due entry from its channel, one
+// batched MODE run per channel. Entries on networks we are not connected to,
+// or in channels we are not in or hold no ops in, stay due and are retried on
+// a later pass; without ops the server would refuse the MODE and the entry
+// would stay on the channel with nothing left to remove it.
+func (a *App) expireChannelListEntries(now time.Time) {
+	due, err := a.storage.ListExpiredChannelListEntries(now)
+	if err != nil {
+		logger.Log.Warn().Err(err).Msg("Failed to load expired channel list entries")
+		return
+	}
+	type channelRef struct {
+		networkID int64
+		key       string
+	}
+	var order []channelRef
+	byChannel := make(map[channelRef][]storage.ChannelListEntry)
+	for _, entry := range due {
+		ref := channelRef{entry.NetworkID, entry.ChannelKey}
+		if _, seen := byChannel[ref]; !seen {
+			order = append(order, ref)
+		}
+		byChannel[ref] = append(byChannel[ref], entry)
+	}
+
+	for _, ref := range order {
+		entries := byChannel[ref]
+		channel := entries[0].Channel
+		client, err := a.connectedChannelListClient(ref.networkID)
+		if err != nil || !a.canSetChannelModes(client, ref.networkID, channel) {
+			continue
+		}
+		changes := make([]irc.ListModeChange, 0, len(entries))
+		for _, entry := range entries {
+			letter, _ := utf8.DecodeRuneInString(entry.Mode)
+			changes = append(changes, irc.ListModeChange{Add: false, Mode: letter, Mask: entry.Mask})
+		}
+		if err := client.SetChannelListModes(channel, changes); err != nil {
+			logger.Log.Warn().Err(err).Str("channel", channel).Msg("Failed to remove expired channel list entries")
+			continue
+		}
+		lines := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if err := a.storage.DeleteChannelListEntry(ref.networkID, ref.key, entry.Mode, entry.Mask); err != nil {
+				logger.Log.Warn().Err(err).Str("mask", entry.Mask).Msg("Failed to delete expired channel list entry")
+			}
+			letter, _ := utf8.DecodeRuneInString(entry.Mode)
+			lines = append(lines, fmt.Sprintf("Removed expired %s %s", channelListModeNames[letter], entry.Mask))
+		}
+		_ = a.PrintLocalLines(ref.networkID, channel, lines)
+	}
+}
+
+// canSetChannelModes reports whether our current nick is on the channel's
+// roster as a halfop or better, which list modes need.
+func (a *App) canSetChannelModes(client *irc.IRCClient, networkID int64, channel string) bool {
+	ch, err := a.storage.GetChannelByName(networkID, channel)
+	if err != nil {
+		return false
+	}
+	modes, err := a.storage.GetChannelUserModes(ch.ID, client.CurrentNick())
+	return err == nil && strings.ContainsAny(modes, "~&@%")
+}
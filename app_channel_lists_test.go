@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+func TestParseChannelListRequest(t *testing.T) {
+	letter, masks, err := parseChannelListRequest("I", []string{" friend!*@* ", "", "pal!*@*"})
+	if err != nil || letter != 'I' || len(masks) != 2 || masks[0] != "friend!*@*" {
+		t.Fatalf("parse = %q %q %v, want I with two trimmed masks", letter, masks, err)
+	}
+	for _, mode := range []string{"", "o", "be", "q"} {
+		if _, _, err := parseChannelListRequest(mode, []string{"x!*@*"}); err == nil {
+			t.Errorf("mode %q accepted, want error", mode)
+		}
+	}
+	if _, _, err := parseChannelListRequest("b", []string{" "}); err == nil {
+		t.Error("blank masks accepted, want error")
+	}
+}
+
+// TestExpiredEntriesWaitForConnection: a due expiry on a network we are not
+// connected to stays stored, so it is carried out after reconnecting.
+func TestExpiredEntriesWaitForConnection(t *testing.T) {
+	a := newTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "ListApp")
+	past := time.Now().Add(-time.Minute)
+	if err := a.storage.UpsertChannelListEntry(storage.ChannelListEntry{
+		NetworkID: net.ID, Channel: "#ops", ChannelKey: "#ops", Mode: "b", Mask: "spam!*@*", ExpiresAt: &past,
+	}); err != nil {
+		t.Fatalf("UpsertChannelListEntry: %v", err)
+	}
+
+	a.expireChannelListEntries(time.Now())
+
+	due, err := a.storage.ListExpiredChannelListEntries(time.Now())
+	if err != nil {
+		t.Fatalf("ListExpiredChannelListEntries: %v", err)
+	}
+	if len(due) != 1 {
+		t.Fatalf("due = %+v, want the entry kept until we can remove it", due)
+	}
+	if got, err := a.GetChannelListEntries(net.ID, "#OPS", "b"); err != nil || len(got) != 1 {
+		t.Errorf("GetChannelListEntries = %+v, err %v; want the stored ban", got, err)
+	}
+}
+
+// TestExpiryNeedsOps: entries in a channel where we hold no ops are left for a
+// later pass, since the server would refuse to remove them.
+func TestExpiryNeedsOps(t *testing.T) {
+	a := newTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "ListOps")
+	client := irc.NewIRCClient(net, a.eventBus, a.storage)
+	ch := &storage.Channel{NetworkID: net.ID, Name: "#ops"}
+	if err := a.storage.CreateChannel(ch); err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	if a.canSetChannelModes(client, net.ID, "#ops") {
+		t.Error("allowed while not in the channel")
+	}
+	for modes, want := range map[string]bool{"": false, "+": false, "%": true, "@": true, "~": true} {
+		if err := a.storage.AddChannelUser(ch.ID, client.CurrentNick(), modes); err != nil {
+			t.Fatalf("AddChannelUser: %v", err)
+		}
+		if got := a.canSetChannelModes(client, net.ID, "#ops"); got != want {
+			t.Errorf("with modes %q: canSetChannelModes = %v, want %v", modes, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/matt0x6f/irc-client/internal/irc"
 )
@@ -16,6 +17,9 @@ func (a *App) SendMessage(networkID int64, target, message string) error {
 // replyMsgID -> +draft/reply, channelContext -> +draft/channel-context. Empty
 // strings omit the corresponding tag.
 func (a *App) SendMessageWithContext(networkID int64, target, message, replyMsgID, channelContext string) error {
+	if peer, ok := directChatPeer(target); ok {
+		return a.sendDirectChat(networkID, peer, message, false)
+	}
 	a.mu.RLock()
 	client, exists := a.ircClients[networkID]
 	a.mu.RUnlock()
@@ -25,10 +29,28 @@ func (a *App) SendMessageWithContext(networkID int64, target, message, replyMsgI
 	return client.SendMessageWithTags(target, message, replyMsgID, channelContext)
 }
 
+// sendScriptMessage is SendMessage for scripts. Their lines are automation:
+// they wait behind anything the user sends on the network.
+func (a *App) sendScriptMessage(networkID int64, target, message string) error {
+	if peer, ok := directChatPeer(target); ok {
+		return a.sendDirectChat(networkID, peer, message, false)
+	}
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("network not connected")
+	}
+	return client.SendAutomatedMessage(target, message)
+}
+
 // SendTyping emits an IRCv3 +typing client tag (active/paused/done) for target.
 // Best-effort: a disconnected network or a server without message-tags yields no
 // error so the frontend's typing state machine can fire freely.
 func (a *App) SendTyping(networkID int64, target, state string) error {
+	if _, ok := directChatPeer(target); ok {
+		return nil // direct chat has no typing notifications
+	}
 	a.mu.RLock()
 	client, exists := a.ircClients[networkID]
 	a.mu.RUnlock()
@@ -45,6 +67,9 @@ func (a *App) SendTyping(networkID int64, target, state string) error {
 // PM/query pane (channel catch-up fires automatically on JOIN). Replays are stored
 // and deduped by msgid; the frontend learns of them via the "history-event".
 func (a *App) RequestChatHistoryLatest(networkID int64, target string, limit int) error {
+	if _, ok := directChatPeer(target); ok {
+		return nil // the server never saw a direct chat
+	}
 	a.mu.RLock()
 	client, exists := a.ircClients[networkID]
 	a.mu.RUnlock()
@@ -59,6 +84,9 @@ func (a *App) RequestChatHistoryLatest(networkID int64, target string, limit int
 // than beforeISO (an ISO8601 timestamp) for target. Used by scroll-to-top deep
 // backscroll once the local store is exhausted.
 func (a *App) RequestChatHistoryBefore(networkID int64, target, beforeISO string, limit int) error {
+	if _, ok := directChatPeer(target); ok {
+		return nil
+	}
 	a.mu.RLock()
 	client, exists := a.ircClients[networkID]
 	a.mu.RUnlock()
@@ -72,6 +100,13 @@ func (a *App) RequestChatHistoryBefore(networkID int64, target, beforeISO string
 // SendCommand sends a command from any channel or status window
 // Supports commands like /join #channel, /msg user message, or raw IRC commands
 func (a *App) SendCommand(networkID int64, command string) error {
+	return a.SendCommandInBuffer(networkID, "status", command)
+}
+
+// SendCommandInBuffer is SendCommand for a command typed in a specific pane:
+// buffer is "status", a channel name, or a query nick. Commands whose output
+// belongs with the request (e.g. CTCP replies) are shown there.
+func (a *App) SendCommandInBuffer(networkID int64, buffer, command string) error {
 	a.mu.RLock()
 	client, exists := a.ircClients[networkID]
 	a.mu.RUnlock()
@@ -93,21 +128,28 @@ func (a *App) SendCommand(networkID int64, command string) error {
 		}
 		// Pass the original remainder (command without the leading slash) so the
 		// unknown-command fallback preserves exact spacing/colons verbatim.
-		return a.dispatchCommand(client, networkID, parts[0], parts[1:], command[1:])
+		return a.dispatchCommand(client, networkID, buffer, parts[0], parts[1:], command[1:])
 	}
 
 	return client.SendRawCommand(command)
 }
 
-// dispatchCommand routes a parsed slash command: built-in handler, then plugin
-// command (Phase 4), then raw passthrough for unknown commands. rawRemainder is
-// the original command text with the leading slash removed, used verbatim for
-// the passthrough so multi-space/colon payloads are not mangled.
+// dispatchCommand routes a parsed slash command: built-in handler, then
+// user-defined alias, then plugin command (Phase 4), then raw passthrough for
+// unknown commands. rawRemainder is the original command text with the leading
+// slash removed, used verbatim for the passthrough so multi-space/colon payloads
+// are not mangled.
 // The client parameter may be nil only in unit tests that exercise paths which
 // short-circuit before using it (Frontend specs and MinArgs usage errors);
 // production always passes a non-nil client because SendCommand guards on the
 // connection first.
-func (a *App) dispatchCommand(client *irc.IRCClient, networkID int64, name string, args []string, rawRemainder string) error {
+func (a *App) dispatchCommand(client *irc.IRCClient, networkID int64, buffer, name string, args []string, rawRemainder string) error {
+	return a.runCommand(client, networkID, buffer, name, args, rawRemainder, nil)
+}
+
+// runCommand is dispatchCommand for a command that may come from an alias
+// expansion; aliasStack lists the aliases being expanded around it.
+func (a *App) runCommand(client *irc.IRCClient, networkID int64, buffer, name string, args []string, rawRemainder string, aliasStack []string) error {
 	if spec, ok := a.commands.Lookup(name); ok {
 		if spec.Frontend {
 			return nil // handled in the frontend; should not reach here
@@ -115,14 +157,37 @@ func (a *App) dispatchCommand(client *irc.IRCClient, networkID int64, name strin
 		if len(args) < spec.MinArgs {
 			return fmt.Errorf("usage: /%s %s", strings.ToLower(spec.Name), spec.Usage)
 		}
-		return spec.handler(a, client, networkID, args)
+		if spec.rawHandler != nil {
+			return spec.rawHandler(a, client, networkID, buffer, args, rawRemainder)
+		}
+		return spec.handler(a, client, networkID, buffer, args)
+	}
+	if alias, ok := a.lookupAlias(networkID, name); ok {
+		return a.runAlias(client, networkID, buffer, alias, args, aliasStack)
 	}
 	if a.pluginManager != nil {
 		if entry, ok := a.pluginManager.LookupPluginCommand(name); ok {
-			channel := "" // best-effort context; the frontend sends the active buffer separately if needed
+			channel := buffer
+			if channel == "status" {
+				channel = ""
+			}
 			return a.pluginManager.InvokePluginCommand(entry.Plugin, strings.ToUpper(name), args, networkID, channel)
 		}
 	}
 	// Unknown command: raw passthrough (preserves server-extension commands).
 	return client.SendRawCommand(rawRemainder)
 }
+
+// textAfterFields returns raw without its first n whitespace-separated fields
+// and the blanks that follow them, leaving the rest exactly as typed.
+func textAfterFields(raw string, n int) string {
+	for i := 0; i < n; i++ {
+		raw = strings.TrimLeftFunc(raw, unicode.IsSpace)
+		end := strings.IndexFunc(raw, unicode.IsSpace)
+		if end < 0 {
+			return ""
+		}
+		raw = raw[end:]
+	}
+	return strings.TrimLeftFunc(raw, unicode.IsSpace)
+}
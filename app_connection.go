@@ -10,6 +10,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/ergochat/irc-go/ircmsg"
 	"github.com/matt0x6f/irc-client/internal/constants"
 	"github.com/matt0x6f/irc-client/internal/events"
 	"github.com/matt0x6f/irc-client/internal/imageproc"
@@ -46,6 +47,7 @@ type NetworkConfig struct {
 	SASLExternalCert string         `json:"sasl_external_cert"`
 	AutoConnect      bool           `json:"auto_connect"`
 	IdentifyAsBot    bool           `json:"identify_as_bot"`
+	FloodProfile     string         `json:"flood_profile"` // "", "standard", "cautious" or "relaxed"
 }
 
 // writeNetworkStatus writes a line to a network's status buffer and emits
@@ -106,6 +108,11 @@ func (a *App) normalizeServers(config NetworkConfig) []ServerConfig {
 // the preference.
 func (a *App) buildNetworkFromConfig(config NetworkConfig, servers []ServerConfig, persistAutoConnect bool) (*storage.Network, error) {
 	var network *storage.Network
+	if persistAutoConnect {
+		if _, err := irc.FloodProfileNamed(config.FloodProfile); err != nil {
+			return nil, err
+		}
+	}
 
 	// Check if network already exists (by name)
 	networks, err := a.storage.GetNetworks()
@@ -156,6 +163,10 @@ func (a *App) buildNetworkFromConfig(config NetworkConfig, servers []ServerConfi
 			UpdatedAt:        time.Now(),
 		}
 
+		if persistAutoConnect {
+			network.FloodProfile = config.FloodProfile
+		}
+
 		if err := a.storage.CreateNetwork(network); err != nil {
 			return nil, fmt.Errorf("failed to create network: %w", err)
 		}
@@ -177,6 +188,7 @@ func (a *App) buildNetworkFromConfig(config NetworkConfig, servers []ServerConfi
 		if persistAutoConnect {
 			network.AutoConnect = config.AutoConnect
 			network.IdentifyAsBot = config.IdentifyAsBot
+			network.FloodProfile = config.FloodProfile
 		}
 		network.UpdatedAt = time.Now()
 		if err := a.storage.UpdateNetwork(network); err != nil {
@@ -294,9 +306,19 @@ func (a *App) SaveNetwork(config NetworkConfig) error {
 	}
 
 	// SaveNetwork is the explicit-edit path: honor the auto_connect checkbox.
-	if _, err := a.buildNetworkFromConfig(config, servers, true); err != nil {
+	network, err := a.buildNetworkFromConfig(config, servers, true)
+	if err != nil {
 		return err
 	}
+	// The flood profile applies to a live connection at once.
+	a.mu.RLock()
+	client := a.ircClients[network.ID]
+	a.mu.RUnlock()
+	if client != nil {
+		if err := client.SetFloodProfile(network.FloodProfile); err != nil {
+			logger.Log.Warn().Err(err).Int64("network_id", network.ID).Msg("Failed to apply flood profile")
+		}
+	}
 	// Broadcast so every window (notably the main window, when the save was made
 	// from the standalone Settings window) refreshes its network list at once
 	// instead of waiting on the periodic poll.
@@ -514,6 +536,20 @@ func (a *App) connectNetwork(config NetworkConfig, reconnect bool) error {
 		ircClient := irc.NewIRCClient(&tempNetwork, a.eventBus, a.storage)
 		ircClient.SetNetworkID(network.ID)
 		ircClient.SetReconnecting(reconnect)
+		ircClient.SetCTCPSettings(a.ctcpSettings())
+		networkID := network.ID
+		ircClient.SetLineObserver(func(msg ircmsg.Message) { a.relayToBouncer(networkID, msg) })
+		ircClient.SetPerformAction(func() { a.runPerform(networkID, ircClient) })
+		ircClient.SetServicesLogin(a.servicesLogin(networkID))
+		ircClient.SetEncryptionKeys(a.encryptionKeys(networkID))
+		ircClient.SetAccountRegisteredAction(func(account, password string) {
+			if err := a.saveRegisteredAccount(networkID, account, password); err != nil {
+				logger.Log.Warn().Err(err).Int64("network_id", networkID).Msg("Failed to save registered account")
+			}
+		})
+		if reg, ok := a.takeQueuedRegistration(networkID); ok {
+			ircClient.QueueAccountRegistration(reg)
+		}
 
 		// Try to connect with timeout
 		logger.Log.Debug().Str("server", serverKey).Msg("Starting connection attempt")
@@ -899,6 +935,7 @@ func (a *App) buildReconnectConfig(networkID int64, network *storage.Network) (N
 		SASLEnabled:      network.SASLEnabled,
 		AutoConnect:      network.AutoConnect,
 		IdentifyAsBot:    network.IdentifyAsBot,
+		FloodProfile:     network.FloodProfile,
 		Servers:          serverConfigs,
 	}
 
@@ -1038,6 +1075,7 @@ func (a *App) autoConnect(ctx context.Context) {
 					SASLEnabled:      network.SASLEnabled,
 					AutoConnect:      network.AutoConnect,
 					IdentifyAsBot:    network.IdentifyAsBot,
+					FloodProfile:     network.FloodProfile,
 				}
 				if network.SASLMechanism != nil {
 					config.SASLMechanism = *network.SASLMechanism
@@ -1240,12 +1278,20 @@ func (a *App) DeleteNetwork(networkID int64) error {
 		client.Disconnect()
 	}
 
+	// The perform list goes with the network row, so collect the secrets it
+	// names first.
+	var performSecrets []string
+	if names, err := a.performSecretNames(networkID); err == nil {
+		for _, name := range names {
+			performSecrets = append(performSecrets, performSecretPrefix+name)
+		}
+	}
 	if err := a.storage.DeleteNetwork(networkID); err != nil {
 		return err
 	}
 	_ = os.Remove(a.networkIconPath(networkID)) // best-effort icon cleanup
 	// Remove any secrets held in the keychain for this network.
-	if err := a.creds.Delete(networkID); err != nil {
+	if err := a.creds.Delete(networkID, performSecrets...); err != nil {
 		logger.Log.Warn().Err(err).Int64("network_id", networkID).Msg("Failed to delete network secrets from keychain")
 	}
 	a.emit("networks:changed")
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/control"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// startControlSocket serves the local automation API (internal/control) on
+// <data dir>/control.sock for cascade-cli and scripts. A failure is logged and
+// leaves the app running without it.
+func (a *App) startControlSocket() {
+	server := control.NewServer(a.eventBus)
+	server.Handle(control.MethodNetworks, a.controlNetworks)
+	server.Handle(control.MethodChannels, a.controlChannels)
+	server.Handle(control.MethodSend, a.controlSend)
+	server.Handle(control.MethodCommand, a.controlCommand)
+	server.Handle(control.MethodSearch, a.controlSearch)
+	server.Handle(control.MethodBackup, a.controlBackup)
+	server.Handle(control.MethodRestore, a.controlRestore)
+
+	path := control.SocketPath(a.dataDir)
+	if err := server.Listen(path); err != nil {
+		logger.Log.Warn().Err(err).Str("path", path).Msg("Control socket unavailable")
+		return
+	}
+	a.mu.Lock()
+	a.control = server
+	a.mu.Unlock()
+	logger.Log.Info().Str("path", path).Msg("Control socket listening")
+}
+
+func (a *App) stopControlSocket() {
+	a.mu.Lock()
+	server := a.control
+	a.control = nil
+	a.mu.Unlock()
+	if server != nil {
+		_ = server.Close()
+	}
+}
+
+// resolveControlNetwork finds a network by case-insensitive name or numeric ID.
+func (a *App) resolveControlNetwork(ref string) (*storage.Network, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, control.InvalidParams(errors.New("network is required"))
+	}
+	networks, err := a.storage.GetNetworks()
+	if err != nil {
+		return nil, err
+	}
+	id, idErr := strconv.ParseInt(ref, 10, 64)
+	for i := range networks {
+		if strings.EqualFold(networks[i].Name, ref) || (idErr == nil && networks[i].ID == id) {
+			return &networks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no network named %q", ref)
+}
+
+func (a *App) controlNetworks(json.RawMessage) (interface{}, error) {
+	networks, err := a.storage.GetNetworks()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]control.NetworkInfo, 0, len(networks))
+	for _, n := range networks {
+		info := control.NetworkInfo{ID: n.ID, Name: n.Name}
+		a.mu.RLock()
+		client := a.ircClients[n.ID]
+		a.mu.RUnlock()
+		if client != nil && client.IsConnected() {
+			info.Connected, info.Nick = true, client.CurrentNick()
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+func (a *App) controlChannels(params json.RawMessage) (interface{}, error) {
+	var p control.NetworkParams
+	if err := control.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	network, err := a.resolveControlNetwork(p.Network)
+	if err != nil {
+		return nil, err
+	}
+	channels, err := a.GetJoinedChannels(network.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]control.ChannelInfo, 0, len(channels))
+	for _, ch := range channels {
+		out = append(out, control.ChannelInfo{Name: ch.Name, Topic: ch.Topic})
+	}
+	return out, nil
+}
+
+func (a *App) controlSend(params json.RawMessage) (interface{}, error) {
+	var p control.SendParams
+	if err := control.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Target == "" || p.Message == "" {
+		return nil, control.InvalidParams(errors.New("target and message are required"))
+	}
+	network, err := a.resolveControlNetwork(p.Network)
+	if err != nil {
+		return nil, err
+	}
+	return nil, a.SendMessage(network.ID, p.Target, p.Message)
+}
+
+func (a *App) controlCommand(params json.RawMessage) (interface{}, error) {
+	var p control.CommandParams
+	if err := control.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Command) == "" {
+		return nil, control.InvalidParams(errors.New("command is required"))
+	}
+	network, err := a.resolveControlNetwork(p.Network)
+	if err != nil {
+		return nil, err
+	}
+	buffer := p.Buffer
+	if buffer == "" {
+		buffer = "status"
+	}
+	return nil, a.SendCommandInBuffer(network.ID, buffer, p.Command)
+}
+
+func (a *App) controlSearch(params json.RawMessage) (interface{}, error) {
+	var p control.SearchParams
+	if err := control.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Query) == "" {
+		return nil, control.InvalidParams(errors.New("query is required"))
+	}
+	var networkID *int64
+	if p.Network != "" {
+		network, err := a.resolveControlNetwork(p.Network)
+		if err != nil {
+			return nil, err
+		}
+		networkID = &network.ID
+	}
+	results, err := a.SearchMessages(p.Query, networkID, p.Limit)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]control.SearchHit, 0, len(results))
+	for _, r := range results {
+		channel := r.ChannelName
+		if channel == "" {
+			channel = r.PMTarget
+		}
+		hits = append(hits, control.SearchHit{
+			Network: r.NetworkName, Channel: channel, User: r.User, Message: r.Message.Message, Timestamp: r.Timestamp,
+		})
+	}
+	return hits, nil
+}
+
+func (a *App) controlBackup(params json.RawMessage) (interface{}, error) {
+	var p control.BackupParams
+	if err := control.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	info, err := a.CreateBackup(p.Path, p.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return controlBackupResult(info), nil
+}
+
+func (a *App) controlRestore(params json.RawMessage) (interface{}, error) {
+	var p control.BackupParams
+	if err := control.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Path) == "" {
+		return nil, control.InvalidParams(errors.New("path is required"))
+	}
+	if p.Passphrase == "" {
+		p.Passphrase = a.creds.AppSecret(backupPassphraseSecret)
+	}
+	info, err := a.RestoreBackup(p.Path, p.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return controlBackupResult(info), nil
+}
+
+func controlBackupResult(info BackupInfo) control.BackupResult {
+	created, _ := time.Parse(time.RFC3339, info.CreatedAt)
+	return control.BackupResult{Path: info.Path, CreatedAt: created}
+}
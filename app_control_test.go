@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestResolveControlNetwork(t *testing.T) {
+	a := newTestApp(t)
+	libera := makeAppTestNetwork(t, a.storage, "Libera")
+	makeAppTestNetwork(t, a.storage, "OFTC")
+
+	for _, ref := range []string{"libera", "LIBERA", " Libera "} {
+		got, err := a.resolveControlNetwork(ref)
+		if err != nil || got.ID != libera.ID {
+			t.Errorf("resolveControlNetwork(%q) = %v, %v; want network %d", ref, got, err, libera.ID)
+		}
+	}
+	if got, err := a.resolveControlNetwork(strconv.FormatInt(libera.ID, 10)); err != nil || got.ID != libera.ID {
+		t.Errorf("by ID: %v, %v", got, err)
+	}
+	if _, err := a.resolveControlNetwork("efnet"); err == nil {
+		t.Error("unknown network resolved")
+	}
+	if _, err := a.resolveControlNetwork(""); err == nil {
+		t.Error("empty reference resolved")
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/core"
+	"github.com/matt0x6f/irc-client/internal/logger"
+)
+
+const (
+	// settingRemoteCoreURL is the base URL of the always-on core this desktop
+	// app attaches to at launch; empty runs everything locally.
+	settingRemoteCoreURL = "core.remote.url"
+	// remoteCoreTokenSecret names the core's access token in the credential store.
+	remoteCoreTokenSecret = "core-remote-token"
+
+	remoteCoreProbeTimeout = 3 * time.Second
+)
+
+// RemoteCoreSettings describes the remote core configuration.
+type RemoteCoreSettings struct {
+	URL      string `json:"url"`      // core base URL; empty when running locally
+	HasToken bool   `json:"hasToken"` // an access token is stored (it is never returned)
+}
+
+// GetRemoteCore returns the configured remote core.
+func (a *App) GetRemoteCore() RemoteCoreSettings {
+	coreURL, _ := a.storage.GetSetting(settingRemoteCoreURL)
+	return RemoteCoreSettings{URL: coreURL, HasToken: a.creds.AppSecret(remoteCoreTokenSecret) != ""}
+}
+
+// SetRemoteCore points the desktop app at an always-on core, used from the
+// next launch. An empty token keeps the stored one; an empty URL goes back to
+// running locally and forgets the token.
+func (a *App) SetRemoteCore(coreURL, token string) error {
+	coreURL, token = strings.TrimSpace(coreURL), strings.TrimSpace(token)
+	if coreURL == "" {
+		return a.clearRemoteCore()
+	}
+	normalized, err := core.NormalizeURL(coreURL)
+	if err != nil {
+		return err
+	}
+	if token == "" && a.creds.AppSecret(remoteCoreTokenSecret) == "" {
+		return fmt.Errorf("an access token is required to attach to a core")
+	}
+	if token != "" {
+		if err := a.creds.StoreAppSecret(remoteCoreTokenSecret, token); err != nil {
+			return fmt.Errorf("store core token: %w", err)
+		}
+	}
+	if err := a.storage.SetSetting(settingRemoteCoreURL, normalized); err != nil {
+		return err
+	}
+	a.emit("setting:changed", map[string]string{"key": settingRemoteCoreURL, "value": normalized})
+	return nil
+}
+
+func (a *App) clearRemoteCore() error {
+	if err := a.storage.SetSetting(settingRemoteCoreURL, ""); err != nil {
+		return err
+	}
+	if err := a.creds.StoreAppSecret(remoteCoreTokenSecret, ""); err != nil {
+		return fmt.Errorf("forget core token: %w", err)
+	}
+	a.emit("setting:changed", map[string]string{"key": settingRemoteCoreURL, "value": ""})
+	return nil
+}
+
+// remoteCoreConfigured reports whether a core is configured. The core owns the
+// IRC connections then, so this app never auto-connects its own networks,
+// even when the core could not be reached at launch.
+func (a *App) remoteCoreConfigured() bool {
+	if headlessBuild {
+		return false
+	}
+	coreURL, _ := a.storage.GetSetting(settingRemoteCoreURL)
+	return coreURL != ""
+}
+
+// remoteCoreAttachURL returns the address the main window should load to
+// attach to the configured core, or "" to show the local UI: nothing is
+// configured, or the core did not answer its health check, in which case the
+// local UI stays usable for detaching.
+func (a *App) remoteCoreAttachURL() string {
+	settings := a.GetRemoteCore()
+	if settings.URL == "" {
+		return ""
+	}
+	attach, err := core.AttachURL(settings.URL, a.creds.AppSecret(remoteCoreTokenSecret))
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("core", settings.URL).Msg("Remote core is misconfigured; running locally")
+		return ""
+	}
+	client := http.Client{Timeout: remoteCoreProbeTimeout}
+	resp, err := client.Get(settings.URL + "/health")
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("core", settings.URL).Msg("Remote core unreachable; showing the local UI")
+		return ""
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		logger.Log.Warn().Int("status", resp.StatusCode).Str("core", settings.URL).Msg("Remote core unhealthy; showing the local UI")
+		return ""
+	}
+	logger.Log.Info().Str("core", settings.URL).Msg("Attaching to remote core")
+	return attach
+}
+
+// detachRemoteCore forgets the remote core, points the main window back at the
+// bundled UI and starts the local networks.
+func (a *App) detachRemoteCore() {
+	if err := a.clearRemoteCore(); err != nil {
+		logger.Log.Warn().Err(err).Msg("Failed to clear remote core settings")
+		return
+	}
+	a.remoteCore = ""
+	if a.notifyWindow != nil {
+		a.notifyWindow.SetURL("/")
+	}
+	logger.Log.Info().Msg("Detached from remote core; starting local networks")
+	a.startLocalCore()
+}
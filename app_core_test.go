@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetRemoteCore(t *testing.T) {
+	a := newCredsTestApp(t)
+	a.emitFn = func(string, ...any) {}
+
+	if err := a.SetRemoteCore("https://core.example:7667/", ""); err == nil {
+		t.Fatal("first attach without a token accepted")
+	}
+	if err := a.SetRemoteCore("https://core.example:7667/", "tok"); err != nil {
+		t.Fatalf("SetRemoteCore: %v", err)
+	}
+	got := a.GetRemoteCore()
+	if got.URL != "https://core.example:7667" || !got.HasToken {
+		t.Fatalf("GetRemoteCore = %+v, want normalized URL with a stored token", got)
+	}
+	// A blank token keeps the stored one when only the URL changes.
+	if err := a.SetRemoteCore("https://core2.example", ""); err != nil || a.creds.AppSecret(remoteCoreTokenSecret) != "tok" {
+		t.Fatalf("re-point kept token %q (err=%v)", a.creds.AppSecret(remoteCoreTokenSecret), err)
+	}
+	if !a.remoteCoreConfigured() {
+		t.Error("remoteCoreConfigured = false with a core set")
+	}
+
+	if err := a.SetRemoteCore("", ""); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if got := a.GetRemoteCore(); got.URL != "" || got.HasToken || a.remoteCoreConfigured() {
+		t.Errorf("after clear = %+v, want running locally with no token", got)
+	}
+}
+
+func TestRemoteCoreAttachURLNeedsHealthyCore(t *testing.T) {
+	a := newCredsTestApp(t)
+	a.emitFn = func(string, ...any) {}
+	healthy := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy || r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	if err := a.SetRemoteCore(srv.URL, "tok"); err != nil {
+		t.Fatalf("SetRemoteCore: %v", err)
+	}
+
+	if got := a.remoteCoreAttachURL(); !strings.HasPrefix(got, srv.URL+"/core/attach?token=tok") {
+		t.Errorf("attach URL = %q", got)
+	}
+	healthy = false
+	if got := a.remoteCoreAttachURL(); got != "" {
+		t.Errorf("attach URL for an unhealthy core = %q, want the local UI", got)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/logger"
+)
+
+// settingCTCPResponder holds the CTCP responder configuration as JSON.
+const settingCTCPResponder = "ctcp.responder"
+
+// ctcpSettings loads the responder configuration, falling back to the defaults
+// when none is stored or it no longer validates. $version always reflects this
+// build.
+func (a *App) ctcpSettings() irc.CTCPSettings {
+	settings := irc.DefaultCTCPSettings()
+	if value, _ := a.storage.GetSetting(settingCTCPResponder); strings.TrimSpace(value) != "" {
+		var stored irc.CTCPSettings
+		if err := json.Unmarshal([]byte(value), &stored); err != nil {
+			logger.Log.Warn().Err(err).Msg("Ignoring unreadable CTCP settings")
+		} else if err := irc.ValidateCTCPSettings(stored); err != nil {
+			logger.Log.Warn().Err(err).Msg("Ignoring invalid CTCP settings")
+		} else {
+			settings = stored
+		}
+	}
+	if settings.Disabled == nil {
+		settings.Disabled = []string{}
+	}
+	if settings.Replies == nil {
+		settings.Replies = map[string]string{}
+	}
+	settings.Version = version
+	return settings
+}
+
+func (a *App) GetCTCPSettings() irc.CTCPSettings { return a.ctcpSettings() }
+
+// UpdateCTCPSettings stores the responder configuration and applies it to
+// every connected network.
+func (a *App) UpdateCTCPSettings(settings irc.CTCPSettings) error {
+	if err := irc.ValidateCTCPSettings(settings); err != nil {
+		return err
+	}
+	for i, command := range settings.Disabled {
+		settings.Disabled[i] = strings.ToUpper(strings.TrimSpace(command))
+	}
+	replies := make(map[string]string, len(settings.Replies))
+	for command, template := range settings.Replies {
+		replies[strings.ToUpper(command)] = template
+	}
+	settings.Replies = replies
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	if err := a.storage.SetSetting(settingCTCPResponder, string(encoded)); err != nil {
+		return err
+	}
+	a.emit("setting:changed", map[string]string{"key": settingCTCPResponder, "value": string(encoded)})
+
+	settings.Version = version
+	a.mu.RLock()
+	for _, client := range a.ircClients {
+		client.SetCTCPSettings(settings)
+	}
+	a.mu.RUnlock()
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -26,6 +27,11 @@ const (
 	settingFileTransfersAddress    = "fileTransfers.advertisedAddress"
 	settingFileTransfersPortMin    = "fileTransfers.portMin"
 	settingFileTransfersPortMax    = "fileTransfers.portMax"
+	settingFileTransfersRateLimit  = "fileTransfers.transferRateLimit"
+	settingFileTransfersGlobalRate = "fileTransfers.globalRateLimit"
+	settingFileTransfersAutoAccept = "fileTransfers.autoAccept"
+	settingFileTransfersMaxSends   = "fileTransfers.maxConcurrentSends"
+	settingFileTransfersPeerSends  = "fileTransfers.maxSendsPerPeer"
 	settingDirectChatEnabled       = "directChat.enabled"
 	fileTransferHistoryDefaultPage = 50
 	fileTransferHistoryMaxPage     = 200
@@ -68,6 +74,7 @@ func (a *App) initializeFileTransfers() error {
 	if err != nil {
 		return err
 	}
+	manager.SetChatHandlers(a.handleDirectChatLine, a.handleDirectChatEvent)
 	a.dccManager = manager
 	rows, err := a.storage.ListActiveFileTransfers()
 	if err != nil {
@@ -98,7 +105,7 @@ func (a *App) loadFileTransferSettings() dcc.Settings {
 	}
 	settings := dcc.Settings{
 		Enabled: true, DownloadDirectory: downloads, HistoryRetention: dcc.HistoryForever,
-		ConnectionMode: dcc.ConnectionAutomatic,
+		ConnectionMode: dcc.ConnectionAutomatic, DirectChat: true,
 	}
 	if get(settingFileTransfersEnabled) == "false" {
 		settings.Enabled = false
@@ -115,9 +122,20 @@ func (a *App) loadFileTransferSettings() dcc.Settings {
 	settings.AdvertisedAddress = get(settingFileTransfersAddress)
 	settings.PortMin, _ = strconv.Atoi(get(settingFileTransfersPortMin))
 	settings.PortMax, _ = strconv.Atoi(get(settingFileTransfersPortMax))
+	settings.DirectChat = get(settingDirectChatEnabled) != "false"
+	settings.TransferRateLimit, _ = strconv.ParseInt(get(settingFileTransfersRateLimit), 10, 64)
+	settings.GlobalRateLimit, _ = strconv.ParseInt(get(settingFileTransfersGlobalRate), 10, 64)
+	settings.MaxConcurrentSends, _ = strconv.Atoi(get(settingFileTransfersMaxSends))
+	settings.MaxSendsPerPeer, _ = strconv.Atoi(get(settingFileTransfersPeerSends))
+	if value := get(settingFileTransfersAutoAccept); value != "" {
+		if err := json.Unmarshal([]byte(value), &settings.AutoAccept); err != nil {
+			logger.Log.Warn().Err(err).Msg("Ignoring unreadable auto-accept policy")
+			settings.AutoAccept = dcc.AutoAcceptPolicy{}
+		}
+	}
 	if err := dcc.ValidateSettings(settings); err != nil {
 		logger.Log.Warn().Err(err).Msg("Ignoring invalid file transfer settings")
-		settings = dcc.Settings{Enabled: true, DownloadDirectory: downloads, HistoryRetention: dcc.HistoryForever, ConnectionMode: dcc.ConnectionAutomatic}
+		settings = dcc.Settings{Enabled: true, DownloadDirectory: downloads, HistoryRetention: dcc.HistoryForever, ConnectionMode: dcc.ConnectionAutomatic, DirectChat: true}
 	}
 	return settings
 }
@@ -130,6 +148,9 @@ func (a *App) handleFileTransferEvent(event dcc.Event) {
 	t := event.Transfer
 	switch t.Status {
 	case dcc.StatusOffered:
+		if a.autoAcceptFileTransfer(t) {
+			return
+		}
 		a.sendNotification(notification.Notification{
 			ID: newNotificationID(), Title: fmt.Sprintf("%s wants to send you a file", t.Peer),
 			Body:       fmt.Sprintf("%s · %s", t.Filename, formatByteCount(t.TotalBytes)),
@@ -234,9 +255,70 @@ func (a *App) AcceptFileTransfer(id string) error {
 	return a.dccManager.Accept(id, path)
 }
 
+// autoAcceptFileTransfer accepts an offer the auto-accept policy allows,
+// saving into the download directory without overwriting anything.
+func (a *App) autoAcceptFileTransfer(t *dcc.View) bool {
+	settings := a.dccManager.Settings()
+	policy := settings.AutoAccept
+	if policy.Mode != dcc.AutoAcceptPeers && policy.Mode != dcc.AutoAcceptBuddies {
+		return false
+	}
+	buddy := policy.Mode == dcc.AutoAcceptBuddies && a.isBuddy(t.NetworkID, t.Peer)
+	if !policy.Allows(t.Peer, t.Filename, t.TotalBytes, buddy) {
+		return false
+	}
+	full, ok := a.dccManager.Get(t.ID)
+	if !ok {
+		return false
+	}
+	destination := full.LocalPath
+	if full.PartialPath == "" {
+		destination = availableDownloadPath(settings.DownloadDirectory, t.Filename)
+	}
+	if err := a.dccManager.Accept(t.ID, destination); err != nil {
+		logger.Log.Warn().Err(err).Str("peer", t.Peer).Msg("Auto-accept of file transfer failed")
+		return false
+	}
+	return true
+}
+
+// availableDownloadPath returns dir/name, or "name (n).ext" when that is taken.
+func availableDownloadPath(dir, name string) string {
+	candidate := filepath.Join(dir, name)
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 1; n < 1000; n++ {
+		if _, err := os.Lstat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+		candidate = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, n, ext))
+	}
+	return candidate
+}
+
+// CompareFileTransferChecksum reports whether expected, a SHA-256 digest the
+// other side shared, matches the digest recorded when the transfer completed.
+func (a *App) CompareFileTransferChecksum(id, expected string) (bool, error) {
+	t, err := a.lookupFileTransfer(id)
+	if err != nil {
+		return false, err
+	}
+	if t.SHA256 == "" {
+		return false, fmt.Errorf("no checksum was recorded for this transfer")
+	}
+	return strings.EqualFold(strings.TrimSpace(expected), t.SHA256), nil
+}
+
 func (a *App) DeclineFileTransfer(id string) error { return a.dccManager.Decline(id) }
 func (a *App) CancelFileTransfer(id string) error  { return a.dccManager.Cancel(id) }
 func (a *App) RetryFileTransfer(id string) error   { return a.dccManager.Retry(id) }
+func (a *App) PauseFileTransfer(id string) error   { return a.dccManager.PauseOutgoing(id) }
+func (a *App) ResumeFileTransfer(id string) error  { return a.dccManager.ResumeOutgoing(id) }
+
+// MoveFileTransfer moves a queued send to a 1-based position in the queue.
+func (a *App) MoveFileTransfer(id string, position int) error {
+	return a.dccManager.MoveOutgoing(id, position)
+}
 func (a *App) DiscardPartialFileTransfer(id string) error {
 	return a.dccManager.DiscardPartial(id)
 }
@@ -269,11 +351,18 @@ func (a *App) UpdateFileTransferSettings(settings dcc.Settings, cancelActive boo
 	if err := a.dccManager.UpdateSettings(settings, cancelActive); err != nil {
 		return err
 	}
+	policy, err := json.Marshal(settings.AutoAccept)
+	if err != nil {
+		return err
+	}
 	values := map[string]string{
 		settingFileTransfersEnabled: boolString(settings.Enabled), settingFileTransfersDirectory: settings.DownloadDirectory,
 		settingFileTransfersRetention: string(settings.HistoryRetention), settingFileTransfersMode: string(settings.ConnectionMode),
 		settingFileTransfersAddress: settings.AdvertisedAddress, settingFileTransfersPortMin: strconv.Itoa(settings.PortMin),
-		settingFileTransfersPortMax: strconv.Itoa(settings.PortMax),
+		settingFileTransfersPortMax: strconv.Itoa(settings.PortMax), settingDirectChatEnabled: boolString(settings.DirectChat),
+		settingFileTransfersRateLimit: strconv.FormatInt(settings.TransferRateLimit, 10), settingFileTransfersGlobalRate: strconv.FormatInt(settings.GlobalRateLimit, 10),
+		settingFileTransfersAutoAccept: string(policy),
+		settingFileTransfersMaxSends:   strconv.Itoa(settings.MaxConcurrentSends), settingFileTransfersPeerSends: strconv.Itoa(settings.MaxSendsPerPeer),
 	}
 	for key, value := range values {
 		if err := a.storage.SetSetting(key, value); err != nil {
@@ -377,6 +466,7 @@ func dccTransferToStorage(t dcc.Transfer) storage.FileTransfer {
 		PartialPath: t.PartialPath, SizeBytes: t.TotalBytes, TransferredBytes: t.TransferredBytes,
 		State: string(t.Status), Error: t.Error, Resumable: t.Resumable,
 		CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt, FinishedAt: t.CompletedAt,
+		SHA256: t.SHA256, QueuePosition: t.QueuePosition,
 	}
 }
 
@@ -391,6 +481,7 @@ func storageTransferToDCC(t storage.FileTransfer) dcc.Transfer {
 		PartialPath: t.PartialPath, TotalBytes: t.SizeBytes, TransferredBytes: t.TransferredBytes,
 		Status: dcc.Status(t.State), Error: t.Error, Resumable: t.Resumable,
 		CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt, CompletedAt: t.FinishedAt,
+		SHA256: t.SHA256, QueuePosition: t.QueuePosition,
 	}
 }
 
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/dcc"
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/notification"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// directChatPrefix marks a direct chat buffer. Other clients use the same
+// "=nick" form, and "=" cannot start a nickname, so these buffers never
+// collide with an ordinary private conversation.
+const directChatPrefix = "="
+
+func directChatTarget(peer string) string { return directChatPrefix + peer }
+
+// directChatPeer returns the peer behind a direct chat buffer name.
+func directChatPeer(target string) (string, bool) {
+	peer, ok := strings.CutPrefix(target, directChatPrefix)
+	return peer, ok && peer != ""
+}
+
+func (a *App) GetDirectChats() []dcc.ChatView {
+	if a.dccManager == nil {
+		return []dcc.ChatView{}
+	}
+	return a.dccManager.ChatSnapshot()
+}
+
+// OpenDirectChat offers a direct chat to peer, or accepts their pending offer,
+// and opens the "=peer" buffer.
+func (a *App) OpenDirectChat(networkID int64, peer string) (dcc.ChatView, error) {
+	peer = strings.TrimSpace(peer)
+	if peer == "" {
+		return dcc.ChatView{}, fmt.Errorf("nickname is required")
+	}
+	network, err := a.storage.GetNetwork(networkID)
+	if err != nil {
+		return dcc.ChatView{}, err
+	}
+	view, err := a.dccManager.OpenChat(networkID, network.Name, peer)
+	if err != nil {
+		return dcc.ChatView{}, err
+	}
+	if err := a.openDirectChatBuffer(networkID, peer); err != nil {
+		return view, err
+	}
+	return view, nil
+}
+
+func (a *App) AcceptDirectChat(id string) error {
+	chat, ok := a.dccManager.GetChat(id)
+	if !ok {
+		return fmt.Errorf("direct chat offer was not found")
+	}
+	if err := a.dccManager.AcceptChat(id); err != nil {
+		return err
+	}
+	return a.openDirectChatBuffer(chat.NetworkID, chat.Peer)
+}
+
+func (a *App) DeclineDirectChat(id string) error { return a.dccManager.DeclineChat(id) }
+func (a *App) CloseDirectChat(id string) error   { return a.dccManager.CloseChat(id) }
+
+// sendDirectChat writes to the peer's socket and records our own line, since
+// there is no server echo for direct chat.
+func (a *App) sendDirectChat(networkID int64, peer, message string, action bool) error {
+	chat, ok := a.dccManager.FindChat(networkID, peer)
+	if !ok {
+		return fmt.Errorf("no direct chat with %s", peer)
+	}
+	if err := a.dccManager.SendChat(chat.ID, message, action); err != nil {
+		return err
+	}
+	nick, _ := a.GetCurrentNick(networkID)
+	if nick == "" {
+		if network, err := a.storage.GetNetwork(networkID); err == nil {
+			nick = network.Nickname
+		}
+	}
+	return a.writeDirectChatMessage(networkID, peer, nick, message, action)
+}
+
+func (a *App) handleDirectChatLine(chat dcc.ChatSession, text string, action bool) {
+	if err := a.writeDirectChatMessage(chat.NetworkID, chat.Peer, chat.Peer, text, action); err != nil {
+		logger.Log.Warn().Err(err).Str("peer", chat.Peer).Msg("Failed to store direct chat line")
+	}
+}
+
+func (a *App) handleDirectChatEvent(event dcc.ChatEvent) {
+	a.emit("direct-chat:event", event)
+	if event.Chat == nil {
+		return
+	}
+	chat := event.Chat
+	var line string
+	switch chat.Status {
+	case dcc.StatusOffered:
+		a.sendNotification(notification.Notification{
+			ID: newNotificationID(), Title: fmt.Sprintf("%s wants to chat directly", chat.Peer),
+			Body:       fmt.Sprintf("Use /dcc chat %s to accept", chat.Peer),
+			CategoryID: notifyCategoryTransfer,
+			Data:       map[string]any{"networkId": strconv.FormatInt(chat.NetworkID, 10), "target": directChatTarget(chat.Peer), "kind": "direct-chat"},
+		})
+		line = fmt.Sprintf("%s offered a direct chat. Use /dcc chat %s to accept.", chat.Peer, chat.Peer)
+		_ = a.PrintLocalLines(chat.NetworkID, "status", []string{line})
+		return
+	case dcc.StatusNegotiating:
+		line = fmt.Sprintf("Offering a direct chat to %s…", chat.Peer)
+	case dcc.StatusConnected:
+		line = fmt.Sprintf("Direct chat with %s connected. Messages here bypass the IRC server.", chat.Peer)
+	case dcc.StatusClosed:
+		line = fmt.Sprintf("Direct chat with %s closed.", chat.Peer)
+	case dcc.StatusFailed:
+		line = fmt.Sprintf("Direct chat with %s failed: %s", chat.Peer, chat.Error)
+	default:
+		return
+	}
+	_ = a.PrintLocalLines(chat.NetworkID, directChatTarget(chat.Peer), []string{line})
+}
+
+func (a *App) openDirectChatBuffer(networkID int64, peer string) error {
+	network, err := a.storage.GetNetwork(networkID)
+	if err != nil {
+		return err
+	}
+	if _, _, err := a.storage.GetOrCreatePMConversation(networkID, directChatTarget(peer), network.Nickname); err != nil {
+		return fmt.Errorf("failed to create direct chat buffer: %w", err)
+	}
+	return a.SetPrivateMessageOpen(networkID, directChatTarget(peer), true)
+}
+
+func (a *App) writeDirectChatMessage(networkID int64, peer, user, text string, action bool) error {
+	msgType := "privmsg"
+	if action {
+		msgType = "action"
+	}
+	target := directChatTarget(peer)
+	if err := a.storage.WriteMessageSync(storage.Message{
+		NetworkID: networkID, User: user, Message: text, MessageType: msgType,
+		Timestamp: time.Now(), RawLine: text, PMTarget: target,
+	}); err != nil {
+		return err
+	}
+	a.emit("message-event", map[string]interface{}{
+		"type":      string(irc.EventMessageReceived),
+		"data":      map[string]interface{}{"networkId": networkID, "channel": target},
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+	return nil
+}
@@ -68,3 +68,24 @@ func TestClearFileTransferHistoryLeavesCompletedFile(t *testing.T) {
 		t.Fatalf("history was not cleared: page=%+v err=%v", page, err)
 	}
 }
+
+func TestDirectChatLinesStayOffTheServer(t *testing.T) {
+	a := newFileTransferTestApp(t)
+	a.emitFn = func(string, ...any) {}
+	network := makeAppTestNetwork(t, a.storage, "DirectChat")
+
+	a.handleDirectChatLine(dcc.ChatSession{NetworkID: network.ID, Peer: "alice"}, "psst", false)
+	messages, err := a.storage.GetPrivateMessages(network.ID, directChatTarget("alice"), network.Nickname, 10)
+	if err != nil || len(messages) != 1 || messages[0].Message != "psst" || messages[0].User != "alice" {
+		t.Fatalf("direct chat line was not stored in its buffer: %+v, %v", messages, err)
+	}
+	if plain, _ := a.storage.GetPrivateMessages(network.ID, "alice", network.Nickname, 10); len(plain) != 0 {
+		t.Fatalf("direct chat line leaked into the ordinary query: %+v", plain)
+	}
+
+	// No IRC client exists for this network; a send to the direct chat buffer
+	// must fail on the missing session rather than reach the server path.
+	if err := a.SendMessage(network.ID, directChatTarget("alice"), "hello"); err == nil || err.Error() != "no direct chat with alice" {
+		t.Fatalf("SendMessage to direct chat = %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/matt0x6f/irc-client/internal/e2e"
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/security"
+)
+
+// GetEncryptionState reports how messages to target are protected on the
+// network: an end-to-end session, one waiting to be accepted, a FiSH key, or
+// nothing. Mode is "" while disconnected.
+func (a *App) GetEncryptionState(networkID int64, target string) (irc.EncryptionState, error) {
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if !exists || !client.IsConnected() {
+		return irc.EncryptionState{}, nil
+	}
+	return client.EncryptionState(target), nil
+}
+
+// StartEncryption offers nick an end-to-end encrypted conversation, or accepts
+// the one they offered. Messages are encrypted once the handshake completes;
+// the "encryption-changed" event tells the frontend when that happens.
+func (a *App) StartEncryption(networkID int64, nick string) error {
+	client, err := a.encryptionClient(networkID)
+	if err != nil {
+		return err
+	}
+	return client.StartEncryption(nick)
+}
+
+// StopEncryption ends the end-to-end session with nick.
+func (a *App) StopEncryption(networkID int64, nick string) error {
+	client, err := a.encryptionClient(networkID)
+	if err != nil {
+		return err
+	}
+	return client.StopEncryption(nick)
+}
+
+// VerifyEncryption records the fingerprint of nick's current session as the
+// one the user checked with them, so a later session under a different key is
+// flagged. The fingerprints are kept in the credential store.
+func (a *App) VerifyEncryption(networkID int64, nick string) error {
+	client, err := a.encryptionClient(networkID)
+	if err != nil {
+		return err
+	}
+	fp := client.EncryptionState(nick).PeerFingerprint
+	if fp == "" {
+		return fmt.Errorf("no encrypted conversation with %s to verify", nick)
+	}
+	trusted := a.secretMap(networkID, security.FieldE2ETrusted)
+	a.setFoldedEntry(networkID, trusted, nick, fp)
+	if err := a.storeSecretMap(networkID, security.FieldE2ETrusted, trusted); err != nil {
+		return fmt.Errorf("store verified fingerprint: %w", err)
+	}
+	client.SetTrustedFingerprint(nick, fp)
+	return nil
+}
+
+// SetFishKey sets the FiSH key for a channel or nick, in the "cbc:<key>" form
+// other FiSH clients use; an empty key removes it. Keys are kept in the
+// credential store.
+func (a *App) SetFishKey(networkID int64, target, key string) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return fmt.Errorf("a channel or nick is required")
+	}
+	if key != "" {
+		parsed, err := e2e.ParseFishKey(key)
+		if err != nil {
+			return err
+		}
+		key = parsed
+	}
+	keys := a.secretMap(networkID, security.FieldFishKeys)
+	a.setFoldedEntry(networkID, keys, target, key)
+	if err := a.storeSecretMap(networkID, security.FieldFishKeys, keys); err != nil {
+		return fmt.Errorf("store FiSH key: %w", err)
+	}
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if exists {
+		client.SetFishKey(target, key)
+	}
+	return nil
+}
+
+// encryptionClient returns the network's connected client.
+func (a *App) encryptionClient(networkID int64) (*irc.IRCClient, error) {
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if !exists || !client.IsConnected() {
+		return nil, fmt.Errorf("network %d is not connected", networkID)
+	}
+	return client, nil
+}
+
+// encryptionKeys loads what a new client needs from the credential store: the
+// network's identity key (generated on first use), the verified fingerprints
+// and the FiSH keys.
+func (a *App) encryptionKeys(networkID int64) (e2e.Identity, map[string]string, map[string]string) {
+	identity, err := e2e.ParseIdentity(a.creds.Resolve(networkID, security.FieldE2EIdentity, ""))
+	if err != nil {
+		if identity, err = e2e.NewIdentity(); err != nil {
+			logger.Log.Warn().Err(err).Int64("network_id", networkID).Msg("Failed to generate an E2E identity key")
+		} else if used, err := a.creds.Store(networkID, security.FieldE2EIdentity, identity.Encode()); !used {
+			// Still usable for this session; the fingerprint just won't survive a restart.
+			logger.Log.Warn().Err(err).Int64("network_id", networkID).Msg("Failed to store the E2E identity key")
+		}
+	}
+	return identity, a.secretMap(networkID, security.FieldE2ETrusted), a.secretMap(networkID, security.FieldFishKeys)
+}
+
+// setFoldedEntry sets m[name] to value, keyed the way the network's client
+// folds names (its CASEMAPPING) so the client finds it, and drops any entry
+// stored under another spelling of the same name. An empty value deletes.
+func (a *App) setFoldedEntry(networkID int64, m map[string]string, name, value string) {
+	key := a.nickKey(networkID, name)
+	for k := range m {
+		if a.nickKey(networkID, k) == key {
+			delete(m, k)
+		}
+	}
+	if value != "" {
+		m[key] = value
+	}
+}
+
+// secretMap reads a JSON object kept in one credential field; a missing or
+// unreadable one is empty.
+func (a *App) secretMap(networkID int64, field string) map[string]string {
+	m := map[string]string{}
+	if raw := a.creds.Resolve(networkID, field, ""); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			logger.Log.Warn().Err(err).Int64("network_id", networkID).Str("field", field).Msg("Ignoring unreadable credential map")
+			m = map[string]string{}
+		}
+	}
+	return m
+}
+
+func (a *App) storeSecretMap(networkID int64, field string, m map[string]string) error {
+	if a.creds == nil {
+		return fmt.Errorf("no credential store is available")
+	}
+	value := ""
+	if len(m) > 0 {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		value = string(b)
+	}
+	_, err := a.creds.Store(networkID, field, value)
+	return err
+}
+
+// cmdE2E implements /e2e [start|stop|verify|status] [nickname]. In a query
+// the nick defaults to the peer.
+func cmdE2E(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	action := "status"
+	if len(args) > 0 {
+		action, args = strings.ToLower(args[0]), args[1:]
+	}
+	nick := buffer
+	if len(args) > 0 {
+		nick = args[0]
+	}
+	if nick == "" || nick == "status" || client.IsChannelName(nick) || len(args) > 1 {
+		return fmt.Errorf("usage: /e2e [start|stop|verify|status] [nickname] (or run it in a query)")
+	}
+
+	switch action {
+	case "start":
+		return a.StartEncryption(networkID, nick)
+	case "stop":
+		return a.StopEncryption(networkID, nick)
+	case "verify":
+		if err := a.VerifyEncryption(networkID, nick); err != nil {
+			return err
+		}
+		return a.PrintLocalLines(networkID, buffer, []string{fmt.Sprintf("Marked %s's key as verified.", nick)})
+	case "status":
+		st := client.EncryptionState(nick)
+		lines := []string{fmt.Sprintf("Your fingerprint: %s", st.Fingerprint)}
+		switch st.Mode {
+		case irc.EncryptionE2E:
+			verified := "not verified"
+			if st.Verified {
+				verified = "verified"
+			}
+			lines = append(lines, fmt.Sprintf("Messages with %s are end-to-end encrypted. Their fingerprint: %s (%s).", nick, st.PeerFingerprint, verified))
+		case irc.EncryptionBroken:
+			lines = append(lines, fmt.Sprintf("The encrypted conversation with %s is broken, so nothing is sent to them. Run /e2e start %s to start a new one or /e2e stop %s to go back to plain text.", nick, nick, nick))
+		case irc.EncryptionPending:
+			lines = append(lines, fmt.Sprintf("Waiting for %s to accept an encrypted conversation.", nick))
+		case irc.EncryptionOffered:
+			lines = append(lines, fmt.Sprintf("%s offers an encrypted conversation. Their fingerprint: %s. Run /e2e start %s to accept.", nick, st.OfferFingerprint, nick))
+		case irc.EncryptionFish:
+			lines = append(lines, fmt.Sprintf("Messages with %s are encrypted with a FiSH key.", nick))
+		default:
+			lines = append(lines, fmt.Sprintf("Messages with %s are not encrypted.", nick))
+		}
+		if (st.Mode == irc.EncryptionE2E || st.Mode == irc.EncryptionBroken) && st.OfferFingerprint != "" {
+			lines = append(lines, fmt.Sprintf("%s asks to start a new encrypted conversation. Their fingerprint: %s. Run /e2e start %s to accept.", nick, st.OfferFingerprint, nick))
+		}
+		return a.PrintLocalLines(networkID, buffer, lines)
+	}
+	return fmt.Errorf("usage: /e2e [start|stop|verify|status] [nickname]")
+}
+
+// cmdSetKey implements /setkey [#channel|nickname] cbc:key.
+func cmdSetKey(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	target := buffer
+	if len(args) > 1 {
+		target, args = args[0], args[1:]
+	}
+	if target == "" || target == "status" || len(args) != 1 {
+		return fmt.Errorf("usage: /setkey [#channel|nickname] cbc:key")
+	}
+	if err := a.SetFishKey(networkID, target, args[0]); err != nil {
+		return err
+	}
+	return a.PrintLocalLines(networkID, buffer, []string{fmt.Sprintf("Messages with %s are now encrypted with FiSH.", target)})
+}
+
+// cmdDelKey implements /delkey [#channel|nickname].
+func cmdDelKey(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	target := buffer
+	if len(args) > 0 {
+		target = args[0]
+	}
+	if target == "" || target == "status" {
+		return fmt.Errorf("usage: /delkey [#channel|nickname]")
+	}
+	if err := a.SetFishKey(networkID, target, ""); err != nil {
+		return err
+	}
+	return a.PrintLocalLines(networkID, buffer, []string{fmt.Sprintf("Removed the FiSH key for %s.", target)})
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/matt0x6f/irc-client/internal/security"
+)
+
+func TestEncryptionKeysAreKeptInCredentialStore(t *testing.T) {
+	a := newCredsTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "CryptoNet")
+
+	identity, trusted, fish := a.encryptionKeys(net.ID)
+	if !identity.Valid() || len(trusted) != 0 || len(fish) != 0 {
+		t.Fatalf("first load: identity valid=%v, trusted=%v, fish=%v", identity.Valid(), trusted, fish)
+	}
+	again, _, _ := a.encryptionKeys(net.ID)
+	if again.Fingerprint() != identity.Fingerprint() {
+		t.Fatal("the identity key was not kept between connections")
+	}
+
+	if err := a.SetFishKey(net.ID, "#Secret", "ecb:old"); err == nil {
+		t.Error("SetFishKey accepted an ECB key")
+	}
+	if err := a.SetFishKey(net.ID, "#Secret", "cbc:s3cret"); err != nil {
+		t.Fatalf("SetFishKey: %v", err)
+	}
+	if _, _, fish = a.encryptionKeys(net.ID); fish["#secret"] != "s3cret" {
+		t.Fatalf("fish keys = %v", fish)
+	}
+	if err := a.SetFishKey(net.ID, "#secret", ""); err != nil {
+		t.Fatalf("SetFishKey(remove): %v", err)
+	}
+	if a.creds.Resolve(net.ID, security.FieldFishKeys, "") != "" {
+		t.Error("removing the last FiSH key left the credential behind")
+	}
+
+	// Keys are folded as the client folds names: under rfc1459, the default,
+	// [nick] and {nick} are one nick.
+	if err := a.SetFishKey(net.ID, "[Dan]", "cbc:brackets"); err != nil {
+		t.Fatalf("SetFishKey: %v", err)
+	}
+	if err := a.SetFishKey(net.ID, "{dan}", "cbc:braces"); err != nil {
+		t.Fatalf("SetFishKey: %v", err)
+	}
+	if _, _, fish = a.encryptionKeys(net.ID); len(fish) != 1 || fish["{dan}"] != "braces" {
+		t.Fatalf("fish keys after folding = %v", fish)
+	}
+
+	if err := a.creds.Delete(net.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if a.creds.Resolve(net.ID, security.FieldE2EIdentity, "") != "" {
+		t.Error("deleting the network kept its identity key")
+	}
+}
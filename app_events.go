@@ -31,12 +31,16 @@ var appForwardedEventTypes = []string{
 	irc.EventUserParted,
 	irc.EventUserQuit,
 	irc.EventUserKicked,
+	irc.EventNetsplit,
+	irc.EventNetjoin,
+	irc.EventMessageUnfiltered,
 	irc.EventUserNick,
 	irc.EventNickChanged,
 	irc.EventChannelTopic,
 	irc.EventChannelMode,
 	irc.EventChannelUserMode,
 	irc.EventChannelBanList,
+	irc.EventChannelListMode,
 	irc.EventError,
 	irc.EventChannelNamesComplete,
 	irc.EventWhoisReceived,
@@ -47,6 +51,8 @@ var appForwardedEventTypes = []string{
 	irc.EventMonitorChanged,
 	irc.EventUserMetaChanged,
 	irc.EventSASLFailed,
+	irc.EventAccountRegistration,
+	irc.EventEncryptionChanged,
 	irc.EventSTSPolicy,
 	irc.EventInviteReceived,
 	irc.EventStatusMessage,
@@ -87,6 +93,10 @@ func (a *App) OnEvent(event events.Event) {
 		networkID, found := a.resolveNetworkID(event.Data)
 		if found {
 			isConnected := event.Type == irc.EventConnectionEstablished
+			if a.dccManager != nil {
+				// Queued sends for this network wait while it is offline.
+				a.dccManager.SetNetworkOnline(networkID, isConnected)
+			}
 			a.emit("connection-status", map[string]interface{}{
 				"networkId": networkID,
 				"connected": isConnected,
@@ -121,6 +131,34 @@ func (a *App) OnEvent(event events.Event) {
 		return
 	}
 
+	// Account registration answered: the settings form shows the outcome and,
+	// when the server wants one, asks for the verification code.
+	if event.Type == irc.EventAccountRegistration {
+		networkID, found := a.resolveNetworkID(event.Data)
+		if found {
+			a.emit("account-registration", map[string]interface{}{
+				"networkId": networkID,
+				"status":    event.Data["status"],
+				"account":   event.Data["account"],
+				"code":      event.Data["code"],
+				"message":   event.Data["message"],
+			})
+		}
+		return
+	}
+
+	// A conversation's encryption changed: the query header refreshes its lock.
+	if event.Type == irc.EventEncryptionChanged {
+		networkID, found := a.resolveNetworkID(event.Data)
+		if found {
+			a.emit("encryption-changed", map[string]interface{}{
+				"networkId": networkID,
+				"target":    event.Data["target"],
+			})
+		}
+		return
+	}
+
 	// Handle IRCv3 STS policy advertisements (plaintext→TLS upgrade or trusted persist)
 	if event.Type == irc.EventSTSPolicy {
 		a.handleSTSPolicy(event)
@@ -173,8 +211,10 @@ func (a *App) OnEvent(event events.Event) {
 	if event.Type == irc.EventMessageSent || event.Type == irc.EventMessageReceived ||
 		event.Type == irc.EventUserJoined || event.Type == irc.EventUserParted || event.Type == irc.EventUserQuit ||
 		event.Type == irc.EventUserKicked || event.Type == irc.EventUserNick ||
+		event.Type == irc.EventNetsplit || event.Type == irc.EventNetjoin || event.Type == irc.EventMessageUnfiltered ||
 		event.Type == irc.EventChannelTopic || event.Type == irc.EventChannelMode ||
 		event.Type == irc.EventChannelUserMode || event.Type == irc.EventChannelBanList ||
+		event.Type == irc.EventChannelListMode ||
 		event.Type == irc.EventError ||
 		event.Type == irc.EventStatusMessage {
 		a.emit("message-event", map[string]interface{}{
@@ -213,18 +253,14 @@ func (a *App) OnEvent(event events.Event) {
 		return
 	}
 
-	// Forward channel list events to frontend, caching the result first so that
-	// reopening the modal can render instantly without a fresh LIST. This runs after
-	// the IRC 323 handler has cleared its accumulation buffer, so it is race-free.
+	// Merge finished LIST replies into the stored channel directory, then send
+	// the frontend the updated directory. This runs after the IRC 323 handler
+	// has cleared its accumulation buffer, so it is race-free.
 	if event.Type == irc.EventChannelListEnd {
-		if networkID, ok := event.Data["networkId"].(int64); ok {
-			a.cacheChannelList(networkID, channelsFromEventData(event.Data["channels"]))
-		}
-		a.emit("channel-list", map[string]interface{}{
-			"type":      event.Type,
-			"data":      event.Data,
-			"timestamp": event.Timestamp.Format(time.RFC3339),
-		})
+		networkID, _ := event.Data["networkId"].(int64)
+		items, _ := event.Data["channels"].([]irc.ChannelListItem)
+		filter, _ := event.Data["filter"].(string)
+		a.storeChannelList(networkID, items, filter)
 	}
 
 	// Forward WHOIS events to frontend
@@ -636,7 +672,7 @@ func (a *App) processPluginActions() {
 			a.mu.RUnlock()
 
 			if client != nil {
-				if err := client.SendMessage(target, message); err != nil {
+				if err := client.SendAutomatedMessage(target, message); err != nil {
 					logger.Log.Error().Err(err).Str("target", target).Msg("Failed to send message from plugin")
 				}
 			} else {
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/imageproc"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/unfurl"
+)
+
+// avatarSize is the edge, in pixels, of cached avatars: twice the largest
+// size the roster draws them, for high-DPI screens.
+const avatarSize = 64
+
+// ProfileMetadata is our own draft/metadata-2 profile on a network.
+type ProfileMetadata struct {
+	Supported   bool   `json:"supported"` // the server granted draft/metadata-2
+	Avatar      string `json:"avatar"`
+	DisplayName string `json:"displayName"`
+	Pronouns    string `json:"pronouns"`
+	Homepage    string `json:"homepage"`
+	Status      string `json:"status"`
+}
+
+// GetProfileMetadata returns our own metadata on the network as the server
+// last confirmed it. Supported is false while disconnected.
+func (a *App) GetProfileMetadata(networkID int64) (ProfileMetadata, error) {
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if !exists || !client.IsConnected() {
+		return ProfileMetadata{}, nil
+	}
+	meta, _ := client.UserMetaFor(client.CurrentNick())
+	return ProfileMetadata{
+		Supported:   client.MetadataSupported(),
+		Avatar:      meta.Avatar,
+		DisplayName: meta.DisplayName,
+		Pronouns:    meta.Pronouns,
+		Homepage:    meta.Homepage,
+		Status:      meta.Status,
+	}, nil
+}
+
+// SetMetadata sets one of our own metadata keys on the network ("avatar",
+// "display-name", "pronouns", "homepage" or "status"); an empty value removes
+// it. The change shows up in the roster once the server confirms it.
+func (a *App) SetMetadata(networkID int64, key, value string) error {
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("network %d is not connected", networkID)
+	}
+	return client.SetMetadata(key, value)
+}
+
+// GetAvatar returns a user's avatar as a PNG data URL, or "" when it can't be
+// fetched. The image is downloaded once through the link-preview SSRF guard,
+// cropped and scaled to avatarSize, and cached under <dataDir>/avatars keyed by
+// its URL; a changed avatar has a new URL and so a new cache entry.
+func (a *App) GetAvatar(avatarURL string) (string, error) {
+	if avatarURL == "" {
+		return "", nil
+	}
+	path := a.avatarPath(avatarURL)
+	if b, err := os.ReadFile(path); err == nil {
+		return "data:image/png;base64," + base64.StdEncoding.EncodeToString(b), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	raw, err := unfurl.FetchImage(ctx, avatarURL)
+	if err != nil {
+		logger.Log.Debug().Err(err).Str("url", avatarURL).Msg("Avatar fetch failed")
+		return "", nil
+	}
+	pngBytes, err := imageproc.SquareIconPNG(raw, avatarSize)
+	if err != nil {
+		logger.Log.Debug().Err(err).Str("url", avatarURL).Msg("Avatar could not be decoded")
+		return "", nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("mkdir avatars: %w", err)
+	}
+	if err := os.WriteFile(path, pngBytes, 0o644); err != nil {
+		return "", fmt.Errorf("write avatar: %w", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngBytes), nil
+}
+
+// avatarPath returns the cache file for an avatar URL.
+func (a *App) avatarPath(avatarURL string) string {
+	sum := sha256.Sum256([]byte(avatarURL))
+	return filepath.Join(a.dataDir, "avatars", hex.EncodeToString(sum[:])+".png")
+}
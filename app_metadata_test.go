@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetAvatarServesCacheAndRefusesPrivateHosts(t *testing.T) {
+	a := &App{dataDir: t.TempDir()}
+
+	if got, err := a.GetAvatar("http://127.0.0.1/me.png"); err != nil || got != "" {
+		t.Fatalf("loopback avatar = %q, %v; want it refused quietly", got, err)
+	}
+
+	url := "https://example.org/me.png"
+	path := a.avatarPath(url)
+	if filepath.Dir(path) != filepath.Join(a.dataDir, "avatars") {
+		t.Fatalf("avatar cached at %q", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := a.GetAvatar(url)
+	if err != nil || !strings.HasPrefix(got, "data:image/png;base64,") {
+		t.Fatalf("cached avatar = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/netconfig"
+	"github.com/matt0x6f/irc-client/internal/security"
+	"github.com/matt0x6f/irc-client/internal/storage"
+	"github.com/matt0x6f/irc-client/internal/validation"
+)
+
+// machineLocalSettings are settings that describe this computer rather than
+// the user's preferences (paths, the core this desktop attaches to, the
+// bouncer's certificate files). They are never exported or imported.
+var machineLocalSettings = map[string]bool{
+	settingRemoteCoreURL:          true,
+	settingBouncer:                true,
+	settingBackup:                 true,
+	settingLogFilePath:            true,
+	settingFileTransfersDirectory: true,
+	settingFileTransfersAddress:   true,
+	settingFileTransfersPortMin:   true,
+	settingFileTransfersPortMax:   true,
+	settingSkippedUpdateVersion:   true,
+	"plugins.discover_path":       true,
+}
+
+// ConfigImportResult reports what ImportConfiguration changed.
+type ConfigImportResult struct {
+	Created  []string `json:"created"`  // networks added
+	Updated  []string `json:"updated"`  // existing networks (matched by name) overwritten
+	Skipped  []string `json:"skipped"`  // networks that could not be imported, with the reason
+	Settings int      `json:"settings"` // app settings applied
+	Warnings []string `json:"warnings"` // things the source had that were not carried over
+}
+
+// ExportConfiguration returns every network (servers, identity, SASL,
+// channels, MONITOR and ignore lists) and, when includeSettings is set, the
+// app settings as a bundle in format "json" or "yaml". Passwords and channel
+// keys are included only when passphrase is set, encrypted with it.
+func (a *App) ExportConfiguration(format, passphrase string, includeSettings bool) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != string(netconfig.FormatJSON) && format != string(netconfig.FormatYAML) {
+		return "", fmt.Errorf("export format must be json or yaml")
+	}
+	cfg, err := a.exportableConfig(includeSettings, passphrase != "")
+	if err != nil {
+		return "", err
+	}
+	data, err := netconfig.Export(cfg, netconfig.Format(format), passphrase)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (a *App) exportableConfig(includeSettings, includeSecrets bool) (netconfig.Config, error) {
+	networks, err := a.storage.GetNetworks()
+	if err != nil {
+		return netconfig.Config{}, err
+	}
+	cfg := netconfig.Config{Secrets: netconfig.Secrets{}}
+	for _, n := range networks {
+		out := netconfig.Network{
+			Name:          n.Name,
+			Nickname:      n.Nickname,
+			Username:      n.Username,
+			Realname:      n.Realname,
+			AutoConnect:   n.AutoConnect,
+			IdentifyAsBot: n.IdentifyAsBot,
+			FloodProfile:  n.FloodProfile,
+		}
+		if n.SASLEnabled {
+			out.SASLMechanism = strings.ToUpper(derefStr(n.SASLMechanism))
+			if out.SASLMechanism == "" {
+				out.SASLMechanism = "PLAIN"
+			}
+			out.SASLUsername = derefStr(n.SASLUsername)
+			out.SASLExternalCert = derefStr(n.SASLExternalCert)
+		}
+
+		servers, err := a.storage.GetServers(n.ID)
+		if err != nil {
+			return netconfig.Config{}, err
+		}
+		for _, s := range servers {
+			out.Servers = append(out.Servers, netconfig.Server{Address: s.Address, Port: s.Port, TLS: s.TLS})
+		}
+		if len(out.Servers) == 0 && n.Address != "" {
+			out.Servers = []netconfig.Server{{Address: n.Address, Port: n.Port, TLS: n.TLS}}
+		}
+
+		secrets := netconfig.NetworkSecrets{ChannelKeys: map[string]string{}}
+		channels, err := a.storage.GetChannels(n.ID)
+		if err != nil {
+			return netconfig.Config{}, err
+		}
+		for _, ch := range channels {
+			if !ch.AutoJoin && !ch.IsOpen {
+				continue // a channel visited once and closed is not configuration
+			}
+			out.Channels = append(out.Channels, netconfig.Channel{Name: ch.Name, AutoJoin: ch.AutoJoin})
+			if ch.Key != "" {
+				secrets.ChannelKeys[ch.Name] = ch.Key
+			}
+		}
+		if out.Monitor, err = a.storage.GetMonitoredNicks(n.ID); err != nil {
+			return netconfig.Config{}, err
+		}
+		if out.Ignore, err = a.storage.ListIgnoredSendersByNetwork(n.ID); err != nil {
+			return netconfig.Config{}, err
+		}
+
+		if includeSecrets {
+			secrets.Password = a.creds.Resolve(n.ID, security.FieldPassword, n.Password)
+			secrets.SASLPassword = a.creds.Resolve(n.ID, security.FieldSASLPassword, derefStr(n.SASLPassword))
+			if secrets.Password != "" || secrets.SASLPassword != "" || len(secrets.ChannelKeys) > 0 {
+				cfg.Secrets[n.Name] = secrets
+			}
+		}
+		cfg.Networks = append(cfg.Networks, out)
+	}
+
+	if includeSettings {
+		all, err := a.storage.GetSettings()
+		if err != nil {
+			return netconfig.Config{}, err
+		}
+		cfg.Settings = make(map[string]string, len(all))
+		for key, value := range all {
+			if !machineLocalSettings[key] {
+				cfg.Settings[key] = value
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// ImportConfiguration reads a Cascade bundle or another client's config file
+// (format "json", "yaml", "irssi", "weechat", "hexchat", or "" to detect it)
+// and adds its networks. A network whose name already exists is updated in
+// place; its channels, MONITOR and ignore entries are added to what is there.
+// passphrase opens a bundle's secrets.
+func (a *App) ImportConfiguration(content, format, passphrase string) (ConfigImportResult, error) {
+	cfg, err := netconfig.Import([]byte(content), netconfig.Format(strings.ToLower(strings.TrimSpace(format))), passphrase)
+	if err != nil {
+		return ConfigImportResult{}, err
+	}
+	result := ConfigImportResult{Created: []string{}, Updated: []string{}, Skipped: []string{}, Warnings: cfg.Warnings}
+	if result.Warnings == nil {
+		result.Warnings = []string{}
+	}
+
+	existing := map[string]bool{}
+	fallbackNick := ""
+	if networks, err := a.storage.GetNetworks(); err == nil {
+		for _, n := range networks {
+			existing[n.Name] = true
+			fallbackNick = firstNonEmptyString(fallbackNick, n.Nickname)
+		}
+	}
+	for _, n := range cfg.Networks {
+		fallbackNick = firstNonEmptyString(fallbackNick, n.Nickname)
+	}
+
+	for _, n := range cfg.Networks {
+		n.Nickname = firstNonEmptyString(n.Nickname, fallbackNick)
+		if err := a.importNetwork(n, cfg.Secrets[n.Name]); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", n.Name, err))
+			continue
+		}
+		if existing[n.Name] {
+			result.Updated = append(result.Updated, n.Name)
+		} else {
+			result.Created = append(result.Created, n.Name)
+		}
+	}
+
+	for key, value := range cfg.Settings {
+		if machineLocalSettings[key] {
+			continue
+		}
+		if err := a.storage.SetSetting(key, value); err != nil {
+			logger.Log.Warn().Err(err).Str("key", key).Msg("Failed to import setting")
+			continue
+		}
+		a.emit("setting:changed", map[string]string{"key": key, "value": value})
+		result.Settings++
+	}
+	if result.Settings > 0 {
+		result.Warnings = append(result.Warnings, "Some imported settings take effect after Cascade restarts.")
+	}
+
+	if len(result.Created)+len(result.Updated) > 0 {
+		a.emit("networks:changed")
+	}
+	return result, nil
+}
+
+// importNetwork saves one imported network through the same path as the
+// network editor, then adds its channels and lists.
+func (a *App) importNetwork(n netconfig.Network, secrets netconfig.NetworkSecrets) error {
+	config := NetworkConfig{
+		Name:             strings.TrimSpace(n.Name),
+		Nickname:         n.Nickname,
+		Username:         firstNonEmptyString(n.Username, n.Nickname),
+		Realname:         firstNonEmptyString(n.Realname, n.Nickname),
+		Password:         secrets.Password,
+		SASLEnabled:      n.SASLMechanism != "",
+		SASLMechanism:    n.SASLMechanism,
+		SASLUsername:     n.SASLUsername,
+		SASLPassword:     secrets.SASLPassword,
+		SASLExternalCert: n.SASLExternalCert,
+		AutoConnect:      n.AutoConnect,
+		IdentifyAsBot:    n.IdentifyAsBot,
+		FloodProfile:     n.FloodProfile,
+	}
+	serverChecks := make([]struct {
+		Address string
+		Port    int
+	}, len(n.Servers))
+	for i, s := range n.Servers {
+		config.Servers = append(config.Servers, ServerConfig{Address: s.Address, Port: s.Port, TLS: s.TLS, Order: i})
+		serverChecks[i].Address, serverChecks[i].Port = s.Address, s.Port
+	}
+	if err := validation.ValidateNetworkConfig(config.Name, config.Nickname, config.Username, config.Realname, serverChecks); err != nil {
+		return err
+	}
+
+	network, err := a.buildNetworkFromConfig(config, config.Servers, true)
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range n.Channels {
+		if validation.ValidateChannelName(ch.Name) != nil {
+			continue
+		}
+		key := secrets.ChannelKeys[ch.Name]
+		stored, err := a.storage.GetChannelByName(network.ID, ch.Name)
+		if err != nil {
+			if err := a.storage.CreateChannel(&storage.Channel{NetworkID: network.ID, Name: ch.Name, Key: key, AutoJoin: ch.AutoJoin}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := a.storage.UpdateChannelAutoJoin(stored.ID, ch.AutoJoin); err != nil {
+			return err
+		}
+		if key != "" {
+			if err := a.storage.UpdateChannelKey(stored.ID, key); err != nil {
+				return err
+			}
+		}
+	}
+	for _, nick := range n.Monitor {
+		if err := a.storage.AddMonitoredNick(network.ID, nick); err != nil {
+			return err
+		}
+	}
+	for _, nick := range n.Ignore {
+		if err := a.storage.AddIgnoredSender(network.ID, nick); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matt0x6f/irc-client/internal/security"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+func TestConfigurationExportImportRoundTrip(t *testing.T) {
+	src := newCredsTestApp(t)
+	err := src.SaveNetwork(NetworkConfig{
+		Name: "Libera", Nickname: "matt", Username: "matt", Realname: "Matt",
+		Servers:     []ServerConfig{{Address: "irc.libera.chat", Port: 6697, TLS: true}},
+		SASLEnabled: true, SASLMechanism: "PLAIN", SASLUsername: "matt", SASLPassword: "saslpw",
+		AutoConnect: true,
+	})
+	if err != nil {
+		t.Fatalf("SaveNetwork: %v", err)
+	}
+	network, err := src.resolveControlNetwork("Libera")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.storage.CreateChannel(&storage.Channel{NetworkID: network.ID, Name: "#ops", Key: "sekrit", AutoJoin: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.storage.AddMonitoredNick(network.ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.storage.SetSetting("theme.accent", "teal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.storage.SetSetting(settingLogFilePath, "/home/matt/cascade.log"); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := src.ExportConfiguration("yaml", "passphrase", true)
+	if err != nil {
+		t.Fatalf("ExportConfiguration: %v", err)
+	}
+	if strings.Contains(bundle, "saslpw") || strings.Contains(bundle, "sekrit") || strings.Contains(bundle, "cascade.log") {
+		t.Fatalf("bundle leaks a secret or a machine-local setting:\n%s", bundle)
+	}
+
+	dst := newCredsTestApp(t)
+	result, err := dst.ImportConfiguration(bundle, "", "passphrase")
+	if err != nil {
+		t.Fatalf("ImportConfiguration: %v", err)
+	}
+	if len(result.Created) != 1 || result.Created[0] != "Libera" || len(result.Skipped) != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	imported, err := dst.resolveControlNetwork("Libera")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !imported.SASLEnabled || derefStr(imported.SASLUsername) != "matt" || !imported.AutoConnect {
+		t.Errorf("imported network = %+v", imported)
+	}
+	if got := dst.creds.Resolve(imported.ID, security.FieldSASLPassword, derefStr(imported.SASLPassword)); got != "saslpw" {
+		t.Errorf("SASL password = %q", got)
+	}
+	ch, err := dst.storage.GetChannelByName(imported.ID, "#ops")
+	if err != nil || !ch.AutoJoin || ch.Key != "sekrit" {
+		t.Errorf("#ops = %+v, %v", ch, err)
+	}
+	if nicks, _ := dst.storage.GetMonitoredNicks(imported.ID); len(nicks) != 1 || nicks[0] != "alice" {
+		t.Errorf("monitor = %v", nicks)
+	}
+	if v, _ := dst.storage.GetSetting("theme.accent"); v != "teal" {
+		t.Errorf("theme.accent = %q", v)
+	}
+	if v, _ := dst.storage.GetSetting(settingLogFilePath); v != "" {
+		t.Errorf("machine-local setting imported: %q", v)
+	}
+}
+
+func TestImportConfigurationFromHexChatFillsMissingNick(t *testing.T) {
+	a := newCredsTestApp(t)
+	makeAppTestNetwork(t, a.storage, "Existing")
+
+	result, err := a.ImportConfiguration("v=2.16.2\n\nN=Rizon\nF=19\nS=irc.rizon.net/6667\nJ=#a\n", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Created) != 1 || len(result.Warnings) == 0 {
+		t.Fatalf("result = %+v", result)
+	}
+	network, err := a.resolveControlNetwork("Rizon")
+	if err != nil || network.Nickname != "matt" {
+		t.Errorf("Rizon = %+v, %v; want the existing network's nick", network, err)
+	}
+}
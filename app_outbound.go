@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+)
+
+// OutboundQueueStatus is what a network's connection still has to write,
+// counted in lines per send class.
+type OutboundQueueStatus struct {
+	Profile      string `json:"profile"`
+	Protocol     int    `json:"protocol"`
+	User         int    `json:"user"`
+	Paste        int    `json:"paste"`
+	Automation   int    `json:"automation"`
+	QueuedBytes  int    `json:"queuedBytes"`
+	OldestWaitMs int64  `json:"oldestWaitMs"`
+}
+
+// GetOutboundQueue reports the network's outbound queue depth.
+func (a *App) GetOutboundQueue(networkID int64) (OutboundQueueStatus, error) {
+	client, err := a.connectedClient(networkID)
+	if err != nil {
+		return OutboundQueueStatus{}, err
+	}
+	stats := client.OutboundQueueStats()
+	status := OutboundQueueStatus{
+		Profile:      stats.Profile,
+		Protocol:     stats.Queued[irc.SendProtocol],
+		User:         stats.Queued[irc.SendUser],
+		Paste:        stats.Queued[irc.SendPaste],
+		Automation:   stats.Queued[irc.SendAutomation],
+		OldestWaitMs: stats.OldestWait.Milliseconds(),
+	}
+	for _, n := range stats.QueuedBytes {
+		status.QueuedBytes += n
+	}
+	return status, nil
+}
+
+// ClearPasteQueue stops every paste still being sent on the network and
+// reports how many lines were not sent.
+func (a *App) ClearPasteQueue(networkID int64) (int, error) {
+	client, err := a.connectedClient(networkID)
+	if err != nil {
+		return 0, err
+	}
+	return client.ClearPasteQueue(), nil
+}
+
+func (a *App) connectedClient(networkID int64) (*irc.IRCClient, error) {
+	a.mu.RLock()
+	client, ok := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("network not connected")
+	}
+	return client, nil
+}
+
+// cmdQueue shows the outbound queue, or with "clear" drops unsent paste lines.
+func cmdQueue(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	if len(args) > 0 {
+		if !strings.EqualFold(args[0], "clear") {
+			return fmt.Errorf("usage: /queue [clear]")
+		}
+		n := client.ClearPasteQueue()
+		return a.PrintLocalLines(networkID, buffer, []string{fmt.Sprintf("Dropped %d unsent paste line(s).", n)})
+	}
+	stats := client.OutboundQueueStats()
+	lines := []string{fmt.Sprintf("Flood profile: %s. %d line(s) waiting.", stats.Profile, stats.Depth())}
+	for _, class := range []irc.SendClass{irc.SendProtocol, irc.SendUser, irc.SendPaste, irc.SendAutomation} {
+		if stats.Queued[class] == 0 && stats.Sent[class] == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %-10s %4d waiting (%d bytes), %d sent", class, stats.Queued[class], stats.QueuedBytes[class], stats.Sent[class]))
+	}
+	if stats.OldestWait > 0 {
+		lines = append(lines, fmt.Sprintf("Oldest line has waited %s.", stats.OldestWait.Round(100*time.Millisecond)))
+	}
+	return a.PrintLocalLines(networkID, buffer, lines)
+}
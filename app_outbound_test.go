@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestFloodProfileSavedAndKeptOnConnect confirms an explicit save stores the
+// chosen flood profile, a connect-time rebuild keeps it, and an unknown
+// profile is refused.
+func TestFloodProfileSavedAndKeptOnConnect(t *testing.T) {
+	a := newAutoConnectTestApp(t)
+
+	servers := []ServerConfig{{Address: "irc.home.example", Port: 6697, TLS: true, Order: 0}}
+	cfg := NetworkConfig{
+		Name:         "Home",
+		Nickname:     "dan",
+		Username:     "dan",
+		Realname:     "Dan",
+		Servers:      servers,
+		FloodProfile: "relaxed",
+	}
+	net, err := a.buildNetworkFromConfig(cfg, servers, true)
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	cfg.FloodProfile = ""
+	if _, err := a.buildNetworkFromConfig(cfg, servers, false); err != nil {
+		t.Fatalf("connect-time rebuild: %v", err)
+	}
+	got, err := a.storage.GetNetwork(net.ID)
+	if err != nil {
+		t.Fatalf("GetNetwork: %v", err)
+	}
+	if got.FloodProfile != "relaxed" {
+		t.Fatalf("FloodProfile = %q after connect, want relaxed", got.FloodProfile)
+	}
+
+	cfg.FloodProfile = "turbo"
+	if _, err := a.buildNetworkFromConfig(cfg, servers, true); err == nil {
+		t.Fatal("save accepted an unknown flood profile")
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// Perform step kinds.
+const (
+	performCommand = "command" // Value is a command line, run as if typed in the status buffer
+	performDelay   = "delay"   // pause for Seconds
+	performWait    = "wait"    // wait up to Seconds for a NOTICE whose text matches the Value pattern
+
+	maxPerformDelay     = 300
+	defaultPerformWait  = 30
+	maxPerformWait      = 300
+	performSecretPrefix = "perform-"
+)
+
+// performSecretRef is how a perform command names a secret kept in the
+// credential store, e.g. "AUTH matt ${secret:q-password}".
+var performSecretRef = regexp.MustCompile(`\$\{secret:([A-Za-z0-9_.-]+)\}`)
+
+// PerformStep is one entry in a network's perform-on-connect list.
+type PerformStep struct {
+	Kind    string `json:"kind"`    // "command", "delay" or "wait"
+	Value   string `json:"value"`   // the command line, or the notice pattern for "wait"
+	Seconds int    `json:"seconds"` // the pause for "delay", the timeout for "wait"
+}
+
+// PerformSecret reports whether a secret referenced by the perform list is
+// stored. The value is never returned.
+type PerformSecret struct {
+	Name  string `json:"name"`
+	IsSet bool   `json:"isSet"`
+}
+
+// GetPerformSteps returns the network's perform list in order.
+func (a *App) GetPerformSteps(networkID int64) ([]PerformStep, error) {
+	rows, err := a.storage.ListPerformSteps(networkID)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]PerformStep, len(rows))
+	for i, row := range rows {
+		steps[i] = PerformStep{Kind: row.Kind, Value: row.Value, Seconds: int(row.Seconds)}
+	}
+	return steps, nil
+}
+
+// SetPerformSteps replaces the network's perform list. It runs on the next
+// connection, after registration and before channels are joined.
+func (a *App) SetPerformSteps(networkID int64, steps []PerformStep) error {
+	rows := make([]storage.PerformStep, 0, len(steps))
+	for i, step := range steps {
+		step.Value = strings.TrimSpace(step.Value)
+		switch step.Kind {
+		case performCommand:
+			if step.Value == "" {
+				return fmt.Errorf("step %d: the command is empty", i+1)
+			}
+			step.Seconds = 0
+		case performDelay:
+			if step.Seconds < 1 || step.Seconds > maxPerformDelay {
+				return fmt.Errorf("step %d: delays are 1 to %d seconds", i+1, maxPerformDelay)
+			}
+			step.Value = ""
+		case performWait:
+			if step.Value == "" {
+				return fmt.Errorf("step %d: say which notice to wait for", i+1)
+			}
+			if step.Seconds <= 0 {
+				step.Seconds = defaultPerformWait
+			}
+			if step.Seconds > maxPerformWait {
+				return fmt.Errorf("step %d: waits are at most %d seconds", i+1, maxPerformWait)
+			}
+		default:
+			return fmt.Errorf("step %d: unknown kind %q", i+1, step.Kind)
+		}
+		rows = append(rows, storage.PerformStep{Kind: step.Kind, Value: step.Value, Seconds: int64(step.Seconds)})
+	}
+	return a.storage.ReplacePerformSteps(networkID, rows)
+}
+
+// GetPerformSecrets lists the secrets the network's perform list refers to.
+func (a *App) GetPerformSecrets(networkID int64) ([]PerformSecret, error) {
+	names, err := a.performSecretNames(networkID)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]PerformSecret, len(names))
+	for i, name := range names {
+		secrets[i] = PerformSecret{Name: name, IsSet: a.creds.Resolve(networkID, performSecretPrefix+name, "") != ""}
+	}
+	return secrets, nil
+}
+
+// SetPerformSecret stores a secret for ${secret:name} references in the
+// network's perform list. An empty value deletes it.
+func (a *App) SetPerformSecret(networkID int64, name, value string) error {
+	if !performSecretRef.MatchString("${secret:" + name + "}") {
+		return fmt.Errorf("secret names use letters, digits, '.', '_' and '-'")
+	}
+	if a.creds == nil {
+		return fmt.Errorf("no credential store is available")
+	}
+	if _, err := a.creds.Store(networkID, performSecretPrefix+name, value); err != nil {
+		return fmt.Errorf("store secret %q: %w", name, err)
+	}
+	return nil
+}
+
+// performSecretNames returns the distinct secret names the network's perform
+// list refers to, in order of first use.
+func (a *App) performSecretNames(networkID int64) ([]string, error) {
+	steps, err := a.storage.ListPerformSteps(networkID)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, step := range steps {
+		if step.Kind != performCommand {
+			continue
+		}
+		for _, m := range performSecretRef.FindAllStringSubmatch(step.Value, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				names = append(names, m[1])
+			}
+		}
+	}
+	return names, nil
+}
+
+// runPerform is the client's perform action: it runs the network's perform
+// list through the command dispatcher. A failing step is reported in the
+// status buffer and the list carries on, so auto-join still happens.
+func (a *App) runPerform(networkID int64, client *irc.IRCClient) {
+	steps, err := a.storage.ListPerformSteps(networkID)
+	if err != nil {
+		logger.Log.Warn().Err(err).Int64("network_id", networkID).Msg("Failed to load perform list")
+		return
+	}
+	if len(steps) == 0 {
+		return
+	}
+	logger.Log.Info().Int64("network_id", networkID).Int("steps", len(steps)).Msg("Running perform list")
+
+	var armed *irc.NoticeWaiter
+	for i, step := range steps {
+		if !client.IsConnectedDirect() {
+			logger.Log.Info().Int64("network_id", networkID).Msg("Connection lost; perform list abandoned")
+			return
+		}
+		switch step.Kind {
+		case performDelay:
+			time.Sleep(time.Duration(step.Seconds) * time.Second)
+		case performWait:
+			if armed == nil {
+				armed = client.ExpectNotice(noticeMatcher(step.Value))
+			}
+			if err := armed.Wait(time.Duration(step.Seconds) * time.Second); err != nil {
+				a.writeNetworkStatus(networkID, fmt.Sprintf("Perform: no notice matching %q within %ds; carrying on", step.Value, step.Seconds))
+			}
+			armed = nil
+		case performCommand:
+			// Arm the next step's wait first so a quick reply is not missed.
+			if i+1 < len(steps) && steps[i+1].Kind == performWait {
+				armed = client.ExpectNotice(noticeMatcher(steps[i+1].Value))
+			}
+			a.runPerformCommand(networkID, client, step.Value)
+		}
+	}
+}
+
+// runPerformCommand expands and runs one command step. Variables work as in
+// aliases ($me, $network, ...). A line that uses secrets is sent as a single
+// raw line with the secrets filled in last; only the line as written, with
+// ${secret:name} unresolved, is stored or shown.
+func (a *App) runPerformCommand(networkID int64, client *irc.IRCClient, command string) {
+	for _, line := range expandAlias(command, nil, a.aliasVariables(client, networkID, "status")) {
+		var err error
+		switch {
+		case performSecretRef.MatchString(line):
+			var raw string
+			if raw, err = a.performSecretLine(networkID, line); err == nil {
+				if raw, err = a.resolvePerformSecrets(networkID, raw); err == nil {
+					err = client.SendSecretCommand(raw, line)
+				}
+			}
+		case strings.HasPrefix(line, "/"):
+			if parts := strings.Fields(line[1:]); len(parts) > 0 {
+				err = a.dispatchCommand(client, networkID, "status", parts[0], parts[1:], line[1:])
+			}
+		default:
+			err = client.SendRawCommand(line)
+		}
+		if err != nil {
+			a.writeNetworkStatus(networkID, fmt.Sprintf("Perform: %s failed: %v", line, err))
+		}
+	}
+}
+
+// performSecretLine turns a perform line that uses secrets into the raw IRC
+// line to send, with the secrets still unresolved. Only /msg, /notice, /quote
+// and commands the server handles map onto one line; anything else would pass
+// the secret through code that stores or echoes it, so it is refused.
+func (a *App) performSecretLine(networkID int64, line string) (string, error) {
+	if !strings.HasPrefix(line, "/") {
+		return line, nil
+	}
+	name, rest, _ := strings.Cut(line[1:], " ")
+	rest = strings.TrimLeft(rest, " ")
+	spec, builtin := a.commands.Lookup(name)
+	if !builtin {
+		if _, ok := a.lookupAlias(networkID, name); ok {
+			return "", fmt.Errorf("secrets cannot be passed to the alias /%s", name)
+		}
+		if a.pluginManager != nil {
+			if _, ok := a.pluginManager.LookupPluginCommand(name); ok {
+				return "", fmt.Errorf("secrets cannot be passed to the plugin command /%s", name)
+			}
+		}
+		return line[1:], nil
+	}
+	switch spec.Name {
+	case "MSG", "NOTICE":
+		target, text, _ := strings.Cut(rest, " ")
+		if target == "" || strings.TrimSpace(text) == "" {
+			return "", fmt.Errorf("usage: /%s %s", strings.ToLower(spec.Name), spec.Usage)
+		}
+		verb := "PRIVMSG"
+		if spec.Name == "NOTICE" {
+			verb = "NOTICE"
+		}
+		return fmt.Sprintf("%s %s :%s", verb, target, strings.TrimLeft(text, " ")), nil
+	case "QUOTE":
+		return rest, nil
+	}
+	return "", fmt.Errorf("/%s cannot use secrets; use /msg, /notice or /quote", strings.ToLower(spec.Name))
+}
+
+func (a *App) resolvePerformSecrets(networkID int64, line string) (string, error) {
+	var missing string
+	resolved := performSecretRef.ReplaceAllStringFunc(line, func(ref string) string {
+		name := performSecretRef.FindStringSubmatch(ref)[1]
+		value := a.creds.Resolve(networkID, performSecretPrefix+name, "")
+		if value == "" && missing == "" {
+			missing = name
+		}
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("secret %q is not set", missing)
+	}
+	return resolved, nil
+}
+
+// noticeMatcher matches notice text against a case-insensitive pattern in
+// which '*' stands for any run of characters and '?' for one.
+func noticeMatcher(pattern string) func(from, text string) bool {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.NewReplacer(`\*`, `.*`, `\?`, `.`).Replace(expr)
+	re := regexp.MustCompile(`(?is)^` + expr + `$`)
+	return func(_, text string) bool { return re.MatchString(text) }
+}
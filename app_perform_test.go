@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetPerformStepsValidates(t *testing.T) {
+	a := newTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "PerformApp")
+
+	for _, bad := range [][]PerformStep{
+		{{Kind: performCommand, Value: "  "}},
+		{{Kind: performDelay, Seconds: 0}},
+		{{Kind: performWait, Value: "*logged in*", Seconds: maxPerformWait + 1}},
+		{{Kind: "sleep", Seconds: 1}},
+	} {
+		if err := a.SetPerformSteps(net.ID, bad); err == nil {
+			t.Errorf("SetPerformSteps(%+v) succeeded", bad)
+		}
+	}
+
+	if err := a.SetPerformSteps(net.ID, []PerformStep{
+		{Kind: performCommand, Value: " /mode $me +x "},
+		{Kind: performWait, Value: "*logged in*"},
+		{Kind: performDelay, Value: "ignored", Seconds: 2},
+	}); err != nil {
+		t.Fatalf("SetPerformSteps: %v", err)
+	}
+	got, err := a.GetPerformSteps(net.ID)
+	if err != nil {
+		t.Fatalf("GetPerformSteps: %v", err)
+	}
+	want := []PerformStep{
+		{Kind: performCommand, Value: "/mode $me +x"},
+		{Kind: performWait, Value: "*logged in*", Seconds: defaultPerformWait},
+		{Kind: performDelay, Seconds: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("steps = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("step %d = %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+}
+
+// TestPerformSecrets: secrets referenced by the perform list are listed
+// without their values, and a command naming an unset secret is refused
+// rather than sent with a blank.
+func TestPerformSecrets(t *testing.T) {
+	a := newCredsTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "PerformSecrets")
+	if err := a.SetPerformSteps(net.ID, []PerformStep{
+		{Kind: performCommand, Value: "/msg Q@CServe.quakenet.org AUTH matt ${secret:q}"},
+		{Kind: performCommand, Value: "/oper matt ${secret:oper}; /msg Q@CServe.quakenet.org HELLO ${secret:q}"},
+	}); err != nil {
+		t.Fatalf("SetPerformSteps: %v", err)
+	}
+	if err := a.SetPerformSecret(net.ID, "q", "hunter2"); err != nil {
+		t.Fatalf("SetPerformSecret: %v", err)
+	}
+	if err := a.SetPerformSecret(net.ID, "bad name", "x"); err == nil {
+		t.Error("SetPerformSecret accepted a name with a space")
+	}
+
+	secrets, err := a.GetPerformSecrets(net.ID)
+	if err != nil {
+		t.Fatalf("GetPerformSecrets: %v", err)
+	}
+	if len(secrets) != 2 || secrets[0] != (PerformSecret{Name: "q", IsSet: true}) || secrets[1] != (PerformSecret{Name: "oper"}) {
+		t.Fatalf("secrets = %+v", secrets)
+	}
+
+	line, err := a.resolvePerformSecrets(net.ID, "/msg Q AUTH matt ${secret:q}")
+	if err != nil || line != "/msg Q AUTH matt hunter2" {
+		t.Errorf("resolve = %q, %v", line, err)
+	}
+	if _, err := a.resolvePerformSecrets(net.ID, "/oper matt ${secret:oper}"); err == nil || !strings.Contains(err.Error(), "oper") {
+		t.Errorf("resolving an unset secret = %v, want an error naming it", err)
+	}
+}
+
+// TestPerformSecretLine: a line using secrets becomes one raw line, sent
+// through SendSecretCommand, so the resolved secret never passes through a
+// handler that stores or echoes what it sends.
+func TestPerformSecretLine(t *testing.T) {
+	a := newTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "PerformSecretLine")
+	if err := a.SaveCommandAlias(AliasDefinition{Name: "login", Expansion: "/msg Q AUTH $*"}); err != nil {
+		t.Fatalf("SaveCommandAlias: %v", err)
+	}
+
+	for line, want := range map[string]string{
+		"/msg Q@CServe.quakenet.org AUTH matt ${secret:q}": "PRIVMSG Q@CServe.quakenet.org :AUTH matt ${secret:q}",
+		"/m NickServ IDENTIFY  ${secret:ns}":               "PRIVMSG NickServ :IDENTIFY  ${secret:ns}",
+		"/notice X login ${secret:x}":                      "NOTICE X :login ${secret:x}",
+		"/quote PASS ${secret:pass}":                       "PASS ${secret:pass}",
+		"/oper matt ${secret:oper}":                        "oper matt ${secret:oper}",
+		"OPER matt ${secret:oper}":                         "OPER matt ${secret:oper}",
+	} {
+		got, err := a.performSecretLine(net.ID, line)
+		if err != nil || got != want {
+			t.Errorf("performSecretLine(%q) = %q, %v; want %q", line, got, err, want)
+		}
+	}
+	for _, line := range []string{"/me says ${secret:q}", "/login ${secret:q}", "/msg Q"} {
+		if _, err := a.performSecretLine(net.ID, line); err == nil {
+			t.Errorf("performSecretLine(%q) succeeded", line)
+		}
+	}
+}
+
+func TestNoticeMatcher(t *testing.T) {
+	match := noticeMatcher("*now logged in as ?att*")
+	if !match("Q", "You are NOW logged in as matt.") {
+		t.Error("pattern did not match the login notice")
+	}
+	if match("Q", "You are not logged in") {
+		t.Error("pattern matched an unrelated notice")
+	}
+	if !noticeMatcher("a.b (c)")("", "a.b (c)") || noticeMatcher("a.b")("", "axb") {
+		t.Error("pattern punctuation is not literal")
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+const (
+	// scheduledMessageInterval is how often the queue is checked for messages
+	// that are due.
+	scheduledMessageInterval = 15 * time.Second
+	// scheduledMessageGrace is how late a message may still go out. One that
+	// falls due while the network is down, or while the app is closed, is
+	// sent when we are back within this window; after it the message fails
+	// with a notice rather than arriving long after the fact.
+	scheduledMessageGrace = time.Hour
+	// scheduledMessageMaxAttempts is how many times sending is tried while
+	// connected before the message fails.
+	scheduledMessageMaxAttempts = 3
+)
+
+// ScheduleMessage queues message for target (a channel or nick) on the
+// network, to be sent through the normal send path at sendAt, in Unix
+// milliseconds. The queue is stored, so it survives a restart.
+func (a *App) ScheduleMessage(networkID int64, target, message string, sendAt int64) (storage.ScheduledMessage, error) {
+	target = strings.TrimSpace(target)
+	if _, ok := directChatPeer(target); ok {
+		return storage.ScheduledMessage{}, fmt.Errorf("messages to a DCC chat can't be scheduled")
+	}
+	if !time.UnixMilli(sendAt).After(time.Now()) {
+		return storage.ScheduledMessage{}, fmt.Errorf("the time to send must be in the future")
+	}
+	msg, err := a.storage.CreateScheduledMessage(networkID, target, message, time.UnixMilli(sendAt))
+	if err != nil {
+		return storage.ScheduledMessage{}, err
+	}
+	a.emit("scheduled-messages-changed", map[string]interface{}{"networkId": networkID})
+	return msg, nil
+}
+
+// GetScheduledMessages returns the network's queued messages, soonest first,
+// including failed ones that have not been removed.
+func (a *App) GetScheduledMessages(networkID int64) ([]storage.ScheduledMessage, error) {
+	return a.storage.ListScheduledMessages(networkID)
+}
+
+// CancelScheduledMessage removes a queued message, or a failed one the user
+// has seen.
+func (a *App) CancelScheduledMessage(networkID, id int64) error {
+	removed, err := a.storage.DeleteScheduledMessage(networkID, id)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("no scheduled message #%d on this network", id)
+	}
+	a.emit("scheduled-messages-changed", map[string]interface{}{"networkId": networkID})
+	return nil
+}
+
+// startScheduledMessages sends due messages once at startup, for any that fell
+// due while the app was closed, and then on a fixed cadence until shutdown.
+func (a *App) startScheduledMessages() {
+	a.startupWg.Add(1)
+	go func() {
+		defer a.startupWg.Done()
+		ticker := time.NewTicker(scheduledMessageInterval)
+		defer ticker.Stop()
+		for {
+			a.sendDueScheduledMessages(time.Now())
+			select {
+			case <-a.startupCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sendDueScheduledMessages sends every due message whose network is
+// connected. The rest wait for a later pass until scheduledMessageGrace has
+// passed, then fail with a notice in the network's status window.
+func (a *App) sendDueScheduledMessages(now time.Time) {
+	due, err := a.storage.ListDueScheduledMessages(now)
+	if err != nil {
+		logger.Log.Warn().Err(err).Msg("Failed to load due scheduled messages")
+		return
+	}
+	for _, msg := range due {
+		late := now.Sub(time.UnixMilli(msg.SendAt)) > scheduledMessageGrace
+		a.mu.RLock()
+		client, exists := a.ircClients[msg.NetworkID]
+		a.mu.RUnlock()
+		if !exists || !client.IsConnected() {
+			if late {
+				a.failScheduledMessage(msg, "the network was not connected")
+			}
+			continue
+		}
+
+		if err := a.SendMessage(msg.NetworkID, msg.Target, msg.Message); err != nil {
+			if late || msg.Attempts+1 >= scheduledMessageMaxAttempts {
+				a.failScheduledMessage(msg, err.Error())
+			} else if err := a.storage.RecordScheduledMessageAttempt(msg.ID, err.Error()); err != nil {
+				logger.Log.Warn().Err(err).Int64("id", msg.ID).Msg("Failed to record scheduled message attempt")
+			}
+			continue
+		}
+		if _, err := a.storage.DeleteScheduledMessage(msg.NetworkID, msg.ID); err != nil {
+			// Left pending it would be sent again on the next pass.
+			logger.Log.Error().Err(err).Int64("id", msg.ID).Msg("Failed to remove sent scheduled message")
+		}
+		a.emit("scheduled-messages-changed", map[string]interface{}{"networkId": msg.NetworkID})
+	}
+}
+
+// failScheduledMessage marks msg failed and says so in the status window. The
+// row is kept so the user can see what was not sent.
+func (a *App) failScheduledMessage(msg storage.ScheduledMessage, reason string) {
+	if err := a.storage.FailScheduledMessage(msg.ID, reason); err != nil {
+		logger.Log.Warn().Err(err).Int64("id", msg.ID).Msg("Failed to mark scheduled message failed")
+		return
+	}
+	_ = a.PrintLocalLines(msg.NetworkID, "status", []string{fmt.Sprintf(
+		"Scheduled message #%d to %s for %s was not sent: %s. Remove it with /later cancel %d.",
+		msg.ID, msg.Target, formatScheduledTime(time.UnixMilli(msg.SendAt), time.Now()), reason, msg.ID)})
+	a.emit("scheduled-messages-changed", map[string]interface{}{"networkId": msg.NetworkID})
+}
+
+// parseLaterTime reads the <when> of /later: a delay such as 10m or 1h30m, a
+// time of day such as 09:30 (today, or tomorrow once it has passed), or a
+// date and time such as 2026-05-01T09:30, in local time unless it carries an
+// offset.
+func parseLaterTime(when string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(when); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("the delay must be positive")
+		}
+		return now.Add(d), nil
+	}
+	if t, err := time.ParseInLocation("15:04", when, now.Location()); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	if t, err := time.Parse(time.RFC3339, when); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04", when, now.Location()); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("can't read %q as a time (use e.g. 10m, 2h, 09:30 or 2026-05-01T09:30)", when)
+}
+
+// formatScheduledTime shows t as a time of day when it is today, and with the
+// date otherwise.
+func formatScheduledTime(t, now time.Time) string {
+	t = t.In(now.Location())
+	if y, m, d := t.Date(); y == now.Year() && m == now.Month() && d == now.Day() {
+		return t.Format("15:04")
+	}
+	return t.Format("Mon Jan 2 15:04")
+}
+
+// cmdLater implements /later <when> <target> <text>, /later list and
+// /later cancel <id>. The text is taken from the line as typed, so its spacing
+// is kept.
+func cmdLater(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string, raw string) error {
+	switch strings.ToLower(args[0]) {
+	case "list":
+		msgs, err := a.GetScheduledMessages(networkID)
+		if err != nil {
+			return err
+		}
+		if len(msgs) == 0 {
+			return a.PrintLocalLines(networkID, buffer, []string{"No scheduled messages on this network."})
+		}
+		now := time.Now()
+		lines := make([]string, 0, len(msgs))
+		for _, msg := range msgs {
+			line := fmt.Sprintf("#%d %s to %s: %s", msg.ID, formatScheduledTime(time.UnixMilli(msg.SendAt), now), msg.Target, msg.Message)
+			if msg.Status == storage.ScheduledFailed {
+				line += fmt.Sprintf(" (not sent: %s)", msg.LastError)
+			}
+			lines = append(lines, line)
+		}
+		return a.PrintLocalLines(networkID, buffer, lines)
+	case "cancel":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: /later cancel <id>")
+		}
+		id, err := strconv.ParseInt(strings.TrimPrefix(args[1], "#"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("usage: /later cancel <id>")
+		}
+		if err := a.CancelScheduledMessage(networkID, id); err != nil {
+			return err
+		}
+		return a.PrintLocalLines(networkID, buffer, []string{fmt.Sprintf("Cancelled scheduled message #%d.", id)})
+	}
+
+	if len(args) < 3 {
+		return fmt.Errorf("usage: /later <when> <target> <text>, /later list or /later cancel <id>")
+	}
+	now := time.Now()
+	at, err := parseLaterTime(args[0], now)
+	if err != nil {
+		return err
+	}
+	msg, err := a.ScheduleMessage(networkID, args[1], textAfterFields(raw, 3), at.UnixMilli())
+	if err != nil {
+		return err
+	}
+	return a.PrintLocalLines(networkID, buffer, []string{fmt.Sprintf(
+		"Message #%d to %s will be sent at %s.", msg.ID, msg.Target, formatScheduledTime(at, now))})
+}
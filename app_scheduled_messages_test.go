@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+func TestParseLaterTime(t *testing.T) {
+	now := time.Date(2026, 5, 1, 14, 0, 0, 0, time.Local)
+	for _, tc := range []struct {
+		when string
+		want time.Time
+	}{
+		{"10m", now.Add(10 * time.Minute)},
+		{"1h30m", now.Add(90 * time.Minute)},
+		{"15:30", time.Date(2026, 5, 1, 15, 30, 0, 0, time.Local)},
+		{"09:00", time.Date(2026, 5, 2, 9, 0, 0, 0, time.Local)},
+		{"2026-05-03T08:15", time.Date(2026, 5, 3, 8, 15, 0, 0, time.Local)},
+		{"2026-05-03T08:15:00Z", time.Date(2026, 5, 3, 8, 15, 0, 0, time.UTC)},
+	} {
+		got, err := parseLaterTime(tc.when, now)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("parseLaterTime(%q) = %v, %v; want %v", tc.when, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"-5m", "0s", "tomorrow", "25:00"} {
+		if _, err := parseLaterTime(bad, now); err == nil {
+			t.Errorf("parseLaterTime(%q) accepted", bad)
+		}
+	}
+}
+
+// TestScheduledMessageWaitsForNetwork: a due message on a disconnected network
+// stays queued within the grace period and fails with a notice after it.
+func TestScheduledMessageWaitsForNetwork(t *testing.T) {
+	a := newCredsTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "LaterNet")
+
+	msg, err := a.ScheduleMessage(net.ID, "#release", "v2.0 is out", time.Now().Add(time.Minute).UnixMilli())
+	if err != nil {
+		t.Fatalf("ScheduleMessage: %v", err)
+	}
+	if _, err := a.ScheduleMessage(net.ID, "#release", "too late", time.Now().Add(-time.Minute).UnixMilli()); err == nil {
+		t.Error("a message was scheduled in the past")
+	}
+
+	a.sendDueScheduledMessages(time.Now().Add(30 * time.Minute))
+	msgs, _ := a.GetScheduledMessages(net.ID)
+	if len(msgs) != 1 || msgs[0].Status != storage.ScheduledPending {
+		t.Fatalf("within the grace period: %+v", msgs)
+	}
+
+	a.sendDueScheduledMessages(time.Now().Add(2 * time.Hour))
+	msgs, _ = a.GetScheduledMessages(net.ID)
+	if len(msgs) != 1 || msgs[0].Status != storage.ScheduledFailed || msgs[0].LastError == "" {
+		t.Fatalf("after the grace period: %+v", msgs)
+	}
+	status, err := a.storage.GetMessages(net.ID, nil, 10)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(status) != 1 || !strings.Contains(status[0].Message, "was not sent") {
+		t.Errorf("status window = %+v", status)
+	}
+
+	if err := a.CancelScheduledMessage(net.ID, msg.ID); err != nil {
+		t.Fatalf("CancelScheduledMessage: %v", err)
+	}
+	if err := a.CancelScheduledMessage(net.ID, msg.ID); err == nil {
+		t.Error("cancelled the same message twice")
+	}
+}
+
+// TestLaterKeepsSpacing: /later schedules the text as typed, not re-joined
+// from its words.
+func TestLaterKeepsSpacing(t *testing.T) {
+	a := newCredsTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "LaterSpacing")
+
+	raw := "later  2h #release   v2.0   is  out  "
+	if err := a.dispatchCommand(nil, net.ID, "#release", "later", strings.Fields(raw)[1:], raw); err != nil {
+		t.Fatalf("/later: %v", err)
+	}
+	msgs, err := a.GetScheduledMessages(net.ID)
+	if err != nil {
+		t.Fatalf("GetScheduledMessages: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Message != "v2.0   is  out  " {
+		t.Errorf("scheduled = %+v", msgs)
+	}
+}
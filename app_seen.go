@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+const (
+	seenChainLimit = 10
+	seenAliasLimit = 5
+)
+
+// SeenReport answers /seen for one nick. Chain starts with the nick's own last
+// sighting; when that was a nick change, each later entry is the sighting of
+// the nick it changed to. Aliases are other nicks last seen with the same
+// account or user@host, most recent first.
+type SeenReport struct {
+	Nick    string              `json:"nick"`
+	Chain   []storage.SeenEntry `json:"chain"`
+	Aliases []storage.SeenEntry `json:"aliases"`
+}
+
+// GetSeen returns what the network's seen database knows about nick, or nil
+// if it has never been seen there.
+func (a *App) GetSeen(networkID int64, nick string) (*SeenReport, error) {
+	nick = strings.TrimSpace(nick)
+	if nick == "" {
+		return nil, fmt.Errorf("nick required")
+	}
+	key := a.nickKey(networkID, nick)
+	first, err := a.storage.GetSeen(networkID, key)
+	if err != nil || first == nil {
+		return nil, err
+	}
+	report := &SeenReport{Nick: nick, Chain: []storage.SeenEntry{*first}, Aliases: []storage.SeenEntry{}}
+
+	// Follow nick changes for as long as each new nick was seen since.
+	visited := map[string]bool{key: true}
+	for last := first; last.Action == irc.SeenNickTo && len(report.Chain) < seenChainLimit; {
+		nextKey := a.nickKey(networkID, last.Target)
+		if visited[nextKey] {
+			break
+		}
+		visited[nextKey] = true
+		next, err := a.storage.GetSeen(networkID, nextKey)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil || next.SeenAt < last.SeenAt {
+			break
+		}
+		report.Chain = append(report.Chain, *next)
+		last = next
+	}
+
+	latest := report.Chain[len(report.Chain)-1]
+	account, host := latest.Account, latest.Host
+	if account == "" {
+		account = first.Account
+	}
+	if host == "" {
+		host = first.Host
+	}
+	others, err := a.storage.SeenWithIdentity(networkID, key, account, host, seenAliasLimit+len(report.Chain))
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range others {
+		if !visited[a.nickKey(networkID, other.Nick)] && len(report.Aliases) < seenAliasLimit {
+			report.Aliases = append(report.Aliases, other)
+		}
+	}
+	return report, nil
+}
+
+// nickKey folds a nick the way the IRC client keys the seen database. With no
+// client it falls back to the protocol-default rfc1459 mapping.
+func (a *App) nickKey(networkID int64, nick string) string {
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if exists {
+		return client.FoldKey(nick)
+	}
+	return irc.CaseFold("", nick)
+}
+
+// cmdSeen reports when a nick was last seen and doing what: /seen nick.
+func cmdSeen(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	nick := args[0]
+	if client.IsCurrentNick(nick) {
+		return a.PrintLocalLines(networkID, buffer, []string{"That's you."})
+	}
+	report, err := a.GetSeen(networkID, nick)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		return a.PrintLocalLines(networkID, buffer, []string{fmt.Sprintf("%s has not been seen on this network.", nick)})
+	}
+
+	now := time.Now()
+	var lines []string
+	for _, e := range report.Chain {
+		lines = append(lines, fmt.Sprintf("%s%s was last seen %s, %s.", e.Nick, seenIdentity(e), seenWhen(now, e.SeenAt), seenDoing(e)))
+	}
+	if len(report.Aliases) > 0 {
+		aliases := make([]string, len(report.Aliases))
+		for i, e := range report.Aliases {
+			aliases[i] = fmt.Sprintf("%s (%s)", e.Nick, seenWhen(now, e.SeenAt))
+		}
+		lines = append(lines, "Also seen with the same account or host: "+strings.Join(aliases, ", ")+".")
+	}
+	return a.PrintLocalLines(networkID, buffer, lines)
+}
+
+// seenIdentity renders " (user@host, account name)" for whatever is known.
+func seenIdentity(e storage.SeenEntry) string {
+	var parts []string
+	if e.Host != "" {
+		parts = append(parts, e.Host)
+	}
+	if e.Account != "" {
+		parts = append(parts, "account "+e.Account)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// seenWhen renders a sighting time as "3h 12m ago (2006-01-02 15:04:05)".
+func seenWhen(now time.Time, seenAt int64) string {
+	at := time.UnixMilli(seenAt)
+	d := now.Sub(at)
+	var ago string
+	switch {
+	case d < time.Minute:
+		ago = "just now"
+	case d < time.Hour:
+		ago = fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		ago = fmt.Sprintf("%dh %dm ago", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		ago = fmt.Sprintf("%dd %dh ago", int(d.Hours())/24, int(d.Hours())%24)
+	}
+	return ago + " (" + at.Local().Format(time.DateTime) + ")"
+}
+
+// seenDoing describes a sighting's action.
+func seenDoing(e storage.SeenEntry) string {
+	reason := ""
+	if e.Message != "" {
+		reason = " (" + e.Message + ")"
+	}
+	switch e.Action {
+	case irc.SeenJoin:
+		return "joining " + e.Channel
+	case irc.SeenPart:
+		return "leaving " + e.Channel + reason
+	case irc.SeenQuit:
+		return "quitting" + reason
+	case irc.SeenKicked:
+		return "being kicked from " + e.Channel + " by " + e.Target + reason
+	case irc.SeenNickTo:
+		return "changing nick to " + e.Target
+	case irc.SeenNickFrom:
+		return "changing nick from " + e.Target
+	case irc.SeenChghost:
+		return "changing host"
+	case irc.SeenAccount:
+		if e.Target == "" {
+			return "logging out of their account"
+		}
+		return "logging in as " + e.Target
+	case irc.SeenMessage, irc.SeenNotice:
+		kind := "message"
+		if e.Action == irc.SeenNotice {
+			kind = "notice"
+		}
+		if e.Channel == "" {
+			return "sending a private " + kind
+		}
+		return fmt.Sprintf("sending a %s to %s: %s", kind, e.Channel, e.Message)
+	}
+	return e.Action
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// TestGetSeenFollowsNickChanges confirms /seen walks a rename chain with the
+// rfc1459 fold used offline, stops at a stale link, and lists other nicks that
+// shared the account or host.
+func TestGetSeenFollowsNickChanges(t *testing.T) {
+	a := newTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "SeenNet")
+	for key, e := range map[string]storage.SeenEntry{
+		"alice{":  {Nick: "Alice[", Action: irc.SeenNickTo, Target: "alice_", Host: "al@home", SeenAt: 1000},
+		"alice_":  {Nick: "alice_", Action: irc.SeenNickTo, Target: "away", Host: "al@home", Account: "alice", SeenAt: 2000},
+		"away":    {Nick: "away", Action: irc.SeenJoin, Channel: "#go", SeenAt: 1500}, // older: a different "away"
+		"al2":     {Nick: "al2", Action: irc.SeenQuit, Account: "alice", SeenAt: 500},
+		"someone": {Nick: "someone", Action: irc.SeenQuit, Host: "x@y", SeenAt: 3000},
+	} {
+		if err := a.storage.RecordSeen(net.ID, key, e); err != nil {
+			t.Fatalf("RecordSeen(%s): %v", key, err)
+		}
+	}
+
+	report, err := a.GetSeen(net.ID, "ALICE[")
+	if err != nil || report == nil {
+		t.Fatalf("GetSeen = %+v, %v", report, err)
+	}
+	if len(report.Chain) != 2 || report.Chain[0].Nick != "Alice[" || report.Chain[1].Nick != "alice_" {
+		t.Fatalf("chain = %+v", report.Chain)
+	}
+	if len(report.Aliases) != 1 || report.Aliases[0].Nick != "al2" {
+		t.Fatalf("aliases = %+v", report.Aliases)
+	}
+
+	if report, err := a.GetSeen(net.ID, "nobody"); err != nil || report != nil {
+		t.Fatalf("GetSeen(nobody) = %+v, %v; want nil", report, err)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matt0x6f/irc-client/internal/security"
+)
+
+// ServicesLogin describes a network's NickServ login for networks without
+// SASL. The password is never returned.
+type ServicesLogin struct {
+	Account     string `json:"account"`     // "" means the network's nick
+	PasswordSet bool   `json:"passwordSet"` // whether the automation is on
+	Package     string `json:"package"`     // "atheme", "anope" or "ergo" while connected, else ""
+}
+
+// GetServicesLogin returns the network's NickServ login settings.
+func (a *App) GetServicesLogin(networkID int64) (ServicesLogin, error) {
+	account, password := a.servicesLogin(networkID)
+	login := ServicesLogin{Account: account, PasswordSet: password != ""}
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if exists && client.IsConnected() {
+		login.Package = client.ServicesPackage()
+	}
+	return login, nil
+}
+
+// SetServicesLogin stores the NickServ account and password in the credential
+// store. On each connection where SASL did not log in, Cascade identifies with
+// them before joining channels, recovers the nick when it's held and asks
+// ChanServ for access to +i and +k channels. An empty password turns this off.
+func (a *App) SetServicesLogin(networkID int64, account, password string) error {
+	if a.creds == nil {
+		return fmt.Errorf("no credential store is available")
+	}
+	account = strings.TrimSpace(account)
+	if strings.ContainsAny(account, " \r\n") || strings.ContainsAny(password, " \r\n") {
+		return fmt.Errorf("the account and password must not contain spaces or line breaks")
+	}
+	if _, err := a.creds.Store(networkID, security.FieldServicesAccount, account); err != nil {
+		return fmt.Errorf("store services account: %w", err)
+	}
+	if _, err := a.creds.Store(networkID, security.FieldServicesPassword, password); err != nil {
+		return fmt.Errorf("store services password: %w", err)
+	}
+	a.mu.RLock()
+	client, exists := a.ircClients[networkID]
+	a.mu.RUnlock()
+	if exists {
+		client.SetServicesLogin(account, password)
+	}
+	return nil
+}
+
+// servicesLogin resolves the network's NickServ account and password.
+func (a *App) servicesLogin(networkID int64) (account, password string) {
+	return a.creds.Resolve(networkID, security.FieldServicesAccount, ""),
+		a.creds.Resolve(networkID, security.FieldServicesPassword, "")
+}
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestServicesLoginIsKeptInCredentialStore(t *testing.T) {
+	a := newCredsTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "ServicesNet")
+
+	if err := a.SetServicesLogin(net.ID, "matt", "two words"); err == nil {
+		t.Error("SetServicesLogin accepted a password with a space")
+	}
+	if err := a.SetServicesLogin(net.ID, " matt ", "hunter2"); err != nil {
+		t.Fatalf("SetServicesLogin: %v", err)
+	}
+	got, err := a.GetServicesLogin(net.ID)
+	if err != nil || got != (ServicesLogin{Account: "matt", PasswordSet: true}) {
+		t.Fatalf("GetServicesLogin = %+v, %v", got, err)
+	}
+	if account, password := a.servicesLogin(net.ID); account != "matt" || password != "hunter2" {
+		t.Fatalf("servicesLogin = %q, %q", account, password)
+	}
+
+	if err := a.SetServicesLogin(net.ID, "", ""); err != nil {
+		t.Fatalf("SetServicesLogin(off): %v", err)
+	}
+	if got, _ := a.GetServicesLogin(net.ID); got.PasswordSet {
+		t.Fatalf("GetServicesLogin after clearing = %+v, want no password", got)
+	}
+}
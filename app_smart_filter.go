@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+)
+
+// SetChannelSmartFilter sets the channel's smart filter window: joins, parts
+// and quits from nicks that haven't spoken there in the last `minutes` minutes
+// are stored filtered and hidden from the channel's history. 0 turns the
+// filter off and shows the lines it had hidden again.
+func (a *App) SetChannelSmartFilter(networkID int64, channelName string, minutes int) error {
+	if minutes < 0 || minutes > irc.SmartFilterMaxMinutes {
+		return fmt.Errorf("smart filter window must be between 0 and %d minutes", irc.SmartFilterMaxMinutes)
+	}
+	channel, err := a.storage.GetChannelByName(networkID, channelName)
+	if err != nil {
+		return fmt.Errorf("channel not found: %w", err)
+	}
+	return a.storage.UpdateChannelSmartFilter(channel.ID, minutes)
+}
+
+// cmdSmartFilter shows or sets a channel's smart filter window.
+func cmdSmartFilter(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	channel := buffer
+	if len(args) > 0 && client.IsChannelName(args[0]) {
+		channel, args = args[0], args[1:]
+	}
+	if !client.IsChannelName(channel) || len(args) > 1 {
+		return fmt.Errorf("usage: /smartfilter [#channel] [minutes|off] (or run it in a channel)")
+	}
+
+	if len(args) == 0 {
+		ch, err := a.storage.GetChannelByName(networkID, channel)
+		if err != nil {
+			return fmt.Errorf("channel not found: %w", err)
+		}
+		line := fmt.Sprintf("The smart filter is off in %s.", channel)
+		if ch.SmartFilterMinutes > 0 {
+			line = fmt.Sprintf("%s hides joins, parts and quits from nicks silent for %d minute(s).", channel, ch.SmartFilterMinutes)
+		}
+		return a.PrintLocalLines(networkID, buffer, []string{line})
+	}
+
+	minutes := 0
+	if value := strings.ToLower(args[0]); value != "off" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("usage: /smartfilter [#channel] [minutes|off]")
+		}
+		minutes = n
+	}
+	if err := a.SetChannelSmartFilter(networkID, channel, minutes); err != nil {
+		return err
+	}
+	line := fmt.Sprintf("Smart filter off in %s; hidden lines are shown again.", channel)
+	if minutes > 0 {
+		line = fmt.Sprintf("%s now hides joins, parts and quits from nicks silent for %d minute(s).", channel, minutes)
+	}
+	return a.PrintLocalLines(networkID, buffer, []string{line})
+}
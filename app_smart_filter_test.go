@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// TestSetChannelSmartFilterValidatesWindow confirms the window is bounded and
+// persisted on the channel.
+func TestSetChannelSmartFilterValidatesWindow(t *testing.T) {
+	a := newTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "FilterNet")
+	ch := &storage.Channel{NetworkID: net.ID, Name: "#busy", CreatedAt: time.Now()}
+	if err := a.storage.CreateChannel(ch); err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+
+	for _, bad := range []int{-1, 24*60 + 1} {
+		if err := a.SetChannelSmartFilter(net.ID, "#busy", bad); err == nil {
+			t.Fatalf("SetChannelSmartFilter(%d) succeeded, want an error", bad)
+		}
+	}
+	if err := a.SetChannelSmartFilter(net.ID, "#nowhere", 5); err == nil {
+		t.Fatal("SetChannelSmartFilter on an unknown channel succeeded")
+	}
+	if err := a.SetChannelSmartFilter(net.ID, "#BUSY", 10); err != nil {
+		t.Fatalf("SetChannelSmartFilter: %v", err)
+	}
+	got, err := a.storage.GetChannelByName(net.ID, "#busy")
+	if err != nil || got.SmartFilterMinutes != 10 {
+		t.Fatalf("channel = %+v, %v; want a 10 minute window", got, err)
+	}
+}
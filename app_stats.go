@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/irc"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+const (
+	statsRefreshInterval = time.Minute
+	statsRefreshBatch    = 5000
+	defaultStatsWindow   = 7 * 24 * time.Hour
+	statsCommandTop      = 10
+)
+
+// ChannelStats summarises a channel's activity over a window. ByHour and
+// ByWeekday count lines in local time (ByWeekday starts on Sunday); Daily
+// holds one entry per local day that saw any activity.
+type ChannelStats struct {
+	Channel    string                 `json:"channel"`
+	From       int64                  `json:"from"` // Unix seconds; 0 means from the start
+	To         int64                  `json:"to"`   // Unix seconds; 0 means until now
+	Lines      int64                  `json:"lines"`
+	Words      int64                  `json:"words"`
+	URLs       int64                  `json:"urls"`
+	Joins      int64                  `json:"joins"`
+	Parts      int64                  `json:"parts"`
+	Nicks      int                    `json:"nicks"`
+	Speakers   int                    `json:"speakers"`
+	TopTalkers []storage.NickActivity `json:"topTalkers"`
+	ByHour     []int64                `json:"byHour"`
+	ByWeekday  []int64                `json:"byWeekday"`
+	Daily      []ChannelStatsDay      `json:"daily"`
+}
+
+// ChannelStatsDay is one local day's traffic and join/part churn.
+type ChannelStatsDay struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Lines int64  `json:"lines"`
+	Joins int64  `json:"joins"`
+	Parts int64  `json:"parts"`
+}
+
+// GetChannelStats returns activity statistics for a channel between from and
+// to (Unix seconds, 0 for an open end), with up to top talkers (0 for every
+// nick seen). The window is matched to whole hours.
+func (a *App) GetChannelStats(networkID int64, channel string, from, to int64, top int) (*ChannelStats, error) {
+	ch, err := a.statsChannel(networkID, channel)
+	if err != nil {
+		return nil, err
+	}
+	fromTime, toTime := unixOrZero(from), unixOrZero(to)
+	nicks, err := a.storage.ChannelNickActivity(ch.ID, fromTime, toTime)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := a.storage.ChannelHourActivity(ch.ID, fromTime, toTime)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ChannelStats{
+		Channel:   ch.Name,
+		From:      from,
+		To:        to,
+		Nicks:     len(nicks),
+		ByHour:    make([]int64, 24),
+		ByWeekday: make([]int64, 7),
+		Daily:     []ChannelStatsDay{},
+	}
+	for _, n := range nicks {
+		if n.Lines > 0 {
+			stats.Speakers++
+		}
+	}
+	if top > 0 && len(nicks) > top {
+		nicks = nicks[:top]
+	}
+	stats.TopTalkers = nicks
+
+	for _, h := range hours {
+		stats.Lines += h.Lines
+		stats.Words += h.Words
+		stats.URLs += h.URLs
+		stats.Joins += h.Joins
+		stats.Parts += h.Parts
+
+		local := h.Hour.Local()
+		stats.ByHour[local.Hour()] += h.Lines
+		stats.ByWeekday[local.Weekday()] += h.Lines
+		date := local.Format(time.DateOnly)
+		if n := len(stats.Daily); n == 0 || stats.Daily[n-1].Date != date {
+			stats.Daily = append(stats.Daily, ChannelStatsDay{Date: date})
+		}
+		day := &stats.Daily[len(stats.Daily)-1]
+		day.Lines += h.Lines
+		day.Joins += h.Joins
+		day.Parts += h.Parts
+	}
+	return stats, nil
+}
+
+// GetChannelNickStats returns one nick's activity in a channel between from
+// and to (Unix seconds, 0 for an open end), or nil if it was not seen there.
+func (a *App) GetChannelNickStats(networkID int64, channel, nick string, from, to int64) (*storage.NickActivity, error) {
+	ch, err := a.statsChannel(networkID, channel)
+	if err != nil {
+		return nil, err
+	}
+	activity, ok, err := a.storage.ChannelNickActivityFor(ch.ID, a.nickKey(networkID, nick), unixOrZero(from), unixOrZero(to))
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// statsChannel looks up a channel and folds any messages stored since the last
+// background refresh into its statistics, so answers include recent lines.
+func (a *App) statsChannel(networkID int64, channel string) (*storage.Channel, error) {
+	ch, err := a.storage.GetChannelByName(networkID, channel)
+	if err != nil {
+		return nil, fmt.Errorf("unknown channel %s", channel)
+	}
+	if _, err := a.storage.RefreshChannelStats(statsRefreshBatch, a.nickKey); err != nil {
+		logger.Log.Warn().Err(err).Msg("failed to refresh channel stats")
+	}
+	return ch, nil
+}
+
+// startChannelStatsRefresher keeps the channel statistics aggregates up to
+// date with the message log.
+func (a *App) startChannelStatsRefresher() {
+	a.startupWg.Add(1)
+	go func() {
+		defer a.startupWg.Done()
+		ticker := time.NewTicker(statsRefreshInterval)
+		defer ticker.Stop()
+		for {
+			a.refreshChannelStats()
+			select {
+			case <-a.startupCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// refreshChannelStats folds new messages into the aggregates a batch at a time
+// until it catches up or the app shuts down.
+func (a *App) refreshChannelStats() {
+	for a.startupCtx.Err() == nil {
+		n, err := a.storage.RefreshChannelStats(statsRefreshBatch, a.nickKey)
+		if err != nil {
+			logger.Log.Warn().Err(err).Msg("failed to refresh channel stats")
+			return
+		}
+		if n < statsRefreshBatch {
+			return
+		}
+	}
+}
+
+func unixOrZero(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// parseStatsWindow reads a /stats window such as 24h, 7d, 4w or all. It
+// returns zero for all.
+func parseStatsWindow(s string) (time.Duration, bool) {
+	if strings.EqualFold(s, "all") {
+		return 0, true
+	}
+	if len(s) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "h":
+		return time.Duration(n) * time.Hour, true
+	case "d":
+		return time.Duration(n) * 24 * time.Hour, true
+	case "w":
+		return time.Duration(n) * 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// cmdStats prints channel statistics: /stats [#channel] [nick] [window]. A
+// single-letter first argument is the server's STATS query (/stats o,
+// /stats l server) and is sent to the server as typed.
+func cmdStats(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	if isServerStatsQuery(args) {
+		return client.SendRawCommand("STATS " + strings.Join(args, " "))
+	}
+	channel, nick := "", ""
+	window, label := defaultStatsWindow, "the last 7d"
+	for _, arg := range args {
+		if d, ok := parseStatsWindow(arg); ok {
+			window, label = d, "the last "+arg
+			if d == 0 {
+				label = "all time"
+			}
+			continue
+		}
+		switch {
+		case irc.IsChannelName(arg) && channel == "":
+			channel = arg
+		case nick == "":
+			nick = arg
+		default:
+			return fmt.Errorf("usage: /stats [#channel] [nick] [24h|7d|4w|all]")
+		}
+	}
+	if channel == "" {
+		if !irc.IsChannelName(buffer) {
+			return fmt.Errorf("usage: /stats #channel [nick] [window] (or run it in a channel)")
+		}
+		channel = buffer
+	}
+	var from int64
+	if window > 0 {
+		from = time.Now().Add(-window).Unix()
+	}
+
+	if nick != "" {
+		activity, err := a.GetChannelNickStats(networkID, channel, nick, from, 0)
+		if err != nil {
+			return err
+		}
+		if activity == nil {
+			return a.PrintLocalLines(networkID, buffer, []string{fmt.Sprintf("%s was not seen in %s in %s.", nick, channel, label)})
+		}
+		return a.PrintLocalLines(networkID, buffer, []string{
+			fmt.Sprintf("%s in %s, %s: %d line(s), %d word(s), %d URL(s), %d join(s), %d part(s).",
+				activity.Nick, channel, label, activity.Lines, activity.Words, activity.URLs, activity.Joins, activity.Parts),
+			fmt.Sprintf("First seen %s, last seen %s.", activity.FirstSeen.Format(time.DateTime), activity.LastSeen.Format(time.DateTime)),
+		})
+	}
+
+	stats, err := a.GetChannelStats(networkID, channel, from, 0, statsCommandTop)
+	if err != nil {
+		return err
+	}
+	lines := []string{fmt.Sprintf("%s, %s: %d line(s) from %d of %d nick(s), %d word(s), %d URL(s), %d join(s), %d part(s).",
+		stats.Channel, label, stats.Lines, stats.Speakers, stats.Nicks, stats.Words, stats.URLs, stats.Joins, stats.Parts)}
+	if stats.Lines > 0 {
+		lines = append(lines, "Top talkers:")
+		for i, n := range stats.TopTalkers {
+			if n.Lines == 0 {
+				break
+			}
+			lines = append(lines, fmt.Sprintf("  %2d. %-16s %6d line(s) %7d word(s)  last seen %s", i+1, n.Nick, n.Lines, n.Words, n.LastSeen.Format(time.DateTime)))
+		}
+		lines = append(lines,
+			fmt.Sprintf("Busiest hour: %02d:00. Busiest day: %s.", busiest(stats.ByHour), time.Weekday(busiest(stats.ByWeekday))))
+	}
+	return a.PrintLocalLines(networkID, buffer, lines)
+}
+
+// isServerStatsQuery reports whether /stats arguments are a server STATS query,
+// whose first argument is a single letter such as o, u or l.
+func isServerStatsQuery(args []string) bool {
+	if len(args) == 0 || len(args[0]) != 1 {
+		return false
+	}
+	c := args[0][0]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// busiest returns the index of the largest count, the earliest on a tie.
+func busiest(counts []int64) int {
+	best := 0
+	for i, n := range counts {
+		if n > counts[best] {
+			best = i
+		}
+	}
+	return best
+}
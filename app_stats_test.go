@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// TestGetChannelStatsSummarisesWindow confirms the stats answer folds in
+// messages stored since the last refresh and limits the talker list without
+// losing the nick counts.
+func TestGetChannelStatsSummarisesWindow(t *testing.T) {
+	a := newTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "StatsNet")
+	ch := &storage.Channel{NetworkID: net.ID, Name: "#go", IsOpen: true, CreatedAt: time.Now()}
+	if err := a.storage.CreateChannel(ch); err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	now := time.Now()
+	for i, m := range []struct{ user, kind, text string }{
+		{"ann", "join", ""},
+		{"ann", "privmsg", "hello there"},
+		{"ann", "privmsg", "read https://go.dev"},
+		{"ben", "privmsg", "hi"},
+		{"cat", "join", ""},
+		{"cat", "part", "bye"},
+		{"[eve]", "privmsg", "one"},
+		{"{Eve}", "privmsg", "two"},
+	} {
+		if err := a.storage.WriteMessageSync(storage.Message{
+			NetworkID:   net.ID,
+			ChannelID:   &ch.ID,
+			User:        m.user,
+			Message:     m.text,
+			MessageType: m.kind,
+			Timestamp:   now.Add(time.Duration(i-10) * time.Minute),
+		}); err != nil {
+			t.Fatalf("WriteMessageSync: %v", err)
+		}
+	}
+
+	stats, err := a.GetChannelStats(net.ID, "#GO", now.Add(-24*time.Hour).Unix(), 0, 1)
+	if err != nil {
+		t.Fatalf("GetChannelStats: %v", err)
+	}
+	if stats.Lines != 5 || stats.Words != 7 || stats.URLs != 1 || stats.Joins != 2 || stats.Parts != 1 {
+		t.Errorf("totals = %+v", stats)
+	}
+	if stats.Nicks != 4 || stats.Speakers != 3 {
+		t.Errorf("nicks = %d, speakers = %d; want 4 and 3", stats.Nicks, stats.Speakers)
+	}
+	if len(stats.TopTalkers) != 1 || stats.TopTalkers[0].Nick != "ann" {
+		t.Errorf("top talkers = %+v, want only ann", stats.TopTalkers)
+	}
+	var byHour, byWeekday, daily int64
+	for _, n := range stats.ByHour {
+		byHour += n
+	}
+	for _, n := range stats.ByWeekday {
+		byWeekday += n
+	}
+	for _, d := range stats.Daily {
+		daily += d.Lines
+	}
+	if byHour != 5 || byWeekday != 5 || daily != 5 {
+		t.Errorf("lines by hour/weekday/day = %d/%d/%d, want 5 each", byHour, byWeekday, daily)
+	}
+
+	nick, err := a.GetChannelNickStats(net.ID, "#go", "BEN", 0, 0)
+	if err != nil || nick == nil || nick.Lines != 1 {
+		t.Errorf("ben = %+v, %v", nick, err)
+	}
+	// Nicks are folded with the network's CASEMAPPING (rfc1459 by default).
+	if eve, err := a.GetChannelNickStats(net.ID, "#go", "[EVE]", 0, 0); err != nil || eve == nil || eve.Lines != 2 {
+		t.Errorf("eve = %+v, %v; want [eve] and {Eve} counted together", eve, err)
+	}
+	if nick, err := a.GetChannelNickStats(net.ID, "#go", "dan", 0, 0); err != nil || nick != nil {
+		t.Errorf("unseen nick = %+v, %v; want nil", nick, err)
+	}
+}
+
+func TestParseStatsWindow(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"24h": 24 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+		"2W":  14 * 24 * time.Hour,
+		"all": 0,
+	} {
+		if got, ok := parseStatsWindow(in); !ok || got != want {
+			t.Errorf("parseStatsWindow(%q) = %v, %v; want %v", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "d", "0d", "7m", "alice"} {
+		if _, ok := parseStatsWindow(in); ok {
+			t.Errorf("parseStatsWindow(%q) accepted", in)
+		}
+	}
+}
+
+func TestIsServerStatsQuery(t *testing.T) {
+	for _, args := range [][]string{{"o"}, {"u"}, {"l", "hub.example.net"}} {
+		if !isServerStatsQuery(args) {
+			t.Errorf("%q is not sent to the server", args)
+		}
+	}
+	for _, args := range [][]string{nil, {"#go"}, {"alice"}, {"7d"}, {"#go", "o"}} {
+		if isServerStatsQuery(args) {
+			t.Errorf("%q is sent to the server", args)
+		}
+	}
+}
@@ -16,6 +16,7 @@ type Client struct {
 	partFn       func(networkName, channel, reason string)
 	changeNickFn func(networkName, nick string)
 	setAwayFn    func(networkName, message string)
+	seenFn       func(networkName, nick string) Seen
 }
 
 // WithIRCActions binds the proactive IRC operations available to scripts.
@@ -55,6 +56,13 @@ func WithNetworkQueries(
 	}
 }
 
+// WithSeenQuery binds the seen database lookup.
+func WithSeenQuery(seen func(networkName, nick string) Seen) ClientOption {
+	return func(c *Client) {
+		c.seenFn = seen
+	}
+}
+
 // NewClient is the host-side constructor.
 func NewClient(say func(networkName, target, message string), every func(interval string, fn func()), after func(delay string, fn func()), options ...ClientOption) *Client {
 	c := &Client{sayFn: say, everyFn: every, afterFn: after}
@@ -83,6 +91,7 @@ func (c *Client) Network(name string) Network {
 		partFn:       c.partFn,
 		changeNickFn: c.changeNickFn,
 		setAwayFn:    c.setAwayFn,
+		seenFn:       c.seenFn,
 	}
 }
 
@@ -114,6 +123,7 @@ type Network struct {
 	partFn       func(networkName, channel, reason string)
 	changeNickFn func(networkName, nick string)
 	setAwayFn    func(networkName, message string)
+	seenFn       func(networkName, nick string) Seen
 }
 
 // UserStatus is Cascade's latest session-local metadata snapshot for a nick.
@@ -127,6 +137,25 @@ type UserStatus struct {
 	Realname    string
 }
 
+// Seen is the last thing a nick was seen doing on a network, remembered across
+// sessions. Known is false when the nick has never been seen. Action is one of
+// "join", "part", "quit", "kicked", "nick-to", "nick-from", "chghost",
+// "account", "message" or "notice". Target is the other nick of a nick change
+// (follow "nick-to" with Seen(Target)) or kick, or the new account; Message is
+// a reason, or the text of a channel message. Channel is empty for private
+// messages.
+type Seen struct {
+	Known   bool
+	Nick    string
+	Action  string
+	Channel string
+	Target  string
+	Message string
+	Host    string
+	Account string
+	Time    Time
+}
+
 // User is a network-scoped nickname handle.
 type User struct {
 	networkName string
@@ -135,6 +164,7 @@ type User struct {
 	isMeFn      func(networkName, nick string) bool
 	statusFn    func(networkName, nick string) UserStatus
 	noticeFn    func(networkName, target, message string)
+	seenFn      func(networkName, nick string) Seen
 }
 
 // Name returns the configured network name used by this handle.
@@ -163,7 +193,15 @@ func (n Network) Self() User { return n.User(n.Nick()) }
 
 // User returns a handle for nick without performing network I/O.
 func (n Network) User(nick string) User {
-	return User{networkName: n.name, nick: nick, sayFn: n.sayFn, noticeFn: n.noticeFn, isMeFn: n.isMeFn, statusFn: n.userStatusFn}
+	return User{networkName: n.name, nick: nick, sayFn: n.sayFn, noticeFn: n.noticeFn, isMeFn: n.isMeFn, statusFn: n.userStatusFn, seenFn: n.seenFn}
+}
+
+// Seen looks nick up in the seen database, or returns an unknown zero value.
+func (n Network) Seen(nick string) Seen {
+	if n.seenFn == nil {
+		return Seen{}
+	}
+	return n.seenFn(n.name, nick)
 }
 
 // Nick returns the nickname addressed by this handle.
@@ -182,6 +220,14 @@ func (u User) Status() UserStatus {
 	return u.statusFn(u.networkName, u.nick)
 }
 
+// LastSeen looks this user up in the seen database. See Network.Seen.
+func (u User) LastSeen() Seen {
+	if u.seenFn == nil {
+		return Seen{}
+	}
+	return u.seenFn(u.networkName, u.nick)
+}
+
 // Known reports whether Cascade has current-session metadata for this user.
 func (u User) Known() bool { return u.Status().Known }
 
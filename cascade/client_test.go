@@ -128,3 +128,23 @@ func TestClientIRCActionsAreNilSafe(t *testing.T) {
 	n.SetAway("away")
 	n.ClearAway()
 }
+
+func TestClientSeenQuery(t *testing.T) {
+	c := NewClient(nil, nil, nil, WithSeenQuery(func(network, nick string) Seen {
+		if network == "libera" && nick == "Alice" {
+			return Seen{Known: true, Nick: "Alice", Action: "nick-to", Target: "alice_", Time: NewTime(1700000000)}
+		}
+		return Seen{}
+	}))
+
+	net := c.Network("libera")
+	if got := net.Seen("Alice"); !got.Known || got.Target != "alice_" || got.Time.Unix() != 1700000000 {
+		t.Fatalf("Seen(Alice) = %+v", got)
+	}
+	if got := net.User("Alice").LastSeen(); got.Action != "nick-to" {
+		t.Fatalf("User.LastSeen = %+v", got)
+	}
+	if got := NewClient(nil, nil, nil).Network("libera").Seen("Alice"); got.Known {
+		t.Fatalf("unbound seen query = %+v", got)
+	}
+}
@@ -2,87 +2,79 @@ package main
 
 import (
 	"testing"
-)
+	"time"
 
-func newCacheApp() *App {
-	return &App{channelListCache: make(map[int64]channelListCacheEntry)}
-}
+	"github.com/matt0x6f/irc-client/internal/irc"
+)
 
 func TestGetCachedChannelList_Miss(t *testing.T) {
-	a := newCacheApp()
-	res := a.GetCachedChannelList(42)
+	a := newTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "ListNet")
+	res := a.GetCachedChannelList(net.ID)
 	if res.Found {
-		t.Fatalf("expected Found=false for empty cache, got %+v", res)
+		t.Fatalf("expected Found=false before any LIST, got %+v", res)
 	}
 	if len(res.Channels) != 0 {
 		t.Fatalf("expected no channels on miss, got %d", len(res.Channels))
 	}
 }
 
-func TestCacheChannelList_StoreAndRead(t *testing.T) {
-	a := newCacheApp()
-	channels := []map[string]interface{}{
-		{"channel": "#go", "users": 10, "topic": "gophers", "networkId": int64(1)},
-		{"channel": "#irc", "users": 3, "topic": "chat", "networkId": int64(1)},
+func TestStoreChannelList_StoreAndRead(t *testing.T) {
+	a := newTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "ListNet")
+	var emitted map[string]interface{}
+	a.emitFn = func(name string, data ...any) {
+		if name == "channel-list" {
+			emitted = data[0].(map[string]interface{})["data"].(map[string]interface{})
+		}
 	}
-	a.cacheChannelList(1, channels)
 
-	res := a.GetCachedChannelList(1)
-	if !res.Found {
-		t.Fatal("expected Found=true after caching")
-	}
-	if len(res.Channels) != 2 {
-		t.Fatalf("expected 2 channels, got %d", len(res.Channels))
+	a.storeChannelList(net.ID, []irc.ChannelListItem{
+		{Channel: "#irc", Users: 3, Topic: "chat"},
+		{Channel: "#go", Users: 10, Topic: "gophers", Modes: "+nt"},
+	}, "")
+
+	res := a.GetCachedChannelList(net.ID)
+	if !res.Found || res.FetchedAt <= 0 {
+		t.Fatalf("expected a stamped directory after storing, got %+v", res)
 	}
-	if res.Channels[0]["channel"] != "#go" {
-		t.Fatalf("unexpected first channel: %v", res.Channels[0]["channel"])
+	if len(res.Channels) != 2 || res.Channels[0].Channel != "#go" || res.Channels[0].Modes != "+nt" {
+		t.Fatalf("unexpected directory: %+v", res.Channels)
 	}
-	if res.FetchedAt <= 0 {
-		t.Fatalf("expected positive FetchedAt, got %d", res.FetchedAt)
+	if emitted == nil || emitted["listed"] != 2 || emitted["networkId"] != net.ID {
+		t.Fatalf("unexpected channel-list event: %+v", emitted)
 	}
 }
 
-func TestCacheChannelList_Overwrite(t *testing.T) {
-	a := newCacheApp()
-	a.cacheChannelList(1, []map[string]interface{}{{"channel": "#old"}})
-	first := a.GetCachedChannelList(1)
-
-	a.cacheChannelList(1, []map[string]interface{}{
-		{"channel": "#new-a"},
-		{"channel": "#new-b"},
-	})
-	second := a.GetCachedChannelList(1)
+// TestStoreChannelList_FilteredFetchKeepsTheRest confirms a narrowed LIST only
+// updates what it returned, while the next full LIST drops vanished channels
+// and flags the ones it lists for the first time.
+func TestStoreChannelList_FilteredFetchKeepsTheRest(t *testing.T) {
+	a := newTestApp(t)
+	net := makeAppTestNetwork(t, a.storage, "ListNet")
+	a.storeChannelList(net.ID, []irc.ChannelListItem{{Channel: "#old"}, {Channel: "#go", Users: 10}}, "")
+	time.Sleep(2 * time.Millisecond) // fetches are stamped in milliseconds
 
-	if len(second.Channels) != 2 {
-		t.Fatalf("expected overwrite to 2 channels, got %d", len(second.Channels))
+	a.storeChannelList(net.ID, []irc.ChannelListItem{{Channel: "#go", Users: 60}}, ">50")
+	res := a.GetCachedChannelList(net.ID)
+	if len(res.Channels) != 2 || res.Channels[0].Users != 60 || res.Filter != ">50" {
+		t.Fatalf("after a filtered fetch: %+v", res)
 	}
-	if second.Channels[0]["channel"] != "#new-a" {
-		t.Fatalf("expected overwritten data, got %v", second.Channels[0]["channel"])
-	}
-	if second.FetchedAt < first.FetchedAt {
-		t.Fatalf("expected FetchedAt to advance on overwrite: first=%d second=%d", first.FetchedAt, second.FetchedAt)
-	}
-}
+	time.Sleep(2 * time.Millisecond)
 
-func TestChannelsFromEventData(t *testing.T) {
-	// Matches the []interface{} of map[string]interface{} shape emitted on LISTEND.
-	raw := []interface{}{
-		map[string]interface{}{"channel": "#a", "users": 1},
-		map[string]interface{}{"channel": "#b", "users": 2},
-		"not-a-map", // ignored
+	a.storeChannelList(net.ID, []irc.ChannelListItem{{Channel: "#go", Users: 61}, {Channel: "#rust", Users: 5}}, "")
+	res = a.GetCachedChannelList(net.ID)
+	if len(res.Channels) != 2 || res.NewCount != 1 {
+		t.Fatalf("after a full fetch: %+v", res)
 	}
-	got := channelsFromEventData(raw)
-	if len(got) != 2 {
-		t.Fatalf("expected 2 valid channels, got %d", len(got))
-	}
-	if got[1]["channel"] != "#b" {
-		t.Fatalf("unexpected channel: %v", got[1]["channel"])
+	for _, ch := range res.Channels {
+		if ch.New != (ch.Channel == "#rust") {
+			t.Errorf("%s new = %v", ch.Channel, ch.New)
+		}
 	}
 
-	if channelsFromEventData(nil) != nil {
-		t.Fatal("expected nil for nil input")
-	}
-	if channelsFromEventData("garbage") != nil {
-		t.Fatal("expected nil for non-slice input")
+	found, err := a.SearchChannelDirectory(net.ID, "rus", true, 0)
+	if err != nil || len(found) != 1 || found[0].Channel != "#rust" {
+		t.Fatalf("search = %+v, %v", found, err)
 	}
 }
@@ -0,0 +1,213 @@
+// Command cascade-cli drives a running Cascade over its local control socket
+// (see internal/control).
+//
+//	cascade-cli networks
+//	cascade-cli channels <network>
+//	cascade-cli send <network> <target> [message...]   (no message: one per stdin line)
+//	cascade-cli command <network> </command args...>
+//	cascade-cli search [-network name] [-limit n] <query...>
+//	cascade-cli tail [-type event.type]...
+//	cascade-cli backup [file]
+//	cascade-cli restore <file>
+//
+// Networks are named by their Cascade name (case-insensitive) or numeric ID.
+// backup and restore take the passphrase from $CASCADE_BACKUP_PASSPHRASE, or
+// use the one stored for scheduled backups when it is unset. Backup files are
+// paths on the machine running Cascade.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/matt0x6f/irc-client/internal/control"
+)
+
+const usage = `usage: cascade-cli [-socket path] <command> [args]
+
+commands:
+  networks                              list configured networks
+  channels <network>                    list joined channels
+  send <network> <target> [message...]  send a message; without one, send each stdin line
+  command <network> <command...>        run a slash command, e.g. "/join #ops"
+  search [-network n] [-limit n] <query...>
+                                        search message history
+  tail [-type t]...                     stream events as JSON lines
+  backup [file]                         write an encrypted backup (default: the backup directory)
+  restore <file>                        stage a backup to replace all data at the next start
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "cascade-cli:", err)
+		os.Exit(1)
+	}
+}
+
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
+
+func run(args []string) error {
+	global := flag.NewFlagSet("cascade-cli", flag.ContinueOnError)
+	global.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	socket := global.String("socket", "", "control socket path (default: <data dir>/"+control.SocketName+")")
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	args = global.Args()
+	if len(args) == 0 {
+		global.Usage()
+		return errors.New("no command given")
+	}
+
+	path := *socket
+	if path == "" {
+		dir, err := control.DataDir()
+		if err != nil {
+			return err
+		}
+		path = control.SocketPath(dir)
+	}
+	client, err := control.Dial(path)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "networks":
+		var networks []control.NetworkInfo
+		if err := client.Call(control.MethodNetworks, nil, &networks); err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, n := range networks {
+			state := "disconnected"
+			if n.Connected {
+				state = "connected as " + n.Nick
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", n.ID, n.Name, state)
+		}
+		return w.Flush()
+
+	case "channels":
+		if len(args) != 1 {
+			return errors.New("usage: channels <network>")
+		}
+		var channels []control.ChannelInfo
+		if err := client.Call(control.MethodChannels, control.NetworkParams{Network: args[0]}, &channels); err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, ch := range channels {
+			fmt.Fprintf(w, "%s\t%s\n", ch.Name, ch.Topic)
+		}
+		return w.Flush()
+
+	case "send":
+		if len(args) < 2 {
+			return errors.New("usage: send <network> <target> [message...]")
+		}
+		send := func(message string) error {
+			return client.Call(control.MethodSend, control.SendParams{Network: args[0], Target: args[1], Message: message}, nil)
+		}
+		if len(args) > 2 {
+			return send(strings.Join(args[2:], " "))
+		}
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if line := strings.TrimRight(scanner.Text(), "\r"); strings.TrimSpace(line) != "" {
+				if err := send(line); err != nil {
+					return err
+				}
+			}
+		}
+		return scanner.Err()
+
+	case "command":
+		if len(args) < 2 {
+			return errors.New("usage: command <network> <command...>")
+		}
+		line := strings.Join(args[1:], " ")
+		if !strings.HasPrefix(line, "/") {
+			line = "/" + line
+		}
+		return client.Call(control.MethodCommand, control.CommandParams{Network: args[0], Command: line}, nil)
+
+	case "search":
+		fs := flag.NewFlagSet("search", flag.ContinueOnError)
+		network := fs.String("network", "", "limit to one network")
+		limit := fs.Int("limit", 50, "maximum results")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			return errors.New("usage: search [-network n] [-limit n] <query...>")
+		}
+		var hits []control.SearchHit
+		params := control.SearchParams{Query: strings.Join(fs.Args(), " "), Network: *network, Limit: *limit}
+		if err := client.Call(control.MethodSearch, params, &hits); err != nil {
+			return err
+		}
+		for _, h := range hits {
+			fmt.Printf("%s %s/%s <%s> %s\n", h.Timestamp.Local().Format("2006-01-02 15:04"), h.Network, h.Channel, h.User, h.Message)
+		}
+		return nil
+
+	case "tail":
+		fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+		var types stringList
+		fs.Var(&types, "type", "event type to stream (repeatable; default all)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		return client.Subscribe(types, func(e control.Event) error { return enc.Encode(e) })
+
+	case "backup", "restore":
+		if command == "backup" && len(args) > 1 {
+			return errors.New("usage: backup [file]")
+		}
+		if command == "restore" && len(args) != 1 {
+			return errors.New("usage: restore <file>")
+		}
+		params := control.BackupParams{Passphrase: os.Getenv("CASCADE_BACKUP_PASSPHRASE")}
+		if len(args) == 1 {
+			// The socket is local, so a path relative to this shell means
+			// the same file to Cascade once made absolute.
+			path, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			params.Path = path
+		}
+		method := control.MethodBackup
+		if command == "restore" {
+			method = control.MethodRestore
+		}
+		var result control.BackupResult
+		if err := client.Call(method, params, &result); err != nil {
+			return err
+		}
+		if command == "restore" {
+			fmt.Printf("staged %s (taken %s); restart Cascade to apply it\n", result.Path, result.CreatedAt.Local().Format("2006-01-02 15:04"))
+		} else {
+			fmt.Println(result.Path)
+		}
+		return nil
+
+	default:
+		global.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
@@ -18,12 +18,19 @@ const (
 	CategoryServer CommandCategory = "server" // IRC protocol verb sent to the server
 	CategoryCTCP   CommandCategory = "ctcp"   // CTCP request
 	CategoryPlugin CommandCategory = "plugin" // registered by a plugin
+	CategoryAlias  CommandCategory = "alias"  // user-defined alias
 )
 
-// HandlerFunc runs a built-in command. args is the argument list (the command
-// word removed). The dispatcher has already resolved the client and enforced
-// MinArgs, so handlers may assume len(args) >= MinArgs.
-type HandlerFunc func(a *App, client *irc.IRCClient, networkID int64, args []string) error
+// HandlerFunc runs a built-in command. buffer is the pane the command was typed
+// in ("status", a channel, or a query nick). args is the argument list (the
+// command word removed). The dispatcher has already resolved the client and
+// enforced MinArgs, so handlers may assume len(args) >= MinArgs.
+type HandlerFunc func(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error
+
+// RawHandlerFunc is a HandlerFunc that is also given the command line as
+// typed, without the leading slash, for commands that take free text whose
+// spacing must survive.
+type RawHandlerFunc func(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string, raw string) error
 
 // CommandSpec is the single source of truth for a command: behavior + metadata.
 type CommandSpec struct {
@@ -36,6 +43,7 @@ type CommandSpec struct {
 	Source      string // "" for built-in; plugin name otherwise
 	Frontend    bool   // true => intercepted client-side; dispatch is a no-op
 	handler     HandlerFunc
+	rawHandler  RawHandlerFunc // used instead of handler when set
 }
 
 // CommandRegistry maps command names + aliases to specs.
@@ -88,24 +96,35 @@ func buildBuiltinRegistry() *CommandRegistry {
 	reg(&CommandSpec{Name: "VERSION", Category: CategoryCTCP, Usage: "target", Description: "Request a user's client version", MinArgs: 1, handler: cmdVersion})
 	reg(&CommandSpec{Name: "TIME", Category: CategoryCTCP, Usage: "target", Description: "Request a user's local time", MinArgs: 1, handler: cmdTime})
 	reg(&CommandSpec{Name: "PING", Category: CategoryCTCP, Usage: "target [args]", Description: "CTCP ping a user", MinArgs: 1, handler: cmdPing})
+	reg(&CommandSpec{Name: "DCC", Category: CategoryCTCP, Usage: "chat|close nickname", Description: "Open or close a direct chat that bypasses the server", MinArgs: 2, handler: cmdDcc})
 	reg(&CommandSpec{Name: "CLIENTINFO", Category: CategoryCTCP, Usage: "target", Description: "Request a user's supported CTCP commands", MinArgs: 1, handler: cmdClientinfo})
 	reg(&CommandSpec{Name: "TOPIC", Category: CategoryServer, Usage: "#channel [new topic]", Description: "View or set a channel topic", MinArgs: 1, handler: cmdTopic})
 	reg(&CommandSpec{Name: "MODE", Category: CategoryServer, Usage: "target modes [args]", Description: "View or change modes", MinArgs: 1, handler: cmdMode})
 	reg(&CommandSpec{Name: "INVITE", Category: CategoryServer, Usage: "nickname #channel", Description: "Invite a user to a channel", MinArgs: 2, handler: cmdInvite})
 	reg(&CommandSpec{Name: "KICK", Category: CategoryServer, Usage: "#channel nickname [reason]", Description: "Kick a user from a channel", MinArgs: 2, handler: cmdKick})
-	reg(&CommandSpec{Name: "BAN", Category: CategoryServer, Usage: "#channel mask", Description: "Ban a mask from a channel", MinArgs: 2, handler: cmdBan})
+	reg(&CommandSpec{Name: "BAN", Category: CategoryServer, Usage: "#channel mask [duration]", Description: "Ban a mask from a channel, optionally lifting it after a duration such as 2h", MinArgs: 2, handler: cmdBan})
 	reg(&CommandSpec{Name: "UNBAN", Category: CategoryServer, Usage: "#channel mask", Description: "Remove a ban from a channel", MinArgs: 2, handler: cmdUnban})
 	reg(&CommandSpec{Name: "OP", Aliases: []string{"HOP"}, Category: CategoryServer, Usage: "#channel nickname", Description: "Grant operator status", MinArgs: 2, handler: cmdOp})
 	reg(&CommandSpec{Name: "DEOP", Aliases: []string{"DEHOP"}, Category: CategoryServer, Usage: "#channel nickname", Description: "Remove operator status", MinArgs: 2, handler: cmdDeop})
 	reg(&CommandSpec{Name: "VOICE", Aliases: []string{"V"}, Category: CategoryServer, Usage: "#channel nickname", Description: "Grant voice", MinArgs: 2, handler: cmdVoice})
 	reg(&CommandSpec{Name: "DEVOICE", Aliases: []string{"DEV"}, Category: CategoryServer, Usage: "#channel nickname", Description: "Remove voice", MinArgs: 2, handler: cmdDevoice})
-	reg(&CommandSpec{Name: "LIST", Category: CategoryServer, Usage: "[filter]", Description: "Browse channels (optional filter: name/topic text or user count, e.g. >50)", MinArgs: 0, handler: cmdList})
+	reg(&CommandSpec{Name: "LIST", Category: CategoryServer, Usage: "[filter]", Description: "Browse channels (optional filter: name/topic text, masks such as #linux* or !*bot*, user count such as >50)", MinArgs: 0, handler: cmdList})
 	reg(&CommandSpec{Name: "NAMES", Category: CategoryServer, Usage: "[#channel]", Description: "List users in a channel", MinArgs: 0, handler: cmdNames})
 	reg(&CommandSpec{Name: "NOTICE", Category: CategoryServer, Usage: "target message", Description: "Send a notice", MinArgs: 2, handler: cmdNotice})
 	reg(&CommandSpec{Name: "QUERY", Aliases: []string{"Q"}, Category: CategoryClient, Usage: "nickname [message]", Description: "Open a private conversation", MinArgs: 1, handler: cmdQuery})
 	reg(&CommandSpec{Name: "CLOSE", Category: CategoryClient, Usage: "#channel or nickname", Description: "Close the current channel or query", MinArgs: 1, handler: cmdClose})
 	reg(&CommandSpec{Name: "QUOTE", Aliases: []string{"RAW"}, Category: CategoryServer, Usage: "command [args]", Description: "Send a raw IRC command", MinArgs: 1, handler: cmdQuote})
+	reg(&CommandSpec{Name: "QUEUE", Category: CategoryClient, Usage: "[clear]", Description: "Show lines waiting to be sent on this network; clear drops an unfinished paste", MinArgs: 0, handler: cmdQueue})
+	reg(&CommandSpec{Name: "STATS", Category: CategoryClient, Usage: "[#channel] [nickname] [24h|7d|4w|all] | letter [server]", Description: "Show channel activity: top talkers, busiest times, joins and parts, or one nick's numbers; a single letter (o, u, l ...) is the server's STATS query", MinArgs: 0, handler: cmdStats})
+	reg(&CommandSpec{Name: "SMARTFILTER", Category: CategoryClient, Usage: "[#channel] [minutes|off]", Description: "Show or set how long a nick must have been silent for its joins, parts and quits to be hidden", MinArgs: 0, handler: cmdSmartFilter})
+	reg(&CommandSpec{Name: "E2E", Category: CategoryClient, Usage: "[start|stop|verify|status] [nickname]", Description: "Start, end or check an end-to-end encrypted private conversation, or mark the peer's key as verified", MinArgs: 0, handler: cmdE2E})
+	reg(&CommandSpec{Name: "SETKEY", Category: CategoryClient, Usage: "[#channel|nickname] cbc:key", Description: "Encrypt a channel or query with a FiSH key shared with its other members", MinArgs: 1, handler: cmdSetKey})
+	reg(&CommandSpec{Name: "DELKEY", Category: CategoryClient, Usage: "[#channel|nickname]", Description: "Stop using a FiSH key for a channel or query", MinArgs: 0, handler: cmdDelKey})
+	reg(&CommandSpec{Name: "LATER", Category: CategoryClient, Usage: "when target text | list | cancel id", Description: "Send a message later, after a delay such as 2h or at a time such as 09:30; list or cancel queued ones", MinArgs: 1, rawHandler: cmdLater})
+	reg(&CommandSpec{Name: "SEEN", Category: CategoryClient, Usage: "nickname", Description: "Show when a nick was last seen, doing what, following nick changes", MinArgs: 1, handler: cmdSeen})
 	reg(&CommandSpec{Name: "IGNORE", Category: CategoryClient, Usage: "nickname", Description: "Ignore a user (not yet implemented)", MinArgs: 1, handler: cmdIgnore})
+	reg(&CommandSpec{Name: "ALIAS", Category: CategoryClient, Usage: "[-network] [name [expansion]]", Description: "List, show or define your own commands; -network limits one to this network", MinArgs: 0, handler: cmdAlias})
+	reg(&CommandSpec{Name: "UNALIAS", Category: CategoryClient, Usage: "[-network] name", Description: "Delete one of your own commands", MinArgs: 1, handler: cmdUnalias})
 	reg(&CommandSpec{Name: "UNIGNORE", Category: CategoryClient, Usage: "nickname", Description: "Stop ignoring a user (not yet implemented)", MinArgs: 1, handler: cmdUnignore})
 
 	// Frontend-handled: never dispatched to the backend (intercepted in the
@@ -115,7 +134,7 @@ func buildBuiltinRegistry() *CommandRegistry {
 	return r
 }
 
-func cmdJoin(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdJoin(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	channelName := args[0]
 	// Route the key through JoinChannelWithKey (not raw passthrough) so a
 	// successful keyed join persists the key for auto-rejoin after reconnect.
@@ -131,7 +150,7 @@ func cmdJoin(a *App, client *irc.IRCClient, networkID int64, args []string) erro
 	return nil
 }
 
-func cmdPart(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdPart(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	channelName := args[0]
 	if len(args) >= 2 {
 		return client.PartChannelWithReason(channelName, strings.Join(args[1:], " "))
@@ -139,140 +158,166 @@ func cmdPart(a *App, client *irc.IRCClient, networkID int64, args []string) erro
 	return client.PartChannel(channelName)
 }
 
-func cmdMsg(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdMsg(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendMessage(args[0], strings.Join(args[1:], " "))
 }
 
-func cmdNick(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdNick(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.ChangeNick(args[0])
 }
 
-func cmdQuit(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdQuit(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	if len(args) >= 1 {
 		return client.SendRawCommand(fmt.Sprintf("QUIT :%s", strings.Join(args, " ")))
 	}
 	return client.SendRawCommand("QUIT")
 }
 
-func cmdAway(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdAway(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SetAway(strings.Join(args, " "))
 }
 
-func cmdWhois(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdWhois(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendRawCommand(fmt.Sprintf("WHOIS %s", args[0]))
 }
 
-func cmdWhowas(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdWhowas(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendRawCommand(fmt.Sprintf("WHOWAS %s", args[0]))
 }
 
 // cmdWho issues a user-initiated WHO. RequestWho marks the target pending so its
 // 352/315 replies are surfaced to the status buffer (and kept distinct from the
 // automatic roster-seeding WHOX). Only the mask is used, so the 315 echo correlates.
-func cmdWho(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdWho(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.RequestWho(args[0])
 }
 
-func cmdMe(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdMe(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	if peer, ok := directChatPeer(args[0]); ok {
+		return a.sendDirectChat(networkID, peer, strings.Join(args[1:], " "), true)
+	}
 	return client.SendAction(args[0], strings.Join(args[1:], " "))
 }
 
-func cmdCtcp(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdCtcp(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	ctcpArgs := ""
 	if len(args) > 2 {
 		ctcpArgs = strings.Join(args[2:], " ")
 	}
-	return client.SendCTCPRequest(args[0], strings.ToUpper(args[1]), ctcpArgs)
+	return client.SendCTCPRequestFrom(args[0], strings.ToUpper(args[1]), ctcpArgs, buffer)
 }
 
-func cmdVersion(a *App, client *irc.IRCClient, networkID int64, args []string) error {
-	return client.SendCTCPRequest(args[0], "VERSION", "")
+func cmdVersion(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	return client.SendCTCPRequestFrom(args[0], "VERSION", "", buffer)
 }
 
-func cmdTime(a *App, client *irc.IRCClient, networkID int64, args []string) error {
-	return client.SendCTCPRequest(args[0], "TIME", "")
+func cmdTime(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	return client.SendCTCPRequestFrom(args[0], "TIME", "", buffer)
 }
 
-func cmdPing(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdPing(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	pingArgs := ""
 	if len(args) > 1 {
 		pingArgs = strings.Join(args[1:], " ")
 	}
-	return client.SendCTCPRequest(args[0], "PING", pingArgs)
+	return client.SendCTCPRequestFrom(args[0], "PING", pingArgs, buffer)
 }
 
-func cmdClientinfo(a *App, client *irc.IRCClient, networkID int64, args []string) error {
-	return client.SendCTCPRequest(args[0], "CLIENTINFO", "")
+func cmdClientinfo(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	return client.SendCTCPRequestFrom(args[0], "CLIENTINFO", "", buffer)
 }
 
-func cmdTopic(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+// cmdDcc handles the direct chat subcommands. File transfers are started from
+// the user list, so SEND is not offered here.
+func cmdDcc(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	peer := strings.TrimPrefix(args[1], directChatPrefix)
+	switch strings.ToLower(args[0]) {
+	case "chat":
+		_, err := a.OpenDirectChat(networkID, peer)
+		return err
+	case "close":
+		chat, ok := a.dccManager.FindChat(networkID, peer)
+		if !ok {
+			return fmt.Errorf("no direct chat with %s", peer)
+		}
+		return a.CloseDirectChat(chat.ID)
+	default:
+		return fmt.Errorf("usage: /dcc chat|close nickname")
+	}
+}
+
+func cmdTopic(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	if len(args) >= 2 {
 		return client.SendRawCommand(fmt.Sprintf("TOPIC %s :%s", args[0], strings.Join(args[1:], " ")))
 	}
 	return client.SendRawCommand(fmt.Sprintf("TOPIC %s", args[0]))
 }
 
-func cmdMode(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdMode(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	if len(args) >= 2 {
 		return client.SendRawCommand(fmt.Sprintf("MODE %s %s", args[0], strings.Join(args[1:], " ")))
 	}
 	return client.SendRawCommand(fmt.Sprintf("MODE %s", args[0]))
 }
 
-func cmdInvite(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdInvite(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendRawCommand(fmt.Sprintf("INVITE %s %s", args[0], args[1]))
 }
 
-func cmdKick(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdKick(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	if len(args) >= 3 {
 		return client.SendRawCommand(fmt.Sprintf("KICK %s %s :%s", args[0], args[1], strings.Join(args[2:], " ")))
 	}
 	return client.SendRawCommand(fmt.Sprintf("KICK %s %s", args[0], args[1]))
 }
 
-func cmdBan(a *App, client *irc.IRCClient, networkID int64, args []string) error {
-	return client.SendRawCommand(fmt.Sprintf("MODE %s +b %s", args[0], args[1]))
+func cmdBan(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	if len(args) < 3 {
+		return client.SendRawCommand(fmt.Sprintf("MODE %s +b %s", args[0], args[1]))
+	}
+	duration, err := time.ParseDuration(args[2])
+	if err != nil || duration < time.Second {
+		return fmt.Errorf("invalid ban duration %q (use e.g. 30m or 2h)", args[2])
+	}
+	return a.AddChannelListEntries(networkID, args[0], "b", []string{args[1]}, int64(duration.Round(time.Second)/time.Second))
 }
 
-func cmdUnban(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdUnban(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendRawCommand(fmt.Sprintf("MODE %s -b %s", args[0], args[1]))
 }
 
-func cmdOp(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdOp(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendRawCommand(fmt.Sprintf("MODE %s +o %s", args[0], args[1]))
 }
 
-func cmdDeop(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdDeop(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendRawCommand(fmt.Sprintf("MODE %s -o %s", args[0], args[1]))
 }
 
-func cmdVoice(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdVoice(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendRawCommand(fmt.Sprintf("MODE %s +v %s", args[0], args[1]))
 }
 
-func cmdDevoice(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdDevoice(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendRawCommand(fmt.Sprintf("MODE %s -v %s", args[0], args[1]))
 }
 
-func cmdList(a *App, client *irc.IRCClient, networkID int64, args []string) error {
-	if len(args) >= 1 {
-		return client.SendRawCommand(fmt.Sprintf("LIST %s", strings.Join(args, " ")))
-	}
-	return client.SendRawCommand("LIST")
+func cmdList(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
+	return a.RequestChannelList(networkID, strings.Join(args, " "))
 }
 
-func cmdNames(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdNames(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	if len(args) >= 1 {
 		return client.SendRawCommand(fmt.Sprintf("NAMES %s", args[0]))
 	}
 	return client.SendRawCommand("NAMES")
 }
 
-func cmdNotice(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdNotice(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendRawCommand(fmt.Sprintf("NOTICE %s :%s", args[0], strings.Join(args[1:], " ")))
 }
 
-func cmdQuery(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdQuery(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	nickname := args[0]
 	if len(args) >= 2 {
 		return client.SendMessage(nickname, strings.Join(args[1:], " "))
@@ -300,7 +345,7 @@ func cmdQuery(a *App, client *irc.IRCClient, networkID int64, args []string) err
 	return a.SetPrivateMessageOpen(networkID, nickname, true)
 }
 
-func cmdClose(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdClose(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	target := args[0]
 	if len(target) > 0 && (target[0] == '#' || target[0] == '&') {
 		return client.PartChannel(target)
@@ -308,15 +353,15 @@ func cmdClose(a *App, client *irc.IRCClient, networkID int64, args []string) err
 	return nil
 }
 
-func cmdQuote(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdQuote(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return client.SendRawCommand(strings.Join(args, " "))
 }
 
-func cmdIgnore(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdIgnore(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return fmt.Errorf("/ignore is not yet implemented")
 }
 
-func cmdUnignore(a *App, client *irc.IRCClient, networkID int64, args []string) error {
+func cmdUnignore(a *App, client *irc.IRCClient, networkID int64, buffer string, args []string) error {
 	return fmt.Errorf("/unignore is not yet implemented")
 }
 
@@ -337,7 +382,8 @@ func specToInfo(s *CommandSpec) CommandInfo {
 	}
 }
 
-// mergeCommandInfos produces the full command list: built-ins followed by plugin commands.
+// mergeCommandInfos produces the full command list: built-ins followed by
+// plugin commands and aliases.
 func mergeCommandInfos(r *CommandRegistry, plugin []CommandInfo) []CommandInfo {
 	specs := r.Specs()
 	out := make([]CommandInfo, 0, len(specs)+len(plugin))
@@ -348,7 +394,7 @@ func mergeCommandInfos(r *CommandRegistry, plugin []CommandInfo) []CommandInfo {
 }
 
 // GetCommands returns metadata for every known command (built-ins merged with
-// plugin commands). Bound to the frontend via Wails.
+// plugin commands and user-defined aliases). Bound to the frontend via Wails.
 func (a *App) GetCommands() []CommandInfo {
 	var plugin []CommandInfo
 	if a.pluginManager != nil {
@@ -360,5 +406,5 @@ func (a *App) GetCommands() []CommandInfo {
 			})
 		}
 	}
-	return mergeCommandInfos(a.commands, plugin)
+	return mergeCommandInfos(a.commands, append(plugin, a.aliasCommandInfos()...))
 }
@@ -0,0 +1,30 @@
+//go:build !server
+
+package main
+
+import "github.com/wailsapp/wails/v3/pkg/application"
+
+// headlessBuild is false in the windowed build: it may attach to a remote core.
+const headlessBuild = false
+
+// setupCore decides what the main window loads in the windowed build: the
+// configured remote core's attach URL, or "" for the bundled local UI. The
+// listener side of core mode only exists in the `server` build (core_server.go),
+// so stop is a no-op and there is no backend to guard here.
+func setupCore(app *App) (windowURL string, backendGuard application.Middleware, stop func(), err error) {
+	app.remoteCore = app.remoteCoreAttachURL()
+	return app.remoteCore, nil, func() {}, nil
+}
+
+// addCoreMenuItems offers a way back to the local UI while attached: the
+// attached window talks to the core's bindings, so only the native menu still
+// reaches this process.
+func addCoreMenuItems(menu *application.Menu, app *App) {
+	if app.remoteCore == "" {
+		return
+	}
+	menu.Add("Detach from Core").OnClick(func(*application.Context) {
+		app.detachRemoteCore()
+	})
+	menu.AddSeparator()
+}
@@ -0,0 +1,107 @@
+//go:build server
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/core"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// headlessBuild is true in the `server` build: it is the core, never a client
+// of one.
+const headlessBuild = true
+
+// setupCore turns the headless build into an always-on core when
+// CASCADE_CORE_LISTEN is set (e.g. "0.0.0.0:7667"). The Wails server moves to a
+// private loopback port and an authenticating proxy (internal/core) serves the
+// listen address instead. Without CASCADE_CORE_LISTEN the build behaves as
+// before, which is what the e2e harness relies on. The returned middleware
+// goes on the Wails asset server and refuses requests that bypass the proxy.
+//
+//	CASCADE_CORE_TLS_CERT, CASCADE_CORE_TLS_KEY  serve TLS (required off loopback)
+//	CASCADE_CORE_TOKEN                           access token; default: <data dir>/core.token
+//	CASCADE_CORE_INSECURE=1                      allow plain HTTP off loopback (TLS terminated elsewhere)
+func setupCore(app *App) (windowURL string, backendGuard application.Middleware, stop func(), err error) {
+	noop := func() {}
+	listen := os.Getenv("CASCADE_CORE_LISTEN")
+	if listen == "" {
+		return "", nil, noop, nil
+	}
+	certFile, keyFile := os.Getenv("CASCADE_CORE_TLS_CERT"), os.Getenv("CASCADE_CORE_TLS_KEY")
+	if (certFile == "") != (keyFile == "") {
+		return "", nil, noop, fmt.Errorf("CASCADE_CORE_TLS_CERT and CASCADE_CORE_TLS_KEY must be set together")
+	}
+	useTLS := certFile != ""
+	if !useTLS && !core.IsLoopback(listen) && os.Getenv("CASCADE_CORE_INSECURE") != "1" {
+		return "", nil, noop, fmt.Errorf("refusing to serve the core on %s without TLS; set CASCADE_CORE_TLS_CERT/KEY or CASCADE_CORE_INSECURE=1", listen)
+	}
+
+	token := os.Getenv("CASCADE_CORE_TOKEN")
+	if token == "" {
+		if token, err = core.LoadOrCreateToken(app.dataDir); err != nil {
+			return "", nil, noop, err
+		}
+		logger.Log.Info().Str("file", filepath.Join(app.dataDir, core.TokenFileName)).Msg("Core access token")
+	}
+
+	// Anyone on this machine can reach the loopback port, so the proxy proves
+	// itself to the backend with a secret that lives only in this process.
+	secret, err := core.NewSecret()
+	if err != nil {
+		return "", nil, noop, err
+	}
+
+	// Reserve a loopback port for the Wails server. The env vars override its
+	// options, so setting them is the one way to pin it.
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, noop, fmt.Errorf("reserve backend port: %w", err)
+	}
+	backendPort := probe.Addr().(*net.TCPAddr).Port
+	_ = probe.Close()
+	os.Setenv("WAILS_SERVER_HOST", "127.0.0.1")
+	os.Setenv("WAILS_SERVER_PORT", strconv.Itoa(backendPort))
+	backend := &url.URL{Scheme: "http", Host: net.JoinHostPort("127.0.0.1", strconv.Itoa(backendPort))}
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return "", nil, noop, fmt.Errorf("listen on %s: %w", listen, err)
+	}
+	server := &http.Server{
+		Handler:           core.NewHandler(backend, token, secret, useTLS),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		var err error
+		if useTLS {
+			err = server.ServeTLS(ln, certFile, keyFile)
+		} else {
+			err = server.Serve(ln)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Log.Error().Err(err).Str("listen", listen).Msg("Core listener stopped")
+		}
+	}()
+	logger.Log.Info().Str("listen", listen).Bool("tls", useTLS).Msg("Core listening for GUI attach")
+
+	return "", core.RequireSecret(secret), func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
+
+// addCoreMenuItems is a no-op in the headless build, which has no menu bar.
+func addCoreMenuItems(_ *application.Menu, _ *App) {}
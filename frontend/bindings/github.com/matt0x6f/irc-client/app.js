@@ -77,6 +77,25 @@ export function CancelFileTransfer(id) {
     return $Call.ByID(3945828302, id);
 }
 
+/**
+ * CancelRestore discards a staged restore.
+ * @returns {$CancellablePromise<void>}
+ */
+export function CancelRestore() {
+    return $Call.ByID(2381516499);
+}
+
+/**
+ * CancelScheduledMessage removes a queued message, or a failed one the user
+ * has seen.
+ * @param {number} networkID
+ * @param {number} id
+ * @returns {$CancellablePromise<void>}
+ */
+export function CancelScheduledMessage(networkID, id) {
+    return $Call.ByID(3302253297, networkID, id);
+}
+
 /**
  * CheckForUpdates is the Wails-bound manual update trigger, called from the
  * "Check for Updates…" menu item and the About-pane button. On a dev build the
@@ -135,6 +154,16 @@ export function ClearPaneFocus(networkID, paneType, paneName) {
     return $Call.ByID(1854039756, networkID, paneType, paneName);
 }
 
+/**
+ * ClearPasteQueue stops every paste still being sent on the network and
+ * reports how many lines were not sent.
+ * @param {number} networkID
+ * @returns {$CancellablePromise<number>}
+ */
+export function ClearPasteQueue(networkID) {
+    return $Call.ByID(367932688, networkID);
+}
+
 /**
  * ClearSTSPolicy removes the STS policy for a host. This is a deliberate security
  * downgrade (the next connection to that host may go plaintext again) exposed for
@@ -190,6 +219,20 @@ export function ConnectSavedNetwork(networkID) {
     return $Call.ByID(2804459446, networkID);
 }
 
+/**
+ * CreateBackup writes an encrypted backup of the data directory while the app
+ * keeps running. An empty path writes a new file in the backup directory; an
+ * empty passphrase uses the stored one.
+ * @param {string} path
+ * @param {string} passphrase
+ * @returns {$CancellablePromise<$models.BackupInfo>}
+ */
+export function CreateBackup(path, passphrase) {
+    return $Call.ByID(1454062655, path, passphrase).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType58($result);
+    }));
+}
+
 /**
  * @param {string} id
  * @returns {$CancellablePromise<void>}
@@ -198,6 +241,16 @@ export function DeclineFileTransfer(id) {
     return $Call.ByID(3195456860, id);
 }
 
+/**
+ * DeleteCommandAlias removes an alias; networkID 0 names the global one.
+ * @param {number} networkID
+ * @param {string} name
+ * @returns {$CancellablePromise<void>}
+ */
+export function DeleteCommandAlias(networkID, name) {
+    return $Call.ByID(3830529437, networkID, name);
+}
+
 /**
  * DeleteNetwork deletes a network configuration
  * @param {number} networkID
@@ -301,6 +354,20 @@ export function EnableScript(id) {
     return $Call.ByID(1252903669, id);
 }
 
+/**
+ * ExportConfiguration returns every network (servers, identity, SASL,
+ * channels, MONITOR and ignore lists) and, when includeSettings is set, the
+ * app settings as a bundle in format "json" or "yaml". Passwords and channel
+ * keys are included only when passphrase is set, encrypted with it.
+ * @param {string} format
+ * @param {string} passphrase
+ * @param {boolean} includeSettings
+ * @returns {$CancellablePromise<string>}
+ */
+export function ExportConfiguration(format, passphrase, includeSettings) {
+    return $Call.ByID(4270088483, format, passphrase, includeSettings);
+}
+
 /**
  * FocusMainWindow raises the main window. Bound to the frontend and called from
  * the notification:navigate handler so window focus runs on the framework's
@@ -311,6 +378,47 @@ export function FocusMainWindow() {
     return $Call.ByID(1159429910);
 }
 
+/**
+ * GetAccountMessages retrieves up to `limit` of the latest messages sent while
+ * logged in as a services account, whatever nick the sender used, in
+ * chronological order.
+ * @param {number} networkID
+ * @param {string} account
+ * @param {number} limit
+ * @returns {$CancellablePromise<storage$0.Message[]>}
+ */
+export function GetAccountMessages(networkID, account, limit) {
+    return $Call.ByID(4026237654, networkID, account, limit).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType22($result);
+    }));
+}
+
+/**
+ * GetAccountNicks lists the nicks a services account has sent messages under
+ * on a network, most recently used first.
+ * @param {number} networkID
+ * @param {string} account
+ * @returns {$CancellablePromise<storage$0.AccountNick[]>}
+ */
+export function GetAccountNicks(networkID, account) {
+    return $Call.ByID(645755658, networkID, account).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType77($result);
+    }));
+}
+
+/**
+ * GetAccountRegistrationSupport reports what the network's server allows for
+ * draft/account-registration. It is the zero value (unsupported) until the
+ * network has connected this session.
+ * @param {number} networkID
+ * @returns {$CancellablePromise<irc$0.AccountRegistrationSupport>}
+ */
+export function GetAccountRegistrationSupport(networkID) {
+    return $Call.ByID(2955040846, networkID).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType79($result);
+    }));
+}
+
 /**
  * @returns {$CancellablePromise<dcc$0.View[]>}
  */
@@ -340,6 +448,56 @@ export function GetActivitySettings() {
     }));
 }
 
+/**
+ * GetAvatar returns a user's avatar as a PNG data URL, or "" when it can't be
+ * fetched. The image is downloaded once through the link-preview SSRF guard,
+ * cropped and scaled to avatarSize, and cached under <dataDir>/avatars keyed by
+ * its URL; a changed avatar has a new URL and so a new cache entry.
+ * @param {string} avatarURL
+ * @returns {$CancellablePromise<string>}
+ */
+export function GetAvatar(avatarURL) {
+    return $Call.ByID(1792877782, avatarURL);
+}
+
+/**
+ * @returns {$CancellablePromise<$models.BackupSettings>}
+ */
+export function GetBackupSettings() {
+    return $Call.ByID(4237475704).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType59($result);
+    }));
+}
+
+/**
+ * GetBackupStatus reports the newest backup in the backup directory.
+ * @returns {$CancellablePromise<$models.BackupStatus>}
+ */
+export function GetBackupStatus() {
+    return $Call.ByID(3559949247).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType60($result);
+    }));
+}
+
+/**
+ * @returns {$CancellablePromise<$models.BouncerSettings>}
+ */
+export function GetBouncerSettings() {
+    return $Call.ByID(144706018).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType55($result);
+    }));
+}
+
+/**
+ * GetBouncerStatus reports the listener's state and the attached clients.
+ * @returns {$CancellablePromise<$models.BouncerStatus>}
+ */
+export function GetBouncerStatus() {
+    return $Call.ByID(117109785).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType56($result);
+    }));
+}
+
 /**
  * GetBuildInfo returns the version, commit, and build date stamped into the
  * binary at link time.
@@ -352,8 +510,8 @@ export function GetBuildInfo() {
 }
 
 /**
- * GetCachedChannelList returns the last-fetched LIST result for a network, if any.
- * It never triggers a fetch — callers use RequestChannelList to force a refresh.
+ * GetCachedChannelList returns the network's stored channel directory, if any.
+ * It never triggers a fetch — callers use RequestChannelList to refresh it.
  * @param {number} networkID
  * @returns {$CancellablePromise<$models.ChannelListCacheResult>}
  */
@@ -385,6 +543,39 @@ export function GetChannelInfo(networkID, channelName) {
     }));
 }
 
+/**
+ * GetChannelNickStats returns one nick's activity in a channel between from
+ * and to (Unix seconds, 0 for an open end), or nil if it was not seen there.
+ * @param {number} networkID
+ * @param {string} channel
+ * @param {string} nick
+ * @param {number} from
+ * @param {number} to
+ * @returns {$CancellablePromise<storage$0.NickActivity | null>}
+ */
+export function GetChannelNickStats(networkID, channel, nick, from, to) {
+    return $Call.ByID(3696881064, networkID, channel, nick, from, to).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType71($result);
+    }));
+}
+
+/**
+ * GetChannelStats returns activity statistics for a channel between from and
+ * to (Unix seconds, 0 for an open end), with up to top talkers (0 for every
+ * nick seen). The window is matched to whole hours.
+ * @param {number} networkID
+ * @param {string} channel
+ * @param {number} from
+ * @param {number} to
+ * @param {number} top
+ * @returns {$CancellablePromise<$models.ChannelStats | null>}
+ */
+export function GetChannelStats(networkID, channel, from, to, top) {
+    return $Call.ByID(4033920501, networkID, channel, from, to, top).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType69($result);
+    }));
+}
+
 /**
  * GetChannels retrieves channels for a network
  * @param {number} networkID
@@ -396,6 +587,16 @@ export function GetChannels(networkID) {
     }));
 }
 
+/**
+ * GetCommandAliases returns every alias, global ones first.
+ * @returns {$CancellablePromise<$models.AliasDefinition[]>}
+ */
+export function GetCommandAliases() {
+    return $Call.ByID(2455141532).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType62($result);
+    }));
+}
+
 /**
  * GetCommands returns metadata for every known command (built-ins merged with
  * plugin commands). Bound to the frontend via Wails.
@@ -436,6 +637,20 @@ export function GetDefaultLogPath() {
     return $Call.ByID(3647489945);
 }
 
+/**
+ * GetEncryptionState reports how messages to target are protected on the
+ * network: an end-to-end session, one waiting to be accepted, a FiSH key, or
+ * nothing. Mode is "" while disconnected.
+ * @param {number} networkID
+ * @param {string} target
+ * @returns {$CancellablePromise<irc$0.EncryptionState>}
+ */
+export function GetEncryptionState(networkID, target) {
+    return $Call.ByID(1703320647, networkID, target).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType81($result);
+    }));
+}
+
 /**
  * @returns {$CancellablePromise<dcc$0.Settings>}
  */
@@ -709,6 +924,17 @@ export function GetOpenChannels(networkID) {
     }));
 }
 
+/**
+ * GetOutboundQueue reports the network's outbound queue depth.
+ * @param {number} networkID
+ * @returns {$CancellablePromise<$models.OutboundQueueStatus>}
+ */
+export function GetOutboundQueue(networkID) {
+    return $Call.ByID(122546774, networkID).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType67($result);
+    }));
+}
+
 /**
  * GetPendingNetworkPrefill returns the pending Add Network prefill (if any) and
  * clears it, so it is consumed exactly once. Bound for the settings window.
@@ -720,6 +946,28 @@ export function GetPendingNetworkPrefill() {
     }));
 }
 
+/**
+ * GetPerformSecrets lists the secrets the network's perform list refers to.
+ * @param {number} networkID
+ * @returns {$CancellablePromise<$models.PerformSecret[]>}
+ */
+export function GetPerformSecrets(networkID) {
+    return $Call.ByID(3954627557, networkID).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType64($result);
+    }));
+}
+
+/**
+ * GetPerformSteps returns the network's perform list in order.
+ * @param {number} networkID
+ * @returns {$CancellablePromise<$models.PerformStep[]>}
+ */
+export function GetPerformSteps(networkID) {
+    return $Call.ByID(3765415707, networkID).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType66($result);
+    }));
+}
+
 /**
  * GetPinnedMessages retrieves pinned messages for a network and channel
  * @param {number} networkID
@@ -779,6 +1027,28 @@ export function GetPrivateMessages(networkID, targetUser, limit) {
     }));
 }
 
+/**
+ * GetProfileMetadata returns our own metadata on the network as the server
+ * last confirmed it. Supported is false while disconnected.
+ * @param {number} networkID
+ * @returns {$CancellablePromise<$models.ProfileMetadata>}
+ */
+export function GetProfileMetadata(networkID) {
+    return $Call.ByID(2551027173, networkID).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType80($result);
+    }));
+}
+
+/**
+ * GetRemoteCore returns the configured remote core.
+ * @returns {$CancellablePromise<$models.RemoteCoreSettings>}
+ */
+export function GetRemoteCore() {
+    return $Call.ByID(2274329958).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType54($result);
+    }));
+}
+
 /**
  * GetSTSPolicies returns every stored IRCv3 STS policy (host → enforced TLS port +
  * expiry), for the settings panel to render an "enforced until" badge per server.
@@ -790,6 +1060,31 @@ export function GetSTSPolicies() {
     }));
 }
 
+/**
+ * GetScheduledMessages returns the network's queued messages, soonest first,
+ * including failed ones that have not been removed.
+ * @param {number} networkID
+ * @returns {$CancellablePromise<storage$0.ScheduledMessage[]>}
+ */
+export function GetScheduledMessages(networkID) {
+    return $Call.ByID(2781185098, networkID).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType83($result);
+    }));
+}
+
+/**
+ * GetSeen returns what the network's seen database knows about nick, or nil
+ * if it has never been seen there.
+ * @param {number} networkID
+ * @param {string} nick
+ * @returns {$CancellablePromise<$models.SeenReport | null>}
+ */
+export function GetSeen(networkID, nick) {
+    return $Call.ByID(3963520244, networkID, nick).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType75($result);
+    }));
+}
+
 /**
  * GetServerCapabilities retrieves server capabilities from ISUPPORT for a network
  * @param {number} networkID
@@ -812,6 +1107,17 @@ export function GetServers(networkID) {
     }));
 }
 
+/**
+ * GetServicesLogin returns the network's NickServ login settings.
+ * @param {number} networkID
+ * @returns {$CancellablePromise<$models.ServicesLogin>}
+ */
+export function GetServicesLogin(networkID) {
+    return $Call.ByID(3304166080, networkID).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType78($result);
+    }));
+}
+
 /**
  * GetSetting returns a persisted UI/app preference by key. A missing key
  * returns an empty string (not an error) so the frontend can apply its default.
@@ -844,6 +1150,23 @@ export function IgnoreActivitySender(networkID, nick) {
     return $Call.ByID(993663303, networkID, nick);
 }
 
+/**
+ * ImportConfiguration reads a Cascade bundle or another client's config file
+ * (format "json", "yaml", "irssi", "weechat", "hexchat", or "" to detect it)
+ * and adds its networks. A network whose name already exists is updated in
+ * place; its channels, MONITOR and ignore entries are added to what is there.
+ * passphrase opens a bundle's secrets.
+ * @param {string} content
+ * @param {string} format
+ * @param {string} passphrase
+ * @returns {$CancellablePromise<$models.ConfigImportResult>}
+ */
+export function ImportConfiguration(content, format, passphrase) {
+    return $Call.ByID(1881369222, content, format, passphrase).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType57($result);
+    }));
+}
+
 /**
  * LeaveChannel leaves an IRC channel by sending a PART when joined and
  * connected. It is overloaded: on the not-joined / no-client / lookup-error
@@ -1029,6 +1352,23 @@ export function PrintLocalLines(networkID, target, lines) {
     return $Call.ByID(821357734, networkID, target, lines);
 }
 
+/**
+ * RegisterAccount creates an account on the network with REGISTER. An empty
+ * account registers the current nick. On a live connection it is sent now;
+ * otherwise it is queued and the network connected, so a server that allows
+ * before-connect gets it during the handshake. The outcome arrives as the
+ * "account-registration" event, and a registered account is saved as the
+ * network's SASL PLAIN login.
+ * @param {number} networkID
+ * @param {string} account
+ * @param {string} email
+ * @param {string} password
+ * @returns {$CancellablePromise<void>}
+ */
+export function RegisterAccount(networkID, account, email, password) {
+    return $Call.ByID(1760306727, networkID, account, email, password);
+}
+
 /**
  * ReloadPlugin reloads a plugin
  * @param {string} name
@@ -1097,12 +1437,16 @@ export function RequestChannelBans(networkID, channelName) {
 }
 
 /**
- * RequestChannelList sends the LIST command to request available channels from the server
+ * RequestChannelList asks the server for its channel list. filter uses /list
+ * syntax; whatever part of it the server's ELIST support allows is applied
+ * server-side, so a narrow filter avoids downloading every channel. An empty
+ * filter fetches the full list, which also drops channels that have gone.
  * @param {number} networkID
+ * @param {string} filter
  * @returns {$CancellablePromise<void>}
  */
-export function RequestChannelList(networkID) {
-    return $Call.ByID(2678000793, networkID);
+export function RequestChannelList(networkID, filter) {
+    return $Call.ByID(2678000793, networkID, filter);
 }
 
 /**
@@ -1133,6 +1477,17 @@ export function RequestChatHistoryLatest(networkID, target, limit) {
     return $Call.ByID(1762900095, networkID, target, limit);
 }
 
+/**
+ * RequestNewChannels refreshes the directory with the channels created since
+ * the last full fetch. Servers whose ELIST lacks creation-time filtering, and
+ * networks never fully fetched, get a full fetch instead.
+ * @param {number} networkID
+ * @returns {$CancellablePromise<void>}
+ */
+export function RequestNewChannels(networkID) {
+    return $Call.ByID(2316555452, networkID);
+}
+
 /**
  * RequestNotificationPermission asks the OS for notification permission. Bound to
  * the frontend; called when the user enables notifications in settings.
@@ -1142,6 +1497,20 @@ export function RequestNotificationPermission() {
     return $Call.ByID(3109134518);
 }
 
+/**
+ * RestoreBackup checks and stages the backup at path. It replaces the current
+ * data the next time Cascade starts; the replaced files are kept in the
+ * backups directory.
+ * @param {string} path
+ * @param {string} passphrase
+ * @returns {$CancellablePromise<$models.BackupInfo>}
+ */
+export function RestoreBackup(path, passphrase) {
+    return $Call.ByID(1800244779, path, passphrase).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType58($result);
+    }));
+}
+
 /**
  * @param {string} id
  * @returns {$CancellablePromise<void>}
@@ -1158,6 +1527,16 @@ export function RevealFileTransfer(id) {
     return $Call.ByID(953180885, id);
 }
 
+/**
+ * SaveCommandAlias creates or replaces an alias. Built-in command names are
+ * refused; a network alias overrides a global alias of the same name.
+ * @param {$models.AliasDefinition} alias
+ * @returns {$CancellablePromise<void>}
+ */
+export function SaveCommandAlias(alias) {
+    return $Call.ByID(3774956875, alias);
+}
+
 /**
  * SaveNetwork saves network configuration without connecting
  * @param {$models.NetworkConfig} config
@@ -1167,6 +1546,40 @@ export function SaveNetwork(config) {
     return $Call.ByID(2045971702, config);
 }
 
+/**
+ * ScheduleMessage queues message for target (a channel or nick) on the
+ * network, to be sent through the normal send path at sendAt, in Unix
+ * milliseconds. The queue is stored, so it survives a restart.
+ * @param {number} networkID
+ * @param {string} target
+ * @param {string} message
+ * @param {number} sendAt
+ * @returns {$CancellablePromise<storage$0.ScheduledMessage>}
+ */
+export function ScheduleMessage(networkID, target, message, sendAt) {
+    return $Call.ByID(243378867, networkID, target, message, sendAt).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType82($result);
+    }));
+}
+
+/**
+ * SearchChannelDirectory searches the network's stored channel directory
+ * without asking the server. filter uses /list syntax (see
+ * irc.ParseChannelListFilter); channel and topic age conditions are ignored,
+ * since LIST replies carry no times. newOnly keeps channels the latest fetch
+ * was the first to list. A limit of 0 returns every match.
+ * @param {number} networkID
+ * @param {string} filter
+ * @param {boolean} newOnly
+ * @param {number} limit
+ * @returns {$CancellablePromise<storage$0.ChannelDirectoryEntry[]>}
+ */
+export function SearchChannelDirectory(networkID, filter, newOnly, limit) {
+    return $Call.ByID(289734155, networkID, filter, newOnly, limit).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType73($result);
+    }));
+}
+
 /**
  * SearchMessages performs full-text search across stored messages
  * @param {string} query
@@ -1191,6 +1604,18 @@ export function SendCommand(networkID, command) {
     return $Call.ByID(2526227764, networkID, command);
 }
 
+/**
+ * SendCommandInBuffer sends a command typed into a specific buffer ("status",
+ * a channel, or a PM nick) so replies it triggers can be routed back there
+ * @param {number} networkID
+ * @param {string} buffer
+ * @param {string} command
+ * @returns {$CancellablePromise<void>}
+ */
+export function SendCommandInBuffer(networkID, buffer, command) {
+    return $Call.ByID(842829889, networkID, buffer, command);
+}
+
 /**
  * SendMessage sends a message to a channel or user
  * @param {number} networkID
@@ -1250,6 +1675,33 @@ export function SetChannelOpen(networkID, channelName, isOpen) {
     return $Call.ByID(1311700154, networkID, channelName, isOpen);
 }
 
+/**
+ * SetChannelSmartFilter sets the channel's smart filter window: joins, parts
+ * and quits from nicks that haven't spoken there in the last `minutes` minutes
+ * are stored filtered and hidden from the channel's history. 0 turns the
+ * filter off and shows the lines it had hidden again.
+ * @param {number} networkID
+ * @param {string} channelName
+ * @param {number} minutes
+ * @returns {$CancellablePromise<void>}
+ */
+export function SetChannelSmartFilter(networkID, channelName, minutes) {
+    return $Call.ByID(1297586209, networkID, channelName, minutes);
+}
+
+/**
+ * SetFishKey sets the FiSH key for a channel or nick, in the "cbc:<key>" form
+ * other FiSH clients use; an empty key removes it. Keys are kept in the
+ * credential store.
+ * @param {number} networkID
+ * @param {string} target
+ * @param {string} key
+ * @returns {$CancellablePromise<void>}
+ */
+export function SetFishKey(networkID, target, key) {
+    return $Call.ByID(3510944832, networkID, target, key);
+}
+
 /**
  * SetLogConfig validates, applies, and persists a new file-logging
  * configuration. The new config is applied to the logger first so a bad path is
@@ -1264,6 +1716,19 @@ export function SetLogConfig(enabled, path, level) {
     return $Call.ByID(2238388953, enabled, path, level);
 }
 
+/**
+ * SetMetadata sets one of our own metadata keys on the network ("avatar",
+ * "display-name", "pronouns", "homepage" or "status"); an empty value removes
+ * it. The change shows up in the roster once the server confirms it.
+ * @param {number} networkID
+ * @param {string} key
+ * @param {string} value
+ * @returns {$CancellablePromise<void>}
+ */
+export function SetMetadata(networkID, key, value) {
+    return $Call.ByID(2556972614, networkID, key, value);
+}
+
 /**
  * SetNetworkColor sets the rail tile color palette key. Empty string clears it
  * (NULL), reverting to the deterministic name-hashed fallback.
@@ -1297,6 +1762,29 @@ export function SetPaneFocus(networkID, paneType, paneName) {
     return $Call.ByID(3606120123, networkID, paneType, paneName);
 }
 
+/**
+ * SetPerformSecret stores a secret for ${secret:name} references in the
+ * network's perform list. An empty value deletes it.
+ * @param {number} networkID
+ * @param {string} name
+ * @param {string} value
+ * @returns {$CancellablePromise<void>}
+ */
+export function SetPerformSecret(networkID, name, value) {
+    return $Call.ByID(197302176, networkID, name, value);
+}
+
+/**
+ * SetPerformSteps replaces the network's perform list. It runs on the next
+ * connection, after registration and before channels are joined.
+ * @param {number} networkID
+ * @param {$models.PerformStep[]} steps
+ * @returns {$CancellablePromise<void>}
+ */
+export function SetPerformSteps(networkID, steps) {
+    return $Call.ByID(2606343175, networkID, steps);
+}
+
 /**
  * SetPluginConfig saves the configuration for a plugin
  * @param {string} pluginName
@@ -1318,6 +1806,32 @@ export function SetPrivateMessageOpen(networkID, targetUser, isOpen) {
     return $Call.ByID(1106422473, networkID, targetUser, isOpen);
 }
 
+/**
+ * SetRemoteCore points the desktop app at an always-on core, used from the
+ * next launch. An empty token keeps the stored one; an empty URL goes back to
+ * running locally and forgets the token.
+ * @param {string} coreURL
+ * @param {string} token
+ * @returns {$CancellablePromise<void>}
+ */
+export function SetRemoteCore(coreURL, token) {
+    return $Call.ByID(4111724154, coreURL, token);
+}
+
+/**
+ * SetServicesLogin stores the NickServ account and password in the credential
+ * store. On each connection where SASL did not log in, Cascade identifies with
+ * them before joining channels, recovers the nick when it's held and asks
+ * ChanServ for access to +i and +k channels. An empty password turns this off.
+ * @param {number} networkID
+ * @param {string} account
+ * @param {string} password
+ * @returns {$CancellablePromise<void>}
+ */
+export function SetServicesLogin(networkID, account, password) {
+    return $Call.ByID(1071400028, networkID, account, password);
+}
+
 /**
  * SetSetting persists a UI/app preference by key. After a successful write it
  * broadcasts a setting:changed event so every open window (e.g. the main window
@@ -1346,6 +1860,28 @@ export function SkipUpdateVersion(version) {
     return $Call.ByID(3143090493, version);
 }
 
+/**
+ * StartEncryption offers nick an end-to-end encrypted conversation. Messages
+ * are encrypted once their client accepts; the "encryption-changed" event
+ * tells the frontend when that happens.
+ * @param {number} networkID
+ * @param {string} nick
+ * @returns {$CancellablePromise<void>}
+ */
+export function StartEncryption(networkID, nick) {
+    return $Call.ByID(2818466778, networkID, nick);
+}
+
+/**
+ * StopEncryption ends the end-to-end session with nick.
+ * @param {number} networkID
+ * @param {string} nick
+ * @returns {$CancellablePromise<void>}
+ */
+export function StopEncryption(networkID, nick) {
+    return $Call.ByID(3013500516, networkID, nick);
+}
+
 /**
  * ToggleChannelAutoJoin toggles the auto-join setting for a channel
  * @param {number} networkID
@@ -1400,6 +1936,28 @@ export function UnpinMessage(messageID) {
     return $Call.ByID(2340933632, messageID);
 }
 
+/**
+ * UpdateBackupSettings stores the schedule. An empty passphrase keeps the
+ * stored one; scheduled backups need one.
+ * @param {$models.BackupSettings} settings
+ * @param {string} passphrase
+ * @returns {$CancellablePromise<void>}
+ */
+export function UpdateBackupSettings(settings, passphrase) {
+    return $Call.ByID(3719885223, settings, passphrase);
+}
+
+/**
+ * UpdateBouncerSettings stores the listener configuration and restarts it. An
+ * empty password keeps the stored one.
+ * @param {$models.BouncerSettings} settings
+ * @param {string} password
+ * @returns {$CancellablePromise<void>}
+ */
+export function UpdateBouncerSettings(settings, password) {
+    return $Call.ByID(3766445943, settings, password);
+}
+
 /**
  * @param {dcc$0.Settings} settings
  * @param {boolean} cancelActive
@@ -1409,6 +1967,29 @@ export function UpdateFileTransferSettings(settings, cancelActive) {
     return $Call.ByID(3842008076, settings, cancelActive);
 }
 
+/**
+ * VerifyAccount sends the verification code the server asked for after
+ * RegisterAccount.
+ * @param {number} networkID
+ * @param {string} code
+ * @returns {$CancellablePromise<void>}
+ */
+export function VerifyAccount(networkID, code) {
+    return $Call.ByID(203343651, networkID, code);
+}
+
+/**
+ * VerifyEncryption records the fingerprint of nick's current session as the
+ * one the user checked with them, so a later session under a different key is
+ * flagged. The fingerprints are kept in the credential store.
+ * @param {number} networkID
+ * @param {string} nick
+ * @returns {$CancellablePromise<void>}
+ */
+export function VerifyEncryption(networkID, nick) {
+    return $Call.ByID(2793343661, networkID, nick);
+}
+
 // Private type creation functions
 const $$createType0 = dcc$0.View.createFrom;
 const $$createType1 = $Create.Array($$createType0);
@@ -1464,3 +2045,33 @@ const $$createType50 = storage$0.SearchResult.createFrom;
 const $$createType51 = $Create.Array($$createType50);
 const $$createType52 = unfurl$0.LinkPreview.createFrom;
 const $$createType53 = $Create.Nullable($$createType52);
+const $$createType54 = $models.RemoteCoreSettings.createFrom;
+const $$createType55 = $models.BouncerSettings.createFrom;
+const $$createType56 = $models.BouncerStatus.createFrom;
+const $$createType57 = $models.ConfigImportResult.createFrom;
+const $$createType58 = $models.BackupInfo.createFrom;
+const $$createType59 = $models.BackupSettings.createFrom;
+const $$createType60 = $models.BackupStatus.createFrom;
+const $$createType61 = $models.AliasDefinition.createFrom;
+const $$createType62 = $Create.Array($$createType61);
+const $$createType63 = $models.PerformSecret.createFrom;
+const $$createType64 = $Create.Array($$createType63);
+const $$createType65 = $models.PerformStep.createFrom;
+const $$createType66 = $Create.Array($$createType65);
+const $$createType67 = $models.OutboundQueueStatus.createFrom;
+const $$createType68 = $models.ChannelStats.createFrom;
+const $$createType69 = $Create.Nullable($$createType68);
+const $$createType70 = storage$0.NickActivity.createFrom;
+const $$createType71 = $Create.Nullable($$createType70);
+const $$createType72 = storage$0.ChannelDirectoryEntry.createFrom;
+const $$createType73 = $Create.Array($$createType72);
+const $$createType74 = $models.SeenReport.createFrom;
+const $$createType75 = $Create.Nullable($$createType74);
+const $$createType76 = storage$0.AccountNick.createFrom;
+const $$createType77 = $Create.Array($$createType76);
+const $$createType78 = $models.ServicesLogin.createFrom;
+const $$createType79 = irc$0.AccountRegistrationSupport.createFrom;
+const $$createType80 = $models.ProfileMetadata.createFrom;
+const $$createType81 = irc$0.EncryptionState.createFrom;
+const $$createType82 = storage$0.ScheduledMessage.createFrom;
+const $$createType83 = $Create.Array($$createType82);
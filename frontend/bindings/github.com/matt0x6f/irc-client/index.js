@@ -9,10 +9,20 @@ export {
 
 export {
     ActivitySettings,
+    AliasDefinition,
+    BackupInfo,
+    BackupSettings,
+    BackupStatus,
+    BouncerClient,
+    BouncerSettings,
+    BouncerStatus,
     BuildInfo,
     ChannelInfo,
     ChannelListCacheResult,
+    ChannelStats,
+    ChannelStatsDay,
     CommandInfo,
+    ConfigImportResult,
     FileTransferPage,
     InviteView,
     LastOpenPane,
@@ -20,9 +30,16 @@ export {
     MonitorEntry,
     NetworkConfig,
     NetworkPrefill,
+    OutboundQueueStatus,
     PendingDeepLink,
+    PerformSecret,
+    PerformStep,
     PluginInfo,
+    ProfileMetadata,
+    RemoteCoreSettings,
     ScriptInfo,
+    SeenReport,
     ServerCapabilitiesInfo,
-    ServerConfig
+    ServerConfig,
+    ServicesLogin
 } from "./models.js";
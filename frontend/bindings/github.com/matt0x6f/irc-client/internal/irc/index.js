@@ -3,5 +3,7 @@
 // This file is automatically generated. DO NOT EDIT
 
 export {
+    AccountRegistrationSupport,
+    EncryptionState,
     UserMeta
 } from "./models.js";
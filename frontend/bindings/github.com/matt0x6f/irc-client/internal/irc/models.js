@@ -6,10 +6,129 @@
 // @ts-ignore: Unused imports
 import { Create as $Create } from "@wailsio/runtime";
 
+/**
+ * AccountRegistrationSupport is what the server's draft/account-registration
+ * advertisement allows.
+ */
+export class AccountRegistrationSupport {
+    /**
+     * Creates a new AccountRegistrationSupport instance.
+     * @param {Partial<AccountRegistrationSupport>} [$$source = {}] - The source object to create the AccountRegistrationSupport.
+     */
+    constructor($$source = {}) {
+        if (!("supported" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["supported"] = false;
+        }
+        if (!("beforeConnect" in $$source)) {
+            /**
+             * REGISTER is accepted before the connection completes
+             * @member
+             * @type {boolean}
+             */
+            this["beforeConnect"] = false;
+        }
+        if (!("emailRequired" in $$source)) {
+            /**
+             * an email address must be given
+             * @member
+             * @type {boolean}
+             */
+            this["emailRequired"] = false;
+        }
+        if (!("customAccountName" in $$source)) {
+            /**
+             * the account may differ from the current nick
+             * @member
+             * @type {boolean}
+             */
+            this["customAccountName"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new AccountRegistrationSupport instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {AccountRegistrationSupport}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new AccountRegistrationSupport(/** @type {Partial<AccountRegistrationSupport>} */($$parsedSource));
+    }
+}
+
+/**
+ * EncryptionState describes how messages to a target are protected.
+ */
+export class EncryptionState {
+    /**
+     * Creates a new EncryptionState instance.
+     * @param {Partial<EncryptionState>} [$$source = {}] - The source object to create the EncryptionState.
+     */
+    constructor($$source = {}) {
+        if (!("mode" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["mode"] = "";
+        }
+        if (!("fingerprint" in $$source)) {
+            /**
+             * our identity fingerprint on this network ("" without one)
+             * @member
+             * @type {string}
+             */
+            this["fingerprint"] = "";
+        }
+        if (!("peerFingerprint" in $$source)) {
+            /**
+             * the peer's, while an E2E session is up or broken
+             * @member
+             * @type {string}
+             */
+            this["peerFingerprint"] = "";
+        }
+        if (!("verified" in $$source)) {
+            /**
+             * PeerFingerprint is the one the user verified for this nick
+             * @member
+             * @type {boolean}
+             */
+            this["verified"] = false;
+        }
+        if (!("offerFingerprint" in $$source)) {
+            /**
+             * the peer's, while their offer of a new session waits for the user
+             * @member
+             * @type {string}
+             */
+            this["offerFingerprint"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new EncryptionState instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {EncryptionState}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new EncryptionState(/** @type {Partial<EncryptionState>} */($$parsedSource));
+    }
+}
+
 /**
  * UserMeta holds the live, session-local roster attributes Cascade tracks for a
  * nick via the IRCv3 caps away-notify, account-notify, extended-join, chghost,
- * and account-tag. It is deliberately not persisted: a nick's away/account/host
+ * account-tag and draft/metadata-2. It is deliberately not persisted: a nick's away/account/host
  * is only meaningful for the current session and is rebuilt on reconnect.
  */
 export class UserMeta {
@@ -58,6 +177,46 @@ export class UserMeta {
              */
             this["realname"] = "";
         }
+        if (!("avatar" in $$source)) {
+            /**
+             * draft/metadata-2 "avatar" URL; "" when unset
+             * @member
+             * @type {string}
+             */
+            this["avatar"] = "";
+        }
+        if (!("display_name" in $$source)) {
+            /**
+             * draft/metadata-2 "display-name"
+             * @member
+             * @type {string}
+             */
+            this["display_name"] = "";
+        }
+        if (!("pronouns" in $$source)) {
+            /**
+             * draft/metadata-2 "pronouns"
+             * @member
+             * @type {string}
+             */
+            this["pronouns"] = "";
+        }
+        if (!("homepage" in $$source)) {
+            /**
+             * draft/metadata-2 "homepage"
+             * @member
+             * @type {string}
+             */
+            this["homepage"] = "";
+        }
+        if (!("status" in $$source)) {
+            /**
+             * draft/metadata-2 "status", a short free-text status
+             * @member
+             * @type {string}
+             */
+            this["status"] = "";
+        }
 
         Object.assign(this, $$source);
     }
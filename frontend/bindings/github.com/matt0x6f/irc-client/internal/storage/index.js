@@ -3,14 +3,18 @@
 // This file is automatically generated. DO NOT EDIT
 
 export {
+    AccountNick,
     ActivityItem,
     Channel,
+    ChannelDirectoryEntry,
     ChannelUser,
     IgnoredSenderRow,
     Message,
     Network,
     PinnedMessage,
     STSPolicy,
+    ScheduledMessage,
     SearchResult,
+    SeenEntry,
     Server
 } from "./models.js";
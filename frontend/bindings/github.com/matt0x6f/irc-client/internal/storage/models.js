@@ -10,6 +10,60 @@ import { Create as $Create } from "@wailsio/runtime";
 // @ts-ignore: Unused imports
 import * as time$0 from "../../../../../time/models.js";
 
+/**
+ * AccountNick is one nick a services account has sent messages under, with
+ * how many stored lines carry it and when the first and last were sent (Unix
+ * seconds).
+ */
+export class AccountNick {
+    /**
+     * Creates a new AccountNick instance.
+     * @param {Partial<AccountNick>} [$$source = {}] - The source object to create the AccountNick.
+     */
+    constructor($$source = {}) {
+        if (!("nick" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["nick"] = "";
+        }
+        if (!("messages" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["messages"] = 0;
+        }
+        if (!("first_seen" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["first_seen"] = 0;
+        }
+        if (!("last_seen" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["last_seen"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new AccountNick instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {AccountNick}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new AccountNick(/** @type {Partial<AccountNick>} */($$parsedSource));
+    }
+}
+
 /**
  * ActivityItem is one attention-inbox row (highlight, keyword, invite, or PM).
  */
@@ -199,6 +253,14 @@ export class Channel {
              */
             this["updated_at"] = null;
         }
+        if (!("smart_filter_minutes" in $$source)) {
+            /**
+             * Hide join/part/quit from nicks silent this long; 0 = off
+             * @member
+             * @type {number}
+             */
+            this["smart_filter_minutes"] = 0;
+        }
 
         Object.assign(this, $$source);
     }
@@ -214,6 +276,88 @@ export class Channel {
     }
 }
 
+/**
+ * ChannelDirectoryEntry is a channel from a network's LIST replies. Times are
+ * Unix milliseconds of the fetches that first and last listed it; New is set
+ * when the latest fetch was the first to list it.
+ */
+export class ChannelDirectoryEntry {
+    /**
+     * Creates a new ChannelDirectoryEntry instance.
+     * @param {Partial<ChannelDirectoryEntry>} [$$source = {}] - The source object to create the ChannelDirectoryEntry.
+     */
+    constructor($$source = {}) {
+        if (!("network_id" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["network_id"] = 0;
+        }
+        if (!("channel" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["channel"] = "";
+        }
+        if (!("users" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["users"] = 0;
+        }
+        if (!("topic" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["topic"] = "";
+        }
+        if (!("modes" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["modes"] = "";
+        }
+        if (!("first_seen" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["first_seen"] = 0;
+        }
+        if (!("last_seen" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["last_seen"] = 0;
+        }
+        if (!("new" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["new"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ChannelDirectoryEntry instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ChannelDirectoryEntry}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ChannelDirectoryEntry(/** @type {Partial<ChannelDirectoryEntry>} */($$parsedSource));
+    }
+}
+
 /**
  * ChannelUser represents a user in a channel
  */
@@ -427,6 +571,30 @@ export class Message {
              */
             this["channel_context"] = "";
         }
+        if (!("account" in $$source)) {
+            /**
+             * Services account the sender was logged in as ("" if unknown)
+             * @member
+             * @type {string}
+             */
+            this["account"] = "";
+        }
+        if (!("filtered" in $$source)) {
+            /**
+             * Join/part/quit hidden by the channel's smart filter; channel GetMessages* skip it
+             * @member
+             * @type {boolean}
+             */
+            this["filtered"] = false;
+        }
+        if (!("encrypted" in $$source)) {
+            /**
+             * Sent or received encrypted (E2E session or FiSH key); Message holds the decrypted text
+             * @member
+             * @type {boolean}
+             */
+            this["encrypted"] = false;
+        }
 
         Object.assign(this, $$source);
     }
@@ -552,6 +720,14 @@ export class Network {
              */
             this["identify_as_bot"] = false;
         }
+        if (!("flood_profile" in $$source)) {
+            /**
+             * outbound pacing profile name; empty means the standard one
+             * @member
+             * @type {string}
+             */
+            this["flood_profile"] = "";
+        }
         if (!("created_at" in $$source)) {
             /**
              * @member
@@ -631,6 +807,88 @@ export class Network {
     }
 }
 
+/**
+ * NickActivity is one nick's channel activity summed over a stats window.
+ * FirstSeen and LastSeen are accurate to the message; the window itself is
+ * matched to whole hours.
+ */
+export class NickActivity {
+    /**
+     * Creates a new NickActivity instance.
+     * @param {Partial<NickActivity>} [$$source = {}] - The source object to create the NickActivity.
+     */
+    constructor($$source = {}) {
+        if (!("nick" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["nick"] = "";
+        }
+        if (!("lines" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["lines"] = 0;
+        }
+        if (!("words" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["words"] = 0;
+        }
+        if (!("urls" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["urls"] = 0;
+        }
+        if (!("joins" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["joins"] = 0;
+        }
+        if (!("parts" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["parts"] = 0;
+        }
+        if (!("first_seen" in $$source)) {
+            /**
+             * @member
+             * @type {time$0.Time}
+             */
+            this["first_seen"] = null;
+        }
+        if (!("last_seen" in $$source)) {
+            /**
+             * @member
+             * @type {time$0.Time}
+             */
+            this["last_seen"] = null;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new NickActivity instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {NickActivity}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new NickActivity(/** @type {Partial<NickActivity>} */($$parsedSource));
+    }
+}
+
 /**
  * PinnedMessage represents a message that has been pinned, with pin metadata
  */
@@ -731,6 +989,30 @@ export class PinnedMessage {
              */
             this["channel_context"] = "";
         }
+        if (!("account" in $$source)) {
+            /**
+             * Services account the sender was logged in as ("" if unknown)
+             * @member
+             * @type {string}
+             */
+            this["account"] = "";
+        }
+        if (!("filtered" in $$source)) {
+            /**
+             * Join/part/quit hidden by the channel's smart filter; channel GetMessages* skip it
+             * @member
+             * @type {boolean}
+             */
+            this["filtered"] = false;
+        }
+        if (!("encrypted" in $$source)) {
+            /**
+             * Sent or received encrypted (E2E session or FiSH key); Message holds the decrypted text
+             * @member
+             * @type {boolean}
+             */
+            this["encrypted"] = false;
+        }
         if (!("pinned_by" in $$source)) {
             /**
              * @member
@@ -807,6 +1089,96 @@ export class STSPolicy {
     }
 }
 
+/**
+ * ScheduledMessage is a message queued to be sent later. SendAt and CreatedAt
+ * are Unix milliseconds. Status is ScheduledPending until it goes out, when
+ * the row is deleted, or ScheduledFailed once it can't be; LastError says why
+ * the latest attempt did not go through.
+ */
+export class ScheduledMessage {
+    /**
+     * Creates a new ScheduledMessage instance.
+     * @param {Partial<ScheduledMessage>} [$$source = {}] - The source object to create the ScheduledMessage.
+     */
+    constructor($$source = {}) {
+        if (!("id" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["id"] = 0;
+        }
+        if (!("network_id" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["network_id"] = 0;
+        }
+        if (!("target" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["target"] = "";
+        }
+        if (!("message" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["message"] = "";
+        }
+        if (!("send_at" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["send_at"] = 0;
+        }
+        if (!("status" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["status"] = "";
+        }
+        if (!("attempts" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["attempts"] = 0;
+        }
+        if (!("last_error" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["last_error"] = "";
+        }
+        if (!("created_at" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["created_at"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ScheduledMessage instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ScheduledMessage}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ScheduledMessage(/** @type {Partial<ScheduledMessage>} */($$parsedSource));
+    }
+}
+
 /**
  * SearchResult extends Message with additional context for search results
  */
@@ -907,6 +1279,14 @@ export class SearchResult {
              */
             this["channel_context"] = "";
         }
+        if (!("account" in $$source)) {
+            /**
+             * Services account the sender was logged in as ("" if unknown)
+             * @member
+             * @type {string}
+             */
+            this["account"] = "";
+        }
         if (!("channel_name" in $$source)) {
             /**
              * @member
@@ -936,6 +1316,96 @@ export class SearchResult {
     }
 }
 
+/**
+ * SeenEntry is the last thing a nick was seen doing on a network. Target is
+ * the other nick of a nick change or kick, or the account of an account
+ * change; Message is a part, quit or kick reason, or the text of a message.
+ * SeenAt is Unix milliseconds.
+ */
+export class SeenEntry {
+    /**
+     * Creates a new SeenEntry instance.
+     * @param {Partial<SeenEntry>} [$$source = {}] - The source object to create the SeenEntry.
+     */
+    constructor($$source = {}) {
+        if (!("network_id" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["network_id"] = 0;
+        }
+        if (!("nick" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["nick"] = "";
+        }
+        if (!("action" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["action"] = "";
+        }
+        if (!("channel" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["channel"] = "";
+        }
+        if (!("target" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["target"] = "";
+        }
+        if (!("message" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["message"] = "";
+        }
+        if (!("host" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["host"] = "";
+        }
+        if (!("account" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["account"] = "";
+        }
+        if (!("seen_at" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["seen_at"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new SeenEntry instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {SeenEntry}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new SeenEntry(/** @type {Partial<SeenEntry>} */($$parsedSource));
+    }
+}
+
 /**
  * Server represents a single server address within a network
  */
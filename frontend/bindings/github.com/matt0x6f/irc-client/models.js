@@ -90,6 +90,426 @@ export class ActivitySettings {
     }
 }
 
+/**
+ * AliasDefinition is a user-defined command as the settings UI edits it.
+ */
+export class AliasDefinition {
+    /**
+     * Creates a new AliasDefinition instance.
+     * @param {Partial<AliasDefinition>} [$$source = {}] - The source object to create the AliasDefinition.
+     */
+    constructor($$source = {}) {
+        if (!("networkId" in $$source)) {
+            /**
+             * 0 for an alias available on every network
+             * @member
+             * @type {number}
+             */
+            this["networkId"] = 0;
+        }
+        if (!("name" in $$source)) {
+            /**
+             * without the slash
+             * @member
+             * @type {string}
+             */
+            this["name"] = "";
+        }
+        if (!("expansion" in $$source)) {
+            /**
+             * commands separated by ';', e.g. "/me slaps $1 with a trout"
+             * @member
+             * @type {string}
+             */
+            this["expansion"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new AliasDefinition instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {AliasDefinition}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new AliasDefinition(/** @type {Partial<AliasDefinition>} */($$parsedSource));
+    }
+}
+
+/**
+ * BackupInfo describes a backup that was written or staged for restore.
+ * createdAt is an RFC 3339 string rather than time.Time, which the binding
+ * generator does not model.
+ */
+export class BackupInfo {
+    /**
+     * Creates a new BackupInfo instance.
+     * @param {Partial<BackupInfo>} [$$source = {}] - The source object to create the BackupInfo.
+     */
+    constructor($$source = {}) {
+        if (!("path" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["path"] = "";
+        }
+        if (!("createdAt" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["createdAt"] = "";
+        }
+        if (!("appVersion" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["appVersion"] = "";
+        }
+        if (!("schemaVersion" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["schemaVersion"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new BackupInfo instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {BackupInfo}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new BackupInfo(/** @type {Partial<BackupInfo>} */($$parsedSource));
+    }
+}
+
+/**
+ * BackupSettings configures scheduled backups.
+ */
+export class BackupSettings {
+    /**
+     * Creates a new BackupSettings instance.
+     * @param {Partial<BackupSettings>} [$$source = {}] - The source object to create the BackupSettings.
+     */
+    constructor($$source = {}) {
+        if (!("enabled" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["enabled"] = false;
+        }
+        if (!("directory" in $$source)) {
+            /**
+             * where backups are written; <data dir>/backups when empty
+             * @member
+             * @type {string}
+             */
+            this["directory"] = "";
+        }
+        if (!("intervalHours" in $$source)) {
+            /**
+             * time between scheduled backups
+             * @member
+             * @type {number}
+             */
+            this["intervalHours"] = 0;
+        }
+        if (!("keep" in $$source)) {
+            /**
+             * scheduled backups kept; older ones are deleted
+             * @member
+             * @type {number}
+             */
+            this["keep"] = 0;
+        }
+        if (!("hasPassphrase" in $$source)) {
+            /**
+             * a passphrase is stored (it is never returned)
+             * @member
+             * @type {boolean}
+             */
+            this["hasPassphrase"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new BackupSettings instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {BackupSettings}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new BackupSettings(/** @type {Partial<BackupSettings>} */($$parsedSource));
+    }
+}
+
+/**
+ * BackupStatus reports the newest backup and whether a restore is waiting for
+ * a restart.
+ */
+export class BackupStatus {
+    /**
+     * Creates a new BackupStatus instance.
+     * @param {Partial<BackupStatus>} [$$source = {}] - The source object to create the BackupStatus.
+     */
+    constructor($$source = {}) {
+        if (!("directory" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["directory"] = "";
+        }
+        if (!("lastBackup" in $$source)) {
+            /**
+             * RFC 3339; "" when there is none
+             * @member
+             * @type {string}
+             */
+            this["lastBackup"] = "";
+        }
+        if (!("lastError" in $$source)) {
+            /**
+             * why the last scheduled backup failed, if it did
+             * @member
+             * @type {string}
+             */
+            this["lastError"] = "";
+        }
+        if (!("restorePending" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["restorePending"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new BackupStatus instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {BackupStatus}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new BackupStatus(/** @type {Partial<BackupStatus>} */($$parsedSource));
+    }
+}
+
+/**
+ * BouncerClient is an attached client. since is an RFC 3339 string rather
+ * than time.Time, which the binding generator does not model.
+ */
+export class BouncerClient {
+    /**
+     * Creates a new BouncerClient instance.
+     * @param {Partial<BouncerClient>} [$$source = {}] - The source object to create the BouncerClient.
+     */
+    constructor($$source = {}) {
+        if (!("networkId" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["networkId"] = 0;
+        }
+        if (!("network" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["network"] = "";
+        }
+        if (!("name" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["name"] = "";
+        }
+        if (!("address" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["address"] = "";
+        }
+        if (!("since" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["since"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new BouncerClient instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {BouncerClient}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new BouncerClient(/** @type {Partial<BouncerClient>} */($$parsedSource));
+    }
+}
+
+/**
+ * BouncerSettings configures the listener other IRC clients attach to.
+ */
+export class BouncerSettings {
+    /**
+     * Creates a new BouncerSettings instance.
+     * @param {Partial<BouncerSettings>} [$$source = {}] - The source object to create the BouncerSettings.
+     */
+    constructor($$source = {}) {
+        if (!("enabled" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["enabled"] = false;
+        }
+        if (!("listen" in $$source)) {
+            /**
+             * host:port; bouncer.DefaultListen when empty
+             * @member
+             * @type {string}
+             */
+            this["listen"] = "";
+        }
+        if (!("tlsCert" in $$source)) {
+            /**
+             * PEM certificate file; plaintext when empty
+             * @member
+             * @type {string}
+             */
+            this["tlsCert"] = "";
+        }
+        if (!("tlsKey" in $$source)) {
+            /**
+             * PEM private key file
+             * @member
+             * @type {string}
+             */
+            this["tlsKey"] = "";
+        }
+        if (!("clientFingerprints" in $$source)) {
+            /**
+             * SHA-256 fingerprints of trusted client certificates
+             * @member
+             * @type {string[]}
+             */
+            this["clientFingerprints"] = [];
+        }
+        if (!("replayLimit" in $$source)) {
+            /**
+             * messages replayed per buffer; 0 for the default
+             * @member
+             * @type {number}
+             */
+            this["replayLimit"] = 0;
+        }
+        if (!("hasPassword" in $$source)) {
+            /**
+             * a password is stored (it is never returned)
+             * @member
+             * @type {boolean}
+             */
+            this["hasPassword"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new BouncerSettings instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {BouncerSettings}
+     */
+    static createFrom($$source = {}) {
+        const $$createField4_0 = $$createType0;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("clientFingerprints" in $$parsedSource) {
+            $$parsedSource["clientFingerprints"] = $$createField4_0($$parsedSource["clientFingerprints"]);
+        }
+        return new BouncerSettings(/** @type {Partial<BouncerSettings>} */($$parsedSource));
+    }
+}
+
+/**
+ * BouncerStatus reports whether the listener is running and who is attached.
+ */
+export class BouncerStatus {
+    /**
+     * Creates a new BouncerStatus instance.
+     * @param {Partial<BouncerStatus>} [$$source = {}] - The source object to create the BouncerStatus.
+     */
+    constructor($$source = {}) {
+        if (!("running" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["running"] = false;
+        }
+        if (!("address" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["address"] = "";
+        }
+        if (!("error" in $$source)) {
+            /**
+             * why the last start failed, if it did
+             * @member
+             * @type {string}
+             */
+            this["error"] = "";
+        }
+        if (!("clients" in $$source)) {
+            /**
+             * @member
+             * @type {BouncerClient[]}
+             */
+            this["clients"] = [];
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new BouncerStatus instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {BouncerStatus}
+     */
+    static createFrom($$source = {}) {
+        const $$createField3_0 = $$createType16;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("clients" in $$parsedSource) {
+            $$parsedSource["clients"] = $$createField3_0($$parsedSource["clients"]);
+        }
+        return new BouncerStatus(/** @type {Partial<BouncerStatus>} */($$parsedSource));
+    }
+}
+
 /**
  * BuildInfo is the build metadata exposed to the frontend. buildDate is a
  * string (ISO-8601), never time.Time: a time.Time in a Wails-bound signature
@@ -104,143 +524,355 @@ export class BuildInfo {
         if (!("version" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {string}
+             */
+            this["version"] = "";
+        }
+        if (!("commit" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["commit"] = "";
+        }
+        if (!("buildDate" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["buildDate"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new BuildInfo instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {BuildInfo}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new BuildInfo(/** @type {Partial<BuildInfo>} */($$parsedSource));
+    }
+}
+
+/**
+ * ChannelInfo represents channel information with users
+ */
+export class ChannelInfo {
+    /**
+     * Creates a new ChannelInfo instance.
+     * @param {Partial<ChannelInfo>} [$$source = {}] - The source object to create the ChannelInfo.
+     */
+    constructor($$source = {}) {
+        if (!("channel" in $$source)) {
+            /**
+             * @member
+             * @type {storage$0.Channel | null}
+             */
+            this["channel"] = null;
+        }
+        if (!("users" in $$source)) {
+            /**
+             * @member
+             * @type {storage$0.ChannelUser[]}
+             */
+            this["users"] = [];
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {ServerCapabilitiesInfo | null | undefined}
+             */
+            this["capabilities"] = undefined;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ChannelInfo instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ChannelInfo}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType2;
+        const $$createField1_0 = $$createType4;
+        const $$createField2_0 = $$createType6;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("channel" in $$parsedSource) {
+            $$parsedSource["channel"] = $$createField0_0($$parsedSource["channel"]);
+        }
+        if ("users" in $$parsedSource) {
+            $$parsedSource["users"] = $$createField1_0($$parsedSource["users"]);
+        }
+        if ("capabilities" in $$parsedSource) {
+            $$parsedSource["capabilities"] = $$createField2_0($$parsedSource["capabilities"]);
+        }
+        return new ChannelInfo(/** @type {Partial<ChannelInfo>} */($$parsedSource));
+    }
+}
+
+/**
+ * ChannelListCacheResult is a network's stored channel directory as returned
+ * to the frontend. FetchedAt is unix milliseconds; Found is false when the
+ * network's channels have never been listed. Filter is the LIST argument of the
+ * latest fetch, empty for a full listing, and NewCount counts the channels that
+ * fetch was the first to list.
+ */
+export class ChannelListCacheResult {
+    /**
+     * Creates a new ChannelListCacheResult instance.
+     * @param {Partial<ChannelListCacheResult>} [$$source = {}] - The source object to create the ChannelListCacheResult.
+     */
+    constructor($$source = {}) {
+        if (!("channels" in $$source)) {
+            /**
+             * @member
+             * @type {storage$0.ChannelDirectoryEntry[]}
+             */
+            this["channels"] = [];
+        }
+        if (!("fetchedAt" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["fetchedAt"] = 0;
+        }
+        if (!("found" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["found"] = false;
+        }
+        if (!("filter" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["filter"] = "";
+        }
+        if (!("newCount" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["newCount"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ChannelListCacheResult instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ChannelListCacheResult}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType9;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("channels" in $$parsedSource) {
+            $$parsedSource["channels"] = $$createField0_0($$parsedSource["channels"]);
+        }
+        return new ChannelListCacheResult(/** @type {Partial<ChannelListCacheResult>} */($$parsedSource));
+    }
+}
+
+/**
+ * ChannelStats summarises a channel's activity over a window. ByHour and
+ * ByWeekday count lines in local time (ByWeekday starts on Sunday); Daily
+ * holds one entry per local day that saw any activity.
+ */
+export class ChannelStats {
+    /**
+     * Creates a new ChannelStats instance.
+     * @param {Partial<ChannelStats>} [$$source = {}] - The source object to create the ChannelStats.
+     */
+    constructor($$source = {}) {
+        if (!("channel" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["channel"] = "";
+        }
+        if (!("from" in $$source)) {
+            /**
+             * Unix seconds; 0 means from the start
+             * @member
+             * @type {number}
+             */
+            this["from"] = 0;
+        }
+        if (!("to" in $$source)) {
+            /**
+             * Unix seconds; 0 means until now
+             * @member
+             * @type {number}
+             */
+            this["to"] = 0;
+        }
+        if (!("lines" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["lines"] = 0;
+        }
+        if (!("words" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["words"] = 0;
+        }
+        if (!("urls" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["urls"] = 0;
+        }
+        if (!("joins" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["joins"] = 0;
+        }
+        if (!("parts" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["parts"] = 0;
+        }
+        if (!("nicks" in $$source)) {
+            /**
+             * @member
+             * @type {number}
              */
-            this["version"] = "";
+            this["nicks"] = 0;
         }
-        if (!("commit" in $$source)) {
+        if (!("speakers" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {number}
              */
-            this["commit"] = "";
+            this["speakers"] = 0;
         }
-        if (!("buildDate" in $$source)) {
+        if (!("topTalkers" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {storage$0.NickActivity[]}
              */
-            this["buildDate"] = "";
+            this["topTalkers"] = [];
         }
-
-        Object.assign(this, $$source);
-    }
-
-    /**
-     * Creates a new BuildInfo instance from a string or object.
-     * @param {any} [$$source = {}]
-     * @returns {BuildInfo}
-     */
-    static createFrom($$source = {}) {
-        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new BuildInfo(/** @type {Partial<BuildInfo>} */($$parsedSource));
-    }
-}
-
-/**
- * ChannelInfo represents channel information with users
- */
-export class ChannelInfo {
-    /**
-     * Creates a new ChannelInfo instance.
-     * @param {Partial<ChannelInfo>} [$$source = {}] - The source object to create the ChannelInfo.
-     */
-    constructor($$source = {}) {
-        if (!("channel" in $$source)) {
+        if (!("byHour" in $$source)) {
             /**
              * @member
-             * @type {storage$0.Channel | null}
+             * @type {number[]}
              */
-            this["channel"] = null;
+            this["byHour"] = [];
         }
-        if (!("users" in $$source)) {
+        if (!("byWeekday" in $$source)) {
             /**
              * @member
-             * @type {storage$0.ChannelUser[]}
+             * @type {number[]}
              */
-            this["users"] = [];
+            this["byWeekday"] = [];
         }
-        if (/** @type {any} */(false)) {
+        if (!("daily" in $$source)) {
             /**
              * @member
-             * @type {ServerCapabilitiesInfo | null | undefined}
+             * @type {ChannelStatsDay[]}
              */
-            this["capabilities"] = undefined;
+            this["daily"] = [];
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new ChannelInfo instance from a string or object.
+     * Creates a new ChannelStats instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {ChannelInfo}
+     * @returns {ChannelStats}
      */
     static createFrom($$source = {}) {
-        const $$createField0_0 = $$createType2;
-        const $$createField1_0 = $$createType4;
-        const $$createField2_0 = $$createType6;
+        const $$createField10_0 = $$createType18;
+        const $$createField11_0 = $$createType0;
+        const $$createField12_0 = $$createType0;
+        const $$createField13_0 = $$createType20;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        if ("channel" in $$parsedSource) {
-            $$parsedSource["channel"] = $$createField0_0($$parsedSource["channel"]);
+        if ("topTalkers" in $$parsedSource) {
+            $$parsedSource["topTalkers"] = $$createField10_0($$parsedSource["topTalkers"]);
         }
-        if ("users" in $$parsedSource) {
-            $$parsedSource["users"] = $$createField1_0($$parsedSource["users"]);
+        if ("byHour" in $$parsedSource) {
+            $$parsedSource["byHour"] = $$createField11_0($$parsedSource["byHour"]);
         }
-        if ("capabilities" in $$parsedSource) {
-            $$parsedSource["capabilities"] = $$createField2_0($$parsedSource["capabilities"]);
+        if ("byWeekday" in $$parsedSource) {
+            $$parsedSource["byWeekday"] = $$createField12_0($$parsedSource["byWeekday"]);
         }
-        return new ChannelInfo(/** @type {Partial<ChannelInfo>} */($$parsedSource));
+        if ("daily" in $$parsedSource) {
+            $$parsedSource["daily"] = $$createField13_0($$parsedSource["daily"]);
+        }
+        return new ChannelStats(/** @type {Partial<ChannelStats>} */($$parsedSource));
     }
 }
 
 /**
- * ChannelListCacheResult is the cached LIST result returned to the frontend.
- * FetchedAt is unix milliseconds; Found is false when no cache exists for the network.
+ * ChannelStatsDay is one local day's traffic and join/part churn.
  */
-export class ChannelListCacheResult {
+export class ChannelStatsDay {
     /**
-     * Creates a new ChannelListCacheResult instance.
-     * @param {Partial<ChannelListCacheResult>} [$$source = {}] - The source object to create the ChannelListCacheResult.
+     * Creates a new ChannelStatsDay instance.
+     * @param {Partial<ChannelStatsDay>} [$$source = {}] - The source object to create the ChannelStatsDay.
      */
     constructor($$source = {}) {
-        if (!("channels" in $$source)) {
+        if (!("date" in $$source)) {
             /**
+             * YYYY-MM-DD
              * @member
-             * @type {{ [_ in string]?: any }[]}
+             * @type {string}
              */
-            this["channels"] = [];
+            this["date"] = "";
         }
-        if (!("fetchedAt" in $$source)) {
+        if (!("lines" in $$source)) {
             /**
              * @member
              * @type {number}
              */
-            this["fetchedAt"] = 0;
+            this["lines"] = 0;
         }
-        if (!("found" in $$source)) {
+        if (!("joins" in $$source)) {
             /**
              * @member
-             * @type {boolean}
+             * @type {number}
              */
-            this["found"] = false;
+            this["joins"] = 0;
+        }
+        if (!("parts" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["parts"] = 0;
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new ChannelListCacheResult instance from a string or object.
+     * Creates a new ChannelStatsDay instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {ChannelListCacheResult}
+     * @returns {ChannelStatsDay}
      */
     static createFrom($$source = {}) {
-        const $$createField0_0 = $$createType8;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        if ("channels" in $$parsedSource) {
-            $$parsedSource["channels"] = $$createField0_0($$parsedSource["channels"]);
-        }
-        return new ChannelListCacheResult(/** @type {Partial<ChannelListCacheResult>} */($$parsedSource));
+        return new ChannelStatsDay(/** @type {Partial<ChannelStatsDay>} */($$parsedSource));
     }
 }
 
@@ -314,6 +946,86 @@ export class CommandInfo {
     }
 }
 
+/**
+ * ConfigImportResult reports what ImportConfiguration changed.
+ */
+export class ConfigImportResult {
+    /**
+     * Creates a new ConfigImportResult instance.
+     * @param {Partial<ConfigImportResult>} [$$source = {}] - The source object to create the ConfigImportResult.
+     */
+    constructor($$source = {}) {
+        if (!("created" in $$source)) {
+            /**
+             * networks added
+             * @member
+             * @type {string[]}
+             */
+            this["created"] = [];
+        }
+        if (!("updated" in $$source)) {
+            /**
+             * existing networks (matched by name) overwritten
+             * @member
+             * @type {string[]}
+             */
+            this["updated"] = [];
+        }
+        if (!("skipped" in $$source)) {
+            /**
+             * networks that could not be imported, with the reason
+             * @member
+             * @type {string[]}
+             */
+            this["skipped"] = [];
+        }
+        if (!("settings" in $$source)) {
+            /**
+             * app settings applied
+             * @member
+             * @type {number}
+             */
+            this["settings"] = 0;
+        }
+        if (!("warnings" in $$source)) {
+            /**
+             * things the source had that were not carried over
+             * @member
+             * @type {string[]}
+             */
+            this["warnings"] = [];
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ConfigImportResult instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ConfigImportResult}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType0;
+        const $$createField1_0 = $$createType0;
+        const $$createField2_0 = $$createType0;
+        const $$createField4_0 = $$createType0;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("created" in $$parsedSource) {
+            $$parsedSource["created"] = $$createField0_0($$parsedSource["created"]);
+        }
+        if ("updated" in $$parsedSource) {
+            $$parsedSource["updated"] = $$createField1_0($$parsedSource["updated"]);
+        }
+        if ("skipped" in $$parsedSource) {
+            $$parsedSource["skipped"] = $$createField2_0($$parsedSource["skipped"]);
+        }
+        if ("warnings" in $$parsedSource) {
+            $$parsedSource["warnings"] = $$createField4_0($$parsedSource["warnings"]);
+        }
+        return new ConfigImportResult(/** @type {Partial<ConfigImportResult>} */($$parsedSource));
+    }
+}
+
 /**
  * FileTransferPage is the paginated Wails response for the History tab.
  */
@@ -347,7 +1059,7 @@ export class FileTransferPage {
      * @returns {FileTransferPage}
      */
     static createFrom($$source = {}) {
-        const $$createField0_0 = $$createType10;
+        const $$createField0_0 = $$createType11;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
         if ("transfers" in $$parsedSource) {
             $$parsedSource["transfers"] = $$createField0_0($$parsedSource["transfers"]);
@@ -621,162 +1333,331 @@ export class NetworkConfig {
              * @member
              * @type {boolean}
              */
-            this["sasl_enabled"] = false;
+            this["sasl_enabled"] = false;
+        }
+        if (!("sasl_mechanism" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["sasl_mechanism"] = "";
+        }
+        if (!("sasl_username" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["sasl_username"] = "";
+        }
+        if (!("sasl_password" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["sasl_password"] = "";
+        }
+        if (!("sasl_external_cert" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["sasl_external_cert"] = "";
+        }
+        if (!("auto_connect" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["auto_connect"] = false;
+        }
+        if (!("identify_as_bot" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["identify_as_bot"] = false;
+        }
+        if (!("flood_profile" in $$source)) {
+            /**
+             * "", "standard", "cautious" or "relaxed"
+             * @member
+             * @type {string}
+             */
+            this["flood_profile"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new NetworkConfig instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {NetworkConfig}
+     */
+    static createFrom($$source = {}) {
+        const $$createField4_0 = $$createType13;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("servers" in $$parsedSource) {
+            $$parsedSource["servers"] = $$createField4_0($$parsedSource["servers"]);
+        }
+        return new NetworkConfig(/** @type {Partial<NetworkConfig>} */($$parsedSource));
+    }
+}
+
+/**
+ * NetworkPrefill carries deep-link data for a network Cascade doesn't have saved
+ * yet, so the settings window can open the Add Network form prefilled.
+ */
+export class NetworkPrefill {
+    /**
+     * Creates a new NetworkPrefill instance.
+     * @param {Partial<NetworkPrefill>} [$$source = {}] - The source object to create the NetworkPrefill.
+     */
+    constructor($$source = {}) {
+        if (!("host" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["host"] = "";
+        }
+        if (!("port" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["port"] = 0;
+        }
+        if (!("tls" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["tls"] = false;
+        }
+        if (!("channel" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["channel"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new NetworkPrefill instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {NetworkPrefill}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new NetworkPrefill(/** @type {Partial<NetworkPrefill>} */($$parsedSource));
+    }
+}
+
+/**
+ * OutboundQueueStatus is what a network's connection still has to write,
+ * counted in lines per send class.
+ */
+export class OutboundQueueStatus {
+    /**
+     * Creates a new OutboundQueueStatus instance.
+     * @param {Partial<OutboundQueueStatus>} [$$source = {}] - The source object to create the OutboundQueueStatus.
+     */
+    constructor($$source = {}) {
+        if (!("profile" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["profile"] = "";
         }
-        if (!("sasl_mechanism" in $$source)) {
+        if (!("protocol" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {number}
              */
-            this["sasl_mechanism"] = "";
+            this["protocol"] = 0;
         }
-        if (!("sasl_username" in $$source)) {
+        if (!("user" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {number}
              */
-            this["sasl_username"] = "";
+            this["user"] = 0;
         }
-        if (!("sasl_password" in $$source)) {
+        if (!("paste" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {number}
              */
-            this["sasl_password"] = "";
+            this["paste"] = 0;
         }
-        if (!("sasl_external_cert" in $$source)) {
+        if (!("automation" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {number}
              */
-            this["sasl_external_cert"] = "";
+            this["automation"] = 0;
         }
-        if (!("auto_connect" in $$source)) {
+        if (!("queuedBytes" in $$source)) {
             /**
              * @member
-             * @type {boolean}
+             * @type {number}
              */
-            this["auto_connect"] = false;
+            this["queuedBytes"] = 0;
         }
-        if (!("identify_as_bot" in $$source)) {
+        if (!("oldestWaitMs" in $$source)) {
             /**
              * @member
-             * @type {boolean}
+             * @type {number}
              */
-            this["identify_as_bot"] = false;
+            this["oldestWaitMs"] = 0;
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new NetworkConfig instance from a string or object.
+     * Creates a new OutboundQueueStatus instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {NetworkConfig}
+     * @returns {OutboundQueueStatus}
      */
     static createFrom($$source = {}) {
-        const $$createField4_0 = $$createType12;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        if ("servers" in $$parsedSource) {
-            $$parsedSource["servers"] = $$createField4_0($$parsedSource["servers"]);
-        }
-        return new NetworkConfig(/** @type {Partial<NetworkConfig>} */($$parsedSource));
+        return new OutboundQueueStatus(/** @type {Partial<OutboundQueueStatus>} */($$parsedSource));
     }
 }
 
 /**
- * NetworkPrefill carries deep-link data for a network Cascade doesn't have saved
- * yet, so the settings window can open the Add Network form prefilled.
+ * PendingDeepLink is a deep-link action emitted before the webview was ready,
+ * buffered so the frontend can drain it on mount.
  */
-export class NetworkPrefill {
+export class PendingDeepLink {
     /**
-     * Creates a new NetworkPrefill instance.
-     * @param {Partial<NetworkPrefill>} [$$source = {}] - The source object to create the NetworkPrefill.
+     * Creates a new PendingDeepLink instance.
+     * @param {Partial<PendingDeepLink>} [$$source = {}] - The source object to create the PendingDeepLink.
      */
     constructor($$source = {}) {
-        if (!("host" in $$source)) {
+        if (!("event" in $$source)) {
             /**
              * @member
              * @type {string}
              */
-            this["host"] = "";
+            this["event"] = "";
         }
-        if (!("port" in $$source)) {
+        if (!("data" in $$source)) {
             /**
              * @member
-             * @type {number}
+             * @type {{ [_ in string]?: any }}
              */
-            this["port"] = 0;
+            this["data"] = {};
         }
-        if (!("tls" in $$source)) {
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new PendingDeepLink instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {PendingDeepLink}
+     */
+    static createFrom($$source = {}) {
+        const $$createField1_0 = $$createType7;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("data" in $$parsedSource) {
+            $$parsedSource["data"] = $$createField1_0($$parsedSource["data"]);
+        }
+        return new PendingDeepLink(/** @type {Partial<PendingDeepLink>} */($$parsedSource));
+    }
+}
+
+/**
+ * PerformSecret reports whether a secret referenced by the perform list is
+ * stored. The value is never returned.
+ */
+export class PerformSecret {
+    /**
+     * Creates a new PerformSecret instance.
+     * @param {Partial<PerformSecret>} [$$source = {}] - The source object to create the PerformSecret.
+     */
+    constructor($$source = {}) {
+        if (!("name" in $$source)) {
             /**
              * @member
-             * @type {boolean}
+             * @type {string}
              */
-            this["tls"] = false;
+            this["name"] = "";
         }
-        if (!("channel" in $$source)) {
+        if (!("isSet" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {boolean}
              */
-            this["channel"] = "";
+            this["isSet"] = false;
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new NetworkPrefill instance from a string or object.
+     * Creates a new PerformSecret instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {NetworkPrefill}
+     * @returns {PerformSecret}
      */
     static createFrom($$source = {}) {
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new NetworkPrefill(/** @type {Partial<NetworkPrefill>} */($$parsedSource));
+        return new PerformSecret(/** @type {Partial<PerformSecret>} */($$parsedSource));
     }
 }
 
 /**
- * PendingDeepLink is a deep-link action emitted before the webview was ready,
- * buffered so the frontend can drain it on mount.
+ * PerformStep is one entry in a network's perform-on-connect list.
  */
-export class PendingDeepLink {
+export class PerformStep {
     /**
-     * Creates a new PendingDeepLink instance.
-     * @param {Partial<PendingDeepLink>} [$$source = {}] - The source object to create the PendingDeepLink.
+     * Creates a new PerformStep instance.
+     * @param {Partial<PerformStep>} [$$source = {}] - The source object to create the PerformStep.
      */
     constructor($$source = {}) {
-        if (!("event" in $$source)) {
+        if (!("kind" in $$source)) {
             /**
+             * "command", "delay" or "wait"
              * @member
              * @type {string}
              */
-            this["event"] = "";
+            this["kind"] = "";
         }
-        if (!("data" in $$source)) {
+        if (!("value" in $$source)) {
             /**
+             * the command line, or the notice pattern for "wait"
              * @member
-             * @type {{ [_ in string]?: any }}
+             * @type {string}
              */
-            this["data"] = {};
+            this["value"] = "";
+        }
+        if (!("seconds" in $$source)) {
+            /**
+             * the pause for "delay", the timeout for "wait"
+             * @member
+             * @type {number}
+             */
+            this["seconds"] = 0;
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new PendingDeepLink instance from a string or object.
+     * Creates a new PerformStep instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {PendingDeepLink}
+     * @returns {PerformStep}
      */
     static createFrom($$source = {}) {
-        const $$createField1_0 = $$createType7;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        if ("data" in $$parsedSource) {
-            $$parsedSource["data"] = $$createField1_0($$parsedSource["data"]);
-        }
-        return new PendingDeepLink(/** @type {Partial<PendingDeepLink>} */($$parsedSource));
+        return new PerformStep(/** @type {Partial<PerformStep>} */($$parsedSource));
     }
 }
 
@@ -890,6 +1771,113 @@ export class PluginInfo {
     }
 }
 
+/**
+ * ProfileMetadata is our own draft/metadata-2 profile on a network.
+ */
+export class ProfileMetadata {
+    /**
+     * Creates a new ProfileMetadata instance.
+     * @param {Partial<ProfileMetadata>} [$$source = {}] - The source object to create the ProfileMetadata.
+     */
+    constructor($$source = {}) {
+        if (!("supported" in $$source)) {
+            /**
+             * the server granted draft/metadata-2
+             * @member
+             * @type {boolean}
+             */
+            this["supported"] = false;
+        }
+        if (!("avatar" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["avatar"] = "";
+        }
+        if (!("displayName" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["displayName"] = "";
+        }
+        if (!("pronouns" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["pronouns"] = "";
+        }
+        if (!("homepage" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["homepage"] = "";
+        }
+        if (!("status" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["status"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ProfileMetadata instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ProfileMetadata}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ProfileMetadata(/** @type {Partial<ProfileMetadata>} */($$parsedSource));
+    }
+}
+
+/**
+ * RemoteCoreSettings describes the remote core configuration.
+ */
+export class RemoteCoreSettings {
+    /**
+     * Creates a new RemoteCoreSettings instance.
+     * @param {Partial<RemoteCoreSettings>} [$$source = {}] - The source object to create the RemoteCoreSettings.
+     */
+    constructor($$source = {}) {
+        if (!("url" in $$source)) {
+            /**
+             * core base URL; empty when running locally
+             * @member
+             * @type {string}
+             */
+            this["url"] = "";
+        }
+        if (!("hasToken" in $$source)) {
+            /**
+             * an access token is stored (it is never returned)
+             * @member
+             * @type {boolean}
+             */
+            this["hasToken"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new RemoteCoreSettings instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {RemoteCoreSettings}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new RemoteCoreSettings(/** @type {Partial<RemoteCoreSettings>} */($$parsedSource));
+    }
+}
+
 /**
  * ScriptInfo is the Wails-bound view of a loaded script.
  */
@@ -967,6 +1955,62 @@ export class ScriptInfo {
     }
 }
 
+/**
+ * SeenReport answers /seen for one nick. Chain starts with the nick's own last
+ * sighting; when that was a nick change, each later entry is the sighting of
+ * the nick it changed to. Aliases are other nicks last seen with the same
+ * account or user@host, most recent first.
+ */
+export class SeenReport {
+    /**
+     * Creates a new SeenReport instance.
+     * @param {Partial<SeenReport>} [$$source = {}] - The source object to create the SeenReport.
+     */
+    constructor($$source = {}) {
+        if (!("nick" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["nick"] = "";
+        }
+        if (!("chain" in $$source)) {
+            /**
+             * @member
+             * @type {storage$0.SeenEntry[]}
+             */
+            this["chain"] = [];
+        }
+        if (!("aliases" in $$source)) {
+            /**
+             * @member
+             * @type {storage$0.SeenEntry[]}
+             */
+            this["aliases"] = [];
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new SeenReport instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {SeenReport}
+     */
+    static createFrom($$source = {}) {
+        const $$createField1_0 = $$createType22;
+        const $$createField2_0 = $$createType22;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("chain" in $$parsedSource) {
+            $$parsedSource["chain"] = $$createField1_0($$parsedSource["chain"]);
+        }
+        if ("aliases" in $$parsedSource) {
+            $$parsedSource["aliases"] = $$createField2_0($$parsedSource["aliases"]);
+        }
+        return new SeenReport(/** @type {Partial<SeenReport>} */($$parsedSource));
+    }
+}
+
 /**
  * ServerCapabilitiesInfo represents server capabilities for frontend
  */
@@ -1089,7 +2133,7 @@ export class ServerCapabilitiesInfo {
      * @returns {ServerCapabilitiesInfo}
      */
     static createFrom($$source = {}) {
-        const $$createField0_0 = $$createType13;
+        const $$createField0_0 = $$createType14;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
         if ("prefix" in $$parsedSource) {
             $$parsedSource["prefix"] = $$createField0_0($$parsedSource["prefix"]);
@@ -1151,6 +2195,55 @@ export class ServerConfig {
     }
 }
 
+/**
+ * ServicesLogin describes a network's NickServ login for networks without
+ * SASL. The password is never returned.
+ */
+export class ServicesLogin {
+    /**
+     * Creates a new ServicesLogin instance.
+     * @param {Partial<ServicesLogin>} [$$source = {}] - The source object to create the ServicesLogin.
+     */
+    constructor($$source = {}) {
+        if (!("account" in $$source)) {
+            /**
+             * "" means the network's nick
+             * @member
+             * @type {string}
+             */
+            this["account"] = "";
+        }
+        if (!("passwordSet" in $$source)) {
+            /**
+             * whether the automation is on
+             * @member
+             * @type {boolean}
+             */
+            this["passwordSet"] = false;
+        }
+        if (!("package" in $$source)) {
+            /**
+             * "atheme", "anope" or "ergo" while connected, else ""
+             * @member
+             * @type {string}
+             */
+            this["package"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ServicesLogin instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ServicesLogin}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ServicesLogin(/** @type {Partial<ServicesLogin>} */($$parsedSource));
+    }
+}
+
 // Private type creation functions
 const $$createType0 = $Create.Array($Create.Any);
 const $$createType1 = storage$0.Channel.createFrom;
@@ -1160,9 +2253,18 @@ const $$createType4 = $Create.Array($$createType3);
 const $$createType5 = ServerCapabilitiesInfo.createFrom;
 const $$createType6 = $Create.Nullable($$createType5);
 const $$createType7 = $Create.Map($Create.Any, $Create.Any);
-const $$createType8 = $Create.Array($$createType7);
-const $$createType9 = dcc$0.View.createFrom;
-const $$createType10 = $Create.Array($$createType9);
-const $$createType11 = ServerConfig.createFrom;
-const $$createType12 = $Create.Array($$createType11);
-const $$createType13 = $Create.Map($Create.Any, $Create.Any);
+const $$createType8 = storage$0.ChannelDirectoryEntry.createFrom;
+const $$createType9 = $Create.Array($$createType8);
+const $$createType10 = dcc$0.View.createFrom;
+const $$createType11 = $Create.Array($$createType10);
+const $$createType12 = ServerConfig.createFrom;
+const $$createType13 = $Create.Array($$createType12);
+const $$createType14 = $Create.Map($Create.Any, $Create.Any);
+const $$createType15 = BouncerClient.createFrom;
+const $$createType16 = $Create.Array($$createType15);
+const $$createType17 = storage$0.NickActivity.createFrom;
+const $$createType18 = $Create.Array($$createType17);
+const $$createType19 = ChannelStatsDay.createFrom;
+const $$createType20 = $Create.Array($$createType19);
+const $$createType21 = storage$0.SeenEntry.createFrom;
+const $$createType22 = $Create.Array($$createType21);
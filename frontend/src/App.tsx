@@ -2,7 +2,7 @@ import { useEffect, useCallback, useRef, useState } from 'react';
 import { SendCommand, OpenSettings, GetServers, ReorderNetworks } from '../wailsjs/go/main/App';
 import { EventsOn } from '../wailsjs/runtime/runtime';
 import { main } from '../wailsjs/go/models';
-import { useNetworkStore } from './stores/network';
+import { toUserMetaT, useNetworkStore } from './stores/network';
 import { useUIStore } from './stores/ui';
 import { eventMatchesPane } from './lib/pane-routing';
 import { activityTargetForEvent } from './lib/activity';
@@ -21,6 +21,7 @@ import { PinnedMessages } from './components/pinned-messages';
 import { TopicEditModal } from './components/topic-edit-modal';
 import { ChannelModeEditor } from './components/channel-mode-editor';
 import { UserInfo } from './components/user-info';
+import { EncryptionBadge } from './components/encryption-badge';
 import { SearchModal } from './components/search-modal';
 import { ChannelListModal } from './components/channel-list-modal';
 import { KeyboardShortcutsModal } from './components/keyboard-shortcuts-modal';
@@ -432,15 +433,7 @@ function App() {
         const networkId = d?.networkId;
         const nickname = d?.nickname;
         if (typeof networkId !== 'number' || typeof nickname !== 'string' || !nickname) return [];
-        return [{
-          networkId,
-          nickname,
-          away: !!d.away,
-          away_message: typeof d.away_message === 'string' ? d.away_message : '',
-          account: typeof d.account === 'string' ? d.account : '',
-          host: typeof d.host === 'string' ? d.host : '',
-          realname: typeof d.realname === 'string' ? d.realname : '',
-        }];
+        return [{ networkId, nickname, ...toUserMetaT(d) }];
       });
       if (updates.length > 0) setUserMetaBatch(updates);
     });
@@ -579,7 +572,8 @@ function App() {
     return () => unsubscribe();
   }, [selectedNetwork, networks]);
 
-  // Topic/mode change events
+  // Topic/mode change events, and smart-filtered joins un-hidden because the
+  // joiner started talking
   useEffect(() => {
     const unsubscribe = EventsOn('message-event', (data: any) => {
       if (selectedNetwork === null || selectedChannel === null || selectedChannel === 'status') return;
@@ -594,6 +588,8 @@ function App() {
       if (currentNetwork && eventNetworkId === currentNetwork.id && channel === selectedChannel) {
         if (eventType === 'channel.topic' || eventType === 'channel.mode') {
           loadChannelInfo();
+        } else if (eventType === 'message.unfiltered') {
+          loadMessages();
         }
       }
     });
@@ -929,6 +925,9 @@ function App() {
                             ? selectedChannel
                             : `#${selectedChannel}`}
                         </span>
+                        {selectedNetwork !== null && (
+                          <EncryptionBadge networkId={selectedNetwork} target={selectedChannel} isQuery={false} />
+                        )}
                       </>
                     )}
                   {selectedChannel && selectedChannel.startsWith('pm:') && (
@@ -937,6 +936,9 @@ function App() {
                       <span className="text-muted-foreground font-medium">
                         PM: {selectedChannel.substring(3)}
                       </span>
+                      {selectedNetwork !== null && (
+                        <EncryptionBadge networkId={selectedNetwork} target={selectedChannel.substring(3)} isQuery />
+                      )}
                     </>
                   )}
                   {selectedChannel === 'status' && (
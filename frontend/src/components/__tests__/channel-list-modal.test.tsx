@@ -63,7 +63,7 @@ describe('ChannelListModal cache behavior', () => {
     render(<ChannelListModal networkId={1} onClose={() => {}} />)
 
     expect(await screen.findByText('#stale')).toBeInTheDocument()
-    await waitFor(() => expect(requestListMock).toHaveBeenCalledWith(1))
+    await waitFor(() => expect(requestListMock).toHaveBeenCalledWith(1, ''))
   })
 
   it('requests a full list on cache miss', async () => {
@@ -71,7 +71,7 @@ describe('ChannelListModal cache behavior', () => {
 
     render(<ChannelListModal networkId={1} onClose={() => {}} />)
 
-    await waitFor(() => expect(requestListMock).toHaveBeenCalledWith(1))
+    await waitFor(() => expect(requestListMock).toHaveBeenCalledWith(1, ''))
     expect(screen.getByText('Loading channel list...')).toBeInTheDocument()
   })
 
@@ -0,0 +1,104 @@
+import { useEffect, useState } from 'react';
+import { irc } from '../../wailsjs/go/models';
+import { GetAccountRegistrationSupport, RegisterAccount, VerifyAccount } from '../../wailsjs/go/main/App';
+import { EventsOn } from '../../wailsjs/runtime/runtime';
+
+const fieldClass = 'w-full px-2 py-1 text-sm border border-border rounded';
+
+interface RegistrationEvent {
+  networkId: number;
+  status: 'success' | 'verification_required' | 'failed';
+  account: string;
+  code: string;
+  message: string;
+}
+
+// AccountRegistrationForm registers an account on servers that advertise
+// draft/account-registration. A registered account becomes the network's SASL
+// PLAIN login, so the next connection logs in on its own.
+export function AccountRegistrationForm({ networkId }: { networkId: number }) {
+  const [support, setSupport] = useState<irc.AccountRegistrationSupport | null>(null);
+  const [account, setAccount] = useState('');
+  const [email, setEmail] = useState('');
+  const [password, setPassword] = useState('');
+  const [code, setCode] = useState('');
+  const [verifying, setVerifying] = useState(false);
+  const [status, setStatus] = useState('');
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    void GetAccountRegistrationSupport(networkId)
+      .then(setSupport)
+      .catch((e) => setError(String(e)));
+    return EventsOn('account-registration', (data: RegistrationEvent) => {
+      if (data.networkId !== networkId) return;
+      setStatus('');
+      setError('');
+      if (data.status === 'success') {
+        setVerifying(false);
+        setPassword('');
+        setCode('');
+        setStatus(`Registered ${data.account}. It's saved as this network's SASL login.`);
+      } else if (data.status === 'verification_required') {
+        setVerifying(true);
+        setStatus(data.message);
+      } else {
+        setError(data.code ? `${data.code}: ${data.message}` : data.message);
+      }
+    });
+  }, [networkId]);
+
+  const run = async (action: () => Promise<void>, pending: string) => {
+    setError('');
+    setStatus(pending);
+    try {
+      await action();
+    } catch (e) {
+      setStatus('');
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  return (
+    <div className="mt-4 p-4 border border-border rounded bg-muted/30" data-testid="account-registration-form">
+      <h5 className="font-semibold text-sm">Register an account</h5>
+      <p className="text-xs text-muted-foreground mt-1 mb-3">
+        Create an account on this network without NickServ. Leave the account empty to register your nick.
+        {support?.beforeConnect && <> This server registers before you finish connecting.</>}
+        {support && !support.supported && <> Whether the server offers registration is known once connected; registering while disconnected connects first.</>}
+      </p>
+
+      {error && <p className="text-xs text-destructive mb-2">{error}</p>}
+
+      {verifying ? (
+        <div className="flex items-center gap-2">
+          <input value={code} placeholder="Verification code" onChange={(e) => setCode(e.target.value)} className={fieldClass} />
+          <button type="button" disabled={!code.trim()} onClick={() => void run(() => VerifyAccount(networkId, code), 'Verifying…')} className="rounded-md bg-primary px-3 py-1 text-xs text-primary-foreground hover:bg-primary/90 disabled:opacity-50">Verify</button>
+        </div>
+      ) : (
+        <div className="flex items-center gap-2">
+          {(!support?.supported || support.customAccountName) && (
+            <input value={account} placeholder="Account (defaults to your nick)" onChange={(e) => setAccount(e.target.value)} className={fieldClass} />
+          )}
+          <input
+            type="email"
+            value={email}
+            placeholder={support?.emailRequired ? 'Email (required)' : 'Email (optional)'}
+            onChange={(e) => setEmail(e.target.value)}
+            className={fieldClass}
+          />
+          <input type="password" value={password} placeholder="Password" onChange={(e) => setPassword(e.target.value)} className={fieldClass} />
+          <button
+            type="button"
+            disabled={!password || (support?.emailRequired && !email.trim())}
+            onClick={() => void run(() => RegisterAccount(networkId, account, email, password), 'Registering…')}
+            className="rounded-md bg-primary px-3 py-1 text-xs text-primary-foreground hover:bg-primary/90 disabled:opacity-50"
+          >
+            Register
+          </button>
+        </div>
+      )}
+      {status && <p className="text-xs text-muted-foreground mt-2">{status}</p>}
+    </div>
+  );
+}
@@ -0,0 +1,89 @@
+import { useEffect, useState } from 'react';
+import { Terminal, Trash2, TriangleAlert } from 'lucide-react';
+import { main, storage } from '../../wailsjs/go/models';
+import { DeleteCommandAlias, GetCommandAliases, SaveCommandAlias } from '../../wailsjs/go/main/App';
+import { EventsOn } from '../../wailsjs/runtime/runtime';
+
+const inputClass =
+  'w-full px-3 py-2 text-sm border border-border rounded-lg bg-background focus:outline-none focus:ring-2 focus:ring-primary focus:border-primary font-mono';
+
+export function AliasSettings({ networks }: { networks: storage.Network[] }) {
+  const [aliases, setAliases] = useState<main.AliasDefinition[]>([]);
+  const [name, setName] = useState('');
+  const [expansion, setExpansion] = useState('');
+  const [networkId, setNetworkId] = useState(0);
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    const refresh = () => void GetCommandAliases().then((a) => setAliases(a ?? [])).catch((e) => setError(String(e)));
+    refresh();
+    return EventsOn('aliases:changed', refresh);
+  }, []);
+
+  const networkName = (id: number) => (id === 0 ? 'All networks' : networks.find((n) => n.id === id)?.name ?? `Network ${id}`);
+
+  const save = async () => {
+    setError('');
+    try {
+      await SaveCommandAlias(main.AliasDefinition.createFrom({ networkId, name, expansion }));
+      setName('');
+      setExpansion('');
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  const remove = async (alias: main.AliasDefinition) => {
+    setError('');
+    try {
+      await DeleteCommandAlias(alias.networkId, alias.name);
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  return (
+    <div className="border border-border rounded-lg p-4 bg-card/50 shadow-[var(--shadow-sm)] space-y-4 mt-4" data-testid="alias-settings">
+      <div className="flex gap-3">
+        <div className="flex h-9 w-9 shrink-0 items-center justify-center rounded-lg bg-primary/10 text-primary"><Terminal size={17} /></div>
+        <div>
+          <div className="text-sm font-semibold">Aliases</div>
+          <p className="text-xs text-muted-foreground mt-1">
+            Your own slash commands. Separate commands with <code>;</code> and use <code>$1</code>, <code>$2-</code> (the second argument onwards), <code>$*</code>, <code>$nick</code>, <code>$chan</code> and <code>$network</code>. A line without a slash is sent to the current channel or query. You can also use <code>/alias</code> and <code>/unalias</code>.
+          </p>
+        </div>
+      </div>
+
+      {error && <div className="flex gap-2 rounded-lg border border-destructive/30 bg-destructive/5 px-3 py-2 text-sm text-destructive"><TriangleAlert className="mt-0.5 shrink-0" size={15} />{error}</div>}
+
+      {aliases.length > 0 && (
+        <ul className="space-y-1 text-sm" data-testid="alias-list">
+          {aliases.map((a) => (
+            <li key={`${a.networkId}:${a.name}`} className="flex items-center gap-3 rounded-md px-2 py-1.5 hover:bg-accent/60">
+              <code className="shrink-0">/{a.name.toLowerCase()}</code>
+              <code className="min-w-0 flex-1 truncate text-muted-foreground" title={a.expansion}>{a.expansion}</code>
+              <span className="shrink-0 text-xs text-muted-foreground">{networkName(a.networkId)}</span>
+              <button className="shrink-0 text-muted-foreground hover:text-destructive" title="Delete alias" onClick={() => void remove(a)}>
+                <Trash2 size={14} />
+              </button>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      <div className="grid grid-cols-[8rem_1fr] gap-3">
+        <input value={name} placeholder="slap" onChange={(e) => setName(e.target.value)} className={inputClass} data-testid="alias-name" />
+        <input value={expansion} placeholder="/me slaps $1 with a trout" onChange={(e) => setExpansion(e.target.value)} className={inputClass} data-testid="alias-expansion" />
+      </div>
+      <div className="flex items-center gap-3">
+        <select value={networkId} onChange={(e) => setNetworkId(Number(e.target.value))} className="h-9 rounded-md border border-border bg-background px-3 text-sm">
+          <option value={0}>All networks</option>
+          {networks.map((n) => <option key={n.id} value={n.id}>{n.name} only</option>)}
+        </select>
+        <button className="rounded-md bg-primary px-3 py-2 text-sm text-primary-foreground hover:bg-primary/90 disabled:opacity-50" disabled={!name.trim() || !expansion.trim()} onClick={() => void save()} data-testid="alias-save">
+          Save alias
+        </button>
+      </div>
+    </div>
+  );
+}
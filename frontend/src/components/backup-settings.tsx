@@ -0,0 +1,154 @@
+import { useEffect, useState } from 'react';
+import { Archive, TriangleAlert } from 'lucide-react';
+import { main } from '../../wailsjs/go/models';
+import {
+  CancelRestore,
+  CreateBackup,
+  GetBackupSettings,
+  GetBackupStatus,
+  RestoreBackup,
+  UpdateBackupSettings,
+} from '../../wailsjs/go/main/App';
+
+const inputClass =
+  'w-full px-3 py-2 text-sm border border-border rounded-lg bg-background focus:outline-none focus:ring-2 focus:ring-primary focus:border-primary font-mono';
+
+const buttonClass = 'rounded-md border border-border px-3 py-2 text-sm hover:bg-accent disabled:opacity-50';
+
+function formatTime(iso: string) {
+  return iso ? new Date(iso).toLocaleString() : '';
+}
+
+export function BackupSettings() {
+  const [settings, setSettings] = useState<main.BackupSettings | null>(null);
+  const [status, setStatus] = useState<main.BackupStatus | null>(null);
+  const [passphrase, setPassphrase] = useState('');
+  const [restorePath, setRestorePath] = useState('');
+  const [restorePassphrase, setRestorePassphrase] = useState('');
+  const [busy, setBusy] = useState(false);
+  const [notice, setNotice] = useState('');
+  const [error, setError] = useState('');
+
+  const refresh = () => void GetBackupStatus().then(setStatus).catch(() => {});
+
+  useEffect(() => {
+    void GetBackupSettings().then(setSettings).catch((e) => setError(String(e)));
+    refresh();
+  }, []);
+
+  const run = async (action: () => Promise<string>) => {
+    setError('');
+    setNotice('');
+    setBusy(true);
+    try {
+      setNotice(await action());
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+    setBusy(false);
+    refresh();
+  };
+
+  const save = () =>
+    run(async () => {
+      await UpdateBackupSettings(settings!, passphrase);
+      setSettings(await GetBackupSettings());
+      setPassphrase('');
+      return 'Saved';
+    });
+
+  const backUpNow = () =>
+    run(async () => {
+      const info = await CreateBackup('', passphrase);
+      return `Backup written to ${info.path}`;
+    });
+
+  const restore = () =>
+    run(async () => {
+      const info = await RestoreBackup(restorePath.trim(), restorePassphrase);
+      setRestorePassphrase('');
+      return `The backup from ${formatTime(info.createdAt)} replaces your current data the next time Cascade starts.`;
+    });
+
+  if (!settings) return null;
+
+  const update = (patch: Partial<main.BackupSettings>) => setSettings(main.BackupSettings.createFrom({ ...settings, ...patch }));
+
+  return (
+    <div className="border border-border rounded-lg p-4 bg-card/50 shadow-[var(--shadow-sm)] space-y-4 mt-4">
+      <div className="flex gap-3">
+        <div className="flex h-9 w-9 shrink-0 items-center justify-center rounded-lg bg-primary/10 text-primary"><Archive size={17} /></div>
+        <div>
+          <div className="text-sm font-semibold">Backups</div>
+          <p className="text-xs text-muted-foreground mt-1">
+            Save your history, settings, saved passwords, network icons, scripts and plugins to one file, encrypted with a passphrase. Backups are taken while Cascade keeps running.
+          </p>
+        </div>
+      </div>
+
+      {error && <div className="flex gap-2 rounded-lg border border-destructive/30 bg-destructive/5 px-3 py-2 text-sm text-destructive"><TriangleAlert className="mt-0.5 shrink-0" size={15} />{error}</div>}
+      {notice && <p className="text-xs text-muted-foreground" data-testid="backup-notice">{notice}</p>}
+
+      <div>
+        <label className="block text-sm font-medium mb-1.5">Passphrase</label>
+        <input
+          type="password"
+          value={passphrase}
+          placeholder={settings.hasPassphrase ? 'Stored — leave blank to keep it' : 'Needed to restore; it cannot be recovered'}
+          onChange={(e) => setPassphrase(e.target.value)}
+          className={inputClass}
+          data-testid="backup-passphrase"
+        />
+      </div>
+      <div>
+        <label className="block text-sm font-medium mb-1.5">Backup folder</label>
+        <input value={settings.directory} placeholder={status?.directory || 'Inside the data directory'} onChange={(e) => update({ directory: e.target.value })} className={inputClass} />
+      </div>
+
+      <label className="flex items-center gap-2 text-sm font-medium">
+        <input type="checkbox" checked={settings.enabled} onChange={(e) => update({ enabled: e.target.checked })} data-testid="backup-enabled" />
+        Back up automatically
+      </label>
+      <div className={settings.enabled ? 'grid grid-cols-2 gap-3' : 'grid grid-cols-2 gap-3 pointer-events-none opacity-50'}>
+        <div>
+          <label className="block text-sm font-medium mb-1.5">Every (hours)</label>
+          <input type="number" min={1} value={settings.intervalHours} onChange={(e) => update({ intervalHours: Number(e.target.value) })} className={inputClass} />
+        </div>
+        <div>
+          <label className="block text-sm font-medium mb-1.5">Keep</label>
+          <input type="number" min={1} value={settings.keep} onChange={(e) => update({ keep: Number(e.target.value) })} className={inputClass} />
+        </div>
+      </div>
+
+      <div className="flex items-center gap-2">
+        <button className="rounded-md bg-primary px-3 py-2 text-sm text-primary-foreground hover:bg-primary/90" onClick={() => void save()} disabled={busy}>Save</button>
+        <button className={buttonClass} onClick={() => void backUpNow()} disabled={busy} data-testid="backup-now">Back up now</button>
+      </div>
+      {status && (
+        <p className="text-xs text-muted-foreground" data-testid="backup-status">
+          {status.lastBackup ? `Last backup ${formatTime(status.lastBackup)}` : 'No backups yet'}
+          {status.lastError && <span className="text-destructive"> · Last scheduled backup failed: {status.lastError}</span>}
+        </p>
+      )}
+
+      <div className="space-y-3 border-t border-border pt-4">
+        <div className="text-sm font-medium">Restore</div>
+        {status?.restorePending ? (
+          <div className="flex items-center gap-2 text-xs">
+            <span>A restore is waiting. Restart Cascade to apply it.</span>
+            <button className={buttonClass} onClick={() => void run(async () => { await CancelRestore(); return 'Restore cancelled'; })}>Cancel restore</button>
+          </div>
+        ) : (
+          <>
+            <div className="grid grid-cols-2 gap-3">
+              <input value={restorePath} placeholder="/path/to/cascade-….cascadebak" onChange={(e) => setRestorePath(e.target.value)} className={inputClass} data-testid="restore-path" />
+              <input type="password" value={restorePassphrase} placeholder="Backup passphrase" onChange={(e) => setRestorePassphrase(e.target.value)} className={inputClass} />
+            </div>
+            <button className={buttonClass} onClick={() => void restore()} disabled={busy || !restorePath.trim()} data-testid="restore-backup">Restore…</button>
+            <p className="text-xs text-muted-foreground">The backup is checked now and applied at the next start. Your current data is moved to the backups folder, not deleted.</p>
+          </>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,128 @@
+import { useEffect, useState } from 'react';
+import { Cable, TriangleAlert } from 'lucide-react';
+import { main } from '../../wailsjs/go/models';
+import { GetBouncerSettings, GetBouncerStatus, UpdateBouncerSettings } from '../../wailsjs/go/main/App';
+
+const inputClass =
+  'w-full px-3 py-2 text-sm border border-border rounded-lg bg-background focus:outline-none focus:ring-2 focus:ring-primary focus:border-primary font-mono';
+
+const STATUS_POLL_MS = 5000;
+
+export function BouncerSettings() {
+  const [settings, setSettings] = useState<main.BouncerSettings | null>(null);
+  const [status, setStatus] = useState<main.BouncerStatus | null>(null);
+  const [fingerprints, setFingerprints] = useState('');
+  const [password, setPassword] = useState('');
+  const [saved, setSaved] = useState(false);
+  const [error, setError] = useState('');
+
+  const load = (s: main.BouncerSettings) => {
+    setSettings(s);
+    setFingerprints(s.clientFingerprints.join('\n'));
+  };
+
+  useEffect(() => {
+    void GetBouncerSettings().then(load).catch((e) => setError(String(e)));
+    const refresh = () => void GetBouncerStatus().then(setStatus).catch(() => {});
+    refresh();
+    const timer = window.setInterval(refresh, STATUS_POLL_MS);
+    return () => window.clearInterval(timer);
+  }, []);
+
+  const save = async (next: main.BouncerSettings) => {
+    setError('');
+    try {
+      const clientFingerprints = fingerprints.split(/\s*\n\s*/).map((f) => f.trim()).filter(Boolean);
+      await UpdateBouncerSettings(main.BouncerSettings.createFrom({ ...next, clientFingerprints }), password);
+      load(await GetBouncerSettings());
+      setPassword('');
+      setSaved(true);
+      window.setTimeout(() => setSaved(false), 1600);
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+    setStatus(await GetBouncerStatus());
+  };
+
+  if (!settings) return null;
+
+  const update = (patch: Partial<main.BouncerSettings>) => setSettings(main.BouncerSettings.createFrom({ ...settings, ...patch }));
+
+  return (
+    <div className="border border-border rounded-lg p-4 bg-card/50 shadow-[var(--shadow-sm)] space-y-4 mt-4">
+      <div className="flex gap-3">
+        <div className="flex h-9 w-9 shrink-0 items-center justify-center rounded-lg bg-primary/10 text-primary"><Cable size={17} /></div>
+        <div>
+          <div className="text-sm font-semibold">Bouncer listener</div>
+          <p className="text-xs text-muted-foreground mt-1">
+            Let other IRC clients — a phone, a terminal client — attach to the networks Cascade is connected to. They log in with the password (or a trusted TLS client certificate) and pick a network with their username, e.g. <code>phone/libera</code>. Missed messages are replayed from Cascade's history, and anything they send also shows up here.
+          </p>
+        </div>
+      </div>
+
+      {error && <div className="flex gap-2 rounded-lg border border-destructive/30 bg-destructive/5 px-3 py-2 text-sm text-destructive"><TriangleAlert className="mt-0.5 shrink-0" size={15} />{error}</div>}
+
+      <label className="flex items-center gap-2 text-sm font-medium">
+        <input type="checkbox" checked={settings.enabled} onChange={(e) => update({ enabled: e.target.checked })} data-testid="bouncer-enabled" />
+        Accept IRC clients
+      </label>
+
+      <div className={settings.enabled ? 'space-y-4' : 'space-y-4 pointer-events-none opacity-50'}>
+        <div>
+          <label className="block text-sm font-medium mb-1.5">Listen address</label>
+          <input value={settings.listen} placeholder="127.0.0.1:6670" onChange={(e) => update({ listen: e.target.value })} className={inputClass} data-testid="bouncer-listen" />
+          <p className="text-xs text-muted-foreground mt-1">Anything other than loopback needs TLS. Only clients on this computer or the local network are accepted.</p>
+        </div>
+        <div>
+          <label className="block text-sm font-medium mb-1.5">Password</label>
+          <input
+            type="password"
+            value={password}
+            placeholder={settings.hasPassword ? 'Stored — leave blank to keep it' : 'Sent by clients with PASS'}
+            onChange={(e) => setPassword(e.target.value)}
+            className={inputClass}
+            data-testid="bouncer-password"
+          />
+        </div>
+        <div className="grid grid-cols-2 gap-3">
+          <div>
+            <label className="block text-sm font-medium mb-1.5">TLS certificate</label>
+            <input value={settings.tlsCert} placeholder="/path/to/cert.pem" onChange={(e) => update({ tlsCert: e.target.value })} className={inputClass} />
+          </div>
+          <div>
+            <label className="block text-sm font-medium mb-1.5">TLS key</label>
+            <input value={settings.tlsKey} placeholder="/path/to/key.pem" onChange={(e) => update({ tlsKey: e.target.value })} className={inputClass} />
+          </div>
+        </div>
+        <div>
+          <label className="block text-sm font-medium mb-1.5">Trusted client certificates</label>
+          <textarea rows={2} value={fingerprints} placeholder="SHA-256 fingerprint, one per line" onChange={(e) => setFingerprints(e.target.value)} className={inputClass} />
+        </div>
+        <div>
+          <label className="block text-sm font-medium mb-1.5">Messages replayed per buffer</label>
+          <input type="number" min={0} max={500} value={settings.replayLimit || ''} placeholder="50" onChange={(e) => update({ replayLimit: Number(e.target.value) })} className={inputClass} />
+          <p className="text-xs text-muted-foreground mt-1">For clients without CHATHISTORY support; the rest fetch what they need.</p>
+        </div>
+      </div>
+
+      <div className="flex items-center gap-2">
+        <button className="rounded-md bg-primary px-3 py-2 text-sm text-primary-foreground hover:bg-primary/90" onClick={() => void save(settings)}>Save</button>
+        {saved && <span className="text-xs text-muted-foreground">Saved</span>}
+      </div>
+
+      {status?.running && (
+        <div className="text-xs text-muted-foreground" data-testid="bouncer-status">
+          Listening on <code>{status.address}</code> · {status.clients.length === 0 ? 'no clients attached' : `${status.clients.length} attached`}
+          {status.clients.length > 0 && (
+            <ul className="mt-1 space-y-0.5">
+              {status.clients.map((c) => (
+                <li key={`${c.address}-${c.since}`}><code>{c.name || 'client'}</code> on {c.network} from {c.address}</li>
+              ))}
+            </ul>
+          )}
+        </div>
+      )}
+      {!status?.running && status?.error && <p className="text-xs text-destructive">Not listening: {status.error}</p>}
+    </div>
+  );
+}
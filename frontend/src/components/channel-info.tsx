@@ -149,9 +149,11 @@ export function ChannelInfo({ networkId, channelName, currentNickname, onSendCom
         return;
       }
       
-      // Refresh on user join/part/quit/kick/nick events or when NAMES list completes
+      // Refresh on user join/part/quit/kick/nick events, netsplit/netjoin
+      // summaries, or when NAMES list completes
       if (eventType === 'user.joined' || eventType === 'user.parted' || eventType === 'user.quit' ||
           eventType === 'user.kicked' || eventType === 'user.nick' ||
+          eventType === 'netsplit' || eventType === 'netjoin' ||
           eventType === 'channel.usermode') {
         const eventNetworkId = eventData.networkId;
         
@@ -21,6 +21,8 @@ interface ChannelListEntry {
   users: number;
   topic: string;
   networkId: number;
+  // First listed by the latest fetch (the backend's "new since last fetch" diff).
+  isNew: boolean;
 }
 
 type SortField = 'channel' | 'users';
@@ -51,7 +53,8 @@ function mapEntries(items: any[], networkId: number): ChannelListEntry[] {
     channel: item.channel || '',
     users: item.users || 0,
     topic: item.topic || '',
-    networkId: item.networkId || networkId,
+    networkId: item.networkId || item.network_id || networkId,
+    isNew: !!item.new,
   }));
 }
 
@@ -81,7 +84,7 @@ export function ChannelListModal({ networkId, initialFilter, onClose }: ChannelL
   const doRefresh = useCallback(() => {
     setRefreshing(true);
     setError(null);
-    RequestChannelList(networkId).catch((err) => {
+    RequestChannelList(networkId, '').catch((err) => {
       setError(`Failed to request channel list: ${err}`);
       setRefreshing(false);
       setLoading(false);
@@ -119,7 +122,7 @@ export function ChannelListModal({ networkId, initialFilter, onClose }: ChannelL
       if (eventData.networkId !== networkId) return;
 
       setChannels(mapEntries(eventData.channels || [], networkId));
-      setFetchedAt(Date.now());
+      setFetchedAt(eventData.fetchedAt || Date.now());
       setLoading(false);
       setRefreshing(false);
       setError(null);
@@ -174,6 +177,8 @@ export function ChannelListModal({ networkId, initialFilter, onClose }: ChannelL
     return result;
   }, [channels, filter, sortField, sortDirection]);
 
+  const newCount = useMemo(() => channels.filter((ch) => ch.isNew).length, [channels]);
+
   const handleSort = (field: SortField) => {
     if (sortField === field) {
       setSortDirection((d) => (d === 'asc' ? 'desc' : 'asc'));
@@ -332,7 +337,14 @@ export function ChannelListModal({ networkId, initialFilter, onClose }: ChannelL
                       {joiningChannel === ch.channel ? (
                         <span className="text-muted-foreground italic">Joining...</span>
                       ) : (
-                        ch.channel
+                        <>
+                          {ch.channel}
+                          {ch.isNew && (
+                            <span className="ml-2 text-[10px] uppercase tracking-wide text-muted-foreground">
+                              new
+                            </span>
+                          )}
+                        </>
                       )}
                     </td>
                     <td className="text-right px-4 py-2 text-muted-foreground tabular-nums">
@@ -353,6 +365,7 @@ export function ChannelListModal({ networkId, initialFilter, onClose }: ChannelL
           <div className="px-5 py-2 border-t border-border text-xs text-muted-foreground">
             {filteredChannels.length} of {channels.length} channels
             {filter.trim() ? ' (filtered)' : ''}
+            {newCount > 0 ? ` · ${newCount} new since last fetch` : ''}
           </div>
         )}
       </div>
@@ -0,0 +1,135 @@
+import { useRef, useState } from 'react';
+import { ArrowDownToLine, ArrowUpFromLine, TriangleAlert } from 'lucide-react';
+import { main } from '../../wailsjs/go/models';
+import { ExportConfiguration, ImportConfiguration } from '../../wailsjs/go/main/App';
+
+const inputClass =
+  'w-full px-3 py-2 text-sm border border-border rounded-lg bg-background focus:outline-none focus:ring-2 focus:ring-primary focus:border-primary';
+
+type ExportFormat = 'json' | 'yaml';
+
+// Other clients' files are recognized by content; the select only needs to
+// override detection when it guesses wrong.
+const IMPORT_FORMATS: { value: string; label: string }[] = [
+  { value: '', label: 'Detect automatically' },
+  { value: 'json', label: 'Cascade bundle (JSON)' },
+  { value: 'yaml', label: 'Cascade bundle (YAML)' },
+  { value: 'irssi', label: 'irssi (~/.irssi/config)' },
+  { value: 'weechat', label: 'WeeChat (irc.conf)' },
+  { value: 'hexchat', label: 'HexChat (servlist.conf)' },
+];
+
+function download(name: string, text: string, type: string) {
+  const url = URL.createObjectURL(new Blob([text], { type }));
+  const a = document.createElement('a');
+  a.href = url;
+  a.download = name;
+  a.click();
+  window.setTimeout(() => URL.revokeObjectURL(url), 0);
+}
+
+export function ConfigTransfer({ onImported }: { onImported: () => void }) {
+  const [format, setFormat] = useState<ExportFormat>('json');
+  const [exportPassphrase, setExportPassphrase] = useState('');
+  const [includeSettings, setIncludeSettings] = useState(true);
+  const [importFormat, setImportFormat] = useState('');
+  const [importPassphrase, setImportPassphrase] = useState('');
+  const [result, setResult] = useState<main.ConfigImportResult | null>(null);
+  const [error, setError] = useState('');
+  const fileInput = useRef<HTMLInputElement>(null);
+
+  const exportBundle = async () => {
+    setError('');
+    try {
+      const text = await ExportConfiguration(format, exportPassphrase, includeSettings);
+      download(`cascade-networks.${format}`, text, format === 'json' ? 'application/json' : 'application/yaml');
+      setExportPassphrase('');
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  const importFile = async (file: File) => {
+    setError('');
+    setResult(null);
+    try {
+      const r = await ImportConfiguration(await file.text(), importFormat, importPassphrase);
+      setResult(r);
+      setImportPassphrase('');
+      onImported();
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  return (
+    <details className="mt-6 border border-border rounded-lg bg-card/50 shadow-[var(--shadow-sm)]" data-testid="config-transfer">
+      <summary className="cursor-pointer p-4 text-sm font-semibold">Import &amp; export</summary>
+      <div className="space-y-5 border-t border-border p-4">
+        {error && <div className="flex gap-2 rounded-lg border border-destructive/30 bg-destructive/5 px-3 py-2 text-sm text-destructive"><TriangleAlert className="mt-0.5 shrink-0" size={15} />{error}</div>}
+
+        <div className="space-y-3">
+          <div>
+            <div className="text-sm font-medium">Export</div>
+            <p className="text-xs text-muted-foreground mt-1">
+              Saves every network with its servers, SASL settings, channels, MONITOR and ignore lists. Passwords and channel keys are included only when you set a passphrase, and are encrypted with it.
+            </p>
+          </div>
+          <div className="grid grid-cols-2 gap-3">
+            <select value={format} onChange={(e) => setFormat(e.target.value as ExportFormat)} className="h-9 rounded-md border border-border bg-background px-3 text-sm">
+              <option value="json">JSON</option>
+              <option value="yaml">YAML</option>
+            </select>
+            <input type="password" value={exportPassphrase} placeholder="Passphrase (optional)" onChange={(e) => setExportPassphrase(e.target.value)} className={inputClass} data-testid="export-passphrase" />
+          </div>
+          <label className="flex items-center gap-2 text-sm">
+            <input type="checkbox" checked={includeSettings} onChange={(e) => setIncludeSettings(e.target.checked)} />
+            Include app settings
+          </label>
+          <button className="inline-flex items-center gap-2 rounded-md border border-border px-3 py-2 text-sm hover:bg-accent" onClick={() => void exportBundle()} data-testid="export-config">
+            <ArrowUpFromLine size={15} />Export…
+          </button>
+        </div>
+
+        <div className="space-y-3 border-t border-border pt-4">
+          <div>
+            <div className="text-sm font-medium">Import</div>
+            <p className="text-xs text-muted-foreground mt-1">
+              Adds networks from a Cascade export or from irssi, WeeChat or HexChat. A network with the same name as an existing one is updated.
+            </p>
+          </div>
+          <div className="grid grid-cols-2 gap-3">
+            <select value={importFormat} onChange={(e) => setImportFormat(e.target.value)} className="h-9 rounded-md border border-border bg-background px-3 text-sm">
+              {IMPORT_FORMATS.map((f) => <option key={f.value} value={f.value}>{f.label}</option>)}
+            </select>
+            <input type="password" value={importPassphrase} placeholder="Bundle passphrase (if any)" onChange={(e) => setImportPassphrase(e.target.value)} className={inputClass} data-testid="import-passphrase" />
+          </div>
+          <input
+            ref={fileInput}
+            type="file"
+            className="hidden"
+            onChange={(e) => {
+              const file = e.target.files?.[0];
+              e.target.value = '';
+              if (file) void importFile(file);
+            }}
+          />
+          <button className="inline-flex items-center gap-2 rounded-md border border-border px-3 py-2 text-sm hover:bg-accent" onClick={() => fileInput.current?.click()} data-testid="import-config">
+            <ArrowDownToLine size={15} />Choose file…
+          </button>
+
+          {result && (
+            <div className="text-xs space-y-1" data-testid="import-result">
+              {result.created.length > 0 && <p>Added: {result.created.join(', ')}</p>}
+              {result.updated.length > 0 && <p>Updated: {result.updated.join(', ')}</p>}
+              {result.settings > 0 && <p>Applied {result.settings} settings.</p>}
+              {result.created.length + result.updated.length === 0 && <p>No networks were imported.</p>}
+              {result.skipped.map((s) => <p key={s} className="text-destructive">Skipped {s}</p>)}
+              {result.warnings.map((w) => <p key={w} className="text-muted-foreground">{w}</p>)}
+            </div>
+          )}
+        </div>
+      </div>
+    </details>
+  );
+}
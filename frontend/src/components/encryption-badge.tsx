@@ -0,0 +1,126 @@
+import { useCallback, useEffect, useState } from 'react';
+import { Lock, LockOpen } from 'lucide-react';
+import { irc } from '../../wailsjs/go/models';
+import { GetEncryptionState, StartEncryption, StopEncryption, VerifyEncryption } from '../../wailsjs/go/main/App';
+import { EventsOn } from '../../wailsjs/runtime/runtime';
+
+interface EncryptionChangedEvent {
+  networkId: number;
+  target: string;
+}
+
+// EncryptionBadge shows, next to a query or channel name, whether messages
+// there are encrypted. In a query it also starts and ends end-to-end sessions
+// and shows the fingerprints to compare; channels only show a FiSH key.
+export function EncryptionBadge({ networkId, target, isQuery }: { networkId: number; target: string; isQuery: boolean }) {
+  const [state, setState] = useState<irc.EncryptionState | null>(null);
+  const [open, setOpen] = useState(false);
+  const [error, setError] = useState('');
+
+  const refresh = useCallback(() => {
+    void GetEncryptionState(networkId, target)
+      .then(setState)
+      .catch(() => setState(null));
+  }, [networkId, target]);
+
+  useEffect(() => {
+    refresh();
+    return EventsOn('encryption-changed', (data: EncryptionChangedEvent) => {
+      if (data.networkId === networkId && data.target.toLowerCase() === target.toLowerCase()) refresh();
+    });
+  }, [networkId, target, refresh]);
+
+  const run = (action: () => Promise<void>) => {
+    setError('');
+    action().catch((e) => setError(e instanceof Error ? e.message : String(e)));
+  };
+
+  if (!state) return null;
+  if (!isQuery && state.mode !== 'fish') return null;
+
+  if (state.mode === 'fish') {
+    return (
+      <span className="text-muted-foreground" title="Encrypted with a FiSH key" data-testid="encryption-badge">
+        <Lock className="h-3.5 w-3.5" />
+      </span>
+    );
+  }
+
+  const color =
+    state.mode === 'e2e'
+      ? state.verified
+        ? 'text-green-600 dark:text-green-400'
+        : 'text-amber-600 dark:text-amber-400'
+      : state.mode === 'broken'
+        ? 'text-destructive'
+        : 'text-muted-foreground';
+  const title =
+    state.mode === 'e2e'
+      ? state.verified
+        ? 'End-to-end encrypted; key verified'
+        : 'End-to-end encrypted; key not verified'
+      : state.mode === 'broken'
+        ? `The encrypted conversation is broken; nothing is sent to ${target} until you start a new one or end it`
+        : state.mode === 'pending'
+          ? `Waiting for ${target} to accept`
+          : state.mode === 'offered'
+            ? `${target} offers an encrypted conversation`
+            : 'Not encrypted';
+
+  return (
+    <span className="relative" data-testid="encryption-badge">
+      <button type="button" onClick={() => setOpen((o) => !o)} className={`flex items-center ${color} hover:text-foreground`} title={title}>
+        {state.mode === 'e2e' || state.mode === 'broken' ? <Lock className="h-3.5 w-3.5" /> : <LockOpen className="h-3.5 w-3.5" />}
+      </button>
+      {open && (
+        <div className="absolute left-0 top-6 z-50 w-80 rounded-md border border-border bg-background p-3 text-xs shadow-lg">
+          <p className="font-semibold mb-2">{title}</p>
+          {(state.mode === 'e2e' || state.mode === 'broken') && (
+            <p className="mb-2">
+              {target}'s fingerprint:
+              <br />
+              <span className="font-mono">{state.peerFingerprint}</span>
+            </p>
+          )}
+          {state.offerFingerprint && (
+            <p className="mb-2">
+              {state.mode === 'e2e' || state.mode === 'broken' ? `${target} asks to start a new conversation with the fingerprint:` : `${target}'s fingerprint:`}
+              <br />
+              <span className="font-mono">{state.offerFingerprint}</span>
+            </p>
+          )}
+          {state.fingerprint && (
+            <p className="mb-2 text-muted-foreground">
+              Yours:
+              <br />
+              <span className="font-mono">{state.fingerprint}</span>
+            </p>
+          )}
+          {error && <p className="text-destructive mb-2">{error}</p>}
+          <div className="flex gap-2">
+            {(state.mode === '' || (state.mode === 'broken' && !state.offerFingerprint)) && (
+              <button type="button" onClick={() => run(() => StartEncryption(networkId, target))} className="rounded-md bg-primary px-2 py-1 text-primary-foreground hover:bg-primary/90">
+                {state.mode === 'broken' ? 'Start a new one' : 'Start encryption'}
+              </button>
+            )}
+            {state.offerFingerprint && (
+              <button type="button" onClick={() => run(() => StartEncryption(networkId, target))} className="rounded-md bg-primary px-2 py-1 text-primary-foreground hover:bg-primary/90">
+                Accept
+              </button>
+            )}
+            {state.mode === 'e2e' && !state.verified && (
+              <button type="button" onClick={() => run(() => VerifyEncryption(networkId, target))} className="rounded-md bg-primary px-2 py-1 text-primary-foreground hover:bg-primary/90" title="Only after comparing the fingerprint with them over another channel">
+                Mark verified
+              </button>
+            )}
+            {(state.mode === 'e2e' || state.mode === 'broken' || state.mode === 'pending' || state.mode === 'offered') && (
+              <button type="button" onClick={() => run(() => StopEncryption(networkId, target))} className="rounded-md border border-border px-2 py-1 hover:bg-accent">
+                {state.mode === 'offered' ? 'Decline' : 'End'}
+              </button>
+            )}
+          </div>
+        </div>
+      )}
+    </span>
+  );
+}
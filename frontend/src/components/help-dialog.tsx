@@ -8,6 +8,7 @@ const CATEGORY_TITLES: Record<string, string> = {
   server: 'Server commands',
   ctcp: 'CTCP commands',
   plugin: 'Plugin commands',
+  alias: 'Your aliases',
 };
 
 export function HelpDialog() {
@@ -25,7 +26,7 @@ export function HelpDialog() {
       c.description.toLowerCase().includes(q) ||
       (c.aliases || []).some((a) => a.toLowerCase().includes(q))
   );
-  const order = ['client', 'server', 'ctcp', 'plugin'];
+  const order = ['client', 'server', 'ctcp', 'plugin', 'alias'];
   const grouped = order
     .map((cat) => ({ cat, items: filtered.filter((c) => c.category === cat) }))
     .filter((g) => g.items.length);
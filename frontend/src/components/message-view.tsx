@@ -10,7 +10,7 @@ import { useUIStore } from '../stores/ui';
 import { casefold } from '../lib/casefold';
 import { useSettingsStore } from '../stores/settings';
 import { SendCommand } from '../../wailsjs/go/main/App';
-import { CornerUpLeft, Hash } from 'lucide-react';
+import { CornerUpLeft, Hash, Lock } from 'lucide-react';
 import { buildMsgidIndex, resolveParent, quoteSnippet } from '../lib/reply';
 
 interface MessageViewProps {
@@ -642,7 +642,7 @@ export function MessageView({ networkId, selectedChannel }: MessageViewProps) {
           const isStatus = msg.message_type === 'status';
           const isCommand = msg.message_type === 'command';
           const isAction = msg.message_type === 'action';
-          const isSystemMessage = msg.message_type === 'join' || msg.message_type === 'part' || msg.message_type === 'quit' || msg.message_type === 'mode';
+          const isSystemMessage = msg.message_type === 'join' || msg.message_type === 'part' || msg.message_type === 'quit' || msg.message_type === 'mode' || msg.message_type === 'netsplit' || msg.message_type === 'netjoin';
           const isEven = index % 2 === 0;
           const isRegularMessage = !isError && !isWarning && !isStatus && !isCommand && !isSystemMessage;
           const hasMention = isRegularMessage && isMention(msg.message);
@@ -752,6 +752,11 @@ export function MessageView({ networkId, selectedChannel }: MessageViewProps) {
                       bot
                     </span>
                   )}
+                  {msg.encrypted && (
+                    <span className="text-muted-foreground flex-shrink-0" title="Encrypted" data-testid="encrypted-marker">
+                      <Lock className="h-3 w-3" />
+                    </span>
+                  )}
                   {!msg.channel_id && msg.channel_context && networkId !== null && (
                     <button
                       type="button"
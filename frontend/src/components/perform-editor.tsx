@@ -0,0 +1,141 @@
+import { useEffect, useState } from 'react';
+import { ArrowDown, ArrowUp, Plus, Trash2 } from 'lucide-react';
+import { main } from '../../wailsjs/go/models';
+import { GetPerformSecrets, GetPerformSteps, SetPerformSecret, SetPerformSteps } from '../../wailsjs/go/main/App';
+
+const fieldClass = 'w-full px-2 py-1 text-sm border border-border rounded';
+
+type StepKind = 'command' | 'delay' | 'wait';
+
+const NEW_STEP: Record<StepKind, Partial<main.PerformStep>> = {
+  command: { kind: 'command', value: '' },
+  delay: { kind: 'delay', seconds: 2 },
+  wait: { kind: 'wait', value: '', seconds: 30 },
+};
+
+// PerformEditor edits a network's perform-on-connect list: commands run after
+// registration and before channels are joined. It saves on its own, apart
+// from the network form.
+export function PerformEditor({ networkId }: { networkId: number }) {
+  const [steps, setSteps] = useState<main.PerformStep[]>([]);
+  const [secrets, setSecrets] = useState<main.PerformSecret[]>([]);
+  const [secretDrafts, setSecretDrafts] = useState<Record<string, string>>({});
+  const [status, setStatus] = useState('');
+  const [error, setError] = useState('');
+
+  const loadSecrets = () => void GetPerformSecrets(networkId).then((s) => setSecrets(s ?? [])).catch(() => {});
+
+  useEffect(() => {
+    void GetPerformSteps(networkId).then((s) => setSteps(s ?? [])).catch((e) => setError(String(e)));
+    loadSecrets();
+  }, [networkId]);
+
+  const flash = (text: string) => {
+    setStatus(text);
+    window.setTimeout(() => setStatus(''), 1600);
+  };
+
+  const update = (index: number, patch: Partial<main.PerformStep>) =>
+    setSteps((prev) => prev.map((s, i) => (i === index ? main.PerformStep.createFrom({ ...s, ...patch }) : s)));
+
+  const move = (index: number, by: number) =>
+    setSteps((prev) => {
+      const next = [...prev];
+      const [step] = next.splice(index, 1);
+      next.splice(index + by, 0, step);
+      return next;
+    });
+
+  const save = async () => {
+    setError('');
+    try {
+      await SetPerformSteps(networkId, steps);
+      loadSecrets();
+      flash('Saved');
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  const saveSecret = async (name: string) => {
+    setError('');
+    try {
+      await SetPerformSecret(networkId, name, secretDrafts[name] ?? '');
+      setSecretDrafts((prev) => ({ ...prev, [name]: '' }));
+      loadSecrets();
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  return (
+    <div className="mt-4 p-4 border border-border rounded bg-muted/30" data-testid="perform-editor">
+      <h5 className="font-semibold text-sm">Perform on connect</h5>
+      <p className="text-xs text-muted-foreground mt-1 mb-3">
+        Commands run in order once you are registered, before any channel is joined — e.g. <code>/mode $me +x</code>. Write <code>{'${secret:name}'}</code> instead of a password; the value is kept in the credential store.
+      </p>
+
+      {error && <p className="text-xs text-destructive mb-2">{error}</p>}
+
+      <div className="space-y-2">
+        {steps.map((step, i) => (
+          <div key={i} className="flex items-center gap-2">
+            {step.kind === 'command' && (
+              <input value={step.value} placeholder="/msg Q@CServe.quakenet.org AUTH name ${secret:q}" onChange={(e) => update(i, { value: e.target.value })} className={`${fieldClass} font-mono`} />
+            )}
+            {step.kind === 'delay' && (
+              <label className="flex flex-1 items-center gap-2 text-sm">
+                Wait
+                <input type="number" min={1} max={300} value={step.seconds} onChange={(e) => update(i, { seconds: Number(e.target.value) })} className={`${fieldClass} w-20`} />
+                seconds
+              </label>
+            )}
+            {step.kind === 'wait' && (
+              <label className="flex flex-1 items-center gap-2 text-sm">
+                <span className="shrink-0">Wait for a notice matching</span>
+                <input value={step.value} placeholder="*now logged in*" onChange={(e) => update(i, { value: e.target.value })} className={`${fieldClass} font-mono`} />
+                <span className="shrink-0">for up to</span>
+                <input type="number" min={1} max={300} value={step.seconds} onChange={(e) => update(i, { seconds: Number(e.target.value) })} className={`${fieldClass} w-20`} />
+                <span className="shrink-0">s</span>
+              </label>
+            )}
+            <button type="button" title="Move up" disabled={i === 0} onClick={() => move(i, -1)} className="text-muted-foreground hover:text-foreground disabled:opacity-30"><ArrowUp size={14} /></button>
+            <button type="button" title="Move down" disabled={i === steps.length - 1} onClick={() => move(i, 1)} className="text-muted-foreground hover:text-foreground disabled:opacity-30"><ArrowDown size={14} /></button>
+            <button type="button" title="Remove step" onClick={() => setSteps((prev) => prev.filter((_, j) => j !== i))} className="text-muted-foreground hover:text-destructive"><Trash2 size={14} /></button>
+          </div>
+        ))}
+      </div>
+
+      <div className="flex flex-wrap items-center gap-2 mt-3">
+        {(['command', 'delay', 'wait'] as StepKind[]).map((kind) => (
+          <button key={kind} type="button" onClick={() => setSteps((prev) => [...prev, main.PerformStep.createFrom(NEW_STEP[kind])])} className="inline-flex items-center gap-1 rounded-md border border-border px-2 py-1 text-xs hover:bg-accent">
+            <Plus size={12} />{kind === 'command' ? 'Command' : kind === 'delay' ? 'Delay' : 'Wait for notice'}
+          </button>
+        ))}
+        <button type="button" onClick={() => void save()} className="ml-auto rounded-md bg-primary px-3 py-1 text-xs text-primary-foreground hover:bg-primary/90" data-testid="perform-save">Save list</button>
+        {status && <span className="text-xs text-muted-foreground">{status}</span>}
+      </div>
+
+      {secrets.length > 0 && (
+        <div className="mt-4 space-y-2 border-t border-border pt-3">
+          <div className="text-xs font-medium">Secrets</div>
+          {secrets.map((s) => (
+            <div key={s.name} className="flex items-center gap-2">
+              <code className="w-28 shrink-0 truncate text-xs">{s.name}</code>
+              <input
+                type="password"
+                value={secretDrafts[s.name] ?? ''}
+                placeholder={s.isSet ? '•••••••• (unchanged)' : 'Not set'}
+                onChange={(e) => setSecretDrafts((prev) => ({ ...prev, [s.name]: e.target.value }))}
+                className={fieldClass}
+              />
+              <button type="button" onClick={() => void saveSecret(s.name)} className="rounded-md border border-border px-2 py-1 text-xs hover:bg-accent">
+                {secretDrafts[s.name] ? 'Store' : s.isSet ? 'Clear' : 'Store'}
+              </button>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
@@ -0,0 +1,80 @@
+import { useEffect, useState } from 'react';
+import { main } from '../../wailsjs/go/models';
+import { GetProfileMetadata, SetMetadata } from '../../wailsjs/go/main/App';
+
+const fieldClass = 'w-full px-2 py-1 text-sm border border-border rounded';
+
+type ProfileField = 'avatar' | 'displayName' | 'pronouns' | 'homepage' | 'status';
+
+// Form fields and the draft/metadata-2 key each one sets.
+const fields: { field: ProfileField; key: string; label: string; placeholder: string }[] = [
+  { field: 'displayName', key: 'display-name', label: 'Display name', placeholder: 'Shown beside your nick' },
+  { field: 'pronouns', key: 'pronouns', label: 'Pronouns', placeholder: 'e.g. they/them' },
+  { field: 'status', key: 'status', label: 'Status', placeholder: 'A short status' },
+  { field: 'avatar', key: 'avatar', label: 'Avatar URL', placeholder: 'https://…' },
+  { field: 'homepage', key: 'homepage', label: 'Homepage', placeholder: 'https://…' },
+];
+
+// ProfileMetadataEditor edits our own IRCv3 metadata (avatar, display name,
+// pronouns…) on a connected network whose server supports draft/metadata-2.
+// Values go to the server, not the network form, so it saves on its own.
+export function ProfileMetadataEditor({ networkId }: { networkId: number }) {
+  const [profile, setProfile] = useState<main.ProfileMetadata | null>(null);
+  const [draft, setDraft] = useState<Record<ProfileField, string>>({ avatar: '', displayName: '', pronouns: '', homepage: '', status: '' });
+  const [status, setStatus] = useState('');
+  const [error, setError] = useState('');
+
+  const load = () =>
+    void GetProfileMetadata(networkId)
+      .then((p) => {
+        setProfile(p);
+        setDraft({ avatar: p.avatar, displayName: p.displayName, pronouns: p.pronouns, homepage: p.homepage, status: p.status });
+      })
+      .catch((e) => setError(String(e)));
+
+  useEffect(load, [networkId]);
+
+  if (!profile?.supported) return null;
+
+  const save = async () => {
+    setError('');
+    try {
+      for (const { field, key } of fields) {
+        if (draft[field].trim() !== profile[field]) {
+          await SetMetadata(networkId, key, draft[field].trim());
+        }
+      }
+      setStatus('Sent');
+      window.setTimeout(() => {
+        setStatus('');
+        load();
+      }, 1600);
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  return (
+    <div className="mt-4 p-4 border border-border rounded bg-muted/30" data-testid="profile-metadata-editor">
+      <h5 className="font-semibold text-sm">Profile</h5>
+      <p className="text-xs text-muted-foreground mt-1 mb-3">
+        Shared with everyone on this network through IRCv3 metadata. Leave a field empty to remove it.
+      </p>
+
+      {error && <p className="text-xs text-destructive mb-2">{error}</p>}
+
+      <div className="grid grid-cols-[auto_1fr] items-center gap-2">
+        {fields.map(({ field, label, placeholder }) => (
+          <label key={field} className="contents">
+            <span className="text-xs text-muted-foreground">{label}</span>
+            <input value={draft[field]} placeholder={placeholder} onChange={(e) => setDraft({ ...draft, [field]: e.target.value })} className={fieldClass} />
+          </label>
+        ))}
+      </div>
+      <div className="flex items-center gap-2 mt-2">
+        <button type="button" onClick={() => void save()} className="rounded-md bg-primary px-3 py-1 text-xs text-primary-foreground hover:bg-primary/90">Save</button>
+        {status && <span className="text-xs text-muted-foreground">{status}</span>}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,76 @@
+import { useEffect, useState } from 'react';
+import { Server, TriangleAlert } from 'lucide-react';
+import { main } from '../../wailsjs/go/models';
+import { GetRemoteCore, SetRemoteCore } from '../../wailsjs/go/main/App';
+
+const inputClass =
+  'w-full px-3 py-2 text-sm border border-border rounded-lg bg-background focus:outline-none focus:ring-2 focus:ring-primary focus:border-primary font-mono';
+
+export function RemoteCoreSettings() {
+  const [settings, setSettings] = useState<main.RemoteCoreSettings | null>(null);
+  const [url, setUrl] = useState('');
+  const [token, setToken] = useState('');
+  const [saved, setSaved] = useState(false);
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    void GetRemoteCore()
+      .then((s) => { setSettings(s); setUrl(s.url); })
+      .catch((e) => setError(String(e)));
+  }, []);
+
+  const save = async (nextUrl: string, nextToken: string) => {
+    setError('');
+    try {
+      await SetRemoteCore(nextUrl, nextToken);
+      const s = await GetRemoteCore();
+      setSettings(s);
+      setUrl(s.url);
+      setToken('');
+      setSaved(true);
+      window.setTimeout(() => setSaved(false), 1600);
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  if (!settings) return null;
+
+  return (
+    <div className="border border-border rounded-lg p-4 bg-card/50 shadow-[var(--shadow-sm)] space-y-4 mt-4">
+      <div className="flex gap-3">
+        <div className="flex h-9 w-9 shrink-0 items-center justify-center rounded-lg bg-primary/10 text-primary"><Server size={17} /></div>
+        <div>
+          <div className="text-sm font-semibold">Remote core</div>
+          <p className="text-xs text-muted-foreground mt-1">
+            Attach to an always-on Cascade core (<code>cascade-server</code> with <code>CASCADE_CORE_LISTEN</code>) instead of connecting from this computer. The core keeps your networks, scripts and plugins running while this app is closed. Takes effect the next time Cascade starts; use File → Detach from Core to come back.
+          </p>
+        </div>
+      </div>
+
+      {error && <div className="flex gap-2 rounded-lg border border-destructive/30 bg-destructive/5 px-3 py-2 text-sm text-destructive"><TriangleAlert className="mt-0.5 shrink-0" size={15} />{error}</div>}
+
+      <div>
+        <label className="block text-sm font-medium mb-1.5">Core URL</label>
+        <input type="url" value={url} placeholder="https://home.example:7667" onChange={(e) => setUrl(e.target.value)} className={inputClass} data-testid="remote-core-url" />
+      </div>
+      <div>
+        <label className="block text-sm font-medium mb-1.5">Access token</label>
+        <input
+          type="password"
+          value={token}
+          placeholder={settings.hasToken ? 'Stored — leave blank to keep it' : 'Contents of core.token on the server'}
+          onChange={(e) => setToken(e.target.value)}
+          className={inputClass}
+          data-testid="remote-core-token"
+        />
+      </div>
+
+      <div className="flex items-center gap-2">
+        <button className="rounded-md bg-primary px-3 py-2 text-sm text-primary-foreground hover:bg-primary/90" onClick={() => void save(url, token)}>Save</button>
+        {settings.url && <button className="rounded-md border border-border px-3 py-2 text-sm hover:bg-accent" onClick={() => void save('', '')}>Run locally</button>}
+        {saved && <span className="text-xs text-muted-foreground">Saved</span>}
+      </div>
+    </div>
+  );
+}
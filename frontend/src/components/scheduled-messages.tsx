@@ -0,0 +1,102 @@
+import { useCallback, useEffect, useState } from 'react';
+import { Trash2 } from 'lucide-react';
+import { storage } from '../../wailsjs/go/models';
+import { CancelScheduledMessage, GetScheduledMessages, ScheduleMessage } from '../../wailsjs/go/main/App';
+import { EventsOn } from '../../wailsjs/runtime/runtime';
+
+const fieldClass = 'w-full px-2 py-1 text-sm border border-border rounded';
+
+function formatSendAt(ms: number): string {
+  const at = new Date(ms);
+  const sameDay = at.toDateString() === new Date().toDateString();
+  return sameDay
+    ? at.toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })
+    : at.toLocaleString([], { weekday: 'short', month: 'short', day: 'numeric', hour: '2-digit', minute: '2-digit' });
+}
+
+// ScheduledMessages lists a network's messages queued with /later and adds
+// new ones. Failed messages stay listed, with the reason, until removed.
+export function ScheduledMessages({ networkId }: { networkId: number }) {
+  const [messages, setMessages] = useState<storage.ScheduledMessage[]>([]);
+  const [target, setTarget] = useState('');
+  const [text, setText] = useState('');
+  const [when, setWhen] = useState('');
+  const [error, setError] = useState('');
+
+  const load = useCallback(() => {
+    void GetScheduledMessages(networkId)
+      .then((m) => setMessages(m ?? []))
+      .catch((e) => setError(String(e)));
+  }, [networkId]);
+
+  useEffect(() => {
+    load();
+    return EventsOn('scheduled-messages-changed', (data: { networkId: number }) => {
+      if (data.networkId === networkId) load();
+    });
+  }, [networkId, load]);
+
+  const run = async (action: () => Promise<unknown>) => {
+    setError('');
+    try {
+      await action();
+      load();
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  const schedule = () =>
+    run(async () => {
+      await ScheduleMessage(networkId, target, text, new Date(when).getTime());
+      setText('');
+    });
+
+  return (
+    <div className="mt-4 p-4 border border-border rounded bg-muted/30" data-testid="scheduled-messages">
+      <h5 className="font-semibold text-sm">Scheduled messages</h5>
+      <p className="text-xs text-muted-foreground mt-1 mb-3">
+        Sent at the given time, or as soon as the network is connected within an hour of it. You can also use <code>/later 2h #channel text</code>.
+      </p>
+
+      {error && <p className="text-xs text-destructive mb-2">{error}</p>}
+
+      {messages.length > 0 && (
+        <ul className="space-y-1 mb-3">
+          {messages.map((m) => (
+            <li key={m.id} className="flex items-start gap-2 text-xs">
+              <span className="text-muted-foreground whitespace-nowrap">{formatSendAt(m.send_at)}</span>
+              <span className="font-medium">{m.target}</span>
+              <span className="flex-1 break-words">
+                {m.message}
+                {m.status === 'failed' && <span className="text-destructive"> · not sent: {m.last_error}</span>}
+              </span>
+              <button
+                type="button"
+                onClick={() => void run(() => CancelScheduledMessage(networkId, m.id))}
+                className="text-muted-foreground hover:text-destructive"
+                title={m.status === 'failed' ? 'Remove' : 'Cancel'}
+              >
+                <Trash2 className="h-3.5 w-3.5" />
+              </button>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      <div className="flex items-center gap-2">
+        <input value={target} placeholder="#channel or nick" onChange={(e) => setTarget(e.target.value)} className="w-40 shrink-0 px-2 py-1 text-sm border border-border rounded" />
+        <input value={text} placeholder="Message" onChange={(e) => setText(e.target.value)} className={fieldClass} />
+        <input type="datetime-local" value={when} onChange={(e) => setWhen(e.target.value)} className="w-52 shrink-0 px-2 py-1 text-sm border border-border rounded" />
+        <button
+          type="button"
+          disabled={!target.trim() || !text.trim() || !when}
+          onClick={() => void schedule()}
+          className="rounded-md bg-primary px-3 py-1 text-xs text-primary-foreground hover:bg-primary/90 disabled:opacity-50"
+        >
+          Schedule
+        </button>
+      </div>
+    </div>
+  );
+}
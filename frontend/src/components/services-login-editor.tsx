@@ -0,0 +1,66 @@
+import { useEffect, useState } from 'react';
+import { main } from '../../wailsjs/go/models';
+import { GetServicesLogin, SetServicesLogin } from '../../wailsjs/go/main/App';
+
+const fieldClass = 'w-full px-2 py-1 text-sm border border-border rounded';
+
+// ServicesLoginEditor edits a network's NickServ login, used when SASL did not
+// log in. Like the perform list it saves on its own, apart from the network form.
+export function ServicesLoginEditor({ networkId }: { networkId: number }) {
+  const [login, setLogin] = useState<main.ServicesLogin | null>(null);
+  const [account, setAccount] = useState('');
+  const [password, setPassword] = useState('');
+  const [status, setStatus] = useState('');
+  const [error, setError] = useState('');
+
+  const load = () =>
+    void GetServicesLogin(networkId)
+      .then((l) => {
+        setLogin(l);
+        setAccount(l.account);
+      })
+      .catch((e) => setError(String(e)));
+
+  useEffect(load, [networkId]);
+
+  const save = async (clear: boolean) => {
+    setError('');
+    try {
+      await SetServicesLogin(networkId, clear ? '' : account, clear ? '' : password);
+      setPassword('');
+      load();
+      setStatus(clear ? 'Cleared' : 'Saved');
+      window.setTimeout(() => setStatus(''), 1600);
+    } catch (e) {
+      setError(e instanceof Error ? e.message : String(e));
+    }
+  };
+
+  return (
+    <div className="mt-4 p-4 border border-border rounded bg-muted/30" data-testid="services-login-editor">
+      <h5 className="font-semibold text-sm">NickServ login</h5>
+      <p className="text-xs text-muted-foreground mt-1 mb-3">
+        For networks where SASL isn't used: Cascade identifies before joining channels, takes your nick back when it's held, and asks ChanServ to let you into invite-only or keyed channels.
+        {login?.package && <> Services syntax: <code>{login.package}</code>.</>}
+      </p>
+
+      {error && <p className="text-xs text-destructive mb-2">{error}</p>}
+
+      <div className="flex items-center gap-2">
+        <input value={account} placeholder="Account (defaults to your nick)" onChange={(e) => setAccount(e.target.value)} className={fieldClass} />
+        <input
+          type="password"
+          value={password}
+          placeholder={login?.passwordSet ? '•••••••• (unchanged)' : 'Password'}
+          onChange={(e) => setPassword(e.target.value)}
+          className={fieldClass}
+        />
+        <button type="button" disabled={!password} onClick={() => void save(false)} className="rounded-md bg-primary px-3 py-1 text-xs text-primary-foreground hover:bg-primary/90 disabled:opacity-50">Save</button>
+        {login?.passwordSet && (
+          <button type="button" onClick={() => void save(true)} className="rounded-md border border-border px-2 py-1 text-xs hover:bg-accent">Clear</button>
+        )}
+        {status && <span className="text-xs text-muted-foreground">{status}</span>}
+      </div>
+    </div>
+  );
+}
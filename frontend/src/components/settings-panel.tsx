@@ -17,6 +17,16 @@ import { useSettingsStore, type PrefixDisplayMode, type UpdateChannel } from '..
 import { usePreferencesStore } from '../stores/preferences';
 import { serializeNetworkForm } from '../lib/settings-network-form';
 import { FileTransferSettings } from './file-transfer-settings';
+import { RemoteCoreSettings } from './remote-core-settings';
+import { BackupSettings } from './backup-settings';
+import { BouncerSettings } from './bouncer-settings';
+import { ConfigTransfer } from './config-transfer';
+import { AliasSettings } from './alias-settings';
+import { PerformEditor } from './perform-editor';
+import { ScheduledMessages } from './scheduled-messages';
+import { ServicesLoginEditor } from './services-login-editor';
+import { AccountRegistrationForm } from './account-registration-form';
+import { ProfileMetadataEditor } from './profile-metadata-editor';
 
 export type SettingsSection = 'networks' | 'plugins' | 'scripts' | 'display' | 'notifications' | 'privacy' | 'advanced' | 'about';
 
@@ -516,6 +526,7 @@ export function SettingsPanel({ section, onSectionChange }: SettingsPanelProps)
       sasl_external_cert: network.sasl_external_cert || '',
       auto_connect: network.auto_connect || false,
       identify_as_bot: network.identify_as_bot || false,
+      flood_profile: network.flood_profile || '',
     });
     setFormData(built);
     formSnapshotRef.current = serializeNetworkForm(built, (servers || []) as any);
@@ -617,6 +628,7 @@ export function SettingsPanel({ section, onSectionChange }: SettingsPanelProps)
         sasl_external_cert: formData.sasl_external_cert || '',
         auto_connect: (formData as any).auto_connect || false,
         identify_as_bot: (formData as any).identify_as_bot || false,
+        flood_profile: formData.flood_profile || '',
       });
       
       await SaveNetwork(config);
@@ -821,6 +833,7 @@ export function SettingsPanel({ section, onSectionChange }: SettingsPanelProps)
           </div>
         )}
       </div>
+      <ConfigTransfer onImported={() => void loadNetworks()} />
     </>
   );
 
@@ -1151,6 +1164,24 @@ export function SettingsPanel({ section, onSectionChange }: SettingsPanelProps)
                     </p>
                   </div>
 
+                  {/* Flood Profile Section */}
+                  <div className="mt-4">
+                    <label className="block text-sm font-medium mb-1">Send pacing</label>
+                    <select
+                      value={formData.flood_profile || ''}
+                      onChange={(e) => setFormData(main.NetworkConfig.createFrom({ ...formData, flood_profile: e.target.value }))}
+                      className="w-full px-3 py-2 text-sm border border-border rounded-lg bg-background"
+                      data-testid="network-flood-profile"
+                    >
+                      <option value="">Standard — 4 lines per 2 seconds</option>
+                      <option value="cautious">Cautious — for servers that disconnect with "Excess Flood"</option>
+                      <option value="relaxed">Relaxed — no pacing, for your own server without fakelag</option>
+                    </select>
+                    <p className="text-xs text-muted-foreground mt-1">
+                      Typed lines always go before pastes, and pastes before automatic requests and scripts. Type <code>/queue</code> to see what is waiting.
+                    </p>
+                  </div>
+
                   {/* SASL Configuration Section */}
                   <div className="mt-4 p-4 border border-border rounded bg-muted/30">
                     <div className="flex items-center justify-between mb-3">
@@ -1235,6 +1266,12 @@ export function SettingsPanel({ section, onSectionChange }: SettingsPanelProps)
                     )}
                   </div>
 
+                  {editingNetwork && <ServicesLoginEditor networkId={editingNetwork.id} />}
+                  {editingNetwork && <AccountRegistrationForm networkId={editingNetwork.id} />}
+                  {editingNetwork && <ProfileMetadataEditor networkId={editingNetwork.id} />}
+                  {editingNetwork && <PerformEditor networkId={editingNetwork.id} />}
+                  {editingNetwork && <ScheduledMessages networkId={editingNetwork.id} />}
+
                 </form>
 
       <div className="flex items-center justify-between gap-3 mt-6 pt-4 border-t border-border">
@@ -1388,7 +1425,12 @@ export function SettingsPanel({ section, onSectionChange }: SettingsPanelProps)
           </div>
         );
       case 'scripts':
-        return <ScriptsPanel />;
+        return (
+          <>
+            <ScriptsPanel />
+            <AliasSettings networks={networks} />
+          </>
+        );
       case 'privacy':
         return <FileTransferSettings />;
       case 'display':
@@ -1922,6 +1964,9 @@ export function SettingsPanel({ section, onSectionChange }: SettingsPanelProps)
                 </p>
               )}
             </div>
+            <RemoteCoreSettings />
+            <BouncerSettings />
+            <BackupSettings />
           </div>
         );
       case 'about':
@@ -3,6 +3,7 @@ import { SendCommand } from '../../wailsjs/go/main/App';
 import { EventsOn } from '../../wailsjs/runtime/runtime';
 import { useNetworkStore } from '../stores/network';
 import { casefold } from '../lib/casefold';
+import { useAvatar } from '../lib/avatar';
 import { Modal } from './ui/modal';
 
 interface WhoisInfo {
@@ -39,6 +40,7 @@ export function UserInfo({ networkId, nickname, onClose }: UserInfoProps) {
       ? s.userMeta[networkId]?.[casefold(s.caseMapping?.[networkId] ?? '', nickname)]
       : undefined
   );
+  const avatar = useAvatar(meta?.avatar);
 
   useEffect(() => {
     if (networkId === null) return;
@@ -115,7 +117,9 @@ export function UserInfo({ networkId, nickname, onClose }: UserInfoProps) {
       <div data-testid="user-info-panel" className="min-w-0 space-y-4 text-sm">
         <div>
           <div className="font-semibold text-lg mb-2 flex items-center gap-2">
+            {avatar && <img src={avatar} alt="" className="h-8 w-8 rounded-full" />}
             {whoisInfo.nickname}
+            {meta?.pronouns && <span className="text-xs font-normal text-muted-foreground">({meta.pronouns})</span>}
             {whoisInfo.is_bot && (
               <span
                 className="text-[10px] uppercase font-semibold tracking-wide px-1.5 py-0.5 rounded bg-primary text-primary-foreground"
@@ -137,6 +141,13 @@ export function UserInfo({ networkId, nickname, onClose }: UserInfoProps) {
               </span>
             )}
           </div>
+          {meta?.display_name && <div className="break-words">{meta.display_name}</div>}
+          {meta?.status && <div className="text-muted-foreground italic break-words">{meta.status}</div>}
+          {meta?.homepage && /^https?:\/\//i.test(meta.homepage) && (
+            <div className="text-muted-foreground break-all">
+              <a href={meta.homepage} className="text-primary hover:underline">{meta.homepage}</a>
+            </div>
+          )}
           {(whoisInfo.account_name || meta?.account) && (
             <div className="text-muted-foreground break-words">
               Account: <span className="text-foreground">{whoisInfo.account_name || meta?.account}</span>
@@ -0,0 +1,34 @@
+import { useEffect, useState } from 'react';
+import { GetAvatar } from '../../wailsjs/go/main/App';
+
+// Avatars come from draft/metadata-2 "avatar" URLs. The webview never fetches
+// them itself: the backend downloads through the link-preview SSRF guard,
+// resizes and caches on disk, and hands back a data URL. One lookup per URL
+// per session; a failed fetch resolves to "" and isn't retried.
+const avatarCache = new Map<string, Promise<string>>();
+
+function loadAvatar(url: string): Promise<string> {
+  let pending = avatarCache.get(url);
+  if (!pending) {
+    pending = GetAvatar(url).catch(() => '');
+    avatarCache.set(url, pending);
+  }
+  return pending;
+}
+
+// useAvatar returns the data URL for an avatar URL, or '' until (or unless) it loads.
+export function useAvatar(url: string | undefined): string {
+  const [dataUrl, setDataUrl] = useState('');
+  useEffect(() => {
+    setDataUrl('');
+    if (!url) return;
+    let live = true;
+    void loadAvatar(url).then((d) => {
+      if (live) setDataUrl(d);
+    });
+    return () => {
+      live = false;
+    };
+  }, [url]);
+  return dataUrl;
+}
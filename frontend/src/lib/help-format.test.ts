@@ -17,10 +17,12 @@ describe('help-format', () => {
       mk({ name: 'JOIN', category: 'server' }),
       mk({ name: 'QUERY', category: 'client' }),
       mk({ name: 'WEATHER', category: 'plugin', source: 'weather-plugin' }),
+      mk({ name: 'SLAP', category: 'alias', source: 'alias' }),
     ]);
     const text = lines.join('\n');
     expect(text).toMatch(/Client commands/i);
     expect(text).toMatch(/Server commands/i);
     expect(text).toMatch(/weather-plugin/i);
+    expect(text).toMatch(/Your aliases[\s\S]*\/slap/);
   });
 });
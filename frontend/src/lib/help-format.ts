@@ -30,5 +30,6 @@ export function formatHelpList(commands: main.CommandInfo[]): string[] {
     bySource.set(c.source, arr);
   }
   for (const [source, cmds] of bySource) section(`Plugin: ${source}`, cmds);
+  section('Your aliases', commands.filter((c) => c.category === 'alias'));
   return lines;
 }
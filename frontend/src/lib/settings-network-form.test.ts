@@ -19,6 +19,7 @@ describe('serializeNetworkForm', () => {
       sasl_external_cert: '',
       auto_connect: false,
       identify_as_bot: false,
+      flood_profile: '',
       servers: [{ address: 'irc.libera.chat', port: 6697, tls: true }],
     });
   });
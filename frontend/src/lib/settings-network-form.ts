@@ -25,6 +25,7 @@ export function serializeNetworkForm(
     sasl_external_cert: form.sasl_external_cert ?? '',
     auto_connect: form.auto_connect ?? false,
     identify_as_bot: form.identify_as_bot ?? false,
+    flood_profile: form.flood_profile ?? '',
     servers: (servers ?? []).map((server) => ({
       address: server.address ?? '',
       port: server.port ?? 6667,
@@ -116,6 +116,11 @@ describe('network store: user meta', () => {
     account: '',
     host: '',
     realname: '',
+    avatar: '',
+    display_name: '',
+    pronouns: '',
+    homepage: '',
+    status: '',
     ...over,
   });
 
@@ -210,6 +215,11 @@ describe('network store: CASEMAPPING-aware nick folding', () => {
     account: '',
     host: '',
     realname: '',
+    avatar: '',
+    display_name: '',
+    pronouns: '',
+    homepage: '',
+    status: '',
     ...over,
   });
 
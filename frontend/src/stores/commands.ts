@@ -29,6 +29,9 @@ export async function initCommands(): Promise<void> {
     EventsOn('plugin-lifecycle', () => {
       useCommandsStore.getState().loadCommands();
     });
+    EventsOn('aliases:changed', () => {
+      useCommandsStore.getState().loadCommands();
+    });
   }
 }
 
@@ -5,6 +5,7 @@ const sendCommand = vi.fn().mockResolvedValue(undefined);
 vi.mock('../../wailsjs/go/main/App', () => ({
   PrintLocalLines: (...a: unknown[]) => printLocalLines(...a),
   SendCommand: (...a: unknown[]) => sendCommand(...a),
+  SendCommandInBuffer: (n: unknown, _buffer: unknown, c: unknown) => sendCommand(n, c),
   // other App imports used by the store are unused in this test path:
   SendMessage: vi.fn(), SetPaneFocus: vi.fn(), RequestChatHistoryLatest: vi.fn(),
   GetMessages: vi.fn().mockResolvedValue([]),
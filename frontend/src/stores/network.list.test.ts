@@ -3,6 +3,7 @@ import { describe, it, expect, vi, beforeEach } from 'vitest';
 const sendCommand = vi.fn().mockResolvedValue(undefined);
 vi.mock('../../wailsjs/go/main/App', () => ({
   SendCommand: (...a: unknown[]) => sendCommand(...a),
+  SendCommandInBuffer: (n: unknown, _buffer: unknown, c: unknown) => sendCommand(n, c),
   // other App imports used by the store are unused in this test path:
   PrintLocalLines: vi.fn(), SendMessage: vi.fn(), SetPaneFocus: vi.fn(), RequestChatHistoryLatest: vi.fn(),
 }));
@@ -33,6 +33,7 @@ import {
   UnpinMessage,
   SendMessage,
   SendCommand,
+  SendCommandInBuffer,
   GetNetworkBots,
   GetMonitorList,
   GetMonitorPresence,
@@ -60,13 +61,45 @@ import { activationFor, type ActivityGroup } from '../lib/activity-inbox';
 
 // UserMetaT mirrors the Go irc.UserMeta JSON shape: the live, session-local
 // roster attributes Cascade tracks per nick via away-notify / account-notify /
-// extended-join / chghost / account-tag.
+// extended-join / chghost / account-tag / draft/metadata-2.
 export interface UserMetaT {
   away: boolean;
   away_message: string;
   account: string;
   host: string;
   realname: string;
+  avatar: string;
+  display_name: string;
+  pronouns: string;
+  homepage: string;
+  status: string;
+}
+
+const userMetaKeys: (keyof UserMetaT)[] = [
+  'away', 'away_message', 'account', 'host', 'realname',
+  'avatar', 'display_name', 'pronouns', 'homepage', 'status',
+];
+
+// sameUserMeta reports whether two roster snapshots carry the same values.
+function sameUserMeta(a: UserMetaT, b: UserMetaT): boolean {
+  return userMetaKeys.every((k) => a[k] === b[k]);
+}
+
+// toUserMetaT normalizes a backend payload, defaulting missing fields.
+export function toUserMetaT(m: any): UserMetaT {
+  const str = (v: unknown) => (typeof v === 'string' ? v : '');
+  return {
+    away: !!m?.away,
+    away_message: str(m?.away_message),
+    account: str(m?.account),
+    host: str(m?.host),
+    realname: str(m?.realname),
+    avatar: str(m?.avatar),
+    display_name: str(m?.display_name),
+    pronouns: str(m?.pronouns),
+    homepage: str(m?.homepage),
+    status: str(m?.status),
+  };
 }
 
 export interface UserMetaUpdateT extends UserMetaT {
@@ -1124,7 +1157,13 @@ export const useNetworkStore = create<NetworkState>((set, get) => ({
       }
 
       try {
-        await SendCommand(selectedNetwork, commandToSend);
+        const buffer =
+          selectedChannel === 'status'
+            ? 'status'
+            : selectedChannel.startsWith('pm:')
+              ? selectedChannel.substring(3)
+              : selectedChannel;
+        await SendCommandInBuffer(selectedNetwork, buffer, commandToSend);
         await loadMessages();
       } catch (error) {
         console.error('Failed to send command:', error);
@@ -1468,13 +1507,7 @@ export const useNetworkStore = create<NetworkState>((set, get) => ({
       const meta = await GetNetworkUserMeta(id);
       const map: Record<string, UserMetaT> = {};
       for (const [nick, m] of Object.entries(meta || {})) {
-        map[casefold(get().caseMapping[id] ?? '', nick)] = {
-          away: !!m?.away,
-          away_message: m?.away_message ?? '',
-          account: m?.account ?? '',
-          host: m?.host ?? '',
-          realname: m?.realname ?? '',
-        };
+        map[casefold(get().caseMapping[id] ?? '', nick)] = toUserMetaT(m);
       }
       set((state) => ({ userMeta: { ...state.userMeta, [id]: map } }));
     } catch (error) {
@@ -1487,14 +1520,7 @@ export const useNetworkStore = create<NetworkState>((set, get) => ({
       const key = casefold(get().caseMapping[networkId] ?? '', nick);
       const existing = state.userMeta[networkId]?.[key];
       // No-op when nothing changed, to avoid needless re-renders.
-      if (
-        existing &&
-        existing.away === meta.away &&
-        existing.away_message === meta.away_message &&
-        existing.account === meta.account &&
-        existing.host === meta.host &&
-        existing.realname === meta.realname
-      ) {
+      if (existing && sameUserMeta(existing, meta)) {
         return state;
       }
       const networkMeta = { ...(state.userMeta[networkId] ?? {}), [key]: meta };
@@ -1511,14 +1537,7 @@ export const useNetworkStore = create<NetworkState>((set, get) => ({
         if (!Number.isFinite(networkId) || !nickname) continue;
         const key = casefold(get().caseMapping[networkId] ?? '', nickname);
         const existing = nextUserMeta[networkId]?.[key];
-        if (
-          existing &&
-          existing.away === meta.away &&
-          existing.away_message === meta.away_message &&
-          existing.account === meta.account &&
-          existing.host === meta.host &&
-          existing.realname === meta.realname
-        ) {
+        if (existing && sameUserMeta(existing, meta)) {
           continue;
         }
         if (nextUserMeta === state.userMeta) {
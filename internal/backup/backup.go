@@ -0,0 +1,519 @@
+// Package backup writes and restores passphrase-encrypted archives of
+// Cascade's data directory: a consistent snapshot of the history database,
+// the credential store's secrets, and the asset directories (network icons,
+// scripts, plugins). Logs, downloads and per-machine files such as the
+// credential key and the core access token are left out.
+//
+// A restore is staged next to the live data and applied the next time the
+// app starts, before the database is opened; the replaced files are kept
+// under backups/ rather than deleted. The staged secrets are re-encrypted
+// under the local credential key, so they never wait on disk in the clear.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+const (
+	// FormatVersion is the archive layout version written into the manifest.
+	FormatVersion = 1
+	// Extension is the file extension of backup archives.
+	Extension = ".cascadebak"
+	// DatabaseFile is the history database's name in the data directory.
+	DatabaseFile = "cascade-chat.db"
+	// PendingDir holds a staged restore until the next start applies it.
+	PendingDir = "restore-pending"
+	// Dir is the default backup directory under the data directory. Files a
+	// restore replaces are moved here too.
+	Dir = "backups"
+
+	manifestEntry = "manifest.json"
+	secretsEntry  = "secrets.json"
+	stagedSecrets = "secrets.enc"
+	filePrefix    = "cascade-"
+)
+
+// AssetDirs are the data-directory subdirectories a backup carries verbatim.
+var AssetDirs = []string{"network-icons", "scripts", "plugins"}
+
+// ErrNewerSchema is returned when a backup's database was written by a newer
+// Cascade than this one.
+var ErrNewerSchema = errors.New("backup was made by a newer version of Cascade; update before restoring it")
+
+// SecretFile keeps a staged restore's secrets between Stage and
+// ApplyPending. Cascade passes a file encrypted under its credential store's
+// key.
+type SecretFile interface {
+	Entries() (map[string]string, error)
+	ReplaceEntries(entries map[string]string) error
+}
+
+// SecretOpener opens the SecretFile at path.
+type SecretOpener func(path string) (SecretFile, error)
+
+// Manifest describes an archive. It is the first entry of the tar stream.
+type Manifest struct {
+	Format        int       `json:"format"`
+	CreatedAt     time.Time `json:"created_at"`
+	AppVersion    string    `json:"app_version"`
+	SchemaVersion int       `json:"schema_version"`
+}
+
+// Source is what a backup is made from.
+type Source struct {
+	DataDir    string
+	Storage    *storage.Storage
+	Secrets    map[string]string // the credential store's entries, stored in the clear inside the encrypted archive
+	AppVersion string
+}
+
+// FileName is the name a backup taken at t gets: names sort by time.
+func FileName(t time.Time) string {
+	return filePrefix + t.UTC().Format("20060102-150405") + Extension
+}
+
+// CreateFile writes a backup to path. The file appears only once it is
+// complete, with 0600 permissions.
+func CreateFile(path string, src Source, passphrase string) (Manifest, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return Manifest{}, fmt.Errorf("create backup directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".backup-*.tmp")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("create backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return Manifest{}, err
+	}
+	manifest, err := Create(tmp, src, passphrase)
+	if err != nil {
+		tmp.Close()
+		return Manifest{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("write backup file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Manifest{}, fmt.Errorf("write backup file: %w", err)
+	}
+	return manifest, nil
+}
+
+// Create writes an encrypted archive of src to w. The database is copied
+// with a snapshot, so the app keeps running while a backup is taken.
+func Create(w io.Writer, src Source, passphrase string) (Manifest, error) {
+	if passphrase == "" {
+		return Manifest{}, errors.New("a backup passphrase is required")
+	}
+	snapshotDir, err := os.MkdirTemp(src.DataDir, ".backup-")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("create snapshot directory: %w", err)
+	}
+	defer os.RemoveAll(snapshotDir)
+	snapshot := filepath.Join(snapshotDir, DatabaseFile)
+	if err := src.Storage.Snapshot(snapshot); err != nil {
+		return Manifest{}, err
+	}
+
+	manifest := Manifest{
+		Format:        FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		AppVersion:    src.AppVersion,
+		SchemaVersion: storage.SchemaVersion,
+	}
+
+	enc, err := newEncryptWriter(w, passphrase)
+	if err != nil {
+		return Manifest{}, err
+	}
+	gz := gzip.NewWriter(enc)
+	tw := tar.NewWriter(gz)
+
+	if err := writeJSON(tw, manifestEntry, manifest); err != nil {
+		return Manifest{}, err
+	}
+	secrets := src.Secrets
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+	if err := writeJSON(tw, secretsEntry, secrets); err != nil {
+		return Manifest{}, err
+	}
+	if err := writeFile(tw, DatabaseFile, snapshot); err != nil {
+		return Manifest{}, err
+	}
+	for _, dir := range AssetDirs {
+		if err := writeDir(tw, dir, filepath.Join(src.DataDir, dir)); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return Manifest{}, err
+	}
+	if err := gz.Close(); err != nil {
+		return Manifest{}, err
+	}
+	if err := enc.Close(); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, nil
+}
+
+func writeJSON(tw *tar.Writer, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: time.Now()}); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+func writeFile(tw *tar.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: name, Mode: int64(info.Mode().Perm()), Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(tw, f, info.Size()); err != nil {
+		return fmt.Errorf("back up %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeDir stores root under name. A missing directory is stored empty, so a
+// restore also clears what the backup did not have. Only regular files are
+// kept; symlinks and other special files are skipped.
+func writeDir(tw *tar.Writer, name, root string) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name + "/", Typeflag: tar.TypeDir, Mode: 0o700, ModTime: time.Now()}); err != nil {
+		return err
+	}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		entry := name + "/" + filepath.ToSlash(rel)
+		switch {
+		case d.IsDir():
+			return tw.WriteHeader(&tar.Header{Name: entry + "/", Typeflag: tar.TypeDir, Mode: 0o700, ModTime: time.Now()})
+		case d.Type().IsRegular():
+			return writeFile(tw, entry, p)
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("back up %s: %w", name, err)
+	}
+	return nil
+}
+
+// Stage decrypts a backup into the data directory and checks it, ready for
+// ApplyPending at the next start. The backup's secrets go into the SecretFile
+// open returns rather than onto disk as they are. A backup staged earlier is
+// replaced. Nothing live is touched, so a failed or abandoned restore costs
+// nothing.
+func Stage(r io.Reader, passphrase, dataDir string, open SecretOpener) (Manifest, error) {
+	staging, err := os.MkdirTemp(dataDir, ".restore-")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("create restore directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	manifest, secrets, err := extract(r, passphrase, staging)
+	if err != nil {
+		return Manifest{}, err
+	}
+	if manifest.Format == 0 {
+		return Manifest{}, fmt.Errorf("backup has no manifest")
+	}
+	if manifest.Format > FormatVersion {
+		return Manifest{}, ErrNewerSchema
+	}
+	version, err := storage.CheckSnapshot(filepath.Join(staging, DatabaseFile))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("backup database: %w", err)
+	}
+	if version > storage.SchemaVersion {
+		return Manifest{}, ErrNewerSchema
+	}
+	if version < 1 {
+		return Manifest{}, fmt.Errorf("backup database has no schema version")
+	}
+	file, err := open(filepath.Join(staging, stagedSecrets))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("stage restored secrets: %w", err)
+	}
+	if err := file.ReplaceEntries(secrets); err != nil {
+		return Manifest{}, fmt.Errorf("stage restored secrets: %w", err)
+	}
+
+	pending := filepath.Join(dataDir, PendingDir)
+	if err := os.RemoveAll(pending); err != nil {
+		return Manifest{}, err
+	}
+	if err := os.Rename(staging, pending); err != nil {
+		return Manifest{}, fmt.Errorf("stage restore: %w", err)
+	}
+	return manifest, nil
+}
+
+// extract unpacks an archive into dest, accepting only the entries a backup
+// writes. The secrets are returned instead of written out.
+func extract(r io.Reader, passphrase, dest string) (Manifest, map[string]string, error) {
+	dec, err := newDecryptReader(r, passphrase)
+	if err != nil {
+		return Manifest{}, nil, err
+	}
+	gz, err := gzip.NewReader(dec)
+	if err != nil {
+		return Manifest{}, nil, archiveError(err)
+	}
+	tr := tar.NewReader(gz)
+	var manifest Manifest
+	secrets := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Manifest{}, nil, archiveError(err)
+		}
+		if !allowedEntry(header.Name) {
+			return Manifest{}, nil, fmt.Errorf("backup has an unexpected entry %q", header.Name)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(header.Name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o700); err != nil {
+				return Manifest{}, nil, err
+			}
+		case tar.TypeReg:
+			if header.Name == manifestEntry {
+				if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+					return Manifest{}, nil, fmt.Errorf("read backup manifest: %w", err)
+				}
+				continue
+			}
+			if header.Name == secretsEntry {
+				if err := json.NewDecoder(tr).Decode(&secrets); err != nil {
+					return Manifest{}, nil, fmt.Errorf("read backup secrets: %w", err)
+				}
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+				return Manifest{}, nil, err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, fs.FileMode(header.Mode).Perm()|0o600)
+			if err != nil {
+				return Manifest{}, nil, err
+			}
+			_, err = io.Copy(f, tr)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return Manifest{}, nil, archiveError(err)
+			}
+		default:
+			return Manifest{}, nil, fmt.Errorf("backup has an unexpected entry %q", header.Name)
+		}
+	}
+	// Read to the end so a missing final chunk is reported as truncation.
+	if _, err := io.Copy(io.Discard, gz); err != nil {
+		return Manifest{}, nil, archiveError(err)
+	}
+	return manifest, secrets, nil
+}
+
+// archiveError keeps the passphrase and truncation errors recognizable
+// through the gzip and tar readers.
+func archiveError(err error) error {
+	if errors.Is(err, ErrBadPassphrase) {
+		return ErrBadPassphrase
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("backup is truncated")
+	}
+	return fmt.Errorf("read backup: %w", err)
+}
+
+func allowedEntry(name string) bool {
+	clean := path.Clean(name)
+	if clean != strings.TrimSuffix(name, "/") || path.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return false
+	}
+	switch clean {
+	case manifestEntry, secretsEntry, DatabaseFile:
+		return true
+	}
+	for _, dir := range AssetDirs {
+		if clean == dir || strings.HasPrefix(clean, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPending reports whether a restore is staged.
+func HasPending(dataDir string) bool {
+	_, err := os.Stat(filepath.Join(dataDir, PendingDir))
+	return err == nil
+}
+
+// ApplyPending moves a staged restore into place and returns the secrets it
+// carried, read through open, for the caller to write into its credential
+// store before calling FinishPending. It must run before the database is
+// opened. Each step only moves what is still staged, so a crash part way
+// through is completed on the next start. applied is false when no restore is
+// staged.
+func ApplyPending(dataDir string, open SecretOpener) (secrets map[string]string, applied bool, err error) {
+	pending := filepath.Join(dataDir, PendingDir)
+	if _, err := os.Stat(pending); errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	aside := filepath.Join(dataDir, Dir, "pre-restore-"+time.Now().UTC().Format("20060102-150405"))
+
+	if exists(filepath.Join(pending, DatabaseFile)) {
+		for _, name := range []string{DatabaseFile, DatabaseFile + "-wal", DatabaseFile + "-shm"} {
+			if err := moveAside(dataDir, aside, name); err != nil {
+				return nil, false, err
+			}
+		}
+		if err := os.Rename(filepath.Join(pending, DatabaseFile), filepath.Join(dataDir, DatabaseFile)); err != nil {
+			return nil, false, fmt.Errorf("restore database: %w", err)
+		}
+	}
+	for _, dir := range AssetDirs {
+		if !exists(filepath.Join(pending, dir)) {
+			continue
+		}
+		if err := moveAside(dataDir, aside, dir); err != nil {
+			return nil, false, err
+		}
+		if err := os.Rename(filepath.Join(pending, dir), filepath.Join(dataDir, dir)); err != nil {
+			return nil, false, fmt.Errorf("restore %s: %w", dir, err)
+		}
+	}
+
+	file, err := open(filepath.Join(pending, stagedSecrets))
+	if err != nil {
+		return nil, false, fmt.Errorf("read restored secrets: %w", err)
+	}
+	if secrets, err = file.Entries(); err != nil {
+		return nil, false, fmt.Errorf("read restored secrets: %w", err)
+	}
+	return secrets, true, nil
+}
+
+// FinishPending removes the staged restore once its secrets are stored.
+func FinishPending(dataDir string) error {
+	return os.RemoveAll(filepath.Join(dataDir, PendingDir))
+}
+
+func moveAside(dataDir, aside, name string) error {
+	src := filepath.Join(dataDir, name)
+	if !exists(src) {
+		return nil
+	}
+	if err := os.MkdirAll(aside, 0o700); err != nil {
+		return err
+	}
+	if err := os.Rename(src, filepath.Join(aside, name)); err != nil {
+		return fmt.Errorf("keep current %s: %w", name, err)
+	}
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// Rotate deletes the oldest backups in dir so that at most keep remain. Only
+// files named by FileName are considered.
+func Rotate(dir string, keep int) error {
+	if keep < 1 {
+		return nil
+	}
+	names, err := list(dir)
+	if err != nil {
+		return err
+	}
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// Latest returns when the newest backup in dir was taken; ok is false when
+// there is none.
+func Latest(dir string) (t time.Time, ok bool) {
+	names, err := list(dir)
+	if err != nil || len(names) == 0 {
+		return time.Time{}, false
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(names[len(names)-1], filePrefix), Extension)
+	t, err = time.Parse("20060102-150405", name)
+	return t, err == nil
+}
+
+// list returns dir's backup file names, oldest first.
+func list(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasPrefix(e.Name(), filePrefix) && strings.HasSuffix(e.Name(), Extension) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
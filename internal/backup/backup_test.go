@@ -0,0 +1,164 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/security"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// testSecrets opens staged secret files encrypted under a fixed key.
+func testSecrets(path string) (SecretFile, error) {
+	return security.NewFileBackendWithKey(path, bytes.Repeat([]byte{0x33}, 32)), nil
+}
+
+// stagedFilesContain reports whether any file of the staged restore holds s.
+func stagedFilesContain(t *testing.T, dataDir, s string) bool {
+	t.Helper()
+	found := false
+	err := filepath.WalkDir(filepath.Join(dataDir, PendingDir), func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		found = found || bytes.Contains(data, []byte(s))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return found
+}
+
+func newDataDir(t *testing.T) (string, *storage.Storage) {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := storage.NewStorage(filepath.Join(dir, DatabaseFile), 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return dir, s
+}
+
+func writeAsset(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	srcDir, src := newDataDir(t)
+	if err := src.SetSetting("theme", "dark"); err != nil {
+		t.Fatal(err)
+	}
+	writeAsset(t, filepath.Join(srcDir, "scripts", "hello.lua"), "print('hi')")
+	writeAsset(t, filepath.Join(srcDir, "network-icons", "1.png"), "png")
+	writeAsset(t, filepath.Join(srcDir, "logs", "cascade-chat.log"), "not backed up")
+
+	var archive bytes.Buffer
+	if _, err := Create(&archive, Source{DataDir: srcDir, Storage: src, Secrets: map[string]string{"network-1-password": "hunter2"}}, "passphrase"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if bytes.Contains(archive.Bytes(), []byte("hunter2")) || bytes.Contains(archive.Bytes(), []byte("print('hi')")) {
+		t.Fatal("archive is not encrypted")
+	}
+
+	dstDir, dst := newDataDir(t)
+	writeAsset(t, filepath.Join(dstDir, "scripts", "old.lua"), "old")
+	if _, err := Stage(bytes.NewReader(archive.Bytes()), "wrong", dstDir, testSecrets); !errors.Is(err, ErrBadPassphrase) {
+		t.Fatalf("wrong passphrase: err = %v", err)
+	}
+	if _, err := Stage(bytes.NewReader(archive.Bytes()), "passphrase", dstDir, testSecrets); err != nil {
+		t.Fatalf("Stage: %v", err)
+	}
+	if !HasPending(dstDir) {
+		t.Fatal("no pending restore after Stage")
+	}
+	if stagedFilesContain(t, dstDir, "hunter2") {
+		t.Fatal("staged restore holds a secret in the clear")
+	}
+
+	_ = dst.Close()
+	secrets, applied, err := ApplyPending(dstDir, testSecrets)
+	if err != nil || !applied {
+		t.Fatalf("ApplyPending = %v, %v", applied, err)
+	}
+	if secrets["network-1-password"] != "hunter2" {
+		t.Errorf("secrets = %v", secrets)
+	}
+	if err := FinishPending(dstDir); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := storage.NewStorage(filepath.Join(dstDir, DatabaseFile), 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+	if v, _ := restored.GetSetting("theme"); v != "dark" {
+		t.Errorf("restored theme = %q", v)
+	}
+	if b, err := os.ReadFile(filepath.Join(dstDir, "scripts", "hello.lua")); err != nil || string(b) != "print('hi')" {
+		t.Errorf("restored script = %q, %v", b, err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "scripts", "old.lua")); !os.IsNotExist(err) {
+		t.Errorf("script absent from the backup survived the restore: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "logs")); !os.IsNotExist(err) {
+		t.Errorf("logs were restored: %v", err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dstDir, Dir, "pre-restore-*", "scripts", "old.lua")); len(matches) != 1 {
+		t.Errorf("replaced files were not kept aside")
+	}
+}
+
+func TestStageRejectsTruncatedBackup(t *testing.T) {
+	dir, s := newDataDir(t)
+	var archive bytes.Buffer
+	if _, err := Create(&archive, Source{DataDir: dir, Storage: s}, "passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	cut := archive.Bytes()[:archive.Len()-40]
+	if _, err := Stage(bytes.NewReader(cut), "passphrase", dir, testSecrets); err == nil {
+		t.Fatal("Stage accepted a truncated backup")
+	}
+	if HasPending(dir) {
+		t.Error("a failed Stage left a pending restore")
+	}
+}
+
+func TestRotateKeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		writeAsset(t, filepath.Join(dir, FileName(start.Add(time.Duration(i)*time.Hour))), "x")
+	}
+	writeAsset(t, filepath.Join(dir, "notes.txt"), "keep me")
+
+	if err := Rotate(dir, 2); err != nil {
+		t.Fatal(err)
+	}
+	names, _ := list(dir)
+	if len(names) != 2 || names[1] != FileName(start.Add(4*time.Hour)) {
+		t.Errorf("remaining = %v", names)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Error("Rotate removed an unrelated file")
+	}
+	if latest, ok := Latest(dir); !ok || !latest.Equal(start.Add(4*time.Hour)) {
+		t.Errorf("Latest = %v, %v", latest, ok)
+	}
+}
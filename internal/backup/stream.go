@@ -0,0 +1,198 @@
+package backup
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// An archive file is a fixed header followed by the tar stream cut into
+// AES-256-GCM chunks. Each chunk's nonce is the header's random prefix, the
+// chunk counter and a final-chunk flag, so chunks cannot be reordered,
+// dropped or truncated without Open failing.
+//
+//	magic[8] | logN[1] | r[1] | p[1] | salt[16] | prefix[7]
+//	{ length[4] | sealed chunk }...
+const (
+	magic       = "CSCDBAK1"
+	chunkSize   = 64 << 10
+	saltSize    = 16
+	prefixSize  = 7
+	headerSize  = len(magic) + 3 + saltSize + prefixSize
+	scryptLogN  = 15
+	scryptR     = 8
+	scryptP     = 1
+	maxLogN     = 20
+	finalChunk  = 1
+	normalChunk = 0
+)
+
+var (
+	// ErrNotBackup is returned for input that is not a Cascade backup.
+	ErrNotBackup = errors.New("not a Cascade backup file")
+	// ErrBadPassphrase is returned when the passphrase does not open the backup,
+	// or the file was altered after it was written.
+	ErrBadPassphrase = errors.New("wrong passphrase, or the backup is damaged")
+)
+
+func newAEAD(passphrase string, salt []byte, logN, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<logN, r, p, 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, counter uint32, flag byte) []byte {
+	nonce := make([]byte, 0, prefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	return append(nonce, flag)
+}
+
+// encryptWriter seals everything written to it. Close must be called to
+// write the final chunk.
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+}
+
+func newEncryptWriter(w io.Writer, passphrase string) (*encryptWriter, error) {
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = append(header, scryptLogN, scryptR, scryptP)
+	random := make([]byte, saltSize+prefixSize)
+	if _, err := io.ReadFull(rand.Reader, random); err != nil {
+		return nil, err
+	}
+	header = append(header, random...)
+	salt, prefix := random[:saltSize], random[saltSize:]
+
+	aead, err := newAEAD(passphrase, salt, scryptLogN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, prefix: prefix, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if len(e.buf) == chunkSize {
+			if err := e.flush(normalChunk); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):chunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) flush(flag byte) error {
+	sealed := e.aead.Seal(nil, chunkNonce(e.prefix, e.counter, flag), e.buf, nil)
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(sealed)))
+	if _, err := e.w.Write(length[:]); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// Close writes the final chunk, which may be empty.
+func (e *encryptWriter) Close() error { return e.flush(finalChunk) }
+
+// decryptReader opens a stream written by encryptWriter. It returns
+// ErrBadPassphrase for any chunk that fails to open and io.ErrUnexpectedEOF
+// when the stream ends before the final chunk.
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	done    bool
+}
+
+func newDecryptReader(r io.Reader, passphrase string) (*decryptReader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(magic)]) != magic {
+		return nil, ErrNotBackup
+	}
+	params := header[len(magic):]
+	logN, rr, p := int(params[0]), int(params[1]), int(params[2])
+	if logN < 10 || logN > maxLogN || rr < 1 || rr > 32 || p < 1 || p > 16 {
+		return nil, fmt.Errorf("backup uses unsupported key parameters")
+	}
+	salt := params[3 : 3+saltSize]
+	prefix := params[3+saltSize:]
+	aead, err := newAEAD(passphrase, salt, logN, rr, p)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{r: br, aead: aead, prefix: prefix}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *decryptReader) next() error {
+	var length [4]byte
+	if _, err := io.ReadFull(d.r, length[:]); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > chunkSize+uint32(d.aead.Overhead()) {
+		return ErrBadPassphrase
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	plain, err := d.aead.Open(nil, chunkNonce(d.prefix, d.counter, normalChunk), sealed, nil)
+	if err != nil {
+		if plain, err = d.aead.Open(nil, chunkNonce(d.prefix, d.counter, finalChunk), sealed, nil); err != nil {
+			return ErrBadPassphrase
+		}
+		d.done = true
+	}
+	d.counter++
+	d.buf = plain
+	return nil
+}
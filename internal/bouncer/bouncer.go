@@ -0,0 +1,423 @@
+// Package bouncer lets other IRC clients (a phone, a terminal client) attach to
+// Cascade's live network sessions, the way they would to a bouncer such as
+// ZNC or soju.
+//
+// A client connects to the listener, logs in with PASS or a TLS client
+// certificate, and picks a network with its username ("libera", or
+// "phone/libera" to also name the client). It gets a registration burst that
+// reflects the live session: our nick, the server's ISUPPORT, every joined
+// channel with its topic and names. Clients without CHATHISTORY then get the
+// messages they missed replayed from storage with server-time. After that it
+// sees every line the server sends, adapted to the capabilities it
+// negotiated. Anything it sends goes out through Cascade's session, so its
+// messages also land in Cascade's own buffers.
+//
+// The listener only accepts peers on loopback or the local network, and
+// requires TLS when it listens anywhere but loopback.
+package bouncer
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/core"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+const (
+	// DefaultListen is the listener address when none is configured.
+	DefaultListen = "127.0.0.1:6670"
+	// DefaultReplayLimit is how many messages per buffer are replayed to a
+	// client that cannot fetch history itself.
+	DefaultReplayLimit = 50
+	// MaxReplayLimit bounds ReplayLimit.
+	MaxReplayLimit = 500
+)
+
+var (
+	ErrNoAuth          = errors.New("set a password or at least one client certificate fingerprint")
+	ErrTLSRequired     = errors.New("TLS is required unless the listener is on loopback")
+	ErrBadFingerprint  = errors.New("client certificate fingerprints must be SHA-256 hex digests")
+	ErrFingerprintsTLS = errors.New("client certificate login requires TLS")
+)
+
+// Config configures the listener.
+type Config struct {
+	Listen             string   // host:port to listen on; DefaultListen when empty
+	TLSCert            string   // PEM certificate file; TLS is off when empty
+	TLSKey             string   // PEM private key file
+	Password           string   // PASS secret; empty disables password login
+	ClientFingerprints []string // SHA-256 fingerprints of client certificates that may log in
+	ReplayLimit        int      // messages replayed per buffer; 0 means DefaultReplayLimit
+}
+
+// Validate checks the configuration without touching the filesystem.
+func (c Config) Validate() error {
+	listen := c.Listen
+	if listen == "" {
+		listen = DefaultListen
+	}
+	if _, _, err := net.SplitHostPort(listen); err != nil {
+		return fmt.Errorf("listen address: %w", err)
+	}
+	useTLS := c.TLSCert != "" || c.TLSKey != ""
+	if useTLS && (c.TLSCert == "" || c.TLSKey == "") {
+		return errors.New("TLS needs both a certificate and a key")
+	}
+	if !useTLS && !core.IsLoopback(listen) {
+		return ErrTLSRequired
+	}
+	if c.Password == "" && len(c.ClientFingerprints) == 0 {
+		return ErrNoAuth
+	}
+	for _, fp := range c.ClientFingerprints {
+		if len(NormalizeFingerprint(fp)) != sha256.Size*2 {
+			return ErrBadFingerprint
+		}
+	}
+	if len(c.ClientFingerprints) > 0 && !useTLS {
+		return ErrFingerprintsTLS
+	}
+	if c.ReplayLimit < 0 || c.ReplayLimit > MaxReplayLimit {
+		return fmt.Errorf("replay limit must be between 0 and %d", MaxReplayLimit)
+	}
+	return nil
+}
+
+// NormalizeFingerprint lowercases a hex fingerprint and drops the colons and
+// spaces tools like openssl put in it. Anything that is not hex yields "".
+func NormalizeFingerprint(fp string) string {
+	fp = strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(strings.TrimSpace(fp)))
+	if _, err := hex.DecodeString(fp); err != nil {
+		return ""
+	}
+	return fp
+}
+
+// Upstream is the live session a client is attached to. *irc.IRCClient
+// implements it.
+type Upstream interface {
+	CurrentNick() string
+	IsConnected() bool
+	ISupport() map[string]string
+	CapEnabled(name string) bool
+	SendMessageWithTags(target, message, replyMsgID, channelContext string) error
+	SendNotice(target, message string) error
+	SendAction(target, message string) error
+	RelayMessage(msg ircmsg.Message) error
+}
+
+// Backend gives the listener access to Cascade's networks.
+type Backend interface {
+	// Network resolves the network a client asked for by name or ID.
+	Network(ref string) (*storage.Network, error)
+	// Upstream returns the network's live session, or nil while it is not
+	// connected.
+	Upstream(networkID int64) Upstream
+}
+
+// ClientInfo describes an attached client.
+type ClientInfo struct {
+	NetworkID int64     `json:"networkId"`
+	Network   string    `json:"network"`
+	Name      string    `json:"name"`    // client name from the login, e.g. "phone"; may be empty
+	Address   string    `json:"address"` // remote address
+	Since     time.Time `json:"since"`
+}
+
+// Server is the bouncer listener.
+type Server struct {
+	cfg          Config
+	backend      Backend
+	store        *storage.Storage
+	tlsConfig    *tls.Config
+	fingerprints map[string]bool
+
+	mu       sync.Mutex
+	ln       net.Listener
+	clients  map[*downstream]struct{}
+	withheld map[int64]map[string]bool // per network: upstream batches kept from clients
+	detached map[string]time.Time      // "<client>\x00<network id>" -> when that client last detached
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewServer validates cfg and loads its TLS certificate.
+func NewServer(cfg Config, backend Backend, store *storage.Storage) (*Server, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Listen == "" {
+		cfg.Listen = DefaultListen
+	}
+	if cfg.ReplayLimit == 0 {
+		cfg.ReplayLimit = DefaultReplayLimit
+	}
+	s := &Server{
+		cfg:          cfg,
+		backend:      backend,
+		store:        store,
+		fingerprints: make(map[string]bool, len(cfg.ClientFingerprints)),
+		clients:      make(map[*downstream]struct{}),
+		withheld:     make(map[int64]map[string]bool),
+		detached:     make(map[string]time.Time),
+	}
+	for _, fp := range cfg.ClientFingerprints {
+		s.fingerprints[NormalizeFingerprint(fp)] = true
+	}
+	if cfg.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS certificate: %w", err)
+		}
+		s.tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientAuth:   tls.RequestClientCert, // checked against fingerprints, not a CA
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+	return s, nil
+}
+
+// Listen binds the configured address and accepts clients in the background.
+func (s *Server) Listen() error {
+	ln, err := net.Listen("tcp", s.cfg.Listen)
+	if err != nil {
+		return err
+	}
+	if s.tlsConfig != nil {
+		ln = tls.NewListener(ln, s.tlsConfig)
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = ln.Close()
+		return net.ErrClosed
+	}
+	s.ln = ln
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.acceptLoop(ln)
+	return nil
+}
+
+// Addr returns the bound address, or nil before Listen.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// Close stops listening and disconnects every client.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	ln := s.ln
+	clients := make([]*downstream, 0, len(s.clients))
+	for d := range s.clients {
+		clients = append(clients, d)
+	}
+	s.mu.Unlock()
+
+	var err error
+	if ln != nil {
+		err = ln.Close()
+	}
+	for _, d := range clients {
+		d.closeWithError("Cascade is shutting down")
+	}
+	s.wg.Wait()
+	return err
+}
+
+// Clients lists the attached clients, longest attached first.
+func (s *Server) Clients() []ClientInfo {
+	s.mu.Lock()
+	out := make([]ClientInfo, 0, len(s.clients))
+	for d := range s.clients {
+		if info, ok := d.info(); ok {
+			out = append(out, info)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Since.Before(out[j].Since) })
+	return out
+}
+
+// Relay hands a line from a network's session to the clients attached to it.
+// It is called on the session's read goroutine and never blocks: a client
+// that falls too far behind is disconnected.
+func (s *Server) Relay(networkID int64, msg ircmsg.Message) {
+	if s.withhold(networkID, msg) {
+		return
+	}
+	s.mu.Lock()
+	var targets []*downstream
+	for d := range s.clients {
+		if d.attachedTo(networkID) {
+			targets = append(targets, d)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, d := range targets {
+		if msg.Command == "001" && len(msg.Params) > 0 {
+			// The session re-registered; keep each client's idea of our nick in step.
+			d.syncNick(msg.Params[0])
+			continue
+		}
+		d.deliver(msg)
+	}
+}
+
+// withhold reports whether a session line is Cascade's business only:
+// connection housekeeping, registration, replies to Cascade's own labeled
+// requests and the CHATHISTORY batches it fetched for its buffers.
+func (s *Server) withhold(networkID int64, msg ircmsg.Message) bool {
+	switch msg.Command {
+	case "PING", "PONG", "CAP", "AUTHENTICATE", "002", "003", "004", "005",
+		"900", "901", "902", "903", "904", "905", "906", "907", "908":
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batches := s.withheld[networkID]
+	if msg.Command == "001" {
+		delete(s.withheld, networkID)
+		return false
+	}
+	if msg.Command == "BATCH" && len(msg.Params) > 0 && len(msg.Params[0]) > 1 {
+		id := msg.Params[0][1:]
+		switch msg.Params[0][0] {
+		case '+':
+			kind := ""
+			if len(msg.Params) > 1 {
+				kind = msg.Params[1]
+			}
+			if msg.HasTag("label") || kind == "chathistory" || kind == "draft/chathistory" || batches[parentBatch(msg)] {
+				if batches == nil {
+					batches = make(map[string]bool)
+					s.withheld[networkID] = batches
+				}
+				batches[id] = true
+				return true
+			}
+		case '-':
+			if batches[id] {
+				delete(batches, id)
+				return true
+			}
+		}
+		return false
+	}
+	if msg.HasTag("label") {
+		return true
+	}
+	return batches[parentBatch(msg)]
+}
+
+func parentBatch(msg ircmsg.Message) string {
+	_, id := msg.GetTag("batch")
+	return id
+}
+
+func (s *Server) acceptLoop(ln net.Listener) {
+	defer s.wg.Done()
+	for {
+		nc, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logger.Log.Warn().Err(err).Msg("Bouncer listener stopped")
+			}
+			return
+		}
+		if !allowedPeer(nc.RemoteAddr()) {
+			logger.Log.Warn().Str("addr", nc.RemoteAddr().String()).Msg("Refused bouncer client from outside the local network")
+			_ = nc.Close()
+			continue
+		}
+		d := newDownstream(s, nc)
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = nc.Close()
+			return
+		}
+		s.clients[d] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go func() {
+			defer s.wg.Done()
+			d.serve()
+			s.detach(d)
+		}()
+	}
+}
+
+func (s *Server) detach(d *downstream) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, d)
+	if key, ok := d.replayKey(); ok {
+		s.detached[key] = time.Now()
+	}
+}
+
+// lastDetached returns when the client named by key last detached, if known.
+func (s *Server) lastDetached(key string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.detached[key]
+	return t, ok
+}
+
+// checkPassword compares a PASS argument to the configured password.
+func (s *Server) checkPassword(pass string) bool {
+	return s.cfg.Password != "" && subtle.ConstantTimeCompare([]byte(pass), []byte(s.cfg.Password)) == 1
+}
+
+// trustedCert reports whether a TLS client presented one of the accepted
+// certificates.
+func (s *Server) trustedCert(nc net.Conn) bool {
+	tc, ok := nc.(*tls.Conn)
+	if !ok || len(s.fingerprints) == 0 {
+		return false
+	}
+	certs := tc.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return false
+	}
+	sum := sha256.Sum256(certs[0].Raw)
+	return s.fingerprints[hex.EncodeToString(sum[:])]
+}
+
+// allowedPeer admits loopback, private and link-local addresses only, so a
+// listener bound to all interfaces is still not an open relay.
+func allowedPeer(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	ip := tcp.IP
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
+}
@@ -0,0 +1,376 @@
+package bouncer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+type fakeUpstream struct {
+	mu      sync.Mutex
+	sent    []string
+	relayed []string
+}
+
+func (u *fakeUpstream) CurrentNick() string { return "matt" }
+func (u *fakeUpstream) IsConnected() bool   { return true }
+func (u *fakeUpstream) ISupport() map[string]string {
+	return map[string]string{"CHANTYPES": "#", "PREFIX": "(ov)@+", "NETWORK": "Libera"}
+}
+func (u *fakeUpstream) CapEnabled(string) bool { return true }
+func (u *fakeUpstream) SendMessageWithTags(target, message, replyMsgID, _ string) error {
+	u.record(&u.sent, "PRIVMSG "+target+" "+message+" reply="+replyMsgID)
+	return nil
+}
+func (u *fakeUpstream) SendNotice(target, message string) error {
+	u.record(&u.sent, "NOTICE "+target+" "+message)
+	return nil
+}
+func (u *fakeUpstream) SendAction(target, message string) error {
+	u.record(&u.sent, "ACTION "+target+" "+message)
+	return nil
+}
+func (u *fakeUpstream) RelayMessage(msg ircmsg.Message) error {
+	line, _ := msg.Line()
+	u.record(&u.relayed, strings.TrimSpace(line))
+	return nil
+}
+
+func (u *fakeUpstream) record(list *[]string, line string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	*list = append(*list, line)
+}
+
+func (u *fakeUpstream) snapshot() (sent, relayed []string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string(nil), u.sent...), append([]string(nil), u.relayed...)
+}
+
+type fakeBackend struct {
+	network  *storage.Network
+	upstream *fakeUpstream
+}
+
+func (b *fakeBackend) Network(ref string) (*storage.Network, error) {
+	if strings.EqualFold(ref, b.network.Name) {
+		return b.network, nil
+	}
+	return nil, errors.New("no such network")
+}
+
+func (b *fakeBackend) Upstream(int64) Upstream { return b.upstream }
+
+type harness struct {
+	srv      *Server
+	store    *storage.Storage
+	backend  *fakeBackend
+	channel  *storage.Channel
+	earliest time.Time
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	store, err := storage.NewStorage(filepath.Join(t.TempDir(), "bouncer.db"), 100, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	network := &storage.Network{Name: "Libera", Address: "irc.example.com", Nickname: "matt", Realname: "Matt"}
+	if err := store.CreateNetwork(network); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	channel := &storage.Channel{NetworkID: network.ID, Name: "#ops", Topic: "deploys only"}
+	if err := store.CreateChannel(channel); err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	if err := store.UpdateChannelTopic(channel.ID, channel.Topic); err != nil {
+		t.Fatalf("UpdateChannelTopic: %v", err)
+	}
+	for nick, modes := range map[string]string{"matt": "", "alice": "@+"} {
+		if err := store.AddChannelUser(channel.ID, nick, modes); err != nil {
+			t.Fatalf("AddChannelUser: %v", err)
+		}
+	}
+	earliest := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	for i, text := range []string{"one", "two", "three"} {
+		msg := storage.Message{
+			NetworkID: network.ID, ChannelID: &channel.ID, User: "alice", Message: text,
+			MessageType: "privmsg", Timestamp: earliest.Add(time.Duration(i) * time.Minute),
+			MsgID: fmt.Sprintf("m%d", i),
+		}
+		if err := store.WriteMessageSync(msg); err != nil {
+			t.Fatalf("WriteMessageSync: %v", err)
+		}
+	}
+
+	backend := &fakeBackend{network: network, upstream: &fakeUpstream{}}
+	srv, err := NewServer(Config{Listen: "127.0.0.1:0", Password: "hunter2"}, backend, store)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	if err := srv.Listen(); err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	return &harness{srv: srv, store: store, backend: backend, channel: channel, earliest: earliest}
+}
+
+type testClient struct {
+	t  *testing.T
+	nc net.Conn
+	r  *bufio.Reader
+}
+
+func (h *harness) dial(t *testing.T) *testClient {
+	t.Helper()
+	nc, err := net.Dial("tcp", h.srv.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = nc.Close() })
+	return &testClient{t: t, nc: nc, r: bufio.NewReader(nc)}
+}
+
+func (c *testClient) send(lines ...string) {
+	c.t.Helper()
+	for _, line := range lines {
+		if _, err := c.nc.Write([]byte(line + "\r\n")); err != nil {
+			c.t.Fatalf("write %q: %v", line, err)
+		}
+	}
+}
+
+// readUntil returns the lines read up to and including the first one
+// satisfying done.
+func (c *testClient) readUntil(done func(ircmsg.Message) bool) []ircmsg.Message {
+	c.t.Helper()
+	_ = c.nc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var got []ircmsg.Message
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			c.t.Fatalf("read after %d lines: %v", len(got), err)
+		}
+		msg, err := ircmsg.ParseLine(strings.TrimRight(line, "\r\n"))
+		if err != nil {
+			c.t.Fatalf("parse %q: %v", line, err)
+		}
+		got = append(got, msg)
+		if done(msg) {
+			return got
+		}
+	}
+}
+
+func command(name string) func(ircmsg.Message) bool {
+	return func(m ircmsg.Message) bool { return m.Command == name }
+}
+
+// login registers and reads the burst up to the end of NAMES for #ops.
+func (c *testClient) login(caps string) []ircmsg.Message {
+	c.t.Helper()
+	if caps != "" {
+		c.send("CAP LS 302", "CAP REQ :"+caps)
+	}
+	c.send("PASS hunter2", "NICK phone", "USER phone/libera 0 * :Phone")
+	if caps != "" {
+		c.send("CAP END")
+	}
+	return c.readUntil(command("366"))
+}
+
+func find(msgs []ircmsg.Message, cmd string) (ircmsg.Message, bool) {
+	for _, m := range msgs {
+		if m.Command == cmd {
+			return m, true
+		}
+	}
+	return ircmsg.Message{}, false
+}
+
+func TestLoginBurstReflectsSessionAndReplaysHistory(t *testing.T) {
+	h := newHarness(t)
+	c := h.dial(t)
+	burst := c.login("server-time batch")
+
+	welcome, _ := find(burst, "001")
+	if len(welcome.Params) == 0 || welcome.Params[0] != "matt" {
+		t.Fatalf("001 = %v, want the session's nick", welcome.Params)
+	}
+	if join, ok := find(burst, "JOIN"); !ok || join.Nick() != "matt" || join.Params[0] != "#ops" {
+		t.Errorf("JOIN = %+v", join)
+	}
+	if topic, ok := find(burst, "332"); !ok || topic.Params[2] != "deploys only" {
+		t.Errorf("332 = %+v", topic)
+	}
+	if names, _ := find(burst, "353"); !strings.Contains(names.Params[3], "@alice") || strings.Contains(names.Params[3], "@+alice") {
+		t.Errorf("353 = %v, want only the highest prefix without multi-prefix", names.Params)
+	}
+
+	replay := c.readUntil(func(m ircmsg.Message) bool { return m.Command == "BATCH" && strings.HasPrefix(m.Params[0], "-") })
+	var texts []string
+	for _, m := range replay {
+		if m.Command != "PRIVMSG" {
+			continue
+		}
+		if !m.HasTag("batch") || !m.HasTag("time") || m.HasTag("msgid") {
+			t.Errorf("replayed line tags = %v, want batch and time only", m.AllTags())
+		}
+		texts = append(texts, m.Params[1])
+	}
+	if strings.Join(texts, ",") != "one,two,three" {
+		t.Errorf("replayed %v", texts)
+	}
+}
+
+func TestRelayAdaptsLinesToClientCaps(t *testing.T) {
+	h := newHarness(t)
+	c := h.dial(t)
+	c.login("")
+	c.readUntil(func(m ircmsg.Message) bool { return m.Command == "PRIVMSG" && m.Params[1] == "three" })
+	id := h.backend.network.ID
+
+	relay := func(line string) {
+		msg, err := ircmsg.ParseLine(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.srv.Relay(id, msg)
+	}
+	relay("@time=2026-05-01T10:00:00.000Z;msgid=x :bob!b@h PRIVMSG #ops :hello")
+	relay(":bob!b@h AWAY :lunch")
+	relay("BATCH +hist chathistory #ops")
+	relay("@batch=hist :bob!b@h PRIVMSG #ops :old")
+	relay("BATCH -hist")
+	relay("@label=7 :server 352 matt #ops b h server bob H :0 Bob")
+	relay(":bob!b@h JOIN #ops bobacct :Bob")
+	relay(":server 353 matt = #ops :@+bob!b@h alice!a@h")
+
+	got := c.readUntil(command("353"))
+	if len(got) != 3 {
+		t.Fatalf("got %d lines, want 3: %+v", len(got), got)
+	}
+	if got[0].Command != "PRIVMSG" || got[0].Params[1] != "hello" || len(got[0].AllTags()) != 0 {
+		t.Errorf("PRIVMSG = %+v, want tags stripped", got[0])
+	}
+	if len(got[1].Params) != 1 {
+		t.Errorf("JOIN params = %v, want the plain form", got[1].Params)
+	}
+	if got[2].Params[3] != "@bob alice" {
+		t.Errorf("NAMES = %q", got[2].Params[3])
+	}
+}
+
+func TestClientMessagesGoOutThroughTheSession(t *testing.T) {
+	h := newHarness(t)
+	c := h.dial(t)
+	c.login("")
+	c.send("@+draft/reply=m1 PRIVMSG #ops :deploy done", "PRIVMSG #ops :\x01ACTION waves\x01", "JOIN #dev")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		sent, relayed := h.backend.upstream.snapshot()
+		if len(sent) == 2 && len(relayed) == 1 {
+			if sent[0] != "PRIVMSG #ops deploy done reply=m1" || sent[1] != "ACTION #ops waves" || relayed[0] != "JOIN #dev" {
+				t.Fatalf("sent %q, relayed %q", sent, relayed)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("sent %q, relayed %q", sent, relayed)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The session's echo of the client's own line is not shown back to a client
+	// without echo-message; a line Cascade sent itself still is.
+	id := h.backend.network.ID
+	echo, _ := ircmsg.ParseLine(":matt PRIVMSG #ops :deploy done")
+	h.srv.Relay(id, echo)
+	own, _ := ircmsg.ParseLine(":matt PRIVMSG #ops :typed in Cascade")
+	h.srv.Relay(id, own)
+	got := c.readUntil(func(m ircmsg.Message) bool { return m.Command == "PRIVMSG" && m.Nick() == "matt" })
+	if last := got[len(got)-1]; last.Params[1] != "typed in Cascade" {
+		t.Errorf("first own line relayed = %q", last.Params[1])
+	}
+}
+
+func TestChatHistoryIsAnsweredFromStorage(t *testing.T) {
+	h := newHarness(t)
+	c := h.dial(t)
+	c.login("draft/chathistory batch server-time message-tags")
+
+	c.send("CHATHISTORY BEFORE #ops timestamp=" + h.earliest.Add(2*time.Minute).Format(serverTimeLayout) + " 10")
+	got := c.readUntil(func(m ircmsg.Message) bool { return m.Command == "BATCH" && strings.HasPrefix(m.Params[0], "-") })
+	var texts []string
+	for _, m := range got {
+		if m.Command == "PRIVMSG" {
+			texts = append(texts, m.Params[1])
+			if present, id := m.GetTag("msgid"); !present || id == "" {
+				t.Errorf("history line without msgid: %+v", m)
+			}
+		}
+	}
+	if strings.Join(texts, ",") != "one,two" {
+		t.Errorf("BEFORE returned %v", texts)
+	}
+
+	c.send("CHATHISTORY AFTER #ops msgid=m0 1")
+	got = c.readUntil(func(m ircmsg.Message) bool { return m.Command == "BATCH" && strings.HasPrefix(m.Params[0], "-") })
+	if msg, ok := find(got, "PRIVMSG"); !ok || msg.Params[1] != "two" {
+		t.Errorf("AFTER msgid=m0 = %+v", got)
+	}
+
+	c.send("CHATHISTORY BOGUS #ops * 10")
+	if fail := c.readUntil(command("FAIL")); fail[len(fail)-1].Params[1] != "INVALID_PARAMS" {
+		t.Errorf("FAIL = %v", fail[len(fail)-1].Params)
+	}
+}
+
+func TestLoginIsRefusedWithoutTheRightPassword(t *testing.T) {
+	h := newHarness(t)
+	c := h.dial(t)
+	c.send("PASS wrong", "NICK phone", "USER libera 0 * :Phone")
+	got := c.readUntil(command("ERROR"))
+	if _, ok := find(got, "464"); !ok {
+		t.Errorf("no ERR_PASSWDMISMATCH in %+v", got)
+	}
+	if len(h.srv.Clients()) != 0 {
+		t.Error("refused client is listed as attached")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	fp := strings.Repeat("AB:", 31) + "AB"
+	cases := []struct {
+		cfg  Config
+		want error
+	}{
+		{Config{Password: "x"}, nil},
+		{Config{Listen: "0.0.0.0:6670", Password: "x"}, ErrTLSRequired},
+		{Config{}, ErrNoAuth},
+		{Config{ClientFingerprints: []string{fp}}, ErrFingerprintsTLS},
+		{Config{ClientFingerprints: []string{fp}, TLSCert: "c.pem", TLSKey: "k.pem", Listen: "[::]:6670"}, nil},
+		{Config{ClientFingerprints: []string{"nope"}, TLSCert: "c.pem", TLSKey: "k.pem"}, ErrBadFingerprint},
+	}
+	for i, tc := range cases {
+		if err := tc.cfg.Validate(); !errors.Is(err, tc.want) {
+			t.Errorf("case %d: Validate() = %v, want %v", i, err, tc.want)
+		}
+	}
+	if got := NormalizeFingerprint(fp); got != strings.Repeat("ab", 32) {
+		t.Errorf("NormalizeFingerprint = %q", got)
+	}
+}
@@ -0,0 +1,799 @@
+package bouncer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/ergochat/irc-go/ircreader"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+const (
+	// serverName is the source of every line the listener itself originates.
+	serverName = "cascade"
+	// sendQueue is how many lines may wait for a slow client before it is
+	// disconnected rather than holding up the session it is attached to.
+	sendQueue           = 4096
+	registrationTimeout = time.Minute
+	idleTimeout         = 5 * time.Minute
+	pingInterval        = 90 * time.Second
+	writeTimeout        = 30 * time.Second
+	// drainTimeout bounds flushing the queue to a client being disconnected.
+	drainTimeout = 2 * time.Second
+	maxLineBytes = 8191 + 512
+	// maxUnechoed bounds the messages remembered to keep a client without
+	// echo-message from seeing its own lines come back.
+	maxUnechoed = 64
+	// namesLineBudget keeps RPL_NAMREPLY lines well under 512 bytes.
+	namesLineBudget  = 400
+	serverTimeLayout = "2006-01-02T15:04:05.000Z"
+)
+
+// offeredCaps are the capabilities a client may request. Most mirror what
+// Cascade negotiates upstream, so each line that depends on one is passed on
+// or adapted per client.
+var offeredCaps = []string{
+	"account-notify", "account-tag", "away-notify", "batch", "chghost", "draft/chathistory",
+	"echo-message", "extended-join", "invite-notify", "message-tags", "multi-prefix", "server-time", "setname",
+}
+
+func offered(name string) bool {
+	for _, c := range offeredCaps {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+type unechoed struct{ target, text string }
+
+// downstream is one attached client.
+type downstream struct {
+	srv         *Server
+	nc          net.Conn
+	out         chan []byte
+	closing     chan struct{} // closed to have the writer flush, send ERROR and hang up
+	closeReason string        // set before closing is closed
+	closingOnce sync.Once
+	done        chan struct{} // closed once the connection is gone
+	closeOnce   sync.Once
+
+	// Registration input, owned by the serve goroutine.
+	capNegotiating bool
+	pass           string
+	nick           string
+	username       string
+
+	mu          sync.Mutex
+	caps        map[string]bool
+	registered  bool
+	bursting    bool             // the registration burst is being written; relayed lines wait in pending
+	pending     []ircmsg.Message // lines relayed during the burst
+	network     *storage.Network
+	clientName  string
+	currentNick string
+	prefixes    string // membership prefix symbols, highest first
+	chanTypes   string
+	sent        []unechoed // our own messages the session will echo back; see adaptLocked
+	since       time.Time
+	batchSeq    int
+}
+
+func newDownstream(s *Server, nc net.Conn) *downstream {
+	return &downstream{
+		srv:     s,
+		nc:      nc,
+		out:     make(chan []byte, sendQueue),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+		caps:    make(map[string]bool),
+	}
+}
+
+// serve reads the client's lines until it quits or the connection fails.
+func (d *downstream) serve() {
+	go d.writeLoop()
+	defer func() {
+		d.closeWithError("connection closed")
+		<-d.done
+	}()
+	if tc, ok := d.nc.(*tls.Conn); ok {
+		_ = tc.SetDeadline(time.Now().Add(registrationTimeout))
+		if err := tc.Handshake(); err != nil {
+			logger.Log.Debug().Err(err).Str("addr", d.nc.RemoteAddr().String()).Msg("Bouncer TLS handshake failed")
+			return
+		}
+		_ = tc.SetDeadline(time.Time{})
+	}
+
+	var reader ircreader.Reader
+	reader.Initialize(d.nc, 1024, maxLineBytes)
+	for {
+		timeout := idleTimeout
+		if !d.isRegistered() {
+			timeout = registrationTimeout
+		}
+		_ = d.nc.SetReadDeadline(time.Now().Add(timeout))
+		line, err := reader.ReadLine()
+		if err != nil {
+			return
+		}
+		msg, err := ircmsg.ParseLineStrict(string(line), true, 0)
+		if err != nil {
+			continue
+		}
+		msg.Command = strings.ToUpper(msg.Command)
+		if !d.handle(msg) {
+			return
+		}
+	}
+}
+
+func (d *downstream) writeLoop() {
+	defer d.close()
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	for {
+		var line []byte
+		select {
+		case line = <-d.out:
+		case <-ping.C:
+			line = []byte("PING :" + serverName + "\r\n")
+		case <-d.closing:
+			d.drain()
+			return
+		case <-d.done:
+			return
+		}
+		_ = d.nc.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err := d.nc.Write(line); err != nil {
+			return
+		}
+	}
+}
+
+// drain writes whatever is queued and the closing ERROR, giving up after
+// drainTimeout.
+func (d *downstream) drain() {
+	_ = d.nc.SetWriteDeadline(time.Now().Add(drainTimeout))
+	for {
+		select {
+		case line := <-d.out:
+			if _, err := d.nc.Write(line); err != nil {
+				return
+			}
+		default:
+			msg := ircmsg.MakeMessage(nil, "", "ERROR", "Closing link: "+d.closeReason)
+			if line, err := msg.LineBytes(); err == nil {
+				_, _ = d.nc.Write(line)
+			}
+			return
+		}
+	}
+}
+
+func (d *downstream) close() {
+	d.closeOnce.Do(func() {
+		close(d.done)
+		_ = d.nc.Close()
+	})
+}
+
+// closeWithError disconnects the client after flushing its queue and telling
+// it why. Only the first reason given is sent.
+func (d *downstream) closeWithError(reason string) {
+	d.closingOnce.Do(func() {
+		d.closeReason = reason
+		close(d.closing)
+	})
+}
+
+// send queues a line from the serve goroutine, waiting for room.
+func (d *downstream) send(msg ircmsg.Message) {
+	line, err := msg.LineBytes()
+	if err != nil {
+		logger.Log.Debug().Err(err).Str("command", msg.Command).Msg("Dropped unencodable bouncer line")
+		return
+	}
+	select {
+	case d.out <- line:
+	case <-d.done:
+	}
+}
+
+// pushLocked queues a relayed line without waiting; a client whose queue is
+// full is disconnected. Called with d.mu held.
+func (d *downstream) pushLocked(msg ircmsg.Message) {
+	line, err := msg.LineBytes()
+	if err != nil {
+		return
+	}
+	select {
+	case d.out <- line:
+	case <-d.done:
+	default:
+		d.closeWithError("send queue exceeded")
+	}
+}
+
+func (d *downstream) numeric(code string, params ...string) {
+	d.send(ircmsg.MakeMessage(nil, serverName, code, append([]string{d.nickOrStar()}, params...)...))
+}
+
+func (d *downstream) notice(text string) {
+	d.send(ircmsg.MakeMessage(nil, serverName, "NOTICE", d.nickOrStar(), text))
+}
+
+func (d *downstream) nickOrStar() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch {
+	case d.currentNick != "":
+		return d.currentNick
+	case d.nick != "":
+		return d.nick
+	}
+	return "*"
+}
+
+func (d *downstream) isRegistered() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.registered
+}
+
+func (d *downstream) hasCap(name string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.caps[name]
+}
+
+func (d *downstream) attachedTo(networkID int64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.registered && d.network.ID == networkID
+}
+
+func (d *downstream) info() (ClientInfo, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.registered {
+		return ClientInfo{}, false
+	}
+	return ClientInfo{
+		NetworkID: d.network.ID,
+		Network:   d.network.Name,
+		Name:      d.clientName,
+		Address:   d.nc.RemoteAddr().String(),
+		Since:     d.since,
+	}, true
+}
+
+// replayKey identifies the client across reconnects for replay purposes.
+func (d *downstream) replayKey() (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.registered {
+		return "", false
+	}
+	return d.clientName + "\x00" + strconv.FormatInt(d.network.ID, 10), true
+}
+
+func (d *downstream) upstream() Upstream {
+	d.mu.Lock()
+	id := d.network.ID
+	d.mu.Unlock()
+	return d.srv.backend.Upstream(id)
+}
+
+// handle processes one client line, returning false to disconnect.
+func (d *downstream) handle(msg ircmsg.Message) bool {
+	switch msg.Command {
+	case "CAP":
+		return d.handleCap(msg)
+	case "PING":
+		token := serverName
+		if len(msg.Params) > 0 {
+			token = msg.Params[len(msg.Params)-1]
+		}
+		d.send(ircmsg.MakeMessage(nil, serverName, "PONG", serverName, token))
+		return true
+	case "PONG":
+		return true
+	case "QUIT":
+		// Only this client leaves; the session stays up.
+		return false
+	}
+
+	if !d.isRegistered() {
+		switch msg.Command {
+		case "PASS":
+			if len(msg.Params) > 0 {
+				d.pass = msg.Params[0]
+			}
+		case "NICK":
+			if len(msg.Params) > 0 {
+				d.nick = msg.Params[0]
+			}
+		case "USER":
+			if len(msg.Params) > 0 {
+				d.username = msg.Params[0]
+			}
+		default:
+			d.numeric("451", "You have not registered")
+			return true
+		}
+		return d.tryRegister()
+	}
+
+	switch msg.Command {
+	case "PASS", "USER":
+		d.numeric("462", "You may not reregister")
+	case "CHATHISTORY":
+		d.handleChatHistory(msg.Params)
+	case "PRIVMSG", "NOTICE":
+		d.relayMessage(msg)
+	default:
+		up := d.upstream()
+		if up == nil {
+			d.notConnected()
+			return true
+		}
+		if err := up.RelayMessage(msg); err != nil {
+			d.notice(fmt.Sprintf("Could not send %s: %v", msg.Command, err))
+		}
+	}
+	return true
+}
+
+func (d *downstream) handleCap(msg ircmsg.Message) bool {
+	if len(msg.Params) == 0 {
+		d.numeric("461", "CAP", "Not enough parameters")
+		return true
+	}
+	registered := d.isRegistered()
+	reply := func(sub, caps string) {
+		d.send(ircmsg.MakeMessage(nil, serverName, "CAP", d.nickOrStar(), sub, caps))
+	}
+	switch sub := strings.ToUpper(msg.Params[0]); sub {
+	case "LS":
+		if !registered {
+			d.capNegotiating = true
+		}
+		reply("LS", strings.Join(offeredCaps, " "))
+	case "LIST":
+		d.mu.Lock()
+		enabled := make([]string, 0, len(d.caps))
+		for name := range d.caps {
+			enabled = append(enabled, name)
+		}
+		d.mu.Unlock()
+		sort.Strings(enabled)
+		reply("LIST", strings.Join(enabled, " "))
+	case "REQ":
+		if !registered {
+			d.capNegotiating = true
+		}
+		requested := ""
+		if len(msg.Params) > 1 {
+			requested = msg.Params[len(msg.Params)-1]
+		}
+		names := strings.Fields(requested)
+		for _, name := range names {
+			if !offered(strings.TrimPrefix(name, "-")) {
+				reply("NAK", requested)
+				return true
+			}
+		}
+		d.mu.Lock()
+		for _, name := range names {
+			if strings.HasPrefix(name, "-") {
+				delete(d.caps, name[1:])
+			} else {
+				d.caps[name] = true
+			}
+		}
+		d.mu.Unlock()
+		reply("ACK", requested)
+	case "END":
+		if !registered {
+			d.capNegotiating = false
+			return d.tryRegister()
+		}
+	default:
+		d.numeric("410", sub, "Invalid CAP command")
+	}
+	return true
+}
+
+// splitUsername reads "client/network" (or just "network") from USER.
+func splitUsername(username string) (client, network string) {
+	username = strings.TrimPrefix(username, "~")
+	if i := strings.LastIndex(username, "/"); i >= 0 {
+		return username[:i], username[i+1:]
+	}
+	return "", username
+}
+
+// tryRegister completes registration once NICK, USER and any CAP negotiation
+// are in, returning false when the login is refused.
+func (d *downstream) tryRegister() bool {
+	if d.nick == "" || d.username == "" || d.capNegotiating {
+		return true
+	}
+	if !d.srv.trustedCert(d.nc) && !d.srv.checkPassword(d.pass) {
+		logger.Log.Warn().Str("addr", d.nc.RemoteAddr().String()).Msg("Bouncer client failed to log in")
+		d.numeric("464", "Password incorrect")
+		d.closeWithError("bad password")
+		return false
+	}
+	d.pass = ""
+
+	clientName, ref := splitUsername(d.username)
+	network, err := d.srv.backend.Network(ref)
+	if err != nil {
+		d.notice(fmt.Sprintf("Log in as <network> or <client>/<network>: %v", err))
+		d.closeWithError("unknown network")
+		return false
+	}
+	d.attach(network, clientName)
+	return true
+}
+
+// attach binds the client to a network and writes the registration burst.
+// Lines the session relays meanwhile are held and sent after it.
+func (d *downstream) attach(network *storage.Network, clientName string) {
+	up := d.srv.backend.Upstream(network.ID)
+	nick := d.nick
+	var isupport map[string]string
+	if up != nil {
+		if current := up.CurrentNick(); current != "" {
+			nick = current
+		}
+		isupport = up.ISupport()
+	}
+
+	d.mu.Lock()
+	d.network = network
+	d.clientName = clientName
+	d.currentNick = nick
+	d.prefixes = prefixSymbols(isupport["PREFIX"])
+	d.chanTypes = isupport["CHANTYPES"]
+	if d.chanTypes == "" {
+		d.chanTypes = "#&"
+	}
+	d.since = time.Now()
+	d.registered = true
+	d.bursting = true
+	d.mu.Unlock()
+	logger.Log.Info().Str("network", network.Name).Str("client", clientName).Str("addr", d.nc.RemoteAddr().String()).Msg("Bouncer client attached")
+
+	d.numeric("001", fmt.Sprintf("Welcome to Cascade, %s. You are attached to %s", nick, network.Name))
+	d.numeric("002", "Your host is "+serverName)
+	d.numeric("003", "This server was created "+d.since.UTC().Format(time.RFC1123))
+	d.numeric("004", serverName, serverName, "iow", "biklmnopstv")
+	tokens := isupportTokens(isupport)
+	for len(tokens) > 0 {
+		n := min(len(tokens), 12)
+		d.numeric("005", append(tokens[:n:n], "are supported by this server")...)
+		tokens = tokens[n:]
+	}
+	d.numeric("422", "MOTD File is missing")
+	if up == nil || !up.IsConnected() {
+		d.notConnected()
+	}
+
+	channels, err := d.srv.store.GetJoinedChannels(network.ID, nick)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("network", network.Name).Msg("Bouncer could not list joined channels")
+	}
+	for _, ch := range channels {
+		d.sendChannelState(network, nick, ch)
+	}
+	d.replayMissed(network, nick, channels)
+	d.finishBurst()
+}
+
+func (d *downstream) sendChannelState(network *storage.Network, nick string, ch storage.Channel) {
+	join := ircmsg.MakeMessage(nil, nick, "JOIN", ch.Name)
+	if d.hasCap("extended-join") {
+		join.Params = append(join.Params, "*", network.Realname)
+	}
+	d.send(join)
+	if ch.Topic != "" {
+		d.numeric("332", ch.Name, ch.Topic)
+	}
+
+	users, err := d.srv.store.GetChannelUsers(ch.ID)
+	if err != nil {
+		logger.Log.Debug().Err(err).Str("channel", ch.Name).Msg("Bouncer could not list channel users")
+	}
+	multiPrefix := d.hasCap("multi-prefix")
+	var entries []string
+	size := 0
+	flush := func() {
+		if len(entries) > 0 {
+			d.numeric("353", "=", ch.Name, strings.Join(entries, " "))
+			entries, size = nil, 0
+		}
+	}
+	for _, u := range users {
+		modes := u.Modes
+		if !multiPrefix && len(modes) > 1 {
+			modes = modes[:1]
+		}
+		entry := modes + u.Nickname
+		if size+len(entry)+1 > namesLineBudget {
+			flush()
+		}
+		entries = append(entries, entry)
+		size += len(entry) + 1
+	}
+	flush()
+	d.numeric("366", ch.Name, "End of /NAMES list")
+}
+
+// finishBurst sends the lines relayed during the burst, in order, and
+// switches the client to live delivery.
+func (d *downstream) finishBurst() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, msg := range d.pending {
+		if out, ok := d.adaptLocked(msg); ok {
+			d.pushLocked(out)
+		}
+	}
+	d.pending = nil
+	d.bursting = false
+}
+
+func (d *downstream) notConnected() {
+	d.mu.Lock()
+	name := d.network.Name
+	d.mu.Unlock()
+	d.notice(name + " is not connected right now; Cascade will relay it again once it reconnects")
+}
+
+// deliver relays a session line to the client.
+func (d *downstream) deliver(msg ircmsg.Message) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.bursting {
+		if len(d.pending) >= sendQueue {
+			d.closeWithError("send queue exceeded")
+			return
+		}
+		d.pending = append(d.pending, msg)
+		return
+	}
+	if out, ok := d.adaptLocked(msg); ok {
+		d.pushLocked(out)
+	}
+}
+
+// syncNick tells the client about a nick the session registered with after a
+// reconnect.
+func (d *downstream) syncNick(nick string) {
+	d.mu.Lock()
+	old := d.currentNick
+	d.mu.Unlock()
+	if old != "" && old != nick {
+		d.deliver(ircmsg.MakeMessage(nil, old, "NICK", nick))
+	}
+}
+
+// adaptLocked rewrites a session line for this client's capabilities, or
+// reports that the client must not see it. Called with d.mu held.
+func (d *downstream) adaptLocked(msg ircmsg.Message) (ircmsg.Message, bool) {
+	params := append([]string(nil), msg.Params...)
+	self := strings.EqualFold(msg.Nick(), d.currentNick)
+	switch msg.Command {
+	case "BATCH":
+		if !d.caps["batch"] {
+			return msg, false
+		}
+	case "TAGMSG":
+		if !d.caps["message-tags"] {
+			return msg, false
+		}
+	case "AWAY":
+		if !d.caps["away-notify"] {
+			return msg, false
+		}
+	case "ACCOUNT":
+		if !d.caps["account-notify"] {
+			return msg, false
+		}
+	case "CHGHOST":
+		if !d.caps["chghost"] {
+			return msg, false
+		}
+	case "SETNAME":
+		if !d.caps["setname"] {
+			return msg, false
+		}
+	case "INVITE":
+		if len(params) > 0 && !strings.EqualFold(params[0], d.currentNick) && !d.caps["invite-notify"] {
+			return msg, false
+		}
+	case "JOIN":
+		if !d.caps["extended-join"] && len(params) > 1 {
+			params = params[:1]
+		}
+	case "NICK":
+		if self && len(params) > 0 {
+			d.currentNick = params[0]
+		}
+	case "353":
+		if len(params) > 0 {
+			params[len(params)-1] = d.namesEntries(params[len(params)-1])
+		}
+	case "PRIVMSG", "NOTICE":
+		if self && !d.caps["echo-message"] && len(params) > 1 && d.takeUnechoed(params[0], params[1]) {
+			return msg, false
+		}
+	}
+	return ircmsg.MakeMessage(d.tagsFor(msg.AllTags()), msg.Source, msg.Command, params...), true
+}
+
+// namesEntries trims NAMES entries to what the client negotiated: bare nicks
+// (the session may use userhost-in-names) and, without multi-prefix, only the
+// highest prefix.
+func (d *downstream) namesEntries(list string) string {
+	entries := strings.Fields(list)
+	for i, entry := range entries {
+		if at := strings.IndexByte(entry, '!'); at >= 0 {
+			entry = entry[:at]
+		}
+		if !d.caps["multi-prefix"] {
+			n := 0
+			for n < len(entry) && strings.IndexByte(d.prefixes, entry[n]) >= 0 {
+				n++
+			}
+			if n > 1 {
+				entry = entry[:1] + entry[n:]
+			}
+		}
+		entries[i] = entry
+	}
+	return strings.Join(entries, " ")
+}
+
+// tagsFor keeps the tags this client negotiated.
+func (d *downstream) tagsFor(all map[string]string) map[string]string {
+	tags := make(map[string]string, len(all))
+	for name, value := range all {
+		switch name {
+		case "time":
+			if !d.caps["server-time"] {
+				continue
+			}
+		case "account":
+			if !d.caps["account-tag"] {
+				continue
+			}
+		case "batch":
+			if !d.caps["batch"] {
+				continue
+			}
+		case "label":
+			continue
+		default:
+			if !d.caps["message-tags"] {
+				continue
+			}
+		}
+		tags[name] = value
+	}
+	return tags
+}
+
+// rememberUnechoed notes a message this client sent so its echo is not shown
+// back to it; clients without echo-message display their own lines locally.
+func (d *downstream) rememberUnechoed(target, text string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.caps["echo-message"] {
+		return
+	}
+	if len(d.sent) == maxUnechoed {
+		d.sent = d.sent[1:]
+	}
+	d.sent = append(d.sent, unechoed{strings.ToLower(target), text})
+}
+
+func (d *downstream) takeUnechoed(target, text string) bool {
+	target = strings.ToLower(target)
+	for i, s := range d.sent {
+		if s.target == target && s.text == text {
+			d.sent = append(d.sent[:i], d.sent[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// relayMessage sends a client's PRIVMSG or NOTICE through the session, which
+// records it in Cascade's buffers like one typed there.
+func (d *downstream) relayMessage(msg ircmsg.Message) {
+	if len(msg.Params) < 2 || msg.Params[1] == "" {
+		d.numeric("412", "No text to send")
+		return
+	}
+	up := d.upstream()
+	if up == nil {
+		d.notConnected()
+		return
+	}
+	target, text := msg.Params[0], msg.Params[1]
+	d.rememberUnechoed(target, text)
+
+	var err error
+	switch {
+	case msg.Command == "NOTICE":
+		err = up.SendNotice(target, text)
+	case strings.HasPrefix(text, "\x01ACTION "):
+		err = up.SendAction(target, strings.TrimSuffix(strings.TrimPrefix(text, "\x01ACTION "), "\x01"))
+	case strings.HasPrefix(text, "\x01"):
+		err = up.RelayMessage(msg) // other CTCP requests go out untouched
+	default:
+		err = up.SendMessageWithTags(target, text, firstTag(msg, "+draft/reply", "+reply"), firstTag(msg, "+draft/channel-context", "+channel-context"))
+	}
+	if err != nil {
+		d.notice(fmt.Sprintf("Could not send to %s: %v", target, err))
+	}
+}
+
+func firstTag(msg ircmsg.Message, names ...string) string {
+	for _, name := range names {
+		if present, value := msg.GetTag(name); present && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// prefixSymbols extracts the symbols from an ISUPPORT PREFIX value such as
+// "(ov)@+".
+func prefixSymbols(prefix string) string {
+	if i := strings.IndexByte(prefix, ')'); i >= 0 {
+		return prefix[i+1:]
+	}
+	return "@+"
+}
+
+// isupportTokens renders the session's ISUPPORT for the client, replacing the
+// history tokens with the listener's own (it answers CHATHISTORY from storage).
+func isupportTokens(isupport map[string]string) []string {
+	tokens := make(map[string]string, len(isupport)+2)
+	for name, value := range isupport {
+		tokens[name] = value
+	}
+	if len(tokens) == 0 {
+		tokens["CASEMAPPING"] = "rfc1459"
+		tokens["CHANTYPES"] = "#&"
+		tokens["PREFIX"] = "(ov)@+"
+	}
+	tokens["CHATHISTORY"] = strconv.Itoa(maxHistoryLimit)
+	tokens["MSGREFTYPES"] = "msgid,timestamp"
+
+	out := make([]string, 0, len(tokens))
+	for name, value := range tokens {
+		if value == "" {
+			out = append(out, name)
+		} else {
+			out = append(out, name+"="+value)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,304 @@
+package bouncer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// maxHistoryLimit is the most messages one CHATHISTORY request returns; it is
+// advertised as ISUPPORT CHATHISTORY.
+const maxHistoryLimit = 500
+
+// farFuture stands in for "now" as an exclusive upper bound, so LATEST also
+// returns rows stamped slightly ahead of the local clock by the server.
+var farFuture = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// replayMissed sends each buffer's recent messages to a client that cannot
+// fetch them itself: everything since it last detached, or the last
+// ReplayLimit messages when it is new, capped at ReplayLimit either way.
+func (d *downstream) replayMissed(network *storage.Network, nick string, channels []storage.Channel) {
+	if d.hasCap("draft/chathistory") {
+		return
+	}
+	var since time.Time
+	if key, ok := d.replayKey(); ok {
+		since, _ = d.srv.lastDetached(key)
+	}
+	limit := d.srv.cfg.ReplayLimit
+	newer := func(msgs []storage.Message) []storage.Message {
+		out := msgs[:0]
+		for _, m := range msgs {
+			if m.Timestamp.After(since) {
+				out = append(out, m)
+			}
+		}
+		return out
+	}
+
+	for _, ch := range channels {
+		msgs, err := d.srv.store.GetMessages(network.ID, &ch.ID, limit)
+		if err == nil {
+			d.sendHistory(ch.Name, ch.Name, nick, newer(msgs), false)
+		}
+	}
+	conversations, err := d.srv.store.GetOpenPMConversations(network.ID, nick)
+	if err != nil {
+		return
+	}
+	for _, pm := range conversations {
+		msgs, err := d.srv.store.GetPrivateMessages(network.ID, pm.TargetUser, nick, limit)
+		if err == nil {
+			d.sendHistory(pm.TargetUser, "", nick, newer(msgs), false)
+		}
+	}
+}
+
+// sendHistory writes stored messages as a chathistory batch (or bare lines
+// when the client has no batch support). An empty result is still sent as an
+// empty batch when it answers a request, so the client stops waiting.
+func (d *downstream) sendHistory(target, channel, self string, msgs []storage.Message, answer bool) {
+	lines := make([]ircmsg.Message, 0, len(msgs))
+	for _, m := range msgs {
+		if line, ok := historyLine(m, channel, self); ok {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 && !answer {
+		return
+	}
+	d.sendBatch("chathistory", []string{target}, lines)
+}
+
+func (d *downstream) sendBatch(kind string, params []string, lines []ircmsg.Message) {
+	id := ""
+	if d.hasCap("batch") {
+		d.mu.Lock()
+		d.batchSeq++
+		id = "h" + strconv.Itoa(d.batchSeq)
+		d.mu.Unlock()
+		d.send(ircmsg.MakeMessage(nil, serverName, "BATCH", append([]string{"+" + id, kind}, params...)...))
+	}
+	for _, line := range lines {
+		d.mu.Lock()
+		out := ircmsg.MakeMessage(d.tagsFor(line.AllTags()), line.Source, line.Command, line.Params...)
+		d.mu.Unlock()
+		if id != "" {
+			out.SetTag("batch", id)
+		}
+		d.send(out)
+	}
+	if id != "" {
+		d.send(ircmsg.MakeMessage(nil, serverName, "BATCH", "-"+id))
+	}
+}
+
+// historyLine renders a stored chat message as the line the server sent.
+// channel is the channel name, or "" for a private conversation.
+func historyLine(m storage.Message, channel, self string) (ircmsg.Message, bool) {
+	command, text := "PRIVMSG", m.Message
+	switch m.MessageType {
+	case "privmsg":
+	case "action":
+		text = "\x01ACTION " + text + "\x01"
+	case "notice":
+		command = "NOTICE"
+	default:
+		return ircmsg.Message{}, false
+	}
+
+	source := m.User
+	if raw, err := ircmsg.ParseLine(m.RawLine); err == nil && raw.Source != "" && strings.EqualFold(raw.Nick(), m.User) {
+		source = raw.Source
+	}
+	target := channel
+	if target == "" {
+		if strings.EqualFold(m.User, self) {
+			target = m.PMTarget
+		} else {
+			target = self
+		}
+	}
+
+	tags := map[string]string{"time": m.Timestamp.UTC().Format(serverTimeLayout)}
+	if m.MsgID != "" {
+		tags["msgid"] = m.MsgID
+	}
+	if m.ReplyMsgID != "" {
+		tags["+draft/reply"] = m.ReplyMsgID
+	}
+	if m.ChannelContext != "" {
+		tags["+draft/channel-context"] = m.ChannelContext
+	}
+	return ircmsg.MakeMessage(tags, source, command, target, text), true
+}
+
+func (d *downstream) chatHistoryFail(code, subcommand, description string) {
+	params := []string{"CHATHISTORY", code}
+	if subcommand != "" {
+		params = append(params, subcommand)
+	}
+	d.send(ircmsg.MakeMessage(nil, serverName, "FAIL", append(params, description)...))
+}
+
+// handleChatHistory answers a CHATHISTORY request from storage.
+func (d *downstream) handleChatHistory(params []string) {
+	if len(params) < 4 {
+		d.chatHistoryFail("NEED_MORE_PARAMS", "", "Missing parameters")
+		return
+	}
+	sub := strings.ToUpper(params[0])
+	limit, err := strconv.Atoi(params[len(params)-1])
+	if err != nil || limit < 1 {
+		d.chatHistoryFail("INVALID_PARAMS", sub, "Invalid limit")
+		return
+	}
+	limit = min(limit, maxHistoryLimit)
+
+	d.mu.Lock()
+	networkID, self, chanTypes := d.network.ID, d.currentNick, d.chanTypes
+	d.mu.Unlock()
+
+	if sub == "TARGETS" {
+		from, okFrom := d.parseRef(networkID, params[1])
+		to, okTo := d.parseRef(networkID, params[2])
+		if !okFrom || !okTo {
+			d.chatHistoryFail("INVALID_PARAMS", sub, "Invalid timestamp")
+			return
+		}
+		d.chatHistoryTargets(networkID, self, from, to, limit)
+		return
+	}
+
+	target := params[1]
+	var channelID *int64
+	channel, pmTarget := "", ""
+	if target != "" && strings.IndexByte(chanTypes, target[0]) >= 0 {
+		ch, err := d.srv.store.GetChannelByName(networkID, target)
+		if err != nil {
+			d.sendHistory(target, target, self, nil, true)
+			return
+		}
+		channelID, channel = &ch.ID, ch.Name
+	} else {
+		pmTarget = target
+	}
+	before := func(t time.Time, n int) []storage.Message {
+		msgs, _ := d.srv.store.GetMessagesBeforeTime(networkID, channelID, pmTarget, t.UTC(), n)
+		return msgs
+	}
+	after := func(t time.Time, n int) []storage.Message {
+		msgs, _ := d.srv.store.GetMessagesAfterTime(networkID, channelID, pmTarget, t.UTC(), n)
+		return msgs
+	}
+	ref, ok := d.parseRef(networkID, params[2])
+	if !ok {
+		d.chatHistoryFail("INVALID_PARAMS", sub, "Invalid message reference")
+		return
+	}
+
+	var msgs []storage.Message
+	switch sub {
+	case "LATEST":
+		msgs = before(farFuture, limit)
+		if !ref.IsZero() {
+			msgs = between(msgs, ref, farFuture)
+		}
+	case "BEFORE":
+		msgs = before(ref, limit)
+	case "AFTER":
+		msgs = after(ref, limit)
+	case "AROUND":
+		msgs = before(ref, limit/2)
+		msgs = append(msgs, after(ref.Add(-time.Nanosecond), limit-len(msgs))...)
+	case "BETWEEN":
+		if len(params) < 5 {
+			d.chatHistoryFail("NEED_MORE_PARAMS", sub, "Missing parameters")
+			return
+		}
+		end, ok := d.parseRef(networkID, params[3])
+		if !ok {
+			d.chatHistoryFail("INVALID_PARAMS", sub, "Invalid message reference")
+			return
+		}
+		if ref.Before(end) {
+			msgs = between(after(ref, limit), ref, end)
+		} else {
+			msgs = between(before(ref, limit), end, ref)
+		}
+	default:
+		d.chatHistoryFail("INVALID_PARAMS", sub, "Unknown subcommand")
+		return
+	}
+	d.sendHistory(target, channel, self, msgs, true)
+}
+
+// between keeps the messages strictly between from and to.
+func between(msgs []storage.Message, from, to time.Time) []storage.Message {
+	out := msgs[:0]
+	for _, m := range msgs {
+		if m.Timestamp.After(from) && m.Timestamp.Before(to) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// parseRef reads "*", "timestamp=<RFC 3339>" or "msgid=<id>"; "*" yields the
+// zero time.
+func (d *downstream) parseRef(networkID int64, ref string) (time.Time, bool) {
+	switch {
+	case ref == "*":
+		return time.Time{}, true
+	case strings.HasPrefix(ref, "timestamp="):
+		t, err := time.Parse(time.RFC3339Nano, strings.TrimPrefix(ref, "timestamp="))
+		return t, err == nil
+	case strings.HasPrefix(ref, "msgid="):
+		m, err := d.srv.store.GetMessageByMsgID(networkID, strings.TrimPrefix(ref, "msgid="))
+		return m.Timestamp, err == nil
+	}
+	return time.Time{}, false
+}
+
+// chatHistoryTargets lists the channels and conversations with messages
+// between two times, by their latest message.
+func (d *downstream) chatHistoryTargets(networkID int64, self string, from, to time.Time, limit int) {
+	if from.After(to) {
+		from, to = to, from
+	}
+	type active struct {
+		name   string
+		latest time.Time
+	}
+	var found []active
+	check := func(name string, channelID *int64, pmTarget string) {
+		msgs, err := d.srv.store.GetMessagesBeforeTime(networkID, channelID, pmTarget, farFuture, 1)
+		if err == nil && len(msgs) == 1 && msgs[0].Timestamp.After(from) && msgs[0].Timestamp.Before(to) {
+			found = append(found, active{name, msgs[0].Timestamp})
+		}
+	}
+	if channels, err := d.srv.store.GetJoinedChannels(networkID, self); err == nil {
+		for _, ch := range channels {
+			check(ch.Name, &ch.ID, "")
+		}
+	}
+	if peers, err := d.srv.store.GetPrivateMessageConversations(networkID, self, false); err == nil {
+		for _, peer := range peers {
+			check(peer, nil, peer)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].latest.Before(found[j].latest) })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+
+	lines := make([]ircmsg.Message, 0, len(found))
+	for _, f := range found {
+		lines = append(lines, ircmsg.MakeMessage(nil, serverName, "CHATHISTORY", "TARGETS", f.name, f.latest.UTC().Format(serverTimeLayout)))
+	}
+	d.sendBatch("draft/chathistory-targets", nil, lines)
+}
@@ -0,0 +1,99 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// Client is a control-socket client. Calls are serialised; Subscribe turns the
+// connection into an event stream, so use a separate Client for calls.
+type Client struct {
+	nc      net.Conn
+	scanner *bufio.Scanner
+	mu      sync.Mutex
+	nextID  int64
+}
+
+// Dial connects to the control socket at path.
+func Dial(path string) (*Client, error) {
+	nc, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("connect to Cascade at %s (is it running?): %w", path, err)
+	}
+	scanner := bufio.NewScanner(nc)
+	scanner.Buffer(make([]byte, 0, 4096), 16*maxRequestBytes)
+	return &Client{nc: nc, scanner: scanner}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error { return c.nc.Close() }
+
+// Call invokes method and decodes its result into result (which may be nil).
+// Event notifications arriving meanwhile are skipped.
+func (c *Client) Call(method string, params, result interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	id := json.RawMessage(strconv.FormatInt(c.nextID, 10))
+	req := struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  interface{}     `json:"params,omitempty"`
+	}{"2.0", id, method, params}
+	line, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if _, err := c.nc.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	for c.scanner.Scan() {
+		var resp Response
+		if err := json.Unmarshal(c.scanner.Bytes(), &resp); err != nil {
+			return fmt.Errorf("malformed response: %w", err)
+		}
+		if string(resp.ID) != string(id) {
+			continue // an event notification or a stray reply
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	}
+	if err := c.scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("connection closed by Cascade")
+}
+
+// Subscribe streams events of the given types (all when empty) to fn until the
+// connection closes or fn returns an error.
+func (c *Client) Subscribe(types []string, fn func(Event) error) error {
+	if err := c.Call(MethodSubscribe, SubscribeParams{Types: types}, nil); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.scanner.Scan() {
+		var note struct {
+			Method string `json:"method"`
+			Params Event  `json:"params"`
+		}
+		if err := json.Unmarshal(c.scanner.Bytes(), &note); err != nil || note.Method != NotificationEvent {
+			continue
+		}
+		if err := fn(note.Params); err != nil {
+			return err
+		}
+	}
+	return c.scanner.Err()
+}
@@ -0,0 +1,133 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/events"
+)
+
+func startTestServer(t *testing.T) (*Server, *events.EventBus, string) {
+	t.Helper()
+	// Unix socket paths are length-limited, so avoid the long t.TempDir().
+	dir, err := os.MkdirTemp("", "ctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	bus := events.NewEventBus()
+	t.Cleanup(bus.Close)
+
+	s := NewServer(bus)
+	s.Handle(MethodSend, func(params json.RawMessage) (interface{}, error) {
+		var p SendParams
+		if err := DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Target == "" {
+			return nil, InvalidParams(errors.New("target is required"))
+		}
+		return map[string]string{"echo": p.Message}, nil
+	})
+	path := SocketPath(dir)
+	if err := s.Listen(path); err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s, bus, path
+}
+
+func TestCallRoundTrip(t *testing.T) {
+	_, _, path := startTestServer(t)
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("socket mode = %v, %v; want 0600", info, err)
+	}
+
+	c, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var got map[string]string
+	if err := c.Call(MethodSend, SendParams{Network: "libera", Target: "#ops", Message: "deploy done"}, &got); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if got["echo"] != "deploy done" {
+		t.Errorf("result = %v", got)
+	}
+
+	var rpcErr *Error
+	if err := c.Call(MethodSend, SendParams{}, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidParams {
+		t.Errorf("missing target: err = %v, want invalid params", err)
+	}
+	if err := c.Call("nope", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+		t.Errorf("unknown method: err = %v, want method not found", err)
+	}
+}
+
+func TestSubscribeStreamsFilteredEvents(t *testing.T) {
+	_, bus, path := startTestServer(t)
+	c, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	got := make(chan Event, 4)
+	go func() {
+		_ = c.Subscribe([]string{"message.received"}, func(e Event) error {
+			got <- e
+			return nil
+		})
+	}()
+
+	// The subscription is live once the server has registered it; keep
+	// emitting until the first event arrives.
+	deadline := time.After(5 * time.Second)
+	for {
+		bus.Emit(events.Event{Type: "connection.status", Data: map[string]interface{}{"ignored": true}})
+		bus.Emit(events.Event{Type: "message.received", Data: map[string]interface{}{"message": "hi"}, Timestamp: time.Now()})
+		select {
+		case e := <-got:
+			if e.Type != "message.received" || e.Data["message"] != "hi" {
+				t.Fatalf("event = %+v", e)
+			}
+			return
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no event streamed")
+		}
+	}
+}
+
+func TestListenRefusesLiveSocketAndReplacesStaleOne(t *testing.T) {
+	_, _, path := startTestServer(t)
+	if err := NewServer(events.NewEventBus()).Listen(path); err == nil {
+		t.Fatal("second server took over a live socket")
+	}
+
+	dir := filepath.Dir(path)
+	stale := filepath.Join(dir, "stale.sock")
+	ln, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = ln.Close()
+
+	s := NewServer(events.NewEventBus())
+	if err := s.Listen(stale); err != nil {
+		t.Fatalf("Listen over a stale socket: %v", err)
+	}
+	_ = s.Close()
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("socket left behind after Close: %v", err)
+	}
+}
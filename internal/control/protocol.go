@@ -0,0 +1,157 @@
+// Package control is Cascade's local automation API: newline-delimited JSON-RPC
+// 2.0 over a Unix domain socket in the data directory. The socket is created
+// 0600 inside the 0700 data directory, so only the user running Cascade can
+// reach it. The App registers the methods; cascade-cli is the stock client.
+package control
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SocketName is the control socket's file name under the data directory.
+const SocketName = "control.sock"
+
+// Methods served over the socket.
+const (
+	MethodNetworks  = "networks.list"    // -> []NetworkInfo
+	MethodChannels  = "channels.list"    // NetworkParams -> []ChannelInfo
+	MethodSend      = "message.send"     // SendParams
+	MethodCommand   = "command.run"      // CommandParams
+	MethodSearch    = "messages.search"  // SearchParams -> []SearchHit
+	MethodSubscribe = "events.subscribe" // SubscribeParams; then "event" notifications
+	MethodBackup    = "backup.create"    // BackupParams -> BackupResult
+	MethodRestore   = "backup.restore"   // BackupParams -> BackupResult
+)
+
+// NotificationEvent is the method name of pushed event notifications.
+const NotificationEvent = "event"
+
+// JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeAppError       = -32000
+)
+
+// Request is a JSON-RPC request or, without an ID, a notification.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// NetworkParams names a network by name (case-insensitive) or numeric ID.
+type NetworkParams struct {
+	Network string `json:"network"`
+}
+
+// NetworkInfo is one configured network.
+type NetworkInfo struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+	Nick      string `json:"nick,omitempty"`
+}
+
+// ChannelInfo is one joined channel.
+type ChannelInfo struct {
+	Name  string `json:"name"`
+	Topic string `json:"topic,omitempty"`
+}
+
+// SendParams sends a PRIVMSG to a channel or nick.
+type SendParams struct {
+	Network string `json:"network"`
+	Target  string `json:"target"`
+	Message string `json:"message"`
+}
+
+// CommandParams runs a slash command as if typed in Buffer ("" = status).
+type CommandParams struct {
+	Network string `json:"network"`
+	Command string `json:"command"`
+	Buffer  string `json:"buffer,omitempty"`
+}
+
+// SearchParams is a full-text message search. Network is optional.
+type SearchParams struct {
+	Query   string `json:"query"`
+	Network string `json:"network,omitempty"`
+	Limit   int    `json:"limit,omitempty"`
+}
+
+// SearchHit is one search result.
+type SearchHit struct {
+	Network   string    `json:"network"`
+	Channel   string    `json:"channel,omitempty"`
+	User      string    `json:"user"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BackupParams names a backup file on the machine running Cascade. An empty
+// Path writes to the configured backup directory; an empty Passphrase uses the
+// one stored for scheduled backups.
+type BackupParams struct {
+	Path       string `json:"path,omitempty"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+// BackupResult describes a backup that was written or staged for restore.
+type BackupResult struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SubscribeParams filters streamed events by exact type; empty streams all.
+type SubscribeParams struct {
+	Types []string `json:"types,omitempty"`
+}
+
+// Event is one event-bus event as streamed to subscribers.
+type Event struct {
+	Type      string                 `json:"type"`
+	Source    string                 `json:"source,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// DataDir returns Cascade's data directory: $CASCADE_DATA_DIR, or
+// ~/.cascade-chat, matching the app.
+func DataDir() (string, error) {
+	if dir := os.Getenv("CASCADE_DATA_DIR"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cascade-chat"), nil
+}
+
+// SocketPath is the control socket for a data directory.
+func SocketPath(dataDir string) string {
+	return filepath.Join(dataDir, SocketName)
+}
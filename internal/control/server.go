@@ -0,0 +1,306 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/events"
+	"github.com/matt0x6f/irc-client/internal/logger"
+)
+
+const (
+	// maxRequestBytes bounds one request line.
+	maxRequestBytes = 1 << 20
+	// eventBacklog is how many events may queue for a slow subscriber before
+	// further ones are dropped. Delivery runs on the event bus dispatcher, which
+	// must never wait on a socket.
+	eventBacklog = 512
+)
+
+// Method handles one RPC method. params is the raw "params" value (possibly
+// empty); the result is marshalled as the response's "result".
+type Method func(params json.RawMessage) (interface{}, error)
+
+// Server serves the control API on a Unix socket.
+type Server struct {
+	bus     *events.EventBus
+	methods map[string]Method
+
+	mu     sync.Mutex
+	ln     net.Listener
+	conns  map[*conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewServer creates a server whose events.subscribe streams from bus.
+func NewServer(bus *events.EventBus) *Server {
+	return &Server{bus: bus, methods: make(map[string]Method), conns: make(map[*conn]struct{})}
+}
+
+// Handle registers a method. Call before Listen.
+func (s *Server) Handle(name string, m Method) {
+	s.methods[name] = m
+}
+
+// InvalidParams wraps err as a JSON-RPC invalid-params error.
+func InvalidParams(err error) error {
+	return &Error{Code: CodeInvalidParams, Message: err.Error()}
+}
+
+// DecodeParams unmarshals params into v, reporting failures as invalid params.
+func DecodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return InvalidParams(err)
+	}
+	return nil
+}
+
+// Listen binds the socket at path, 0600, and starts accepting. A leftover
+// socket from a crashed run is replaced; one that still answers is not.
+func (s *Server) Listen(path string) error {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if probe, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			_ = probe.Close()
+			return fmt.Errorf("another Cascade instance is serving %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("remove stale control socket: %w", err)
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return fmt.Errorf("secure control socket: %w", err)
+	}
+
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go s.acceptLoop(ln)
+	return nil
+}
+
+// Close stops accepting, disconnects every client and removes the socket.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	ln := s.ln
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	var err error
+	if ln != nil {
+		err = ln.Close() // also unlinks the socket file
+	}
+	for _, c := range conns {
+		_ = c.nc.Close()
+	}
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) acceptLoop(ln net.Listener) {
+	defer s.wg.Done()
+	for {
+		nc, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logger.Log.Warn().Err(err).Msg("Control socket accept failed")
+			}
+			return
+		}
+		c := &conn{server: s, nc: nc, events: make(chan events.Event, eventBacklog), done: make(chan struct{})}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = nc.Close()
+			return
+		}
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go c.serve()
+	}
+}
+
+// conn is one connected client.
+type conn struct {
+	server  *Server
+	nc      net.Conn
+	writeMu sync.Mutex
+
+	events chan events.Event
+	done   chan struct{}
+	typeMu sync.RWMutex
+	types  map[string]bool // nil streams every type
+}
+
+func (c *conn) serve() {
+	defer c.server.wg.Done()
+	subscribed := false
+	defer func() {
+		if subscribed {
+			c.server.bus.Unsubscribe("*", c)
+		}
+		close(c.done)
+		_ = c.nc.Close()
+		c.server.mu.Lock()
+		delete(c.server.conns, c)
+		c.server.mu.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(c.nc)
+	scanner.Buffer(make([]byte, 0, 4096), maxRequestBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			c.reply(nil, nil, &Error{Code: CodeParseError, Message: "parse error"})
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			c.reply(req.ID, nil, &Error{Code: CodeInvalidRequest, Message: "invalid request"})
+			continue
+		}
+		if req.Method == MethodSubscribe {
+			var p SubscribeParams
+			if err := DecodeParams(req.Params, &p); err != nil {
+				c.reply(req.ID, nil, toRPCError(err))
+				continue
+			}
+			c.setTypes(p.Types)
+			if !subscribed {
+				subscribed = true
+				c.server.bus.Subscribe("*", c)
+				c.server.wg.Add(1)
+				go c.streamEvents()
+			}
+			c.reply(req.ID, map[string]bool{"subscribed": true}, nil)
+			continue
+		}
+		method, ok := c.server.methods[req.Method]
+		if !ok {
+			c.reply(req.ID, nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method})
+			continue
+		}
+		result, err := method(req.Params)
+		if err != nil {
+			c.reply(req.ID, nil, toRPCError(err))
+			continue
+		}
+		c.reply(req.ID, result, nil)
+	}
+}
+
+func toRPCError(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	return &Error{Code: CodeAppError, Message: err.Error()}
+}
+
+// reply answers a request. Notifications (no ID) get no reply.
+func (c *conn) reply(id json.RawMessage, result interface{}, rpcErr *Error) {
+	if len(id) == 0 && rpcErr == nil {
+		return
+	}
+	resp := Response{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			resp.Error = &Error{Code: CodeAppError, Message: "unencodable result: " + err.Error()}
+		} else {
+			resp.Result = encoded
+		}
+	}
+	c.write(resp)
+}
+
+func (c *conn) write(v interface{}) {
+	line, err := json.Marshal(v)
+	if err != nil {
+		logger.Log.Warn().Err(err).Msg("Control socket: unencodable message")
+		return
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.nc.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, _ = c.nc.Write(append(line, '\n'))
+}
+
+func (c *conn) setTypes(types []string) {
+	var set map[string]bool
+	if len(types) > 0 {
+		set = make(map[string]bool, len(types))
+		for _, t := range types {
+			set[t] = true
+		}
+	}
+	c.typeMu.Lock()
+	c.types = set
+	c.typeMu.Unlock()
+}
+
+// OnEvent queues an event for the client without blocking the bus.
+func (c *conn) OnEvent(event events.Event) {
+	c.typeMu.RLock()
+	wanted := c.types == nil || c.types[event.Type]
+	c.typeMu.RUnlock()
+	if !wanted {
+		return
+	}
+	select {
+	case c.events <- event:
+	default:
+		logger.Log.Debug().Str("type", event.Type).Msg("Control socket subscriber behind; dropping event")
+	}
+}
+
+func (c *conn) streamEvents() {
+	defer c.server.wg.Done()
+	for {
+		select {
+		case <-c.done:
+			return
+		case event := <-c.events:
+			c.write(struct {
+				JSONRPC string `json:"jsonrpc"`
+				Method  string `json:"method"`
+				Params  Event  `json:"params"`
+			}{"2.0", NotificationEvent, Event{
+				Type:      event.Type,
+				Source:    string(event.Source),
+				Timestamp: event.Timestamp,
+				Data:      event.Data,
+			}})
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package core fronts the headless (`-tags server`) build when it runs as an
+// always-on daemon. The Wails server runtime already serves the frontend, the
+// /wails/runtime binding bridge and the /wails/events WebSocket, but without
+// any authentication, so the daemon binds it to loopback and puts this
+// package's handler in front: a token-checking reverse proxy that a desktop
+// app (or a browser) attaches to over TLS. Loopback is still open to every
+// local user, so the proxy also stamps each request with a per-run secret that
+// RequireSecret checks in front of the Wails asset server.
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// TokenFileName is the file under the data directory holding the daemon's
+	// generated access token.
+	TokenFileName = "core.token"
+	// AttachPath exchanges ?token= for a session cookie and redirects to the UI.
+	AttachPath = "/core/attach"
+	// SecretHeader carries the per-run secret from the proxy to the backend.
+	SecretHeader = "X-Cascade-Core-Secret"
+
+	sessionCookie = "cascade_core"
+	tokenBytes    = 32
+)
+
+var (
+	ErrBadURL     = errors.New("core: URL must be http:// or https:// with a host")
+	ErrEmptyToken = errors.New("core: token must not be empty")
+)
+
+// LoadOrCreateToken returns the token stored in dataDir, generating a random
+// one (0600) on first use.
+func LoadOrCreateToken(dataDir string) (string, error) {
+	path := filepath.Join(dataDir, TokenFileName)
+	if b, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(b)); token != "" {
+			return token, nil
+		}
+		return "", fmt.Errorf("core token file %s is empty", path)
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("read core token: %w", err)
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return "", fmt.Errorf("generate core token: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("create core token: %w", err)
+	}
+	if _, err := f.WriteString(token + "\n"); err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("write core token: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write core token: %w", err)
+	}
+	return token, nil
+}
+
+// NewSecret returns a random secret for one run of the daemon, shared by
+// NewHandler and RequireSecret and never written to disk.
+func NewSecret() (string, error) {
+	secret, err := randomToken()
+	if err != nil {
+		return "", fmt.Errorf("generate core secret: %w", err)
+	}
+	return secret, nil
+}
+
+func randomToken() (string, error) {
+	raw := make([]byte, tokenBytes)
+	if _, err := io.ReadFull(rand.Reader, raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// IsLoopback reports whether a listen address only accepts local connections.
+// An empty host ("[::]:port", ":port") listens everywhere and is not loopback.
+func IsLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// NormalizeURL validates a core's base URL and strips any path, query or
+// fragment, so "https://core.example:7667/" and "https://core.example:7667"
+// name the same core.
+func NormalizeURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", ErrBadURL
+	}
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host}).String(), nil
+}
+
+// AttachURL is the address a GUI loads to attach to the core at base. Loading
+// it sets the session cookie and lands on the normal UI.
+func AttachURL(base, token string) (string, error) {
+	base, err := NormalizeURL(base)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", ErrEmptyToken
+	}
+	return base + AttachPath + "?token=" + url.QueryEscape(token), nil
+}
+
+// NewHandler returns the authenticating proxy in front of the Wails server at
+// backend. /health stays open for container health checks; everything else,
+// including the event WebSocket, needs either "Authorization: Bearer <token>"
+// or the session cookie set by AttachPath. Every proxied request carries
+// secret in SecretHeader, replacing any copy the client sent. secure marks the
+// cookie Secure and should be true whenever the listener serves TLS.
+func NewHandler(backend *url.URL, token, secret string, secure bool) http.Handler {
+	session := sessionValue(token)
+	proxy := httputil.NewSingleHostReverseProxy(backend)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Header.Set(SecretHeader, secret)
+	}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		proxy.ServeHTTP(w, r)
+	})
+	mux.HandleFunc(AttachPath, func(w http.ResponseWriter, r *http.Request) {
+		if !equal(r.URL.Query().Get("token"), token) {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     sessionCookie,
+			Value:    session,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   secure,
+			SameSite: http.SameSiteStrictMode,
+		})
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token, session) {
+			http.Error(w, "not attached to this core", http.StatusUnauthorized)
+			return
+		}
+		// The backend neither needs nor should see our credentials.
+		r.Header.Del("Authorization")
+		stripSessionCookie(r)
+		proxy.ServeHTTP(w, r)
+	})
+	return mux
+}
+
+// RequireSecret refuses requests that did not come through NewHandler with the
+// same secret. It is shaped as Wails asset middleware, which sees the binding
+// bridge and the UI but not the Wails server's own /health and /wails/events
+// routes.
+func RequireSecret(secret string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if secret == "" || !equal(r.Header.Get(SecretHeader), secret) {
+				http.Error(w, "use the core's listen address", http.StatusForbidden)
+				return
+			}
+			r.Header.Del(SecretHeader)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func authorized(r *http.Request, token, session string) bool {
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && equal(bearer, token) {
+		return true
+	}
+	c, err := r.Cookie(sessionCookie)
+	return err == nil && equal(c.Value, session)
+}
+
+// stripSessionCookie rewrites the Cookie header without ours.
+func stripSessionCookie(r *http.Request) {
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+	for _, c := range cookies {
+		if c.Name != sessionCookie {
+			r.AddCookie(c)
+		}
+	}
+}
+
+// sessionValue derives the cookie value from the token, so the cookie jar
+// never holds the token itself.
+func sessionValue(token string) string {
+	sum := sha256.Sum256([]byte("cascade-core-session\x00" + token))
+	return hex.EncodeToString(sum[:])
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
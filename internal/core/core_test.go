@@ -0,0 +1,191 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestCore starts a backend guarded by RequireSecret, as the daemon guards
+// the Wails server, and the proxy in front of it.
+func newTestCore(t *testing.T, token string) (front, backend *httptest.Server, seen *[]*http.Request) {
+	t.Helper()
+	const secret = "per-run"
+	var requests []*http.Request
+	backend = httptest.NewServer(RequireSecret(secret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Clone(r.Context()))
+		_, _ = w.Write([]byte("backend " + r.URL.Path))
+	})))
+	t.Cleanup(backend.Close)
+	target, _ := url.Parse(backend.URL)
+	front = httptest.NewServer(NewHandler(target, token, secret, false))
+	t.Cleanup(front.Close)
+	return front, backend, &requests
+}
+
+func TestHandlerRequiresToken(t *testing.T) {
+	front, _, seen := newTestCore(t, "s3cret")
+
+	resp, err := http.Get(front.URL + "/wails/runtime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || len(*seen) != 0 {
+		t.Fatalf("anonymous request: status %d, backend saw %d requests", resp.StatusCode, len(*seen))
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, front.URL+"/wails/runtime", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("bearer request: status %d", resp.StatusCode)
+	}
+	if got := (*seen)[0].Header.Get("Authorization"); got != "" {
+		t.Errorf("backend saw Authorization %q, want it stripped", got)
+	}
+
+	resp, err = http.Get(front.URL + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("/health: status %d, want it open", resp.StatusCode)
+	}
+}
+
+func TestAttachSetsSessionCookie(t *testing.T) {
+	front, _, seen := newTestCore(t, "s3cret")
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	resp, err := client.Get(front.URL + AttachPath + "?token=wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || len(resp.Cookies()) != 0 {
+		t.Fatalf("bad token: status %d, cookies %v", resp.StatusCode, resp.Cookies())
+	}
+
+	attach, err := AttachURL(front.URL+"/ignored?x=1", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.Get(attach)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+	if resp.StatusCode != http.StatusSeeOther || len(cookies) != 1 || !cookies[0].HttpOnly {
+		t.Fatalf("attach: status %d, cookies %v", resp.StatusCode, cookies)
+	}
+	if strings.Contains(cookies[0].Value, "s3cret") {
+		t.Errorf("session cookie %q carries the token", cookies[0].Value)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, front.URL+"/", nil)
+	req.AddCookie(cookies[0])
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("cookie request: status %d", resp.StatusCode)
+	}
+	if got := (*seen)[len(*seen)-1].Header.Get("Cookie"); got != "theme=dark" {
+		t.Errorf("backend saw Cookie %q, want only the app's own cookies", got)
+	}
+}
+
+func TestBackendRefusesDirectRequests(t *testing.T) {
+	front, backend, seen := newTestCore(t, "s3cret")
+
+	for _, secret := range []string{"", "guess"} {
+		req, _ := http.NewRequest(http.MethodPost, backend.URL+"/wails/runtime", nil)
+		if secret != "" {
+			req.Header.Set(SecretHeader, secret)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("direct request with secret %q: status %d, want %d", secret, resp.StatusCode, http.StatusForbidden)
+		}
+	}
+	if len(*seen) != 0 {
+		t.Fatalf("backend handled %d direct requests", len(*seen))
+	}
+
+	// A client can't pick the secret the proxy sends, and the backend handler
+	// never sees it.
+	req, _ := http.NewRequest(http.MethodPost, front.URL+"/wails/runtime", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	req.Header.Set(SecretHeader, "guess")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(*seen) != 1 {
+		t.Fatalf("proxied request: status %d, backend saw %d requests", resp.StatusCode, len(*seen))
+	}
+	if got := (*seen)[0].Header.Get(SecretHeader); got != "" {
+		t.Errorf("backend handler saw %s %q, want it stripped", SecretHeader, got)
+	}
+}
+
+func TestLoadOrCreateToken(t *testing.T) {
+	dir := t.TempDir()
+	first, err := LoadOrCreateToken(dir)
+	if err != nil || len(first) != 2*tokenBytes {
+		t.Fatalf("LoadOrCreateToken = %q, %v", first, err)
+	}
+	info, err := os.Stat(filepath.Join(dir, TokenFileName))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("token file mode = %v, %v; want 0600", info, err)
+	}
+	if again, _ := LoadOrCreateToken(dir); again != first {
+		t.Errorf("second load = %q, want the stored %q", again, first)
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:7667": true,
+		"[::1]:7667":     true,
+		"localhost:7667": true,
+		"0.0.0.0:7667":   false,
+		":7667":          false,
+		"192.0.2.4:7667": false,
+		"nonsense":       false,
+	} {
+		if got := IsLoopback(addr); got != want {
+			t.Errorf("IsLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	if got, err := NormalizeURL(" https://core.example:7667/ui?x=1 "); err != nil || got != "https://core.example:7667" {
+		t.Errorf("NormalizeURL = %q, %v", got, err)
+	}
+	for _, bad := range []string{"", "core.example", "ftp://core.example", "https://"} {
+		if _, err := NormalizeURL(bad); err == nil {
+			t.Errorf("NormalizeURL(%q) accepted", bad)
+		}
+	}
+}
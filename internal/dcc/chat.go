@@ -0,0 +1,523 @@
+package dcc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxChatLineBytes = 16 * 1024
+	chatWriteTimeout = 20 * time.Second
+)
+
+// ChatLineFunc receives each line read from a connected chat. action is set for
+// CTCP ACTION lines, whose text has the ACTION framing removed.
+type ChatLineFunc func(chat ChatSession, text string, action bool)
+type ChatEmitFunc func(ChatEvent)
+
+type chatConn struct {
+	session ChatSession
+	offer   Offer
+	conn    net.Conn
+	cancel  context.CancelFunc
+	writeMu sync.Mutex
+}
+
+// SetChatHandlers wires the callbacks used by direct chat. Call it before the
+// manager starts handling control messages.
+func (m *Manager) SetChatHandlers(line ChatLineFunc, emit ChatEmitFunc) {
+	m.mu.Lock()
+	m.chatLine, m.chatEmit = line, emit
+	m.mu.Unlock()
+}
+
+func (m *Manager) ChatSnapshot() []ChatView {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]ChatView, 0, len(m.chats))
+	for _, c := range m.chats {
+		out = append(out, c.session.View())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UpdatedAt.After(out[j].UpdatedAt) })
+	return out
+}
+
+func (m *Manager) GetChat(id string) (ChatSession, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.chats[id]
+	if !ok {
+		return ChatSession{}, false
+	}
+	return c.session, true
+}
+
+// FindChat returns the open session with peer on a network, if any.
+func (m *Manager) FindChat(networkID int64, peer string) (ChatSession, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if c := m.findChatLocked(networkID, peer); c != nil {
+		return c.session, true
+	}
+	return ChatSession{}, false
+}
+
+func (m *Manager) findChatLocked(networkID int64, peer string) *chatConn {
+	var found *chatConn
+	for _, c := range m.chats {
+		if c.session.NetworkID == networkID && strings.EqualFold(c.session.Peer, peer) && c.session.Open() {
+			if found == nil || c.session.UpdatedAt.After(found.session.UpdatedAt) {
+				found = c
+			}
+		}
+	}
+	return found
+}
+
+// OpenChat offers a direct chat to peer. A pending offer from the same peer is
+// accepted instead, and an already open session is returned unchanged.
+func (m *Manager) OpenChat(networkID int64, networkName, peer string) (ChatView, error) {
+	m.mu.Lock()
+	if !m.settings.DirectChat {
+		m.mu.Unlock()
+		return ChatView{}, fmt.Errorf("direct chat is turned off")
+	}
+	if existing := m.findChatLocked(networkID, peer); existing != nil {
+		session := existing.session
+		m.mu.Unlock()
+		if session.Direction == DirectionIncoming && session.Status == StatusOffered {
+			if err := m.AcceptChat(session.ID); err != nil {
+				return ChatView{}, err
+			}
+			if current, ok := m.GetChat(session.ID); ok {
+				session = current
+			}
+		}
+		return session.View(), nil
+	}
+	now, id := time.Now(), newID()
+	c := &chatConn{session: ChatSession{
+		ID: id, NetworkID: networkID, NetworkName: networkName, Peer: peer,
+		Direction: DirectionOutgoing, Status: StatusNegotiating, CreatedAt: now, UpdatedAt: now,
+	}}
+	m.chats[id] = c
+	settings := m.settings
+	session := c.session
+	m.mu.Unlock()
+	m.chatChanged(session)
+
+	var err error
+	if settings.outgoingMode() == ConnectionPassive {
+		err = m.beginPassiveChat(id)
+	} else {
+		err = m.beginClassicChat(id, settings)
+	}
+	if err != nil {
+		m.finishChat(id, context.Background(), err)
+		return ChatView{}, err
+	}
+	current, _ := m.GetChat(id)
+	return current.View(), nil
+}
+
+func (m *Manager) beginClassicChat(id string, settings Settings) error {
+	if settings.AdvertisedAddress == "" {
+		return errors.New("Set an advertised address or use Automatic compatibility mode")
+	}
+	address, err := FormatAddress(settings.AdvertisedAddress)
+	if err != nil {
+		return err
+	}
+	listener, err := listen(settings)
+	if err != nil {
+		return err
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	m.mu.Lock()
+	c := m.chats[id]
+	if c == nil || c.session.Status != StatusNegotiating {
+		m.mu.Unlock()
+		_ = listener.Close()
+		return fmt.Errorf("direct chat was closed")
+	}
+	c.session.Address, c.session.Port = address, port
+	session := c.session
+	m.mu.Unlock()
+	if err := m.sendControl(session.NetworkID, session.Peer, FormatChat(address, port, "")); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	m.acceptChat(id, listener)
+	return nil
+}
+
+func (m *Manager) beginPassiveChat(id string) error {
+	m.mu.Lock()
+	c := m.chats[id]
+	if c == nil || c.session.Status != StatusNegotiating {
+		m.mu.Unlock()
+		return fmt.Errorf("direct chat was closed")
+	}
+	c.session.Token, c.session.Address, c.session.Port = passiveToken(), "0", 0
+	session := c.session
+	m.mu.Unlock()
+	if err := m.sendControl(session.NetworkID, session.Peer, FormatChat("0", 0, session.Token)); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(m.ctx, defaultNegotiationTimeout)
+	m.setChatCancel(id, cancel)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		<-ctx.Done()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			m.finishChat(id, context.Background(), fmt.Errorf("the other client did not accept the direct chat"))
+		}
+	}()
+	return nil
+}
+
+// acceptChat waits for the peer to connect to listener, which it owns.
+func (m *Manager) acceptChat(id string, listener *net.TCPListener) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.setChatCancel(id, cancel)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer listener.Close()
+		go func() { <-ctx.Done(); _ = listener.Close() }()
+		_ = listener.SetDeadline(time.Now().Add(defaultNegotiationTimeout))
+		conn, err := listener.AcceptTCP()
+		if err != nil {
+			m.finishChat(id, ctx, err)
+			return
+		}
+		m.runChat(ctx, id, conn)
+	}()
+}
+
+func (m *Manager) dialChat(id string, offer Offer) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.setChatCancel(id, cancel)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ip, err := ParseAddress(offer.Address)
+		if err != nil {
+			m.finishChat(id, ctx, err)
+			return
+		}
+		conn, err := (&net.Dialer{Timeout: 20 * time.Second}).DialContext(ctx, "tcp", net.JoinHostPort(ip.String(), strconv.Itoa(offer.Port)))
+		if err != nil {
+			m.finishChat(id, ctx, err)
+			return
+		}
+		m.runChat(ctx, id, conn)
+	}()
+}
+
+func (m *Manager) handleChatControl(networkID int64, networkName, peer string, offer Offer) error {
+	if offer.Token != "" && offer.Port > 0 {
+		// The peer answered our passive offer with the address to dial.
+		m.mu.Lock()
+		var id string
+		for candidate, c := range m.chats {
+			if c.session.Direction == DirectionOutgoing && c.session.NetworkID == networkID && strings.EqualFold(c.session.Peer, peer) &&
+				c.session.Token == offer.Token && c.session.Status == StatusNegotiating {
+				id = candidate
+				c.session.Address, c.session.Port = offer.Address, offer.Port
+				c.session.Status, c.session.UpdatedAt = StatusConnecting, time.Now()
+				break
+			}
+		}
+		var session ChatSession
+		var waiter context.CancelFunc
+		if id != "" {
+			session = m.chats[id].session
+			waiter, m.chats[id].cancel = m.chats[id].cancel, nil
+		}
+		m.mu.Unlock()
+		if id == "" {
+			return fmt.Errorf("no matching direct chat offer")
+		}
+		if waiter != nil {
+			waiter()
+		}
+		m.chatChanged(session)
+		m.dialChat(id, offer)
+		return nil
+	}
+
+	m.mu.Lock()
+	for _, c := range m.chats {
+		if c.session.Direction == DirectionIncoming && c.session.Status == StatusOffered &&
+			c.session.NetworkID == networkID && strings.EqualFold(c.session.Peer, peer) {
+			c.offer = offer
+			c.session.Address, c.session.Port, c.session.Token = offer.Address, offer.Port, offer.Token
+			c.session.UpdatedAt = time.Now()
+			session := c.session
+			m.mu.Unlock()
+			m.chatChanged(session)
+			return nil
+		}
+	}
+	now, id := time.Now(), newID()
+	c := &chatConn{offer: offer, session: ChatSession{
+		ID: id, NetworkID: networkID, NetworkName: networkName, Peer: peer,
+		Direction: DirectionIncoming, Status: StatusOffered,
+		Address: offer.Address, Port: offer.Port, Token: offer.Token,
+		CreatedAt: now, UpdatedAt: now,
+	}}
+	m.chats[id] = c
+	session := c.session
+	m.mu.Unlock()
+	m.chatChanged(session)
+	return nil
+}
+
+func (m *Manager) AcceptChat(id string) error {
+	m.mu.Lock()
+	if !m.settings.DirectChat {
+		m.mu.Unlock()
+		return fmt.Errorf("direct chat is turned off")
+	}
+	c := m.chats[id]
+	if c == nil || c.session.Direction != DirectionIncoming || c.session.Status != StatusOffered {
+		m.mu.Unlock()
+		return fmt.Errorf("direct chat offer was not found")
+	}
+	c.session.Status, c.session.UpdatedAt = StatusConnecting, time.Now()
+	offer, session, settings := c.offer, c.session, m.settings
+	m.mu.Unlock()
+	m.chatChanged(session)
+
+	if !offer.Passive {
+		m.dialChat(id, offer)
+		return nil
+	}
+	err := func() error {
+		if settings.AdvertisedAddress == "" {
+			return fmt.Errorf("an advertised address is required to accept this passive chat")
+		}
+		address, err := FormatAddress(settings.AdvertisedAddress)
+		if err != nil {
+			return err
+		}
+		listener, err := listen(settings)
+		if err != nil {
+			return err
+		}
+		port := listener.Addr().(*net.TCPAddr).Port
+		if err := m.sendControl(session.NetworkID, session.Peer, FormatChat(address, port, offer.Token)); err != nil {
+			_ = listener.Close()
+			return err
+		}
+		m.acceptChat(id, listener)
+		return nil
+	}()
+	if err != nil {
+		m.finishChat(id, context.Background(), err)
+		return err
+	}
+	return nil
+}
+
+func (m *Manager) DeclineChat(id string) error {
+	m.mu.Lock()
+	c := m.chats[id]
+	if c == nil || c.session.Status != StatusOffered {
+		m.mu.Unlock()
+		return fmt.Errorf("direct chat offer was not found")
+	}
+	delete(m.chats, id)
+	c.session.Status, c.session.UpdatedAt = StatusDeclined, time.Now()
+	session := c.session
+	m.mu.Unlock()
+	m.chatChanged(session)
+	return nil
+}
+
+// CloseChat ends a session in any open state.
+func (m *Manager) CloseChat(id string) error {
+	m.mu.Lock()
+	c := m.chats[id]
+	if c == nil {
+		m.mu.Unlock()
+		return fmt.Errorf("direct chat was not found")
+	}
+	delete(m.chats, id)
+	c.session.Status, c.session.Error, c.session.UpdatedAt = StatusClosed, "", time.Now()
+	session, cancel, conn := c.session, c.cancel, c.conn
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if conn != nil {
+		_ = conn.Close()
+	}
+	m.chatChanged(session)
+	return nil
+}
+
+func (m *Manager) CloseAllChats() {
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.chats))
+	for id := range m.chats {
+		ids = append(ids, id)
+	}
+	m.mu.RUnlock()
+	for _, id := range ids {
+		_ = m.CloseChat(id)
+	}
+}
+
+// SendChat writes text to a connected session, one protocol line per input
+// line. Nothing is sent through the IRC server.
+func (m *Manager) SendChat(id, text string, action bool) error {
+	m.mu.RLock()
+	c := m.chats[id]
+	var conn net.Conn
+	if c != nil && c.session.Status == StatusConnected {
+		conn = c.conn
+	}
+	m.mu.RUnlock()
+	if conn == nil {
+		return fmt.Errorf("direct chat is not connected")
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" && !action {
+			continue
+		}
+		if action {
+			line = "\x01ACTION " + line + "\x01"
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = conn.SetWriteDeadline(time.Now().Add(chatWriteTimeout))
+	if err := writeAll(conn, []byte(b.String())); err != nil {
+		return fmt.Errorf("send direct chat: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) runChat(ctx context.Context, id string, conn net.Conn) {
+	defer conn.Close()
+	m.mu.Lock()
+	c := m.chats[id]
+	if c == nil || (c.session.Status != StatusNegotiating && c.session.Status != StatusConnecting) {
+		m.mu.Unlock()
+		return
+	}
+	c.conn = conn
+	c.session.Status, c.session.Error, c.session.UpdatedAt = StatusConnected, "", time.Now()
+	session, onLine := c.session, m.chatLine
+	m.mu.Unlock()
+	m.chatChanged(session)
+
+	stopWatching := watchConnectionCancellation(ctx, conn)
+	defer stopWatching()
+	err := readChatLines(conn, func(text string, action bool) {
+		if onLine != nil {
+			onLine(session, text, action)
+		}
+	})
+	m.finishChat(id, ctx, err)
+}
+
+// readChatLines delivers newline-terminated lines until the peer disconnects.
+// A clean EOF returns nil.
+func readChatLines(r io.Reader, deliver func(text string, action bool)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxChatLineBytes)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "\x01ACTION") {
+			text := strings.TrimSuffix(strings.TrimPrefix(line, "\x01ACTION"), "\x01")
+			deliver(strings.TrimPrefix(text, " "), true)
+			continue
+		}
+		deliver(line, false)
+	}
+	return scanner.Err()
+}
+
+// finishChat records how a session ended and drops it from the manager. A nil
+// error means the peer closed the connection.
+func (m *Manager) finishChat(id string, ctx context.Context, err error) {
+	m.mu.Lock()
+	c := m.chats[id]
+	if c == nil {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.chats, id)
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled) || err == nil:
+		c.session.Status, c.session.Error = StatusClosed, ""
+	default:
+		c.session.Status, c.session.Error = StatusFailed, chatError(err)
+	}
+	c.session.UpdatedAt = time.Now()
+	session, cancel := c.session, c.cancel
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	m.chatChanged(session)
+}
+
+func (m *Manager) setChatCancel(id string, cancel context.CancelFunc) {
+	m.mu.Lock()
+	c := m.chats[id]
+	if c == nil {
+		m.mu.Unlock()
+		cancel()
+		return
+	}
+	if old := c.cancel; old != nil {
+		old()
+	}
+	c.cancel = cancel
+	m.mu.Unlock()
+}
+
+func (m *Manager) chatChanged(session ChatSession) {
+	m.mu.RLock()
+	emit := m.chatEmit
+	m.mu.RUnlock()
+	if emit != nil {
+		v := session.View()
+		emit(ChatEvent{Type: "upsert", Chat: &v})
+	}
+}
+
+func chatError(err error) string {
+	if errors.Is(err, bufio.ErrTooLong) {
+		return "The other client sent a line that was too long"
+	}
+	msg := userError(err)
+	if msg == "The direct connection failed" || msg == "The transfer failed" {
+		return "The direct chat connection failed"
+	}
+	return msg
+}
@@ -41,8 +41,16 @@ type Manager struct {
 	cancels  map[string]context.CancelFunc
 	offers   map[string]Offer
 
+	// outgoingQueue orders queued and paused sends; outgoingActive holds the
+	// sends occupying a concurrency slot. offline networks are skipped until
+	// App reports them connected.
 	outgoingQueue  []string
-	outgoingActive string
+	outgoingActive map[string]bool
+	offline        map[int64]bool
+
+	chats    map[string]*chatConn
+	chatLine ChatLineFunc
+	chatEmit ChatEmitFunc
 
 	sendControl SendControlFunc
 	persist     PersistFunc
@@ -50,6 +58,9 @@ type Manager struct {
 	emit        EmitFunc
 	lastEmit    map[string]time.Time
 	lastPersist map[string]time.Time
+
+	global  *tokenBucket
+	buckets map[string]*tokenBucket
 }
 
 func NewManager(settings Settings, send SendControlFunc, persist PersistFunc, remove RemoveFunc, emit EmitFunc) (*Manager, error) {
@@ -60,12 +71,17 @@ func NewManager(settings Settings, send SendControlFunc, persist PersistFunc, re
 		send = func(int64, string, string) error { return fmt.Errorf("IRC negotiation is unavailable") }
 	}
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Manager{
+	m := &Manager{
 		ctx: ctx, cancel: cancel, settings: settings,
 		items: make(map[string]*Transfer), cancels: make(map[string]context.CancelFunc),
-		offers: make(map[string]Offer), sendControl: send, persist: persist,
+		offers: make(map[string]Offer), chats: make(map[string]*chatConn), sendControl: send, persist: persist,
 		remove: remove, emit: emit, lastEmit: make(map[string]time.Time), lastPersist: make(map[string]time.Time),
-	}, nil
+		global: newTokenBucket(settings.GlobalRateLimit), buckets: make(map[string]*tokenBucket),
+		outgoingActive: make(map[string]bool), offline: make(map[int64]bool),
+	}
+	m.wg.Add(1)
+	go m.watchStalls()
+	return m, nil
 }
 
 func ValidateSettings(s Settings) error {
@@ -78,6 +94,9 @@ func ValidateSettings(s Settings) error {
 	if s.DownloadDirectory == "" {
 		return fmt.Errorf("download directory is required")
 	}
+	if s.MaxConcurrentSends < 0 || s.MaxSendsPerPeer < 0 {
+		return fmt.Errorf("invalid send concurrency limit")
+	}
 	if (s.PortMin == 0) != (s.PortMax == 0) || s.PortMin < 0 || s.PortMax < 0 || s.PortMin > 65535 || s.PortMax > 65535 || s.PortMin > s.PortMax {
 		return fmt.Errorf("invalid port range")
 	}
@@ -89,18 +108,32 @@ func ValidateSettings(s Settings) error {
 			return fmt.Errorf("unsafe advertised address")
 		}
 	}
-	return nil
+	if s.TransferRateLimit < 0 || s.GlobalRateLimit < 0 {
+		return fmt.Errorf("invalid bandwidth limit")
+	}
+	return validatePolicy(s.AutoAccept)
 }
 
+// Restore loads persisted transfers at startup. Outgoing files whose source is
+// still present rejoin the send queue in their saved order; their networks
+// stay offline until SetNetworkOnline so nothing is offered before connecting.
 func (m *Manager) Restore(transfers []Transfer) {
 	m.mu.Lock()
 	changed := make([]Transfer, 0)
+	requeued := make([]*Transfer, 0)
 	for i := range transfers {
 		t := transfers[i]
 		if t.Status.Active() {
 			now := time.Now()
 			if t.Direction == DirectionIncoming && t.PartialPath != "" && t.TransferredBytes > 0 {
 				t.Status, t.Resumable = StatusResumable, true
+			} else if t.Direction == DirectionOutgoing && sourceAvailable(t.LocalPath, t.TotalBytes) {
+				if t.Status != StatusPaused {
+					t.Status = StatusQueued
+				}
+				t.TransferredBytes, t.ResumeOffset, t.Error = 0, 0, ""
+				requeued = append(requeued, &t)
+				m.offline[t.NetworkID] = true
 			} else {
 				t.Status = StatusFailed
 				t.Error = "Cascade closed before the transfer finished"
@@ -111,6 +144,20 @@ func (m *Manager) Restore(transfers []Transfer) {
 		}
 		m.items[t.ID] = &t
 	}
+	sort.SliceStable(requeued, func(i, j int) bool {
+		a, b := requeued[i], requeued[j]
+		if (a.QueuePosition == 0) != (b.QueuePosition == 0) {
+			return b.QueuePosition == 0
+		}
+		if a.QueuePosition != b.QueuePosition {
+			return a.QueuePosition < b.QueuePosition
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	for _, t := range requeued {
+		m.outgoingQueue = append(m.outgoingQueue, t.ID)
+	}
+	changed = append(changed, m.renumberQueueLocked()...)
 	m.mu.Unlock()
 	for _, transfer := range changed {
 		m.changed(transfer, true)
@@ -127,9 +174,19 @@ func (m *Manager) UpdateSettings(settings Settings, cancelActive bool) error {
 	if err := ValidateSettings(settings); err != nil {
 		return err
 	}
+	if !settings.DirectChat {
+		defer m.CloseAllChats()
+	}
+	// Caps apply to running transfers immediately.
+	m.global.SetRate(settings.GlobalRateLimit)
+	m.mu.RLock()
+	for _, bucket := range m.buckets {
+		bucket.SetRate(settings.TransferRateLimit)
+	}
+	m.mu.RUnlock()
 	if !settings.Enabled {
 		m.mu.RLock()
-		active := len(m.cancels) > 0 || m.outgoingActive != "" || len(m.outgoingQueue) > 0
+		active := len(m.cancels) > 0 || len(m.outgoingActive) > 0 || len(m.outgoingQueue) > 0
 		if !active {
 			for _, transfer := range m.items {
 				if transfer.Status.Active() {
@@ -155,6 +212,8 @@ func (m *Manager) UpdateSettings(settings Settings, cancelActive bool) error {
 	m.mu.Lock()
 	m.settings = settings
 	m.mu.Unlock()
+	// Raised concurrency limits take effect without waiting for a send to end.
+	m.startNextOutgoing()
 	return nil
 }
 
@@ -186,15 +245,24 @@ func (m *Manager) Get(id string) (Transfer, bool) {
 // HandleControl consumes the arguments after the CTCP DCC verb.
 func (m *Manager) HandleControl(networkID int64, networkName, peer, payload string) error {
 	m.mu.RLock()
-	enabled := m.settings.Enabled
+	settings := m.settings
 	m.mu.RUnlock()
-	if !enabled {
+	if !settings.Enabled && !settings.DirectChat {
 		return nil
 	}
 	offer, err := Parse(payload)
 	if err != nil {
 		return err
 	}
+	if offer.Command == CommandChat {
+		if !settings.DirectChat {
+			return nil
+		}
+		return m.handleChatControl(networkID, networkName, peer, offer)
+	}
+	if !settings.Enabled {
+		return nil
+	}
 	switch offer.Command {
 	case CommandSend:
 		if offer.Token != "" && offer.Port > 0 {
@@ -207,9 +275,6 @@ func (m *Manager) HandleControl(networkID int64, networkName, peer, payload stri
 		return m.handleResumeRequest(networkID, peer, offer)
 	case CommandAccept:
 		return m.handleResumeAccept(networkID, peer, offer)
-	case CommandChat:
-		// Direct Chat is intentionally feature-gated for its follow-up phase.
-		return nil
 	default:
 		return fmt.Errorf("unsupported DCC control message")
 	}
@@ -283,7 +348,10 @@ func (m *Manager) QueueOutgoing(networkID int64, networkName, peer string, paths
 		}
 		m.items[id] = t
 		m.outgoingQueue = append(m.outgoingQueue, id)
-		created = append(created, t.View())
+	}
+	m.renumberQueueLocked()
+	for _, id := range m.outgoingQueue[len(m.outgoingQueue)-len(selected):] {
+		created = append(created, m.items[id].View())
 	}
 	m.mu.Unlock()
 	for _, v := range created {
@@ -295,36 +363,9 @@ func (m *Manager) QueueOutgoing(networkID int64, networkName, peer string, paths
 	return created, nil
 }
 
-func (m *Manager) startNextOutgoing() {
-	m.mu.Lock()
-	if m.outgoingActive != "" || len(m.outgoingQueue) == 0 || !m.settings.Enabled {
-		m.mu.Unlock()
-		return
-	}
-	id := m.outgoingQueue[0]
-	m.outgoingQueue = m.outgoingQueue[1:]
-	t := m.items[id]
-	if t == nil || t.Status != StatusQueued {
-		m.mu.Unlock()
-		m.startNextOutgoing()
-		return
-	}
-	m.outgoingActive = id
-	settings := m.settings
-	t.Status, t.UpdatedAt = StatusNegotiating, time.Now()
-	copyT := *t
-	m.mu.Unlock()
-	m.changed(copyT, true)
-
-	mode := settings.ConnectionMode
-	if mode == ConnectionAutomatic {
-		if settings.AdvertisedAddress == "" {
-			mode = ConnectionPassive
-		} else {
-			mode = ConnectionClassic
-		}
-	}
-	if mode == ConnectionPassive {
+// negotiateOutgoing offers a send the scheduler has just claimed.
+func (m *Manager) negotiateOutgoing(id string, settings Settings) {
+	if settings.outgoingMode() == ConnectionPassive {
 		m.beginPassiveOutgoing(id)
 		return
 	}
@@ -646,12 +687,15 @@ func (m *Manager) runSend(ctx context.Context, id string, conn net.Conn) {
 	}
 	stopWatching := watchConnectionCancellation(ctx, conn)
 	defer stopWatching()
-	err := sendFile(ctx, conn, t.LocalPath, t.ResumeOffset, t.TotalBytes, func(n int64) { m.progress(id, n) })
+	err := sendFile(ctx, conn, t.LocalPath, t.ResumeOffset, t.TotalBytes, m.bandwidth(id), func(n int64) { m.progress(id, n) })
 	if err != nil {
 		m.finishWithError(id, ctx, err)
 		return
 	}
-	m.complete(id)
+	// The sender hashes its source so the user can compare digests with the
+	// receiver. A hashing failure does not undo a finished transfer.
+	sum, _ := fileSHA256(t.LocalPath)
+	m.complete(id, sum)
 }
 
 func (m *Manager) runReceive(ctx context.Context, id string, conn net.Conn) {
@@ -664,7 +708,7 @@ func (m *Manager) runReceive(ctx context.Context, id string, conn net.Conn) {
 	}
 	stopWatching := watchConnectionCancellation(ctx, conn)
 	defer stopWatching()
-	err := receiveFile(ctx, conn, t.PartialPath, t.ResumeOffset, t.TotalBytes, func(n int64) { m.progress(id, n) })
+	err := receiveFile(ctx, conn, t.PartialPath, t.ResumeOffset, t.TotalBytes, m.bandwidth(id), func(n int64) { m.progress(id, n) })
 	if err != nil {
 		m.finishWithError(id, ctx, err)
 		return
@@ -673,7 +717,18 @@ func (m *Manager) runReceive(ctx context.Context, id string, conn net.Conn) {
 		m.fail(id, err)
 		return
 	}
-	m.complete(id)
+	sum, _ := fileSHA256(t.LocalPath)
+	m.complete(id, sum)
+}
+
+// bandwidth returns the buckets for one running transfer. The per-transfer
+// bucket is tracked so a settings change reaches transfers already in flight.
+func (m *Manager) bandwidth(id string) bandwidth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := newTokenBucket(m.settings.TransferRateLimit)
+	m.buckets[id] = bucket
+	return bandwidth{transfer: bucket, global: m.global}
 }
 
 func watchConnectionCancellation(ctx context.Context, conn net.Conn) func() {
@@ -692,6 +747,7 @@ func (m *Manager) markTransferring(id string) bool {
 	m.mu.Lock()
 	if t := m.items[id]; t != nil && (t.Status == StatusNegotiating || t.Status == StatusConnecting) {
 		t.Status, t.Error, t.UpdatedAt = StatusTransferring, "", time.Now()
+		t.LastProgressAt = t.UpdatedAt
 		copyT := *t
 		m.mu.Unlock()
 		m.changed(copyT, true)
@@ -704,11 +760,23 @@ func (m *Manager) markTransferring(id string) bool {
 func (m *Manager) progress(id string, transferred int64) {
 	m.mu.Lock()
 	t := m.items[id]
-	if t == nil || t.Status != StatusTransferring {
+	if t == nil || (t.Status != StatusTransferring && t.Status != StatusStalled) {
 		m.mu.Unlock()
 		return
 	}
 	now := time.Now()
+	recovered := false
+	if t.Status == StatusStalled {
+		// Bytes are moving again; the send takes a slot back even if the
+		// scheduler has since filled it.
+		t.Status, recovered = StatusTransferring, true
+		if t.Direction == DirectionOutgoing {
+			m.outgoingActive[id] = true
+		}
+	}
+	if transferred != t.TransferredBytes {
+		t.LastProgressAt = now
+	}
 	deltaBytes := transferred - t.TransferredBytes
 	deltaTime := now.Sub(t.UpdatedAt)
 	if deltaTime > 0 && deltaBytes >= 0 {
@@ -719,8 +787,8 @@ func (m *Manager) progress(id string, transferred int64) {
 	}
 	t.TransferredBytes, t.UpdatedAt = transferred, now
 	copyT := *t
-	force := now.Sub(m.lastEmit[id]) >= progressInterval
-	checkpoint := now.Sub(m.lastPersist[id]) >= time.Second
+	force := recovered || now.Sub(m.lastEmit[id]) >= progressInterval
+	checkpoint := recovered || now.Sub(m.lastPersist[id]) >= time.Second
 	if force {
 		m.lastEmit[id] = now
 	}
@@ -737,16 +805,17 @@ func (m *Manager) progress(id string, transferred int64) {
 	}
 }
 
-func (m *Manager) complete(id string) {
+func (m *Manager) complete(id, sum string) {
 	now := time.Now()
 	m.mu.Lock()
 	t := m.items[id]
-	if t == nil || t.Status != StatusTransferring {
+	if t == nil || (t.Status != StatusTransferring && t.Status != StatusStalled) {
 		m.mu.Unlock()
 		return
 	}
 	t.Status, t.Error, t.UpdatedAt, t.CompletedAt = StatusCompleted, "", now, &now
 	t.TransferredBytes, t.SpeedBPS, t.ETASeconds, t.Resumable = t.TotalBytes, 0, 0, false
+	t.SHA256 = sum
 	if t.Direction == DirectionIncoming {
 		t.FileAvailable = true
 	}
@@ -827,12 +896,8 @@ func (m *Manager) Cancel(id string) error {
 		m.mu.Unlock()
 		return fmt.Errorf("active transfer was not found")
 	}
-	for i, queued := range m.outgoingQueue {
-		if queued == id {
-			m.outgoingQueue = append(m.outgoingQueue[:i], m.outgoingQueue[i+1:]...)
-			break
-		}
-	}
+	m.removeFromQueueLocked(id)
+	renumbered := m.renumberQueueLocked()
 	cancel := m.cancels[id]
 	now := time.Now()
 	t.Status, t.UpdatedAt = StatusCanceled, now
@@ -855,6 +920,7 @@ func (m *Manager) Cancel(id string) error {
 		_ = os.Remove(cleanupPartial)
 	}
 	m.changed(copyT, true)
+	m.persistAll(renumbered)
 	m.advanceOutgoing(id)
 	return nil
 }
@@ -892,6 +958,7 @@ func (m *Manager) Retry(id string) error {
 	t.Status, t.Error, t.TransferredBytes, t.ResumeOffset, t.UpdatedAt = StatusQueued, "", 0, 0, time.Now()
 	t.CompletedAt, t.Resumable = nil, false
 	m.outgoingQueue = append(m.outgoingQueue, id)
+	m.renumberQueueLocked()
 	copyT := *t
 	m.mu.Unlock()
 	m.changed(copyT, true)
@@ -983,10 +1050,11 @@ func (m *Manager) Locate(id, path string) error {
 
 func (m *Manager) Close() {
 	m.mu.Lock()
-	m.settings.Enabled = false
+	m.settings.Enabled, m.settings.DirectChat = false, false
 	m.mu.Unlock()
 	m.cancel()
 	m.CancelAll()
+	m.CloseAllChats()
 	done := make(chan struct{})
 	go func() { m.wg.Wait(); close(done) }()
 	select {
@@ -1018,13 +1086,12 @@ func (m *Manager) clearWorkerLocked(id string) {
 	delete(m.cancels, id)
 	delete(m.lastEmit, id)
 	delete(m.lastPersist, id)
+	delete(m.buckets, id)
 }
 
 func (m *Manager) advanceOutgoing(id string) {
 	m.mu.Lock()
-	if m.outgoingActive == id {
-		m.outgoingActive = ""
-	}
+	delete(m.outgoingActive, id)
 	m.mu.Unlock()
 	m.startNextOutgoing()
 }
@@ -1039,6 +1106,18 @@ func (m *Manager) changed(t Transfer, force bool) {
 	}
 }
 
+// outgoingMode resolves Automatic compatibility mode: classic when we can
+// advertise a reachable address, passive/reverse otherwise.
+func (s Settings) outgoingMode() ConnectionMode {
+	if s.ConnectionMode != ConnectionAutomatic {
+		return s.ConnectionMode
+	}
+	if s.AdvertisedAddress == "" {
+		return ConnectionPassive
+	}
+	return ConnectionClassic
+}
+
 func listen(settings Settings) (*net.TCPListener, error) {
 	if settings.PortMin == 0 {
 		return net.ListenTCP("tcp", &net.TCPAddr{Port: 0})
@@ -1,11 +1,13 @@
 package dcc
 
 import (
+	"bufio"
 	"context"
 	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -103,8 +105,8 @@ func TestTransportRoundTrip(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	errCh := make(chan error, 2)
-	go func() { errCh <- sendFile(ctx, left, source, 0, int64(len(want)), func(int64) {}) }()
-	go func() { errCh <- receiveFile(ctx, right, partial, 0, int64(len(want)), func(int64) {}) }()
+	go func() { errCh <- sendFile(ctx, left, source, 0, int64(len(want)), bandwidth{}, func(int64) {}) }()
+	go func() { errCh <- receiveFile(ctx, right, partial, 0, int64(len(want)), bandwidth{}, func(int64) {}) }()
 	for range 2 {
 		if err := <-errCh; err != nil {
 			t.Fatal(err)
@@ -119,13 +121,53 @@ func TestTransportRoundTrip(t *testing.T) {
 	}
 }
 
+func TestTransportHonorsRateLimitAndHashes(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.bin")
+	partial := filepath.Join(dir, "received.part")
+	want := make([]byte, 96*1024)
+	for i := range want {
+		want[i] = byte(i % 13)
+	}
+	if err := os.WriteFile(source, want, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	left, right := net.Pipe()
+	defer left.Close()
+	defer right.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// 64 KiB/s with a one-second burst leaves 32 KiB to pace: about half a second.
+	bw := bandwidth{transfer: newTokenBucket(64 * 1024)}
+	start := time.Now()
+	errCh := make(chan error, 2)
+	go func() { errCh <- sendFile(ctx, left, source, 0, int64(len(want)), bw, func(int64) {}) }()
+	go func() { errCh <- receiveFile(ctx, right, partial, 0, int64(len(want)), bandwidth{}, func(int64) {}) }()
+	for range 2 {
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Fatalf("rate-limited transfer finished in %v", elapsed)
+	}
+	sent, err := fileSHA256(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received, err := fileSHA256(partial)
+	if err != nil || received != sent || len(sent) != 64 {
+		t.Fatalf("checksums differ: sent %s received %s (%v)", sent, received, err)
+	}
+}
+
 func TestReceiveRejectsShortFile(t *testing.T) {
 	left, right := net.Pipe()
 	go func() {
 		_, _ = io.WriteString(left, "short")
 		_ = left.Close()
 	}()
-	err := receiveFile(context.Background(), right, filepath.Join(t.TempDir(), "x.part"), 0, 100, func(int64) {})
+	err := receiveFile(context.Background(), right, filepath.Join(t.TempDir(), "x.part"), 0, 100, bandwidth{}, func(int64) {})
 	if err == nil {
 		t.Fatal("expected short transfer error")
 	}
@@ -139,7 +181,7 @@ func TestSendRejectsChangedSourceSize(t *testing.T) {
 	left, right := net.Pipe()
 	defer left.Close()
 	defer right.Close()
-	if err := sendFile(context.Background(), left, path, 0, 99, func(int64) {}); err == nil {
+	if err := sendFile(context.Background(), left, path, 0, 99, bandwidth{}, func(int64) {}); err == nil {
 		t.Fatal("expected changed source size to be rejected")
 	}
 }
@@ -206,3 +248,103 @@ func TestProgressEventsAreThrottled(t *testing.T) {
 		t.Fatalf("100 immediate progress samples emitted %d events", got)
 	}
 }
+
+func TestPassiveChatOfferWaitsForReply(t *testing.T) {
+	s := testSettings(t)
+	s.DirectChat = true
+	var mu sync.Mutex
+	var controls []string
+	m, err := NewManager(s, func(_ int64, _ string, payload string) error {
+		mu.Lock()
+		controls = append(controls, payload)
+		mu.Unlock()
+		return nil
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	view, err := m.OpenChat(1, "net", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Status != StatusNegotiating || view.Direction != DirectionOutgoing {
+		t.Fatalf("unexpected session: %+v", view)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(controls) != 1 || !strings.HasPrefix(controls[0], "CHAT chat 0 0 ") {
+		t.Fatalf("passive chat offer = %q", controls)
+	}
+}
+
+func TestChatSessionExchangesLines(t *testing.T) {
+	s := testSettings(t)
+	s.DirectChat = true
+	m, err := NewManager(s, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	type line struct {
+		text   string
+		action bool
+	}
+	lines := make(chan line, 4)
+	statuses := make(chan Status, 8)
+	m.SetChatHandlers(
+		func(_ ChatSession, text string, action bool) { lines <- line{text, action} },
+		func(event ChatEvent) { statuses <- event.Chat.Status },
+	)
+	if err := m.HandleControl(1, "net", "alice", `CHAT chat 3405803781 5000`); err != nil {
+		t.Fatal(err)
+	}
+	chats := m.ChatSnapshot()
+	if len(chats) != 1 || chats[0].Status != StatusOffered {
+		t.Fatalf("incoming offer not recorded: %+v", chats)
+	}
+	id := chats[0].ID
+	<-statuses
+
+	// Stand in for the dial so the session runs over an in-memory pipe.
+	m.mu.Lock()
+	m.chats[id].session.Status = StatusConnecting
+	m.mu.Unlock()
+	local, remote := net.Pipe()
+	defer remote.Close()
+	done := make(chan struct{})
+	go func() { defer close(done); m.runChat(context.Background(), id, local) }()
+	if got := <-statuses; got != StatusConnected {
+		t.Fatalf("status = %s, want connected", got)
+	}
+
+	go func() { _, _ = remote.Write([]byte("hello there\r\n\x01ACTION waves\x01\n")) }()
+	for _, want := range []line{{"hello there", false}, {"waves", true}} {
+		select {
+		case got := <-lines:
+			if got != want {
+				t.Fatalf("line = %+v, want %+v", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for chat line")
+		}
+	}
+
+	go func() { _ = m.SendChat(id, "hi\nbye", false) }()
+	reader := bufio.NewReader(remote)
+	for _, want := range []string{"hi\n", "bye\n"} {
+		got, err := reader.ReadString('\n')
+		if err != nil || got != want {
+			t.Fatalf("peer read %q, %v; want %q", got, err, want)
+		}
+	}
+
+	_ = remote.Close()
+	<-done
+	if got := <-statuses; got != StatusClosed {
+		t.Fatalf("status = %s, want closed", got)
+	}
+	if _, ok := m.GetChat(id); ok {
+		t.Fatal("finished chat was not removed")
+	}
+}
@@ -0,0 +1,79 @@
+package dcc
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Allows reports whether an offer may be accepted without asking. buddy is
+// whether peer is on the network's buddy list; App owns that lookup.
+func (p AutoAcceptPolicy) Allows(peer, filename string, size int64, buddy bool) bool {
+	switch p.Mode {
+	case AutoAcceptPeers:
+		if !containsFold(p.Peers, peer) {
+			return false
+		}
+	case AutoAcceptBuddies:
+		if !buddy {
+			return false
+		}
+	default:
+		return false
+	}
+	if p.MaxBytes > 0 && size > p.MaxBytes {
+		return false
+	}
+	if len(p.Extensions) == 0 && len(p.Filenames) == 0 {
+		return true
+	}
+	name := strings.ToLower(filename)
+	for _, ext := range p.Extensions {
+		if ext = normalizeExtension(ext); ext != "" && strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	for _, pattern := range p.Filenames {
+		if ok, err := filepath.Match(strings.ToLower(pattern), name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePolicy(p AutoAcceptPolicy) error {
+	switch p.Mode {
+	case "", AutoAcceptOff, AutoAcceptPeers, AutoAcceptBuddies:
+	default:
+		return fmt.Errorf("invalid auto-accept mode")
+	}
+	if p.MaxBytes < 0 {
+		return fmt.Errorf("invalid auto-accept size limit")
+	}
+	for _, pattern := range p.Filenames {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid auto-accept filename pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext == "" {
+		return ""
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+	return false
+}
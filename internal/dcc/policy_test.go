@@ -0,0 +1,45 @@
+package dcc
+
+import "testing"
+
+func TestAutoAcceptPolicy(t *testing.T) {
+	peers := AutoAcceptPolicy{Mode: AutoAcceptPeers, Peers: []string{"Alice"}, MaxBytes: 1000, Extensions: []string{"png", ".JPG"}, Filenames: []string{"notes-*.txt"}}
+	tests := []struct {
+		name     string
+		policy   AutoAcceptPolicy
+		peer     string
+		filename string
+		size     int64
+		buddy    bool
+		want     bool
+	}{
+		{"off", AutoAcceptPolicy{}, "alice", "a.png", 1, true, false},
+		{"listed peer extension", peers, "alice", "photo.PNG", 10, false, true},
+		{"listed peer pattern", peers, "alice", "notes-monday.txt", 10, false, true},
+		{"unlisted peer", peers, "bob", "photo.png", 10, true, false},
+		{"too large", peers, "alice", "photo.png", 1001, false, false},
+		{"name not allowed", peers, "alice", "setup.exe", 10, false, false},
+		{"buddy any name", AutoAcceptPolicy{Mode: AutoAcceptBuddies}, "bob", "setup.exe", 1 << 30, true, true},
+		{"not a buddy", AutoAcceptPolicy{Mode: AutoAcceptBuddies}, "bob", "a.png", 1, false, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.Allows(tc.peer, tc.filename, tc.size, tc.buddy); got != tc.want {
+				t.Fatalf("Allows() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateSettingsRejectsBadPolicy(t *testing.T) {
+	s := testSettings(t)
+	s.AutoAccept = AutoAcceptPolicy{Mode: AutoAcceptPeers, Filenames: []string{"[unterminated"}}
+	if err := ValidateSettings(s); err == nil {
+		t.Fatal("invalid glob should be rejected")
+	}
+	s = testSettings(t)
+	s.GlobalRateLimit = -1
+	if err := ValidateSettings(s); err == nil {
+		t.Fatal("negative rate limit should be rejected")
+	}
+}
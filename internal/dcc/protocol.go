@@ -67,7 +67,7 @@ func Parse(payload string) (Offer, error) {
 		}
 		return o, nil
 	case CommandChat:
-		return Offer{Command: cmd}, nil
+		return parseChatFields(fields)
 	default:
 		return Offer{}, fmt.Errorf("unsupported DCC command %q", fields[0])
 	}
@@ -132,6 +132,33 @@ func makeSendOffer(filenameRaw, address, portRaw, sizeRaw, token string) (Offer,
 	return offer, nil
 }
 
+// parseChatFields accepts "CHAT chat <address> <port> [token]". Port zero with
+// a token is the passive/reverse form, mirroring passive SEND.
+func parseChatFields(fields []string) (Offer, error) {
+	if len(fields) < 4 || len(fields) > 5 {
+		return Offer{}, fmt.Errorf("DCC CHAT expects protocol, address, port, and optional token")
+	}
+	if !strings.EqualFold(fields[1], "chat") {
+		return Offer{}, fmt.Errorf("unsupported DCC CHAT protocol %q", fields[1])
+	}
+	port, err := parsePort(fields[3], true)
+	if err != nil {
+		return Offer{}, err
+	}
+	offer := Offer{Command: CommandChat, Address: fields[2], Port: port, Passive: port == 0}
+	if len(fields) == 5 {
+		offer.Token = fields[4]
+	}
+	if offer.Passive {
+		if offer.Token == "" || offer.Address != "0" {
+			return Offer{}, fmt.Errorf("invalid passive DCC CHAT")
+		}
+	} else if _, err := ParseAddress(offer.Address); err != nil {
+		return Offer{}, err
+	}
+	return offer, nil
+}
+
 func ParseAddress(raw string) (net.IP, error) {
 	if n, err := strconv.ParseUint(raw, 10, 32); err == nil {
 		b := make([]byte, 4)
@@ -172,6 +199,14 @@ func FormatSend(filename, address string, port int, size int64, token string) st
 	return strings.Join(parts, " ")
 }
 
+func FormatChat(address string, port int, token string) string {
+	parts := []string{"CHAT", "chat", address, strconv.Itoa(port)}
+	if token != "" {
+		parts = append(parts, token)
+	}
+	return strings.Join(parts, " ")
+}
+
 func FormatResume(command Command, filename string, port int, position int64, token string) string {
 	parts := []string{string(command), quoteFilename(filename), strconv.Itoa(port), strconv.FormatInt(position, 10)}
 	if token != "" {
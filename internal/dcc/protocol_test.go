@@ -75,3 +75,34 @@ func TestLegacyAcknowledgementRollover(t *testing.T) {
 		t.Fatalf("acknowledgement after 4GiB = %d, want 123", got)
 	}
 }
+
+func TestParseChat(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    Offer
+		wantErr bool
+	}{
+		{"classic", `CHAT chat 3405803781 5000`, Offer{Command: CommandChat, Address: "3405803781", Port: 5000}, false},
+		{"passive", `CHAT chat 0 0 token-1`, Offer{Command: CommandChat, Address: "0", Port: 0, Token: "token-1", Passive: true}, false},
+		{"passive reply", `CHAT chat 3405803781 5000 token-1`, Offer{Command: CommandChat, Address: "3405803781", Port: 5000, Token: "token-1"}, false},
+		{"passive without token", `CHAT chat 0 0`, Offer{}, true},
+		{"other protocol", `CHAT wboard 3405803781 5000`, Offer{}, true},
+		{"loopback", `CHAT chat 2130706433 5000`, Offer{}, true},
+		{"missing fields", `CHAT chat`, Offer{}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.payload)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !tc.wantErr && got != tc.want {
+				t.Fatalf("Parse() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+	if got := FormatChat("0", 0, "token-1"); got != "CHAT chat 0 0 token-1" {
+		t.Fatalf("FormatChat = %q", got)
+	}
+}
@@ -0,0 +1,235 @@
+package dcc
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxConcurrentSends = 3
+	defaultMaxSendsPerPeer    = 1
+	stallCheckInterval        = 5 * time.Second
+	// stallTimeout is how long a running transfer may go without moving a byte
+	// before it is reported stalled and its send slot is released.
+	stallTimeout = 45 * time.Second
+)
+
+func (s Settings) sendLimits() (global, perPeer int) {
+	global, perPeer = s.MaxConcurrentSends, s.MaxSendsPerPeer
+	if global <= 0 {
+		global = defaultMaxConcurrentSends
+	}
+	if perPeer <= 0 {
+		perPeer = defaultMaxSendsPerPeer
+	}
+	return global, perPeer
+}
+
+func peerKey(networkID int64, peer string) string {
+	return strconv.FormatInt(networkID, 10) + "\x00" + strings.ToLower(peer)
+}
+
+// startNextOutgoing claims and offers queued sends until a limit is reached.
+func (m *Manager) startNextOutgoing() {
+	for {
+		id, settings, renumbered, ok := m.claimNextOutgoing()
+		m.persistAll(renumbered)
+		if !ok {
+			return
+		}
+		m.negotiateOutgoing(id, settings)
+	}
+}
+
+// claimNextOutgoing picks the first queued send whose network is online and
+// whose peer is under the per-peer limit, skipping paused entries so one slow
+// peer or a paused file never holds up the rest of the queue.
+func (m *Manager) claimNextOutgoing() (string, Settings, []Transfer, bool) {
+	m.mu.Lock()
+	if !m.settings.Enabled {
+		m.mu.Unlock()
+		return "", Settings{}, nil, false
+	}
+	global, perPeer := m.settings.sendLimits()
+	if len(m.outgoingActive) >= global {
+		m.mu.Unlock()
+		return "", Settings{}, nil, false
+	}
+	busy := make(map[string]int)
+	for id := range m.outgoingActive {
+		if t := m.items[id]; t != nil {
+			busy[peerKey(t.NetworkID, t.Peer)]++
+		}
+	}
+	for _, id := range m.outgoingQueue {
+		t := m.items[id]
+		if t == nil || t.Status != StatusQueued || m.offline[t.NetworkID] || busy[peerKey(t.NetworkID, t.Peer)] >= perPeer {
+			continue
+		}
+		m.removeFromQueueLocked(id)
+		m.outgoingActive[id] = true
+		t.Status, t.UpdatedAt = StatusNegotiating, time.Now()
+		copyT, settings := *t, m.settings
+		renumbered := m.renumberQueueLocked()
+		m.mu.Unlock()
+		m.changed(copyT, true)
+		return id, settings, renumbered, true
+	}
+	m.mu.Unlock()
+	return "", Settings{}, nil, false
+}
+
+func (m *Manager) removeFromQueueLocked(id string) {
+	for i, queued := range m.outgoingQueue {
+		if queued == id {
+			m.outgoingQueue = append(m.outgoingQueue[:i], m.outgoingQueue[i+1:]...)
+			break
+		}
+	}
+	if t := m.items[id]; t != nil {
+		t.QueuePosition = 0
+	}
+}
+
+// renumberQueueLocked assigns 1-based positions in queue order and returns the
+// entries whose position changed so the caller can persist them.
+func (m *Manager) renumberQueueLocked() []Transfer {
+	changed := make([]Transfer, 0)
+	kept := m.outgoingQueue[:0]
+	for _, id := range m.outgoingQueue {
+		t := m.items[id]
+		if t == nil || (t.Status != StatusQueued && t.Status != StatusPaused) {
+			continue
+		}
+		kept = append(kept, id)
+		if t.QueuePosition != len(kept) {
+			t.QueuePosition = len(kept)
+			changed = append(changed, *t)
+		}
+	}
+	m.outgoingQueue = kept
+	return changed
+}
+
+func (m *Manager) persistAll(transfers []Transfer) {
+	for _, t := range transfers {
+		m.changed(t, true)
+	}
+}
+
+// PauseOutgoing holds a queued send in place until ResumeOutgoing.
+func (m *Manager) PauseOutgoing(id string) error {
+	return m.setQueuedStatus(id, StatusQueued, StatusPaused)
+}
+
+func (m *Manager) ResumeOutgoing(id string) error {
+	if err := m.setQueuedStatus(id, StatusPaused, StatusQueued); err != nil {
+		return err
+	}
+	m.startNextOutgoing()
+	return nil
+}
+
+func (m *Manager) setQueuedStatus(id string, from, to Status) error {
+	m.mu.Lock()
+	t := m.items[id]
+	if t == nil || t.Direction != DirectionOutgoing || t.Status != from {
+		m.mu.Unlock()
+		if to == StatusPaused {
+			return fmt.Errorf("only queued transfers can be paused")
+		}
+		return fmt.Errorf("transfer is not paused")
+	}
+	t.Status, t.UpdatedAt = to, time.Now()
+	copyT := *t
+	m.mu.Unlock()
+	m.changed(copyT, true)
+	return nil
+}
+
+// MoveOutgoing moves a queued or paused send to a 1-based queue position,
+// clamped to the queue length.
+func (m *Manager) MoveOutgoing(id string, position int) error {
+	m.mu.Lock()
+	index := -1
+	for i, queued := range m.outgoingQueue {
+		if queued == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		m.mu.Unlock()
+		return fmt.Errorf("transfer is not queued")
+	}
+	m.outgoingQueue = append(m.outgoingQueue[:index], m.outgoingQueue[index+1:]...)
+	target := min(max(position-1, 0), len(m.outgoingQueue))
+	m.outgoingQueue = append(m.outgoingQueue[:target], append([]string{id}, m.outgoingQueue[target:]...)...)
+	renumbered := m.renumberQueueLocked()
+	m.mu.Unlock()
+	m.persistAll(renumbered)
+	m.startNextOutgoing()
+	return nil
+}
+
+// SetNetworkOnline tells the scheduler whether sends on a network can be
+// negotiated. Queued files for an offline network keep their place.
+func (m *Manager) SetNetworkOnline(networkID int64, online bool) {
+	m.mu.Lock()
+	if online {
+		delete(m.offline, networkID)
+	} else {
+		m.offline[networkID] = true
+	}
+	m.mu.Unlock()
+	if online {
+		m.startNextOutgoing()
+	}
+}
+
+func (m *Manager) watchStalls() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(stallCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.checkStalls(now)
+		}
+	}
+}
+
+// checkStalls marks transfers that have not moved since stallTimeout as
+// stalled. The connection stays open, but a stalled send gives up its slot so
+// the queue can move on; progress() restores it if bytes start flowing again.
+func (m *Manager) checkStalls(now time.Time) {
+	m.mu.Lock()
+	stalled := make([]Transfer, 0)
+	for id, t := range m.items {
+		if t.Status != StatusTransferring || t.LastProgressAt.IsZero() || now.Sub(t.LastProgressAt) < stallTimeout {
+			continue
+		}
+		t.Status, t.SpeedBPS, t.ETASeconds, t.UpdatedAt = StatusStalled, 0, 0, now
+		delete(m.outgoingActive, id)
+		stalled = append(stalled, *t)
+	}
+	m.mu.Unlock()
+	if len(stalled) == 0 {
+		return
+	}
+	m.persistAll(stalled)
+	m.startNextOutgoing()
+}
+
+func sourceAvailable(path string, size int64) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode().IsRegular() && info.Size() == size
+}
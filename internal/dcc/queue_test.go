@@ -0,0 +1,156 @@
+package dcc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// queueHarness records control payloads; testSettings has no advertised
+// address, so every send is offered passively without opening a listener.
+type queueHarness struct {
+	mu       sync.Mutex
+	controls []string
+}
+
+func (h *queueHarness) send(_ int64, peer, payload string) error {
+	h.mu.Lock()
+	h.controls = append(h.controls, peer+" "+payload)
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *queueHarness) offered() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.controls...)
+}
+
+func writeQueueFiles(t *testing.T, names ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name)
+		if err := os.WriteFile(paths[i], []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestQueueHonorsGlobalAndPerPeerLimits(t *testing.T) {
+	h := &queueHarness{}
+	s := testSettings(t)
+	s.MaxConcurrentSends, s.MaxSendsPerPeer = 2, 1
+	m, err := NewManager(s, h.send, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	alice := writeQueueFiles(t, "a1.txt", "a2.txt")
+	if _, err := m.QueueOutgoing(1, "net", "alice", alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.QueueOutgoing(1, "net", "bob", writeQueueFiles(t, "b1.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.QueueOutgoing(1, "net", "carol", writeQueueFiles(t, "c1.txt")); err != nil {
+		t.Fatal(err)
+	}
+	got := h.offered()
+	if len(got) != 2 || !strings.Contains(got[0], "a1.txt") || !strings.Contains(got[1], "b1.txt") {
+		t.Fatalf("offers = %q, want a1 then b1", got)
+	}
+}
+
+func TestQueuePauseAndReorder(t *testing.T) {
+	h := &queueHarness{}
+	m, err := NewManager(testSettings(t), h.send, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	views, err := m.QueueOutgoing(1, "net", "alice", writeQueueFiles(t, "one.txt", "two.txt", "three.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.PauseOutgoing(views[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.MoveOutgoing(views[2].ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	three, _ := m.Get(views[2].ID)
+	two, _ := m.Get(views[1].ID)
+	if three.QueuePosition != 1 || two.QueuePosition != 2 {
+		t.Fatalf("positions after move: three=%d two=%d", three.QueuePosition, two.QueuePosition)
+	}
+	if err := m.Cancel(views[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	got := h.offered()
+	if len(got) != 2 || !strings.Contains(got[1], "three.txt") {
+		t.Fatalf("offers = %q, want three.txt after one.txt", got)
+	}
+	if paused, _ := m.Get(views[1].ID); paused.Status != StatusPaused {
+		t.Fatalf("paused transfer was started: %s", paused.Status)
+	}
+}
+
+func TestStalledSendReleasesItsSlot(t *testing.T) {
+	h := &queueHarness{}
+	m, err := NewManager(testSettings(t), h.send, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	views, err := m.QueueOutgoing(1, "net", "alice", writeQueueFiles(t, "one.txt", "two.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.markTransferring(views[0].ID) {
+		t.Fatal("first send was not negotiating")
+	}
+	m.checkStalls(time.Now().Add(stallTimeout + time.Second))
+	if stalled, _ := m.Get(views[0].ID); stalled.Status != StatusStalled {
+		t.Fatalf("status = %s, want stalled", stalled.Status)
+	}
+	if got := h.offered(); len(got) != 2 {
+		t.Fatalf("queue did not move past the stalled send: %q", got)
+	}
+	m.progress(views[0].ID, 1)
+	if resumed, _ := m.Get(views[0].ID); resumed.Status != StatusTransferring {
+		t.Fatalf("status after progress = %s, want transferring", resumed.Status)
+	}
+}
+
+func TestRestoreRequeuesOutgoingInSavedOrder(t *testing.T) {
+	h := &queueHarness{}
+	m, err := NewManager(testSettings(t), h.send, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	paths := writeQueueFiles(t, "first.txt", "second.txt")
+	now := time.Now()
+	m.Restore([]Transfer{
+		{ID: "second", NetworkID: 1, Peer: "alice", Direction: DirectionOutgoing, Filename: "second.txt", LocalPath: paths[1], TotalBytes: int64(len("second.txt")), Status: StatusQueued, QueuePosition: 2, CreatedAt: now, UpdatedAt: now},
+		{ID: "first", NetworkID: 1, Peer: "alice", Direction: DirectionOutgoing, Filename: "first.txt", LocalPath: paths[0], TotalBytes: int64(len("first.txt")), Status: StatusTransferring, QueuePosition: 0, CreatedAt: now.Add(-time.Hour), UpdatedAt: now},
+	})
+	if got := h.offered(); len(got) != 0 {
+		t.Fatalf("restored queue started before the network connected: %q", got)
+	}
+	second, _ := m.Get("second")
+	first, _ := m.Get("first")
+	if second.QueuePosition != 1 || first.QueuePosition != 2 || first.Status != StatusQueued {
+		t.Fatalf("restored order: second=%+v first=%+v", second, first)
+	}
+	m.SetNetworkOnline(1, true)
+	if got := h.offered(); len(got) != 1 || !strings.Contains(got[0], "second.txt") {
+		t.Fatalf("offers after connect = %q", got)
+	}
+}
@@ -2,15 +2,90 @@ package dcc
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"sync"
+	"time"
 )
 
-func sendFile(ctx context.Context, conn net.Conn, path string, offset, total int64, progress func(int64)) error {
+// tokenBucket limits throughput to rate bytes per second with a one-second
+// burst. A zero rate disables it. Tokens may go negative, so a chunk larger
+// than the burst simply waits proportionally longer.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate int64) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: float64(rate), last: time.Now()}
+}
+
+func (b *tokenBucket) SetRate(rate int64) {
+	b.mu.Lock()
+	b.rate = rate
+	if b.tokens > float64(rate) {
+		b.tokens = float64(rate)
+	}
+	b.mu.Unlock()
+}
+
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	if b.rate <= 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * float64(b.rate)
+	if burst := float64(b.rate); b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / float64(b.rate) * float64(time.Second))
+	}
+	b.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// bandwidth pairs a transfer's own bucket with the manager-wide one. The zero
+// value is unthrottled.
+type bandwidth struct {
+	transfer *tokenBucket
+	global   *tokenBucket
+}
+
+func (bw bandwidth) wait(ctx context.Context, n int) error {
+	if err := bw.transfer.wait(ctx, n); err != nil {
+		return err
+	}
+	return bw.global.wait(ctx, n)
+}
+
+func sendFile(ctx context.Context, conn net.Conn, path string, offset, total int64, bw bandwidth, progress func(int64)) error {
 	f, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("open source file: %w", err)
@@ -56,6 +131,9 @@ func sendFile(ctx context.Context, conn net.Conn, path string, offset, total int
 		}
 		n, readErr := f.Read(readBuf)
 		if n > 0 {
+			if err := bw.wait(ctx, n); err != nil {
+				return err
+			}
 			if err := writeAll(conn, readBuf[:n]); err != nil {
 				return fmt.Errorf("send file: %w", err)
 			}
@@ -72,7 +150,7 @@ func sendFile(ctx context.Context, conn net.Conn, path string, offset, total int
 	return nil
 }
 
-func receiveFile(ctx context.Context, conn net.Conn, partialPath string, offset, total int64, progress func(int64)) error {
+func receiveFile(ctx context.Context, conn net.Conn, partialPath string, offset, total int64, bw bandwidth, progress func(int64)) error {
 	flags := os.O_CREATE | os.O_WRONLY
 	if offset == 0 {
 		flags |= os.O_EXCL
@@ -118,6 +196,10 @@ func receiveFile(ctx context.Context, conn net.Conn, partialPath string, offset,
 				return fmt.Errorf("acknowledge transfer: %w", err)
 			}
 			progress(received)
+			// Pacing reads lets TCP flow control slow the sender down.
+			if err := bw.wait(ctx, n); err != nil {
+				return err
+			}
 		}
 		if readErr != nil {
 			if errors.Is(readErr, io.EOF) {
@@ -132,6 +214,20 @@ func receiveFile(ctx context.Context, conn net.Conn, partialPath string, offset,
 	return f.Sync()
 }
 
+// fileSHA256 hashes a completed file so both sides can compare digests.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func legacyAcknowledgement(received int64) uint32 {
 	return uint32(uint64(received) & 0xffffffff)
 }
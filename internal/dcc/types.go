@@ -24,11 +24,17 @@ const (
 	StatusCanceled     Status = "canceled"
 	StatusDeclined     Status = "declined"
 	StatusResumable    Status = "resumable"
+	StatusPaused       Status = "paused"
+	StatusStalled      Status = "stalled"
+
+	// Direct chat sessions additionally use these two states.
+	StatusConnected Status = "connected"
+	StatusClosed    Status = "closed"
 )
 
 func (s Status) Active() bool {
 	switch s {
-	case StatusOffered, StatusQueued, StatusNegotiating, StatusConnecting, StatusTransferring:
+	case StatusOffered, StatusQueued, StatusPaused, StatusNegotiating, StatusConnecting, StatusTransferring, StatusStalled:
 		return true
 	default:
 		return false
@@ -44,6 +50,26 @@ const (
 	ConnectionPassive   ConnectionMode = "passive"
 )
 
+// AutoAcceptMode selects whose incoming offers are accepted without asking.
+type AutoAcceptMode string
+
+const (
+	AutoAcceptOff     AutoAcceptMode = "off"
+	AutoAcceptPeers   AutoAcceptMode = "peers"
+	AutoAcceptBuddies AutoAcceptMode = "buddies"
+)
+
+// AutoAcceptPolicy decides which offers are accepted into the download
+// directory unattended. Empty Extensions and Filenames allow any name; otherwise
+// a name must match one extension (".png") or one glob pattern ("*.log").
+type AutoAcceptPolicy struct {
+	Mode       AutoAcceptMode `json:"mode"`
+	Peers      []string       `json:"peers"`
+	MaxBytes   int64          `json:"maxBytes"`
+	Extensions []string       `json:"extensions"`
+	Filenames  []string       `json:"filenames"`
+}
+
 // HistoryRetention is deliberately string-valued at the Wails boundary.
 type HistoryRetention string
 
@@ -62,6 +88,14 @@ type Settings struct {
 	AdvertisedAddress string           `json:"advertisedAddress"`
 	PortMin           int              `json:"portMin"`
 	PortMax           int              `json:"portMax"`
+	DirectChat        bool             `json:"directChat"`
+	// Rate limits are bytes per second; zero leaves the link unthrottled.
+	TransferRateLimit int64            `json:"transferRateLimit"`
+	GlobalRateLimit   int64            `json:"globalRateLimit"`
+	AutoAccept        AutoAcceptPolicy `json:"autoAccept"`
+	// Send limits; zero selects the defaults (three sends, one per peer).
+	MaxConcurrentSends int `json:"maxConcurrentSends"`
+	MaxSendsPerPeer    int `json:"maxSendsPerPeer"`
 }
 
 // Transfer is the manager's complete record. LocalPath and PartialPath must not
@@ -90,6 +124,9 @@ type Transfer struct {
 	ETASeconds       int64
 	FileAvailable    bool
 	Resumable        bool
+	SHA256           string
+	QueuePosition    int
+	LastProgressAt   time.Time
 }
 
 // View is the safe, typed model sent to React.
@@ -111,6 +148,8 @@ type View struct {
 	ETASeconds       int64      `json:"etaSeconds"`
 	FileAvailable    bool       `json:"fileAvailable"`
 	Resumable        bool       `json:"resumable"`
+	SHA256           string     `json:"sha256,omitempty"`
+	QueuePosition    int        `json:"queuePosition,omitempty"`
 }
 
 func (t Transfer) View() View {
@@ -121,10 +160,64 @@ func (t Transfer) View() View {
 		Status: t.Status, Error: t.Error, CreatedAt: t.CreatedAt,
 		UpdatedAt: t.UpdatedAt, CompletedAt: t.CompletedAt,
 		SpeedBPS: t.SpeedBPS, ETASeconds: t.ETASeconds,
-		FileAvailable: t.FileAvailable, Resumable: t.Resumable,
+		FileAvailable: t.FileAvailable, Resumable: t.Resumable, SHA256: t.SHA256,
+		QueuePosition: t.QueuePosition,
+	}
+}
+
+// ChatSession is one direct chat connection. Sessions live only in memory;
+// App stores the exchanged lines as ordinary buffer messages.
+type ChatSession struct {
+	ID          string
+	NetworkID   int64
+	NetworkName string
+	Peer        string
+	Direction   Direction
+	Status      Status
+	Error       string
+	Address     string
+	Port        int
+	Token       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ChatView is the frontend model for a direct chat session.
+type ChatView struct {
+	ID          string    `json:"id"`
+	NetworkID   int64     `json:"networkId"`
+	NetworkName string    `json:"networkName"`
+	Peer        string    `json:"peer"`
+	Direction   Direction `json:"direction"`
+	Status      Status    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (c ChatSession) View() ChatView {
+	return ChatView{
+		ID: c.ID, NetworkID: c.NetworkID, NetworkName: c.NetworkName, Peer: c.Peer,
+		Direction: c.Direction, Status: c.Status, Error: c.Error,
+		CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt,
 	}
 }
 
+// Open reports whether the session is still being negotiated or connected.
+func (c ChatSession) Open() bool {
+	switch c.Status {
+	case StatusOffered, StatusNegotiating, StatusConnecting, StatusConnected:
+		return true
+	default:
+		return false
+	}
+}
+
+type ChatEvent struct {
+	Type string    `json:"type"` // upsert
+	Chat *ChatView `json:"chat,omitempty"`
+}
+
 type Event struct {
 	Type     string `json:"type"` // upsert|remove|reset
 	Transfer *View  `json:"transfer,omitempty"`
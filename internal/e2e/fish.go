@@ -0,0 +1,110 @@
+package e2e
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+// FiSH is the de facto standard for encrypting IRC channels and queries with a
+// shared Blowfish key, spoken by FiSH10 for mIRC, the weechat and irssi fish
+// scripts and mircryption. Only its CBC mode is supported: the text is
+// zero-padded to the block size, encrypted under a random IV, and sent as
+// "+OK *" followed by base64(IV || ciphertext). mircryption's "mcps " prefix is
+// accepted on receive.
+
+const (
+	fishPrefix    = "+OK "
+	fishAltPrefix = "mcps "
+	fishCBCMarker = "*"
+	// FishKeyPrefix marks a CBC key in the "cbc:<key>" form FiSH10 writes.
+	FishKeyPrefix = "cbc:"
+)
+
+// ParseFishKey validates a FiSH key as typed by the user or copied from another
+// client's config, returning the raw key. A "cbc:" prefix is stripped; "ecb:"
+// keys (the old mode) are refused.
+func ParseFishKey(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	if strings.HasPrefix(strings.ToLower(key), "ecb:") {
+		return "", fmt.Errorf("FiSH ECB keys are not supported; use a cbc: key")
+	}
+	if len(key) >= len(FishKeyPrefix) && strings.EqualFold(key[:len(FishKeyPrefix)], FishKeyPrefix) {
+		key = key[len(FishKeyPrefix):]
+	}
+	if key == "" {
+		return "", fmt.Errorf("the key is empty")
+	}
+	if len(key) > 56 {
+		return "", fmt.Errorf("FiSH keys are at most 56 bytes")
+	}
+	if strings.ContainsAny(key, " \r\n") {
+		return "", fmt.Errorf("the key must not contain spaces or line breaks")
+	}
+	return key, nil
+}
+
+// IsFish reports whether text looks like a FiSH-encrypted message.
+func IsFish(text string) bool {
+	return strings.HasPrefix(text, fishPrefix) || strings.HasPrefix(text, fishAltPrefix)
+}
+
+// FishEncrypt encrypts text under key in CBC mode and returns the wire form.
+func FishEncrypt(key, text string) (string, error) {
+	block, err := blowfish.NewCipher([]byte(key))
+	if err != nil {
+		return "", fmt.Errorf("fish key: %w", err)
+	}
+	plain := []byte(text)
+	if pad := len(plain) % blowfish.BlockSize; pad != 0 {
+		plain = append(plain, make([]byte, blowfish.BlockSize-pad)...)
+	}
+	out := make([]byte, blowfish.BlockSize+len(plain))
+	if _, err := rand.Read(out[:blowfish.BlockSize]); err != nil {
+		return "", fmt.Errorf("fish iv: %w", err)
+	}
+	cipher.NewCBCEncrypter(block, out[:blowfish.BlockSize]).CryptBlocks(out[blowfish.BlockSize:], plain)
+	return fishPrefix + fishCBCMarker + base64.StdEncoding.EncodeToString(out), nil
+}
+
+// FishDecrypt decrypts a message produced by FishEncrypt or another FiSH client
+// in CBC mode. The zero padding is stripped.
+func FishDecrypt(key, text string) (string, error) {
+	switch {
+	case strings.HasPrefix(text, fishPrefix):
+		text = text[len(fishPrefix):]
+	case strings.HasPrefix(text, fishAltPrefix):
+		text = text[len(fishAltPrefix):]
+	default:
+		return "", fmt.Errorf("not a FiSH message")
+	}
+	if !strings.HasPrefix(text, fishCBCMarker) {
+		return "", fmt.Errorf("FiSH ECB messages are not supported")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text[len(fishCBCMarker):]))
+	if err != nil {
+		return "", fmt.Errorf("fish base64: %w", err)
+	}
+	if len(raw) < 2*blowfish.BlockSize || len(raw)%blowfish.BlockSize != 0 {
+		return "", fmt.Errorf("fish ciphertext has a bad length")
+	}
+	block, err := blowfish.NewCipher([]byte(key))
+	if err != nil {
+		return "", fmt.Errorf("fish key: %w", err)
+	}
+	plain := make([]byte, len(raw)-blowfish.BlockSize)
+	cipher.NewCBCDecrypter(block, raw[:blowfish.BlockSize]).CryptBlocks(plain, raw[blowfish.BlockSize:])
+	return string(bytes.TrimRight(plain, "\x00")), nil
+}
+
+// FishMaxPlaintext returns the longest text whose FiSH form fits in budget
+// bytes.
+func FishMaxPlaintext(budget int) int {
+	encoded := (budget - len(fishPrefix) - len(fishCBCMarker)) / 4 * 3
+	return encoded/blowfish.BlockSize*blowfish.BlockSize - blowfish.BlockSize
+}
@@ -0,0 +1,56 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFishDecryptKnownCiphertext decrypts a message built independently with
+// OpenSSL's bf-cbc (IV 0102030405060708, zero padding), the way other FiSH
+// clients produce it.
+func TestFishDecryptKnownCiphertext(t *testing.T) {
+	const wire = "+OK *AQIDBAUGBwiQsBTG7T4ywChsJIPY3uHt"
+	got, err := FishDecrypt("0123456789abcdef", wire)
+	if err != nil {
+		t.Fatalf("FishDecrypt: %v", err)
+	}
+	if got != "hello world" {
+		t.Fatalf("decrypted %q", got)
+	}
+	if got, err := FishDecrypt("0123456789abcdef", "mcps *AQIDBAUGBwiQsBTG7T4ywChsJIPY3uHt"); err != nil || got != "hello world" {
+		t.Fatalf("mcps prefix: %q, %v", got, err)
+	}
+}
+
+func TestFishRoundTripAndBudget(t *testing.T) {
+	text := strings.Repeat("ü", 100)
+	wire, err := FishEncrypt("key", text)
+	if err != nil {
+		t.Fatalf("FishEncrypt: %v", err)
+	}
+	if !IsFish(wire) || !strings.HasPrefix(wire, "+OK *") {
+		t.Fatalf("wire form %q", wire)
+	}
+	if got, err := FishDecrypt("key", wire); err != nil || got != text {
+		t.Fatalf("round trip: %q, %v", got, err)
+	}
+
+	for _, budget := range []int{64, 100, 400, 450} {
+		n := FishMaxPlaintext(budget)
+		wire, _ := FishEncrypt("key", strings.Repeat("a", n))
+		if len(wire) > budget {
+			t.Fatalf("budget %d: %d bytes of text became %d on the wire", budget, n, len(wire))
+		}
+	}
+}
+
+func TestParseFishKey(t *testing.T) {
+	if key, err := ParseFishKey(" cbc:s3cret "); err != nil || key != "s3cret" {
+		t.Fatalf("cbc key: %q, %v", key, err)
+	}
+	for _, bad := range []string{"ecb:s3cret", "", "cbc:", "two words", strings.Repeat("k", 57)} {
+		if _, err := ParseFishKey(bad); err == nil {
+			t.Fatalf("ParseFishKey(%q) succeeded", bad)
+		}
+	}
+}
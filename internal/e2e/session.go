@@ -0,0 +1,289 @@
+package e2e
+
+import (
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// End-to-end encrypted private messages between two Cascade users. Each side
+// has a long-term X25519 identity key per network. A conversation starts with
+// a CTCP handshake carrying both parties' identity and a fresh ephemeral key:
+//
+//	initiator -> "E2E INIT <identity> <ephemeral>"
+//	responder -> "E2E ACCEPT <identity> <ephemeral>"
+//	either    -> "E2E END"
+//
+// The session keys come from HKDF over three X25519 agreements (identity x
+// ephemeral both ways, and ephemeral x ephemeral), so only the holders of both
+// identity keys can read the conversation and a later key leak does not expose
+// it. Messages are XChaCha20-Poly1305 under a random nonce, one key per
+// direction, sent as "+E2E <base64(nonce || ciphertext)>". Each user compares
+// the other's identity fingerprint out of band to rule out a man in the middle.
+
+// CTCPCommand is the CTCP command that carries the handshake.
+const CTCPCommand = "E2E"
+
+// Handshake verbs.
+const (
+	VerbInit   = "INIT"
+	VerbAccept = "ACCEPT"
+	VerbEnd    = "END"
+)
+
+const (
+	messagePrefix = "+E2E "
+	// kdfInfo domain-separates the session key derivation; bump the suffix only
+	// if the scheme changes in an incompatible way.
+	kdfInfo = "cascade-e2e-v1"
+)
+
+var keyEncoding = base64.RawStdEncoding
+
+// Identity is a long-term X25519 key pair.
+type Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// NewIdentity generates a random identity.
+func NewIdentity() (Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return Identity{}, fmt.Errorf("generate identity: %w", err)
+	}
+	return Identity{key: key}, nil
+}
+
+// ParseIdentity decodes an identity written by Encode.
+func ParseIdentity(s string) (Identity, error) {
+	raw, err := keyEncoding.DecodeString(s)
+	if err != nil {
+		return Identity{}, fmt.Errorf("decode identity: %w", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return Identity{}, fmt.Errorf("decode identity: %w", err)
+	}
+	return Identity{key: key}, nil
+}
+
+// Valid reports whether the identity holds a key.
+func (id Identity) Valid() bool { return id.key != nil }
+
+// Encode returns the private key in the form ParseIdentity reads, for the
+// credential store.
+func (id Identity) Encode() string { return keyEncoding.EncodeToString(id.key.Bytes()) }
+
+// Fingerprint returns the identity's public fingerprint.
+func (id Identity) Fingerprint() string { return Fingerprint(id.key.PublicKey().Bytes()) }
+
+// Fingerprint formats the SHA-256 of a public identity key as ten groups of
+// four hex digits, for reading aloud or comparing side by side.
+func Fingerprint(public []byte) string {
+	sum := sha256.Sum256(public)
+	digits := strings.ToUpper(hex.EncodeToString(sum[:20]))
+	groups := make([]string, 0, len(digits)/4)
+	for i := 0; i < len(digits); i += 4 {
+		groups = append(groups, digits[i:i+4])
+	}
+	return strings.Join(groups, " ")
+}
+
+// Handshake is an INIT we sent and are waiting to have accepted.
+type Handshake struct {
+	id        Identity
+	ephemeral *ecdh.PrivateKey
+}
+
+// StartHandshake creates a handshake and returns the CTCP arguments of its
+// INIT.
+func StartHandshake(id Identity) (*Handshake, string, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("generate ephemeral key: %w", err)
+	}
+	return &Handshake{id: id, ephemeral: ephemeral}, handshakeArgs(VerbInit, id, ephemeral), nil
+}
+
+// Finish completes the handshake from the peer's ACCEPT arguments.
+func (h *Handshake) Finish(args string) (*Session, error) {
+	peerID, peerEphemeral, err := parseHandshakeArgs(VerbAccept, args)
+	if err != nil {
+		return nil, err
+	}
+	return newSession(true, h.id, h.ephemeral, peerID, peerEphemeral)
+}
+
+// Accept answers a peer's INIT, returning the session and the CTCP arguments
+// of the ACCEPT to send back.
+func Accept(id Identity, args string) (*Session, string, error) {
+	peerID, peerEphemeral, err := parseHandshakeArgs(VerbInit, args)
+	if err != nil {
+		return nil, "", err
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("generate ephemeral key: %w", err)
+	}
+	s, err := newSession(false, id, ephemeral, peerID, peerEphemeral)
+	if err != nil {
+		return nil, "", err
+	}
+	return s, handshakeArgs(VerbAccept, id, ephemeral), nil
+}
+
+// OfferFingerprint returns the fingerprint of the identity that sent an INIT,
+// so the user can see who is asking before accepting.
+func OfferFingerprint(args string) (string, error) {
+	peerID, _, err := parseHandshakeArgs(VerbInit, args)
+	if err != nil {
+		return "", err
+	}
+	return Fingerprint(peerID.Bytes()), nil
+}
+
+// Outranks settles INITs that crossed: both sides compare the two INITs the
+// same way, the lower one stays the initiator and the other side accepts it.
+func (h *Handshake) Outranks(peerInit string) bool {
+	fields := strings.Fields(peerInit)
+	if len(fields) > 0 {
+		fields[0] = strings.ToUpper(fields[0])
+	}
+	return handshakeArgs(VerbInit, h.id, h.ephemeral) < strings.Join(fields, " ")
+}
+
+// HandshakeVerb returns the verb of a handshake's CTCP arguments, upper-cased.
+func HandshakeVerb(args string) string {
+	verb, _, _ := strings.Cut(args, " ")
+	return strings.ToUpper(verb)
+}
+
+func handshakeArgs(verb string, id Identity, ephemeral *ecdh.PrivateKey) string {
+	return verb + " " + keyEncoding.EncodeToString(id.key.PublicKey().Bytes()) + " " +
+		keyEncoding.EncodeToString(ephemeral.PublicKey().Bytes())
+}
+
+func parseHandshakeArgs(verb, args string) (id, ephemeral *ecdh.PublicKey, err error) {
+	fields := strings.Fields(args)
+	if len(fields) != 3 || !strings.EqualFold(fields[0], verb) {
+		return nil, nil, fmt.Errorf("malformed %s", verb)
+	}
+	keys := make([]*ecdh.PublicKey, 2)
+	for i, f := range fields[1:] {
+		raw, err := keyEncoding.DecodeString(f)
+		if err != nil {
+			return nil, nil, fmt.Errorf("malformed %s key: %w", verb, err)
+		}
+		if keys[i], err = ecdh.X25519().NewPublicKey(raw); err != nil {
+			return nil, nil, fmt.Errorf("malformed %s key: %w", verb, err)
+		}
+	}
+	return keys[0], keys[1], nil
+}
+
+// Session is an established conversation: one key for each direction.
+type Session struct {
+	peerIdentity []byte
+	send, recv   cipher.AEAD
+}
+
+func newSession(initiator bool, id Identity, ephemeral *ecdh.PrivateKey, peerID, peerEphemeral *ecdh.PublicKey) (*Session, error) {
+	idToEph, err := id.key.ECDH(peerEphemeral)
+	if err != nil {
+		return nil, fmt.Errorf("key agreement: %w", err)
+	}
+	ephToID, err := ephemeral.ECDH(peerID)
+	if err != nil {
+		return nil, fmt.Errorf("key agreement: %w", err)
+	}
+	ephToEph, err := ephemeral.ECDH(peerEphemeral)
+	if err != nil {
+		return nil, fmt.Errorf("key agreement: %w", err)
+	}
+
+	// Both sides must feed HKDF the same bytes, so order everything
+	// initiator first.
+	mine := [][]byte{id.key.PublicKey().Bytes(), ephemeral.PublicKey().Bytes()}
+	theirs := [][]byte{peerID.Bytes(), peerEphemeral.Bytes()}
+	secret := append(append(append([]byte{}, idToEph...), ephToID...), ephToEph...)
+	first, second := mine, theirs
+	if !initiator {
+		secret = append(append(append([]byte{}, ephToID...), idToEph...), ephToEph...)
+		first, second = theirs, mine
+	}
+	info := append([]byte(kdfInfo), first[0]...)
+	info = append(append(append(info, second[0]...), first[1]...), second[1]...)
+
+	keys := make([]byte, 2*chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, info), keys); err != nil {
+		return nil, fmt.Errorf("derive session keys: %w", err)
+	}
+	toResponder, err := chacha20poly1305.NewX(keys[:chacha20poly1305.KeySize])
+	if err != nil {
+		return nil, err
+	}
+	toInitiator, err := chacha20poly1305.NewX(keys[chacha20poly1305.KeySize:])
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{peerIdentity: peerID.Bytes(), send: toResponder, recv: toInitiator}
+	if !initiator {
+		s.send, s.recv = toInitiator, toResponder
+	}
+	return s, nil
+}
+
+// PeerFingerprint returns the fingerprint of the peer's identity key.
+func (s *Session) PeerFingerprint() string { return Fingerprint(s.peerIdentity) }
+
+// IsEncrypted reports whether text looks like a session message.
+func IsEncrypted(text string) bool { return strings.HasPrefix(text, messagePrefix) }
+
+// Seal encrypts text for the peer and returns the wire form.
+func (s *Session) Seal(text string) (string, error) {
+	nonce := make([]byte, chacha20poly1305.NonceSizeX, chacha20poly1305.NonceSizeX+len(text)+chacha20poly1305.Overhead)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("e2e nonce: %w", err)
+	}
+	sealed := s.send.Seal(nonce, nonce, []byte(text), nil)
+	return messagePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a message from the peer.
+func (s *Session) Open(text string) (string, error) { return open(s.recv, text) }
+
+// OpenOwn decrypts a message we sealed, as echo-message hands it back.
+func (s *Session) OpenOwn(text string) (string, error) { return open(s.send, text) }
+
+func open(aead cipher.AEAD, text string) (string, error) {
+	if !IsEncrypted(text) {
+		return "", fmt.Errorf("not an encrypted message")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text[len(messagePrefix):]))
+	if err != nil {
+		return "", fmt.Errorf("e2e base64: %w", err)
+	}
+	if len(raw) < chacha20poly1305.NonceSizeX+chacha20poly1305.Overhead {
+		return "", fmt.Errorf("e2e message is too short")
+	}
+	plain, err := aead.Open(nil, raw[:chacha20poly1305.NonceSizeX], raw[chacha20poly1305.NonceSizeX:], nil)
+	if err != nil {
+		return "", fmt.Errorf("e2e message failed to authenticate")
+	}
+	return string(plain), nil
+}
+
+// MaxPlaintext returns the longest text whose sealed form fits in budget
+// bytes.
+func MaxPlaintext(budget int) int {
+	return (budget-len(messagePrefix))/4*3 - chacha20poly1305.NonceSizeX - chacha20poly1305.Overhead
+}
@@ -0,0 +1,114 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestIdentity(t *testing.T) Identity {
+	t.Helper()
+	id, err := NewIdentity()
+	if err != nil {
+		t.Fatalf("NewIdentity: %v", err)
+	}
+	return id
+}
+
+// TestHandshakeAndMessages: both sides derive the same keys, each can read
+// what the other sealed and its own echo, and a third identity can't.
+func TestHandshakeAndMessages(t *testing.T) {
+	alice, bob := newTestIdentity(t), newTestIdentity(t)
+
+	pending, initArgs, err := StartHandshake(alice)
+	if err != nil {
+		t.Fatalf("StartHandshake: %v", err)
+	}
+	if HandshakeVerb(initArgs) != VerbInit {
+		t.Fatalf("init args %q", initArgs)
+	}
+	bobSession, acceptArgs, err := Accept(bob, initArgs)
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	aliceSession, err := pending.Finish(acceptArgs)
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if aliceSession.PeerFingerprint() != bob.Fingerprint() || bobSession.PeerFingerprint() != alice.Fingerprint() {
+		t.Fatal("peer fingerprints don't match the identities")
+	}
+
+	wire, err := aliceSession.Seal("hello bob")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsEncrypted(wire) || strings.Contains(wire, "hello") {
+		t.Fatalf("wire form %q", wire)
+	}
+	if got, err := bobSession.Open(wire); err != nil || got != "hello bob" {
+		t.Fatalf("bob opened %q, %v", got, err)
+	}
+	if got, err := aliceSession.OpenOwn(wire); err != nil || got != "hello bob" {
+		t.Fatalf("alice opened her echo as %q, %v", got, err)
+	}
+	if _, err := aliceSession.Open(wire); err == nil {
+		t.Fatal("a message opened with the wrong direction's key")
+	}
+
+	mallory, _, _ := Accept(newTestIdentity(t), initArgs)
+	if _, err := mallory.Open(wire); err == nil {
+		t.Fatal("a third party opened the message")
+	}
+}
+
+func TestIdentityEncodeAndBudget(t *testing.T) {
+	id := newTestIdentity(t)
+	parsed, err := ParseIdentity(id.Encode())
+	if err != nil || parsed.Fingerprint() != id.Fingerprint() {
+		t.Fatalf("ParseIdentity: %v", err)
+	}
+	if len(strings.Fields(id.Fingerprint())) != 10 {
+		t.Fatalf("fingerprint %q", id.Fingerprint())
+	}
+
+	s, _, _ := Accept(id, mustInit(t))
+	for _, budget := range []int{64, 100, 400, 450} {
+		n := MaxPlaintext(budget)
+		wire, _ := s.Seal(strings.Repeat("a", n))
+		if len(wire) > budget {
+			t.Fatalf("budget %d: %d bytes of text became %d on the wire", budget, n, len(wire))
+		}
+	}
+}
+
+func mustInit(t *testing.T) string {
+	t.Helper()
+	_, args, err := StartHandshake(newTestIdentity(t))
+	if err != nil {
+		t.Fatalf("StartHandshake: %v", err)
+	}
+	return args
+}
+
+// TestCrossingInits: when both sides send INIT, exactly one of them outranks
+// the other, and an offer names the identity that sent it.
+func TestCrossingInits(t *testing.T) {
+	alice, bob := newTestIdentity(t), newTestIdentity(t)
+	aliceHandshake, aliceInit, err := StartHandshake(alice)
+	if err != nil {
+		t.Fatalf("StartHandshake: %v", err)
+	}
+	bobHandshake, bobInit, err := StartHandshake(bob)
+	if err != nil {
+		t.Fatalf("StartHandshake: %v", err)
+	}
+	if aliceHandshake.Outranks(bobInit) == bobHandshake.Outranks(aliceInit) {
+		t.Fatal("crossing INITs did not settle on one initiator")
+	}
+	if fp, err := OfferFingerprint(aliceInit); err != nil || fp != alice.Fingerprint() {
+		t.Fatalf("OfferFingerprint = %q, %v", fp, err)
+	}
+	if _, err := OfferFingerprint("INIT nonsense"); err == nil {
+		t.Fatal("OfferFingerprint accepted a malformed INIT")
+	}
+}
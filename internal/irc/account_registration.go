@@ -0,0 +1,291 @@
+package irc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/events"
+	"github.com/matt0x6f/irc-client/internal/logger"
+)
+
+// Account registration through IRCv3 draft/account-registration: REGISTER
+// creates an account, VERIFY completes one that needs an emailed code. The
+// capability is informational and never requested. When its value includes
+// before-connect, a registration queued before connecting is sent during the
+// handshake, ahead of CAP END, so the connection completes logged in;
+// otherwise it is sent once registration with the server is done. Failures
+// arrive as FAIL REGISTER / FAIL VERIFY standard replies.
+
+const accountRegistrationCap = "draft/account-registration"
+
+// registrationReplyTimeout bounds the wait for a REGISTER reply. It stays
+// under the connection timeout so a before-connect wait can't fail the
+// handshake.
+const registrationReplyTimeout = 20 * time.Second
+
+// Outcomes reported in EventAccountRegistration's "status".
+const (
+	RegistrationSuccess              = "success"
+	RegistrationVerificationRequired = "verification_required"
+	RegistrationFailed               = "failed"
+)
+
+// AccountRegistrationSupport is what the server's draft/account-registration
+// advertisement allows.
+type AccountRegistrationSupport struct {
+	Supported         bool `json:"supported"`
+	BeforeConnect     bool `json:"beforeConnect"`     // REGISTER is accepted before the connection completes
+	EmailRequired     bool `json:"emailRequired"`     // an email address must be given
+	CustomAccountName bool `json:"customAccountName"` // the account may differ from the current nick
+}
+
+// parseAccountRegistrationCap reads the capability's comma-separated value.
+func parseAccountRegistrationCap(value string, present bool) AccountRegistrationSupport {
+	support := AccountRegistrationSupport{Supported: present}
+	for _, key := range strings.Split(value, ",") {
+		switch key {
+		case "before-connect":
+			support.BeforeConnect = true
+		case "email-required":
+			support.EmailRequired = true
+		case "custom-account-name":
+			support.CustomAccountName = true
+		}
+	}
+	return support
+}
+
+// AccountRegistration is a request to create an account. An empty Account
+// means the current nick; an empty Email sends "*".
+type AccountRegistration struct {
+	Account  string
+	Email    string
+	Password string
+}
+
+// registrationOutcome is one REGISTER or VERIFY result.
+type registrationOutcome struct {
+	status, account, code, message string
+}
+
+// AccountRegistrationSupport reports what the server allows, as advertised in
+// CAP LS. It is the zero value until the server has been asked.
+func (c *IRCClient) AccountRegistrationSupport() AccountRegistrationSupport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.registrationSupport
+}
+
+// SetAccountRegisteredAction installs fn to run when an account this client
+// registered is ready to log in to: after REGISTER SUCCESS, or after VERIFY
+// SUCCESS when a code was needed. The app saves the credentials from it.
+func (c *IRCClient) SetAccountRegisteredAction(fn func(account, password string)) {
+	c.mu.Lock()
+	c.onAccountRegistered = fn
+	c.mu.Unlock()
+}
+
+// QueueAccountRegistration holds reg until the next connection: it is sent
+// during the handshake when the server allows before-connect, else once
+// registered with the server. Call it before Connect.
+func (c *IRCClient) QueueAccountRegistration(reg AccountRegistration) {
+	c.mu.Lock()
+	c.queuedRegistration = &reg
+	c.mu.Unlock()
+}
+
+// beforeCapEnd is the library's BeforeCapEnd hook. It records the server's
+// registration support and, when before-connect allows it, sends a queued
+// registration and waits for its answer. A failed registration doesn't stop
+// the connection; the FAIL reply has already told the user why.
+func (c *IRCClient) beforeCapEnd(advertised map[string]string) error {
+	value, present := advertised[accountRegistrationCap]
+	support := parseAccountRegistrationCap(value, present)
+	c.mu.Lock()
+	c.registrationSupport = support
+	reg := c.queuedRegistration
+	if reg != nil && support.BeforeConnect {
+		c.queuedRegistration = nil
+	}
+	c.mu.Unlock()
+
+	if reg != nil && support.BeforeConnect {
+		c.registerAndWait(*reg)
+	}
+	return nil
+}
+
+// registerQueuedAccount sends a registration that was queued for a server
+// without before-connect, now that we're registered. Auto-join waits for it.
+func (c *IRCClient) registerQueuedAccount() {
+	c.mu.Lock()
+	reg := c.queuedRegistration
+	c.queuedRegistration = nil
+	c.mu.Unlock()
+	if reg != nil {
+		c.registerAndWait(*reg)
+	}
+}
+
+func (c *IRCClient) registerAndWait(reg AccountRegistration) {
+	done, err := c.sendRegister(reg)
+	if err != nil {
+		c.writeStatusLine("error", fmt.Sprintf("Couldn't register an account: %v", err))
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(registrationReplyTimeout):
+		c.writeStatusLine("warning", "The server didn't answer the account registration.")
+	}
+}
+
+// RegisterAccount sends REGISTER on a registered connection. The outcome
+// arrives as EventAccountRegistration.
+func (c *IRCClient) RegisterAccount(reg AccountRegistration) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+	_, err := c.sendRegister(reg)
+	return err
+}
+
+// sendRegister checks reg against the server's support and sends REGISTER,
+// returning a channel closed when the answer arrives.
+func (c *IRCClient) sendRegister(reg AccountRegistration) (<-chan struct{}, error) {
+	support := c.AccountRegistrationSupport()
+	switch {
+	case !support.Supported:
+		return nil, fmt.Errorf("this server doesn't offer account registration")
+	case reg.Password == "":
+		return nil, fmt.Errorf("a password is required")
+	case support.EmailRequired && reg.Email == "":
+		return nil, fmt.Errorf("this server requires an email address")
+	}
+	nick := c.CurrentNick()
+	if nick == "" {
+		nick = c.preferredNick() // before-connect: 001 hasn't named us yet
+	}
+	account := "*"
+	if reg.Account == "" {
+		reg.Account = nick
+	} else if !c.sameName(reg.Account, nick) {
+		if !support.CustomAccountName {
+			return nil, fmt.Errorf("this server only registers your current nick")
+		}
+		account = reg.Account
+	}
+	email := reg.Email
+	if email == "" {
+		email = "*"
+	}
+
+	done := make(chan struct{})
+	c.mu.Lock()
+	c.pendingRegistration = &reg
+	c.registrationDone = done
+	c.mu.Unlock()
+	if err := c.conn.Send("REGISTER", account, email, reg.Password); err != nil {
+		c.mu.Lock()
+		c.pendingRegistration, c.registrationDone = nil, nil
+		c.mu.Unlock()
+		return nil, err
+	}
+	return done, nil
+}
+
+// VerifyAccount sends VERIFY with the code the server asked for after a
+// REGISTER answered VERIFICATION_REQUIRED.
+func (c *IRCClient) VerifyAccount(code string) error {
+	c.mu.Lock()
+	reg := c.pendingRegistration
+	awaiting := c.awaitingVerification
+	c.mu.Unlock()
+	if reg == nil || !awaiting {
+		return fmt.Errorf("no account is waiting for a verification code")
+	}
+	return c.conn.Send("VERIFY", reg.Account, strings.TrimSpace(code))
+}
+
+// handleRegisterReply handles "REGISTER SUCCESS|VERIFICATION_REQUIRED
+// <account> :<message>".
+func (c *IRCClient) handleRegisterReply(e ircmsg.Message) {
+	if len(e.Params) < 3 {
+		return
+	}
+	account, message := e.Params[1], e.Params[len(e.Params)-1]
+	switch e.Params[0] {
+	case "SUCCESS":
+		c.finishRegistration(registrationOutcome{status: RegistrationSuccess, account: account, message: message})
+	case "VERIFICATION_REQUIRED":
+		c.finishRegistration(registrationOutcome{status: RegistrationVerificationRequired, account: account, message: message})
+	}
+}
+
+// handleVerifyReply handles "VERIFY SUCCESS <account> :<message>".
+func (c *IRCClient) handleVerifyReply(e ircmsg.Message) {
+	if len(e.Params) < 3 || e.Params[0] != "SUCCESS" {
+		return
+	}
+	c.finishRegistration(registrationOutcome{status: RegistrationSuccess, account: e.Params[1], message: e.Params[len(e.Params)-1]})
+}
+
+// registrationFailed is called by handleStandardReply for FAIL REGISTER and
+// FAIL VERIFY. An invalid code leaves the account waiting for another try.
+func (c *IRCClient) registrationFailed(code, message string) {
+	c.finishRegistration(registrationOutcome{status: RegistrationFailed, code: code, message: message})
+}
+
+// finishRegistration settles the registration in flight: it wakes a waiting
+// handshake, hands a usable account to the app, writes a status line and
+// emits EventAccountRegistration. Replies with no registration in flight
+// (someone typed /quote REGISTER) only get the event.
+func (c *IRCClient) finishRegistration(out registrationOutcome) {
+	c.mu.Lock()
+	reg := c.pendingRegistration
+	if done := c.registrationDone; done != nil {
+		close(done)
+		c.registrationDone = nil
+	}
+	switch {
+	case out.status == RegistrationVerificationRequired:
+		c.awaitingVerification = true
+	case out.status == RegistrationFailed && out.code == "INVALID_CODE" && c.awaitingVerification:
+		// Keep the account so the user can enter the code again.
+	default:
+		c.pendingRegistration = nil
+		c.awaitingVerification = false
+	}
+	registered := c.onAccountRegistered
+	c.mu.Unlock()
+
+	if out.account == "" && reg != nil {
+		out.account = reg.Account
+	}
+	switch out.status {
+	case RegistrationSuccess:
+		c.writeStatusLine("status", fmt.Sprintf("Account %q registered.", out.account))
+		if reg != nil && registered != nil {
+			registered(out.account, reg.Password)
+		}
+	case RegistrationVerificationRequired:
+		c.writeStatusLine("status", fmt.Sprintf("Account %q needs verifying: %s", out.account, out.message))
+	}
+	logger.Log.Info().Str("status", out.status).Str("account", out.account).Str("code", out.code).Msg("Account registration reply")
+
+	c.eventBus.Emit(events.Event{
+		Type: EventAccountRegistration,
+		Data: map[string]interface{}{
+			"network":   c.network.Address,
+			"networkId": c.networkID,
+			"status":    out.status,
+			"account":   out.account,
+			"code":      out.code,
+			"message":   out.message,
+		},
+		Timestamp: time.Now(),
+		Source:    events.EventSourceIRC,
+	})
+}
@@ -0,0 +1,85 @@
+package irc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/events"
+)
+
+func TestParseAccountRegistrationCap(t *testing.T) {
+	got := parseAccountRegistrationCap("before-connect,custom-account-name", true)
+	want := AccountRegistrationSupport{Supported: true, BeforeConnect: true, CustomAccountName: true}
+	if got != want {
+		t.Fatalf("parse = %+v, want %+v", got, want)
+	}
+	if got := parseAccountRegistrationCap("", false); got.Supported {
+		t.Fatalf("absent cap parsed as %+v", got)
+	}
+}
+
+// TestAccountRegistrationWithVerification: a registration queued for a server
+// without before-connect waits for registration, then REGISTER asks for a
+// code; a wrong code can be retried, and the right one hands the credentials
+// to the app.
+func TestAccountRegistrationWithVerification(t *testing.T) {
+	c := newAnnounceBotTestClient(t)
+	c.currentNick = "robodan"
+	conn, sentLines := newConnectedPipe(t)
+	c.conn = conn
+	outcomes := make(chan events.Event, 4)
+	c.eventBus.Subscribe(EventAccountRegistration, capturingSub{got: outcomes})
+	saved := make(chan [2]string, 1)
+	c.SetAccountRegisteredAction(func(account, password string) { saved <- [2]string{account, password} })
+
+	c.QueueAccountRegistration(AccountRegistration{Password: "hunter2"})
+	if err := c.beforeCapEnd(map[string]string{"draft/account-registration": "email-required"}); err != nil {
+		t.Fatalf("beforeCapEnd: %v", err)
+	}
+	if !c.AccountRegistrationSupport().EmailRequired {
+		t.Fatal("email-required not recorded")
+	}
+	c.registerQueuedAccount()
+	if !hasStatusLineInStorage(t, c, "error", "Couldn't register an account: this server requires an email address") {
+		t.Fatal("a registration without the required email was not refused")
+	}
+
+	c.QueueAccountRegistration(AccountRegistration{Email: "me@example.org", Password: "hunter2"})
+	go c.registerQueuedAccount()
+	if got := drainUntilPrefix(t, sentLines, "REGISTER ", 2*time.Second); got != "REGISTER * me@example.org hunter2" {
+		t.Fatalf("sent %q", got)
+	}
+	c.handleRegisterReply(parse(t, ":irc.test.local REGISTER VERIFICATION_REQUIRED robodan :Check your email"))
+	if ev := <-outcomes; ev.Data["status"] != RegistrationVerificationRequired {
+		t.Fatalf("outcome = %+v", ev.Data)
+	}
+
+	if err := c.VerifyAccount(" 1234 "); err != nil {
+		t.Fatalf("VerifyAccount: %v", err)
+	}
+	if got := drainUntilPrefix(t, sentLines, "VERIFY ", 2*time.Second); got != "VERIFY robodan 1234" {
+		t.Fatalf("sent %q", got)
+	}
+	c.handleStandardReply(parse(t, ":irc.test.local FAIL VERIFY INVALID_CODE robodan :Wrong code"), "error")
+	if ev := <-outcomes; ev.Data["status"] != RegistrationFailed || ev.Data["code"] != "INVALID_CODE" {
+		t.Fatalf("outcome = %+v", ev.Data)
+	}
+	if err := c.VerifyAccount("5678"); err != nil {
+		t.Fatalf("VerifyAccount after a wrong code: %v", err)
+	}
+	c.handleVerifyReply(parse(t, ":irc.test.local VERIFY SUCCESS robodan :Account verified"))
+	if ev := <-outcomes; ev.Data["status"] != RegistrationSuccess || ev.Data["account"] != "robodan" {
+		t.Fatalf("outcome = %+v", ev.Data)
+	}
+	select {
+	case got := <-saved:
+		if got != [2]string{"robodan", "hunter2"} {
+			t.Fatalf("saved %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the registered account was not handed to the app")
+	}
+	if err := c.VerifyAccount("9999"); err == nil {
+		t.Fatal("VerifyAccount succeeded with nothing awaiting a code")
+	}
+}
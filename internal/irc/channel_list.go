@@ -0,0 +1,171 @@
+package irc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChannelListFilter narrows a channel LIST. Zero fields are unset. Times are in
+// minutes before now, the unit ELIST uses.
+type ChannelListFilter struct {
+	MinUsers      int      `json:"minUsers"`      // more than this many users (>N)
+	MaxUsers      int      `json:"maxUsers"`      // fewer than this many users (<N)
+	Masks         []string `json:"masks"`         // channel name masks such as #linux*
+	ExcludeMasks  []string `json:"excludeMasks"`  // name masks to leave out (!mask)
+	CreatedWithin int      `json:"createdWithin"` // created less than this many minutes ago (C>N)
+	CreatedBefore int      `json:"createdBefore"` // created more than this many minutes ago (C<N)
+	TopicWithin   int      `json:"topicWithin"`   // topic set less than this many minutes ago (T>N)
+	TopicBefore   int      `json:"topicBefore"`   // topic set more than this many minutes ago (T<N)
+	Text          []string `json:"text"`          // words matched locally against names and topics
+}
+
+// ParseChannelListFilter reads a /list style filter: >N and <N bound the user
+// count, C>N, C<N, T>N and T<N bound channel and topic age in minutes, !mask
+// excludes names, a word with a wildcard or channel prefix is a name mask,
+// and any other word is text to search for locally.
+func ParseChannelListFilter(text string) (ChannelListFilter, error) {
+	var f ChannelListFilter
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool { return r == ' ' || r == ',' }) {
+		upper := strings.ToUpper(token)
+		switch {
+		case strings.HasPrefix(token, ">") || strings.HasPrefix(token, "<"):
+			n, err := listFilterNumber(token, token[1:])
+			if err != nil {
+				return f, err
+			}
+			if token[0] == '>' {
+				f.MinUsers = n
+			} else {
+				f.MaxUsers = n
+			}
+		case len(token) > 2 && (upper[0] == 'C' || upper[0] == 'T') && (token[1] == '>' || token[1] == '<'):
+			n, err := listFilterNumber(token, token[2:])
+			if err != nil {
+				return f, err
+			}
+			switch upper[:2] {
+			case "C>":
+				f.CreatedWithin = n
+			case "C<":
+				f.CreatedBefore = n
+			case "T>":
+				f.TopicWithin = n
+			case "T<":
+				f.TopicBefore = n
+			}
+		case strings.HasPrefix(token, "!") && len(token) > 1:
+			f.ExcludeMasks = append(f.ExcludeMasks, token[1:])
+		case strings.ContainsAny(token, "*?") || IsChannelName(token):
+			f.Masks = append(f.Masks, token)
+		default:
+			f.Text = append(f.Text, token)
+		}
+	}
+	return f, nil
+}
+
+func listFilterNumber(token, digits string) (int, error) {
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid list filter %q", token)
+	}
+	return n, nil
+}
+
+// IsZero reports whether the filter narrows nothing.
+func (f ChannelListFilter) IsZero() bool {
+	return f.MinUsers == 0 && f.MaxUsers == 0 && len(f.Masks) == 0 && len(f.ExcludeMasks) == 0 &&
+		f.CreatedWithin == 0 && f.CreatedBefore == 0 && f.TopicWithin == 0 && f.TopicBefore == 0 && len(f.Text) == 0
+}
+
+// listArgument builds the LIST parameter for the conditions the server's ELIST
+// token says it can apply (U user counts, M and N name masks, C creation and T
+// topic times). Conditions it cannot apply are left for the caller to apply to
+// the results; the empty string means a bare LIST.
+func (f ChannelListFilter) listArgument(elist string) string {
+	elist = strings.ToUpper(elist)
+	var conds []string
+	if strings.Contains(elist, "U") {
+		if f.MinUsers > 0 {
+			conds = append(conds, fmt.Sprintf(">%d", f.MinUsers))
+		}
+		if f.MaxUsers > 0 {
+			conds = append(conds, fmt.Sprintf("<%d", f.MaxUsers))
+		}
+	}
+	if strings.Contains(elist, "C") {
+		if f.CreatedWithin > 0 {
+			conds = append(conds, fmt.Sprintf("C>%d", f.CreatedWithin))
+		}
+		if f.CreatedBefore > 0 {
+			conds = append(conds, fmt.Sprintf("C<%d", f.CreatedBefore))
+		}
+	}
+	if strings.Contains(elist, "T") {
+		if f.TopicWithin > 0 {
+			conds = append(conds, fmt.Sprintf("T>%d", f.TopicWithin))
+		}
+		if f.TopicBefore > 0 {
+			conds = append(conds, fmt.Sprintf("T<%d", f.TopicBefore))
+		}
+	}
+	// Several include masks would be OR-ed by the server; one is unambiguous.
+	if strings.Contains(elist, "M") && len(f.Masks) == 1 {
+		conds = append(conds, f.Masks[0])
+	}
+	if strings.Contains(elist, "N") {
+		for _, mask := range f.ExcludeMasks {
+			conds = append(conds, "!"+mask)
+		}
+	}
+	return strings.Join(conds, ",")
+}
+
+// SupportsListCondition reports whether the server's ELIST token advertises
+// the given condition letter (U, M, N, C or T).
+func (c *IRCClient) SupportsListCondition(letter string) bool {
+	return strings.Contains(strings.ToUpper(c.ISupport()["ELIST"]), strings.ToUpper(letter))
+}
+
+// RequestChannelList sends LIST, passing the server whatever parts of filter
+// its ELIST token supports, and returns the argument it sent. Results arrive
+// as EventChannelListEnd.
+func (c *IRCClient) RequestChannelList(filter ChannelListFilter) (string, error) {
+	arg := filter.listArgument(c.ISupport()["ELIST"])
+	line := "LIST"
+	if arg != "" {
+		line += " " + arg
+	}
+	return arg, c.SendRawCommand(line)
+}
+
+// noteListRequest remembers the argument of an outgoing LIST, however it was
+// sent, so EventChannelListEnd can say whether the reply covers every visible
+// channel (an empty "filter") or only those the argument selected.
+func (c *IRCClient) noteListRequest(line string) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	if !strings.EqualFold(command, "LIST") {
+		return
+	}
+	c.channelListMu.Lock()
+	c.channelListFilter = strings.TrimSpace(arg)
+	c.channelListMu.Unlock()
+}
+
+// parseListTopic splits the "[+modes] " prefix some servers put in front of
+// an RPL_LIST topic.
+func parseListTopic(topic string) (modes, rest string) {
+	if !strings.HasPrefix(topic, "[+") {
+		return "", topic
+	}
+	end := strings.IndexByte(topic, ']')
+	if end < 3 || !isASCIILetter(topic[2]) {
+		return "", topic
+	}
+	return topic[1:end], strings.TrimPrefix(topic[end+1:], " ")
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,70 @@
+package irc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChannelListFilter(t *testing.T) {
+	f, err := ParseChannelListFilter(">50 <1000,c>60 T<5 !*bot* #linux* python")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ChannelListFilter{
+		MinUsers:      50,
+		MaxUsers:      1000,
+		CreatedWithin: 60,
+		TopicBefore:   5,
+		ExcludeMasks:  []string{"*bot*"},
+		Masks:         []string{"#linux*"},
+		Text:          []string{"python"},
+	}
+	if !reflect.DeepEqual(f, want) {
+		t.Errorf("filter = %+v\nwant %+v", f, want)
+	}
+	if _, err := ParseChannelListFilter(">lots"); err == nil {
+		t.Error("non-numeric bound accepted")
+	}
+	if f, _ := ParseChannelListFilter("  "); !f.IsZero() {
+		t.Errorf("blank filter = %+v, want zero", f)
+	}
+}
+
+func TestChannelListArgumentFollowsELIST(t *testing.T) {
+	f := ChannelListFilter{
+		MinUsers:      50,
+		CreatedWithin: 60,
+		TopicBefore:   5,
+		Masks:         []string{"#linux*"},
+		ExcludeMasks:  []string{"*bot*"},
+		Text:          []string{"python"},
+	}
+	for elist, want := range map[string]string{
+		"":      "",
+		"U":     ">50",
+		"CMNTU": ">50,C>60,T<5,#linux*,!*bot*",
+		"mnu":   ">50,#linux*,!*bot*",
+	} {
+		if got := f.listArgument(elist); got != want {
+			t.Errorf("ELIST=%q: argument %q, want %q", elist, got, want)
+		}
+	}
+	f.Masks = append(f.Masks, "#python*")
+	if got := f.listArgument("M"); got != "" {
+		t.Errorf("two masks sent as %q; the server would OR them", got)
+	}
+}
+
+func TestParseListTopic(t *testing.T) {
+	for topic, want := range map[string][2]string{
+		"[+nt] Welcome":        {"+nt", "Welcome"},
+		"[+ntl 50] Full house": {"+ntl 50", "Full house"},
+		"[+1] votes please":    {"", "[+1] votes please"},
+		"no modes here":        {"", "no modes here"},
+	} {
+		modes, rest := parseListTopic(topic)
+		if modes != want[0] || rest != want[1] {
+			t.Errorf("parseListTopic(%q) = %q, %q; want %q, %q", topic, modes, rest, want[0], want[1])
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/ergochat/irc-go/ircevent"
 	"github.com/ergochat/irc-go/ircmsg"
 	"github.com/matt0x6f/irc-client/internal/constants"
+	"github.com/matt0x6f/irc-client/internal/e2e"
 	"github.com/matt0x6f/irc-client/internal/events"
 	"github.com/matt0x6f/irc-client/internal/irc/sasl"
 	"github.com/matt0x6f/irc-client/internal/logger"
@@ -42,7 +43,7 @@ import (
 // with, so the Buddies pane / DM dots can reflect their away state. "no-implicit-names"
 // suppresses the automatic NAMES reply after our JOIN; when it is ACKed we send an
 // explicit NAMES so the roster still builds (see the JOIN handler).
-var requestedCaps = []string{"sasl", "server-time", "echo-message", "message-tags", "batch", "draft/chathistory", "chathistory", "draft/event-playback", "multi-prefix", "cap-notify", "away-notify", "account-notify", "extended-join", "chghost", "account-tag", "userhost-in-names", "setname", "invite-notify", "standard-replies", "labeled-response", "extended-monitor", "no-implicit-names"}
+var requestedCaps = []string{"sasl", "server-time", "echo-message", "message-tags", "batch", "draft/chathistory", "chathistory", "draft/event-playback", "multi-prefix", "cap-notify", "away-notify", "account-notify", "extended-join", "chghost", "account-tag", "userhost-in-names", "setname", "invite-notify", "standard-replies", "labeled-response", "extended-monitor", "no-implicit-names", "draft/metadata-2"}
 
 // requestCapsForLibrary returns the caps the library should CAP REQ. It is
 // requestedCaps minus "sts" (informational metadata, never requested) and "sasl"
@@ -84,55 +85,89 @@ type IRCClient struct {
 	loopDone              chan struct{} // Closed when the library's Loop() goroutine fully exits; lets teardown wait for a clean stop (guarded by mu)
 	saslEnabled           bool
 	saslAuthenticated     bool
-	authFailed            bool                       // True when SASL was enabled but did not succeed this session (guarded by mu)
-	saslConfigErr         error                      // Mechanism-construction error from NewIRCClient (unknown mechanism); surfaced by Connect() before dialing
-	namesInProgress       map[string]bool            // Track channels currently receiving NAMES list
-	namesMu               sync.Mutex                 // Mutex for namesInProgress map
-	serverCapabilities    *ServerCapabilities        // Server capabilities from ISUPPORT
-	chanTypesAtomic       atomic.Pointer[string]     // CHANTYPES from ISUPPORT (e.g. "#&"); lock-free so channel detection is callable anywhere
-	caseMappingAtomic     atomic.Pointer[string]     // CASEMAPPING from ISUPPORT (e.g. "ascii"); lock-free so nick folding is callable anywhere
-	supportsWHOX          bool                       // Server advertised the WHOX token in ISUPPORT (guarded by mu)
-	supportsMonitor       bool                       // Server advertised the MONITOR token in ISUPPORT (guarded by mu)
-	monitorLimit          int                        // MONITOR=<limit> from ISUPPORT; 0 = unlimited/unknown (guarded by mu)
-	monitorStatus         map[string]bool            // MONITOR presence: lowercased nick -> online (guarded by monitorMu)
-	monitorArmed          map[string]bool            // Nicks currently on the server MONITOR list (guarded by monitorMu)
-	monitorMu             sync.Mutex                 // Mutex for monitorStatus and monitorArmed
-	whoisInProgress       map[string]*WhoisInfo      // Track WHOIS requests in progress (key: nickname)
-	whoisMu               sync.Mutex                 // Mutex for whoisInProgress map
-	whoPending            map[string]bool            // Targets of user-initiated /who awaiting replies (key: folded mask); distinguishes 352/315 from roster-seed WHOX (guarded by whoMu)
-	whoMu                 sync.Mutex                 // Mutex for whoPending map
-	knownBots             map[string]bool            // Nicks recognized as IRCv3 bots this session (key: lowercased nick)
-	knownBotsMu           sync.Mutex                 // Mutex for knownBots map
-	userMeta              map[string]*UserMeta       // Live roster attributes (away/account/host) this session (key: lowercased nick)
-	userMetaMu            sync.Mutex                 // Mutex for userMeta map
-	metaEmitOnce          sync.Once                  // Lazily starts the user-meta forwarder goroutine
-	metaEmitStopOnce      sync.Once                  // Guards the single close of metaEmitStop
-	metaEmitMu            sync.Mutex                 // Guards metaPending, metaEmitSignal, metaEmitStop
-	metaPending           map[string]UserMeta        // Coalesced pending user-meta snapshots awaiting emit (key: lowercased nick)
-	metaEmitSignal        chan struct{}              // Wakes the forwarder (buffered 1)
-	metaEmitStop          chan struct{}              // Closed on teardown to stop the forwarder
-	callbacks             *callbackDispatcher        // Ordered application work kept off irc-go's socket read goroutine
-	registrationApplied   chan struct{}              // Closed after the current registration's queued callbacks have been applied (guarded by mu)
-	registrationApplyOnce *sync.Once                 // Guards registrationApplied for duplicate 376/422 replies (guarded by mu)
-	automaticRequests     *outboundRequestDispatcher // Serialized, rate-limited protocol requests generated by callbacks
-	autoJoinOnce          *sync.Once                 // Guards the one auto-join per connection; re-created each Connect (guarded by mu)
-	autoJoinAction        func()                     // What triggerAutoJoin runs once per connection; defaults to doAutoJoin (injectable for tests)
-	enabledCaps           map[string]bool            // IRCv3 capabilities granted by the server
-	chatHistoryMaxBatch   int                        // Max messages per CHATHISTORY request, from the chathistory=N cap value (0 = unknown, use default)
-	channelListItems      []ChannelListItem          // Temporary storage for LIST response
-	channelListMu         sync.Mutex                 // Mutex for channelListItems
-	banLists              map[string][]BanEntry      // Per-channel ban entries collected between 367 and 368
-	banListsMu            sync.Mutex                 // Mutex for banLists
-	rateLimiter           *RateLimiter               // Rate limiter for outgoing messages
-	currentNick           string                     // Nick the server currently knows us by; differs from the preferred nick during a collision (guarded by mu)
-	selfAway              bool                       // Server-acknowledged away state for our current nick (guarded by mu)
-	selfAwayMessage       string                     // Requested away reason committed by RPL_NOWAWAY (guarded by mu)
-	pendingAwayMessage    string                     // Away reason awaiting 305/306 acknowledgement (guarded by mu)
-	awayRequestPending    bool                       // Distinguishes a pending clear (empty message) from no request (guarded by mu)
-	nickCollisionNotified bool                       // True once we've told the user (one time) that the preferred nick was unavailable (guarded by mu)
-	pendingManualNick     string                     // Nick the user explicitly asked for via /nick and is awaiting; lets us surface a failure that the library's silent background reclaims would otherwise hide (guarded by mu)
-	reconnecting          bool                       // True when this connection is an auto-reconnect after an unexpected drop (guarded by mu)
-	pendingJoinKeys       map[string]string          // Case-folded channel -> key from a user-initiated JOIN, persisted when our JOIN echo confirms it worked (guarded by mu)
+	authFailed            bool                                 // True when SASL was enabled but did not succeed this session (guarded by mu)
+	saslConfigErr         error                                // Mechanism-construction error from NewIRCClient (unknown mechanism); surfaced by Connect() before dialing
+	namesInProgress       map[string]bool                      // Track channels currently receiving NAMES list
+	namesMu               sync.Mutex                           // Mutex for namesInProgress map
+	serverCapabilities    *ServerCapabilities                  // Server capabilities from ISUPPORT
+	chanTypesAtomic       atomic.Pointer[string]               // CHANTYPES from ISUPPORT (e.g. "#&"); lock-free so channel detection is callable anywhere
+	caseMappingAtomic     atomic.Pointer[string]               // CASEMAPPING from ISUPPORT (e.g. "ascii"); lock-free so nick folding is callable anywhere
+	supportsWHOX          bool                                 // Server advertised the WHOX token in ISUPPORT (guarded by mu)
+	supportsMonitor       bool                                 // Server advertised the MONITOR token in ISUPPORT (guarded by mu)
+	monitorLimit          int                                  // MONITOR=<limit> from ISUPPORT; 0 = unlimited/unknown (guarded by mu)
+	monitorStatus         map[string]bool                      // MONITOR presence: lowercased nick -> online (guarded by monitorMu)
+	monitorArmed          map[string]bool                      // Nicks currently on the server MONITOR list (guarded by monitorMu)
+	monitorMu             sync.Mutex                           // Mutex for monitorStatus and monitorArmed
+	whoisInProgress       map[string]*WhoisInfo                // Track WHOIS requests in progress (key: nickname)
+	whoisMu               sync.Mutex                           // Mutex for whoisInProgress map
+	whoPending            map[string]bool                      // Targets of user-initiated /who awaiting replies (key: folded mask); distinguishes 352/315 from roster-seed WHOX (guarded by whoMu)
+	whoMu                 sync.Mutex                           // Mutex for whoPending map
+	knownBots             map[string]bool                      // Nicks recognized as IRCv3 bots this session (key: lowercased nick)
+	knownBotsMu           sync.Mutex                           // Mutex for knownBots map
+	userMeta              map[string]*UserMeta                 // Live roster attributes (away/account/host) this session (key: lowercased nick)
+	userMetaMu            sync.Mutex                           // Mutex for userMeta map
+	metaEmitOnce          sync.Once                            // Lazily starts the user-meta forwarder goroutine
+	metaEmitStopOnce      sync.Once                            // Guards the single close of metaEmitStop
+	metaEmitMu            sync.Mutex                           // Guards metaPending, metaEmitSignal, metaEmitStop
+	metaPending           map[string]UserMeta                  // Coalesced pending user-meta snapshots awaiting emit (key: lowercased nick)
+	metaEmitSignal        chan struct{}                        // Wakes the forwarder (buffered 1)
+	metaEmitStop          chan struct{}                        // Closed on teardown to stop the forwarder
+	callbacks             *callbackDispatcher                  // Ordered application work kept off irc-go's socket read goroutine
+	registrationApplied   chan struct{}                        // Closed after the current registration's queued callbacks have been applied (guarded by mu)
+	registrationApplyOnce *sync.Once                           // Guards registrationApplied for duplicate 376/422 replies (guarded by mu)
+	automaticRequests     *outboundRequestDispatcher           // Serialized, rate-limited protocol requests generated by callbacks
+	autoJoinOnce          *sync.Once                           // Guards the one auto-join per connection; re-created each Connect (guarded by mu)
+	autoJoinAction        func()                               // What triggerAutoJoin runs once per connection; defaults to doAutoJoin (injectable for tests)
+	performAction         func()                               // Runs before autoJoinAction each connection, e.g. the network's perform list (guarded by mu; see SetPerformAction)
+	noticeWaiters         []*NoticeWaiter                      // Armed ExpectNotice waiters (guarded by noticeWaitersMu)
+	noticeWaitersMu       sync.Mutex                           // Mutex for noticeWaiters
+	enabledCaps           map[string]bool                      // IRCv3 capabilities granted by the server
+	chatHistoryMaxBatch   int                                  // Max messages per CHATHISTORY request, from the chathistory=N cap value (0 = unknown, use default)
+	channelListItems      []ChannelListItem                    // Temporary storage for LIST response
+	channelListMu         sync.Mutex                           // Mutex for channelListItems and channelListFilter
+	channelListFilter     string                               // LIST argument of the request being answered
+	listModeEntries       map[string][]BanEntry                // Per-channel, per-mode list entries collected until the end numeric (e.g. 367 until 368)
+	listModeEntriesMu     sync.Mutex                           // Mutex for listModeEntries
+	ctcp                  ctcpResponder                        // CTCP reply settings, flood buckets and /ctcp reply routing (own mutex)
+	lineObserver          atomic.Pointer[func(ircmsg.Message)] // Optional tap on inbound lines and local echoes of our messages (see SetLineObserver)
+	outbound              *outboundScheduler                   // Paces every line we write against the network's flood profile, by send class
+	pasteMu               sync.Mutex                           // Guards pasteTail, pasteEpoch and the paste backlog counters
+	pasteTail             chan struct{}                        // Closed when the most recently queued paste has finished; the next one waits on it
+	pasteEpoch            int64                                // Bumped by ClearPasteQueue; a paste from an older epoch stops
+	pasteBacklog          int                                  // Paste chunks queued but not yet written
+	pasteBacklogBytes     int                                  // Wire bytes of pasteBacklog
+	currentNick           string                               // Nick the server currently knows us by; differs from the preferred nick during a collision (guarded by mu)
+	selfAway              bool                                 // Server-acknowledged away state for our current nick (guarded by mu)
+	selfAwayMessage       string                               // Requested away reason committed by RPL_NOWAWAY (guarded by mu)
+	pendingAwayMessage    string                               // Away reason awaiting 305/306 acknowledgement (guarded by mu)
+	awayRequestPending    bool                                 // Distinguishes a pending clear (empty message) from no request (guarded by mu)
+	nickCollisionNotified bool                                 // True once we've told the user (one time) that the preferred nick was unavailable (guarded by mu)
+	pendingManualNick     string                               // Nick the user explicitly asked for via /nick and is awaiting; lets us surface a failure that the library's silent background reclaims would otherwise hide (guarded by mu)
+	reconnecting          bool                                 // True when this connection is an auto-reconnect after an unexpected drop (guarded by mu)
+	pendingJoinKeys       map[string]string                    // Case-folded channel -> key from a user-initiated JOIN, persisted when our JOIN echo confirms it worked (guarded by mu)
+	splits                map[string]*netsplit                 // Detected netsplits by "server1 server2", grouping their QUITs and later rejoins (guarded by splitMu)
+	splitMu               sync.Mutex                           // Mutex for splits
+	speakers              map[string]map[string]time.Time      // Folded channel -> folded nick -> when they last spoke there, for the smart join/part/quit filter (guarded by speakersMu)
+	filteredJoins         map[string]map[string]time.Time      // Folded channel -> folded nick -> when their filtered join was stored, un-hidden if they speak within the window (guarded by speakersMu)
+	servicesAccount       string                               // NickServ account for services login without SASL; "" means the preferred nick (guarded by mu)
+	servicesPassword      string                               // NickServ password; "" turns the services automation off (guarded by mu)
+	nickRecoveredAt       time.Time                            // When we last sent GHOST/REGAIN, to rate-limit recovery (guarded by mu)
+	servicesJoins         map[string]bool                      // Folded channels we asked ChanServ to let us into, awaiting the retried join (guarded by mu)
+	servicesEchoes        map[string]int                       // Folded target + text of password-carrying services lines whose echo must not be stored (guarded by mu)
+	registrationSupport   AccountRegistrationSupport           // draft/account-registration as advertised in CAP LS (guarded by mu)
+	queuedRegistration    *AccountRegistration                 // Registration to send on the next connection (guarded by mu; see QueueAccountRegistration)
+	pendingRegistration   *AccountRegistration                 // REGISTER in flight or awaiting VERIFY (guarded by mu)
+	registrationDone      chan struct{}                        // Closed when the REGISTER in flight is answered (guarded by mu)
+	awaitingVerification  bool                                 // The pending registration needs a VERIFY code (guarded by mu)
+	onAccountRegistered   func(account, password string)       // Runs when a registered account is ready to log in to (guarded by mu)
+	e2eIdentity           e2e.Identity                         // Our long-term identity key on this network (guarded by mu; see encryption.go)
+	e2eSessions           map[string]*e2e.Session              // Folded peer nick -> established end-to-end session (guarded by mu)
+	e2eHandshakes         map[string]*e2e.Handshake            // Folded peer nick -> our E2E INIT awaiting ACCEPT (guarded by mu)
+	e2eTrusted            map[string]string                    // Folded peer nick -> fingerprint the user verified (guarded by mu)
+	e2eOffers             map[string]string                    // Folded peer nick -> their E2E INIT awaiting the user's accept (guarded by mu)
+	e2eBroken             map[string]bool                      // Folded peer nick -> the session is no longer usable and sending is refused (guarded by mu)
+	fishKeys              map[string]string                    // Folded channel or nick -> FiSH CBC key (guarded by mu)
+	speakersMu            sync.Mutex                           // Mutex for speakers and filteredJoins
 }
 
 // ServerCapabilities stores parsed ISUPPORT information
@@ -461,7 +496,8 @@ func (c *IRCClient) surfaceManualNickError(attempted, message string) bool {
 // our own and surface only a single, one-time notice while still unregistered —
 // the post-registration reclaim attempts stay silent so the log isn't spammed
 // every keepalive interval. A 433 answering the user's explicit /nick is the
-// exception: it's surfaced regardless of registration.
+// exception: it's surfaced regardless of registration. After registration, a
+// configured services login lets recoverNick free the nick with GHOST/REGAIN.
 func (c *IRCClient) handleNickInUse(e ircmsg.Message) {
 	attempted := nickErrorAttempt(e)
 	if c.surfaceManualNickError(attempted, fmt.Sprintf("Couldn't change nick to %q — it's already in use.", attempted)) {
@@ -476,7 +512,12 @@ func (c *IRCClient) handleNickInUse(e ircmsg.Message) {
 	}
 	c.mu.Unlock()
 
-	if registered || alreadyNotified {
+	if registered {
+		// With a services login, free the nick rather than wait for it.
+		go c.recoverNick()
+		return
+	}
+	if alreadyNotified {
 		return
 	}
 	c.writeStatusBuffer(storage.Message{
@@ -563,6 +604,7 @@ func (c *IRCClient) handleJoin(e ircmsg.Message) {
 	c.maybeApplyAccountTag(e)
 	// extended-join: when negotiated, JOIN carries the joiner's account.
 	c.maybeApplyExtendedJoin(e)
+	c.recordSeen(e, user, SeenJoin, channel, "", "")
 
 	logger.Log.Debug().
 		Str("user", user).
@@ -606,6 +648,7 @@ func (c *IRCClient) handleJoin(e ircmsg.Message) {
 		// The join succeeded, so the key it was sent with (possibly none)
 		// is now the channel's truth — persist it for auto-rejoin (+k).
 		c.persistPendingJoinKey(channel, ch)
+		c.noteServicesJoin(channel)
 	}
 
 	// Add user to channel user list (for all users, not just ourselves)
@@ -644,18 +687,27 @@ func (c *IRCClient) handleJoin(e ircmsg.Message) {
 		}
 	}
 
+	// A user returning from a netsplit is summarized with the rest of the
+	// netjoin instead of getting a join line and event of their own.
+	netjoin := ch != nil && !c.isMe(user) && c.noteNetjoin(user, ch)
+
 	// Store join message in the channel (use sync write so it appears immediately)
-	if ch != nil {
+	if ch != nil && !netjoin {
 		rawLine, _ := e.Line()
 		joinMsg := storage.Message{
 			NetworkID:   c.networkID,
 			ChannelID:   &ch.ID,
 			User:        user,
+			Account:     c.accountFor(user),
 			Message:     fmt.Sprintf("%s joined the channel", user),
 			MessageType: "join",
 			Timestamp:   time.Now(),
 			RawLine:     rawLine,
 			MsgID:       c.getMsgID(e),
+			Filtered:    c.smartFiltered(ch, user),
+		}
+		if joinMsg.Filtered {
+			c.noteFilteredJoin(channel, user, joinMsg.Timestamp)
 		}
 		if err := c.storage.WriteMessageSync(joinMsg); err != nil {
 			// During shutdown, storage may be closed - this is expected
@@ -668,18 +720,20 @@ func (c *IRCClient) handleJoin(e ircmsg.Message) {
 	}
 
 	// Emit event
-	c.eventBus.Emit(events.Event{
-		Type: EventUserJoined,
-		Data: map[string]interface{}{
-			"network":     c.network.Address,
-			"networkName": c.network.Name,
-			"networkId":   c.networkID,
-			"channel":     channel,
-			"user":        user,
-		},
-		Timestamp: time.Now(),
-		Source:    events.EventSourceIRC,
-	})
+	if !netjoin {
+		c.eventBus.Emit(events.Event{
+			Type: EventUserJoined,
+			Data: map[string]interface{}{
+				"network":     c.network.Address,
+				"networkName": c.network.Name,
+				"networkId":   c.networkID,
+				"channel":     channel,
+				"user":        user,
+			},
+			Timestamp: time.Now(),
+			Source:    events.EventSourceIRC,
+		})
+	}
 
 	// Emit channels changed event if channel was created or updated
 	if channelCreated || (channelUpdated && c.isMe(user)) {
@@ -706,6 +760,8 @@ func (c *IRCClient) handlePart(e ircmsg.Message) {
 	if len(e.Params) > 1 {
 		reason = e.Params[1]
 	}
+	c.recordSeen(e, user, SeenPart, channel, "", reason)
+	c.forgetSplitUser(user)
 
 	// Get channel and remove user from user list
 	ch, err := c.storage.GetChannelByName(c.networkID, channel)
@@ -732,6 +788,7 @@ func (c *IRCClient) handlePart(e ircmsg.Message) {
 			NetworkID: c.networkID,
 			ChannelID: &ch.ID,
 			User:      user,
+			Account:   c.accountFor(user),
 			Message: fmt.Sprintf("%s left the channel%s", user, func() string {
 				if reason != "" {
 					return fmt.Sprintf(" (%s)", reason)
@@ -742,6 +799,7 @@ func (c *IRCClient) handlePart(e ircmsg.Message) {
 			Timestamp:   time.Now(),
 			RawLine:     rawLine,
 			MsgID:       c.getMsgID(e),
+			Filtered:    c.smartFiltered(ch, user),
 		}
 		if err := c.storage.WriteMessageSync(partMsg); err != nil {
 			// During shutdown, storage may be closed - this is expected
@@ -752,6 +810,11 @@ func (c *IRCClient) handlePart(e ircmsg.Message) {
 			}
 		}
 	}
+	if c.isMe(user) {
+		c.forgetChannelSpeakers(channel)
+	} else {
+		c.forgetSpeaker(channel, user)
+	}
 
 	c.eventBus.Emit(events.Event{
 		Type: EventUserParted,
@@ -797,6 +860,14 @@ func (c *IRCClient) handleQuit(e ircmsg.Message) {
 	// Snapshot metadata before removing it so downstream consumers receive the
 	// identity that belonged to this quit event.
 	meta, _ := c.UserMetaFor(user)
+	c.recordSeen(e, user, SeenQuit, "", "", reason)
+	// A netsplit QUIT updates the roster like any other, but its quit line and
+	// event are folded into one summary per channel (see noteNetsplitQuit).
+	server1, server2, inSplit := parseNetsplitReason(reason)
+	var splitChannels []storage.Channel
+	if !inSplit {
+		c.forgetSplitUser(user)
+	}
 
 	// The user left the network entirely, so drop their live roster
 	// attributes (away/account/host). PART/KICK deliberately don't do this.
@@ -821,12 +892,18 @@ func (c *IRCClient) handleQuit(e ircmsg.Message) {
 							_, _ = c.storage.GetChannelUsers(ch.ID)
 						}
 
+						if inSplit {
+							splitChannels = append(splitChannels, ch)
+							break
+						}
+
 						// Store quit message in the channel
 						rawLine, _ := e.Line()
 						quitMsg := storage.Message{
 							NetworkID: c.networkID,
 							ChannelID: &ch.ID,
 							User:      user,
+							Account:   meta.Account,
 							Message: fmt.Sprintf("%s quit%s", user, func() string {
 								if reason != "" {
 									return fmt.Sprintf(" (%s)", reason)
@@ -837,6 +914,7 @@ func (c *IRCClient) handleQuit(e ircmsg.Message) {
 							Timestamp:   time.Now(),
 							RawLine:     rawLine,
 							MsgID:       c.getMsgID(e),
+							Filtered:    c.smartFiltered(&ch, user),
 						}
 						if err := c.storage.WriteMessageSync(quitMsg); err != nil {
 							// During shutdown, storage may be closed - this is expected
@@ -846,6 +924,7 @@ func (c *IRCClient) handleQuit(e ircmsg.Message) {
 								logger.Log.Error().Err(err).Msg("Failed to store quit message")
 							}
 						}
+						c.forgetSpeaker(ch.Name, user)
 						break
 					}
 				}
@@ -853,6 +932,11 @@ func (c *IRCClient) handleQuit(e ircmsg.Message) {
 		}
 	}
 
+	if inSplit {
+		c.noteNetsplitQuit(server1, server2, user, splitChannels)
+		return
+	}
+
 	c.eventBus.Emit(events.Event{
 		Type: EventUserQuit,
 		Data: map[string]interface{}{
@@ -885,6 +969,7 @@ func (c *IRCClient) handleKick(e ircmsg.Message) {
 	if len(e.Params) > 2 {
 		reason = e.Params[2]
 	}
+	c.recordSeen(e, kickedUser, SeenKicked, channel, kicker, reason)
 
 	// Get channel from database
 	ch, err := c.storage.GetChannelByName(c.networkID, channel)
@@ -904,6 +989,9 @@ func (c *IRCClient) handleKick(e ircmsg.Message) {
 		if c.isMe(kickedUser) {
 			c.storage.UpdateChannelIsOpen(ch.ID, false)
 			channelUpdated = true
+			c.forgetChannelSpeakers(channel)
+		} else {
+			c.forgetSpeaker(channel, kickedUser)
 		}
 
 		// Store kick message in the channel (use sync write so it appears immediately)
@@ -912,6 +1000,7 @@ func (c *IRCClient) handleKick(e ircmsg.Message) {
 			NetworkID: c.networkID,
 			ChannelID: &ch.ID,
 			User:      kicker,
+			Account:   c.accountFor(kicker),
 			Message: fmt.Sprintf("%s kicked %s%s", kicker, kickedUser, func() string {
 				if reason != "" {
 					return fmt.Sprintf(" (%s)", reason)
@@ -993,21 +1082,22 @@ func (c *IRCClient) handleMode(e ircmsg.Message) {
 	c.mu.RUnlock()
 
 	changes := ParseModeChanges(e.Params[1], e.Params[2:], cls)
+	actor := e.Nick()
+	if actor == "" {
+		actor = e.Source
+	}
 
 	// Surface the change in the channel itself (like join/part/kick), faithfully
 	// mirroring what the server applied: "<actor> sets mode: +o-v+k a b key".
 	// Bare list-mode *queries* (e.g. ban-list fetches) never reach here — the
 	// server answers those with 367/368, not a MODE echo — so this won't fire for them.
 	if len(changes) > 0 {
-		actor := e.Nick()
-		if actor == "" {
-			actor = e.Source
-		}
 		rawLine, _ := e.Line()
 		c.storage.WriteMessageSync(storage.Message{
 			NetworkID:   c.networkID,
 			ChannelID:   &ch.ID,
 			User:        actor,
+			Account:     c.accountFor(actor),
 			Message:     fmt.Sprintf("%s sets mode: %s", actor, strings.Join(e.Params[1:], " ")),
 			MessageType: "mode",
 			Timestamp:   time.Now(),
@@ -1016,6 +1106,9 @@ func (c *IRCClient) handleMode(e ircmsg.Message) {
 		})
 	}
 
+	// Keep the stored ban/exception/invex lists in step with the channel.
+	c.recordListModeChanges(target, actor, changes)
+
 	// Fold channel-level changes (D flags + B/C params) into the canonical mode
 	// string, persisting only when it actually changed.
 	newModes := applyChannelModes(ch.Modes, changes, cls)
@@ -1143,6 +1236,12 @@ func (c *IRCClient) handleNickMessage(e ircmsg.Message) {
 	// Carry live roster attributes (away/account/host) over to the new nick so
 	// badges and dimming don't go stale on a rename.
 	c.renameUserMeta(oldNick, newNick)
+	c.renameSpeaker(oldNick, newNick)
+	c.renameEncryptionPeer(oldNick, newNick)
+	c.forgetSplitUser(oldNick)
+	c.forgetSplitUser(newNick)
+	c.recordSeen(e, oldNick, SeenNickTo, "", newNick, "")
+	c.recordSeen(e, newNick, SeenNickFrom, "", oldNick, "")
 
 	c.eventBus.Emit(events.Event{
 		Type: EventUserNick,
@@ -1219,19 +1318,25 @@ func NewIRCClient(network *storage.Network, eventBus *events.EventBus, storage *
 		callbacks:         newCallbackDispatcher(),
 		autoJoinOnce:      &sync.Once{},
 		enabledCaps:       make(map[string]bool),
-		banLists:          make(map[string][]BanEntry),
+		listModeEntries:   make(map[string][]BanEntry),
 		serverCapabilities: &ServerCapabilities{
 			Prefix:       make(map[rune]rune),
 			PrefixString: "",
 			ChanModes:    "",
 		},
-		rateLimiter: NewRateLimiter(4, 2*time.Second),
 	}
+	profile, err := FloodProfileNamed(network.FloodProfile)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("network", network.Name).Msg("Using the standard flood profile")
+		profile, _ = FloodProfileNamed(FloodStandard)
+	}
+	client.outbound = newOutboundScheduler(profile)
 	// Automatic requests such as NAMES and WHO can amplify one short command into
-	// thousands of reply rows. Give them a deliberately conservative budget that
-	// is independent of ordinary user messages and auto-join commands.
-	automaticLimiter := NewRateLimiter(1, time.Second)
-	client.automaticRequests = newOutboundRequestDispatcher(automaticLimiter.Wait)
+	// thousands of reply rows. They share the network's budget at the lowest
+	// priority and are further spaced by the profile's automation gap.
+	client.automaticRequests = newOutboundRequestDispatcher(func() {
+		client.outbound.wait(SendAutomation, automaticRequestSize)
+	})
 
 	// Debug: Log SASL configuration
 	if network.SASLEnabled {
@@ -1288,7 +1393,11 @@ func NewIRCClient(network *storage.Network, eventBus *events.EventBus, storage *
 		// Constraint: KeepAlive must be >= Timeout.
 		Timeout:   constants.ConnectionReadTimeout,
 		KeepAlive: constants.ConnectionKeepAlive,
+		OnRead:    client.observeLine,
 	}
+	// A queued account registration goes out before CAP END when the server
+	// allows it (see beforeCapEnd).
+	client.conn.BeforeCapEnd = client.beforeCapEnd
 
 	// Auto-join runs once per connection through triggerAutoJoin; doAutoJoin is the
 	// real action (overridable in tests, which have no live connection to JOIN on).
@@ -1526,6 +1635,11 @@ func (c *IRCClient) publishUserMeta(nick string, meta UserMeta) {
 			"account":      meta.Account,
 			"host":         meta.Host,
 			"realname":     meta.Realname,
+			"avatar":       meta.Avatar,
+			"display_name": meta.DisplayName,
+			"pronouns":     meta.Pronouns,
+			"homepage":     meta.Homepage,
+			"status":       meta.Status,
 		},
 		Timestamp: time.Now(),
 		Source:    events.EventSourceIRC,
@@ -1692,6 +1806,7 @@ func (c *IRCClient) handleAccount(e ircmsg.Message) {
 		account = ""
 	}
 	c.applyUserMeta(e.Nick(), func(m *UserMeta) { m.Account = account })
+	c.recordSeen(e, e.Nick(), SeenAccount, "", account, "")
 }
 
 // handleChghost processes chghost: ":nick CHGHOST <newuser> <newhost>" — the
@@ -1702,6 +1817,7 @@ func (c *IRCClient) handleChghost(e ircmsg.Message) {
 	}
 	host := e.Params[0] + "@" + e.Params[1]
 	c.applyUserMeta(e.Nick(), func(m *UserMeta) { m.Host = host })
+	c.recordSeen(e, e.Nick(), SeenChghost, "", "", "")
 }
 
 // maybeApplyExtendedJoin records the joiner's account and realname from an
@@ -1875,6 +1991,9 @@ func (c *IRCClient) handleStandardReply(e ircmsg.Message, messageType string) {
 		text = fmt.Sprintf("%s %s: %s", e.Command, command, description)
 	}
 	c.writeStatusLine(messageType, text)
+	if e.Command == "FAIL" && (command == "REGISTER" || command == "VERIFY") {
+		c.registrationFailed(code, description)
+	}
 }
 
 // genericErrorNumerics are server error replies that carry a human-readable
@@ -2000,6 +2119,22 @@ func (c *IRCClient) accountFor(nick string) string {
 	return ""
 }
 
+// historyAccount returns the account a CHATHISTORY-replayed line was sent
+// under: its account-tag, or the account field of an extended-join JOIN. The
+// live roster is not consulted — it describes the sender now, not then.
+func (c *IRCClient) historyAccount(e ircmsg.Message) string {
+	account := ""
+	if present, tag := e.GetTag("account"); present {
+		account = tag
+	} else if e.Command == "JOIN" && c.capEnabled("extended-join") && len(e.Params) >= 2 {
+		account = e.Params[1]
+	}
+	if account == "*" {
+		return ""
+	}
+	return account
+}
+
 // emitChannelsChanged announces that this network's sidebar list (channels or PM
 // conversations) changed, so open windows re-fetch it. The PM handlers call this
 // when a brand-new DM conversation is created: an unsolicited message from a peer
@@ -2029,6 +2164,17 @@ func (c *IRCClient) handlePrivmsg(e ircmsg.Message) {
 	c.maybeMarkBotFromTag(e)
 	// account-tag: learn the sender's account from the `@account` tag.
 	c.maybeApplyAccountTag(e)
+	// Encrypted conversations are decrypted before anything reads the text.
+	message, encrypted, ok := c.decryptIncoming(user, channel, message)
+	if !ok {
+		return
+	}
+	// The echo of a services login must not reach history.
+	if c.isMe(user) && c.takeServicesEcho(channel, message) {
+		return
+	}
+	c.recordSeenMessage(e, user, channel, message, SeenMessage)
+	c.noteSpoke(e, channel, user, message)
 
 	// Check if this is a CTCP message (wrapped in \001)
 	if len(message) >= 2 && message[0] == '\001' && message[len(message)-1] == '\001' {
@@ -2041,6 +2187,19 @@ func (c *IRCClient) handlePrivmsg(e ircmsg.Message) {
 				ctcpArgs = strings.Join(parts[1:], " ")
 			}
 
+			// End-to-end handshakes arrive privately and in the clear, and an
+			// END may come sealed in the session; our own echo is neither.
+			if ctcpCommand == e2e.CTCPCommand {
+				if c.isMe(channel) && !c.isMe(user) {
+					if encrypted {
+						c.handleSealedE2ECTCP(user, ctcpArgs)
+					} else {
+						c.handleE2ECTCP(user, ctcpArgs)
+					}
+				}
+				return
+			}
+
 			// DCC is negotiated over CTCP but owns independent TCP sessions. Emit
 			// the control payload without treating it as a chat message or a generic
 			// CTCP request; the App-level DCC manager applies feature gating.
@@ -2085,6 +2244,7 @@ func (c *IRCClient) handlePrivmsg(e ircmsg.Message) {
 					NetworkID:      c.networkID,
 					ChannelID:      channelID,
 					User:           user,
+					Account:        c.accountFor(user),
 					Message:        fmt.Sprintf("* %s %s", user, ctcpArgs),
 					MessageType:    "action",
 					Timestamp:      c.getMessageTime(e),
@@ -2093,6 +2253,7 @@ func (c *IRCClient) handlePrivmsg(e ircmsg.Message) {
 					MsgID:          c.getMsgID(e),
 					ReplyMsgID:     c.getReplyTag(e),
 					ChannelContext: c.getChannelContext(e),
+					Encrypted:      encrypted,
 				}
 				c.storage.WriteMessageSync(msg)
 				c.eventBus.Emit(events.Event{
@@ -2108,6 +2269,7 @@ func (c *IRCClient) handlePrivmsg(e ircmsg.Message) {
 						"msgid":       c.getMsgID(e),
 						"messageUnix": c.getMessageTime(e).Unix(),
 						"isAction":    true,
+						"encrypted":   encrypted,
 					},
 					Timestamp: time.Now(),
 					Source:    events.EventSourceIRC,
@@ -2167,6 +2329,7 @@ func (c *IRCClient) handlePrivmsg(e ircmsg.Message) {
 		NetworkID:      c.networkID,
 		ChannelID:      channelID,
 		User:           user,
+		Account:        c.accountFor(user),
 		Message:        message,
 		MessageType:    "privmsg",
 		Timestamp:      c.getMessageTime(e),
@@ -2175,6 +2338,7 @@ func (c *IRCClient) handlePrivmsg(e ircmsg.Message) {
 		MsgID:          c.getMsgID(e),
 		ReplyMsgID:     c.getReplyTag(e),
 		ChannelContext: c.getChannelContext(e),
+		Encrypted:      encrypted,
 	}
 
 	// Store message (use sync write so it appears immediately)
@@ -2208,6 +2372,7 @@ func (c *IRCClient) handlePrivmsg(e ircmsg.Message) {
 			"messageType":    msg.MessageType,
 			"replyMsgid":     c.getReplyTag(e),
 			"channelContext": c.getChannelContext(e),
+			"encrypted":      encrypted,
 		},
 		Timestamp: time.Now(),
 		Source:    events.EventSourceIRC,
@@ -2308,6 +2473,7 @@ func (c *IRCClient) onDisconnect(e ircmsg.Message) {
 	if automaticRequests != nil {
 		automaticRequests.stopNow()
 	}
+	c.outbound.close()
 
 	// Also set the LIBRARY's quit flag, or the abandoned flag never gets its
 	// chance to matter: Loop() re-checks isQuitting() right after this callback
@@ -2530,6 +2696,13 @@ func (c *IRCClient) setupHandlers() {
 	c.addCallback("FAIL", func(e ircmsg.Message) { c.handleStandardReply(e, "error") })
 	c.addCallback("WARN", func(e ircmsg.Message) { c.handleStandardReply(e, "warning") })
 	c.addCallback("NOTE", func(e ircmsg.Message) { c.handleStandardReply(e, "status") })
+	c.addCallback("REGISTER", c.handleRegisterReply)
+	c.addCallback("VERIFY", c.handleVerifyReply)
+	c.addCallback("METADATA", c.handleMetadata)
+	c.addCallback("760", c.handleMetadataKeyValue)  // RPL_WHOISKEYVALUE
+	c.addCallback("761", c.handleMetadataKeyValue)  // RPL_KEYVALUE
+	c.addCallback("766", c.handleMetadataKeyNotSet) // RPL_KEYNOTSET
+	c.addCallback("774", c.handleMetadataSyncLater) // RPL_METADATASYNCLATER
 
 	// Channel topic (RPL_TOPIC = 332) - received when topic is retrieved
 	c.addCallback("332", func(e ircmsg.Message) {
@@ -2909,53 +3082,8 @@ func (c *IRCClient) setupHandlers() {
 		})
 	})
 
-	// RPL_BANLIST (367) - a single ban entry: <channel> <mask> [<setter> <time>]
-	c.addCallback("367", func(e ircmsg.Message) {
-		if len(e.Params) < 3 {
-			return
-		}
-		channel := e.Params[1]
-		entry := BanEntry{Mask: e.Params[2]}
-		if len(e.Params) >= 4 {
-			entry.By = e.Params[3]
-		}
-		if len(e.Params) >= 5 {
-			fmt.Sscanf(e.Params[4], "%d", &entry.Time)
-		}
-		key := c.foldKey(channel)
-		c.banListsMu.Lock()
-		c.banLists[key] = append(c.banLists[key], entry)
-		c.banListsMu.Unlock()
-	})
-
-	// RPL_ENDOFBANLIST (368) - end of ban list; flush collected entries to the frontend.
-	c.addCallback("368", func(e ircmsg.Message) {
-		if len(e.Params) < 2 {
-			return
-		}
-		channel := e.Params[1]
-		key := c.foldKey(channel)
-		c.banListsMu.Lock()
-		entries := c.banLists[key]
-		delete(c.banLists, key)
-		c.banListsMu.Unlock()
-
-		bans := make([]interface{}, len(entries))
-		for i, b := range entries {
-			bans[i] = map[string]interface{}{"mask": b.Mask, "by": b.By, "time": b.Time}
-		}
-		c.eventBus.Emit(events.Event{
-			Type: EventChannelBanList,
-			Data: map[string]interface{}{
-				"network":   c.network.Address,
-				"networkId": c.networkID,
-				"channel":   channel,
-				"bans":      bans,
-			},
-			Timestamp: time.Now(),
-			Source:    events.EventSourceIRC,
-		})
-	})
+	// Channel list replies: 367/368 (bans), 348/349 (exceptions), 346/347 (invex)
+	c.registerListModeCallbacks()
 
 	// MOTD (Message of the Day) - store in status window
 	c.addCallback("372", func(e ircmsg.Message) {
@@ -3024,6 +3152,7 @@ func (c *IRCClient) setupHandlers() {
 	// preferred nick was taken). Registered after the welcome-line writer above
 	// so the log shows the welcome first; see handleWelcome.
 	c.addCallback("001", c.handleWelcome)
+	c.addCallback("001", func(e ircmsg.Message) { c.subscribeMetadata() })
 
 	// Auto-join is gated on registration completion. The end-of-MOTD numerics are
 	// the primary trigger because they guarantee ISUPPORT (005) has arrived, so
@@ -3273,11 +3402,13 @@ func (c *IRCClient) setupHandlers() {
 		if len(e.Params) >= 4 {
 			topic = e.Params[3]
 		}
+		modes, topic := parseListTopic(topic)
 
 		item := ChannelListItem{
 			Channel:   channelName,
 			Users:     usersCount,
 			Topic:     topic,
+			Modes:     modes,
 			NetworkID: c.networkID,
 		}
 
@@ -3289,29 +3420,20 @@ func (c *IRCClient) setupHandlers() {
 	// RPL_LISTEND (323) - End of LIST response
 	c.addCallback("323", func(e ircmsg.Message) {
 		c.channelListMu.Lock()
-		items := make([]ChannelListItem, len(c.channelListItems))
-		copy(items, c.channelListItems)
+		items := c.channelListItems
+		filter := c.channelListFilter
 		c.channelListItems = nil
+		c.channelListFilter = ""
 		c.channelListMu.Unlock()
 
-		logger.Log.Debug().Int64("network_id", c.networkID).Int("count", len(items)).Msg("Channel LIST completed")
-
-		// Convert items to interface slice for event data
-		itemsData := make([]interface{}, len(items))
-		for i, item := range items {
-			itemsData[i] = map[string]interface{}{
-				"channel":   item.Channel,
-				"users":     item.Users,
-				"topic":     item.Topic,
-				"networkId": item.NetworkID,
-			}
-		}
+		logger.Log.Debug().Int64("network_id", c.networkID).Int("count", len(items)).Str("filter", filter).Msg("Channel LIST completed")
 
 		c.eventBus.Emit(events.Event{
 			Type: EventChannelListEnd,
 			Data: map[string]interface{}{
 				"networkId": c.networkID,
-				"channels":  itemsData,
+				"channels":  items,
+				"filter":    filter,
 			},
 			Timestamp: time.Now(),
 			Source:    events.EventSourceIRC,
@@ -3451,7 +3573,9 @@ func (c *IRCClient) setupHandlers() {
 					})
 					// No CAP END here: negotiation already ended. The server's
 					// CAP ACK/NAK is handled by the cases above.
-					c.conn.SendRaw("CAP REQ :" + reqStr)
+					if c.outbound.wait(SendProtocol, lineSize(nil, "CAP", "REQ", reqStr)) {
+						c.conn.SendRaw("CAP REQ :" + reqStr)
+					}
 				}
 			}
 		case "DEL":
@@ -4385,6 +4509,7 @@ func (c *IRCClient) buildHistoryMessage(e ircmsg.Message, batchTarget string) (s
 		NetworkID:   c.networkID,
 		ChannelID:   channelID,
 		User:        user,
+		Account:     c.historyAccount(e),
 		Message:     text,
 		MessageType: messageType,
 		Timestamp:   c.getHistoryTime(e),
@@ -4441,6 +4566,7 @@ func (c *IRCClient) buildHistoryChatMessage(e ircmsg.Message) (storage.Message,
 		NetworkID:   c.networkID,
 		ChannelID:   channelID,
 		User:        user,
+		Account:     c.historyAccount(e),
 		Message:     text,
 		MessageType: messageType,
 		Timestamp:   c.getHistoryTime(e),
@@ -4622,6 +4748,7 @@ func (c *IRCClient) Connect() error {
 		if c.automaticRequests != nil {
 			c.automaticRequests.stopNow()
 		}
+		c.outbound.close()
 		if c.callbacks != nil {
 			c.callbacks.stopAfterDrain()
 		}
@@ -4644,6 +4771,7 @@ func (c *IRCClient) Connect() error {
 		if c.automaticRequests != nil {
 			c.automaticRequests.stopNow()
 		}
+		c.outbound.close()
 		if c.callbacks != nil {
 			c.callbacks.stopAfterDrain()
 		}
@@ -4730,7 +4858,7 @@ func (c *IRCClient) finishRegistrationCallbacks() {
 // triggerAutoJoin runs the one auto-join for this connection, guarded by
 // autoJoinOnce so the multiple registration signals that race to call it
 // (RPL_ENDOFMOTD 376, ERR_NOMOTD 422, and the 001-armed fallback timer) result
-// in exactly one join pass.
+// in exactly one join pass. The perform action, if any, runs first.
 func (c *IRCClient) triggerAutoJoin() {
 	c.mu.RLock()
 	once := c.autoJoinOnce
@@ -4739,7 +4867,14 @@ func (c *IRCClient) triggerAutoJoin() {
 	if once == nil || action == nil {
 		return
 	}
-	once.Do(func() { go action() })
+	once.Do(func() {
+		go func() {
+			c.registerQueuedAccount()
+			c.identifyWithServices()
+			c.runPerform()
+			action()
+		}()
+	})
 }
 
 // doAutoJoin joins the channels for this connection. On a fresh startup we join
@@ -4767,7 +4902,9 @@ func (c *IRCClient) doAutoJoin() {
 	}
 	logger.Log.Info().Int("count", len(channels)).Bool("reconnect", reconnect).Msg("Joining channels")
 	for _, channel := range channels {
-		c.rateLimiter.Wait()
+		if !c.outbound.wait(SendUser, lineSize(nil, "JOIN", channel.Name, channel.Key)) {
+			return
+		}
 		logger.Log.Info().Str("channel", channel.Name).Bool("reconnect", reconnect).Bool("with_key", channel.Key != "").Msg("Joining channel")
 		// Keyed (+k) channels must be rejoined with their stored key or the
 		// server answers 475 (ERR_BADCHANNELKEY) and the session stays broken.
@@ -4864,6 +5001,7 @@ func (c *IRCClient) signalQuit(reason string) <-chan struct{} {
 	if automaticRequests != nil {
 		automaticRequests.stopNow()
 	}
+	c.outbound.close()
 
 	if conn == nil {
 		return nil
@@ -4932,8 +5070,13 @@ func (c *IRCClient) SendTyping(target, state string) error {
 	}
 	c.mu.RUnlock()
 
-	c.rateLimiter.Wait()
-	if err := c.conn.SendWithTags(map[string]string{"+typing": state}, "TAGMSG", target); err != nil {
+	// A late typing notification is worse than none: skip it rather than queue
+	// it behind real messages when the budget is short.
+	tags := map[string]string{"+typing": state}
+	if !c.outbound.tryNow(lineSize(tags, "TAGMSG", target)) {
+		return nil
+	}
+	if err := c.conn.SendWithTags(tags, "TAGMSG", target); err != nil {
 		return fmt.Errorf("failed to send typing tag: %w", err)
 	}
 	return nil
@@ -4982,11 +5125,21 @@ func (c *IRCClient) handleTypingTag(e ircmsg.Message) {
 
 // SendMessage sends a message to a channel or user
 func (c *IRCClient) SendMessage(target, message string) error {
-	return c.sendMessage(target, message, "", "")
+	return c.sendMessage(SendUser, target, message, "", "")
 }
 
 // SendNotice sends one or more wire-sized NOTICE messages to target.
 func (c *IRCClient) SendNotice(target, message string) error {
+	return c.sendNotice(SendUser, target, message)
+}
+
+// SendAutomatedNotice is SendNotice for scripts and plugins: it waits behind
+// anything the user sends.
+func (c *IRCClient) SendAutomatedNotice(target, message string) error {
+	return c.sendNotice(SendAutomation, target, message)
+}
+
+func (c *IRCClient) sendNotice(class SendClass, target, message string) error {
 	c.mu.RLock()
 	connected := c.connected
 	c.mu.RUnlock()
@@ -4994,27 +5147,46 @@ func (c *IRCClient) SendNotice(target, message string) error {
 		return fmt.Errorf("not connected")
 	}
 	for _, chunk := range splitOutboundMessage(message, c.maxMessageChunk(target)) {
-		c.rateLimiter.Wait()
+		if !c.outbound.wait(class, lineSize(nil, "NOTICE", target, chunk)) {
+			return fmt.Errorf("not connected")
+		}
 		if err := c.conn.Send("NOTICE", target, chunk); err != nil {
 			return fmt.Errorf("failed to send notice: %w", err)
 		}
+		c.echoLocally(nil, "NOTICE", target, chunk)
 	}
 	return nil
 }
 
 // SendAction sends one or more CTCP ACTION messages to target.
 func (c *IRCClient) SendAction(target, message string) error {
+	return c.sendAction(SendUser, target, message)
+}
+
+// SendAutomatedAction is SendAction for scripts and plugins.
+func (c *IRCClient) SendAutomatedAction(target, message string) error {
+	return c.sendAction(SendAutomation, target, message)
+}
+
+func (c *IRCClient) sendAction(class SendClass, target, message string) error {
 	c.mu.RLock()
 	connected := c.connected
 	c.mu.RUnlock()
 	if !connected {
 		return fmt.Errorf("not connected")
 	}
-	for _, chunk := range splitOutboundMessage(message, c.maxMessageChunk(target)-len("\x01ACTION \x01")) {
-		c.rateLimiter.Wait()
-		if err := c.conn.Send("PRIVMSG", target, "\x01ACTION "+chunk+"\x01"); err != nil {
+	for _, chunk := range splitOutboundMessage(message, c.plaintextChunk(target)-len("\x01ACTION \x01")) {
+		wire, _, err := c.encryptOutgoing(target, "\x01ACTION "+chunk+"\x01")
+		if err != nil {
+			return fmt.Errorf("failed to encrypt action: %w", err)
+		}
+		if !c.outbound.wait(class, lineSize(nil, "PRIVMSG", target, wire)) {
+			return fmt.Errorf("not connected")
+		}
+		if err := c.conn.Send("PRIVMSG", target, wire); err != nil {
 			return fmt.Errorf("failed to send action: %w", err)
 		}
+		c.echoLocally(nil, "PRIVMSG", target, "\x01ACTION "+chunk+"\x01")
 	}
 	return nil
 }
@@ -5023,7 +5195,13 @@ func (c *IRCClient) SendAction(target, message string) error {
 // replyMsgID populates +draft/reply; channelContext populates
 // +draft/channel-context. Either may be empty.
 func (c *IRCClient) SendMessageWithTags(target, message, replyMsgID, channelContext string) error {
-	return c.sendMessage(target, message, replyMsgID, channelContext)
+	return c.sendMessage(SendUser, target, message, replyMsgID, channelContext)
+}
+
+// SendAutomatedMessage is SendMessage for scripts and plugins: every chunk
+// waits behind anything the user sends.
+func (c *IRCClient) SendAutomatedMessage(target, message string) error {
+	return c.sendMessage(SendAutomation, target, message, "", "")
 }
 
 // sendMessage is the shared core for SendMessage and SendMessageWithTags. A
@@ -5032,39 +5210,58 @@ func (c *IRCClient) SendMessageWithTags(target, message, replyMsgID, channelCont
 // library refuses to send an over-length line rather than truncating it. The
 // +draft/reply tag goes on the first chunk only (one reply, not N);
 // +draft/channel-context rides every chunk since it routes each one.
-func (c *IRCClient) sendMessage(target, message, replyMsgID, channelContext string) error {
+//
+// A user message of several chunks is handed to queuePaste and sent in the
+// background, so the call returns at once and what is typed next is not
+// stuck behind it.
+func (c *IRCClient) sendMessage(class SendClass, target, message, replyMsgID, channelContext string) error {
 	c.mu.RLock()
 	if !c.connected {
 		c.mu.RUnlock()
 		return fmt.Errorf("not connected")
 	}
 	c.mu.RUnlock()
+	if c.encryptionBroken(target) {
+		return errEncryptionBroken(target)
+	}
 
-	for i, chunk := range splitOutboundMessage(message, c.maxMessageChunk(target)) {
+	chunks := splitOutboundMessage(message, c.plaintextChunk(target))
+	if class == SendUser && len(chunks) > 1 {
+		c.queuePaste(target, chunks, replyMsgID, channelContext)
+		return nil
+	}
+	for i, chunk := range chunks {
 		chunkReply := ""
 		if i == 0 {
 			chunkReply = replyMsgID
 		}
-		if err := c.sendMessageChunk(target, chunk, chunkReply, channelContext); err != nil {
+		if err := c.sendMessageChunk(class, target, chunk, chunkReply, channelContext); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// sendMessageChunk sends one wire-sized PRIVMSG: rate-limit, send, store the
-// local copy (unless echo-message will hand us the canonical one), and emit
-// the message.sent event.
-func (c *IRCClient) sendMessageChunk(target, message, replyMsgID, channelContext string) error {
-	// Wait for rate limiter before sending
-	c.rateLimiter.Wait()
+// sendMessageChunk sends one wire-sized PRIVMSG: wait for its turn in class,
+// encrypt it when the conversation is encrypted, send, store the local copy
+// (unless echo-message will hand us the canonical one), and emit the
+// message.sent event.
+func (c *IRCClient) sendMessageChunk(class SendClass, target, message, replyMsgID, channelContext string) error {
+	wire, encrypted, err := c.encryptOutgoing(target, message)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt message: %w", err)
+	}
+	tags := buildSendTags(replyMsgID, channelContext)
+	if !c.outbound.wait(class, lineSize(tags, "PRIVMSG", target, wire)) {
+		return fmt.Errorf("not connected")
+	}
 
-	if tags := buildSendTags(replyMsgID, channelContext); tags != nil {
-		if err := c.conn.SendWithTags(tags, "PRIVMSG", target, message); err != nil {
+	if tags != nil {
+		if err := c.conn.SendWithTags(tags, "PRIVMSG", target, wire); err != nil {
 			return fmt.Errorf("failed to send message: %w", err)
 		}
 	} else {
-		if err := c.conn.Privmsg(target, message); err != nil {
+		if err := c.conn.Privmsg(target, wire); err != nil {
 			return fmt.Errorf("failed to send message: %w", err)
 		}
 	}
@@ -5108,17 +5305,20 @@ func (c *IRCClient) sendMessageChunk(target, message, replyMsgID, channelContext
 			NetworkID:      c.networkID,
 			ChannelID:      channelID,
 			User:           c.network.Nickname,
+			Account:        c.accountFor(c.network.Nickname),
 			Message:        message,
 			MessageType:    "privmsg",
 			Timestamp:      time.Now(),
-			RawLine:        fmt.Sprintf("PRIVMSG %s :%s", target, message),
+			RawLine:        fmt.Sprintf("PRIVMSG %s :%s", target, wire),
 			PMTarget:       pmTarget,
 			ReplyMsgID:     replyMsgID,
 			ChannelContext: channelContext,
+			Encrypted:      encrypted,
 		}
 		if err := c.storage.WriteMessageSync(msg); err != nil {
 			return fmt.Errorf("failed to store message: %w", err)
 		}
+		c.echoLocally(buildSendTags(replyMsgID, channelContext), "PRIVMSG", target, message)
 	}
 
 	// Emit event
@@ -5130,6 +5330,7 @@ func (c *IRCClient) sendMessageChunk(target, message, replyMsgID, channelContext
 			"target":    target,
 			"message":   message,
 			"pmTarget":  pmTarget,
+			"encrypted": encrypted,
 		},
 		Timestamp: time.Now(),
 		Source:    events.EventSourceIRC,
@@ -5195,6 +5396,10 @@ func (c *IRCClient) foldKey(s string) string {
 	return casefold(c.CaseMapping(), s)
 }
 
+// FoldKey is foldKey for callers outside the package that key stored state by
+// nick or channel (e.g. the App's channel list manager).
+func (c *IRCClient) FoldKey(s string) string { return c.foldKey(s) }
+
 // channelsToJoin returns the channels the auto-join goroutine should JOIN on this
 // connection.
 //
@@ -5381,9 +5586,11 @@ func (c *IRCClient) SendRawCommand(command string) error {
 	}
 	c.mu.RUnlock()
 
-	// Wait for rate limiter before sending
-	c.rateLimiter.Wait()
+	if !c.outbound.wait(SendUser, len(command)+2) {
+		return fmt.Errorf("not connected")
+	}
 
+	c.noteListRequest(command)
 	// Send raw command via the connection's SendRaw method
 	if err := c.conn.SendRaw(command); err != nil {
 		return fmt.Errorf("failed to send raw command: %w", err)
@@ -5702,53 +5909,17 @@ func (c *IRCClient) GetChanModeClasses() (a, b, cc, d string) {
 // RequestChannelBans asks the server for the channel ban list (MODE #channel +b).
 // Results arrive asynchronously via the EventChannelBanList event after RPL_ENDOFBANLIST.
 func (c *IRCClient) RequestChannelBans(channel string) error {
-	return c.SendRawCommand(fmt.Sprintf("MODE %s +b", channel))
-}
-
-// handleCTCPRequest handles incoming CTCP requests and sends appropriate responses
-func (c *IRCClient) handleCTCPRequest(from, command, args string) {
-	c.mu.RLock()
-	connected := c.connected
-	c.mu.RUnlock()
-
-	if !connected {
-		return
-	}
-
-	var response string
-	switch command {
-	case "VERSION":
-		response = "Cascade IRC Client v1.0.0"
-	case "TIME":
-		response = time.Now().Format(time.RFC1123Z)
-	case "PING":
-		// Echo back the ping argument or use current timestamp
-		if args != "" {
-			response = args
-		} else {
-			response = fmt.Sprintf("%d", time.Now().Unix())
-		}
-	case "CLIENTINFO":
-		response = "ACTION CLIENTINFO PING TIME VERSION"
-	default:
-		// Unknown CTCP command - don't respond
-		return
-	}
-
-	// Send CTCP response as NOTICE with \001 delimiters
-	ctcpResponse := fmt.Sprintf("\001%s %s\001", command, response)
-	c.conn.Notice(from, ctcpResponse)
-
-	// Log the CTCP request
-	logger.Log.Debug().
-		Str("from", from).
-		Str("command", command).
-		Str("response", response).
-		Msg("Handled CTCP request")
+	return c.RequestChannelListMode(channel, 'b')
 }
 
 // SendCTCPRequest sends a CTCP request to a target
 func (c *IRCClient) SendCTCPRequest(target, command, args string) error {
+	return c.SendCTCPRequestFrom(target, command, args, "")
+}
+
+// SendCTCPRequestFrom sends a CTCP request typed in buffer (a channel, a query
+// nick, or "" for the status buffer); replies are shown in that buffer.
+func (c *IRCClient) SendCTCPRequestFrom(target, command, args, buffer string) error {
 	c.mu.RLock()
 	if !c.connected {
 		c.mu.RUnlock()
@@ -5766,6 +5937,7 @@ func (c *IRCClient) SendCTCPRequest(target, command, args string) error {
 	if err := c.conn.Privmsg(target, ctcpMessage); err != nil {
 		return fmt.Errorf("failed to send CTCP request: %w", err)
 	}
+	c.rememberCTCPOrigin(target, command, buffer)
 
 	// Store CTCP request in status window
 	statusMsg := storage.Message{
@@ -5811,23 +5983,22 @@ func (c *IRCClient) handleNotice(e ircmsg.Message) {
 				ctcpResponse = strings.Join(parts[1:], " ")
 			}
 
-			// Store CTCP response in status window
+			// Show the reply where we asked for it (status by default)
 			rawLine, _ := e.Line()
-			statusMsg := storage.Message{
-				NetworkID:   c.networkID,
-				ChannelID:   nil, // Status window
-				User:        user,
-				Message:     fmt.Sprintf("CTCP %s reply from %s: %s", ctcpCommand, user, ctcpResponse),
-				MessageType: "ctcp",
-				Timestamp:   c.getMessageTime(e),
-				RawLine:     rawLine,
-			}
-			c.writeStatusBuffer(statusMsg)
+			c.writeCTCPReply(user, ctcpCommand, ctcpResponse, rawLine, c.getMessageTime(e))
 			return
 		}
 	}
 
-	// Regular NOTICE.
+	// The echo of a secret-bearing perform line must not reach history.
+	if c.isMe(user) && c.takeServicesEcho(target, notice) {
+		return
+	}
+
+	// Regular NOTICE. A perform step may be waiting for it.
+	c.notifyNoticeWaiters(user, notice)
+	c.recordSeenMessage(e, user, target, notice, SeenNotice)
+
 	// Channel-targeted notices (e.g. bot/announcement notices) belong in that
 	// channel's buffer, mirroring how channel PRIVMSGs are routed.
 	if len(target) > 0 && (target[0] == '#' || target[0] == '&') {
@@ -5842,6 +6013,7 @@ func (c *IRCClient) handleNotice(e ircmsg.Message) {
 			NetworkID:      c.networkID,
 			ChannelID:      channelID,
 			User:           user,
+			Account:        c.accountFor(user),
 			Message:        notice,
 			MessageType:    "notice",
 			Timestamp:      c.getMessageTime(e),
@@ -5894,6 +6066,7 @@ func (c *IRCClient) handleNotice(e ircmsg.Message) {
 			NetworkID:      c.networkID,
 			ChannelID:      nil, // PM rows and status rows both have a nil channel
 			User:           user,
+			Account:        c.accountFor(user),
 			Message:        notice,
 			MessageType:    "notice",
 			Timestamp:      c.getMessageTime(e),
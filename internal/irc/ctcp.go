@@ -0,0 +1,364 @@
+package irc
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/events"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// CTCP responder. Replies to VERSION, USERINFO, SOURCE and FINGER come from
+// user-editable templates; TIME, PING and CLIENTINFO are computed. Any command
+// can be disabled. Every reply spends a token from the sender's bucket and from
+// a shared bucket, so a burst of requests (one nick's or a botnet's) cannot get
+// us flood-kicked by the server; once either runs dry the rest are dropped and
+// a single status line says so.
+
+// CTCPCommands are the requests the responder knows how to answer, in the order
+// CLIENTINFO lists them.
+var CTCPCommands = []string{"ACTION", "CLIENTINFO", "DCC", "FINGER", "PING", "SOURCE", "TIME", "USERINFO", "VERSION"}
+
+// CTCPTemplateCommands are the commands whose reply text is a template.
+var CTCPTemplateCommands = []string{"VERSION", "USERINFO", "SOURCE", "FINGER"}
+
+// CTCPTemplateVariables are the placeholders a reply template may use.
+var CTCPTemplateVariables = []string{"$version", "$nick", "$realname", "$network", "$os", "$arch"}
+
+const (
+	defaultCTCPSenderRepliesPerMinute = 4
+	defaultCTCPGlobalRepliesPerMinute = 20
+	// ctcpFloodNoticeInterval spaces out "flood suppressed" status lines so the
+	// notice itself cannot become the flood.
+	ctcpFloodNoticeInterval = time.Minute
+	// ctcpReplyWindow is how long a reply is matched to the buffer its
+	// /ctcp request was typed in before falling back to the status buffer.
+	ctcpReplyWindow      = 2 * time.Minute
+	maxCTCPSenderBuckets = 256
+)
+
+// CTCPSettings configures the CTCP responder.
+type CTCPSettings struct {
+	Disabled               []string          `json:"disabled"`               // commands never answered, e.g. "FINGER"
+	Replies                map[string]string `json:"replies"`                // reply templates keyed by CTCPTemplateCommands
+	SenderRepliesPerMinute int               `json:"senderRepliesPerMinute"` // per-nick reply budget; 0 means the default
+	GlobalRepliesPerMinute int               `json:"globalRepliesPerMinute"` // budget across all nicks; 0 means the default
+	Version                string            `json:"-"`                      // client version for $version, set by the App
+}
+
+// DefaultCTCPSettings returns the stock responder: everything enabled, with
+// replies that name the client and the user's realname.
+func DefaultCTCPSettings() CTCPSettings {
+	return CTCPSettings{
+		Disabled: []string{},
+		Replies: map[string]string{
+			"VERSION":  "Cascade IRC Client $version ($os/$arch)",
+			"USERINFO": "$realname",
+			"SOURCE":   "https://github.com/matt0x6f/irc-client",
+			"FINGER":   "$nick ($realname)",
+		},
+	}
+}
+
+// ValidateCTCPSettings rejects unknown commands and negative rates.
+func ValidateCTCPSettings(s CTCPSettings) error {
+	for _, command := range s.Disabled {
+		if !slices.Contains(CTCPCommands, strings.ToUpper(strings.TrimSpace(command))) {
+			return fmt.Errorf("unknown CTCP command %q", command)
+		}
+	}
+	for command := range s.Replies {
+		if !slices.Contains(CTCPTemplateCommands, strings.ToUpper(command)) {
+			return fmt.Errorf("CTCP %s has no configurable reply", command)
+		}
+	}
+	if s.SenderRepliesPerMinute < 0 || s.GlobalRepliesPerMinute < 0 {
+		return fmt.Errorf("CTCP reply rates must not be negative")
+	}
+	return nil
+}
+
+func (s CTCPSettings) disabled(command string) bool {
+	return slices.ContainsFunc(s.Disabled, func(d string) bool { return strings.EqualFold(strings.TrimSpace(d), command) })
+}
+
+func (s CTCPSettings) rates() (sender, global int) {
+	sender, global = s.SenderRepliesPerMinute, s.GlobalRepliesPerMinute
+	if sender <= 0 {
+		sender = defaultCTCPSenderRepliesPerMinute
+	}
+	if global <= 0 {
+		global = defaultCTCPGlobalRepliesPerMinute
+	}
+	return sender, global
+}
+
+// ctcpResponder is the client's responder state: settings, buckets and the
+// origin buffers of our own outstanding requests.
+type ctcpResponder struct {
+	mu         sync.Mutex
+	settings   CTCPSettings
+	configured bool
+	global     *RateLimiter
+	senders    map[string]*RateLimiter
+	lastNotice map[string]time.Time
+	origins    map[string]ctcpOrigin
+}
+
+type ctcpOrigin struct {
+	buffer string
+	sentAt time.Time
+}
+
+// SetCTCPSettings replaces the responder configuration. Buckets restart full.
+func (c *IRCClient) SetCTCPSettings(settings CTCPSettings) {
+	r := &c.ctcp
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settings, r.configured = settings, true
+	r.global, r.senders = nil, nil
+}
+
+// settingsLocked returns the configuration, defaulting when the App never
+// set one. Callers hold r.mu.
+func (r *ctcpResponder) settingsLocked() CTCPSettings {
+	if !r.configured {
+		r.settings, r.configured = DefaultCTCPSettings(), true
+	}
+	return r.settings
+}
+
+// allowCTCPReply spends a token from the sender's and the global bucket. The
+// second return is the status line to write when a flood has just been
+// suppressed, or "" when the reply is allowed or the flood was already reported.
+func (c *IRCClient) allowCTCPReply(from string) (bool, string) {
+	r := &c.ctcp
+	key := c.foldKey(from)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	senderRate, globalRate := r.settingsLocked().rates()
+	if r.global == nil {
+		r.global = NewRateLimiter(globalRate, time.Minute)
+	}
+	if r.senders == nil {
+		r.senders = make(map[string]*RateLimiter)
+	}
+	bucket := r.senders[key]
+	if bucket == nil {
+		if len(r.senders) >= maxCTCPSenderBuckets {
+			r.senders = make(map[string]*RateLimiter)
+		}
+		bucket = NewRateLimiter(senderRate, time.Minute)
+		r.senders[key] = bucket
+	}
+
+	noticeKey, line := key, fmt.Sprintf("CTCP flood from %s detected; ignoring their requests for now.", from)
+	if bucket.TryAcquire() {
+		if r.global.TryAcquire() {
+			return true, ""
+		}
+		noticeKey, line = "", "CTCP flood detected; ignoring CTCP requests for now."
+	}
+	if r.lastNotice == nil {
+		r.lastNotice = make(map[string]time.Time)
+	}
+	if last, ok := r.lastNotice[noticeKey]; ok && time.Since(last) < ctcpFloodNoticeInterval {
+		return false, ""
+	}
+	r.lastNotice[noticeKey] = time.Now()
+	return false, line
+}
+
+// handleCTCPRequest answers an incoming CTCP request per the responder settings.
+func (c *IRCClient) handleCTCPRequest(from, command, args string) {
+	c.mu.RLock()
+	connected := c.connected
+	c.mu.RUnlock()
+
+	if !connected {
+		return
+	}
+
+	response, ok := c.ctcpResponse(command, args)
+	if !ok {
+		return
+	}
+	if allowed, floodLine := c.allowCTCPReply(from); !allowed {
+		if floodLine != "" {
+			c.writeStatusLine("status", floodLine)
+		}
+		logger.Log.Debug().Str("from", from).Str("command", command).Msg("Suppressed CTCP reply (rate limited)")
+		return
+	}
+
+	// Send CTCP response as NOTICE with \001 delimiters
+	ctcpResponse := fmt.Sprintf("\001%s %s\001", command, response)
+	c.conn.Notice(from, ctcpResponse)
+
+	// Log the CTCP request
+	logger.Log.Debug().
+		Str("from", from).
+		Str("command", command).
+		Str("response", response).
+		Msg("Handled CTCP request")
+}
+
+// ctcpResponse builds the reply text for command, or ok=false when the command
+// is unknown, disabled, or its template renders empty.
+func (c *IRCClient) ctcpResponse(command, args string) (string, bool) {
+	c.ctcp.mu.Lock()
+	settings := c.ctcp.settingsLocked()
+	c.ctcp.mu.Unlock()
+	if settings.disabled(command) {
+		return "", false
+	}
+
+	switch command {
+	case "TIME":
+		return time.Now().Format(time.RFC1123Z), true
+	case "PING":
+		// Echo back the ping argument or use current timestamp
+		if args != "" {
+			return args, true
+		}
+		return fmt.Sprintf("%d", time.Now().Unix()), true
+	case "CLIENTINFO":
+		enabled := make([]string, 0, len(CTCPCommands))
+		for _, name := range CTCPCommands {
+			if !settings.disabled(name) {
+				enabled = append(enabled, name)
+			}
+		}
+		return strings.Join(enabled, " "), true
+	case "VERSION", "USERINFO", "SOURCE", "FINGER":
+		reply := strings.TrimSpace(c.expandCTCPTemplate(settings.Replies[command], settings.Version))
+		return reply, reply != ""
+	default:
+		// Unknown CTCP command - don't respond
+		return "", false
+	}
+}
+
+func (c *IRCClient) expandCTCPTemplate(template, version string) string {
+	if version == "" {
+		version = "dev"
+	}
+	return strings.NewReplacer(
+		"$version", version,
+		"$nick", c.CurrentNick(),
+		"$realname", c.network.Realname,
+		"$network", c.network.Name,
+		"$os", runtime.GOOS,
+		"$arch", runtime.GOARCH,
+	).Replace(template)
+}
+
+func ctcpOriginKey(target, command string) string {
+	return target + "\x00" + command
+}
+
+// rememberCTCPOrigin notes the buffer a /ctcp request was typed in so the
+// reply can be shown there. buffer is a channel, a query nick, or "" / "status".
+func (c *IRCClient) rememberCTCPOrigin(target, command, buffer string) {
+	r := &c.ctcp
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for key, origin := range r.origins {
+		if now.Sub(origin.sentAt) > ctcpReplyWindow {
+			delete(r.origins, key)
+		}
+	}
+	if buffer == "" || buffer == "status" {
+		return
+	}
+	if r.origins == nil {
+		r.origins = make(map[string]ctcpOrigin)
+	}
+	r.origins[ctcpOriginKey(c.foldKey(target), command)] = ctcpOrigin{buffer: buffer, sentAt: now}
+}
+
+// ctcpReplyBuffer returns the buffer a CTCP reply from sender belongs in, or ""
+// for the status buffer. A request sent to a channel is answered by each
+// member, so channel-targeted origins stay until they age out.
+func (c *IRCClient) ctcpReplyBuffer(sender, command string) string {
+	r := &c.ctcp
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := ctcpOriginKey(c.foldKey(sender), command)
+	if origin, ok := r.origins[key]; ok && time.Since(origin.sentAt) <= ctcpReplyWindow {
+		delete(r.origins, key)
+		return origin.buffer
+	}
+	var (
+		best  ctcpOrigin
+		found bool
+	)
+	for key, origin := range r.origins {
+		target, cmd, _ := strings.Cut(key, "\x00")
+		if cmd != command || !c.isChannelName(target) || time.Since(origin.sentAt) > ctcpReplyWindow {
+			continue
+		}
+		if !found || origin.sentAt.After(best.sentAt) {
+			best, found = origin, true
+		}
+	}
+	return best.buffer
+}
+
+// writeCTCPReply records a CTCP reply in the buffer its request came from,
+// falling back to the status buffer.
+func (c *IRCClient) writeCTCPReply(sender, command, response, rawLine string, ts time.Time) {
+	text := fmt.Sprintf("CTCP %s reply from %s: %s", command, sender, response)
+	buffer := c.ctcpReplyBuffer(sender, command)
+	msg := storage.Message{
+		NetworkID:   c.networkID,
+		User:        sender,
+		Account:     c.accountFor(sender),
+		Message:     text,
+		MessageType: "ctcp",
+		Timestamp:   ts,
+		RawLine:     rawLine,
+	}
+	if buffer == "" {
+		c.writeStatusBuffer(msg)
+		return
+	}
+	channel, user := buffer, sender
+	if c.isChannelName(buffer) {
+		ch, err := c.storage.GetChannelByName(c.networkID, buffer)
+		if err != nil {
+			c.writeStatusBuffer(msg)
+			return
+		}
+		msg.ChannelID = &ch.ID
+	} else {
+		// PM events name the peer as the user, so the query pane refreshes.
+		msg.PMTarget = buffer
+		channel, user = c.CurrentNick(), buffer
+	}
+	if err := c.storage.WriteMessageSync(msg); err != nil {
+		logger.Log.Warn().Err(err).Str("buffer", buffer).Msg("Failed to write CTCP reply")
+	}
+	// Reported as a notice (which it is on the wire) so it badges the pane
+	// without raising a desktop notification.
+	c.eventBus.Emit(events.Event{
+		Type: EventMessageReceived,
+		Data: map[string]interface{}{
+			"network":     c.network.Address,
+			"networkId":   c.networkID,
+			"networkName": c.network.Name,
+			"channel":     channel,
+			"user":        user,
+			"message":     text,
+			"messageType": "notice",
+		},
+		Timestamp: time.Now(),
+		Source:    events.EventSourceIRC,
+	})
+}
@@ -0,0 +1,111 @@
+package irc
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCTCPSenderFloodSuppressed(t *testing.T) {
+	c, _ := newUserMetaTestClient(t)
+	c.SetCTCPSettings(CTCPSettings{SenderRepliesPerMinute: 2, GlobalRepliesPerMinute: 10})
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := c.allowCTCPReply("Spammer"); !ok {
+			t.Fatalf("request %d suppressed, want it answered", i+1)
+		}
+	}
+	ok, line := c.allowCTCPReply("spammer")
+	if ok || !strings.Contains(line, "spammer") {
+		t.Fatalf("third request = (%v, %q), want suppressed with a flood line", ok, line)
+	}
+	if ok, line := c.allowCTCPReply("SPAMMER"); ok || line != "" {
+		t.Errorf("fourth request = (%v, %q), want suppressed silently", ok, line)
+	}
+	if ok, _ := c.allowCTCPReply("friend"); !ok {
+		t.Error("another sender was suppressed by spammer's bucket")
+	}
+}
+
+func TestCTCPGlobalFloodSuppressed(t *testing.T) {
+	c, _ := newUserMetaTestClient(t)
+	c.SetCTCPSettings(CTCPSettings{SenderRepliesPerMinute: 5, GlobalRepliesPerMinute: 3})
+
+	for _, nick := range []string{"a", "b", "c"} {
+		if ok, _ := c.allowCTCPReply(nick); !ok {
+			t.Fatalf("%s suppressed under the global budget", nick)
+		}
+	}
+	ok, line := c.allowCTCPReply("d")
+	if ok || !strings.HasPrefix(line, "CTCP flood detected") {
+		t.Fatalf("d = (%v, %q), want suppressed with the global flood line", ok, line)
+	}
+	if ok, line := c.allowCTCPReply("e"); ok || line != "" {
+		t.Errorf("e = (%v, %q), want suppressed without a second line", ok, line)
+	}
+}
+
+func TestCTCPResponseTemplatesAndDisabled(t *testing.T) {
+	c, _ := newUserMetaTestClient(t)
+	c.network.Realname = "Matt"
+	c.SetCTCPSettings(CTCPSettings{
+		Disabled: []string{"finger", "TIME"},
+		Replies:  map[string]string{"VERSION": "cascade $version on $os/$arch for $nick@$network", "USERINFO": "  "},
+		Version:  "1.2.3",
+	})
+
+	if got, ok := c.ctcpResponse("VERSION", ""); !ok || got != "cascade 1.2.3 on "+runtime.GOOS+"/"+runtime.GOARCH+" for matt0x6f@Libera" {
+		t.Errorf("VERSION = (%q, %v)", got, ok)
+	}
+	for _, command := range []string{"FINGER", "TIME", "USERINFO", "SOURCE", "BOGUS"} {
+		if got, ok := c.ctcpResponse(command, ""); ok {
+			t.Errorf("%s answered %q, want no reply", command, got)
+		}
+	}
+	if got, ok := c.ctcpResponse("PING", "12345"); !ok || got != "12345" {
+		t.Errorf("PING = (%q, %v), want the argument echoed", got, ok)
+	}
+	got, _ := c.ctcpResponse("CLIENTINFO", "")
+	if strings.Contains(got, "FINGER") || strings.Contains(got, "TIME") || !strings.Contains(got, "VERSION") {
+		t.Errorf("CLIENTINFO = %q, want only enabled commands", got)
+	}
+}
+
+func TestValidateCTCPSettings(t *testing.T) {
+	if err := ValidateCTCPSettings(DefaultCTCPSettings()); err != nil {
+		t.Fatalf("defaults rejected: %v", err)
+	}
+	for name, s := range map[string]CTCPSettings{
+		"unknown disabled": {Disabled: []string{"XYZZY"}},
+		"computed reply":   {Replies: map[string]string{"TIME": "noon"}},
+		"negative rate":    {SenderRepliesPerMinute: -1},
+	} {
+		if err := ValidateCTCPSettings(s); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestCTCPReplyBufferFollowsRequest(t *testing.T) {
+	c, _ := newUserMetaTestClient(t)
+
+	c.rememberCTCPOrigin("Alice", "VERSION", "#go")
+	c.rememberCTCPOrigin("#help", "PING", "#help")
+	c.rememberCTCPOrigin("bob", "TIME", "status")
+
+	if got := c.ctcpReplyBuffer("alice", "VERSION"); got != "#go" {
+		t.Errorf("alice VERSION reply buffer = %q, want #go", got)
+	}
+	if got := c.ctcpReplyBuffer("alice", "VERSION"); got != "" {
+		t.Errorf("second alice VERSION reply buffer = %q, want status", got)
+	}
+	// Every member of #help answers a channel-wide request.
+	for _, nick := range []string{"carol", "dave"} {
+		if got := c.ctcpReplyBuffer(nick, "PING"); got != "#help" {
+			t.Errorf("%s PING reply buffer = %q, want #help", nick, got)
+		}
+	}
+	if got := c.ctcpReplyBuffer("bob", "TIME"); got != "" {
+		t.Errorf("bob TIME reply buffer = %q, want status", got)
+	}
+}
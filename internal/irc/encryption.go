@@ -0,0 +1,611 @@
+package irc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/e2e"
+	"github.com/matt0x6f/irc-client/internal/events"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// Message encryption. A private conversation can run an end-to-end session
+// (package e2e) negotiated over CTCP E2E; channels and queries that already
+// share a FiSH key use that instead. The app hands the client the network's
+// identity key, the fingerprints the user has verified and the FiSH keys, all
+// kept in the credential store. Sessions live in memory: a peer that lost
+// theirs answers our next message with a plain E2E END. A plain END can be
+// forged, so it never ends a session; it only marks the conversation broken,
+// and nothing is sent to the peer until the user re-keys with /e2e start or
+// goes back to plain text with /e2e stop. Only an END sealed inside the
+// session ends it. A peer's INIT is only an offer until the user accepts it
+// with StartEncryption; INITs that cross are settled by
+// e2e.Handshake.Outranks. The ACCEPT goes out before the session is
+// installed, so nothing sealed can overtake it.
+//
+// Encryption happens in sendMessageChunk/sendAction and decryption at the top
+// of handlePrivmsg, so everything after them (storage, events, highlights)
+// sees plain text, with stored rows marked encrypted.
+
+// Conversation encryption modes, as EncryptionState.Mode.
+const (
+	EncryptionNone    = ""
+	EncryptionPending = "pending" // we sent E2E INIT and wait for ACCEPT
+	EncryptionOffered = "offered" // the peer sent E2E INIT and waits for the user to accept
+	EncryptionE2E     = "e2e"
+	EncryptionBroken  = "broken" // the E2E session can't be used; sending is refused until the user re-keys or stops it
+	EncryptionFish    = "fish"
+)
+
+// EncryptionState describes how messages to a target are protected.
+type EncryptionState struct {
+	Mode             string `json:"mode"`
+	Fingerprint      string `json:"fingerprint"`      // our identity fingerprint on this network ("" without one)
+	PeerFingerprint  string `json:"peerFingerprint"`  // the peer's, while an E2E session is up or broken
+	Verified         bool   `json:"verified"`         // PeerFingerprint is the one the user verified for this nick
+	OfferFingerprint string `json:"offerFingerprint"` // the peer's, while their offer of a new session waits for the user
+}
+
+// SetEncryptionKeys installs the network's identity key, the fingerprints the
+// user verified (nick -> fingerprint) and the FiSH keys (target -> key).
+func (c *IRCClient) SetEncryptionKeys(identity e2e.Identity, trusted, fishKeys map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.e2eIdentity = identity
+	c.e2eTrusted = make(map[string]string, len(trusted))
+	for nick, fp := range trusted {
+		c.e2eTrusted[c.foldKey(nick)] = fp
+	}
+	c.fishKeys = make(map[string]string, len(fishKeys))
+	for target, key := range fishKeys {
+		c.fishKeys[c.foldKey(target)] = key
+	}
+}
+
+// SetFishKey sets the FiSH key for a channel or nick; "" removes it.
+func (c *IRCClient) SetFishKey(target, key string) {
+	c.mu.Lock()
+	if c.fishKeys == nil {
+		c.fishKeys = make(map[string]string)
+	}
+	if key == "" {
+		delete(c.fishKeys, c.foldKey(target))
+	} else {
+		c.fishKeys[c.foldKey(target)] = key
+	}
+	c.mu.Unlock()
+	c.emitEncryptionChanged(target)
+}
+
+// SetTrustedFingerprint records the fingerprint the user verified for nick;
+// "" forgets it.
+func (c *IRCClient) SetTrustedFingerprint(nick, fingerprint string) {
+	c.mu.Lock()
+	if c.e2eTrusted == nil {
+		c.e2eTrusted = make(map[string]string)
+	}
+	if fingerprint == "" {
+		delete(c.e2eTrusted, c.foldKey(nick))
+	} else {
+		c.e2eTrusted[c.foldKey(nick)] = fingerprint
+	}
+	c.mu.Unlock()
+	c.emitEncryptionChanged(nick)
+}
+
+// EncryptionState reports how messages to target are protected. An E2E
+// session wins over a FiSH key for the same nick.
+func (c *IRCClient) EncryptionState(target string) EncryptionState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var st EncryptionState
+	if c.e2eIdentity.Valid() {
+		st.Fingerprint = c.e2eIdentity.Fingerprint()
+	}
+	key := c.foldKey(target)
+	if offer := c.e2eOffers[key]; offer != "" {
+		st.OfferFingerprint, _ = e2e.OfferFingerprint(offer)
+	}
+	switch {
+	case c.e2eSessions[key] != nil:
+		st.Mode = EncryptionE2E
+		if c.e2eBroken[key] {
+			st.Mode = EncryptionBroken
+		}
+		st.PeerFingerprint = c.e2eSessions[key].PeerFingerprint()
+		st.Verified = c.e2eTrusted[key] == st.PeerFingerprint
+	case c.e2eHandshakes[key] != nil:
+		st.Mode = EncryptionPending
+	case st.OfferFingerprint != "":
+		st.Mode = EncryptionOffered
+	case c.fishKeys[key] != "":
+		st.Mode = EncryptionFish
+	}
+	return st
+}
+
+// StartEncryption offers nick an end-to-end session, or accepts the one they
+// offered. The conversation is encrypted once the handshake completes; until
+// then messages go out as before.
+func (c *IRCClient) StartEncryption(nick string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+	if c.isChannelName(nick) {
+		return fmt.Errorf("end-to-end encryption is for private conversations; channels can use a FiSH key")
+	}
+	c.mu.Lock()
+	identity := c.e2eIdentity
+	offer := c.e2eOffers[c.foldKey(nick)]
+	c.mu.Unlock()
+	if !identity.Valid() {
+		return fmt.Errorf("no identity key is available for this network")
+	}
+	if offer != "" {
+		return c.acceptEncryptionOffer(nick, offer)
+	}
+	handshake, args, err := e2e.StartHandshake(identity)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	if c.e2eHandshakes == nil {
+		c.e2eHandshakes = make(map[string]*e2e.Handshake)
+	}
+	c.e2eHandshakes[c.foldKey(nick)] = handshake
+	c.mu.Unlock()
+	if err := c.sendE2ECTCP(SendUser, nick, args); err != nil {
+		c.dropEncryptionSession(nick)
+		return err
+	}
+	c.writeStatusLine("status", fmt.Sprintf("Asked %s for an encrypted conversation.", nick))
+	c.emitEncryptionChanged(nick)
+	return nil
+}
+
+// acceptEncryptionOffer answers nick's INIT, replacing any session we had with
+// them. The session is installed only once the ACCEPT is on the wire, so a
+// message sealed with it can't reach them before they can open it.
+func (c *IRCClient) acceptEncryptionOffer(nick, offer string) error {
+	key := c.foldKey(nick)
+	c.mu.Lock()
+	identity := c.e2eIdentity
+	delete(c.e2eOffers, key)
+	delete(c.e2eHandshakes, key)
+	c.mu.Unlock()
+	session, reply, err := e2e.Accept(identity, offer)
+	if err == nil {
+		err = c.sendE2ECTCP(SendProtocol, nick, reply)
+	}
+	if err != nil {
+		c.emitEncryptionChanged(nick)
+		return fmt.Errorf("couldn't accept the encrypted conversation with %s: %w", nick, err)
+	}
+	c.installE2ESession(nick, key, session)
+	return nil
+}
+
+// StopEncryption ends the session, or the offer either side has pending, with
+// nick and tells them. The END is sealed in the session when there is one, so
+// the peer can tell it came from us.
+func (c *IRCClient) StopEncryption(nick string) error {
+	c.mu.RLock()
+	session := c.e2eSessions[c.foldKey(nick)]
+	c.mu.RUnlock()
+	if !c.dropEncryptionSession(nick) {
+		return fmt.Errorf("no encrypted conversation with %s", nick)
+	}
+	if c.IsConnected() {
+		if err := c.sendE2EEnd(nick, session); err != nil {
+			return err
+		}
+	}
+	c.writeStatusLine("status", fmt.Sprintf("Ended the encrypted conversation with %s.", nick))
+	return nil
+}
+
+// dropEncryptionSession forgets the session and any pending offer, ours or
+// theirs, for nick, reporting whether there was one.
+func (c *IRCClient) dropEncryptionSession(nick string) bool {
+	key := c.foldKey(nick)
+	c.mu.Lock()
+	had := c.e2eSessions[key] != nil || c.e2eHandshakes[key] != nil || c.e2eOffers[key] != ""
+	delete(c.e2eSessions, key)
+	delete(c.e2eHandshakes, key)
+	delete(c.e2eOffers, key)
+	delete(c.e2eBroken, key)
+	c.mu.Unlock()
+	if had {
+		c.emitEncryptionChanged(nick)
+	}
+	return had
+}
+
+// renameEncryptionPeer carries a session and the pending offers over a peer's
+// nick change. The verified fingerprint stays with the nick the user verified
+// it for, as it does in the credential store.
+func (c *IRCClient) renameEncryptionPeer(oldNick, newNick string) {
+	oldKey, newKey := c.foldKey(oldNick), c.foldKey(newNick)
+	if oldKey == newKey {
+		return
+	}
+	c.mu.Lock()
+	moved := renameKey(c.e2eSessions, oldKey, newKey)
+	moved = renameKey(c.e2eHandshakes, oldKey, newKey) || moved
+	moved = renameKey(c.e2eOffers, oldKey, newKey) || moved
+	moved = renameKey(c.e2eBroken, oldKey, newKey) || moved
+	c.mu.Unlock()
+	if moved {
+		c.emitEncryptionChanged(newNick)
+	}
+}
+
+// renameKey moves m[from] to m[to], reporting whether there was one.
+func renameKey[V any](m map[string]V, from, to string) bool {
+	v, ok := m[from]
+	if ok {
+		delete(m, from)
+		m[to] = v
+	}
+	return ok
+}
+
+// sendE2ECTCP sends a handshake line through the outbound queue. ACCEPT and
+// END go as protocol replies, ahead of anything the user types.
+func (c *IRCClient) sendE2ECTCP(class SendClass, nick, args string) error {
+	text := "\x01" + e2e.CTCPCommand + " " + args + "\x01"
+	if !c.outbound.wait(class, lineSize(nil, "PRIVMSG", nick, text)) {
+		return fmt.Errorf("not connected")
+	}
+	if err := c.conn.Privmsg(nick, text); err != nil {
+		return fmt.Errorf("failed to send E2E %s: %w", e2e.HandshakeVerb(args), err)
+	}
+	return nil
+}
+
+// sendE2EEnd tells nick the conversation is over: sealed in session when
+// there is one, so that only we can end it, and in the clear otherwise.
+func (c *IRCClient) sendE2EEnd(nick string, session *e2e.Session) error {
+	if session == nil {
+		return c.sendE2ECTCP(SendProtocol, nick, e2e.VerbEnd)
+	}
+	wire, err := session.Seal("\x01" + e2e.CTCPCommand + " " + e2e.VerbEnd + "\x01")
+	if err != nil {
+		return fmt.Errorf("failed to seal E2E END: %w", err)
+	}
+	if !c.outbound.wait(SendProtocol, lineSize(nil, "PRIVMSG", nick, wire)) {
+		return fmt.Errorf("not connected")
+	}
+	if err := c.conn.Privmsg(nick, wire); err != nil {
+		return fmt.Errorf("failed to send E2E END: %w", err)
+	}
+	return nil
+}
+
+// handleSealedE2ECTCP processes a CTCP E2E message that arrived inside the
+// session with from. Only END means anything there: it is the peer ending
+// the conversation, and sealing proves it is them.
+func (c *IRCClient) handleSealedE2ECTCP(from, args string) {
+	if e2e.HandshakeVerb(args) != e2e.VerbEnd {
+		return
+	}
+	if c.dropEncryptionSession(from) {
+		c.warnConversation(from, fmt.Sprintf("%s ended the encrypted conversation; messages to them are no longer encrypted.", from))
+	}
+}
+
+// breakEncryptionSession marks the session with nick unusable, so nothing
+// more is sent to them until the user re-keys or stops it, and reports
+// whether there was a session to break.
+func (c *IRCClient) breakEncryptionSession(nick string) bool {
+	key := c.foldKey(nick)
+	c.mu.Lock()
+	had := c.e2eSessions[key] != nil && !c.e2eBroken[key]
+	if had {
+		if c.e2eBroken == nil {
+			c.e2eBroken = make(map[string]bool)
+		}
+		c.e2eBroken[key] = true
+	}
+	c.mu.Unlock()
+	if had {
+		c.emitEncryptionChanged(nick)
+	}
+	return had
+}
+
+// handleE2ECTCP processes a CTCP E2E handshake message sent privately by from
+// in the clear.
+func (c *IRCClient) handleE2ECTCP(from, args string) {
+	key := c.foldKey(from)
+	switch e2e.HandshakeVerb(args) {
+	case e2e.VerbInit:
+		c.mu.RLock()
+		identity := c.e2eIdentity
+		c.mu.RUnlock()
+		if !identity.Valid() {
+			c.writeStatusLine("error", fmt.Sprintf("%s asked for an encrypted conversation, but no identity key is available for this network.", from))
+			return
+		}
+		fp, err := e2e.OfferFingerprint(args)
+		if err != nil {
+			logger.Log.Debug().Err(err).Str("from", from).Msg("Ignored malformed E2E INIT")
+			return
+		}
+		c.mu.Lock()
+		handshake := c.e2eHandshakes[key]
+		if handshake != nil && handshake.Outranks(args) {
+			// Our INITs crossed and ours wins; they accept it instead.
+			c.mu.Unlock()
+			return
+		}
+		if handshake == nil {
+			if c.e2eOffers == nil {
+				c.e2eOffers = make(map[string]string)
+			}
+			c.e2eOffers[key] = args
+		}
+		inSession := c.e2eSessions[key] != nil
+		trusted := c.e2eTrusted[key]
+		c.mu.Unlock()
+
+		if handshake != nil {
+			// The user already asked for a session, so theirs is taken as agreed.
+			if err := c.acceptEncryptionOffer(from, args); err != nil {
+				c.writeStatusLine("error", err.Error())
+			}
+			return
+		}
+		var about string
+		switch trusted {
+		case fp:
+			about = "their key is the one you verified"
+		case "":
+			about = "their fingerprint is " + fp
+		default:
+			about = "their key has CHANGED since you verified it; the new fingerprint is " + fp
+		}
+		if inSession {
+			c.writeStatusLine("status", fmt.Sprintf("%s asks to start a new encrypted conversation (%s). The current one carries on until you accept with /e2e start %s.", from, about, from))
+		} else {
+			c.writeStatusLine("status", fmt.Sprintf("%s offers an encrypted conversation (%s). Run /e2e start %s to accept.", from, about, from))
+		}
+		c.emitEncryptionChanged(from)
+
+	case e2e.VerbAccept:
+		c.mu.Lock()
+		handshake := c.e2eHandshakes[key]
+		delete(c.e2eHandshakes, key)
+		c.mu.Unlock()
+		if handshake == nil {
+			return
+		}
+		session, err := handshake.Finish(args)
+		if err != nil {
+			c.writeStatusLine("error", fmt.Sprintf("Couldn't start an encrypted conversation with %s: %v", from, err))
+			c.emitEncryptionChanged(from)
+			return
+		}
+		c.installE2ESession(from, key, session)
+
+	case e2e.VerbEnd:
+		// Anyone can send a plain END, so it can't take a session down: a peer
+		// that really lost theirs has to start a new one.
+		if c.breakEncryptionSession(from) {
+			c.warnConversation(from, fmt.Sprintf("%s says they can no longer read this encrypted conversation. Nothing more is sent to them until you run /e2e start %s to start a new one, or /e2e stop %s to go back to plain text.", from, from, from))
+			return
+		}
+		c.mu.Lock()
+		key := c.foldKey(from)
+		ended := c.e2eSessions[key] == nil && (c.e2eHandshakes[key] != nil || c.e2eOffers[key] != "")
+		if ended {
+			delete(c.e2eHandshakes, key)
+			delete(c.e2eOffers, key)
+		}
+		c.mu.Unlock()
+		if ended {
+			c.writeStatusLine("status", fmt.Sprintf("%s declined the encrypted conversation.", from))
+			c.emitEncryptionChanged(from)
+		}
+	}
+}
+
+// installE2ESession records a new session and tells the user whether the
+// peer's fingerprint is the one they verified.
+func (c *IRCClient) installE2ESession(nick, key string, session *e2e.Session) {
+	fp := session.PeerFingerprint()
+	c.mu.Lock()
+	if c.e2eSessions == nil {
+		c.e2eSessions = make(map[string]*e2e.Session)
+	}
+	c.e2eSessions[key] = session
+	delete(c.e2eBroken, key)
+	trusted := c.e2eTrusted[key]
+	c.mu.Unlock()
+
+	switch trusted {
+	case fp:
+		c.writeStatusLine("status", fmt.Sprintf("Encrypted conversation with %s started; their key is the one you verified.", nick))
+	case "":
+		c.writeStatusLine("status", fmt.Sprintf("Encrypted conversation with %s started. Their fingerprint is %s; compare it with them and run /e2e verify %s if it matches.", nick, fp, nick))
+	default:
+		c.writeStatusLine("error", fmt.Sprintf("Encrypted conversation with %s started, but their key has CHANGED since you verified it. New fingerprint: %s. Check it with them before trusting this conversation.", nick, fp))
+	}
+	c.emitEncryptionChanged(nick)
+}
+
+// encryptOutgoing returns the wire form of text for target and whether it was
+// encrypted. A CTCP ACTION is sealed whole in an E2E session and has only its
+// text encrypted under FiSH, as FiSH clients expect. A broken session refuses
+// rather than letting the text go out in the clear.
+func (c *IRCClient) encryptOutgoing(target, text string) (string, bool, error) {
+	key := c.foldKey(target)
+	c.mu.RLock()
+	session, fishKey, broken := c.e2eSessions[key], c.fishKeys[key], c.e2eBroken[key]
+	c.mu.RUnlock()
+	switch {
+	case broken:
+		return "", false, errEncryptionBroken(target)
+	case session != nil:
+		wire, err := session.Seal(text)
+		return wire, err == nil, err
+	case fishKey != "":
+		if action, ok := ctcpActionText(text); ok {
+			wire, err := e2e.FishEncrypt(fishKey, action)
+			return "\x01ACTION " + wire + "\x01", err == nil, err
+		}
+		wire, err := e2e.FishEncrypt(fishKey, text)
+		return wire, err == nil, err
+	}
+	return text, false, nil
+}
+
+// decryptIncoming returns the plain text of a PRIVMSG from sender to target
+// and whether it arrived encrypted. ok=false means an E2E message could not be
+// read and must be dropped; a FiSH message under the wrong key is passed on
+// as is, like other FiSH clients do.
+func (c *IRCClient) decryptIncoming(sender, target, text string) (plain string, encrypted, ok bool) {
+	conversation := target
+	if !c.isChannelName(target) {
+		conversation = c.pmPeer(sender, target)
+	}
+	key := c.foldKey(conversation)
+
+	if e2e.IsEncrypted(text) && !c.isChannelName(target) {
+		c.mu.RLock()
+		session := c.e2eSessions[key]
+		c.mu.RUnlock()
+		var err error
+		switch {
+		case session == nil:
+			err = fmt.Errorf("there is no encrypted conversation with them")
+		case c.isMe(sender):
+			plain, err = session.OpenOwn(text)
+		default:
+			plain, err = session.Open(text)
+		}
+		if err == nil {
+			return plain, true, true
+		}
+		if c.isMe(sender) {
+			return "", false, false
+		}
+		if session != nil {
+			// Anyone can inject a line under the peer's nick, so one that fails
+			// to authenticate is dropped and the session kept.
+			c.warnConversation(sender, fmt.Sprintf("Dropped a message claiming to be from %s that couldn't be decrypted (%v). The encrypted conversation carries on.", sender, err))
+			return "", false, false
+		}
+		c.writeStatusLine("error", fmt.Sprintf("Couldn't decrypt a message from %s (%v). They have been told to start a new encrypted conversation.", sender, err))
+		if err := c.sendE2ECTCP(SendProtocol, sender, e2e.VerbEnd); err != nil {
+			logger.Log.Debug().Err(err).Str("to", sender).Msg("Failed to send E2E END")
+		}
+		return "", false, false
+	}
+
+	c.mu.RLock()
+	fishKey := c.fishKeys[key]
+	c.mu.RUnlock()
+	if fishKey == "" {
+		return text, false, true
+	}
+	action, isAction := ctcpActionText(text)
+	body := text
+	if isAction {
+		body = action
+	}
+	if !e2e.IsFish(body) {
+		return text, false, true
+	}
+	decrypted, err := e2e.FishDecrypt(fishKey, body)
+	if err != nil {
+		logger.Log.Debug().Err(err).Str("target", conversation).Msg("FiSH message didn't decrypt")
+		return text, false, true
+	}
+	if isAction {
+		decrypted = "\x01ACTION " + decrypted + "\x01"
+	}
+	return decrypted, true, true
+}
+
+// encryptionBroken reports whether the E2E session with target is broken.
+func (c *IRCClient) encryptionBroken(target string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.e2eBroken[c.foldKey(target)]
+}
+
+// errEncryptionBroken is what sending to a peer whose session broke returns.
+func errEncryptionBroken(nick string) error {
+	return fmt.Errorf("the encrypted conversation with %s is broken; run /e2e start %s to start a new one or /e2e stop %s to send in plain text", nick, nick, nick)
+}
+
+// warnConversation reports a change to how the conversation with nick is
+// protected in its query, where the user is about to type, and in the status
+// buffer.
+func (c *IRCClient) warnConversation(nick, text string) {
+	c.writeStatusLine("error", text)
+	if err := c.storage.WriteMessageSync(storage.Message{
+		NetworkID:   c.networkID,
+		User:        "*",
+		Message:     text,
+		MessageType: "notice",
+		Timestamp:   time.Now(),
+		PMTarget:    nick,
+	}); err != nil {
+		logger.Log.Warn().Err(err).Str("nick", nick).Msg("Failed to write encryption warning")
+	}
+	// Reported as a notice, like a CTCP reply, so it badges the query without
+	// raising a desktop notification.
+	c.eventBus.Emit(events.Event{
+		Type: EventMessageReceived,
+		Data: map[string]interface{}{
+			"network":     c.network.Address,
+			"networkId":   c.networkID,
+			"networkName": c.network.Name,
+			"channel":     c.CurrentNick(),
+			"user":        nick,
+			"message":     text,
+			"messageType": "notice",
+		},
+		Timestamp: time.Now(),
+		Source:    events.EventSourceIRC,
+	})
+}
+
+// plaintextChunk is maxMessageChunk less what encrypting for target adds.
+func (c *IRCClient) plaintextChunk(target string) int {
+	budget := c.maxMessageChunk(target)
+	switch c.EncryptionState(target).Mode {
+	case EncryptionE2E:
+		return max(e2e.MaxPlaintext(budget), minEncryptedChunk)
+	case EncryptionFish:
+		return max(e2e.FishMaxPlaintext(budget), minEncryptedChunk)
+	}
+	return budget
+}
+
+// minEncryptedChunk keeps splitting sane on a tiny line budget.
+const minEncryptedChunk = 16
+
+func ctcpActionText(text string) (string, bool) {
+	if len(text) >= len("\x01ACTION \x01") && strings.HasPrefix(text, "\x01ACTION ") && strings.HasSuffix(text, "\x01") {
+		return text[len("\x01ACTION ") : len(text)-1], true
+	}
+	return "", false
+}
+
+func (c *IRCClient) emitEncryptionChanged(target string) {
+	c.eventBus.Emit(events.Event{
+		Type: EventEncryptionChanged,
+		Data: map[string]interface{}{
+			"network":   c.network.Address,
+			"networkId": c.networkID,
+			"target":    target,
+		},
+		Timestamp: time.Now(),
+		Source:    events.EventSourceIRC,
+	})
+}
@@ -0,0 +1,326 @@
+package irc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/e2e"
+	"github.com/matt0x6f/irc-client/internal/events"
+)
+
+func newEncryptionTestClient(t *testing.T) (*IRCClient, <-chan string) {
+	t.Helper()
+	c := newPrivmsgTestClient(t)
+	c.currentNick = "matt0x6f"
+	c.connected = true
+	conn, sentLines := newConnectedPipe(t)
+	c.conn = conn
+	return c, sentLines
+}
+
+// sentText returns the trailing parameter of a raw PRIVMSG line.
+func sentText(t *testing.T, line string) string {
+	t.Helper()
+	_, text, ok := strings.Cut(line, " :")
+	if !ok {
+		t.Fatalf("no text in %q", line)
+	}
+	return text
+}
+
+// TestE2EConversation: we offer a session, the peer accepts, and from then on
+// what we send is sealed for them, what they send is opened and stored marked
+// encrypted, and an END sealed in the session ends it.
+func TestE2EConversation(t *testing.T) {
+	c, sentLines := newEncryptionTestClient(t)
+	identity, _ := e2e.NewIdentity()
+	c.SetEncryptionKeys(identity, nil, nil)
+	bob, _ := e2e.NewIdentity()
+	received := make(chan events.Event, 4)
+	c.eventBus.Subscribe(EventMessageReceived, capturingSub{got: received})
+
+	if err := c.StartEncryption("bob"); err != nil {
+		t.Fatalf("StartEncryption: %v", err)
+	}
+	initLine := drainUntilPrefix(t, sentLines, "PRIVMSG bob :\x01E2E INIT ", 2*time.Second)
+	if got := c.EncryptionState("bob").Mode; got != EncryptionPending {
+		t.Fatalf("mode after INIT = %q", got)
+	}
+	bobSession, accept, err := e2e.Accept(bob, strings.Trim(sentText(t, initLine), "\x01")[len("E2E "):])
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG matt0x6f :\x01E2E "+accept+"\x01"))
+	st := c.EncryptionState("Bob")
+	if st.Mode != EncryptionE2E || st.PeerFingerprint != bob.Fingerprint() || st.Verified {
+		t.Fatalf("state after ACCEPT = %+v", st)
+	}
+	c.SetTrustedFingerprint("bob", bob.Fingerprint())
+	if !c.EncryptionState("bob").Verified {
+		t.Fatal("verified fingerprint not recognised")
+	}
+
+	if err := c.SendMessage("bob", "hello bob"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	wire := sentText(t, drainUntilPrefix(t, sentLines, "PRIVMSG bob :", 2*time.Second))
+	if got, err := bobSession.Open(wire); err != nil || got != "hello bob" {
+		t.Fatalf("bob read %q, %v (wire %q)", got, err, wire)
+	}
+
+	sealed, _ := bobSession.Seal("hi matt")
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG matt0x6f :"+sealed))
+	if ev := <-received; ev.Data["message"] != "hi matt" || ev.Data["encrypted"] != true {
+		t.Fatalf("received event = %+v", ev.Data)
+	}
+	rows, err := c.storage.GetPrivateMessages(c.networkID, "bob", "matt0x6f", 10)
+	if err != nil {
+		t.Fatalf("GetPrivateMessages: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("stored %d rows", len(rows))
+	}
+	for _, row := range rows {
+		if !row.Encrypted || strings.HasPrefix(row.Message, "+E2E") {
+			t.Fatalf("stored row %+v", row)
+		}
+	}
+
+	end, _ := bobSession.Seal("\x01E2E END\x01")
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG matt0x6f :"+end))
+	if got := c.EncryptionState("bob").Mode; got != EncryptionNone {
+		t.Fatalf("mode after a sealed END = %q", got)
+	}
+}
+
+// TestE2ESessionSurvivesForgery: neither a plain END nor a line that fails to
+// decrypt, which anyone can send under the peer's nick, gets a message out in
+// the clear. The bad line is dropped and the session kept; the plain END
+// leaves it broken, refusing to send, until the user stops or re-keys it.
+func TestE2ESessionSurvivesForgery(t *testing.T) {
+	c, sentLines := newEncryptionTestClient(t)
+	identity, _ := e2e.NewIdentity()
+	c.SetEncryptionKeys(identity, nil, nil)
+	bob, _ := e2e.NewIdentity()
+	bobHandshake, bobInit, _ := e2e.StartHandshake(bob)
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG matt0x6f :\x01E2E "+bobInit+"\x01"))
+	if err := c.StartEncryption("bob"); err != nil {
+		t.Fatalf("StartEncryption: %v", err)
+	}
+	bobSession, err := bobHandshake.Finish(e2eInit(t, drainUntilPrefix(t, sentLines, "PRIVMSG bob :\x01E2E ACCEPT ", 2*time.Second)))
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG matt0x6f :+E2E bm90IHNlYWxlZCBmb3IgdXMgYXQgYWxsLCBzb3JyeQ"))
+	nothingSentTo(t, sentLines, "bob")
+	if err := c.SendMessage("bob", "after garbage"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	wire := sentText(t, drainUntilPrefix(t, sentLines, "PRIVMSG bob :", 2*time.Second))
+	if got, err := bobSession.Open(wire); err != nil || got != "after garbage" {
+		t.Fatalf("after an unreadable line bob read %q, %v (wire %q)", got, err, wire)
+	}
+
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG matt0x6f :\x01E2E END\x01"))
+	if st := c.EncryptionState("bob"); st.Mode != EncryptionBroken || st.PeerFingerprint != bob.Fingerprint() {
+		t.Fatalf("state after a plain END = %+v", st)
+	}
+	if err := c.SendMessage("bob", "secret plans"); err == nil {
+		t.Fatal("SendMessage succeeded on a broken session")
+	}
+	if err := c.SendMessage("bob", "long secret plans\nover two lines"); err == nil {
+		t.Fatal("a paste was queued on a broken session")
+	}
+	if err := c.SendAction("bob", "whispers"); err == nil {
+		t.Fatal("SendAction succeeded on a broken session")
+	}
+	nothingSentTo(t, sentLines, "bob")
+
+	if err := c.StopEncryption("bob"); err != nil {
+		t.Fatalf("StopEncryption: %v", err)
+	}
+	wire = sentText(t, drainUntilPrefix(t, sentLines, "PRIVMSG bob :", 2*time.Second))
+	if got, err := bobSession.Open(wire); err != nil || got != "\x01E2E END\x01" {
+		t.Fatalf("END was not sealed: %q, %v (wire %q)", got, err, wire)
+	}
+	if got := c.EncryptionState("bob").Mode; got != EncryptionNone {
+		t.Fatalf("mode after /e2e stop = %q", got)
+	}
+}
+
+// TestFishChannelKey: with a FiSH key for a channel, messages and actions go
+// out encrypted in the form other FiSH clients read, and theirs come back in
+// the clear.
+func TestFishChannelKey(t *testing.T) {
+	c, sentLines := newEncryptionTestClient(t)
+	c.SetEncryptionKeys(e2e.Identity{}, nil, map[string]string{"#Secret": "s3cret"})
+	received := make(chan events.Event, 4)
+	c.eventBus.Subscribe(EventMessageReceived, capturingSub{got: received})
+
+	if err := c.SendMessage("#secret", "top secret"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	wire := sentText(t, drainUntilPrefix(t, sentLines, "PRIVMSG #secret :", 2*time.Second))
+	if got, err := e2e.FishDecrypt("s3cret", wire); err != nil || got != "top secret" {
+		t.Fatalf("channel read %q, %v (wire %q)", got, err, wire)
+	}
+	if err := c.SendAction("#secret", "whispers"); err != nil {
+		t.Fatalf("SendAction: %v", err)
+	}
+	wire = sentText(t, drainUntilPrefix(t, sentLines, "PRIVMSG #secret :", 2*time.Second))
+	if !strings.HasPrefix(wire, "\x01ACTION +OK *") {
+		t.Fatalf("action wire %q", wire)
+	}
+
+	theirs, _ := e2e.FishEncrypt("s3cret", "hello channel")
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG #secret :"+theirs))
+	if ev := <-received; ev.Data["message"] != "hello channel" || ev.Data["encrypted"] != true {
+		t.Fatalf("received event = %+v", ev.Data)
+	}
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG #open :"+theirs))
+	if ev := <-received; ev.Data["message"] != theirs || ev.Data["encrypted"] != false {
+		t.Fatalf("a channel without a key decrypted: %+v", ev.Data)
+	}
+}
+
+// nothingSentTo fails if the client messages nick within a short wait.
+func nothingSentTo(t *testing.T, sentLines <-chan string, nick string) {
+	t.Helper()
+	deadline := time.After(50 * time.Millisecond)
+	for {
+		select {
+		case line := <-sentLines:
+			if strings.HasPrefix(line, "PRIVMSG "+nick+" ") {
+				t.Fatalf("sent %q", line)
+			}
+		case <-deadline:
+			return
+		}
+	}
+}
+
+// e2eInit returns the CTCP arguments of an INIT line we sent.
+func e2eInit(t *testing.T, line string) string {
+	t.Helper()
+	return strings.Trim(sentText(t, line), "\x01")[len("E2E "):]
+}
+
+// TestE2EOfferWaitsForUser: a peer's INIT is only an offer. Nothing is sent
+// and an existing session is kept until the user accepts; the ACCEPT then goes
+// out before anything sealed with the new session.
+func TestE2EOfferWaitsForUser(t *testing.T) {
+	c, sentLines := newEncryptionTestClient(t)
+	identity, _ := e2e.NewIdentity()
+	c.SetEncryptionKeys(identity, nil, nil)
+	bob, _ := e2e.NewIdentity()
+
+	bobHandshake, bobInit, _ := e2e.StartHandshake(bob)
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG matt0x6f :\x01E2E "+bobInit+"\x01"))
+	nothingSentTo(t, sentLines, "bob")
+	if st := c.EncryptionState("bob"); st.Mode != EncryptionOffered || st.OfferFingerprint != bob.Fingerprint() {
+		t.Fatalf("state after INIT = %+v", st)
+	}
+
+	if err := c.StartEncryption("bob"); err != nil {
+		t.Fatalf("StartEncryption: %v", err)
+	}
+	accept := e2eInit(t, drainUntilPrefix(t, sentLines, "PRIVMSG bob :\x01E2E ACCEPT ", 2*time.Second))
+	bobSession, err := bobHandshake.Finish(accept)
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if st := c.EncryptionState("bob"); st.Mode != EncryptionE2E || st.OfferFingerprint != "" {
+		t.Fatalf("state after accepting = %+v", st)
+	}
+	if err := c.SendMessage("bob", "hello bob"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	wire := sentText(t, drainUntilPrefix(t, sentLines, "PRIVMSG bob :", 2*time.Second))
+	if got, err := bobSession.Open(wire); err != nil || got != "hello bob" {
+		t.Fatalf("bob read %q, %v", got, err)
+	}
+
+	// A second INIT doesn't replace the running session on its own.
+	_, again, _ := e2e.StartHandshake(bob)
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG matt0x6f :\x01E2E "+again+"\x01"))
+	nothingSentTo(t, sentLines, "bob")
+	if err := c.SendMessage("bob", "still here"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	wire = sentText(t, drainUntilPrefix(t, sentLines, "PRIVMSG bob :", 2*time.Second))
+	if got, err := bobSession.Open(wire); err != nil || got != "still here" {
+		t.Fatalf("the old session was replaced: %q, %v", got, err)
+	}
+	if st := c.EncryptionState("bob"); st.Mode != EncryptionE2E || st.OfferFingerprint != bob.Fingerprint() {
+		t.Fatalf("state with a second offer = %+v", st)
+	}
+}
+
+// TestE2ECrossingInits: when both sides start at once, the INIT that
+// outranks the other is the one accepted, and both end up in one session.
+func TestE2ECrossingInits(t *testing.T) {
+	c, sentLines := newEncryptionTestClient(t)
+	identity, _ := e2e.NewIdentity()
+	c.SetEncryptionKeys(identity, nil, nil)
+	bob, _ := e2e.NewIdentity()
+
+	if err := c.StartEncryption("bob"); err != nil {
+		t.Fatalf("StartEncryption: %v", err)
+	}
+	ourInit := e2eInit(t, drainUntilPrefix(t, sentLines, "PRIVMSG bob :\x01E2E INIT ", 2*time.Second))
+	bobHandshake, bobInit, _ := e2e.StartHandshake(bob)
+	c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG matt0x6f :\x01E2E "+bobInit+"\x01"))
+
+	var bobSession *e2e.Session
+	var err error
+	if bobHandshake.Outranks(ourInit) {
+		accept := e2eInit(t, drainUntilPrefix(t, sentLines, "PRIVMSG bob :\x01E2E ACCEPT ", 2*time.Second))
+		bobSession, err = bobHandshake.Finish(accept)
+	} else {
+		nothingSentTo(t, sentLines, "bob")
+		var accept string
+		bobSession, accept, err = e2e.Accept(bob, ourInit)
+		c.handlePrivmsg(parse(t, ":bob!b@h PRIVMSG matt0x6f :\x01E2E "+accept+"\x01"))
+	}
+	if err != nil {
+		t.Fatalf("bob's side of the handshake: %v", err)
+	}
+	if st := c.EncryptionState("bob"); st.Mode != EncryptionE2E || st.PeerFingerprint != bob.Fingerprint() {
+		t.Fatalf("state after crossing INITs = %+v", st)
+	}
+	if err := c.SendMessage("bob", "hello bob"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	wire := sentText(t, drainUntilPrefix(t, sentLines, "PRIVMSG bob :", 2*time.Second))
+	if got, err := bobSession.Open(wire); err != nil || got != "hello bob" {
+		t.Fatalf("bob read %q, %v", got, err)
+	}
+}
+
+// TestRenameEncryptionPeer: a nick change carries the pending handshake
+// along with the session, but each verified fingerprint stays with the nick it
+// was verified for.
+func TestRenameEncryptionPeer(t *testing.T) {
+	c, sentLines := newEncryptionTestClient(t)
+	identity, _ := e2e.NewIdentity()
+	c.SetEncryptionKeys(identity, map[string]string{"bob": "fp", "Robert": "robert-fp"}, nil)
+	if err := c.StartEncryption("bob"); err != nil {
+		t.Fatalf("StartEncryption: %v", err)
+	}
+	drainUntilPrefix(t, sentLines, "PRIVMSG bob :\x01E2E INIT ", 2*time.Second)
+
+	c.renameEncryptionPeer("bob", "Robert")
+	if got := c.EncryptionState("robert").Mode; got != EncryptionPending {
+		t.Fatalf("mode after rename = %q", got)
+	}
+	if got := c.EncryptionState("bob").Mode; got != EncryptionNone {
+		t.Fatalf("old nick mode = %q", got)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.e2eTrusted["robert"] != "robert-fp" || c.e2eTrusted["bob"] != "fp" {
+		t.Fatalf("trusted = %v", c.e2eTrusted)
+	}
+}
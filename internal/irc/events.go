@@ -14,6 +14,7 @@ const (
 	EventChannelMode           = "channel.mode"
 	EventChannelUserMode       = "channel.usermode"
 	EventChannelBanList        = "channel.banlist"
+	EventChannelListMode       = "channel.listmode" // a +b/+e/+I list finished loading (carries the mode letter)
 	EventChannelNamesComplete  = "channel.names.complete"
 	EventChannelsChanged       = "channels.changed"
 	EventConnectionEstablished = "connection.established"
@@ -27,20 +28,28 @@ const (
 	EventChannelListItem       = "channel.list.item"
 	EventChannelListEnd        = "channel.list.end"
 	EventHistoryReceived       = "history.received"
-	EventBotDetected           = "bot.detected"    // a nick was recognized as an IRCv3 bot (bot tag or RPL_WHOISBOT)
-	EventUserMetaChanged       = "user.meta"       // a user's live roster attributes changed (away/account/host)
-	EventSelfStatusChanged     = "self.status"     // our server-acknowledged away state changed
-	EventSTSPolicy             = "sts.policy"      // server advertised an IRCv3 STS policy in CAP LS
-	EventMonitorChanged        = "monitor.changed" // a monitored nick's online/offline state changed (MONITOR)
-	EventTypingReceived        = "typing.received" // a peer sent an IRCv3 +typing client tag (active/paused/done)
-	EventInviteReceived        = "invite.received" // an INVITE addressed to us (actionable)
-	EventStatusMessage         = "status.message"  // a line was written to a network's status buffer (server log)
-	EventDCCControl            = "dcc.control"     // an inbound CTCP DCC negotiation message
+	EventBotDetected           = "bot.detected"       // a nick was recognized as an IRCv3 bot (bot tag or RPL_WHOISBOT)
+	EventUserMetaChanged       = "user.meta"          // a user's live roster attributes changed (away/account/host)
+	EventSelfStatusChanged     = "self.status"        // our server-acknowledged away state changed
+	EventSTSPolicy             = "sts.policy"         // server advertised an IRCv3 STS policy in CAP LS
+	EventMonitorChanged        = "monitor.changed"    // a monitored nick's online/offline state changed (MONITOR)
+	EventTypingReceived        = "typing.received"    // a peer sent an IRCv3 +typing client tag (active/paused/done)
+	EventInviteReceived        = "invite.received"    // an INVITE addressed to us (actionable)
+	EventStatusMessage         = "status.message"     // a line was written to a network's status buffer (server log)
+	EventDCCControl            = "dcc.control"        // an inbound CTCP DCC negotiation message
+	EventNetsplit              = "netsplit"           // one channel's summary of users lost in a netsplit (replaces their user.quit events)
+	EventNetjoin               = "netjoin"            // one channel's summary of users back from a netsplit (replaces their user.joined events)
+	EventMessageUnfiltered     = "message.unfiltered" // a smart-filtered join row was un-hidden because its user started talking
+	// A draft/account-registration REGISTER or VERIFY finished, or needs a code.
+	EventAccountRegistration = "account.registration"
+	// A conversation's encryption changed: E2E offered, started or ended, a
+	// FiSH key set, or a fingerprint verified.
+	EventEncryptionChanged = "encryption.changed"
 )
 
 // UserMeta holds the live, session-local roster attributes Cascade tracks for a
 // nick via the IRCv3 caps away-notify, account-notify, extended-join, chghost,
-// and account-tag. It is deliberately not persisted: a nick's away/account/host
+// account-tag and draft/metadata-2. It is deliberately not persisted: a nick's away/account/host
 // is only meaningful for the current session and is rebuilt on reconnect.
 type UserMeta struct {
 	Away        bool   `json:"away"`         // true while the user is marked away (away-notify)
@@ -48,9 +57,15 @@ type UserMeta struct {
 	Account     string `json:"account"`      // account the user is logged in as; "" when not logged in
 	Host        string `json:"host"`         // user@host learned from chghost / userhost-in-names; "" until seen
 	Realname    string `json:"realname"`     // realname learned from setname / extended-join; "" until seen
+	Avatar      string `json:"avatar"`       // draft/metadata-2 "avatar" URL; "" when unset
+	DisplayName string `json:"display_name"` // draft/metadata-2 "display-name"
+	Pronouns    string `json:"pronouns"`     // draft/metadata-2 "pronouns"
+	Homepage    string `json:"homepage"`     // draft/metadata-2 "homepage"
+	Status      string `json:"status"`       // draft/metadata-2 "status", a short free-text status
 }
 
-// BanEntry represents a single entry from a channel ban list (RPL_BANLIST 367)
+// BanEntry represents a single entry from a channel list mode reply (RPL_BANLIST 367,
+// RPL_EXCEPTLIST 348 or RPL_INVITELIST 346)
 type BanEntry struct {
 	Mask string `json:"mask"`
 	By   string `json:"by"`
@@ -62,6 +77,7 @@ type ChannelListItem struct {
 	Channel   string `json:"channel"`
 	Users     int    `json:"users"`
 	Topic     string `json:"topic"`
+	Modes     string `json:"modes"` // "+nt" when the server prefixes the topic with modes
 	NetworkID int64  `json:"networkId"`
 }
 
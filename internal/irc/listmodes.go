@@ -0,0 +1,231 @@
+package irc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/events"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// Channel list modes: +b (bans), +e (ban exceptions) and +I (invite
+// exceptions). A bare "MODE #chan +e" query is answered by one entry numeric
+// per mask followed by an end numeric; the collected list replaces the stored
+// copy, and MODE echoes keep that copy current between refreshes.
+
+// TrackedListModes are the list modes the client queries and persists.
+const TrackedListModes = "beI"
+
+// defaultModesPerLine is the RFC 1459 limit on parameterised mode changes per
+// MODE command, assumed when the server does not advertise MODES.
+const defaultModesPerLine = 3
+
+// listModeNumerics maps each tracked list mode to its entry and end replies.
+var listModeNumerics = map[rune]struct{ entry, end string }{
+	'b': {"367", "368"}, // RPL_BANLIST / RPL_ENDOFBANLIST
+	'e': {"348", "349"}, // RPL_EXCEPTLIST / RPL_ENDOFEXCEPTLIST
+	'I': {"346", "347"}, // RPL_INVITELIST / RPL_ENDOFINVITELIST
+}
+
+// ListModeChange is one addition or removal on a channel list mode.
+type ListModeChange struct {
+	Add  bool
+	Mode rune
+	Mask string
+}
+
+// registerListModeCallbacks wires the entry/end numerics for every tracked list.
+func (c *IRCClient) registerListModeCallbacks() {
+	for mode, numerics := range listModeNumerics {
+		c.addCallback(numerics.entry, func(e ircmsg.Message) { c.handleListModeEntry(mode, e) })
+		c.addCallback(numerics.end, func(e ircmsg.Message) { c.handleListModeEnd(mode, e) })
+	}
+}
+
+func listModeKey(channelKey string, mode rune) string {
+	return channelKey + "\x00" + string(mode)
+}
+
+// handleListModeEntry collects a single entry: <me> <channel> <mask> [<setter> <time>].
+func (c *IRCClient) handleListModeEntry(mode rune, e ircmsg.Message) {
+	if len(e.Params) < 3 {
+		return
+	}
+	entry := BanEntry{Mask: e.Params[2]}
+	if len(e.Params) >= 4 {
+		entry.By = e.Params[3]
+	}
+	if len(e.Params) >= 5 {
+		fmt.Sscanf(e.Params[4], "%d", &entry.Time)
+	}
+	key := listModeKey(c.foldKey(e.Params[1]), mode)
+	c.listModeEntriesMu.Lock()
+	if c.listModeEntries == nil {
+		c.listModeEntries = make(map[string][]BanEntry)
+	}
+	c.listModeEntries[key] = append(c.listModeEntries[key], entry)
+	c.listModeEntriesMu.Unlock()
+}
+
+// handleListModeEnd flushes a collected list: it replaces the stored list and
+// announces it. Bans additionally go out as EventChannelBanList, which the
+// channel mode editor listens for.
+func (c *IRCClient) handleListModeEnd(mode rune, e ircmsg.Message) {
+	if len(e.Params) < 2 {
+		return
+	}
+	channel := e.Params[1]
+	channelKey := c.foldKey(channel)
+	key := listModeKey(channelKey, mode)
+	c.listModeEntriesMu.Lock()
+	entries := c.listModeEntries[key]
+	delete(c.listModeEntries, key)
+	c.listModeEntriesMu.Unlock()
+
+	rows := make([]storage.ChannelListEntry, len(entries))
+	payload := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		rows[i] = storage.ChannelListEntry{Mask: entry.Mask, SetBy: entry.By}
+		if entry.Time > 0 {
+			setAt := time.Unix(entry.Time, 0)
+			rows[i].SetAt = &setAt
+		}
+		payload[i] = map[string]interface{}{"mask": entry.Mask, "by": entry.By, "time": entry.Time}
+	}
+	if err := c.storage.ReplaceChannelListEntries(c.networkID, channel, channelKey, string(mode), rows); err != nil {
+		logger.Log.Warn().Err(err).Str("channel", channel).Str("mode", string(mode)).Msg("Failed to store channel list")
+	}
+
+	if mode == 'b' {
+		c.eventBus.Emit(events.Event{
+			Type: EventChannelBanList,
+			Data: map[string]interface{}{
+				"network":   c.network.Address,
+				"networkId": c.networkID,
+				"channel":   channel,
+				"bans":      payload,
+			},
+			Timestamp: time.Now(),
+			Source:    events.EventSourceIRC,
+		})
+	}
+	c.eventBus.Emit(events.Event{
+		Type: EventChannelListMode,
+		Data: map[string]interface{}{
+			"network":   c.network.Address,
+			"networkId": c.networkID,
+			"channel":   channel,
+			"mode":      string(mode),
+			"entries":   payload,
+		},
+		Timestamp: time.Now(),
+		Source:    events.EventSourceIRC,
+	})
+}
+
+// recordListModeChanges applies tracked list-mode additions and removals from a
+// MODE echo to the stored lists, so they stay current between refreshes.
+func (c *IRCClient) recordListModeChanges(channel, actor string, changes []ModeChange) {
+	channelKey := c.foldKey(channel)
+	now := time.Now()
+	for _, mc := range changes {
+		if mc.Kind != ModeKindList || mc.Param == "" || !strings.ContainsRune(TrackedListModes, mc.Mode) {
+			continue
+		}
+		var err error
+		if mc.Add {
+			err = c.storage.UpsertChannelListEntry(storage.ChannelListEntry{
+				NetworkID: c.networkID, Channel: channel, ChannelKey: channelKey,
+				Mode: string(mc.Mode), Mask: mc.Param, SetBy: actor, SetAt: &now,
+			})
+		} else {
+			err = c.storage.DeleteChannelListEntry(c.networkID, channelKey, string(mc.Mode), mc.Param)
+		}
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("channel", channel).Str("mask", mc.Param).Msg("Failed to update channel list")
+		}
+	}
+}
+
+// RequestChannelListMode asks the server for one of a channel's list modes
+// (MODE #channel +<mode>). Results arrive as EventChannelListMode.
+func (c *IRCClient) RequestChannelListMode(channel string, mode rune) error {
+	return c.SendRawCommand(fmt.Sprintf("MODE %s +%c", channel, mode))
+}
+
+// SetChannelListModes sends list additions and removals in as few MODE lines
+// as the server's MODES and LINELEN limits allow.
+func (c *IRCClient) SetChannelListModes(channel string, changes []ListModeChange) error {
+	c.mu.RLock()
+	cls := c.serverCapabilities.classification()
+	perLine, lineLen := c.serverCapabilities.Modes, c.serverCapabilities.LineLen
+	c.mu.RUnlock()
+
+	for _, change := range changes {
+		if !cls.List[change.Mode] {
+			return fmt.Errorf("+%c is not a list mode on this server", change.Mode)
+		}
+		if change.Mask == "" || change.Mask[0] == ':' || strings.ContainsAny(change.Mask, " \r\n") {
+			return fmt.Errorf("invalid mask %q", change.Mask)
+		}
+	}
+	for _, line := range batchListModeChanges(channel, changes, perLine, lineLen) {
+		if err := c.SendRawCommand(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchListModeChanges packs changes into MODE lines in order. perLine follows
+// the MODES token (0 = unadvertised, -1 = no limit) and lineLen the LINELEN
+// token (0 = the 512-byte default). A single change always gets a line even if
+// its mask alone exceeds the budget; the server is the judge of that.
+func batchListModeChanges(channel string, changes []ListModeChange, perLine, lineLen int) []string {
+	if perLine == 0 {
+		perLine = defaultModesPerLine
+	}
+	if lineLen <= 0 {
+		lineLen = defaultLineLen
+	}
+	budget := lineLen - 2 // CRLF
+
+	var lines []string
+	var batch []ListModeChange
+	for _, change := range changes {
+		candidate := append(batch[:len(batch):len(batch)], change)
+		if len(batch) > 0 && ((perLine > 0 && len(candidate) > perLine) || len(formatListModeLine(channel, candidate)) > budget) {
+			lines = append(lines, formatListModeLine(channel, batch))
+			candidate = []ListModeChange{change}
+		}
+		batch = candidate
+	}
+	if len(batch) > 0 {
+		lines = append(lines, formatListModeLine(channel, batch))
+	}
+	return lines
+}
+
+// formatListModeLine renders "MODE #chan +bb-e m1 m2 m3", emitting a sign only
+// when it changes.
+func formatListModeLine(channel string, batch []ListModeChange) string {
+	var modes strings.Builder
+	masks := make([]string, len(batch))
+	var sign byte
+	for i, change := range batch {
+		want := byte('-')
+		if change.Add {
+			want = '+'
+		}
+		if want != sign {
+			modes.WriteByte(want)
+			sign = want
+		}
+		modes.WriteRune(change.Mode)
+		masks[i] = change.Mask
+	}
+	return fmt.Sprintf("MODE %s %s %s", channel, modes.String(), strings.Join(masks, " "))
+}
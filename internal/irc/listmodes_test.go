@@ -0,0 +1,94 @@
+package irc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+func TestBatchListModeChanges(t *testing.T) {
+	changes := []ListModeChange{
+		{Add: true, Mode: 'b', Mask: "a!*@*"},
+		{Add: true, Mode: 'b', Mask: "b!*@*"},
+		{Add: false, Mode: 'e', Mask: "c!*@*"},
+		{Add: true, Mode: 'I', Mask: "d!*@*"},
+	}
+
+	t.Run("unadvertised MODES falls back to three per line", func(t *testing.T) {
+		got := batchListModeChanges("#ops", changes, 0, 0)
+		want := []string{
+			"MODE #ops +bb-e a!*@* b!*@* c!*@*",
+			"MODE #ops +I d!*@*",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("lines = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("MODES limit", func(t *testing.T) {
+		got := batchListModeChanges("#ops", changes, 1, 0)
+		if len(got) != 4 || got[2] != "MODE #ops -e c!*@*" {
+			t.Errorf("lines = %q, want one change per line", got)
+		}
+	})
+
+	t.Run("unlimited MODES still respects the line length", func(t *testing.T) {
+		many := make([]ListModeChange, 40)
+		for i := range many {
+			many[i] = ListModeChange{Add: true, Mode: 'b', Mask: strings.Repeat("x", 20) + "!*@*"}
+		}
+		got := batchListModeChanges("#ops", many, -1, 0)
+		if len(got) < 2 {
+			t.Fatalf("lines = %d, want the batch split by length", len(got))
+		}
+		total := 0
+		for _, line := range got {
+			if len(line) > defaultLineLen-2 {
+				t.Errorf("line is %d bytes, over the %d budget", len(line), defaultLineLen-2)
+			}
+			total += strings.Count(line, "!*@*")
+		}
+		if total != len(many) {
+			t.Errorf("sent %d masks, want %d", total, len(many))
+		}
+	})
+}
+
+// TestListModeRepliesReplaceStoredList: an exception list (348/349) replaces the
+// stored +e list, and MODE echoes then add and remove entries.
+func TestListModeRepliesReplaceStoredList(t *testing.T) {
+	c, _ := newUserMetaTestClient(t)
+	if err := c.storage.UpsertChannelListEntry(storage.ChannelListEntry{
+		NetworkID: c.networkID, Channel: "#ops", ChannelKey: "#ops", Mode: "e", Mask: "gone!*@*",
+	}); err != nil {
+		t.Fatalf("UpsertChannelListEntry: %v", err)
+	}
+
+	c.handleListModeEntry('e', parse(t, ":srv 348 matt0x6f #Ops friend!*@* alice 1700000000"))
+	c.handleListModeEnd('e', parse(t, ":srv 349 matt0x6f #Ops :End of Channel Exception List"))
+
+	got, err := c.storage.ListChannelListEntries(c.networkID, "#ops", "e")
+	if err != nil {
+		t.Fatalf("ListChannelListEntries: %v", err)
+	}
+	if len(got) != 1 || got[0].Mask != "friend!*@*" || got[0].SetBy != "alice" ||
+		got[0].SetAt == nil || !got[0].SetAt.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("entries = %+v, want only friend!*@* set by alice", got)
+	}
+
+	c.recordListModeChanges("#OPS", "bob", []ModeChange{
+		{Add: true, Mode: 'e', Param: "pal!*@*", Kind: ModeKindList},
+		{Add: false, Mode: 'e', Param: "friend!*@*", Kind: ModeKindList},
+		{Add: true, Mode: 'q', Param: "quiet!*@*", Kind: ModeKindList}, // untracked list mode
+	})
+	got, err = c.storage.ListChannelListEntries(c.networkID, "#ops", "")
+	if err != nil {
+		t.Fatalf("ListChannelListEntries: %v", err)
+	}
+	if len(got) != 1 || got[0].Mask != "pal!*@*" || got[0].SetBy != "bob" {
+		t.Errorf("entries after MODE = %+v, want only pal!*@* set by bob", got)
+	}
+}
@@ -0,0 +1,163 @@
+package irc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/logger"
+)
+
+// User metadata through IRCv3 draft/metadata-2. Once registered we subscribe to
+// the keys the roster shows; the server then sends their values for everyone
+// we share a channel with (RPL_KEYVALUE, usually in a metadata batch after a
+// JOIN) and a METADATA message whenever one changes. Values live in UserMeta
+// alongside away/account/host, so they reach the frontend as
+// EventUserMetaChanged. Channel metadata is ignored.
+
+const metadataCap = "draft/metadata-2"
+
+// Metadata keys the roster subscribes to and SetMetadata accepts.
+const (
+	MetadataAvatar      = "avatar"
+	MetadataDisplayName = "display-name"
+	MetadataPronouns    = "pronouns"
+	MetadataHomepage    = "homepage"
+	MetadataStatus      = "status"
+)
+
+var subscribedMetadataKeys = []string{MetadataAvatar, MetadataDisplayName, MetadataPronouns, MetadataHomepage, MetadataStatus}
+
+// metadataSyncDelay is how long to wait before SYNC when RPL_METADATASYNCLATER
+// gives no retry time.
+const metadataSyncDelay = 5 * time.Second
+
+// subscribeMetadata sends METADATA SUB for the roster keys. Called at
+// RPL_WELCOME; a no-op unless the server granted draft/metadata-2.
+func (c *IRCClient) subscribeMetadata() {
+	if !c.capEnabled(metadataCap) {
+		return
+	}
+	args := append([]string{"*", "SUB"}, subscribedMetadataKeys...)
+	if err := c.conn.Send("METADATA", args...); err != nil {
+		logger.Log.Debug().Err(err).Msg("Failed to subscribe to metadata")
+	}
+}
+
+// setMetadataValue stores one key for a nick's roster entry. An empty value
+// clears it. Keys we didn't subscribe to are dropped.
+func (c *IRCClient) setMetadataValue(target, key, value string) {
+	if target == "*" {
+		target = c.CurrentNick()
+	}
+	if target == "" || c.isChannelName(target) {
+		return
+	}
+	var apply func(*UserMeta)
+	switch key {
+	case MetadataAvatar:
+		apply = func(m *UserMeta) { m.Avatar = value }
+	case MetadataDisplayName:
+		apply = func(m *UserMeta) { m.DisplayName = value }
+	case MetadataPronouns:
+		apply = func(m *UserMeta) { m.Pronouns = value }
+	case MetadataHomepage:
+		apply = func(m *UserMeta) { m.Homepage = value }
+	case MetadataStatus:
+		apply = func(m *UserMeta) { m.Status = value }
+	default:
+		return
+	}
+	c.applyUserMeta(target, apply)
+}
+
+// handleMetadata processes a pushed change: "METADATA <target> <key>
+// <visibility> [:<value>]". A missing value means the key was removed.
+func (c *IRCClient) handleMetadata(e ircmsg.Message) {
+	if len(e.Params) < 3 {
+		return
+	}
+	value := ""
+	if len(e.Params) >= 4 {
+		value = e.Params[3]
+	}
+	c.setMetadataValue(e.Params[0], e.Params[1], value)
+}
+
+// handleMetadataKeyValue processes RPL_WHOISKEYVALUE (760) and RPL_KEYVALUE
+// (761): "<client> <target> <key> <visibility> :<value>".
+func (c *IRCClient) handleMetadataKeyValue(e ircmsg.Message) {
+	if len(e.Params) < 5 {
+		return
+	}
+	c.setMetadataValue(e.Params[1], e.Params[2], e.Params[4])
+}
+
+// handleMetadataKeyNotSet processes RPL_KEYNOTSET (766): "<client> <target>
+// <key> :key not set".
+func (c *IRCClient) handleMetadataKeyNotSet(e ircmsg.Message) {
+	if len(e.Params) < 3 {
+		return
+	}
+	c.setMetadataValue(e.Params[1], e.Params[2], "")
+}
+
+// handleMetadataSyncLater processes RPL_METADATASYNCLATER (774): "<client>
+// <target> [<retry after>]". The server held back a target's metadata, so ask
+// for it again with SYNC once the retry time has passed.
+func (c *IRCClient) handleMetadataSyncLater(e ircmsg.Message) {
+	if len(e.Params) < 2 {
+		return
+	}
+	target := e.Params[1]
+	delay := metadataSyncDelay
+	if len(e.Params) >= 3 {
+		if secs, err := strconv.Atoi(e.Params[2]); err == nil && secs > 0 {
+			delay = time.Duration(secs) * time.Second
+		}
+	}
+	time.AfterFunc(delay, func() {
+		if !c.IsConnected() {
+			return
+		}
+		c.enqueueAutomaticRequest("METADATA SYNC "+target, func() error {
+			return c.conn.Send("METADATA", target, "SYNC")
+		})
+	})
+}
+
+// SetMetadata sets one of our own metadata keys; an empty value removes it.
+// The server confirms with RPL_KEYVALUE, which updates our roster entry, and
+// refuses with FAIL METADATA, which lands in the status window.
+func (c *IRCClient) SetMetadata(key, value string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+	if !c.capEnabled(metadataCap) {
+		return fmt.Errorf("this server doesn't support metadata")
+	}
+	if !isSubscribedMetadataKey(key) {
+		return fmt.Errorf("unknown metadata key %q", key)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("metadata values must not contain line breaks")
+	}
+	if value == "" {
+		return c.conn.Send("METADATA", "*", "SET", key)
+	}
+	return c.conn.Send("METADATA", "*", "SET", key, value)
+}
+
+// MetadataSupported reports whether the server granted draft/metadata-2.
+func (c *IRCClient) MetadataSupported() bool { return c.capEnabled(metadataCap) }
+
+func isSubscribedMetadataKey(key string) bool {
+	for _, k := range subscribedMetadataKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package irc
+
+import (
+	"testing"
+	"time"
+)
+
+// TestMetadataRepliesFillUserMeta: RPL_KEYVALUE and pushed METADATA set roster
+// keys, a METADATA without a value and RPL_KEYNOTSET clear them, and channel
+// targets and unsubscribed keys are ignored.
+func TestMetadataRepliesFillUserMeta(t *testing.T) {
+	c, counter := newUserMetaTestClient(t)
+
+	c.handleMetadataKeyValue(parse(t, ":irc.test 761 matt0x6f alice avatar * :https://example.org/a.png"))
+	c.handleMetadata(parse(t, ":irc.test METADATA alice display-name * :Alice Liddell"))
+	c.handleMetadata(parse(t, ":irc.test METADATA alice pronouns * :she/her"))
+	c.handleMetadata(parse(t, ":irc.test METADATA alice color * :red"))
+	c.handleMetadata(parse(t, ":irc.test METADATA #chan avatar * :https://example.org/c.png"))
+	meta, _ := c.UserMetaFor("alice")
+	if meta.Avatar != "https://example.org/a.png" || meta.DisplayName != "Alice Liddell" || meta.Pronouns != "she/her" {
+		t.Fatalf("meta = %+v", meta)
+	}
+	if _, ok := c.UserMetaFor("#chan"); ok {
+		t.Error("channel metadata was stored as a nick")
+	}
+
+	c.handleMetadata(parse(t, ":irc.test METADATA alice pronouns *"))
+	c.handleMetadataKeyNotSet(parse(t, ":irc.test 766 matt0x6f alice display-name :key not set"))
+	meta, _ = c.UserMetaFor("alice")
+	if meta.Pronouns != "" || meta.DisplayName != "" || meta.Avatar == "" {
+		t.Fatalf("after clearing: meta = %+v", meta)
+	}
+	waitForMetaLatest(t, counter, "alice", func(d map[string]interface{}) bool {
+		return d["avatar"] == "https://example.org/a.png" && d["display_name"] == "" && d["pronouns"] == ""
+	})
+}
+
+// TestMetadataSubscribeAndSet: the roster keys are subscribed at welcome and
+// our own keys are set and cleared with METADATA * SET.
+func TestMetadataSubscribeAndSet(t *testing.T) {
+	c := newAnnounceBotTestClient(t)
+	c.currentNick = "robodan"
+	c.connected = true
+	conn, sentLines := newConnectedPipe(t)
+	c.conn = conn
+
+	if err := c.SetMetadata(MetadataPronouns, "they/them"); err == nil {
+		t.Fatal("SetMetadata succeeded without draft/metadata-2")
+	}
+	c.enabledCaps = map[string]bool{metadataCap: true}
+	c.subscribeMetadata()
+	if got := drainUntilPrefix(t, sentLines, "METADATA ", 2*time.Second); got != "METADATA * SUB avatar display-name pronouns homepage status" {
+		t.Fatalf("sent %q", got)
+	}
+
+	if err := c.SetMetadata("color", "red"); err == nil {
+		t.Error("SetMetadata accepted a key the roster doesn't use")
+	}
+	if err := c.SetMetadata(MetadataPronouns, "they/them"); err != nil {
+		t.Fatalf("SetMetadata: %v", err)
+	}
+	if got := drainUntilPrefix(t, sentLines, "METADATA ", 2*time.Second); got != "METADATA * SET pronouns they/them" {
+		t.Fatalf("sent %q", got)
+	}
+	if err := c.SetMetadata(MetadataStatus, ""); err != nil {
+		t.Fatalf("SetMetadata(clear): %v", err)
+	}
+	if got := drainUntilPrefix(t, sentLines, "METADATA ", 2*time.Second); got != "METADATA * SET status" {
+		t.Fatalf("sent %q", got)
+	}
+
+	c.userMeta = make(map[string]*UserMeta)
+	c.handleMetadataKeyValue(parse(t, ":irc.test 761 robodan * pronouns * :they/them"))
+	if meta, _ := c.UserMetaFor("robodan"); meta.Pronouns != "they/them" {
+		t.Fatalf("own meta = %+v", meta)
+	}
+}
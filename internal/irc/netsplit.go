@@ -0,0 +1,246 @@
+package irc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/events"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+const (
+	// netsplitQuiet is how long a split's QUITs (or a netjoin's JOINs) must stop
+	// arriving before the burst is summarized.
+	netsplitQuiet = 2 * time.Second
+	// netjoinExpiry is how long after its last QUIT a split's users are still
+	// matched against JOINs as part of its netjoin.
+	netjoinExpiry = 30 * time.Minute
+	// netsplitNickList caps how many nicks a summary row names.
+	netsplitNickList = 15
+)
+
+// netsplit is one detected split between two servers: the users it took and
+// the per-channel QUIT and JOIN bursts not yet summarized.
+type netsplit struct {
+	servers   [2]string
+	users     map[string]bool         // folded nicks that quit in the split and haven't come back
+	lastQuit  time.Time               // when the latest QUIT of the split arrived
+	quits     map[int64]*splitChannel // pending netsplit summary per channel ID
+	joins     map[int64]*splitChannel // pending netjoin summary per channel ID
+	quitTimer *time.Timer             // fires netsplitQuiet after the latest QUIT
+	joinTimer *time.Timer             // fires netsplitQuiet after the latest rejoin
+}
+
+// splitChannel is the nicks of one channel caught in a netsplit or netjoin.
+type splitChannel struct {
+	name  string
+	nicks []string
+}
+
+// parseNetsplitReason reports whether a QUIT reason has the shape servers give
+// users lost in a netsplit — exactly two distinct server names separated by one
+// space, such as "hub.example.net leaf.example.net" or the masked "*.net
+// *.split" — and returns the two names.
+func parseNetsplitReason(reason string) (string, string, bool) {
+	parts := strings.Split(reason, " ")
+	if len(parts) != 2 || parts[0] == parts[1] {
+		return "", "", false
+	}
+	for _, p := range parts {
+		if !isSplitServerName(p) {
+			return "", "", false
+		}
+	}
+	return parts[0], parts[1], true
+}
+
+// isSplitServerName reports whether s looks like a server name: dot-separated
+// non-empty labels of letters, digits, '-' and the '*' masks some networks use.
+func isSplitServerName(s string) bool {
+	labels := strings.Split(s, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '*') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// noteNetsplitQuit records that nick quit in the split between server1 and
+// server2, adding it to the pending summary of every channel in chans. The
+// summaries are written once QUITs for the split stop arriving.
+func (c *IRCClient) noteNetsplitQuit(server1, server2, nick string, chans []storage.Channel) {
+	c.splitMu.Lock()
+	defer c.splitMu.Unlock()
+	now := time.Now()
+	c.expireSplitsLocked(now)
+	if c.splits == nil {
+		c.splits = make(map[string]*netsplit)
+	}
+	key := server1 + " " + server2
+	s := c.splits[key]
+	if s == nil {
+		s = &netsplit{servers: [2]string{server1, server2}, users: make(map[string]bool)}
+		c.splits[key] = s
+	}
+	s.users[c.foldKey(nick)] = true
+	s.lastQuit = now
+	if s.quits == nil {
+		s.quits = make(map[int64]*splitChannel)
+	}
+	for _, ch := range chans {
+		sc := s.quits[ch.ID]
+		if sc == nil {
+			sc = &splitChannel{name: ch.Name}
+			s.quits[ch.ID] = sc
+		}
+		sc.nicks = append(sc.nicks, nick)
+	}
+	if s.quitTimer != nil {
+		s.quitTimer.Stop()
+	}
+	s.quitTimer = time.AfterFunc(netsplitQuiet, func() { c.flushNetsplit(key, EventNetsplit) })
+}
+
+// noteNetjoin reports whether nick's JOIN to ch is part of a netjoin — the
+// nick quit in a split that has not expired — and if so adds it to that
+// netjoin's pending summary for the channel.
+func (c *IRCClient) noteNetjoin(nick string, ch *storage.Channel) bool {
+	c.splitMu.Lock()
+	defer c.splitMu.Unlock()
+	c.expireSplitsLocked(time.Now())
+	folded := c.foldKey(nick)
+	for key, s := range c.splits {
+		if !s.users[folded] {
+			continue
+		}
+		if s.joins == nil {
+			s.joins = make(map[int64]*splitChannel)
+		}
+		sc := s.joins[ch.ID]
+		if sc == nil {
+			sc = &splitChannel{name: ch.Name}
+			s.joins[ch.ID] = sc
+		}
+		sc.nicks = append(sc.nicks, nick)
+		if s.joinTimer != nil {
+			s.joinTimer.Stop()
+		}
+		s.joinTimer = time.AfterFunc(netsplitQuiet, func() { c.flushNetsplit(key, EventNetjoin) })
+		return true
+	}
+	return false
+}
+
+// forgetSplitUser stops expecting nick back in a netjoin, once it has been
+// seen to quit, part or change nick outside a split.
+func (c *IRCClient) forgetSplitUser(nick string) {
+	c.splitMu.Lock()
+	defer c.splitMu.Unlock()
+	folded := c.foldKey(nick)
+	for _, s := range c.splits {
+		delete(s.users, folded)
+	}
+	c.expireSplitsLocked(time.Now())
+}
+
+// expireSplitsLocked forgets splits whose users can no longer be expected back
+// as a netjoin, because they all came back or the split is too old. Splits
+// with a summary still pending are kept until it is written. Callers hold
+// splitMu.
+func (c *IRCClient) expireSplitsLocked(now time.Time) {
+	for key, s := range c.splits {
+		if (len(s.users) == 0 || now.Sub(s.lastQuit) > netjoinExpiry) && len(s.quits) == 0 && len(s.joins) == 0 {
+			delete(c.splits, key)
+		}
+	}
+}
+
+// flushNetsplit writes one summary row per channel for the split's pending
+// QUITs (kind EventNetsplit) or rejoins (EventNetjoin), and emits one event
+// per channel in place of the per-user quit or join events.
+func (c *IRCClient) flushNetsplit(key, kind string) {
+	c.splitMu.Lock()
+	s := c.splits[key]
+	if s == nil {
+		c.splitMu.Unlock()
+		return
+	}
+	pending := s.quits
+	label := "Netsplit"
+	if kind == EventNetjoin {
+		pending = s.joins
+		label = "Netjoin"
+		s.joins = nil
+		// Those who came back are ordinary users again: a later JOIN of theirs
+		// is reported on its own.
+		for _, sc := range pending {
+			for _, nick := range sc.nicks {
+				delete(s.users, c.foldKey(nick))
+			}
+		}
+	} else {
+		s.quits = nil
+	}
+	servers := s.servers
+	c.splitMu.Unlock()
+
+	total := 0
+	for channelID, sc := range pending {
+		total += len(sc.nicks)
+		text := fmt.Sprintf("%s: %s ↔ %s, %d %s: %s", label, servers[0], servers[1],
+			len(sc.nicks), pluralUsers(len(sc.nicks)), summarizeNicks(sc.nicks))
+		id := channelID
+		if err := c.storage.WriteMessageSync(storage.Message{
+			NetworkID:   c.networkID,
+			ChannelID:   &id,
+			User:        "*",
+			Message:     text,
+			MessageType: kind,
+			Timestamp:   time.Now(),
+		}); err != nil && err.Error() != "storage is closed" {
+			logger.Log.Error().Err(err).Str("channel", sc.name).Msgf("Failed to store %s summary", kind)
+		}
+		c.eventBus.Emit(events.Event{
+			Type: kind,
+			Data: map[string]interface{}{
+				"network":     c.network.Address,
+				"networkName": c.network.Name,
+				"networkId":   c.networkID,
+				"channel":     sc.name,
+				"servers":     []string{servers[0], servers[1]},
+				"users":       sc.nicks,
+				"message":     text,
+			},
+			Timestamp: time.Now(),
+			Source:    events.EventSourceIRC,
+		})
+	}
+	logger.Log.Info().Str("servers", key).Int("users", total).Msgf("Summarized %s", kind)
+}
+
+// summarizeNicks lists nicks for a summary row, naming at most
+// netsplitNickList of them.
+func summarizeNicks(nicks []string) string {
+	if len(nicks) <= netsplitNickList {
+		return strings.Join(nicks, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(nicks[:netsplitNickList], ", "), len(nicks)-netsplitNickList)
+}
+
+func pluralUsers(n int) string {
+	if n == 1 {
+		return "user"
+	}
+	return "users"
+}
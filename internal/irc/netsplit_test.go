@@ -0,0 +1,136 @@
+package irc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+func TestParseNetsplitReason(t *testing.T) {
+	cases := []struct {
+		reason string
+		split  bool
+	}{
+		{"hub.example.net leaf.example.net", true},
+		{"*.net *.split", true},
+		{"irc-1.example.org irc-2.example.org", true},
+		{"Quit: see you tomorrow", false},
+		{"Ping timeout: 240 seconds", false},
+		{"example.net example.net", false},
+		{"hub.example.net  leaf.example.net", false},
+		{"hub. leaf.example.net", false},
+		{"localhost leaf.example.net", false},
+		{"Read error", false},
+	}
+	for _, tc := range cases {
+		if _, _, got := parseNetsplitReason(tc.reason); got != tc.split {
+			t.Errorf("parseNetsplitReason(%q) = %v, want %v", tc.reason, got, tc.split)
+		}
+	}
+}
+
+func TestNetsplitQuitsAndRejoinsAreSummarized(t *testing.T) {
+	c := newPrivmsgTestClient(t)
+	ch := &storage.Channel{NetworkID: c.networkID, Name: "#go"}
+	if err := c.storage.CreateChannel(ch); err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	for _, nick := range []string{"matt0x6f", "alice", "bob", "carol"} {
+		if err := c.storage.AddChannelUser(ch.ID, nick, ""); err != nil {
+			t.Fatalf("AddChannelUser: %v", err)
+		}
+	}
+	feed := func(line string, handle func(ircmsg.Message)) {
+		t.Helper()
+		msg, err := ircmsg.ParseLine(line)
+		if err != nil {
+			t.Fatalf("ParseLine(%q): %v", line, err)
+		}
+		handle(msg)
+	}
+
+	feed(":alice!a@h QUIT :hub.example.net leaf.example.net", c.handleQuit)
+	feed(":bob!b@h QUIT :hub.example.net leaf.example.net", c.handleQuit)
+	feed(":carol!c@h QUIT :Quit: lunch", c.handleQuit)
+	users, _ := c.storage.GetChannelUsers(ch.ID)
+	if len(users) != 1 {
+		t.Fatalf("roster after quits = %+v, want only our own nick", users)
+	}
+	c.flushNetsplit("hub.example.net leaf.example.net", EventNetsplit)
+
+	feed(":alice!a@h JOIN #go", c.handleJoin)
+	feed(":bob!b@h JOIN #go", c.handleJoin)
+	feed(":carol!c@h JOIN #go", c.handleJoin)
+	c.flushNetsplit("hub.example.net leaf.example.net", EventNetjoin)
+
+	msgs, err := c.storage.GetMessages(c.networkID, &ch.ID, 50)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	var got []string
+	for _, m := range msgs {
+		got = append(got, m.MessageType+" "+m.Message)
+	}
+	want := []string{
+		"quit carol quit (Quit: lunch)",
+		"netsplit Netsplit: hub.example.net ↔ leaf.example.net, 2 users: alice, bob",
+		"join carol joined the channel",
+		"netjoin Netjoin: hub.example.net ↔ leaf.example.net, 2 users: alice, bob",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("channel rows:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if users, _ := c.storage.GetChannelUsers(ch.ID); len(users) != 4 {
+		t.Fatalf("roster after rejoins = %+v, want everyone back", users)
+	}
+}
+
+// TestJoinAfterNetjoinIsReported: once a nick's netjoin has been summarized,
+// its next JOIN is an ordinary one, and a split whose users are all back is
+// forgotten.
+func TestJoinAfterNetjoinIsReported(t *testing.T) {
+	c := newPrivmsgTestClient(t)
+	goChan := &storage.Channel{NetworkID: c.networkID, Name: "#go"}
+	rustChan := &storage.Channel{NetworkID: c.networkID, Name: "#rust"}
+	for _, ch := range []*storage.Channel{goChan, rustChan} {
+		if err := c.storage.CreateChannel(ch); err != nil {
+			t.Fatalf("CreateChannel: %v", err)
+		}
+	}
+	for _, nick := range []string{"matt0x6f", "alice"} {
+		if err := c.storage.AddChannelUser(goChan.ID, nick, ""); err != nil {
+			t.Fatalf("AddChannelUser: %v", err)
+		}
+	}
+	feed := func(line string, handle func(ircmsg.Message)) {
+		t.Helper()
+		msg, err := ircmsg.ParseLine(line)
+		if err != nil {
+			t.Fatalf("ParseLine(%q): %v", line, err)
+		}
+		handle(msg)
+	}
+
+	feed(":alice!a@h QUIT :hub.example.net leaf.example.net", c.handleQuit)
+	c.flushNetsplit("hub.example.net leaf.example.net", EventNetsplit)
+	feed(":alice!a@h JOIN #go", c.handleJoin)
+	c.flushNetsplit("hub.example.net leaf.example.net", EventNetjoin)
+	feed(":alice!a@h JOIN #rust", c.handleJoin)
+
+	msgs, err := c.storage.GetMessages(c.networkID, &rustChan.ID, 10)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].MessageType != "join" {
+		t.Fatalf("#rust rows = %+v, want alice's join", msgs)
+	}
+	c.splitMu.Lock()
+	defer c.splitMu.Unlock()
+	c.expireSplitsLocked(time.Now())
+	if len(c.splits) != 0 {
+		t.Fatalf("splits still tracked: %+v", c.splits)
+	}
+}
@@ -15,6 +15,10 @@ type automaticRequest struct {
 	run  func() error
 }
 
+// automaticRequestSize is the byte cost charged to the flood budget for each
+// automatic request. They are all short commands, e.g. "WHO #chan %tcuhnfar,332".
+const automaticRequestSize = 64
+
 // outboundRequestDispatcher serializes and rate-limits protocol requests that
 // Cascade generates automatically. It is separate from callbackDispatcher so a
 // slow server-request budget never delays storage, plugins, or the frontend.
@@ -0,0 +1,433 @@
+package irc
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// SendClass orders outbound lines when the flood budget runs short: a waiting
+// line of an earlier class always goes before one of a later class, and lines
+// of one class keep their order.
+type SendClass int
+
+const (
+	SendProtocol   SendClass = iota // replies the server is waiting on, e.g. CAP REQ
+	SendUser                        // lines the user typed, and auto-join
+	SendPaste                       // the rest of a multi-line paste, so typing overtakes it
+	SendAutomation                  // requests Cascade or a script makes on its own: NAMES, WHOX, CHATHISTORY
+	numSendClasses
+)
+
+var sendClassNames = [numSendClasses]string{"protocol", "user", "paste", "automation"}
+
+func (c SendClass) String() string {
+	if c < 0 || c >= numSendClasses {
+		return fmt.Sprintf("SendClass(%d)", int(c))
+	}
+	return sendClassNames[c]
+}
+
+// FloodProfile is a network's outbound budget. Lines and bytes are two token
+// buckets, each refilling at a steady rate up to its burst; a line goes out
+// once both allow it. A zero rate leaves that bucket unlimited. Byte
+// accounting follows servers that charge long lines more than short ones; a
+// line longer than the byte burst waits for a full bucket and leaves it in
+// debt.
+type FloodProfile struct {
+	Name           string        `json:"name"`
+	BurstLines     int           `json:"burstLines"`
+	LinesPerSecond float64       `json:"linesPerSecond"`
+	BurstBytes     int           `json:"burstBytes"`
+	BytesPerSecond float64       `json:"bytesPerSecond"`
+	AutomationGap  time.Duration `json:"automationGap"` // minimum spacing between automation lines
+}
+
+// Flood profile names. The empty name stored on a network means standard.
+const (
+	FloodStandard = "standard"
+	FloodCautious = "cautious"
+	FloodRelaxed  = "relaxed"
+)
+
+var floodProfiles = map[string]FloodProfile{
+	// 4 lines per 2 seconds, the classic client budget most ircds tolerate.
+	FloodStandard: {Name: FloodStandard, BurstLines: 4, LinesPerSecond: 2, BurstBytes: 2048, BytesPerSecond: 1024, AutomationGap: time.Second},
+	// For servers that disconnect with "Excess Flood" at the standard pace.
+	FloodCautious: {Name: FloodCautious, BurstLines: 3, LinesPerSecond: 0.5, BurstBytes: 1024, BytesPerSecond: 256, AutomationGap: 3 * time.Second},
+	// No pacing at all, for a server you run yourself with fakelag disabled.
+	FloodRelaxed: {Name: FloodRelaxed},
+}
+
+// FloodProfileNamed returns the profile with the given name; "" is standard.
+func FloodProfileNamed(name string) (FloodProfile, error) {
+	if name == "" {
+		name = FloodStandard
+	}
+	profile, ok := floodProfiles[name]
+	if !ok {
+		return FloodProfile{}, fmt.Errorf("unknown flood profile %q (want standard, cautious or relaxed)", name)
+	}
+	return profile, nil
+}
+
+// OutboundQueueStats is a snapshot of a client's outbound queue.
+type OutboundQueueStats struct {
+	Profile     string
+	Queued      [numSendClasses]int    // lines waiting, by class
+	QueuedBytes [numSendClasses]int    // wire bytes waiting, by class
+	OldestWait  time.Duration          // how long the longest-waiting line has waited
+	Sent        [numSendClasses]uint64 // lines sent so far, by class
+}
+
+// Depth is the number of lines waiting in any class.
+func (s OutboundQueueStats) Depth() int {
+	n := 0
+	for _, queued := range s.Queued {
+		n += queued
+	}
+	return n
+}
+
+// sendTicket is one line waiting for its turn. ready receives true when the
+// line may be written, false when it was dropped.
+type sendTicket struct {
+	size   int
+	queued time.Time
+	ready  chan bool
+}
+
+// outboundScheduler paces every line a client writes against its network's
+// flood profile, granting waiting lines strictly by class. It replaces the
+// single fixed token bucket all sends used to share, under which a long paste
+// or a burst of automatic requests held up everything typed after it.
+//
+// Lines the ircevent library writes itself (PONG, registration, SASL) do not
+// pass through here.
+type outboundScheduler struct {
+	mu             sync.Mutex
+	profile        FloodProfile
+	lines          float64 // line tokens available
+	bytes          float64 // byte tokens available; negative after an oversized line
+	refilled       time.Time
+	lastAutomation time.Time
+	queues         [numSendClasses][]*sendTicket
+	sent           [numSendClasses]uint64
+	timer          *time.Timer
+	closed         bool
+	now            func() time.Time
+}
+
+func newOutboundScheduler(profile FloodProfile) *outboundScheduler {
+	s := &outboundScheduler{now: time.Now}
+	s.profile = profile
+	s.lines = float64(profile.BurstLines)
+	s.bytes = float64(profile.BurstBytes)
+	s.refilled = s.now()
+	return s
+}
+
+// The methods below accept a nil scheduler, which paces nothing: unit tests
+// build bare clients without one.
+
+// setProfile switches to another profile. The buckets start full, and lines
+// already waiting are re-paced at once.
+func (s *outboundScheduler) setProfile(profile FloodProfile) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profile = profile
+	s.lines = float64(profile.BurstLines)
+	s.bytes = float64(profile.BurstBytes)
+	s.refilled = s.now()
+	s.dispatch()
+}
+
+// wait blocks until a line of size wire bytes may be written in class. It
+// returns false if the line was dropped or the scheduler closed; the caller
+// must then not write it.
+func (s *outboundScheduler) wait(class SendClass, size int) bool {
+	if s == nil {
+		return true
+	}
+	t := &sendTicket{size: size, ready: make(chan bool, 1)}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return false
+	}
+	t.queued = s.now()
+	s.queues[class] = append(s.queues[class], t)
+	s.dispatch()
+	s.mu.Unlock()
+	return <-t.ready
+}
+
+// tryNow takes the budget for a line only if it can go immediately and
+// nothing else is waiting. Lines that are worthless once late, such as typing
+// notifications, use it to be skipped rather than queued.
+func (s *outboundScheduler) tryNow(size int) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	for _, queue := range s.queues {
+		if len(queue) > 0 {
+			return false
+		}
+	}
+	now := s.now()
+	s.refill(now)
+	if s.delay(SendUser, size, now) > 0 {
+		return false
+	}
+	s.spend(SendUser, size, now)
+	return true
+}
+
+// drop releases every line waiting in class without sending it and reports
+// how many there were.
+func (s *outboundScheduler) drop(class SendClass) int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dropped := s.queues[class]
+	s.queues[class] = nil
+	for _, t := range dropped {
+		t.ready <- false
+	}
+	s.dispatch()
+	return len(dropped)
+}
+
+// close drops everything waiting and refuses new lines: the connection they
+// were meant for is gone.
+func (s *outboundScheduler) close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for class := range s.queues {
+		for _, t := range s.queues[class] {
+			t.ready <- false
+		}
+		s.queues[class] = nil
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+func (s *outboundScheduler) stats() OutboundQueueStats {
+	if s == nil {
+		return OutboundQueueStats{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := OutboundQueueStats{Profile: s.profile.Name, Sent: s.sent}
+	now := s.now()
+	for class, queue := range s.queues {
+		st.Queued[class] = len(queue)
+		for _, t := range queue {
+			st.QueuedBytes[class] += t.size
+			if age := now.Sub(t.queued); age > st.OldestWait {
+				st.OldestWait = age
+			}
+		}
+	}
+	return st
+}
+
+// dispatch grants waiting lines for as long as the budget allows, then arms a
+// timer for when the next one can go. Must be called with mu held.
+func (s *outboundScheduler) dispatch() {
+	if s.closed {
+		return
+	}
+	now := s.now()
+	s.refill(now)
+	for class := range s.queues {
+		for len(s.queues[class]) > 0 {
+			t := s.queues[class][0]
+			if d := s.delay(SendClass(class), t.size, now); d > 0 {
+				s.arm(d)
+				return
+			}
+			s.queues[class][0] = nil
+			s.queues[class] = s.queues[class][1:]
+			s.spend(SendClass(class), t.size, now)
+			s.sent[class]++
+			t.ready <- true
+		}
+	}
+}
+
+func (s *outboundScheduler) arm(d time.Duration) {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(d, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.timer = nil
+		s.dispatch()
+	})
+}
+
+func (s *outboundScheduler) refill(now time.Time) {
+	elapsed := now.Sub(s.refilled).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	s.refilled = now
+	p := s.profile
+	if p.LinesPerSecond > 0 {
+		s.lines = min(float64(p.BurstLines), s.lines+elapsed*p.LinesPerSecond)
+	}
+	if p.BytesPerSecond > 0 {
+		s.bytes = min(float64(p.BurstBytes), s.bytes+elapsed*p.BytesPerSecond)
+	}
+}
+
+// delay is how long a line of size bytes in class must still wait; 0 means it
+// may go now.
+func (s *outboundScheduler) delay(class SendClass, size int, now time.Time) time.Duration {
+	p := s.profile
+	var wait float64
+	if p.LinesPerSecond > 0 && s.lines < 1 {
+		wait = (1 - s.lines) / p.LinesPerSecond
+	}
+	if p.BytesPerSecond > 0 {
+		need := float64(min(size, p.BurstBytes))
+		if s.bytes < need {
+			wait = max(wait, (need-s.bytes)/p.BytesPerSecond)
+		}
+	}
+	d := time.Duration(math.Ceil(wait * float64(time.Second)))
+	if class == SendAutomation && p.AutomationGap > 0 && !s.lastAutomation.IsZero() {
+		d = max(d, p.AutomationGap-now.Sub(s.lastAutomation))
+	}
+	if d > 0 && d < time.Millisecond {
+		d = time.Millisecond
+	}
+	return d
+}
+
+func (s *outboundScheduler) spend(class SendClass, size int, now time.Time) {
+	if s.profile.LinesPerSecond > 0 {
+		s.lines--
+	}
+	if s.profile.BytesPerSecond > 0 {
+		s.bytes -= float64(size)
+	}
+	if class == SendAutomation {
+		s.lastAutomation = now
+	}
+}
+
+// lineSize estimates the wire length of a line, CRLF included, for byte
+// accounting.
+func lineSize(tags map[string]string, command string, params ...string) int {
+	n := len(command) + 2
+	for _, param := range params {
+		n += len(param) + 2 // the space, and a ':' on the trailing parameter
+	}
+	for key, value := range tags {
+		n += len(key) + len(value) + 2
+	}
+	return n
+}
+
+// SetFloodProfile switches the connection to the named flood profile ("" is
+// standard) without reconnecting.
+func (c *IRCClient) SetFloodProfile(name string) error {
+	profile, err := FloodProfileNamed(name)
+	if err != nil {
+		return err
+	}
+	c.outbound.setProfile(profile)
+	return nil
+}
+
+// OutboundQueueStats reports what is waiting to be written. The paste class
+// counts every unsent paste line, not only the one the scheduler holds.
+func (c *IRCClient) OutboundQueueStats() OutboundQueueStats {
+	stats := c.outbound.stats()
+	c.pasteMu.Lock()
+	defer c.pasteMu.Unlock()
+	stats.Queued[SendPaste] = c.pasteBacklog
+	stats.QueuedBytes[SendPaste] = c.pasteBacklogBytes
+	return stats
+}
+
+// ClearPasteQueue abandons every paste still being sent and reports how many
+// lines were not sent.
+func (c *IRCClient) ClearPasteQueue() int {
+	c.pasteMu.Lock()
+	c.pasteEpoch++
+	unsent := c.pasteBacklog
+	c.pasteMu.Unlock()
+	c.outbound.drop(SendPaste)
+	return unsent
+}
+
+// queuePaste sends the chunks of a multi-line message in the background at
+// paste priority. Pastes go out whole and in the order they were queued, but
+// lines typed meanwhile overtake them. A failure stops the paste and is
+// reported in the status buffer.
+func (c *IRCClient) queuePaste(target string, chunks []string, replyMsgID, channelContext string) {
+	sizes := make([]int, len(chunks))
+	total := 0
+	for i, chunk := range chunks {
+		sizes[i] = lineSize(buildSendTags("", channelContext), "PRIVMSG", target, chunk)
+		total += sizes[i]
+	}
+
+	c.pasteMu.Lock()
+	prev, done, epoch := c.pasteTail, make(chan struct{}), c.pasteEpoch
+	c.pasteTail = done
+	c.pasteBacklog += len(chunks)
+	c.pasteBacklogBytes += total
+	c.pasteMu.Unlock()
+
+	go func() {
+		defer close(done)
+		if prev != nil {
+			<-prev
+		}
+		stopped := false
+		for i, chunk := range chunks {
+			c.pasteMu.Lock()
+			stopped = stopped || c.pasteEpoch != epoch
+			c.pasteMu.Unlock()
+			if !stopped {
+				chunkReply := ""
+				if i == 0 {
+					chunkReply = replyMsgID
+				}
+				if err := c.sendMessageChunk(SendPaste, target, chunk, chunkReply, channelContext); err != nil {
+					stopped = true
+					if c.IsConnectedDirect() {
+						c.writeStatusLine("error", fmt.Sprintf("Paste to %s stopped after %d of %d lines: %v", target, i, len(chunks), err))
+					}
+				}
+			}
+			c.pasteMu.Lock()
+			c.pasteBacklog--
+			c.pasteBacklogBytes -= sizes[i]
+			c.pasteMu.Unlock()
+		}
+	}()
+}
@@ -0,0 +1,151 @@
+package irc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func waitForDepth(t *testing.T, s *outboundScheduler, depth int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for s.stats().Depth() != depth {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue depth = %d, want %d", s.stats().Depth(), depth)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOutboundSchedulerGrantsByClass(t *testing.T) {
+	s := newOutboundScheduler(FloodProfile{Name: "test", BurstLines: 1, LinesPerSecond: 20})
+	t.Cleanup(s.close)
+	if !s.wait(SendUser, 10) {
+		t.Fatal("first line was refused")
+	}
+
+	// Queue one line per class, lowest priority first, while the budget is spent.
+	order := make(chan SendClass, numSendClasses)
+	for _, class := range []SendClass{SendAutomation, SendPaste, SendUser, SendProtocol} {
+		go func() {
+			if s.wait(class, 10) {
+				order <- class
+			}
+		}()
+		waitForDepth(t, s, int(numSendClasses-class))
+	}
+
+	var got []SendClass
+	for range numSendClasses {
+		select {
+		case class := <-order:
+			got = append(got, class)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %v were sent", got)
+		}
+	}
+	want := []SendClass{SendProtocol, SendUser, SendPaste, SendAutomation}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("send order = %v, want %v", got, want)
+	}
+	if sent := s.stats().Sent; sent[SendUser] != 2 || sent[SendAutomation] != 1 {
+		t.Errorf("sent counts = %v", sent)
+	}
+}
+
+func TestOutboundSchedulerChargesBytes(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := newOutboundScheduler(FloodProfile{Name: "test", BurstBytes: 100, BytesPerSecond: 100})
+	s.now = func() time.Time { return now }
+	s.refilled = now
+
+	if d := s.delay(SendUser, 60, now); d != 0 {
+		t.Fatalf("first 60 bytes wait %v, want none", d)
+	}
+	s.spend(SendUser, 60, now)
+	if d := s.delay(SendUser, 60, now); d != 200*time.Millisecond {
+		t.Errorf("second 60 bytes wait %v, want 200ms", d)
+	}
+	// A line longer than the burst waits for a full bucket, then leaves debt.
+	if d := s.delay(SendUser, 500, now); d != 600*time.Millisecond {
+		t.Errorf("500 bytes wait %v, want 600ms", d)
+	}
+	now = now.Add(600 * time.Millisecond)
+	s.refill(now)
+	s.spend(SendUser, 500, now)
+	if d := s.delay(SendUser, 10, now); d != 4100*time.Millisecond {
+		t.Errorf("after the long line, 10 bytes wait %v, want 4.1s", d)
+	}
+}
+
+func TestOutboundSchedulerAutomationGap(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := newOutboundScheduler(FloodProfile{Name: "test", AutomationGap: time.Second})
+	s.now = func() time.Time { return now }
+
+	s.spend(SendAutomation, 64, now)
+	now = now.Add(300 * time.Millisecond)
+	if d := s.delay(SendAutomation, 64, now); d != 700*time.Millisecond {
+		t.Errorf("automation wait %v, want 700ms", d)
+	}
+	if d := s.delay(SendUser, 64, now); d != 0 {
+		t.Errorf("user line wait %v, want none", d)
+	}
+}
+
+func TestOutboundSchedulerRelaxedNeverWaits(t *testing.T) {
+	profile, err := FloodProfileNamed(FloodRelaxed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newOutboundScheduler(profile)
+	start := time.Now()
+	for range 200 {
+		if !s.wait(SendAutomation, 512) {
+			t.Fatal("line refused")
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("200 relaxed lines took %v", elapsed)
+	}
+	if !s.tryNow(512) {
+		t.Error("tryNow refused on an idle relaxed scheduler")
+	}
+}
+
+func TestOutboundSchedulerDropAndClose(t *testing.T) {
+	s := newOutboundScheduler(FloodProfile{Name: "test", BurstLines: 1, LinesPerSecond: 0.01})
+	s.wait(SendUser, 10)
+	if s.tryNow(10) {
+		t.Error("tryNow succeeded with the budget spent")
+	}
+
+	results := make(chan bool, 2)
+	go func() { results <- s.wait(SendPaste, 10) }()
+	waitForDepth(t, s, 1)
+	if n := s.drop(SendPaste); n != 1 {
+		t.Errorf("drop = %d, want 1", n)
+	}
+	if <-results {
+		t.Error("dropped line was granted")
+	}
+
+	go func() { results <- s.wait(SendUser, 10) }()
+	waitForDepth(t, s, 1)
+	s.close()
+	if <-results {
+		t.Error("line granted after close")
+	}
+	if s.wait(SendProtocol, 10) {
+		t.Error("closed scheduler accepted a line")
+	}
+}
+
+func TestFloodProfileNamed(t *testing.T) {
+	if p, err := FloodProfileNamed(""); err != nil || p.Name != FloodStandard {
+		t.Errorf(`FloodProfileNamed("") = %+v, %v; want standard`, p, err)
+	}
+	if _, err := FloodProfileNamed("turbo"); err == nil {
+		t.Error("unknown profile accepted")
+	}
+}
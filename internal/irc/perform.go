@@ -0,0 +1,125 @@
+package irc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// Perform-on-connect support. The app installs a perform action that runs once
+// per connection after registration and before auto-join, so commands such as
+// a services login or a cloak request take effect before any channel is
+// joined. The action may wait for a NOTICE, e.g. a login confirmation.
+
+// SetPerformAction installs fn to run once per connection, after registration
+// and before auto-join; nil removes it. Auto-join waits for fn to return.
+func (c *IRCClient) SetPerformAction(fn func()) {
+	c.mu.Lock()
+	c.performAction = fn
+	c.mu.Unlock()
+}
+
+func (c *IRCClient) runPerform() {
+	c.mu.RLock()
+	perform := c.performAction
+	c.mu.RUnlock()
+	if perform != nil {
+		perform()
+	}
+}
+
+// NoticeWaiter is an armed expectation of a NOTICE; see ExpectNotice.
+type NoticeWaiter struct {
+	c     *IRCClient
+	match func(from, text string) bool
+	seen  chan struct{}
+}
+
+// ExpectNotice arms a waiter that fires on the first NOTICE for which match
+// reports true. Arm it before sending the command that provokes the notice so
+// a fast reply is not missed, then call Wait.
+func (c *IRCClient) ExpectNotice(match func(from, text string) bool) *NoticeWaiter {
+	w := &NoticeWaiter{c: c, match: match, seen: make(chan struct{})}
+	c.noticeWaitersMu.Lock()
+	c.noticeWaiters = append(c.noticeWaiters, w)
+	c.noticeWaitersMu.Unlock()
+	return w
+}
+
+// Wait blocks until the notice arrives or timeout passes, then disarms w.
+func (w *NoticeWaiter) Wait(timeout time.Duration) error {
+	defer w.c.disarmNotice(w)
+	select {
+	case <-w.seen:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("no matching notice within %s", timeout)
+	}
+}
+
+func (c *IRCClient) disarmNotice(w *NoticeWaiter) {
+	c.noticeWaitersMu.Lock()
+	defer c.noticeWaitersMu.Unlock()
+	for i, armed := range c.noticeWaiters {
+		if armed == w {
+			c.noticeWaiters = append(c.noticeWaiters[:i], c.noticeWaiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// notifyNoticeWaiters fires, and disarms, every waiter the notice matches.
+func (c *IRCClient) notifyNoticeWaiters(from, text string) {
+	c.noticeWaitersMu.Lock()
+	defer c.noticeWaitersMu.Unlock()
+	kept := c.noticeWaiters[:0]
+	for _, w := range c.noticeWaiters {
+		if w.match(from, text) {
+			close(w.seen)
+			continue
+		}
+		kept = append(kept, w)
+	}
+	c.noticeWaiters = kept
+}
+
+// SendSecretCommand sends a raw line that carries a secret, such as a perform
+// step with a stored password filled in. Only display, the line as written
+// with the secret unresolved, goes to the status buffer, and the echo-message
+// copy of a PRIVMSG or NOTICE is dropped instead of stored.
+func (c *IRCClient) SendSecretCommand(command, display string) error {
+	if err := validateRawCommand(command); err != nil {
+		return err
+	}
+	line, err := ircmsg.ParseLine(command)
+	if err != nil {
+		return fmt.Errorf("invalid command: %w", err)
+	}
+
+	c.mu.RLock()
+	connected := c.connected
+	c.mu.RUnlock()
+	if !connected || !c.outbound.wait(SendUser, len(command)+2) {
+		return fmt.Errorf("not connected")
+	}
+
+	if (line.Command == "PRIVMSG" || line.Command == "NOTICE") && len(line.Params) >= 2 {
+		c.noteSecretEcho(line.Params[0], line.Params[1])
+	}
+	if err := c.conn.SendRaw(command); err != nil {
+		return fmt.Errorf("failed to send raw command: %w", err)
+	}
+
+	return c.writeStatusBuffer(storage.Message{
+		NetworkID:   c.networkID,
+		ChannelID:   nil,
+		User:        c.network.Nickname,
+		Message:     display,
+		MessageType: "command",
+		Timestamp:   time.Now(),
+		RawLine:     display,
+	})
+}
@@ -0,0 +1,105 @@
+package irc
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestExpectNoticeFiresOnMatch: a waiter armed before the notice arrives sees
+// it through the real handleNotice path, and a non-matching notice leaves it
+// waiting.
+func TestExpectNoticeFiresOnMatch(t *testing.T) {
+	c := newNoticeTestClient(t)
+	w := c.ExpectNotice(func(from, text string) bool {
+		return from == "Q" && strings.Contains(text, "now logged in")
+	})
+
+	c.handleNotice(parse(t, ":Q!TheQBot@CServe.quakenet.org NOTICE matt0x6f :Unknown command"))
+	if err := w.Wait(20 * time.Millisecond); err == nil {
+		t.Fatal("waiter fired on a non-matching notice")
+	}
+
+	w = c.ExpectNotice(func(from, text string) bool {
+		return from == "Q" && strings.Contains(text, "now logged in")
+	})
+	c.handleNotice(parse(t, ":Q!TheQBot@CServe.quakenet.org NOTICE matt0x6f :You are now logged in as matt."))
+	if err := w.Wait(time.Second); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if n := len(c.noticeWaiters); n != 0 {
+		t.Errorf("%d waiters still armed", n)
+	}
+}
+
+// TestPerformRunsBeforeAutoJoin: the perform action finishes before the
+// auto-join action starts.
+func TestPerformRunsBeforeAutoJoin(t *testing.T) {
+	c := newNoticeTestClient(t)
+	var mu sync.Mutex
+	var order []string
+	done := make(chan struct{})
+	c.autoJoinOnce = &sync.Once{}
+	c.SetPerformAction(func() {
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		order = append(order, "perform")
+		mu.Unlock()
+	})
+	c.autoJoinAction = func() {
+		mu.Lock()
+		order = append(order, "join")
+		mu.Unlock()
+		close(done)
+	}
+
+	c.triggerAutoJoin()
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(order, ",") != "perform,join" {
+		t.Fatalf("order = %v, want perform then join", order)
+	}
+}
+
+// TestSendSecretCommandKeepsSecretOutOfStorage: the resolved line goes on the
+// wire, but only the unresolved form is stored, and the echo of a message
+// carrying the secret is dropped.
+func TestSendSecretCommandKeepsSecretOutOfStorage(t *testing.T) {
+	c := newAnnounceBotTestClient(t)
+	c.currentNick = "robodan"
+	c.connected = true
+	conn, sentLines := newConnectedPipe(t)
+	c.conn = conn
+
+	if err := c.SendSecretCommand("PRIVMSG Q@CServe.quakenet.org :AUTH matt hunter2", "/msg Q@CServe.quakenet.org AUTH matt ${secret:q}"); err != nil {
+		t.Fatalf("SendSecretCommand: %v", err)
+	}
+	if got := drainUntilPrefix(t, sentLines, "PRIVMSG Q@", 2*time.Second); got != "PRIVMSG Q@CServe.quakenet.org :AUTH matt hunter2" {
+		t.Fatalf("sent %q", got)
+	}
+	if err := c.SendSecretCommand("OPER matt hunter2", "OPER matt ${secret:oper}"); err != nil {
+		t.Fatalf("SendSecretCommand: %v", err)
+	}
+	drainUntilPrefix(t, sentLines, "OPER ", 2*time.Second)
+	c.handlePrivmsg(parse(t, ":robodan!u@h PRIVMSG Q@CServe.quakenet.org :AUTH matt hunter2"))
+
+	if !hasStatusLineInStorage(t, c, "command", "OPER matt ${secret:oper}") {
+		t.Error("the unresolved command was not shown in the status buffer")
+	}
+	var stored []string
+	status, _ := c.storage.GetMessages(c.networkID, nil, 50)
+	private, _ := c.storage.GetPrivateMessages(c.networkID, "Q@CServe.quakenet.org", "robodan", 50)
+	for _, m := range append(status, private...) {
+		stored = append(stored, m.Message, m.RawLine)
+	}
+	for _, text := range stored {
+		if strings.Contains(text, "hunter2") {
+			t.Errorf("the secret reached storage: %q", text)
+		}
+	}
+	if found, _ := c.storage.SearchMessages("hunter2", nil, 10); len(found) != 0 {
+		t.Errorf("the secret is searchable: %+v", found)
+	}
+}
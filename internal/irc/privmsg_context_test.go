@@ -2,6 +2,7 @@ package irc
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -271,3 +272,40 @@ func TestInboundReplyTagEmitted(t *testing.T) {
 		t.Fatal("no message.received event emitted")
 	}
 }
+
+func TestStoredMessagesCarrySenderAccount(t *testing.T) {
+	c := newPrivmsgTestClient(t)
+	for _, line := range []string{
+		"@account=foo :foo|away!u@h PRIVMSG matt0x6f :brb",
+		":foo_!u@h PRIVMSG matt0x6f :back", // no tag: the roster already knows foo_
+		"@account=* :foo!u@h PRIVMSG matt0x6f :logged out",
+	} {
+		if strings.HasPrefix(line, ":foo_") {
+			c.applyUserMeta("foo_", func(m *UserMeta) { m.Account = "foo" })
+		}
+		msg, err := ircmsg.ParseLine(line)
+		if err != nil {
+			t.Fatalf("ParseLine: %v", err)
+		}
+		c.handlePrivmsg(msg)
+	}
+
+	nicks, err := c.storage.GetAccountNicks(c.networkID, "foo")
+	if err != nil {
+		t.Fatalf("GetAccountNicks: %v", err)
+	}
+	if len(nicks) != 2 || nicks[0].Nick != "foo_" || nicks[1].Nick != "foo|away" {
+		t.Fatalf("GetAccountNicks = %+v, want foo_ and foo|away", nicks)
+	}
+
+	// Replayed history trusts only what the line itself says.
+	c.enabledCaps["extended-join"] = true
+	join, _ := ircmsg.ParseLine(":foo!u@h JOIN #chan foo :Foo")
+	if got := c.historyAccount(join); got != "foo" {
+		t.Fatalf("historyAccount(extended JOIN) = %q, want foo", got)
+	}
+	plain, _ := ircmsg.ParseLine(":foo_!u@h PRIVMSG #chan :hi")
+	if got := c.historyAccount(plain); got != "" {
+		t.Fatalf("historyAccount(untagged) = %q, want empty", got)
+	}
+}
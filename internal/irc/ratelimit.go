@@ -5,9 +5,9 @@ import (
 	"time"
 )
 
-// RateLimiter implements a token bucket rate limiter for outgoing IRC messages.
-// IRC servers typically allow ~4 messages per 2 seconds before throttling or
-// disconnecting the client.
+// RateLimiter is a fixed token bucket counted in events. The CTCP responder
+// uses it to bound how often it answers; lines written to the server are
+// paced by outboundScheduler instead.
 type RateLimiter struct {
 	mu       sync.Mutex
 	tokens   int
@@ -57,6 +57,18 @@ func (r *RateLimiter) Wait() {
 	}
 }
 
+// TryAcquire consumes a token if one is available, without waiting.
+func (r *RateLimiter) TryAcquire() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refill()
+	if r.tokens > 0 {
+		r.tokens--
+		return true
+	}
+	return false
+}
+
 // refill adds tokens based on elapsed time. Must be called with mu held.
 func (r *RateLimiter) refill() {
 	now := time.Now()
@@ -11,8 +11,8 @@ import (
 
 // recovery.go holds the connection's authentication-failure state and the
 // channel-join error feedback. It builds only on protocol-detectable signals
-// (SASL numerics, standard join-error numerics) — it issues no services
-// commands and parses no NOTICE text.
+// (SASL numerics, standard join-error numerics); asking ChanServ for a way
+// into a channel is left to services.go.
 
 // AuthFailed reports whether SASL was enabled but did not succeed on this
 // session. The app layer reads it to decide whether an auto-reconnect should be
@@ -140,6 +140,9 @@ func (c *IRCClient) handleJoinError(e ircmsg.Message) {
 	if serverText != "" {
 		msg += " (" + serverText + ")"
 	}
+	if (e.Command == "473" || e.Command == "475") && c.requestChannelAccess(e.Command, channel) {
+		msg += " Asking ChanServ for access…"
+	}
 	if err := c.writeStatusBuffer(storage.Message{
 		NetworkID:   c.networkID,
 		ChannelID:   nil,
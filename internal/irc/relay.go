@@ -0,0 +1,81 @@
+package irc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+)
+
+// Line relay for the bouncer listener. The observer sees every line the server
+// sends, straight off the read loop, plus a local echo of each message we send
+// when the server will not echo it (no echo-message), so a consumer gets the
+// same stream Cascade's own buffers are built from.
+
+// serverTimeLayout is the IRCv3 server-time tag format.
+const serverTimeLayout = "2006-01-02T15:04:05.000Z"
+
+// SetLineObserver installs fn as the line observer, replacing any previous one;
+// nil removes it. fn runs on the connection's read goroutine and must not block.
+func (c *IRCClient) SetLineObserver(fn func(ircmsg.Message)) {
+	if fn == nil {
+		c.lineObserver.Store(nil)
+		return
+	}
+	c.lineObserver.Store(&fn)
+}
+
+func (c *IRCClient) observeLine(msg ircmsg.Message) {
+	if fn := c.lineObserver.Load(); fn != nil {
+		(*fn)(msg)
+	}
+}
+
+// echoLocally hands the observer our own PRIVMSG/NOTICE when the server will
+// not echo it back, stamped like a server echo would be.
+func (c *IRCClient) echoLocally(tags map[string]string, command, target, text string) {
+	if c.lineObserver.Load() == nil || c.capEnabled("echo-message") {
+		return
+	}
+	msg := ircmsg.MakeMessage(tags, c.CurrentNick(), command, target, text)
+	msg.SetTag("time", time.Now().UTC().Format(serverTimeLayout))
+	c.observeLine(msg)
+}
+
+// CapEnabled reports whether the server granted the IRCv3 capability name.
+func (c *IRCClient) CapEnabled(name string) bool { return c.capEnabled(name) }
+
+// ISupport returns the server's RPL_ISUPPORT tokens from registration. The map
+// is shared; do not modify it.
+func (c *IRCClient) ISupport() map[string]string {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.ISupport()
+}
+
+// RelayMessage sends a line composed by another client (the bouncer's
+// downstreams) as-is, paced like lines typed here. Client-only tags
+// survive only when the server speaks message-tags; other tags are dropped.
+func (c *IRCClient) RelayMessage(msg ircmsg.Message) error {
+	c.mu.RLock()
+	connected := c.connected
+	c.mu.RUnlock()
+	if !connected {
+		return fmt.Errorf("not connected")
+	}
+	var tags map[string]string
+	if c.capEnabled("message-tags") {
+		tags = msg.ClientOnlyTags()
+	}
+	out := ircmsg.MakeMessage(tags, "", msg.Command, msg.Params...)
+	if !c.outbound.wait(SendUser, lineSize(tags, msg.Command, msg.Params...)) {
+		return fmt.Errorf("not connected")
+	}
+	c.noteListRequest(msg.Command + " " + strings.Join(msg.Params, " "))
+	if err := c.conn.SendIRCMessage(out); err != nil {
+		return fmt.Errorf("failed to relay %s: %w", msg.Command, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package irc
+
+import (
+	"strings"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// Actions recorded in the seen database (storage.SeenEntry.Action).
+const (
+	SeenJoin     = "join"
+	SeenPart     = "part"
+	SeenQuit     = "quit"
+	SeenKicked   = "kicked"    // Target is the kicker
+	SeenNickTo   = "nick-to"   // the nick was given up for Target
+	SeenNickFrom = "nick-from" // the nick was taken from Target
+	SeenChghost  = "chghost"
+	SeenAccount  = "account" // Target is the new account, empty on logout
+	SeenMessage  = "message" // Channel is empty for a private message
+	SeenNotice   = "notice"
+)
+
+// recordSeen notes nick's latest action in the network's seen database, keyed
+// by the nick folded with the server's CASEMAPPING. Our own nick is not
+// tracked. The user@host comes from the message prefix when it is nick's (a
+// renamed nick shares it; a kicker's does not) and current (a CHGHOST prefix
+// carries the old one), otherwise from the roster. The account always comes
+// from the roster.
+func (c *IRCClient) recordSeen(e ircmsg.Message, nick, action, channel, target, message string) {
+	// Server names (never valid nicks) source notices and numerics.
+	if nick == "" || strings.Contains(nick, ".") || c.storage == nil || c.isMe(nick) {
+		return
+	}
+	meta, _ := c.UserMetaFor(nick)
+	host := meta.Host
+	if nuh, err := ircmsg.ParseNUH(e.Source); err == nil && nuh.User != "" && nuh.Host != "" && action != SeenChghost &&
+		(action == SeenNickFrom || c.sameName(nuh.Name, nick)) {
+		host = nuh.User + "@" + nuh.Host
+	}
+	if err := c.storage.RecordSeen(c.networkID, c.foldKey(nick), storage.SeenEntry{
+		Nick:    nick,
+		Action:  action,
+		Channel: channel,
+		Target:  target,
+		Message: message,
+		Host:    host,
+		Account: meta.Account,
+		SeenAt:  c.getMessageTime(e).UnixMilli(),
+	}); err != nil {
+		logger.Log.Warn().Err(err).Str("nick", nick).Str("action", action).Msg("Failed to record seen entry")
+	}
+}
+
+// recordSeenMessage notes a PRIVMSG or NOTICE from nick to target. Channel
+// messages keep their text; private ones are recorded without it. CTCP
+// requests other than ACTION are not chat and are skipped.
+func (c *IRCClient) recordSeenMessage(e ircmsg.Message, nick, target, text, action string) {
+	if len(text) >= 2 && text[0] == '\x01' && text[len(text)-1] == '\x01' {
+		body, ok := strings.CutPrefix(text[1:len(text)-1], "ACTION ")
+		if !ok {
+			return
+		}
+		text = "* " + nick + " " + body
+	}
+	if !IsChannelName(target) {
+		target, text = "", ""
+	}
+	c.recordSeen(e, nick, action, target, "", text)
+}
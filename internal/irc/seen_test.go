@@ -0,0 +1,52 @@
+package irc
+
+import (
+	"testing"
+
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+func seenEntry(t *testing.T, c *IRCClient, nick string) *storage.SeenEntry {
+	t.Helper()
+	got, err := c.storage.GetSeen(c.networkID, c.foldKey(nick))
+	if err != nil {
+		t.Fatalf("GetSeen(%s): %v", nick, err)
+	}
+	return got
+}
+
+// The seen database follows a user through joins, messages, renames and kicks,
+// keyed case-insensitively, and never records us.
+func TestRecordSeenFollowsAUser(t *testing.T) {
+	c, _ := newUserMetaTestClient(t)
+
+	c.handleJoin(parse(t, ":Alice!al@home.example JOIN #go"))
+	c.handlePrivmsg(parse(t, ":Alice!al@home.example PRIVMSG #go :\x01ACTION waves\x01"))
+	if got := seenEntry(t, c, "ALICE"); got == nil || got.Action != SeenMessage || got.Channel != "#go" ||
+		got.Message != "* Alice waves" || got.Host != "al@home.example" {
+		t.Fatalf("after an action: %+v", got)
+	}
+
+	c.handlePrivmsg(parse(t, ":Alice!al@home.example PRIVMSG matt0x6f :psst"))
+	if got := seenEntry(t, c, "alice"); got.Channel != "" || got.Message != "" {
+		t.Fatalf("a private message should be recorded without its text: %+v", got)
+	}
+
+	c.handleNickMessage(parse(t, ":Alice!al@home.example NICK alice_"))
+	if got := seenEntry(t, c, "alice"); got.Action != SeenNickTo || got.Target != "alice_" {
+		t.Fatalf("old nick after a rename: %+v", got)
+	}
+	if got := seenEntry(t, c, "alice_"); got == nil || got.Action != SeenNickFrom || got.Target != "Alice" || got.Host != "al@home.example" {
+		t.Fatalf("new nick after a rename: %+v", got)
+	}
+
+	c.handleKick(parse(t, ":op!o@staff KICK #go alice_ :flooding"))
+	if got := seenEntry(t, c, "alice_"); got.Action != SeenKicked || got.Target != "op" || got.Message != "flooding" || got.Host != "al@home.example" {
+		t.Fatalf("after a kick: %+v", got)
+	}
+
+	c.handlePrivmsg(parse(t, ":matt0x6f!me@here PRIVMSG #go :hello"))
+	if got := seenEntry(t, c, "matt0x6f"); got != nil {
+		t.Fatalf("our own nick should not be recorded: %+v", got)
+	}
+}
@@ -0,0 +1,356 @@
+package irc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matt0x6f/irc-client/internal/logger"
+)
+
+// Services automation for networks where SASL is off or unavailable: identify
+// to NickServ before auto-join, take the preferred nick back with GHOST/REGAIN
+// when it's held, and ask ChanServ for a way into +i and +k channels. Services
+// packages aren't announced by the server, so the syntax is chosen from the
+// IRCd family (see detectServerFamily): solanum networks run Atheme,
+// UnrealIRCd and InspIRCd networks usually run Anope, and ergo has its own.
+
+// Services packages whose command syntax we know.
+const (
+	ServicesAtheme = "atheme"
+	ServicesAnope  = "anope"
+	ServicesErgo   = "ergo"
+)
+
+const (
+	servicesReplyTimeout = 15 * time.Second // how long to wait for a NickServ or ChanServ answer
+	nickRecoveryInterval = time.Minute      // the least time between two GHOST/REGAIN attempts
+)
+
+// servicesPackage maps an IRCd family to the services package it is normally
+// paired with. Unknown servers get Atheme, whose syntax Anope also accepts
+// for everything but nick recovery.
+func servicesPackage(family string) string {
+	switch family {
+	case "unrealircd", "inspircd":
+		return ServicesAnope
+	case "ergo":
+		return ServicesErgo
+	default:
+		return ServicesAtheme
+	}
+}
+
+// ServicesPackage returns the services package assumed for this network.
+func (c *IRCClient) ServicesPackage() string {
+	return servicesPackage(c.SoftwareFamily())
+}
+
+// SetServicesLogin installs the NickServ account and password used when SASL
+// did not log us in; an empty password turns the automation off. An empty
+// account means the preferred nick.
+func (c *IRCClient) SetServicesLogin(account, password string) {
+	c.mu.Lock()
+	c.servicesAccount = account
+	c.servicesPassword = password
+	c.mu.Unlock()
+}
+
+// servicesLogin returns the configured login, or ok=false when there is none.
+func (c *IRCClient) servicesLogin() (account, password string, ok bool) {
+	c.mu.RLock()
+	account, password = c.servicesAccount, c.servicesPassword
+	c.mu.RUnlock()
+	if password == "" {
+		return "", "", false
+	}
+	if account == "" {
+		account = c.preferredNick()
+	}
+	return account, password, true
+}
+
+// identifyCommand returns the NickServ IDENTIFY line for pkg. Every package
+// takes an account name first, except that Anope before 2.0 accepts only the
+// password, so Anope gets the short form whenever the account is our nick.
+func identifyCommand(pkg, account, password, nick string) string {
+	if pkg == ServicesAnope && strings.EqualFold(account, nick) {
+		return "IDENTIFY " + password
+	}
+	return "IDENTIFY " + account + " " + password
+}
+
+// recoverCommand returns the NickServ line that frees nick for us. Atheme's
+// REGAIN and Anope's RECOVER also switch us to it; ergo's GHOST only
+// disconnects the holder, so a NICK retry always follows.
+func recoverCommand(pkg, nick, password string) string {
+	switch pkg {
+	case ServicesAnope:
+		return "RECOVER " + nick + " " + password
+	case ServicesErgo:
+		return "GHOST " + nick
+	default:
+		return "REGAIN " + nick + " " + password
+	}
+}
+
+// channelAccessCommand returns the ChanServ line that gets us past a join
+// error, or "" when pkg has none: INVITE for +i, GETKEY for +k.
+func channelAccessCommand(pkg, numeric, channel string) string {
+	switch {
+	case numeric == "473":
+		return "INVITE " + channel
+	case numeric == "475" && pkg != ServicesErgo:
+		return "GETKEY " + channel
+	}
+	return ""
+}
+
+// statusCommand returns the ChanServ line that gives us whatever status our
+// access grants once we're in the channel.
+func statusCommand(pkg, channel string) string {
+	if pkg == ServicesErgo {
+		return "OP " + channel
+	}
+	return "UP " + channel
+}
+
+var (
+	identifiedReplies = []string{"you are now identified", "password accepted", "you are now logged in", "you're now logged in", "you are already logged in", "you are already identified"}
+	rejectedReplies   = []string{"invalid password", "incorrect password", "password incorrect", "authentication failed", "is not registered", "isn't registered", "not a registered"}
+)
+
+// plainNotice lowercases a services notice and drops its bold, underline,
+// italic, reverse and reset codes.
+func plainNotice(text string) string {
+	return strings.ToLower(strings.NewReplacer("\x02", "", "\x1d", "", "\x1f", "", "\x16", "", "\x0f", "").Replace(text))
+}
+
+func containsAny(text string, needles []string) bool {
+	for _, needle := range needles {
+		if strings.Contains(text, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseChannelKey extracts the key from a ChanServ GETKEY reply: Atheme says
+// "Channel #c key is: k", Anope "Key for channel #c is k.".
+func parseChannelKey(text string) string {
+	text = strings.NewReplacer("\x02", "", "\x1f", "", "\x0f", "").Replace(text)
+	lower := strings.ToLower(text)
+	i := strings.LastIndex(lower, " is: ")
+	n := len(" is: ")
+	if i < 0 {
+		i, n = strings.LastIndex(lower, " is "), len(" is ")
+	}
+	if i < 0 {
+		return ""
+	}
+	fields := strings.Fields(text[i+n:])
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(fields[0], ".")
+}
+
+// sendToService messages a services bot. Lines carrying a password are noted
+// so their echo-message copy is dropped instead of stored in a query buffer.
+func (c *IRCClient) sendToService(service, text string, secret bool) error {
+	if !c.outbound.wait(SendUser, lineSize(nil, "PRIVMSG", service, text)) {
+		return fmt.Errorf("not connected")
+	}
+	if secret {
+		c.noteSecretEcho(service, text)
+	}
+	return c.conn.Send("PRIVMSG", service, text)
+}
+
+// noteSecretEcho records that a line of ours to target carries a secret, so
+// its echo-message copy is dropped instead of stored.
+func (c *IRCClient) noteSecretEcho(target, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.servicesEchoes == nil {
+		c.servicesEchoes = make(map[string]int)
+	}
+	c.servicesEchoes[c.foldKey(target)+" "+text]++
+}
+
+// takeServicesEcho reports, once per line sent, whether a PRIVMSG or NOTICE
+// of ours to target is the echo of a password-carrying line.
+func (c *IRCClient) takeServicesEcho(target, text string) bool {
+	key := c.foldKey(target) + " " + text
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.servicesEchoes[key] == 0 {
+		return false
+	}
+	c.servicesEchoes[key]--
+	if c.servicesEchoes[key] == 0 {
+		delete(c.servicesEchoes, key)
+	}
+	return true
+}
+
+// identifyWithServices logs in through NickServ when a login is configured
+// and SASL hasn't already done so, waiting for the confirmation notice so
+// auto-join sees a registered nick. It runs before the perform list. Once
+// identified, it recovers the preferred nick if we were given another.
+func (c *IRCClient) identifyWithServices() {
+	account, password, ok := c.servicesLogin()
+	if !ok {
+		return
+	}
+	c.mu.RLock()
+	viaSASL := c.saslAuthenticated
+	c.mu.RUnlock()
+	if viaSASL {
+		return
+	}
+
+	pkg := c.ServicesPackage()
+	accepted := false
+	w := c.ExpectNotice(func(from, text string) bool {
+		if !c.sameName(from, "NickServ") {
+			return false
+		}
+		text = plainNotice(text)
+		accepted = containsAny(text, identifiedReplies)
+		return accepted || containsAny(text, rejectedReplies)
+	})
+	if err := c.sendToService("NickServ", identifyCommand(pkg, account, password, c.CurrentNick()), true); err != nil {
+		c.disarmNotice(w)
+		logger.Log.Warn().Err(err).Msg("Failed to send NickServ IDENTIFY")
+		return
+	}
+	if err := w.Wait(servicesReplyTimeout); err != nil {
+		c.writeStatusLine("warning", fmt.Sprintf("NickServ did not confirm the login within %s; joining channels anyway.", servicesReplyTimeout))
+		return
+	}
+	if !accepted {
+		c.writeStatusLine("warning", fmt.Sprintf("NickServ rejected the login for %q; check the services password.", account))
+		return
+	}
+	c.writeStatusLine("status", fmt.Sprintf("Identified to NickServ as %q.", account))
+	c.recoverNick()
+}
+
+// recoverNick asks NickServ to free the preferred nick and then switches to
+// it. It needs a services login, is skipped when we already hold the nick,
+// and runs at most once per nickRecoveryInterval so the library's periodic
+// reclaims, each answered by a 433, don't turn into a GHOST loop.
+func (c *IRCClient) recoverNick() {
+	_, password, ok := c.servicesLogin()
+	if !ok {
+		return
+	}
+	preferred := c.preferredNick()
+	if c.sameName(c.CurrentNick(), preferred) {
+		return
+	}
+	c.mu.Lock()
+	if time.Since(c.nickRecoveredAt) < nickRecoveryInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.nickRecoveredAt = time.Now()
+	c.mu.Unlock()
+
+	folded := plainNotice(preferred)
+	w := c.ExpectNotice(func(from, text string) bool {
+		return c.sameName(from, "NickServ") && strings.Contains(plainNotice(text), folded)
+	})
+	c.writeStatusLine("status", fmt.Sprintf("Asking NickServ to release %q…", preferred))
+	if err := c.sendToService("NickServ", recoverCommand(c.ServicesPackage(), preferred, password), true); err != nil {
+		c.disarmNotice(w)
+		logger.Log.Warn().Err(err).Msg("Failed to send NickServ nick recovery")
+		return
+	}
+	_ = w.Wait(servicesReplyTimeout)
+	if c.sameName(c.CurrentNick(), preferred) {
+		return
+	}
+	if err := c.conn.Send("NICK", preferred); err != nil {
+		logger.Log.Warn().Err(err).Str("nick", preferred).Msg("Failed to retry NICK after recovery")
+	}
+}
+
+// requestChannelAccess asks ChanServ to let us into channel after the join
+// failed with numeric (473 +i or 475 +k), then retries the join. It reports
+// whether a request went out. A channel gets one request per failure: if the
+// retried join fails too, the next error is left to stand.
+func (c *IRCClient) requestChannelAccess(numeric, channel string) bool {
+	if _, _, ok := c.servicesLogin(); !ok {
+		return false
+	}
+	pkg := c.ServicesPackage()
+	command := channelAccessCommand(pkg, numeric, channel)
+	if command == "" {
+		return false
+	}
+	folded := c.foldKey(channel)
+	c.mu.Lock()
+	if c.servicesJoins[folded] {
+		delete(c.servicesJoins, folded)
+		c.mu.Unlock()
+		return false
+	}
+	if c.servicesJoins == nil {
+		c.servicesJoins = make(map[string]bool)
+	}
+	c.servicesJoins[folded] = true
+	c.mu.Unlock()
+
+	go func() {
+		key := ""
+		lowerChannel := plainNotice(channel)
+		w := c.ExpectNotice(func(from, text string) bool {
+			if !c.sameName(from, "ChanServ") || !strings.Contains(plainNotice(text), lowerChannel) {
+				return false
+			}
+			if numeric == "475" {
+				key = parseChannelKey(text)
+			}
+			return true
+		})
+		if err := c.sendToService("ChanServ", command, false); err != nil {
+			c.disarmNotice(w)
+			logger.Log.Warn().Err(err).Str("channel", channel).Msg("Failed to ask ChanServ for access")
+			return
+		}
+		if err := w.Wait(servicesReplyTimeout); err != nil {
+			logger.Log.Debug().Str("channel", channel).Msg("No ChanServ reply; retrying the join anyway")
+		}
+		var err error
+		if key != "" {
+			// Through JoinChannelWithKey so the key is stored once it works.
+			err = c.JoinChannelWithKey(channel, key)
+		} else {
+			err = c.conn.Join(channel)
+		}
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("channel", channel).Msg("Failed to retry join after ChanServ")
+		}
+	}()
+	return true
+}
+
+// noteServicesJoin finishes a ChanServ-assisted join once our JOIN echo
+// arrives, asking ChanServ for the status our access grants.
+func (c *IRCClient) noteServicesJoin(channel string) {
+	folded := c.foldKey(channel)
+	c.mu.Lock()
+	assisted := c.servicesJoins[folded]
+	delete(c.servicesJoins, folded)
+	c.mu.Unlock()
+	if !assisted {
+		return
+	}
+	go func() {
+		if err := c.sendToService("ChanServ", statusCommand(c.ServicesPackage(), channel), false); err != nil {
+			logger.Log.Warn().Err(err).Str("channel", channel).Msg("Failed to ask ChanServ for channel status")
+		}
+	}()
+}
@@ -0,0 +1,114 @@
+package irc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServicesSyntaxPerPackage(t *testing.T) {
+	if got := servicesPackage("solanum"); got != ServicesAtheme {
+		t.Errorf("solanum -> %q, want atheme", got)
+	}
+	if got := servicesPackage("inspircd"); got != ServicesAnope {
+		t.Errorf("inspircd -> %q, want anope", got)
+	}
+	if got := servicesPackage(""); got != ServicesAtheme {
+		t.Errorf("unknown -> %q, want atheme", got)
+	}
+
+	cases := []struct{ got, want string }{
+		{identifyCommand(ServicesAtheme, "matt", "pw", "matt"), "IDENTIFY matt pw"},
+		{identifyCommand(ServicesAnope, "matt", "pw", "Matt"), "IDENTIFY pw"},
+		{identifyCommand(ServicesAnope, "matt", "pw", "matt_"), "IDENTIFY matt pw"},
+		{recoverCommand(ServicesAtheme, "matt", "pw"), "REGAIN matt pw"},
+		{recoverCommand(ServicesAnope, "matt", "pw"), "RECOVER matt pw"},
+		{recoverCommand(ServicesErgo, "matt", "pw"), "GHOST matt"},
+		{channelAccessCommand(ServicesAnope, "473", "#c"), "INVITE #c"},
+		{channelAccessCommand(ServicesAtheme, "475", "#c"), "GETKEY #c"},
+		{channelAccessCommand(ServicesErgo, "475", "#c"), ""},
+		{channelAccessCommand(ServicesAtheme, "474", "#c"), ""},
+		{statusCommand(ServicesErgo, "#c"), "OP #c"},
+		{parseChannelKey("Channel \x02#c\x02 key is: s3cret"), "s3cret"},
+		{parseChannelKey("Key for channel \x02#c\x02 is \x02s3cret\x02."), "s3cret"},
+	}
+	for i, tc := range cases {
+		if tc.got != tc.want {
+			t.Errorf("case %d = %q, want %q", i, tc.got, tc.want)
+		}
+	}
+}
+
+// TestIdentifyWaitsForNickServThenRegains: the login is sent before auto-join
+// and holds it until NickServ confirms, its echo isn't stored, and a held
+// preferred nick is then regained and retried.
+func TestIdentifyWaitsForNickServThenRegains(t *testing.T) {
+	c := newAnnounceBotTestClient(t)
+	c.currentNick = "robodan"
+	c.serverCapabilities.Software = "solanum-1.0"
+	conn, sentLines := newConnectedPipe(t)
+	c.conn = conn
+	c.SetServicesLogin("matt", "hunter2")
+
+	done := make(chan struct{})
+	go func() {
+		c.identifyWithServices()
+		close(done)
+	}()
+	if got := drainUntilPrefix(t, sentLines, "PRIVMSG NickServ", 2*time.Second); got != "PRIVMSG NickServ :IDENTIFY matt hunter2" {
+		t.Fatalf("sent %q", got)
+	}
+	c.handlePrivmsg(parse(t, ":robodan!u@h PRIVMSG NickServ :IDENTIFY matt hunter2"))
+	if msgs, _ := c.storage.GetMessages(c.networkID, nil, 20); len(msgs) != 0 {
+		t.Fatalf("the IDENTIFY echo was stored: %+v", msgs)
+	}
+	select {
+	case <-done:
+		t.Fatal("identify returned before NickServ answered")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	c.handleNotice(parse(t, ":NickServ!NickServ@services. NOTICE robodan :You are now identified for \x02matt\x02."))
+	if got := drainUntilPrefix(t, sentLines, "PRIVMSG NickServ", 2*time.Second); got != "PRIVMSG NickServ :REGAIN preferrednick hunter2" {
+		t.Fatalf("sent %q", got)
+	}
+	c.handleNotice(parse(t, ":NickServ!NickServ@services. NOTICE robodan :\x02preferrednick\x02 has been regained."))
+	if got := drainUntilPrefix(t, sentLines, "NICK ", 2*time.Second); got != "NICK preferrednick" {
+		t.Fatalf("sent %q", got)
+	}
+	<-done
+	if !hasStatusLineInStorage(t, c, "status", `Identified to NickServ as "matt".`) {
+		t.Error("no status line for the login")
+	}
+}
+
+// TestJoinErrorAsksChanServForKey: a 475 sends GETKEY, the join is retried
+// with the key ChanServ gives, and a second failure isn't asked about again.
+func TestJoinErrorAsksChanServForKey(t *testing.T) {
+	c := newAnnounceBotTestClient(t)
+	c.currentNick = "robodan"
+	c.connected = true
+	conn, sentLines := newConnectedPipe(t)
+	c.conn = conn
+	c.SetServicesLogin("", "hunter2")
+
+	c.handleJoinError(parse(t, ":irc.test.local 475 robodan #secret :Cannot join channel (+k)"))
+	if got := drainUntilPrefix(t, sentLines, "PRIVMSG ChanServ", 2*time.Second); got != "PRIVMSG ChanServ :GETKEY #secret" {
+		t.Fatalf("sent %q", got)
+	}
+	c.handleNotice(parse(t, ":ChanServ!ChanServ@services. NOTICE robodan :Channel \x02#secret\x02 key is: s3cret"))
+	if got := drainUntilPrefix(t, sentLines, "JOIN ", 2*time.Second); got != "JOIN #secret s3cret" {
+		t.Fatalf("sent %q", got)
+	}
+
+	if c.requestChannelAccess("475", "#SECRET") {
+		t.Error("a second failure asked ChanServ again")
+	}
+	if !c.requestChannelAccess("473", "#secret") {
+		t.Fatal("a fresh failure did not ask ChanServ")
+	}
+	drainUntilPrefix(t, sentLines, "PRIVMSG ChanServ", 2*time.Second)
+	c.noteServicesJoin("#secret")
+	if got := drainUntilPrefix(t, sentLines, "PRIVMSG ChanServ", 2*time.Second); got != "PRIVMSG ChanServ :UP #secret" {
+		t.Fatalf("sent %q", got)
+	}
+}
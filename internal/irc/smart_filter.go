@@ -0,0 +1,155 @@
+package irc
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/events"
+	"github.com/matt0x6f/irc-client/internal/logger"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+// SmartFilterMaxMinutes is the longest smart filter window a channel may use.
+// Speakers silent for longer are forgotten when they leave.
+const SmartFilterMaxMinutes = 24 * 60
+
+// smartFilterWindow returns ch's smart filter window, or 0 when it is off.
+func smartFilterWindow(ch *storage.Channel) time.Duration {
+	if ch == nil || ch.SmartFilterMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(ch.SmartFilterMinutes) * time.Minute
+}
+
+// smartFiltered reports whether nick's join, part or quit line in ch should be
+// stored filtered: the channel's smart filter is on and nick has not spoken
+// there within its window. Our own lines are never filtered.
+func (c *IRCClient) smartFiltered(ch *storage.Channel, nick string) bool {
+	window := smartFilterWindow(ch)
+	if window == 0 || c.isMe(nick) {
+		return false
+	}
+	c.speakersMu.Lock()
+	defer c.speakersMu.Unlock()
+	last, ok := c.speakers[c.foldKey(ch.Name)][c.foldKey(nick)]
+	return !ok || time.Since(last) > window
+}
+
+// noteFilteredJoin remembers that nick's join to channel was stored filtered,
+// so noteSpoke can un-hide it if nick starts talking.
+func (c *IRCClient) noteFilteredJoin(channel, nick string, at time.Time) {
+	c.speakersMu.Lock()
+	defer c.speakersMu.Unlock()
+	if c.filteredJoins == nil {
+		c.filteredJoins = make(map[string]map[string]time.Time)
+	}
+	ck := c.foldKey(channel)
+	if c.filteredJoins[ck] == nil {
+		c.filteredJoins[ck] = make(map[string]time.Time)
+	}
+	c.filteredJoins[ck][c.foldKey(nick)] = at
+}
+
+// noteSpoke records that nick spoke in channel. If nick's join there was
+// filtered within the channel's window, the join row is un-hidden and an
+// EventMessageUnfiltered lets the frontend show it. CTCP requests other than
+// ACTION don't count as speaking.
+func (c *IRCClient) noteSpoke(e ircmsg.Message, channel, nick, text string) {
+	if !c.isChannelName(channel) || c.isMe(nick) {
+		return
+	}
+	if len(text) >= 2 && text[0] == '\x01' && !strings.HasPrefix(text, "\x01ACTION") {
+		return
+	}
+	ck, nk := c.foldKey(channel), c.foldKey(nick)
+	spokeAt := c.getMessageTime(e)
+	c.speakersMu.Lock()
+	if c.speakers == nil {
+		c.speakers = make(map[string]map[string]time.Time)
+	}
+	if c.speakers[ck] == nil {
+		c.speakers[ck] = make(map[string]time.Time)
+	}
+	c.speakers[ck][nk] = spokeAt
+	joinedAt, pending := c.filteredJoins[ck][nk]
+	if pending {
+		delete(c.filteredJoins[ck], nk)
+	}
+	c.speakersMu.Unlock()
+	if !pending {
+		return
+	}
+
+	ch, err := c.storage.GetChannelByName(c.networkID, channel)
+	if err != nil {
+		return
+	}
+	window := smartFilterWindow(ch)
+	now := time.Now()
+	if window == 0 || now.Sub(joinedAt) > window {
+		return
+	}
+	id, ok, err := c.storage.UnfilterLatestJoin(ch.ID, nick, now.Add(-window))
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("nick", nick).Str("channel", channel).Msg("Failed to un-hide filtered join")
+		return
+	}
+	if !ok {
+		return
+	}
+	c.eventBus.Emit(events.Event{
+		Type: EventMessageUnfiltered,
+		Data: map[string]interface{}{
+			"network":     c.network.Address,
+			"networkName": c.network.Name,
+			"networkId":   c.networkID,
+			"channel":     channel,
+			"user":        nick,
+			"messageId":   id,
+		},
+		Timestamp: now,
+		Source:    events.EventSourceIRC,
+	})
+}
+
+// forgetSpeaker drops nick's pending filtered join in channel once they have
+// left it, and their last-spoke time if it is too old to matter to any window.
+func (c *IRCClient) forgetSpeaker(channel, nick string) {
+	ck, nk := c.foldKey(channel), c.foldKey(nick)
+	c.speakersMu.Lock()
+	defer c.speakersMu.Unlock()
+	delete(c.filteredJoins[ck], nk)
+	if last, ok := c.speakers[ck][nk]; ok && time.Since(last) > SmartFilterMaxMinutes*time.Minute {
+		delete(c.speakers[ck], nk)
+	}
+}
+
+// forgetChannelSpeakers drops everything the smart filter knows about channel,
+// for when we leave it.
+func (c *IRCClient) forgetChannelSpeakers(channel string) {
+	ck := c.foldKey(channel)
+	c.speakersMu.Lock()
+	defer c.speakersMu.Unlock()
+	delete(c.speakers, ck)
+	delete(c.filteredJoins, ck)
+}
+
+// renameSpeaker carries oldNick's last-spoke times and pending filtered joins
+// over to newNick, so a nick change neither resets nor escapes the filter.
+func (c *IRCClient) renameSpeaker(oldNick, newNick string) {
+	oldKey, newKey := c.foldKey(oldNick), c.foldKey(newNick)
+	if oldKey == newKey {
+		return
+	}
+	c.speakersMu.Lock()
+	defer c.speakersMu.Unlock()
+	for _, byNick := range []map[string]map[string]time.Time{c.speakers, c.filteredJoins} {
+		for _, m := range byNick {
+			if t, found := m[oldKey]; found {
+				delete(m, oldKey)
+				m[newKey] = t
+			}
+		}
+	}
+}
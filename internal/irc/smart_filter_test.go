@@ -0,0 +1,85 @@
+package irc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ergochat/irc-go/ircmsg"
+	"github.com/matt0x6f/irc-client/internal/events"
+	"github.com/matt0x6f/irc-client/internal/storage"
+)
+
+func TestSmartFilterHidesMembershipOfSilentNicks(t *testing.T) {
+	c := newPrivmsgTestClient(t)
+	ch := &storage.Channel{NetworkID: c.networkID, Name: "#go"}
+	if err := c.storage.CreateChannel(ch); err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	if err := c.storage.UpdateChannelSmartFilter(ch.ID, 5); err != nil {
+		t.Fatalf("UpdateChannelSmartFilter: %v", err)
+	}
+	for _, nick := range []string{"matt0x6f", "alice", "ghost"} {
+		if err := c.storage.AddChannelUser(ch.ID, nick, ""); err != nil {
+			t.Fatalf("AddChannelUser: %v", err)
+		}
+	}
+	unfiltered := make(chan events.Event, 1)
+	c.eventBus.Subscribe(EventMessageUnfiltered, capturingSub{got: unfiltered})
+	feed := func(line string, handle func(ircmsg.Message)) {
+		t.Helper()
+		msg, err := ircmsg.ParseLine(line)
+		if err != nil {
+			t.Fatalf("ParseLine(%q): %v", line, err)
+		}
+		handle(msg)
+	}
+
+	feed(":alice!a@h PRIVMSG #go :hello", c.handlePrivmsg)
+	feed(":alice!a@h NICK alice_", c.handleNickMessage)
+	feed(":alice_!a@h PART #go :bye", c.handlePart)
+	feed(":ghost!g@h QUIT :Ping timeout", c.handleQuit)
+	feed(":lurker!l@h JOIN #go", c.handleJoin)
+	feed(":carol!c@h JOIN #go", c.handleJoin)
+	feed(":carol!c@h PRIVMSG #go :\x01VERSION\x01", c.handlePrivmsg)
+	select {
+	case ev := <-unfiltered:
+		t.Fatalf("a CTCP request un-hid a join: %+v", ev.Data)
+	default:
+	}
+	feed(":carol!c@h PRIVMSG #go :hi all", c.handlePrivmsg)
+
+	select {
+	case ev := <-unfiltered:
+		if ev.Data["user"] != "carol" || ev.Data["channel"] != "#go" {
+			t.Fatalf("unfiltered event = %+v, want carol in #go", ev.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no message.unfiltered event after carol spoke")
+	}
+
+	msgs, err := c.storage.GetMessages(c.networkID, &ch.ID, 50)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	var got []string
+	for _, m := range msgs {
+		if m.MessageType != "privmsg" {
+			got = append(got, m.Message)
+		}
+	}
+	want := []string{
+		"alice_ left the channel (bye)",
+		"carol joined the channel",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("visible membership rows:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if err := c.storage.UpdateChannelSmartFilter(ch.ID, 0); err != nil {
+		t.Fatalf("UpdateChannelSmartFilter(0): %v", err)
+	}
+	if msgs, _ := c.storage.GetMessages(c.networkID, &ch.ID, 50); len(msgs) != 6 {
+		t.Fatalf("rows with the filter off = %d, want all 6", len(msgs))
+	}
+}
@@ -58,6 +58,10 @@ func casefold(mapping, s string) string {
 	return string(b)
 }
 
+// CaseFold folds s under mapping for callers outside the package that have no
+// live client to ask, e.g. when reading stored state for a disconnected network.
+func CaseFold(mapping, s string) string { return casefold(mapping, s) }
+
 // channelNameMatches reports whether name is a channel (vs. a nick/PM target)
 // according to the server's CHANTYPES (RPL_ISUPPORT). chantypes is the set of
 // channel-prefix characters (e.g. "#&"); empty falls back to the conventional
@@ -0,0 +1,145 @@
+package netconfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HexChat servlist.conf network flags (F=) and login methods (L=).
+const (
+	hexchatFlagUseGlobal   = 2
+	hexchatFlagTLS         = 4
+	hexchatFlagAutoConnect = 8
+
+	hexchatLoginDefault      = 0
+	hexchatLoginSASL         = 6
+	hexchatLoginServerPass   = 7
+	hexchatLoginSASLExternal = 10
+)
+
+// ParseHexChat reads HexChat's servlist.conf. Networks that use HexChat's
+// global user info (the default for its built-in list) take no nickname from
+// the file; they are imported without one and flagged in Warnings.
+func ParseHexChat(data []byte) (Config, error) {
+	cfg := Config{Secrets: Secrets{}}
+	var network *Network
+	var secrets NetworkSecrets
+	var flags, login int
+	var user, pass string
+	var commands bool
+
+	finish := func() {
+		if network == nil {
+			return
+		}
+		tls := flags&hexchatFlagTLS != 0
+		for i := range network.Servers {
+			network.Servers[i].TLS = network.Servers[i].TLS || tls
+			if network.Servers[i].Port == 0 {
+				network.Servers[i].Port = defaultPort(network.Servers[i].TLS)
+			}
+		}
+		network.AutoConnect = flags&hexchatFlagAutoConnect != 0
+		if flags&hexchatFlagUseGlobal != 0 {
+			network.Nickname, network.Username = "", ""
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s: uses HexChat's global nickname, which servlist.conf does not hold; check the nickname after importing.", network.Name))
+		}
+		switch login {
+		case hexchatLoginSASL:
+			network.SASLMechanism, network.SASLUsername = "PLAIN", user
+			secrets.SASLPassword = pass
+		case hexchatLoginSASLExternal:
+			network.SASLMechanism = "EXTERNAL"
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s: SASL EXTERNAL needs a client certificate; choose it after importing.", network.Name))
+		case hexchatLoginDefault, hexchatLoginServerPass:
+			secrets.Password = pass
+		default:
+			if pass != "" {
+				cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s: HexChat's login method %d is not supported; its password was not imported.", network.Name, login))
+			}
+		}
+		if commands {
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s: connect commands were not imported.", network.Name))
+		}
+		if len(network.Servers) > 0 {
+			cfg.Networks = append(cfg.Networks, *network)
+			cfg.Secrets.set(network.Name, secrets)
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) < 2 || line[1] != '=' {
+			continue
+		}
+		value := line[2:]
+		if line[0] == 'N' {
+			finish()
+			network = &Network{Name: value}
+			secrets = NetworkSecrets{ChannelKeys: map[string]string{}}
+			flags, login, user, pass, commands = 0, hexchatLoginDefault, "", "", false
+			continue
+		}
+		if network == nil {
+			continue // "v=" header
+		}
+		switch line[0] {
+		case 'I':
+			network.Nickname = value
+		case 'U':
+			network.Username, user = value, value
+		case 'R':
+			network.Realname = value
+		case 'P':
+			pass = value
+		case 'F':
+			flags, _ = strconv.Atoi(value)
+		case 'L':
+			login, _ = strconv.Atoi(value)
+		case 'C':
+			commands = true
+		case 'S':
+			host, portText, _ := strings.Cut(value, "/")
+			server := Server{Address: host, TLS: strings.HasPrefix(portText, "+")}
+			server.Port, _ = strconv.Atoi(strings.TrimPrefix(portText, "+"))
+			network.Servers = append(network.Servers, server)
+		case 'J':
+			hexchatChannels(network, secrets.ChannelKeys, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Config{}, fmt.Errorf("hexchat servlist.conf: %w", err)
+	}
+	finish()
+	return cfg, nil
+}
+
+// hexchatChannels reads a J= line: "#chan" or "#chan,key" in current versions,
+// "#a,#b keyA,keyB" in older ones.
+func hexchatChannels(network *Network, keys map[string]string, value string) {
+	names, keyText, _ := strings.Cut(value, " ")
+	parts := strings.Split(names, ",")
+	if keyText == "" && len(parts) == 2 && !isChannelName(parts[1]) {
+		network.Channels = append(network.Channels, Channel{Name: parts[0], AutoJoin: true})
+		keys[parts[0]] = parts[1]
+		return
+	}
+	keyList := strings.Split(keyText, ",")
+	for i, name := range parts {
+		if name == "" {
+			continue
+		}
+		network.Channels = append(network.Channels, Channel{Name: name, AutoJoin: true})
+		if i < len(keyList) && keyList[i] != "" {
+			keys[name] = keyList[i]
+		}
+	}
+}
+
+func isChannelName(s string) bool {
+	return s != "" && strings.ContainsRune("#&+!", rune(s[0]))
+}
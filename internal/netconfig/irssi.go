@@ -0,0 +1,274 @@
+package netconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseIrssi reads an irssi config file. Networks come from its chatnets, in
+// the order their servers are listed; a server without a chatnet becomes a
+// network named after its address.
+func ParseIrssi(data []byte) (Config, error) {
+	p := &irssiParser{src: []rune(string(data))}
+	root, err := p.block(true)
+	if err != nil {
+		return Config{}, fmt.Errorf("irssi config: %w", err)
+	}
+
+	cfg := Config{Secrets: Secrets{}}
+	core := root.block("settings").block("core")
+	chatnets := root.block("chatnets")
+
+	networks := map[string]*Network{}
+	secrets := map[string]*NetworkSecrets{}
+	var order []string
+	network := func(name string) *Network {
+		if n, ok := networks[name]; ok {
+			return n
+		}
+		chatnet := chatnets.block(name)
+		n := &Network{
+			Name:     name,
+			Nickname: firstNonEmpty(chatnet.str("nick"), core.str("nick")),
+			Username: firstNonEmpty(chatnet.str("username"), core.str("user_name")),
+			Realname: firstNonEmpty(chatnet.str("realname"), core.str("real_name")),
+		}
+		if mech := strings.ToUpper(chatnet.str("sasl_mechanism")); mech != "" {
+			n.SASLMechanism = mech
+			n.SASLUsername = chatnet.str("sasl_username")
+		}
+		if chatnet.str("autosendcmd") != "" {
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s: autosendcmd was not imported.", name))
+		}
+		networks[name] = n
+		secrets[name] = &NetworkSecrets{SASLPassword: chatnet.str("sasl_password"), ChannelKeys: map[string]string{}}
+		order = append(order, name)
+		return n
+	}
+
+	for _, item := range root.list("servers") {
+		server, ok := item.(irssiBlock)
+		if !ok || server.str("address") == "" {
+			continue
+		}
+		name := firstNonEmpty(server.str("chatnet"), server.str("address"))
+		n := network(name)
+		port, _ := strconv.Atoi(server.str("port"))
+		tls := irssiBool(server.str("use_tls")) || irssiBool(server.str("use_ssl"))
+		if port == 0 {
+			port = defaultPort(tls)
+		}
+		n.Servers = append(n.Servers, Server{Address: server.str("address"), Port: port, TLS: tls})
+		n.AutoConnect = n.AutoConnect || irssiBool(server.str("autoconnect"))
+		if pass := server.str("password"); pass != "" && secrets[name].Password == "" {
+			secrets[name].Password = pass
+		}
+		if cert := firstNonEmpty(server.str("tls_cert"), server.str("ssl_cert")); cert != "" && n.SASLMechanism == "EXTERNAL" {
+			n.SASLExternalCert = cert
+		}
+	}
+
+	for _, item := range root.list("channels") {
+		channel, ok := item.(irssiBlock)
+		if !ok || channel.str("name") == "" {
+			continue
+		}
+		n, ok := networks[channel.str("chatnet")]
+		if !ok {
+			continue // a network without servers cannot be connected to
+		}
+		n.Channels = append(n.Channels, Channel{Name: channel.str("name"), AutoJoin: irssiBool(channel.str("autojoin"))})
+		if key := channel.str("password"); key != "" {
+			secrets[n.Name].ChannelKeys[channel.str("name")] = key
+		}
+	}
+
+	for _, name := range order {
+		cfg.Networks = append(cfg.Networks, *networks[name])
+		cfg.Secrets.set(name, *secrets[name])
+	}
+	return cfg, nil
+}
+
+func irssiBool(v string) bool {
+	switch strings.ToLower(v) {
+	case "yes", "true", "on", "1":
+		return true
+	}
+	return false
+}
+
+// irssiBlock is a { key = value; ... } section. Values are string, irssiBlock
+// or []any.
+type irssiBlock map[string]any
+
+func (b irssiBlock) str(key string) string {
+	s, _ := b[key].(string)
+	return s
+}
+
+func (b irssiBlock) block(key string) irssiBlock {
+	v, _ := b[key].(irssiBlock)
+	return v
+}
+
+func (b irssiBlock) list(key string) []any {
+	v, _ := b[key].([]any)
+	return v
+}
+
+// irssiParser reads irssi's config syntax: blocks { k = v; }, lists ( a, b ),
+// quoted or bare strings, and # comments.
+type irssiParser struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func (p *irssiParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line+1, fmt.Sprintf(format, args...))
+}
+
+func (p *irssiParser) skip() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *irssiParser) peek() rune {
+	p.skip()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// block reads key = value pairs up to the closing brace, or to the end of
+// input for the top level.
+func (p *irssiParser) block(top bool) (irssiBlock, error) {
+	b := irssiBlock{}
+	for {
+		switch c := p.peek(); {
+		case c == 0 && top:
+			return b, nil
+		case c == 0:
+			return nil, p.errorf("missing }")
+		case c == '}' && !top:
+			p.pos++
+			return b, nil
+		case c == ';' || c == ',':
+			p.pos++
+			continue
+		}
+		key, err := p.scalar()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != '=' {
+			return nil, p.errorf("expected = after %q", key)
+		}
+		p.pos++
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		b[key] = value
+	}
+}
+
+func (p *irssiParser) value() (any, error) {
+	switch p.peek() {
+	case '{':
+		p.pos++
+		return p.block(false)
+	case '(':
+		p.pos++
+		var items []any
+		for {
+			switch p.peek() {
+			case 0:
+				return nil, p.errorf("missing )")
+			case ')':
+				p.pos++
+				return items, nil
+			case ',', ';':
+				p.pos++
+				continue
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+	return p.scalar()
+}
+
+func (p *irssiParser) scalar() (string, error) {
+	c := p.peek()
+	if c == 0 {
+		return "", p.errorf("unexpected end of file")
+	}
+	if c == '"' {
+		p.pos++
+		var sb strings.Builder
+		for p.pos < len(p.src) {
+			c := p.src[p.pos]
+			p.pos++
+			switch c {
+			case '"':
+				return sb.String(), nil
+			case '\\':
+				if p.pos < len(p.src) {
+					sb.WriteRune(p.src[p.pos])
+					p.pos++
+				}
+			case '\n':
+				p.line++
+				sb.WriteRune(c)
+			default:
+				sb.WriteRune(c)
+			}
+		}
+		return "", p.errorf("unterminated string")
+	}
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(" \t\r\n=;,{}()#\"", p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("unexpected %q", c)
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// defaultPort is the conventional IRC port.
+func defaultPort(tls bool) int {
+	if tls {
+		return 6697
+	}
+	return 6667
+}
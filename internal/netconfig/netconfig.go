@@ -0,0 +1,223 @@
+// Package netconfig moves network configuration in and out of Cascade: a
+// versioned bundle (JSON or YAML) that Cascade exports and imports, and
+// importers for the configuration files of irssi, WeeChat and HexChat.
+//
+// Everything is read into a Config, the client-neutral description of a set
+// of networks. Secrets (server and SASL passwords, channel keys) travel in
+// the bundle only when the user gives a passphrase, sealed with it; the other
+// clients' files keep them in plaintext and they are read from there.
+package netconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BundleVersion is the bundle format this package writes. Bundles from a newer
+// version are refused rather than half-read.
+const BundleVersion = 1
+
+// Format names an input or output format.
+type Format string
+
+const (
+	FormatJSON    Format = "json"
+	FormatYAML    Format = "yaml"
+	FormatIrssi   Format = "irssi"   // ~/.irssi/config
+	FormatWeeChat Format = "weechat" // ~/.config/weechat/irc.conf (or ~/.weechat/irc.conf)
+	FormatHexChat Format = "hexchat" // ~/.config/hexchat/servlist.conf
+)
+
+var (
+	ErrUnknownFormat      = errors.New("unrecognized configuration format")
+	ErrUnsupportedVersion = errors.New("bundle was written by a newer version of Cascade")
+	ErrPassphraseRequired = errors.New("bundle secrets are encrypted; a passphrase is required")
+	ErrBadPassphrase      = errors.New("wrong passphrase, or the bundle's secrets are damaged")
+)
+
+// Config is a set of networks and app settings, independent of where it came
+// from.
+type Config struct {
+	Networks []Network
+	Settings map[string]string // app settings by key; only Cascade bundles carry them
+	Secrets  Secrets
+	Warnings []string // things an importer saw but could not carry over
+}
+
+// Network is one network's configuration.
+type Network struct {
+	Name             string    `json:"name" yaml:"name"`
+	Nickname         string    `json:"nickname" yaml:"nickname"`
+	Username         string    `json:"username,omitempty" yaml:"username,omitempty"`
+	Realname         string    `json:"realname,omitempty" yaml:"realname,omitempty"`
+	SASLMechanism    string    `json:"sasl_mechanism,omitempty" yaml:"sasl_mechanism,omitempty"` // "" when SASL is off
+	SASLUsername     string    `json:"sasl_username,omitempty" yaml:"sasl_username,omitempty"`
+	SASLExternalCert string    `json:"sasl_external_cert,omitempty" yaml:"sasl_external_cert,omitempty"`
+	AutoConnect      bool      `json:"auto_connect" yaml:"auto_connect"`
+	IdentifyAsBot    bool      `json:"identify_as_bot,omitempty" yaml:"identify_as_bot,omitempty"`
+	FloodProfile     string    `json:"flood_profile,omitempty" yaml:"flood_profile,omitempty"` // "" for standard
+	Servers          []Server  `json:"servers" yaml:"servers"`
+	Channels         []Channel `json:"channels,omitempty" yaml:"channels,omitempty"`
+	Monitor          []string  `json:"monitor,omitempty" yaml:"monitor,omitempty"` // MONITOR buddy list
+	Ignore           []string  `json:"ignore,omitempty" yaml:"ignore,omitempty"`   // nicks kept out of the activity inbox
+}
+
+// Server is one address of a network, in fallback order.
+type Server struct {
+	Address string `json:"address" yaml:"address"`
+	Port    int    `json:"port" yaml:"port"`
+	TLS     bool   `json:"tls" yaml:"tls"`
+}
+
+// Channel is a channel remembered for a network.
+type Channel struct {
+	Name     string `json:"name" yaml:"name"`
+	AutoJoin bool   `json:"auto_join" yaml:"auto_join"`
+}
+
+// Secrets holds the secrets of each network, by network name.
+type Secrets map[string]NetworkSecrets
+
+// NetworkSecrets are the secrets of one network. Empty fields are unset.
+type NetworkSecrets struct {
+	Password     string            `json:"password,omitempty"`
+	SASLPassword string            `json:"sasl_password,omitempty"`
+	ChannelKeys  map[string]string `json:"channel_keys,omitempty"` // by channel name
+}
+
+func (s NetworkSecrets) empty() bool {
+	return s.Password == "" && s.SASLPassword == "" && len(s.ChannelKeys) == 0
+}
+
+// set records secrets for a network, skipping empty ones so Secrets only
+// holds networks that have some.
+func (s Secrets) set(network string, secrets NetworkSecrets) {
+	if !secrets.empty() {
+		s[network] = secrets
+	}
+}
+
+// bundle is the exported document.
+type bundle struct {
+	Version    int               `json:"version" yaml:"version"`
+	ExportedAt time.Time         `json:"exported_at" yaml:"exported_at"`
+	Networks   []Network         `json:"networks" yaml:"networks"`
+	Settings   map[string]string `json:"settings,omitempty" yaml:"settings,omitempty"`
+	Secrets    *sealed           `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+}
+
+// Export writes cfg as a bundle in JSON or YAML. Secrets are included only
+// when passphrase is set, sealed with it.
+func Export(cfg Config, format Format, passphrase string) ([]byte, error) {
+	b := bundle{
+		Version:    BundleVersion,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Networks:   cfg.Networks,
+		Settings:   cfg.Settings,
+	}
+	if b.Networks == nil {
+		b.Networks = []Network{}
+	}
+	if passphrase != "" && len(cfg.Secrets) > 0 {
+		s, err := seal(cfg.Secrets, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		b.Secrets = s
+	}
+
+	switch format {
+	case FormatJSON:
+		out, err := json.MarshalIndent(b, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	case FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(b); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("%w: cannot export as %q", ErrUnknownFormat, format)
+}
+
+// Import reads a configuration in the given format; an empty format is
+// detected from the content. For bundles with sealed secrets, an empty
+// passphrase imports everything else and notes the skipped secrets in
+// Warnings, while a wrong one fails with ErrBadPassphrase.
+func Import(data []byte, format Format, passphrase string) (Config, error) {
+	if format == "" {
+		format = Detect(data)
+	}
+	switch format {
+	case FormatJSON, FormatYAML:
+		return importBundle(data, format, passphrase)
+	case FormatIrssi:
+		return ParseIrssi(data)
+	case FormatWeeChat:
+		return ParseWeeChat(data)
+	case FormatHexChat:
+		return ParseHexChat(data)
+	}
+	return Config{}, ErrUnknownFormat
+}
+
+func importBundle(data []byte, format Format, passphrase string) (Config, error) {
+	var b bundle
+	var err error
+	if format == FormatJSON {
+		err = json.Unmarshal(data, &b)
+	} else {
+		err = yaml.Unmarshal(data, &b)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("read bundle: %w", err)
+	}
+	switch {
+	case b.Version == 0:
+		return Config{}, fmt.Errorf("read bundle: %w", ErrUnknownFormat)
+	case b.Version > BundleVersion:
+		return Config{}, fmt.Errorf("%w (format version %d)", ErrUnsupportedVersion, b.Version)
+	}
+
+	cfg := Config{Networks: b.Networks, Settings: b.Settings, Secrets: Secrets{}}
+	if b.Secrets != nil {
+		if passphrase == "" {
+			cfg.Warnings = append(cfg.Warnings, "Passwords and channel keys were skipped: the bundle's secrets need its passphrase.")
+		} else if cfg.Secrets, err = b.Secrets.open(passphrase); err != nil {
+			return Config{}, err
+		}
+	}
+	return cfg, nil
+}
+
+// Detect guesses the format of a configuration file from its content.
+func Detect(data []byte) Format {
+	text := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(text, "{"):
+		return FormatJSON
+	case strings.HasPrefix(text, "version:") || strings.Contains(text, "\nnetworks:"):
+		return FormatYAML
+	case strings.HasPrefix(text, "v=") || strings.Contains(text, "\nN="):
+		return FormatHexChat
+	case strings.Contains(text, "[server]") || strings.Contains(text, "[server_default]"):
+		return FormatWeeChat
+	case strings.Contains(text, "servers = (") || strings.Contains(text, "chatnets = {"):
+		return FormatIrssi
+	}
+	return ""
+}
@@ -0,0 +1,270 @@
+package netconfig
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sampleConfig() Config {
+	return Config{
+		Networks: []Network{{
+			Name: "Libera", Nickname: "matt", Username: "matt", Realname: "Matt",
+			SASLMechanism: "PLAIN", SASLUsername: "matt", AutoConnect: true,
+			Servers:  []Server{{Address: "irc.libera.chat", Port: 6697, TLS: true}, {Address: "irc.eu.libera.chat", Port: 6697, TLS: true}},
+			Channels: []Channel{{Name: "#ops", AutoJoin: true}, {Name: "#lounge"}},
+			Monitor:  []string{"alice"},
+			Ignore:   []string{"spambot"},
+		}},
+		Settings: map[string]string{"ctcp.responder": `{"replies":{}}`},
+		Secrets: Secrets{"Libera": {
+			SASLPassword: "hunter2",
+			ChannelKeys:  map[string]string{"#ops": "sekrit"},
+		}},
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	want := sampleConfig()
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		data, err := Export(want, format, "correct horse")
+		if err != nil {
+			t.Fatalf("Export(%s): %v", format, err)
+		}
+		if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "sekrit") {
+			t.Fatalf("%s bundle contains a plaintext secret:\n%s", format, data)
+		}
+		if got := Detect(data); got != format {
+			t.Errorf("Detect = %q, want %q", got, format)
+		}
+		got, err := Import(data, "", "correct horse")
+		if err != nil {
+			t.Fatalf("Import(%s): %v", format, err)
+		}
+		if !reflect.DeepEqual(got.Networks, want.Networks) || !reflect.DeepEqual(got.Settings, want.Settings) || !reflect.DeepEqual(got.Secrets, want.Secrets) {
+			t.Errorf("%s round trip:\n got %+v\nwant %+v", format, got, want)
+		}
+	}
+}
+
+func TestBundleSecretsNeedThePassphrase(t *testing.T) {
+	data, err := Export(sampleConfig(), FormatJSON, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(data, FormatJSON, "wrong"); !errors.Is(err, ErrBadPassphrase) {
+		t.Errorf("wrong passphrase: err = %v", err)
+	}
+	cfg, err := Import(data, FormatJSON, "")
+	if err != nil {
+		t.Fatalf("no passphrase: %v", err)
+	}
+	if len(cfg.Networks) != 1 || len(cfg.Secrets) != 0 || len(cfg.Warnings) != 1 {
+		t.Errorf("no passphrase: networks=%d secrets=%v warnings=%v", len(cfg.Networks), cfg.Secrets, cfg.Warnings)
+	}
+
+	plain, err := Export(sampleConfig(), FormatJSON, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(plain), `"secrets"`) {
+		t.Errorf("export without a passphrase carries secrets:\n%s", plain)
+	}
+}
+
+func TestBundleFromNewerVersionIsRefused(t *testing.T) {
+	_, err := Import([]byte(`{"version": 99, "networks": []}`), FormatJSON, "")
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("err = %v, want ErrUnsupportedVersion", err)
+	}
+}
+
+const irssiConfig = `
+servers = (
+  {
+    address = "irc.libera.chat";
+    chatnet = "liberachat";
+    port = "6697";
+    use_tls = "yes";
+    tls_verify = "yes";
+    autoconnect = "yes";
+  },
+  { address = "irc.oftc.net"; chatnet = "OFTC"; port = "6667"; password = "serverpass"; }
+);
+
+# chatnets carry the identity
+chatnets = {
+  liberachat = {
+    type = "IRC";
+    nick = "mattx";
+    sasl_mechanism = "plain";
+    sasl_username = "mattx";
+    sasl_password = "saslpass";
+  };
+  OFTC = { type = "IRC"; };
+};
+
+channels = (
+  { name = "#irssi"; chatnet = "liberachat"; autojoin = "yes"; },
+  { name = "#secret"; chatnet = "liberachat"; autojoin = "no"; password = "k3y"; },
+  { name = "#debian"; chatnet = "OFTC"; autojoin = "yes"; }
+);
+
+settings = {
+  core = { real_name = "Matt"; user_name = "matt"; nick = "matt"; };
+};
+`
+
+func TestParseIrssi(t *testing.T) {
+	if Detect([]byte(irssiConfig)) != FormatIrssi {
+		t.Fatalf("Detect = %q", Detect([]byte(irssiConfig)))
+	}
+	cfg, err := Import([]byte(irssiConfig), FormatIrssi, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Network{
+		{
+			Name: "liberachat", Nickname: "mattx", Username: "matt", Realname: "Matt",
+			SASLMechanism: "PLAIN", SASLUsername: "mattx", AutoConnect: true,
+			Servers:  []Server{{Address: "irc.libera.chat", Port: 6697, TLS: true}},
+			Channels: []Channel{{Name: "#irssi", AutoJoin: true}, {Name: "#secret"}},
+		},
+		{
+			Name: "OFTC", Nickname: "matt", Username: "matt", Realname: "Matt",
+			Servers:  []Server{{Address: "irc.oftc.net", Port: 6667}},
+			Channels: []Channel{{Name: "#debian", AutoJoin: true}},
+		},
+	}
+	if !reflect.DeepEqual(cfg.Networks, want) {
+		t.Errorf("networks:\n got %+v\nwant %+v", cfg.Networks, want)
+	}
+	wantSecrets := Secrets{
+		"liberachat": {SASLPassword: "saslpass", ChannelKeys: map[string]string{"#secret": "k3y"}},
+		"OFTC":       {Password: "serverpass", ChannelKeys: map[string]string{}},
+	}
+	if !reflect.DeepEqual(cfg.Secrets, wantSecrets) {
+		t.Errorf("secrets = %+v", cfg.Secrets)
+	}
+}
+
+const weechatConfig = `
+#
+# weechat -- irc.conf
+#
+
+[look]
+buffer_open_before_autojoin = on
+
+[server_default]
+autoconnect = off
+nicks = "matt,matt_"
+tls = on
+username = "matt"
+realname = ""
+
+[server]
+libera.addresses = "irc.libera.chat/6697,irc.eu.libera.chat/6697"
+libera.tls = null
+libera.autoconnect = on
+libera.autojoin = "#weechat,#private key1"
+libera.sasl_mechanism = plain
+libera.sasl_username = "matt"
+libera.sasl_password = "${sec.data.libera}"
+hackint.addresses = "irc.hackint.org/6667"
+hackint.tls = off
+hackint.nicks = "matthack"
+hackint.password = "pw"
+`
+
+func TestParseWeeChat(t *testing.T) {
+	if Detect([]byte(weechatConfig)) != FormatWeeChat {
+		t.Fatalf("Detect = %q", Detect([]byte(weechatConfig)))
+	}
+	cfg, err := Import([]byte(weechatConfig), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Network{
+		{
+			Name: "libera", Nickname: "matt", Username: "matt", AutoConnect: true,
+			SASLMechanism: "PLAIN", SASLUsername: "matt",
+			Servers:  []Server{{Address: "irc.libera.chat", Port: 6697, TLS: true}, {Address: "irc.eu.libera.chat", Port: 6697, TLS: true}},
+			Channels: []Channel{{Name: "#weechat", AutoJoin: true}, {Name: "#private", AutoJoin: true}},
+		},
+		{
+			Name: "hackint", Nickname: "matthack", Username: "matt",
+			Servers: []Server{{Address: "irc.hackint.org", Port: 6667}},
+		},
+	}
+	if !reflect.DeepEqual(cfg.Networks, want) {
+		t.Errorf("networks:\n got %+v\nwant %+v", cfg.Networks, want)
+	}
+	if got := cfg.Secrets["libera"]; got.SASLPassword != "" || got.ChannelKeys["#weechat"] != "key1" {
+		t.Errorf("libera secrets = %+v", got)
+	}
+	if cfg.Secrets["hackint"].Password != "pw" {
+		t.Errorf("hackint secrets = %+v", cfg.Secrets["hackint"])
+	}
+	if len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], "secured data") {
+		t.Errorf("warnings = %v", cfg.Warnings)
+	}
+}
+
+const hexchatConfig = `v=2.16.2
+
+N=Libera.Chat
+L=6
+E=UTF-8 (IRC)
+F=29
+D=0
+I=matt
+i=matt_
+U=mattu
+R=Matt
+P=saslpass
+S=irc.libera.chat/+6697
+S=irc.eu.libera.chat
+J=#hexchat
+J=#keyed,opensesame
+
+N=Rizon
+E=UTF-8 (IRC)
+F=19
+D=0
+S=irc.rizon.net/6667
+J=#a,#b
+`
+
+func TestParseHexChat(t *testing.T) {
+	if Detect([]byte(hexchatConfig)) != FormatHexChat {
+		t.Fatalf("Detect = %q", Detect([]byte(hexchatConfig)))
+	}
+	cfg, err := Import([]byte(hexchatConfig), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Network{
+		{
+			Name: "Libera.Chat", Nickname: "matt", Username: "mattu", Realname: "Matt",
+			SASLMechanism: "PLAIN", SASLUsername: "mattu", AutoConnect: true,
+			Servers:  []Server{{Address: "irc.libera.chat", Port: 6697, TLS: true}, {Address: "irc.eu.libera.chat", Port: 6697, TLS: true}},
+			Channels: []Channel{{Name: "#hexchat", AutoJoin: true}, {Name: "#keyed", AutoJoin: true}},
+		},
+		{
+			Name:     "Rizon",
+			Servers:  []Server{{Address: "irc.rizon.net", Port: 6667}},
+			Channels: []Channel{{Name: "#a", AutoJoin: true}, {Name: "#b", AutoJoin: true}},
+		},
+	}
+	if !reflect.DeepEqual(cfg.Networks, want) {
+		t.Errorf("networks:\n got %+v\nwant %+v", cfg.Networks, want)
+	}
+	if got := cfg.Secrets["Libera.Chat"]; got.SASLPassword != "saslpass" || got.ChannelKeys["#keyed"] != "opensesame" {
+		t.Errorf("secrets = %+v", got)
+	}
+	if len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], "Rizon") {
+		t.Errorf("warnings = %v", cfg.Warnings)
+	}
+}
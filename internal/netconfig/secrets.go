@@ -0,0 +1,104 @@
+package netconfig
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters for new bundles (the interactive-login recommendation).
+// They are stored with each bundle so they can be raised later.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32 // AES-256
+	saltLen      = 16
+	// maxScryptN bounds the work factor a bundle may ask for, so a crafted
+	// file cannot tie up the importer.
+	maxScryptN = 1 << 20
+)
+
+// sealed is Secrets encrypted with AES-256-GCM under a key derived from the
+// passphrase with scrypt. Binary fields are base64 strings so JSON and YAML
+// carry them alike.
+type sealed struct {
+	KDF   string `json:"kdf" yaml:"kdf"`
+	N     int    `json:"n" yaml:"n"`
+	R     int    `json:"r" yaml:"r"`
+	P     int    `json:"p" yaml:"p"`
+	Salt  string `json:"salt" yaml:"salt"`
+	Nonce string `json:"nonce" yaml:"nonce"`
+	Data  string `json:"data" yaml:"data"`
+}
+
+func seal(secrets Secrets, passphrase string) (*sealed, error) {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	s := &sealed{KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: base64.StdEncoding.EncodeToString(salt)}
+	aead, err := s.aead(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	s.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	s.Data = base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plain, nil))
+	return s, nil
+}
+
+func (s *sealed) open(passphrase string) (Secrets, error) {
+	if s.KDF != "scrypt" {
+		return nil, fmt.Errorf("bundle secrets use an unknown key derivation %q", s.KDF)
+	}
+	if s.N > maxScryptN || s.R*s.P > 64 {
+		return nil, fmt.Errorf("bundle secrets ask for an unreasonable key derivation cost")
+	}
+	salt, err1 := base64.StdEncoding.DecodeString(s.Salt)
+	nonce, err2 := base64.StdEncoding.DecodeString(s.Nonce)
+	data, err3 := base64.StdEncoding.DecodeString(s.Data)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, ErrBadPassphrase
+	}
+	aead, err := s.aead(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrBadPassphrase
+	}
+	plain, err := aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	var secrets Secrets
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, ErrBadPassphrase
+	}
+	return secrets, nil
+}
+
+func (s *sealed) aead(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, s.N, s.R, s.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("derive bundle key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,145 @@
+package netconfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseWeeChat reads WeeChat's irc.conf. Options a server leaves unset (null
+// or absent) fall back to [server_default]. Values kept in WeeChat's secured
+// data ("${sec.data.name}") are encrypted with WeeChat's own passphrase and are
+// left out, with a warning.
+func ParseWeeChat(data []byte) (Config, error) {
+	defaults := map[string]string{}
+	servers := map[string]map[string]string{}
+	var order []string
+
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			section = line[1 : len(line)-1]
+			continue
+		}
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("weechat irc.conf: line %d: expected option = value", n)
+		}
+		key = strings.TrimSpace(key)
+		value, set := weechatValue(strings.TrimSpace(raw))
+		if !set {
+			continue
+		}
+		switch section {
+		case "server_default":
+			defaults[key] = value
+		case "server":
+			name, option, ok := strings.Cut(key, ".")
+			if !ok {
+				continue
+			}
+			if servers[name] == nil {
+				servers[name] = map[string]string{}
+				order = append(order, name)
+			}
+			servers[name][option] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Config{}, fmt.Errorf("weechat irc.conf: %w", err)
+	}
+
+	cfg := Config{Secrets: Secrets{}}
+	for _, name := range order {
+		opts := servers[name]
+		get := func(option string) string {
+			if v, ok := opts[option]; ok {
+				return v
+			}
+			return defaults[option]
+		}
+		secret := func(option string) string {
+			v := get(option)
+			if strings.Contains(v, "${") {
+				cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s: %s is kept in WeeChat's secured data and was not imported.", name, option))
+				return ""
+			}
+			return v
+		}
+
+		tls := weechatBool(firstNonEmpty(get("tls"), get("ssl")))
+		network := Network{
+			Name:        name,
+			Nickname:    strings.TrimSpace(strings.Split(get("nicks"), ",")[0]),
+			Username:    get("username"),
+			Realname:    get("realname"),
+			AutoConnect: weechatBool(get("autoconnect")),
+		}
+		for _, addr := range strings.Split(get("addresses"), ",") {
+			if addr = strings.TrimSpace(addr); addr == "" {
+				continue
+			}
+			host, portText, _ := strings.Cut(addr, "/")
+			port, _ := strconv.Atoi(strings.TrimPrefix(portText, "+"))
+			if port == 0 {
+				port = defaultPort(tls)
+			}
+			network.Servers = append(network.Servers, Server{Address: host, Port: port, TLS: tls})
+		}
+
+		secrets := NetworkSecrets{Password: secret("password"), ChannelKeys: map[string]string{}}
+		if mech := strings.ToUpper(get("sasl_mechanism")); mech != "" && (get("sasl_username") != "" || mech == "EXTERNAL") {
+			network.SASLMechanism = mech
+			network.SASLUsername = get("sasl_username")
+			secrets.SASLPassword = secret("sasl_password")
+			if mech == "EXTERNAL" {
+				network.SASLExternalCert = firstNonEmpty(get("tls_cert"), get("ssl_cert"))
+				if strings.Contains(network.SASLExternalCert, "%h") || strings.Contains(network.SASLExternalCert, "${") {
+					cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s: check the client certificate path %s.", name, network.SASLExternalCert))
+				}
+			}
+		}
+
+		channels, keys, _ := strings.Cut(get("autojoin"), " ")
+		keyList := strings.Split(keys, ",")
+		for i, channel := range strings.Split(channels, ",") {
+			if channel = strings.TrimSpace(channel); channel == "" {
+				continue
+			}
+			network.Channels = append(network.Channels, Channel{Name: channel, AutoJoin: true})
+			if i < len(keyList) && strings.TrimSpace(keyList[i]) != "" {
+				secrets.ChannelKeys[channel] = strings.TrimSpace(keyList[i])
+			}
+		}
+		if get("command") != "" {
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s: the connect command was not imported.", name))
+		}
+
+		cfg.Networks = append(cfg.Networks, network)
+		cfg.Secrets.set(name, secrets)
+	}
+	return cfg, nil
+}
+
+// weechatValue unquotes an option value. null (inherit the default) reports
+// set=false.
+func weechatValue(raw string) (value string, set bool) {
+	if raw == "null" {
+		return "", false
+	}
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(raw[1 : len(raw)-1]), true
+	}
+	return raw, true
+}
+
+func weechatBool(v string) bool {
+	return v == "on" || v == "true"
+}
@@ -41,12 +41,13 @@ type Sender func(networkID int64, target, message string) error
 
 // Host bundles the capabilities the Manager needs from the application.
 type Host struct {
-	Send           Sender                          // App.SendMessage
+	Send           Sender                          // App.sendScriptMessage
 	SelfNick       func(networkID int64) string    // App.GetCurrentNick wrapper
 	ResolveNetwork func(name string) (int64, bool) // name → networkID (via App.GetNetworks)
 	Connected      func(networkID int64) bool
 	IsMe           func(networkID int64, nick string) bool
 	UserStatus     func(networkID int64, nick string) cascade.UserStatus
+	Seen           func(networkID int64, nick string) cascade.Seen
 	Notice         func(networkID int64, target, message string) error
 	Action         func(networkID int64, target, message string) error
 	Join           func(networkID int64, channel, key string) error
@@ -147,6 +148,13 @@ func (m *Manager) makeClient(id extension.ID) *cascade.Client {
 		}
 		return m.host.UserStatus(netID, candidate)
 	}
+	seen := func(networkName, candidate string) cascade.Seen {
+		netID, ok := resolve(networkName)
+		if !ok || m.host.Seen == nil {
+			return cascade.Seen{}
+		}
+		return m.host.Seen(netID, candidate)
+	}
 	withTargetAction := func(name string, fn func(int64, string, string) error) func(string, string, string) {
 		return func(networkName, target, value string) {
 			netID, ok := resolve(networkName)
@@ -184,6 +192,7 @@ func (m *Manager) makeClient(id extension.ID) *cascade.Client {
 			withNetworkAction("nick", m.host.ChangeNick),
 			withNetworkAction("away", m.host.SetAway),
 		),
+		cascade.WithSeenQuery(seen),
 	)
 }
 
@@ -24,6 +24,7 @@ func init() {
 		"NewUserStatusEvent":       reflect.ValueOf(cascade.NewUserStatusEvent),
 		"WithIRCActions":           reflect.ValueOf(cascade.WithIRCActions),
 		"WithNetworkQueries":       reflect.ValueOf(cascade.WithNetworkQueries),
+		"WithSeenQuery":            reflect.ValueOf(cascade.WithSeenQuery),
 
 		// type definitions
 		"Client":          reflect.ValueOf((*cascade.Client)(nil)),
@@ -35,6 +36,7 @@ func init() {
 		"NoticeEvent":     reflect.ValueOf((*cascade.NoticeEvent)(nil)),
 		"PartEvent":       reflect.ValueOf((*cascade.PartEvent)(nil)),
 		"QuitEvent":       reflect.ValueOf((*cascade.QuitEvent)(nil)),
+		"Seen":            reflect.ValueOf((*cascade.Seen)(nil)),
 		"TextEvent":       reflect.ValueOf((*cascade.TextEvent)(nil)),
 		"Time":            reflect.ValueOf((*cascade.Time)(nil)),
 		"User":            reflect.ValueOf((*cascade.User)(nil)),
@@ -8,6 +8,11 @@ const (
 	FieldPassword         = "password"
 	FieldSASLPassword     = "sasl_password"
 	FieldSASLExternalCert = "sasl_external_cert"
+	FieldServicesAccount  = "services_account"  // NickServ account for networks without SASL
+	FieldServicesPassword = "services_password" // NickServ password for networks without SASL
+	FieldE2EIdentity      = "e2e_identity"      // Long-term identity key for end-to-end encrypted PMs
+	FieldE2ETrusted       = "e2e_trusted"       // JSON nick -> fingerprint the user verified
+	FieldFishKeys         = "fish_keys"         // JSON channel or nick -> FiSH CBC key
 )
 
 // SecretBackend is the minimal storage surface CredentialStore needs. Get
@@ -88,15 +93,90 @@ func (cs *CredentialStore) Migrate(networkID int64, field, columnValue string) (
 	return true, nil
 }
 
-// Delete removes all secrets for a network (used when the network is deleted).
-func (cs *CredentialStore) Delete(networkID int64) error {
+// Delete removes all secrets for a network (used when the network is deleted):
+// the standard fields plus any extra fields the caller stored.
+func (cs *CredentialStore) Delete(networkID int64, extraFields ...string) error {
 	if cs == nil {
 		return nil
 	}
-	for _, field := range []string{FieldPassword, FieldSASLPassword, FieldSASLExternalCert} {
+	for _, field := range append([]string{FieldPassword, FieldSASLPassword, FieldSASLExternalCert, FieldServicesAccount, FieldServicesPassword, FieldE2EIdentity, FieldE2ETrusted, FieldFishKeys}, extraFields...) {
 		if err := cs.backend.Delete(credKey(networkID, field)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// appSecretKey namespaces an app-wide secret (one not tied to a network).
+func appSecretKey(name string) string {
+	return "app-" + name
+}
+
+// StoreAppSecret persists an app-wide secret such as a remote core's access
+// token. An empty value deletes it.
+func (cs *CredentialStore) StoreAppSecret(name, value string) error {
+	if cs == nil {
+		return fmt.Errorf("no credential store configured")
+	}
+	if value == "" {
+		return cs.backend.Delete(appSecretKey(name))
+	}
+	return cs.backend.Set(appSecretKey(name), value)
+}
+
+// AppSecret returns an app-wide secret, or "" when it is unset or unreadable.
+func (cs *CredentialStore) AppSecret(name string) string {
+	if cs == nil {
+		return ""
+	}
+	v, err := cs.backend.Get(appSecretKey(name))
+	if err != nil {
+		return ""
+	}
+	return v
+}
+
+// entrySet is implemented by backends that can list and replace all of their
+// entries, as FileBackend does. The OS keychain cannot enumerate its entries.
+type entrySet interface {
+	Entries() (map[string]string, error)
+	ReplaceEntries(map[string]string) error
+}
+
+// Entries returns every stored secret, for a backup.
+func (cs *CredentialStore) Entries() (map[string]string, error) {
+	if cs == nil {
+		return map[string]string{}, nil
+	}
+	set, ok := cs.backend.(entrySet)
+	if !ok {
+		return nil, fmt.Errorf("credential backend cannot list its secrets")
+	}
+	return set.Entries()
+}
+
+// ReplaceEntries replaces every stored secret with entries, as a restore does.
+func (cs *CredentialStore) ReplaceEntries(entries map[string]string) error {
+	if cs == nil {
+		return fmt.Errorf("no credential store configured")
+	}
+	set, ok := cs.backend.(entrySet)
+	if !ok {
+		return fmt.Errorf("credential backend cannot replace its secrets")
+	}
+	return set.ReplaceEntries(entries)
+}
+
+// FileAt returns a FileBackend for the file at path, encrypted under the same
+// key as this store, for secrets that have to wait on disk outside it, such as
+// those of a staged backup restore.
+func (cs *CredentialStore) FileAt(path string) (*FileBackend, error) {
+	if cs == nil {
+		return nil, fmt.Errorf("no credential store configured")
+	}
+	f, ok := cs.backend.(*FileBackend)
+	if !ok {
+		return nil, fmt.Errorf("credential backend cannot encrypt a separate file")
+	}
+	return NewFileBackendWithKey(path, f.key), nil
+}
@@ -94,3 +94,22 @@ func TestCredentialStoreMigrate(t *testing.T) {
 		t.Errorf("empty column: moved=%v err=%v, want false/nil", moved, err)
 	}
 }
+
+func TestCredentialStoreAppSecrets(t *testing.T) {
+	backend := newFakeBackend()
+	cs := NewCredentialStore(backend)
+
+	if err := cs.StoreAppSecret("core-token", "abc"); err != nil {
+		t.Fatalf("StoreAppSecret: %v", err)
+	}
+	if got := cs.AppSecret("core-token"); got != "abc" {
+		t.Errorf("AppSecret = %q, want %q", got, "abc")
+	}
+	// App secrets never collide with per-network ones.
+	if err := cs.Delete(0); err != nil || cs.AppSecret("core-token") != "abc" {
+		t.Errorf("network Delete removed an app secret (err=%v)", err)
+	}
+	if err := cs.StoreAppSecret("core-token", ""); err != nil || len(backend.m) != 0 {
+		t.Errorf("empty StoreAppSecret left %v (err=%v)", backend.m, err)
+	}
+}
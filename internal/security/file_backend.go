@@ -75,6 +75,26 @@ func (f *FileBackend) Delete(key string) error {
 	return f.save(m)
 }
 
+// Entries returns a copy of every stored secret.
+func (f *FileBackend) Entries() (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.load()
+}
+
+// ReplaceEntries replaces the whole store with entries, encrypted under this
+// backend's key. A restore uses it to re-key secrets that were encrypted on
+// another machine.
+func (f *FileBackend) ReplaceEntries(entries map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m := make(map[string]string, len(entries))
+	for k, v := range entries {
+		m[k] = v
+	}
+	return f.save(m)
+}
+
 // load reads and decrypts the secrets map. A missing file is an empty map.
 func (f *FileBackend) load() (map[string]string, error) {
 	raw, err := os.ReadFile(f.path)
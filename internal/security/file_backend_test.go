@@ -119,6 +119,37 @@ func TestFileBackendWrongKeyFailsToDecrypt(t *testing.T) {
 	}
 }
 
+// Secrets moved through Entries/ReplaceEntries end up encrypted under the
+// receiving backend's key, which is how a restore re-keys them.
+func TestFileBackendReplaceEntriesRekeys(t *testing.T) {
+	src := NewFileBackendWithKey(filepath.Join(t.TempDir(), "credentials.enc"), testKey)
+	if err := src.Set("network-1-password", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := src.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	dst := NewFileBackendWithKey(path, bytes.Repeat([]byte{0x99}, 32))
+	if err := dst.Set("stale", "gone"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.ReplaceEntries(entries); err != nil {
+		t.Fatalf("ReplaceEntries: %v", err)
+	}
+	if v, _ := dst.Get("network-1-password"); v != "hunter2" {
+		t.Errorf("re-keyed secret = %q", v)
+	}
+	if v, _ := dst.Get("stale"); v != "" {
+		t.Errorf("ReplaceEntries kept %q", v)
+	}
+	if _, err := NewFileBackendWithKey(path, testKey).Get("network-1-password"); err == nil {
+		t.Error("re-keyed file still opens with the source key")
+	}
+}
+
 // The production constructor derives its key from a persisted master key, so a
 // secret written by one instance must be readable by a freshly constructed
 // instance over the same dataDir. If the derived key weren't stable, every
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/matt0x6f/irc-client/internal/storage/generated"
+)
+
+// StoreChannelDirectory merges one LIST fetch into the network's directory and
+// reports how many channels it removed. Listed channels are added or updated.
+// A full fetch (empty filter) also drops every channel it did not list; a
+// filtered one leaves them alone, since the server only sent a subset.
+func (s *Storage) StoreChannelDirectory(networkID int64, filter string, fetchedAt time.Time, channels []ChannelDirectoryEntry) (int64, error) {
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin channel directory update: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries := s.queries.WithTx(tx)
+
+	at := fetchedAt.UnixMilli()
+	for _, ch := range channels {
+		if err := queries.UpsertChannelDirectoryEntry(ctx, db.UpsertChannelDirectoryEntryParams{
+			NetworkID:  networkID,
+			ChannelKey: strings.ToLower(ch.Channel),
+			Channel:    ch.Channel,
+			Users:      ch.Users,
+			Topic:      ch.Topic,
+			Modes:      ch.Modes,
+			SeenAt:     at,
+		}); err != nil {
+			return 0, fmt.Errorf("store channel %s: %w", ch.Channel, err)
+		}
+	}
+	var removed int64
+	if filter == "" {
+		if removed, err = queries.DeleteChannelDirectoryUnseenSince(ctx, db.DeleteChannelDirectoryUnseenSinceParams{
+			NetworkID: networkID,
+			LastSeen:  at,
+		}); err != nil {
+			return 0, fmt.Errorf("prune channel directory: %w", err)
+		}
+	}
+	if err := queries.RecordChannelDirectoryFetch(ctx, db.RecordChannelDirectoryFetchParams{
+		NetworkID: networkID,
+		FetchedAt: at,
+		Filter:    filter,
+	}); err != nil {
+		return 0, fmt.Errorf("record channel directory fetch: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit channel directory update: %w", err)
+	}
+	return removed, nil
+}
+
+// GetChannelDirectoryFetch returns the network's latest LIST fetch, or nil if
+// the directory has never been fetched.
+func (s *Storage) GetChannelDirectoryFetch(networkID int64) (*ChannelDirectoryFetch, error) {
+	row, err := s.queries.GetChannelDirectoryFetch(context.Background(), networkID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get channel directory fetch: %w", err)
+	}
+	return &ChannelDirectoryFetch{
+		FetchedAt:         row.FetchedAt,
+		PreviousFetchedAt: row.PreviousFetchedAt,
+		FullFetchedAt:     row.FullFetchedAt,
+		Filter:            row.Filter,
+	}, nil
+}
+
+// SearchChannelDirectory returns the network's directory entries matching q,
+// busiest first.
+func (s *Storage) SearchChannelDirectory(networkID int64, q ChannelDirectoryQuery) ([]ChannelDirectoryEntry, error) {
+	var (
+		where = []string{"d.network_id = ?"}
+		args  = []any{networkID}
+		from  = "channel_directory d"
+	)
+	if match := ftsPrefixQuery(q.Text); match != "" {
+		from += " JOIN channel_directory_fts ON channel_directory_fts.rowid = d.id"
+		where = append(where, "channel_directory_fts MATCH ?")
+		args = append(args, match)
+	}
+	if q.MinUsers > 0 {
+		where = append(where, "d.users > ?")
+		args = append(args, q.MinUsers)
+	}
+	if q.MaxUsers > 0 {
+		where = append(where, "d.users < ?")
+		args = append(args, q.MaxUsers)
+	}
+	if len(q.Masks) > 0 {
+		alts := make([]string, len(q.Masks))
+		for i, mask := range q.Masks {
+			alts[i] = "d.channel_key GLOB ?"
+			args = append(args, maskToGlob(mask))
+		}
+		where = append(where, "("+strings.Join(alts, " OR ")+")")
+	}
+	for _, mask := range q.ExcludeMasks {
+		where = append(where, "d.channel_key NOT GLOB ?")
+		args = append(args, maskToGlob(mask))
+	}
+	const isNew = "(f.previous_fetched_at > 0 AND d.first_seen >= f.fetched_at)"
+	if q.NewOnly {
+		where = append(where, isNew)
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append(args, limit)
+
+	var entries []ChannelDirectoryEntry
+	err := s.db.Select(&entries, `
+		SELECT d.network_id, d.channel, d.users, d.topic, d.modes, d.first_seen, d.last_seen,
+			COALESCE(`+isNew+`, 0) AS is_new
+		FROM `+from+`
+		LEFT JOIN channel_directory_fetches f ON f.network_id = d.network_id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY d.users DESC, d.channel_key
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search channel directory: %w", err)
+	}
+	if entries == nil {
+		entries = []ChannelDirectoryEntry{}
+	}
+	return entries, nil
+}
+
+// ftsPrefixQuery quotes each word for FTS5 MATCH and makes it a prefix match,
+// so "linu" finds #linux. Quotes in the words are dropped.
+func ftsPrefixQuery(words []string) string {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if clean := strings.ReplaceAll(word, "\"", ""); clean != "" {
+			quoted = append(quoted, "\""+clean+"\"*")
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// maskToGlob turns an IRC wildcard mask into a lower-case SQLite GLOB pattern.
+// IRC masks only know * and ?, so a literal [ is escaped.
+func maskToGlob(mask string) string {
+	return strings.ReplaceAll(strings.ToLower(mask), "[", "[[]")
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func channelNames(entries []ChannelDirectoryEntry) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Channel
+	}
+	return names
+}
+
+func TestChannelDirectoryMergesFetches(t *testing.T) {
+	s := newTestStorage(t)
+	net := makeNetwork("DirNet")
+	if err := s.CreateNetwork(net); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	if fetch, err := s.GetChannelDirectoryFetch(net.ID); err != nil || fetch != nil {
+		t.Fatalf("fetch before any LIST = %+v, %v; want nil", fetch, err)
+	}
+
+	first := time.UnixMilli(1_000_000)
+	if _, err := s.StoreChannelDirectory(net.ID, "", first, []ChannelDirectoryEntry{
+		{Channel: "#linux", Users: 900, Topic: "Linux support"},
+		{Channel: "#go-nuts", Users: 300, Topic: "The Go programming language"},
+		{Channel: "#old", Users: 2},
+	}); err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	all, err := s.SearchChannelDirectory(net.ID, ChannelDirectoryQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Channel != "#linux" || all[0].New {
+		t.Fatalf("after the first fetch = %+v; want three, none new", all)
+	}
+
+	// A filtered fetch updates what it lists and keeps the rest.
+	second := first.Add(time.Minute)
+	if removed, err := s.StoreChannelDirectory(net.ID, ">100", second, []ChannelDirectoryEntry{
+		{Channel: "#Linux", Users: 950, Topic: "Linux support | rules in /topic"},
+	}); err != nil || removed != 0 {
+		t.Fatalf("filtered fetch = %d, %v", removed, err)
+	}
+	if got, _ := s.SearchChannelDirectory(net.ID, ChannelDirectoryQuery{}); len(got) != 3 || got[0].Users != 950 || got[0].Channel != "#Linux" {
+		t.Fatalf("after the filtered fetch = %+v", got)
+	}
+
+	// A full fetch drops channels it did not list and marks first sightings new.
+	third := second.Add(time.Minute)
+	removed, err := s.StoreChannelDirectory(net.ID, "", third, []ChannelDirectoryEntry{
+		{Channel: "#linux", Users: 940, Topic: "Linux support"},
+		{Channel: "#go-nuts", Users: 310, Topic: "The Go programming language"},
+		{Channel: "#rust", Users: 400, Topic: "Rust language"},
+	})
+	if err != nil || removed != 1 {
+		t.Fatalf("full fetch removed %d, %v; want 1", removed, err)
+	}
+	fetch, err := s.GetChannelDirectoryFetch(net.ID)
+	if err != nil || fetch.FetchedAt != third.UnixMilli() || fetch.PreviousFetchedAt != second.UnixMilli() || fetch.FullFetchedAt != third.UnixMilli() {
+		t.Fatalf("fetch = %+v, %v", fetch, err)
+	}
+	newOnly, err := s.SearchChannelDirectory(net.ID, ChannelDirectoryQuery{NewOnly: true})
+	if err != nil || len(newOnly) != 1 || newOnly[0].Channel != "#rust" || !newOnly[0].New {
+		t.Fatalf("new channels = %+v, %v; want #rust", newOnly, err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		q    ChannelDirectoryQuery
+		want []string
+	}{
+		{"topic word prefix", ChannelDirectoryQuery{Text: []string{"lang"}}, []string{"#rust", "#go-nuts"}},
+		{"name and topic words", ChannelDirectoryQuery{Text: []string{"go", "programming"}}, []string{"#go-nuts"}},
+		{"user bounds", ChannelDirectoryQuery{MinUsers: 300, MaxUsers: 900}, []string{"#rust", "#go-nuts"}},
+		{"masks", ChannelDirectoryQuery{Masks: []string{"#L*", "#r?st"}}, []string{"#linux", "#rust"}},
+		{"excluded mask", ChannelDirectoryQuery{ExcludeMasks: []string{"*n*"}}, []string{"#rust"}},
+		{"limit", ChannelDirectoryQuery{Limit: 1}, []string{"#linux"}},
+	} {
+		got, err := s.SearchChannelDirectory(net.ID, tc.q)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if names := channelNames(got); !reflect.DeepEqual(names, tc.want) {
+			t.Errorf("%s = %v, want %v", tc.name, names, tc.want)
+		}
+	}
+}
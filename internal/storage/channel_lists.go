@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	db "github.com/matt0x6f/irc-client/internal/storage/generated"
+)
+
+// UpsertChannelListEntry records a list entry. An empty SetBy or nil SetAt or
+// ExpiresAt keeps the stored value, so a refresh from the server never drops a
+// client-side expiry; use SetChannelListEntryExpiry to clear one.
+func (s *Storage) UpsertChannelListEntry(entry ChannelListEntry) error {
+	if entry.ChannelKey == "" || entry.Mode == "" || entry.Mask == "" {
+		return fmt.Errorf("channel list entry requires a channel, mode and mask")
+	}
+	if err := s.queries.UpsertChannelListEntry(context.Background(), convertChannelListEntryToUpsertParams(entry)); err != nil {
+		return fmt.Errorf("upsert %s list entry %q: %w", entry.Mode, entry.Mask, err)
+	}
+	return nil
+}
+
+// ReplaceChannelListEntries makes the stored mode list for a channel match a
+// fresh server listing. Entries still present keep their expiry; entries the
+// server no longer reports are dropped.
+func (s *Storage) ReplaceChannelListEntries(networkID int64, channel, channelKey, mode string, entries []ChannelListEntry) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("begin %s list refresh: %w", mode, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	queries := s.queries.WithTx(tx)
+	existing, err := queries.ListChannelListEntries(context.Background(), db.ListChannelListEntriesParams{
+		NetworkID: networkID, ChannelKey: channelKey, Mode: mode,
+	})
+	if err != nil {
+		return fmt.Errorf("list %s entries: %w", mode, err)
+	}
+	fresh := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		entry.NetworkID, entry.Channel, entry.ChannelKey, entry.Mode = networkID, channel, channelKey, mode
+		fresh[entry.Mask] = true
+		if err := queries.UpsertChannelListEntry(context.Background(), convertChannelListEntryToUpsertParams(entry)); err != nil {
+			return fmt.Errorf("upsert %s list entry %q: %w", mode, entry.Mask, err)
+		}
+	}
+	for _, row := range existing {
+		if fresh[row.Mask] {
+			continue
+		}
+		if err := queries.DeleteChannelListEntry(context.Background(), db.DeleteChannelListEntryParams{
+			NetworkID: networkID, ChannelKey: channelKey, Mode: mode, Mask: row.Mask,
+		}); err != nil {
+			return fmt.Errorf("delete stale %s list entry %q: %w", mode, row.Mask, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit %s list refresh: %w", mode, err)
+	}
+	return nil
+}
+
+// SetChannelListEntryExpiry schedules (or, with a nil expiresAt, cancels) the
+// client-side removal of a list entry.
+func (s *Storage) SetChannelListEntryExpiry(networkID int64, channelKey, mode, mask string, expiresAt *time.Time) error {
+	if err := s.queries.SetChannelListEntryExpiry(context.Background(), db.SetChannelListEntryExpiryParams{
+		ExpiresAt: convertToNullTime(expiresAt),
+		NetworkID: networkID, ChannelKey: channelKey, Mode: mode, Mask: mask,
+	}); err != nil {
+		return fmt.Errorf("set expiry for %s list entry %q: %w", mode, mask, err)
+	}
+	return nil
+}
+
+func (s *Storage) DeleteChannelListEntry(networkID int64, channelKey, mode, mask string) error {
+	if err := s.queries.DeleteChannelListEntry(context.Background(), db.DeleteChannelListEntryParams{
+		NetworkID: networkID, ChannelKey: channelKey, Mode: mode, Mask: mask,
+	}); err != nil {
+		return fmt.Errorf("delete %s list entry %q: %w", mode, mask, err)
+	}
+	return nil
+}
+
+// ListChannelListEntries returns a channel's stored list entries. mode is
+// optional; empty returns every list, grouped by mode and oldest first.
+func (s *Storage) ListChannelListEntries(networkID int64, channelKey, mode string) ([]ChannelListEntry, error) {
+	rows, err := s.queries.ListChannelListEntries(context.Background(), db.ListChannelListEntriesParams{
+		NetworkID: networkID, ChannelKey: channelKey, Mode: mode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list channel list entries: %w", err)
+	}
+	return convertChannelListEntriesFromDB(rows), nil
+}
+
+// ListExpiredChannelListEntries returns every entry whose expiry is at or
+// before now, across all networks.
+func (s *Storage) ListExpiredChannelListEntries(now time.Time) ([]ChannelListEntry, error) {
+	rows, err := s.queries.ListExpiredChannelListEntries(context.Background(), sql.NullTime{Time: now.UTC(), Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("list expired channel list entries: %w", err)
+	}
+	return convertChannelListEntriesFromDB(rows), nil
+}
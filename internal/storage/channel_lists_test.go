@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+// TestChannelListRefreshKeepsExpiry: a fresh server listing replaces the stored
+// list, but an entry still present keeps its client-side expiry and an entry
+// the server dropped is removed.
+func TestChannelListRefreshKeepsExpiry(t *testing.T) {
+	s := newTestStorage(t)
+	net := makeNetwork("ListNet")
+	if err := s.CreateNetwork(net); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+
+	expires := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	for _, mask := range []string{"*!*@spam.example", "*!*@old.example"} {
+		if err := s.UpsertChannelListEntry(ChannelListEntry{
+			NetworkID: net.ID, Channel: "#Ops", ChannelKey: "#ops", Mode: "b", Mask: mask, SetBy: "me", ExpiresAt: &expires,
+		}); err != nil {
+			t.Fatalf("UpsertChannelListEntry: %v", err)
+		}
+	}
+
+	setAt := time.Unix(1700000000, 0).UTC()
+	if err := s.ReplaceChannelListEntries(net.ID, "#ops", "#ops", "b", []ChannelListEntry{
+		{Mask: "*!*@spam.example", SetBy: "chanserv", SetAt: &setAt},
+		{Mask: "*!*@new.example"},
+	}); err != nil {
+		t.Fatalf("ReplaceChannelListEntries: %v", err)
+	}
+
+	got, err := s.ListChannelListEntries(net.ID, "#ops", "b")
+	if err != nil {
+		t.Fatalf("ListChannelListEntries: %v", err)
+	}
+	byMask := make(map[string]ChannelListEntry, len(got))
+	for _, entry := range got {
+		byMask[entry.Mask] = entry
+	}
+	if len(byMask) != 2 {
+		t.Fatalf("entries = %+v, want spam and new only", got)
+	}
+	spam := byMask["*!*@spam.example"]
+	if spam.SetBy != "chanserv" || spam.SetAt == nil || !spam.SetAt.Equal(setAt) {
+		t.Errorf("spam entry = %+v, want set by chanserv at %v", spam, setAt)
+	}
+	if spam.ExpiresAt == nil || !spam.ExpiresAt.Equal(expires) {
+		t.Errorf("spam expiry = %v, want %v kept across refresh", spam.ExpiresAt, expires)
+	}
+	if byMask["*!*@new.example"].ExpiresAt != nil {
+		t.Errorf("new entry has an expiry it was never given")
+	}
+
+	// Another mode's list is untouched by a +b refresh.
+	if got, err := s.ListChannelListEntries(net.ID, "#ops", "e"); err != nil || len(got) != 0 {
+		t.Errorf("exception list = %v, err %v; want empty", got, err)
+	}
+}
+
+func TestListExpiredChannelListEntries(t *testing.T) {
+	s := newTestStorage(t)
+	net := makeNetwork("ExpiryNet")
+	if err := s.CreateNetwork(net); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	entries := []ChannelListEntry{
+		{NetworkID: net.ID, Channel: "#a", ChannelKey: "#a", Mode: "b", Mask: "due!*@*", ExpiresAt: &past},
+		{NetworkID: net.ID, Channel: "#a", ChannelKey: "#a", Mode: "I", Mask: "later!*@*", ExpiresAt: &future},
+		{NetworkID: net.ID, Channel: "#a", ChannelKey: "#a", Mode: "e", Mask: "forever!*@*"},
+	}
+	for _, entry := range entries {
+		if err := s.UpsertChannelListEntry(entry); err != nil {
+			t.Fatalf("UpsertChannelListEntry: %v", err)
+		}
+	}
+
+	expired, err := s.ListExpiredChannelListEntries(now)
+	if err != nil {
+		t.Fatalf("ListExpiredChannelListEntries: %v", err)
+	}
+	if len(expired) != 1 || expired[0].Mask != "due!*@*" {
+		t.Fatalf("expired = %+v, want only due!*@*", expired)
+	}
+
+	// Cancelling the expiry takes the entry off the schedule.
+	if err := s.SetChannelListEntryExpiry(net.ID, "#a", "b", "due!*@*", nil); err != nil {
+		t.Fatalf("SetChannelListEntryExpiry: %v", err)
+	}
+	if expired, err := s.ListExpiredChannelListEntries(now); err != nil || len(expired) != 0 {
+		t.Errorf("expired after cancel = %+v, err %v; want none", expired, err)
+	}
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	db "github.com/matt0x6f/irc-client/internal/storage/generated"
+)
+
+// statsKey identifies one row of channel_stats_hourly.
+type statsKey struct {
+	channelID int64
+	nickKey   string
+	hour      int64
+}
+
+// RefreshChannelStats folds up to batch messages stored since the last refresh
+// into the hourly channel aggregates and reports how many it consumed. A
+// result below batch means the aggregates have caught up.
+//
+// Chat lines and actions count towards lines, words and URLs; joins count as
+// joins; parts and quits count as parts. Everything else only advances the
+// cursor. fold keys each nick the way its network compares nicks, so that
+// "[nick]" and "{nick}" count as one under rfc1459.
+func (s *Storage) RefreshChannelStats(batch int, fold func(networkID int64, nick string) string) (int, error) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin stats refresh: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries := s.queries.WithTx(tx)
+
+	last, err := queries.GetChannelStatsProgress(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("read stats progress: %w", err)
+	}
+	rows, err := queries.ListMessagesAfter(ctx, db.ListMessagesAfterParams{ID: last, Limit: int64(batch)})
+	if err != nil {
+		return 0, fmt.Errorf("list messages for stats: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	// Sum the batch in memory first so each hour is written once.
+	sums := make(map[statsKey]*db.AddChannelStatsHourParams)
+	var order []statsKey
+	for _, row := range rows {
+		if !row.ChannelID.Valid || row.User == "" {
+			continue
+		}
+		var lines, words, urls, joins, parts int64
+		switch row.MessageType {
+		case "privmsg", "action":
+			lines = 1
+			words, urls = countWords(row.Text)
+		case "join":
+			joins = 1
+		case "part", "quit":
+			parts = 1
+		default:
+			continue
+		}
+		at := row.Timestamp.Unix()
+		key := statsKey{channelID: row.ChannelID.Int64, nickKey: fold(row.NetworkID, row.User), hour: hourOf(at)}
+		sum, ok := sums[key]
+		if !ok {
+			sum = &db.AddChannelStatsHourParams{
+				NetworkID: row.NetworkID,
+				ChannelID: key.channelID,
+				NickKey:   key.nickKey,
+				Hour:      key.hour,
+				FirstSeen: at,
+				LastSeen:  at,
+			}
+			sums[key] = sum
+			order = append(order, key)
+		}
+		sum.Nick = row.User
+		sum.Lines += lines
+		sum.Words += words
+		sum.Urls += urls
+		sum.Joins += joins
+		sum.Parts += parts
+		sum.FirstSeen = min(sum.FirstSeen, at)
+		sum.LastSeen = max(sum.LastSeen, at)
+	}
+	for _, key := range order {
+		if err := queries.AddChannelStatsHour(ctx, *sums[key]); err != nil {
+			return 0, fmt.Errorf("update channel stats: %w", err)
+		}
+	}
+	if err := queries.SetChannelStatsProgress(ctx, rows[len(rows)-1].ID); err != nil {
+		return 0, fmt.Errorf("record stats progress: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit stats refresh: %w", err)
+	}
+	return len(rows), nil
+}
+
+// ChannelNickActivity returns each nick's activity in the channel between from
+// and to, busiest first. A zero from or to leaves that end of the window open.
+func (s *Storage) ChannelNickActivity(channelID int64, from, to time.Time) ([]NickActivity, error) {
+	fromHour, toHour := hourWindow(from, to)
+	rows, err := s.queries.ListChannelStatsNicks(context.Background(), db.ListChannelStatsNicksParams{
+		ChannelID: channelID,
+		FromHour:  fromHour,
+		ToHour:    toHour,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list channel nick stats: %w", err)
+	}
+	out := make([]NickActivity, len(rows))
+	for i, row := range rows {
+		out[i] = nickActivity(db.GetChannelStatsNickRow(row))
+	}
+	return out, nil
+}
+
+// ChannelNickActivityFor returns the activity in the channel between from and
+// to of the nick folded to nickKey, as RefreshChannelStats folded it. It
+// reports false when the nick was not seen in that window.
+func (s *Storage) ChannelNickActivityFor(channelID int64, nickKey string, from, to time.Time) (NickActivity, bool, error) {
+	fromHour, toHour := hourWindow(from, to)
+	row, err := s.queries.GetChannelStatsNick(context.Background(), db.GetChannelStatsNickParams{
+		ChannelID: channelID,
+		NickKey:   nickKey,
+		FromHour:  fromHour,
+		ToHour:    toHour,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return NickActivity{}, false, nil
+	}
+	if err != nil {
+		return NickActivity{}, false, fmt.Errorf("get channel nick stats: %w", err)
+	}
+	return nickActivity(row), true, nil
+}
+
+// ChannelHourActivity returns the channel's activity for every UTC hour
+// between from and to that saw any, oldest first.
+func (s *Storage) ChannelHourActivity(channelID int64, from, to time.Time) ([]HourActivity, error) {
+	fromHour, toHour := hourWindow(from, to)
+	rows, err := s.queries.ListChannelStatsHours(context.Background(), db.ListChannelStatsHoursParams{
+		ChannelID: channelID,
+		FromHour:  fromHour,
+		ToHour:    toHour,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list channel hour stats: %w", err)
+	}
+	out := make([]HourActivity, len(rows))
+	for i, row := range rows {
+		out[i] = HourActivity{
+			Hour:  time.Unix(row.Hour*3600, 0).UTC(),
+			Lines: row.Lines,
+			Words: row.Words,
+			URLs:  row.Urls,
+			Joins: row.Joins,
+			Parts: row.Parts,
+		}
+	}
+	return out, nil
+}
+
+func nickActivity(row db.GetChannelStatsNickRow) NickActivity {
+	return NickActivity{
+		Nick:      row.Nick,
+		Lines:     row.Lines,
+		Words:     row.Words,
+		URLs:      row.Urls,
+		Joins:     row.Joins,
+		Parts:     row.Parts,
+		FirstSeen: time.Unix(row.FirstSeen, 0),
+		LastSeen:  time.Unix(row.LastSeen, 0),
+	}
+}
+
+// hourOf returns the UTC hour bucket holding a Unix time.
+func hourOf(unix int64) int64 {
+	if unix < 0 {
+		return (unix - 3599) / 3600
+	}
+	return unix / 3600
+}
+
+// hourWindow turns a time window into the half-open range of hour buckets
+// that overlap it.
+func hourWindow(from, to time.Time) (int64, int64) {
+	fromHour, toHour := int64(math.MinInt64), int64(math.MaxInt64)
+	if !from.IsZero() {
+		fromHour = hourOf(from.Unix())
+	}
+	if !to.IsZero() {
+		toHour = hourOf(to.Unix()-1) + 1
+	}
+	return fromHour, toHour
+}
+
+// countWords counts the words in a line and how many of them are URLs.
+func countWords(text string) (words, urls int64) {
+	for _, field := range strings.Fields(text) {
+		words++
+		lower := strings.ToLower(field)
+		if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "www.") {
+			urls++
+		}
+	}
+	return words, urls
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc1459Fold folds a nick as the rfc1459 CASEMAPPING does.
+func rfc1459Fold(_ int64, nick string) string {
+	return strings.NewReplacer("[", "{", "]", "}", "\\", "|", "~", "^").Replace(strings.ToLower(nick))
+}
+
+func TestRefreshChannelStatsIsIncremental(t *testing.T) {
+	s := newTestStorage(t)
+	networkID, channelID := testChannel(t, s)
+	base := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	write := func(offset time.Duration, user, kind, text string) {
+		t.Helper()
+		if err := s.WriteMessageSync(Message{
+			NetworkID:   networkID,
+			ChannelID:   &channelID,
+			User:        user,
+			Message:     text,
+			MessageType: kind,
+			Timestamp:   base.Add(offset),
+		}); err != nil {
+			t.Fatalf("WriteMessageSync: %v", err)
+		}
+	}
+	write(0, "alice", "join", "")
+	write(time.Minute, "alice", "privmsg", "see https://example.com now")
+	write(2*time.Minute, "Bob", "action", "waves")
+	write(3*time.Minute, "carol", "mode", "+o alice")
+	write(90*time.Minute, "ALICE", "privmsg", "back again")
+	write(91*time.Minute, "bob", "quit", "Quit: bye")
+	write(92*time.Minute, "[dan]", "privmsg", "hi")
+	write(93*time.Minute, "{Dan}", "privmsg", "again")
+
+	// A batch smaller than the backlog leaves the rest for the next call.
+	if n, err := s.RefreshChannelStats(4, rfc1459Fold); err != nil || n != 4 {
+		t.Fatalf("first refresh = %d, %v; want 4", n, err)
+	}
+	if n, err := s.RefreshChannelStats(4, rfc1459Fold); err != nil || n != 4 {
+		t.Fatalf("second refresh = %d, %v; want 4", n, err)
+	}
+	if n, err := s.RefreshChannelStats(4, rfc1459Fold); err != nil || n != 0 {
+		t.Fatalf("caught-up refresh = %d, %v; want 0", n, err)
+	}
+
+	nicks, err := s.ChannelNickActivity(channelID, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nicks) != 3 {
+		t.Fatalf("nicks = %+v, want alice, dan and bob", nicks)
+	}
+	alice := nicks[0]
+	if alice.Nick != "ALICE" || alice.Lines != 2 || alice.Words != 5 || alice.URLs != 1 || alice.Joins != 1 {
+		t.Errorf("alice = %+v", alice)
+	}
+	if !alice.FirstSeen.Equal(base) || !alice.LastSeen.Equal(base.Add(90*time.Minute)) {
+		t.Errorf("alice seen %v..%v", alice.FirstSeen, alice.LastSeen)
+	}
+	if dan := nicks[1]; dan.Nick != "{Dan}" || dan.Lines != 2 {
+		t.Errorf("[dan] and {Dan} were not counted as one nick: %+v", dan)
+	}
+	if bob := nicks[2]; bob.Lines != 1 || bob.Parts != 1 {
+		t.Errorf("bob = %+v", bob)
+	}
+
+	// The window is matched to whole hours.
+	hours, err := s.ChannelHourActivity(channelID, base, base.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hours) != 1 || hours[0].Lines != 2 || hours[0].Joins != 1 || !hours[0].Hour.Equal(base) {
+		t.Errorf("first hour = %+v", hours)
+	}
+
+	if _, ok, err := s.ChannelNickActivityFor(channelID, rfc1459Fold(networkID, "carol"), time.Time{}, time.Time{}); err != nil || ok {
+		t.Errorf("carol only set a mode but was reported (ok=%v, err=%v)", ok, err)
+	}
+	if bob, ok, err := s.ChannelNickActivityFor(channelID, rfc1459Fold(networkID, "BOB"), base.Add(time.Hour), time.Time{}); err != nil || !ok || bob.Lines != 0 || bob.Parts != 1 {
+		t.Errorf("bob in the second hour = %+v, %v, %v", bob, ok, err)
+	}
+}
+
+// TestMigrateChannelStatsNickKeysRebuilds: aggregates from before nick keys
+// were CASEMAPPING-folded are dropped once, so the refresh rebuilds them, and
+// a current database keeps its aggregates.
+func TestMigrateChannelStatsNickKeysRebuilds(t *testing.T) {
+	s := newTestStorage(t)
+	networkID, channelID := testChannel(t, s)
+	for _, user := range []string{"[dan]", "{dan}"} {
+		if err := s.WriteMessageSync(Message{NetworkID: networkID, ChannelID: &channelID, User: user, Message: "hi", MessageType: "privmsg", Timestamp: time.Now()}); err != nil {
+			t.Fatalf("WriteMessageSync: %v", err)
+		}
+	}
+	lower := func(_ int64, nick string) string { return strings.ToLower(nick) }
+	if _, err := s.RefreshChannelStats(10, lower); err != nil {
+		t.Fatalf("RefreshChannelStats: %v", err)
+	}
+
+	if err := migrateChannelStatsNickKeys(s.db); err != nil {
+		t.Fatalf("migration on a current database: %v", err)
+	}
+	if nicks, _ := s.ChannelNickActivity(channelID, time.Time{}, time.Time{}); len(nicks) != 2 {
+		t.Fatalf("a current database lost its aggregates: %+v", nicks)
+	}
+
+	if _, err := s.db.Exec("PRAGMA user_version = 11"); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateChannelStatsNickKeys(s.db); err != nil {
+		t.Fatalf("migration: %v", err)
+	}
+	if n, err := s.RefreshChannelStats(10, rfc1459Fold); err != nil || n != 2 {
+		t.Fatalf("rebuild = %d, %v; want both messages refolded", n, err)
+	}
+	if nicks, _ := s.ChannelNickActivity(channelID, time.Time{}, time.Time{}); len(nicks) != 1 || nicks[0].Lines != 2 {
+		t.Fatalf("rebuilt nicks = %+v, want one nick with 2 lines", nicks)
+	}
+}
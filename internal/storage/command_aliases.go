@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	db "github.com/matt0x6f/irc-client/internal/storage/generated"
+)
+
+// SaveCommandAlias creates or replaces the alias with the same name in the
+// same scope. Names are stored upper-case, like built-in command names.
+func (s *Storage) SaveCommandAlias(alias CommandAlias) error {
+	name := strings.ToUpper(strings.TrimSpace(alias.Name))
+	if name == "" || strings.TrimSpace(alias.Expansion) == "" {
+		return fmt.Errorf("command alias requires a name and an expansion")
+	}
+	if err := s.queries.UpsertCommandAlias(context.Background(), db.UpsertCommandAliasParams{
+		NetworkID: convertToNullInt64(alias.NetworkID),
+		Name:      name,
+		Expansion: alias.Expansion,
+	}); err != nil {
+		return fmt.Errorf("save command alias %q: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteCommandAlias removes an alias; a nil networkID names the global one.
+func (s *Storage) DeleteCommandAlias(networkID *int64, name string) error {
+	if err := s.queries.DeleteCommandAlias(context.Background(), db.DeleteCommandAliasParams{
+		NetworkID: convertToNullInt64(networkID),
+		Name:      strings.ToUpper(name),
+	}); err != nil {
+		return fmt.Errorf("delete command alias %q: %w", name, err)
+	}
+	return nil
+}
+
+// ListCommandAliases returns every alias: global ones first, then each
+// network's, sorted by name within a scope.
+func (s *Storage) ListCommandAliases() ([]CommandAlias, error) {
+	rows, err := s.queries.ListCommandAliases(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("list command aliases: %w", err)
+	}
+	out := make([]CommandAlias, len(rows))
+	for i, row := range rows {
+		out[i] = convertCommandAliasFromDB(row)
+	}
+	return out, nil
+}
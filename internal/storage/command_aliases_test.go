@@ -0,0 +1,50 @@
+package storage
+
+import "testing"
+
+// TestCommandAliasScopes: a name is unique per scope, so a global alias and a
+// network's alias of the same name coexist, and saving again replaces the
+// expansion rather than adding a row.
+func TestCommandAliasScopes(t *testing.T) {
+	s := newTestStorage(t)
+	net := makeNetwork("AliasNet")
+	if err := s.CreateNetwork(net); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+
+	for _, alias := range []CommandAlias{
+		{Name: "ns", Expansion: "/msg NickServ $*"},
+		{Name: "NS", Expansion: "/msg NickServ $1-"},
+		{NetworkID: &net.ID, Name: "ns", Expansion: "/msg NS $*"},
+	} {
+		if err := s.SaveCommandAlias(alias); err != nil {
+			t.Fatalf("SaveCommandAlias(%+v): %v", alias, err)
+		}
+	}
+
+	got, err := s.ListCommandAliases()
+	if err != nil {
+		t.Fatalf("ListCommandAliases: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d aliases, want 2: %+v", len(got), got)
+	}
+	if got[0].NetworkID != nil || got[0].Name != "NS" || got[0].Expansion != "/msg NickServ $1-" {
+		t.Errorf("global alias = %+v", got[0])
+	}
+	if got[1].NetworkID == nil || *got[1].NetworkID != net.ID || got[1].Expansion != "/msg NS $*" {
+		t.Errorf("network alias = %+v", got[1])
+	}
+
+	if err := s.DeleteCommandAlias(nil, "ns"); err != nil {
+		t.Fatalf("DeleteCommandAlias: %v", err)
+	}
+	got, _ = s.ListCommandAliases()
+	if len(got) != 1 || got[0].NetworkID == nil {
+		t.Fatalf("after deleting the global alias: %+v", got)
+	}
+
+	if err := s.SaveCommandAlias(CommandAlias{Name: "empty"}); err == nil {
+		t.Error("SaveCommandAlias accepted an alias without an expansion")
+	}
+}
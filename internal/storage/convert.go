@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	db "github.com/matt0x6f/irc-client/internal/storage/generated"
 )
@@ -32,6 +33,7 @@ func convertNetworkFromDB(n db.Network) Network {
 		SASLEnabled:   n.SaslEnabled,
 		AutoConnect:   n.AutoConnect,
 		IdentifyAsBot: n.IdentifyAsBot,
+		FloodProfile:  n.FloodProfile,
 		CreatedAt:     n.CreatedAt,
 		UpdatedAt:     n.UpdatedAt,
 		SortOrder:     n.SortOrder,
@@ -70,6 +72,7 @@ func convertNetworkToDBCreateParams(n *Network) db.CreateNetworkParams {
 		SaslEnabled:   n.SASLEnabled,
 		AutoConnect:   n.AutoConnect,
 		IdentifyAsBot: n.IdentifyAsBot,
+		FloodProfile:  n.FloodProfile,
 		CreatedAt:     n.CreatedAt,
 		UpdatedAt:     n.UpdatedAt,
 	}
@@ -101,6 +104,7 @@ func convertNetworkToDBUpdateParams(n *Network) db.UpdateNetworkParams {
 		SaslEnabled:   n.SASLEnabled,
 		AutoConnect:   n.AutoConnect,
 		IdentifyAsBot: n.IdentifyAsBot,
+		FloodProfile:  n.FloodProfile,
 		UpdatedAt:     n.UpdatedAt,
 		ID:            n.ID,
 	}
@@ -154,15 +158,16 @@ func convertServerToDBUpdateParams(s *Server) db.UpdateServerParams {
 
 func convertChannelFromDB(c db.Channel) Channel {
 	result := Channel{
-		ID:        c.ID,
-		NetworkID: c.NetworkID,
-		Name:      c.Name,
-		Topic:     convertNullString(c.Topic),
-		Modes:     convertNullString(c.Modes),
-		Key:       c.Key,
-		AutoJoin:  c.AutoJoin,
-		IsOpen:    c.IsOpen,
-		CreatedAt: c.CreatedAt,
+		ID:                 c.ID,
+		NetworkID:          c.NetworkID,
+		Name:               c.Name,
+		Topic:              convertNullString(c.Topic),
+		Modes:              convertNullString(c.Modes),
+		Key:                c.Key,
+		AutoJoin:           c.AutoJoin,
+		IsOpen:             c.IsOpen,
+		CreatedAt:          c.CreatedAt,
+		SmartFilterMinutes: int(c.SmartFilterMinutes),
 	}
 	if c.UpdatedAt.Valid {
 		result.UpdatedAt = &c.UpdatedAt.Time
@@ -205,6 +210,10 @@ func convertMessageFromDB(m db.Message) Message {
 		MsgID:          convertNullString(m.Msgid),
 		ReplyMsgID:     convertNullString(m.ReplyMsgid),
 		ChannelContext: convertNullString(m.ChannelContext),
+		Account:        convertNullString(m.Account),
+		Filtered:       m.Filtered,
+		Encrypted:      m.Encrypted,
+		Plaintext:      convertNullString(m.Plaintext),
 	}
 	if m.ChannelID.Valid {
 		result.ChannelID = &m.ChannelID.Int64
@@ -229,6 +238,10 @@ func convertMessageToDBCreateParams(m Message) db.CreateMessageParams {
 		Msgid:          convertToNullString(m.MsgID),
 		ReplyMsgid:     convertToNullString(m.ReplyMsgID),
 		ChannelContext: convertToNullString(m.ChannelContext),
+		Account:        convertToNullString(m.Account),
+		Filtered:       m.Filtered,
+		Encrypted:      m.Encrypted,
+		Plaintext:      sql.NullString{String: stripFormatting(m.Message), Valid: true}, // derived, never trusted from the caller (see normalizeForStore)
 	}
 }
 
@@ -298,6 +311,8 @@ func convertFileTransferFromDB(t db.FileTransfer) FileTransfer {
 		Resumable:        t.Resumable != 0,
 		CreatedAt:        t.CreatedAt,
 		UpdatedAt:        t.UpdatedAt,
+		SHA256:           t.Sha256,
+		QueuePosition:    int(t.QueuePosition),
 	}
 	if t.NetworkID.Valid {
 		networkID := t.NetworkID.Int64
@@ -348,6 +363,8 @@ func convertFileTransferToUpsertParams(t FileTransfer) db.UpsertFileTransferPara
 		CreatedAt:        t.CreatedAt.UTC(),
 		UpdatedAt:        t.UpdatedAt.UTC(),
 		FinishedAt:       finishedAt,
+		Sha256:           t.SHA256,
+		QueuePosition:    int64(t.QueuePosition),
 	}
 }
 
@@ -450,7 +467,58 @@ func convertPluginConfigFromDB(pc db.PluginConfig) (*PluginConfig, error) {
 	return &config, nil
 }
 
+func convertChannelListEntryFromDB(e db.ChannelListEntry) ChannelListEntry {
+	return ChannelListEntry{
+		ID:         e.ID,
+		NetworkID:  e.NetworkID,
+		Channel:    e.Channel,
+		ChannelKey: e.ChannelKey,
+		Mode:       e.Mode,
+		Mask:       e.Mask,
+		SetBy:      e.SetBy,
+		SetAt:      convertNullTimePtr(e.SetAt),
+		ExpiresAt:  convertNullTimePtr(e.ExpiresAt),
+	}
+}
+
+func convertChannelListEntriesFromDB(rows []db.ChannelListEntry) []ChannelListEntry {
+	result := make([]ChannelListEntry, len(rows))
+	for i, row := range rows {
+		result[i] = convertChannelListEntryFromDB(row)
+	}
+	return result
+}
+
+func convertChannelListEntryToUpsertParams(e ChannelListEntry) db.UpsertChannelListEntryParams {
+	return db.UpsertChannelListEntryParams{
+		NetworkID:  e.NetworkID,
+		Channel:    e.Channel,
+		ChannelKey: e.ChannelKey,
+		Mode:       e.Mode,
+		Mask:       e.Mask,
+		SetBy:      e.SetBy,
+		SetAt:      convertToNullTime(e.SetAt),
+		ExpiresAt:  convertToNullTime(e.ExpiresAt),
+	}
+}
+
 // Helper functions for null conversions
+func convertCommandAliasFromDB(a db.CommandAlias) CommandAlias {
+	alias := CommandAlias{ID: a.ID, Name: a.Name, Expansion: a.Expansion}
+	if a.NetworkID.Valid {
+		networkID := a.NetworkID.Int64
+		alias.NetworkID = &networkID
+	}
+	return alias
+}
+
+func convertToNullInt64(v *int64) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *v, Valid: true}
+}
+
 func convertNullString(ns sql.NullString) string {
 	if ns.Valid {
 		return ns.String
@@ -461,3 +529,18 @@ func convertNullString(ns sql.NullString) string {
 func convertToNullString(s string) sql.NullString {
 	return sql.NullString{String: s, Valid: s != ""}
 }
+
+func convertNullTimePtr(nt sql.NullTime) *time.Time {
+	if !nt.Valid {
+		return nil
+	}
+	t := nt.Time
+	return &t
+}
+
+func convertToNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
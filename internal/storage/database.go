@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ergochat/irc-go/ircfmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/matt0x6f/irc-client/internal/logger"
 	db "github.com/matt0x6f/irc-client/internal/storage/generated"
@@ -28,6 +29,7 @@ type Storage struct {
 	wg            sync.WaitGroup
 	closed        bool
 	closedMu      sync.RWMutex
+	statsMu       sync.Mutex // serializes RefreshChannelStats
 }
 
 // NewStorage creates a new storage instance
@@ -152,8 +154,8 @@ func (s *Storage) flushBuffer(allowClosed bool) {
 			// rows stay out of the partial unique index (so they never collide). The
 			// ON CONFLICT clause makes the live path idempotent against the msgid dedup
 			// index — e.g. an echo and a CHATHISTORY replay of the same line.
-			query := `INSERT INTO messages (network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context)
-			          VALUES (:network_id, :channel_id, :user, :message, :message_type, :timestamp, :raw_line, NULLIF(:pm_target, ''), NULLIF(:msgid, ''), NULLIF(:reply_msgid, ''), NULLIF(:channel_context, ''))
+			query := `INSERT INTO messages (network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted)
+			          VALUES (:network_id, :channel_id, :user, :message, :message_type, :timestamp, :raw_line, NULLIF(:pm_target, ''), NULLIF(:msgid, ''), NULLIF(:reply_msgid, ''), NULLIF(:channel_context, ''), :plaintext, NULLIF(:account, ''), :filtered, :encrypted)
 			          ON CONFLICT(network_id, COALESCE(channel_id,0), COALESCE(pm_target,''), msgid) WHERE msgid IS NOT NULL DO NOTHING`
 
 			_, err := s.db.NamedExec(query, messages)
@@ -173,8 +175,14 @@ func (s *Storage) flushBuffer(allowClosed bool) {
 // `ORDER BY timestamp` and the `WHERE timestamp < ?` scrollback cursor
 // (GetMessagesBeforeTime) chronologically wrong. Keeping every write in UTC keeps
 // text order == chronological order.
+//
+// Plaintext is always re-derived from Message (never trusted from the caller): it
+// is the formatting-stripped copy that messages_fts indexes, so a coloured bot line
+// like "\x0304build\x03 \x02failed\x02" is found by a search for "build failed"
+// while the formatted original is still what the frontend renders.
 func normalizeForStore(msg Message) Message {
 	msg.Timestamp = msg.Timestamp.UTC()
+	msg.Plaintext = stripFormatting(msg.Message)
 	return msg
 }
 
@@ -285,8 +293,8 @@ func (s *Storage) WriteHistoryMessages(msgs []Message) (int, error) {
 	// Same NULLIF + ON CONFLICT semantics as flushBuffer: msgid-less rows are
 	// exempt from the dedup index; rows whose msgid already exists are skipped
 	// (and excluded from RowsAffected, so the returned count is new rows only).
-	query := `INSERT INTO messages (network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context)
-	          VALUES (:network_id, :channel_id, :user, :message, :message_type, :timestamp, :raw_line, NULLIF(:pm_target, ''), NULLIF(:msgid, ''), NULLIF(:reply_msgid, ''), NULLIF(:channel_context, ''))
+	query := `INSERT INTO messages (network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted)
+	          VALUES (:network_id, :channel_id, :user, :message, :message_type, :timestamp, :raw_line, NULLIF(:pm_target, ''), NULLIF(:msgid, ''), NULLIF(:reply_msgid, ''), NULLIF(:channel_context, ''), :plaintext, NULLIF(:account, ''), :filtered, :encrypted)
 	          ON CONFLICT(network_id, COALESCE(channel_id,0), COALESCE(pm_target,''), msgid) WHERE msgid IS NOT NULL DO NOTHING`
 
 	normalized := make([]Message, len(msgs))
@@ -552,6 +560,48 @@ func (s *Storage) GetMessagesBeforeTime(networkID int64, channelID *int64, pmTar
 	return messages, nil
 }
 
+// GetMessagesAfterTime returns up to `limit` messages strictly newer than
+// `after` (by server-time timestamp), oldest first. It is the newer-direction
+// counterpart of GetMessagesBeforeTime and selects its target the same way.
+func (s *Storage) GetMessagesAfterTime(networkID int64, channelID *int64, pmTarget string, after time.Time, limit int) ([]Message, error) {
+	var dbMessages []db.Message
+	var err error
+
+	switch {
+	case pmTarget != "":
+		dbMessages, err = s.queries.GetMessagesAfterTimePM(context.Background(), db.GetMessagesAfterTimePMParams{
+			NetworkID: networkID,
+			PmTarget:  sql.NullString{String: strings.ToLower(pmTarget), Valid: true},
+			Timestamp: after,
+			Limit:     int64(limit),
+		})
+	case channelID != nil:
+		dbMessages, err = s.queries.GetMessagesAfterTimeWithChannel(context.Background(), db.GetMessagesAfterTimeWithChannelParams{
+			NetworkID: networkID,
+			ChannelID: sql.NullInt64{Int64: *channelID, Valid: true},
+			Timestamp: after,
+			Limit:     int64(limit),
+		})
+	default:
+		dbMessages, err = s.queries.GetMessagesAfterTimeWithoutChannel(context.Background(), db.GetMessagesAfterTimeWithoutChannelParams{
+			NetworkID: networkID,
+			Timestamp: after,
+			Limit:     int64(limit),
+		})
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages after time: %w", err)
+	}
+
+	messages := make([]Message, len(dbMessages))
+	for i, m := range dbMessages {
+		messages[i] = convertMessageFromDB(m)
+	}
+
+	return messages, nil
+}
+
 // GetMessagesAfter returns up to `limit` messages strictly newer than afterID
 // (exclusive), in chronological (ascending id) order. channelID nil = status pane.
 // The newer-direction counterpart of GetMessagesBefore — used when scrolling down
@@ -1186,51 +1236,53 @@ type SearchResult struct {
 	NetworkName string `db:"network_name" json:"network_name"`
 }
 
-// SearchMessages performs full-text search across messages using FTS5
+// SearchMessages performs full-text search across messages using FTS5. An
+// "account:name" term restricts results to lines sent while logged in as that
+// services account, whatever nick was in use; a query of only account terms
+// lists that account's messages without a text match.
 func (s *Storage) SearchMessages(query string, networkID *int64, limit int) ([]SearchResult, error) {
-	if query == "" {
+	text, account := splitAccountFilter(query)
+	if text == "" && account == "" {
 		return []SearchResult{}, nil
 	}
 	if limit <= 0 {
 		limit = 50
 	}
 
-	// Sanitize the query for FTS5: wrap terms in quotes to avoid syntax errors
-	// from special characters, and add * for prefix matching
-	sanitized := sanitizeFTS5Query(query)
-
-	var results []SearchResult
-	var err error
-
+	var where []string
+	var args []interface{}
+	join := ""
+	if text != "" {
+		// Sanitize the query for FTS5: wrap terms in quotes to avoid syntax errors
+		// from special characters, and add * for prefix matching
+		join = "JOIN messages_fts ON messages_fts.rowid = m.id"
+		where = append(where, "messages_fts MATCH ?")
+		args = append(args, sanitizeFTS5Query(text))
+	}
+	if account != "" {
+		where = append(where, "m.account = ? COLLATE NOCASE")
+		args = append(args, account)
+	}
 	if networkID != nil {
-		err = s.db.Select(&results, `
-			SELECT m.id, m.network_id, m.channel_id, m.user, m.message, m.message_type, m.timestamp, m.raw_line,
-				COALESCE(c.name, '') as channel_name,
-				COALESCE(n.name, '') as network_name
-			FROM messages m
-			JOIN messages_fts ON messages_fts.rowid = m.id
-			LEFT JOIN channels c ON m.channel_id = c.id
-			LEFT JOIN networks n ON m.network_id = n.id
-			WHERE messages_fts MATCH ?
-			AND m.network_id = ?
-			ORDER BY m.timestamp DESC
-			LIMIT ?
-		`, sanitized, *networkID, limit)
-	} else {
-		err = s.db.Select(&results, `
-			SELECT m.id, m.network_id, m.channel_id, m.user, m.message, m.message_type, m.timestamp, m.raw_line,
-				COALESCE(c.name, '') as channel_name,
-				COALESCE(n.name, '') as network_name
-			FROM messages m
-			JOIN messages_fts ON messages_fts.rowid = m.id
-			LEFT JOIN channels c ON m.channel_id = c.id
-			LEFT JOIN networks n ON m.network_id = n.id
-			WHERE messages_fts MATCH ?
-			ORDER BY m.timestamp DESC
-			LIMIT ?
-		`, sanitized, limit)
+		where = append(where, "m.network_id = ?")
+		args = append(args, *networkID)
 	}
+	args = append(args, limit)
 
+	var results []SearchResult
+	err := s.db.Select(&results, `
+		SELECT m.id, m.network_id, m.channel_id, m.user, m.message, m.message_type, m.timestamp, m.raw_line,
+			COALESCE(m.account, '') as account,
+			COALESCE(c.name, '') as channel_name,
+			COALESCE(n.name, '') as network_name
+		FROM messages m
+		`+join+`
+		LEFT JOIN channels c ON m.channel_id = c.id
+		LEFT JOIN networks n ON m.network_id = n.id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY m.timestamp DESC
+		LIMIT ?
+	`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search messages: %w", err)
 	}
@@ -1238,6 +1290,21 @@ func (s *Storage) SearchMessages(query string, networkID *int64, limit int) ([]S
 	return results, nil
 }
 
+// splitAccountFilter pulls an "account:name" term out of a search query,
+// returning the remaining text and the account ("" when absent). The last
+// account term wins.
+func splitAccountFilter(query string) (text, account string) {
+	var words []string
+	for _, word := range strings.Fields(query) {
+		if name, ok := strings.CutPrefix(word, "account:"); ok && name != "" {
+			account = name
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), account
+}
+
 // sanitizeFTS5Query sanitizes a user query for FTS5 MATCH syntax
 func sanitizeFTS5Query(query string) string {
 	// Trim whitespace
@@ -1260,6 +1327,20 @@ func sanitizeFTS5Query(query string) string {
 	return strings.Join(quoted, " ")
 }
 
+// stripFormatting removes mIRC formatting control codes (bold, colour, italic,
+// reverse, reset, ...) from a message body. Colour codes take their digit
+// arguments with them, so "\x0304red" strips to "red" rather than "04red".
+func stripFormatting(message string) string {
+	if !strings.ContainsAny(message, ircFormattingCodes) {
+		return message
+	}
+	return ircfmt.Strip(message)
+}
+
+// ircFormattingCodes are the control characters ircfmt.Strip understands:
+// bold, colour, monospace, reverse, italic, strikethrough, reset, underline.
+const ircFormattingCodes = "\x02\x03\x11\x16\x1d\x1e\x0f\x1f"
+
 // GetPluginConfig retrieves the configuration for a plugin
 func (s *Storage) GetPluginConfig(name string) (*PluginConfig, error) {
 	dbConfig, err := s.queries.GetPluginConfig(context.Background(), name)
@@ -1318,6 +1399,19 @@ func (s *Storage) SetSetting(key, value string) error {
 	return nil
 }
 
+// GetSettings returns every key/value pair in the settings store.
+func (s *Storage) GetSettings() (map[string]string, error) {
+	rows, err := s.queries.ListSettings(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settings: %w", err)
+	}
+	settings := make(map[string]string, len(rows))
+	for _, r := range rows {
+		settings[r.Key] = r.Value
+	}
+	return settings, nil
+}
+
 // GetLinkPreview returns a cached preview if present and not older than
 // ttlSeconds relative to nowUnix. A stale row reports (nil, false, nil) and is
 // left in place (overwritten on the next fetch, swept by PruneLinkPreviews).
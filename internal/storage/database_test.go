@@ -1413,6 +1413,31 @@ func TestNetwork_IdentifyAsBot_UpdateRoundTrips(t *testing.T) {
 	}
 }
 
+func TestNetwork_FloodProfile_RoundTrips(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+	n := &Network{
+		Name: "home", Address: "irc.home.example", Port: 6697, TLS: true,
+		Nickname: "dan", Username: "dan", Realname: "Dan",
+		FloodProfile: "relaxed", CreatedAt: now, UpdatedAt: now,
+	}
+	if err := s.CreateNetwork(n); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	if got, err := s.GetNetwork(n.ID); err != nil || got.FloodProfile != "relaxed" {
+		t.Fatalf("after create: FloodProfile = %q, %v; want relaxed", got.FloodProfile, err)
+	}
+
+	n.FloodProfile = ""
+	n.UpdatedAt = time.Now()
+	if err := s.UpdateNetwork(n); err != nil {
+		t.Fatalf("UpdateNetwork: %v", err)
+	}
+	if got, err := s.GetNetwork(n.ID); err != nil || got.FloodProfile != "" {
+		t.Fatalf("after update: FloodProfile = %q, %v; want empty", got.FloodProfile, err)
+	}
+}
+
 // ---------- IRCv3 reply/channel-context tags ----------
 
 // TestConversationDedupAllowsMultiChannelQuit verifies that the dedup index is
@@ -1495,3 +1520,47 @@ func TestMessageReplyContextRoundTrip(t *testing.T) {
 		t.Fatalf("round-trip lost tags: reply=%q ctx=%q", got.ReplyMsgID, got.ChannelContext)
 	}
 }
+
+// TestSearchMessagesIgnoresFormattingCodes verifies that colour/bold codes in a
+// live-written message do not split words in the full-text index, and that the
+// index follows deletes.
+func TestSearchMessagesIgnoresFormattingCodes(t *testing.T) {
+	s := newTestStorage(t)
+	net := makeNetwork("SearchNet")
+	if err := s.CreateNetwork(net); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	formatted := "deploy \x0309,01succeeded\x03 for \x02cascade\x0f"
+	if err := s.WriteMessageSync(Message{
+		NetworkID:   net.ID,
+		User:        "deploybot",
+		Message:     formatted,
+		MessageType: "privmsg",
+		Timestamp:   time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, q := range []string{"succeeded", "cascade", "deploy succeeded"} {
+		results, err := s.SearchMessages(q, &net.ID, 10)
+		if err != nil {
+			t.Fatalf("SearchMessages(%q): %v", q, err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("SearchMessages(%q) returned %d rows; want 1", q, len(results))
+		}
+		if results[0].Message.Message != formatted {
+			t.Fatalf("result message = %q; want the formatted original", results[0].Message.Message)
+		}
+	}
+	if results, _ := s.SearchMessages("09", &net.ID, 10); len(results) != 0 {
+		t.Fatalf("colour digits leaked into the index: %+v", results)
+	}
+
+	if _, err := s.db.Exec("DELETE FROM messages WHERE network_id = ?", net.ID); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := s.SearchMessages("succeeded", &net.ID, 10); len(results) != 0 {
+		t.Fatalf("deleted row still indexed: %+v", results)
+	}
+}
@@ -186,6 +186,40 @@ func TestMigrateFileTransfersIsIdempotent(t *testing.T) {
 	}
 }
 
+func TestFileTransferChecksumRoundTrip(t *testing.T) {
+	s := newTestStorage(t)
+	if err := migrateFileTransferChecksum(s.db); err != nil {
+		t.Fatalf("repeat checksum migration: %v", err)
+	}
+	finished := time.Date(2026, 7, 14, 13, 0, 0, 0, time.UTC)
+	transfer := testFileTransfer("hashed", &finished)
+	transfer.State = "completed"
+	transfer.SHA256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	if err := s.UpsertFileTransfer(transfer); err != nil {
+		t.Fatalf("UpsertFileTransfer: %v", err)
+	}
+	got, err := s.GetFileTransfer("hashed")
+	if err != nil || got == nil || got.SHA256 != transfer.SHA256 {
+		t.Fatalf("checksum did not round trip: %+v, %v", got, err)
+	}
+}
+
+func TestFileTransferQueuePositionRoundTrip(t *testing.T) {
+	s := newTestStorage(t)
+	if err := migrateFileTransferQueuePosition(s.db); err != nil {
+		t.Fatalf("repeat queue position migration: %v", err)
+	}
+	transfer := testFileTransfer("queued", nil)
+	transfer.QueuePosition = 3
+	if err := s.UpsertFileTransfer(transfer); err != nil {
+		t.Fatalf("UpsertFileTransfer: %v", err)
+	}
+	got, err := s.GetFileTransfer("queued")
+	if err != nil || got == nil || got.QueuePosition != 3 {
+		t.Fatalf("queue position did not round trip: %+v, %v", got, err)
+	}
+}
+
 func timePtr(value time.Time) *time.Time {
 	return &value
 }
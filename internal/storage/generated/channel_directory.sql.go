@@ -0,0 +1,99 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: channel_directory.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteChannelDirectoryUnseenSince = `-- name: DeleteChannelDirectoryUnseenSince :execrows
+DELETE FROM channel_directory WHERE network_id = ? AND last_seen < ?
+`
+
+type DeleteChannelDirectoryUnseenSinceParams struct {
+	NetworkID int64 `json:"network_id"`
+	LastSeen  int64 `json:"last_seen"`
+}
+
+func (q *Queries) DeleteChannelDirectoryUnseenSince(ctx context.Context, arg DeleteChannelDirectoryUnseenSinceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteChannelDirectoryUnseenSince, arg.NetworkID, arg.LastSeen)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getChannelDirectoryFetch = `-- name: GetChannelDirectoryFetch :one
+SELECT network_id, fetched_at, previous_fetched_at, full_fetched_at, "filter" FROM channel_directory_fetches WHERE network_id = ?
+`
+
+func (q *Queries) GetChannelDirectoryFetch(ctx context.Context, networkID int64) (ChannelDirectoryFetch, error) {
+	row := q.db.QueryRowContext(ctx, getChannelDirectoryFetch, networkID)
+	var i ChannelDirectoryFetch
+	err := row.Scan(
+		&i.NetworkID,
+		&i.FetchedAt,
+		&i.PreviousFetchedAt,
+		&i.FullFetchedAt,
+		&i.Filter,
+	)
+	return i, err
+}
+
+const recordChannelDirectoryFetch = `-- name: RecordChannelDirectoryFetch :exec
+INSERT INTO channel_directory_fetches (network_id, fetched_at, previous_fetched_at, full_fetched_at, filter)
+VALUES (?1, ?2, 0, CASE WHEN ?3 = '' THEN ?2 ELSE 0 END, ?3)
+ON CONFLICT(network_id) DO UPDATE SET
+    previous_fetched_at = channel_directory_fetches.fetched_at,
+    fetched_at = excluded.fetched_at,
+    full_fetched_at = CASE WHEN excluded.filter = '' THEN excluded.fetched_at ELSE channel_directory_fetches.full_fetched_at END,
+    filter = excluded.filter
+`
+
+type RecordChannelDirectoryFetchParams struct {
+	NetworkID int64  `json:"network_id"`
+	FetchedAt int64  `json:"fetched_at"`
+	Filter    string `json:"filter"`
+}
+
+func (q *Queries) RecordChannelDirectoryFetch(ctx context.Context, arg RecordChannelDirectoryFetchParams) error {
+	_, err := q.db.ExecContext(ctx, recordChannelDirectoryFetch, arg.NetworkID, arg.FetchedAt, arg.Filter)
+	return err
+}
+
+const upsertChannelDirectoryEntry = `-- name: UpsertChannelDirectoryEntry :exec
+INSERT INTO channel_directory (network_id, channel_key, channel, users, topic, modes, first_seen, last_seen)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?7)
+ON CONFLICT(network_id, channel_key) DO UPDATE SET
+    channel = excluded.channel,
+    users = excluded.users,
+    topic = excluded.topic,
+    modes = excluded.modes,
+    last_seen = excluded.last_seen
+`
+
+type UpsertChannelDirectoryEntryParams struct {
+	NetworkID  int64  `json:"network_id"`
+	ChannelKey string `json:"channel_key"`
+	Channel    string `json:"channel"`
+	Users      int64  `json:"users"`
+	Topic      string `json:"topic"`
+	Modes      string `json:"modes"`
+	SeenAt     int64  `json:"seen_at"`
+}
+
+func (q *Queries) UpsertChannelDirectoryEntry(ctx context.Context, arg UpsertChannelDirectoryEntryParams) error {
+	_, err := q.db.ExecContext(ctx, upsertChannelDirectoryEntry,
+		arg.NetworkID,
+		arg.ChannelKey,
+		arg.Channel,
+		arg.Users,
+		arg.Topic,
+		arg.Modes,
+		arg.SeenAt,
+	)
+	return err
+}
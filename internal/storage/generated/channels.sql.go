@@ -14,7 +14,7 @@ import (
 const createChannel = `-- name: CreateChannel :one
 INSERT INTO channels (network_id, name, "key", auto_join, is_open, created_at)
 VALUES (?, ?, ?, ?, ?, ?)
-RETURNING id, network_id, name, topic, modes, "key", auto_join, is_open, created_at, updated_at
+RETURNING id, network_id, name, topic, modes, "key", auto_join, is_open, created_at, updated_at, smart_filter_minutes
 `
 
 type CreateChannelParams struct {
@@ -47,12 +47,13 @@ func (q *Queries) CreateChannel(ctx context.Context, arg CreateChannelParams) (C
 		&i.IsOpen,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SmartFilterMinutes,
 	)
 	return i, err
 }
 
 const getChannelByName = `-- name: GetChannelByName :one
-SELECT id, network_id, name, topic, modes, "key", auto_join, is_open, created_at, updated_at FROM channels WHERE network_id = ? AND LOWER(name) = LOWER(?)
+SELECT id, network_id, name, topic, modes, "key", auto_join, is_open, created_at, updated_at, smart_filter_minutes FROM channels WHERE network_id = ? AND LOWER(name) = LOWER(?)
 `
 
 type GetChannelByNameParams struct {
@@ -74,12 +75,13 @@ func (q *Queries) GetChannelByName(ctx context.Context, arg GetChannelByNamePara
 		&i.IsOpen,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SmartFilterMinutes,
 	)
 	return i, err
 }
 
 const getChannels = `-- name: GetChannels :many
-SELECT id, network_id, name, topic, modes, "key", auto_join, is_open, created_at, updated_at FROM channels WHERE network_id = ? ORDER BY name
+SELECT id, network_id, name, topic, modes, "key", auto_join, is_open, created_at, updated_at, smart_filter_minutes FROM channels WHERE network_id = ? ORDER BY name
 `
 
 func (q *Queries) GetChannels(ctx context.Context, networkID int64) ([]Channel, error) {
@@ -102,6 +104,7 @@ func (q *Queries) GetChannels(ctx context.Context, networkID int64) ([]Channel,
 			&i.IsOpen,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SmartFilterMinutes,
 		); err != nil {
 			return nil, err
 		}
@@ -117,7 +120,7 @@ func (q *Queries) GetChannels(ctx context.Context, networkID int64) ([]Channel,
 }
 
 const getJoinedChannels = `-- name: GetJoinedChannels :many
-SELECT DISTINCT c.id, c.network_id, c.name, c.topic, c.modes, c."key", c.auto_join, c.is_open, c.created_at, c.updated_at 
+SELECT DISTINCT c.id, c.network_id, c.name, c.topic, c.modes, c."key", c.auto_join, c.is_open, c.created_at, c.updated_at, c.smart_filter_minutes 
 FROM channels c
 INNER JOIN channel_users cu ON c.id = cu.channel_id
 WHERE c.network_id = ? AND LOWER(cu.nickname) = LOWER(?)
@@ -149,6 +152,7 @@ func (q *Queries) GetJoinedChannels(ctx context.Context, arg GetJoinedChannelsPa
 			&i.IsOpen,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SmartFilterMinutes,
 		); err != nil {
 			return nil, err
 		}
@@ -164,7 +168,7 @@ func (q *Queries) GetJoinedChannels(ctx context.Context, arg GetJoinedChannelsPa
 }
 
 const getOpenChannels = `-- name: GetOpenChannels :many
-SELECT DISTINCT c.id, c.network_id, c.name, c.topic, c.modes, c."key", c.auto_join, c.is_open, c.created_at, c.updated_at 
+SELECT DISTINCT c.id, c.network_id, c.name, c.topic, c.modes, c."key", c.auto_join, c.is_open, c.created_at, c.updated_at, c.smart_filter_minutes 
 FROM channels c
 LEFT JOIN channel_users cu ON c.id = cu.channel_id AND LOWER(cu.nickname) = LOWER(?)
 WHERE c.network_id = ? AND (c.is_open = 1 OR cu.nickname IS NOT NULL)
@@ -196,6 +200,7 @@ func (q *Queries) GetOpenChannels(ctx context.Context, arg GetOpenChannelsParams
 			&i.IsOpen,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SmartFilterMinutes,
 		); err != nil {
 			return nil, err
 		}
@@ -266,6 +271,20 @@ func (q *Queries) UpdateChannelModes(ctx context.Context, arg UpdateChannelModes
 	return err
 }
 
+const updateChannelSmartFilter = `-- name: UpdateChannelSmartFilter :exec
+UPDATE channels SET smart_filter_minutes = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+type UpdateChannelSmartFilterParams struct {
+	SmartFilterMinutes int64 `json:"smart_filter_minutes"`
+	ID                 int64 `json:"id"`
+}
+
+func (q *Queries) UpdateChannelSmartFilter(ctx context.Context, arg UpdateChannelSmartFilterParams) error {
+	_, err := q.db.ExecContext(ctx, updateChannelSmartFilter, arg.SmartFilterMinutes, arg.ID)
+	return err
+}
+
 const updateChannelTopic = `-- name: UpdateChannelTopic :exec
 UPDATE channels SET topic = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
 `
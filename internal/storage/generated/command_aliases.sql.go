@@ -0,0 +1,80 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: command_aliases.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const deleteCommandAlias = `-- name: DeleteCommandAlias :exec
+DELETE FROM command_aliases
+WHERE COALESCE(network_id, 0) = COALESCE(?1, 0) AND name = ?2
+`
+
+type DeleteCommandAliasParams struct {
+	NetworkID sql.NullInt64 `json:"network_id"`
+	Name      string        `json:"name"`
+}
+
+func (q *Queries) DeleteCommandAlias(ctx context.Context, arg DeleteCommandAliasParams) error {
+	_, err := q.db.ExecContext(ctx, deleteCommandAlias, arg.NetworkID, arg.Name)
+	return err
+}
+
+const listCommandAliases = `-- name: ListCommandAliases :many
+SELECT id, network_id, name, expansion, created_at, updated_at FROM command_aliases
+ORDER BY network_id IS NOT NULL, network_id, name
+`
+
+func (q *Queries) ListCommandAliases(ctx context.Context) ([]CommandAlias, error) {
+	rows, err := q.db.QueryContext(ctx, listCommandAliases)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CommandAlias
+	for rows.Next() {
+		var i CommandAlias
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.Name,
+			&i.Expansion,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertCommandAlias = `-- name: UpsertCommandAlias :exec
+INSERT INTO command_aliases (network_id, name, expansion)
+VALUES (?, ?, ?)
+ON CONFLICT(COALESCE(network_id, 0), name) DO UPDATE SET
+    expansion = excluded.expansion,
+    updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertCommandAliasParams struct {
+	NetworkID sql.NullInt64 `json:"network_id"`
+	Name      string        `json:"name"`
+	Expansion string        `json:"expansion"`
+}
+
+func (q *Queries) UpsertCommandAlias(ctx context.Context, arg UpsertCommandAliasParams) error {
+	_, err := q.db.ExecContext(ctx, upsertCommandAlias, arg.NetworkID, arg.Name, arg.Expansion)
+	return err
+}
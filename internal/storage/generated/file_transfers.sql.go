@@ -30,7 +30,7 @@ func (q *Queries) DeleteFileTransferHistoryEntry(ctx context.Context, transferID
 }
 
 const getFileTransfer = `-- name: GetFileTransfer :one
-SELECT id, transfer_id, network_id, network_name, peer, direction, filename, local_path, partial_path, size_bytes, transferred_bytes, state, error, resumable, created_at, updated_at, finished_at FROM file_transfers WHERE transfer_id = ?
+SELECT id, transfer_id, network_id, network_name, peer, direction, filename, local_path, partial_path, size_bytes, transferred_bytes, state, error, resumable, created_at, updated_at, finished_at, sha256, queue_position FROM file_transfers WHERE transfer_id = ?
 `
 
 func (q *Queries) GetFileTransfer(ctx context.Context, transferID string) (FileTransfer, error) {
@@ -54,12 +54,14 @@ func (q *Queries) GetFileTransfer(ctx context.Context, transferID string) (FileT
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.FinishedAt,
+		&i.Sha256,
+		&i.QueuePosition,
 	)
 	return i, err
 }
 
 const listActiveFileTransfers = `-- name: ListActiveFileTransfers :many
-SELECT id, transfer_id, network_id, network_name, peer, direction, filename, local_path, partial_path, size_bytes, transferred_bytes, state, error, resumable, created_at, updated_at, finished_at FROM file_transfers
+SELECT id, transfer_id, network_id, network_name, peer, direction, filename, local_path, partial_path, size_bytes, transferred_bytes, state, error, resumable, created_at, updated_at, finished_at, sha256, queue_position FROM file_transfers
 WHERE finished_at IS NULL
 ORDER BY created_at ASC, transfer_id ASC
 `
@@ -91,6 +93,8 @@ func (q *Queries) ListActiveFileTransfers(ctx context.Context) ([]FileTransfer,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.FinishedAt,
+			&i.Sha256,
+			&i.QueuePosition,
 		); err != nil {
 			return nil, err
 		}
@@ -106,7 +110,7 @@ func (q *Queries) ListActiveFileTransfers(ctx context.Context) ([]FileTransfer,
 }
 
 const listFileTransferHistory = `-- name: ListFileTransferHistory :many
-SELECT id, transfer_id, network_id, network_name, peer, direction, filename, local_path, partial_path, size_bytes, transferred_bytes, state, error, resumable, created_at, updated_at, finished_at FROM file_transfers
+SELECT id, transfer_id, network_id, network_name, peer, direction, filename, local_path, partial_path, size_bytes, transferred_bytes, state, error, resumable, created_at, updated_at, finished_at, sha256, queue_position FROM file_transfers
 WHERE finished_at IS NOT NULL
   AND (?1 = '' OR direction = ?1)
   AND (?2 = ''
@@ -150,6 +154,8 @@ func (q *Queries) ListFileTransferHistory(ctx context.Context, arg ListFileTrans
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.FinishedAt,
+			&i.Sha256,
+			&i.QueuePosition,
 		); err != nil {
 			return nil, err
 		}
@@ -165,7 +171,7 @@ func (q *Queries) ListFileTransferHistory(ctx context.Context, arg ListFileTrans
 }
 
 const listFileTransferHistoryAfter = `-- name: ListFileTransferHistoryAfter :many
-SELECT id, transfer_id, network_id, network_name, peer, direction, filename, local_path, partial_path, size_bytes, transferred_bytes, state, error, resumable, created_at, updated_at, finished_at FROM file_transfers
+SELECT id, transfer_id, network_id, network_name, peer, direction, filename, local_path, partial_path, size_bytes, transferred_bytes, state, error, resumable, created_at, updated_at, finished_at, sha256, queue_position FROM file_transfers
 WHERE finished_at IS NOT NULL
   AND (finished_at < ?1
        OR (finished_at = ?1 AND transfer_id < ?2))
@@ -219,6 +225,8 @@ func (q *Queries) ListFileTransferHistoryAfter(ctx context.Context, arg ListFile
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.FinishedAt,
+			&i.Sha256,
+			&i.QueuePosition,
 		); err != nil {
 			return nil, err
 		}
@@ -246,8 +254,8 @@ const upsertFileTransfer = `-- name: UpsertFileTransfer :exec
 INSERT INTO file_transfers (
     transfer_id, network_id, network_name, peer, direction, filename,
     local_path, partial_path, size_bytes, transferred_bytes, state, error,
-    resumable, created_at, updated_at, finished_at
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    resumable, created_at, updated_at, finished_at, sha256, queue_position
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(transfer_id) DO UPDATE SET
     network_id = excluded.network_id,
     network_name = excluded.network_name,
@@ -262,7 +270,9 @@ ON CONFLICT(transfer_id) DO UPDATE SET
     error = excluded.error,
     resumable = excluded.resumable,
     updated_at = excluded.updated_at,
-    finished_at = excluded.finished_at
+    finished_at = excluded.finished_at,
+    sha256 = excluded.sha256,
+    queue_position = excluded.queue_position
 `
 
 type UpsertFileTransferParams struct {
@@ -282,6 +292,8 @@ type UpsertFileTransferParams struct {
 	CreatedAt        time.Time     `json:"created_at"`
 	UpdatedAt        time.Time     `json:"updated_at"`
 	FinishedAt       sql.NullTime  `json:"finished_at"`
+	Sha256           string        `json:"sha256"`
+	QueuePosition    int64         `json:"queue_position"`
 }
 
 func (q *Queries) UpsertFileTransfer(ctx context.Context, arg UpsertFileTransferParams) error {
@@ -302,6 +314,8 @@ func (q *Queries) UpsertFileTransfer(ctx context.Context, arg UpsertFileTransfer
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.FinishedAt,
+		arg.Sha256,
+		arg.QueuePosition,
 	)
 	return err
 }
@@ -11,10 +11,19 @@ import (
 	"time"
 )
 
+const clearChannelFiltered = `-- name: ClearChannelFiltered :exec
+UPDATE messages SET filtered = 0 WHERE channel_id = ? AND filtered = 1
+`
+
+func (q *Queries) ClearChannelFiltered(ctx context.Context, channelID sql.NullInt64) error {
+	_, err := q.db.ExecContext(ctx, clearChannelFiltered, channelID)
+	return err
+}
+
 const createMessage = `-- name: CreateMessage :one
-INSERT INTO messages (network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context
+INSERT INTO messages (network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted
 `
 
 type CreateMessageParams struct {
@@ -29,6 +38,10 @@ type CreateMessageParams struct {
 	Msgid          sql.NullString `json:"msgid"`
 	ReplyMsgid     sql.NullString `json:"reply_msgid"`
 	ChannelContext sql.NullString `json:"channel_context"`
+	Plaintext      sql.NullString `json:"plaintext"`
+	Account        sql.NullString `json:"account"`
+	Filtered       bool           `json:"filtered"`
+	Encrypted      bool           `json:"encrypted"`
 }
 
 func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error) {
@@ -44,6 +57,10 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		arg.Msgid,
 		arg.ReplyMsgid,
 		arg.ChannelContext,
+		arg.Plaintext,
+		arg.Account,
+		arg.Filtered,
+		arg.Encrypted,
 	)
 	var i Message
 	err := row.Scan(
@@ -59,12 +76,16 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		&i.Msgid,
 		&i.ReplyMsgid,
 		&i.ChannelContext,
+		&i.Plaintext,
+		&i.Account,
+		&i.Filtered,
+		&i.Encrypted,
 	)
 	return i, err
 }
 
 const getMessageByMsgID = `-- name: GetMessageByMsgID :one
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
 WHERE network_id = ? AND msgid = ?
 LIMIT 1
 `
@@ -90,6 +111,10 @@ func (q *Queries) GetMessageByMsgID(ctx context.Context, arg GetMessageByMsgIDPa
 		&i.Msgid,
 		&i.ReplyMsgid,
 		&i.ChannelContext,
+		&i.Plaintext,
+		&i.Account,
+		&i.Filtered,
+		&i.Encrypted,
 	)
 	return i, err
 }
@@ -110,9 +135,62 @@ func (q *Queries) GetMessageIDByMsgID(ctx context.Context, arg GetMessageIDByMsg
 	return id, err
 }
 
+const getMessagesByAccount = `-- name: GetMessagesByAccount :many
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
+WHERE network_id = ? AND account = ? COLLATE NOCASE
+ORDER BY timestamp DESC
+LIMIT ?
+`
+
+type GetMessagesByAccountParams struct {
+	NetworkID int64          `json:"network_id"`
+	Account   sql.NullString `json:"account"`
+	Limit     int64          `json:"limit"`
+}
+
+func (q *Queries) GetMessagesByAccount(ctx context.Context, arg GetMessagesByAccountParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, getMessagesByAccount, arg.NetworkID, arg.Account, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.ChannelID,
+			&i.User,
+			&i.Message,
+			&i.MessageType,
+			&i.Timestamp,
+			&i.RawLine,
+			&i.PmTarget,
+			&i.Msgid,
+			&i.ReplyMsgid,
+			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMessagesWithChannel = `-- name: GetMessagesWithChannel :many
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages 
-WHERE network_id = ? AND channel_id = ? 
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages 
+WHERE network_id = ? AND channel_id = ? AND filtered = 0
 ORDER BY timestamp DESC 
 LIMIT ?
 `
@@ -145,6 +223,10 @@ func (q *Queries) GetMessagesWithChannel(ctx context.Context, arg GetMessagesWit
 			&i.Msgid,
 			&i.ReplyMsgid,
 			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -160,7 +242,7 @@ func (q *Queries) GetMessagesWithChannel(ctx context.Context, arg GetMessagesWit
 }
 
 const getMessagesWithoutChannel = `-- name: GetMessagesWithoutChannel :many
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
 WHERE network_id = ? AND channel_id IS NULL AND pm_target IS NULL
 ORDER BY timestamp DESC
 LIMIT ?
@@ -193,6 +275,10 @@ func (q *Queries) GetMessagesWithoutChannel(ctx context.Context, arg GetMessages
 			&i.Msgid,
 			&i.ReplyMsgid,
 			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -208,7 +294,7 @@ func (q *Queries) GetMessagesWithoutChannel(ctx context.Context, arg GetMessages
 }
 
 const getPrivateMessages = `-- name: GetPrivateMessages :many
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
 WHERE network_id = ? AND channel_id IS NULL AND message_type IN ('privmsg', 'action', 'notice', 'marker')
 AND LOWER(pm_target) = ?
 ORDER BY timestamp DESC
@@ -243,6 +329,60 @@ func (q *Queries) GetPrivateMessages(ctx context.Context, arg GetPrivateMessages
 			&i.Msgid,
 			&i.ReplyMsgid,
 			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountNicks = `-- name: ListAccountNicks :many
+SELECT user AS nick, COUNT(*) AS messages,
+    CAST(strftime('%s', MIN(timestamp)) AS INTEGER) AS first_seen,
+    CAST(strftime('%s', MAX(timestamp)) AS INTEGER) AS last_seen
+FROM messages
+WHERE network_id = ? AND account = ? COLLATE NOCASE
+GROUP BY user
+ORDER BY MAX(timestamp) DESC
+`
+
+type ListAccountNicksParams struct {
+	NetworkID int64          `json:"network_id"`
+	Account   sql.NullString `json:"account"`
+}
+
+type ListAccountNicksRow struct {
+	Nick      string `json:"nick"`
+	Messages  int64  `json:"messages"`
+	FirstSeen int64  `json:"first_seen"`
+	LastSeen  int64  `json:"last_seen"`
+}
+
+func (q *Queries) ListAccountNicks(ctx context.Context, arg ListAccountNicksParams) ([]ListAccountNicksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountNicks, arg.NetworkID, arg.Account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountNicksRow
+	for rows.Next() {
+		var i ListAccountNicksRow
+		if err := rows.Scan(
+			&i.Nick,
+			&i.Messages,
+			&i.FirstSeen,
+			&i.LastSeen,
 		); err != nil {
 			return nil, err
 		}
@@ -256,3 +396,28 @@ func (q *Queries) GetPrivateMessages(ctx context.Context, arg GetPrivateMessages
 	}
 	return items, nil
 }
+
+const unfilterLatestJoin = `-- name: UnfilterLatestJoin :one
+UPDATE messages SET filtered = 0
+WHERE id = (
+    SELECT m.id FROM messages m
+    WHERE m.channel_id = ? AND m.user = ? COLLATE NOCASE AND m.message_type = 'join'
+      AND m.filtered = 1 AND m.timestamp >= ?
+    ORDER BY m.timestamp DESC, m.id DESC
+    LIMIT 1
+)
+RETURNING id
+`
+
+type UnfilterLatestJoinParams struct {
+	ChannelID sql.NullInt64 `json:"channel_id"`
+	User      string        `json:"user"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+func (q *Queries) UnfilterLatestJoin(ctx context.Context, arg UnfilterLatestJoinParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, unfilterLatestJoin, arg.ChannelID, arg.User, arg.Timestamp)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
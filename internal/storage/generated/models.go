@@ -33,16 +33,74 @@ type ActivityItem struct {
 }
 
 type Channel struct {
-	ID        int64          `json:"id"`
-	NetworkID int64          `json:"network_id"`
-	Name      string         `json:"name"`
-	Topic     sql.NullString `json:"topic"`
-	Modes     sql.NullString `json:"modes"`
-	Key       string         `json:"key"`
-	AutoJoin  bool           `json:"auto_join"`
-	IsOpen    bool           `json:"is_open"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt sql.NullTime   `json:"updated_at"`
+	ID                 int64          `json:"id"`
+	NetworkID          int64          `json:"network_id"`
+	Name               string         `json:"name"`
+	Topic              sql.NullString `json:"topic"`
+	Modes              sql.NullString `json:"modes"`
+	Key                string         `json:"key"`
+	AutoJoin           bool           `json:"auto_join"`
+	IsOpen             bool           `json:"is_open"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          sql.NullTime   `json:"updated_at"`
+	SmartFilterMinutes int64          `json:"smart_filter_minutes"`
+}
+
+type ChannelDirectory struct {
+	ID         int64  `json:"id"`
+	NetworkID  int64  `json:"network_id"`
+	ChannelKey string `json:"channel_key"`
+	Channel    string `json:"channel"`
+	Users      int64  `json:"users"`
+	Topic      string `json:"topic"`
+	Modes      string `json:"modes"`
+	FirstSeen  int64  `json:"first_seen"`
+	LastSeen   int64  `json:"last_seen"`
+}
+
+type ChannelDirectoryFetch struct {
+	NetworkID         int64  `json:"network_id"`
+	FetchedAt         int64  `json:"fetched_at"`
+	PreviousFetchedAt int64  `json:"previous_fetched_at"`
+	FullFetchedAt     int64  `json:"full_fetched_at"`
+	Filter            string `json:"filter"`
+}
+
+type ChannelDirectoryFt struct {
+	Channel string `json:"channel"`
+	Topic   string `json:"topic"`
+}
+
+type ChannelListEntry struct {
+	ID         int64        `json:"id"`
+	NetworkID  int64        `json:"network_id"`
+	Channel    string       `json:"channel"`
+	ChannelKey string       `json:"channel_key"`
+	Mode       string       `json:"mode"`
+	Mask       string       `json:"mask"`
+	SetBy      string       `json:"set_by"`
+	SetAt      sql.NullTime `json:"set_at"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+}
+
+type ChannelStatsHourly struct {
+	NetworkID int64  `json:"network_id"`
+	ChannelID int64  `json:"channel_id"`
+	NickKey   string `json:"nick_key"`
+	Nick      string `json:"nick"`
+	Hour      int64  `json:"hour"`
+	Lines     int64  `json:"lines"`
+	Words     int64  `json:"words"`
+	Urls      int64  `json:"urls"`
+	Joins     int64  `json:"joins"`
+	Parts     int64  `json:"parts"`
+	FirstSeen int64  `json:"first_seen"`
+	LastSeen  int64  `json:"last_seen"`
+}
+
+type ChannelStatsProgress struct {
+	ID            int64 `json:"id"`
+	LastMessageID int64 `json:"last_message_id"`
 }
 
 type ChannelUser struct {
@@ -54,6 +112,15 @@ type ChannelUser struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 }
 
+type CommandAlias struct {
+	ID        int64         `json:"id"`
+	NetworkID sql.NullInt64 `json:"network_id"`
+	Name      string        `json:"name"`
+	Expansion string        `json:"expansion"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
 type FileTransfer struct {
 	ID               int64         `json:"id"`
 	TransferID       string        `json:"transfer_id"`
@@ -72,6 +139,8 @@ type FileTransfer struct {
 	CreatedAt        time.Time     `json:"created_at"`
 	UpdatedAt        time.Time     `json:"updated_at"`
 	FinishedAt       sql.NullTime  `json:"finished_at"`
+	Sha256           string        `json:"sha256"`
+	QueuePosition    int64         `json:"queue_position"`
 }
 
 type LinkPreview struct {
@@ -97,11 +166,15 @@ type Message struct {
 	Msgid          sql.NullString `json:"msgid"`
 	ReplyMsgid     sql.NullString `json:"reply_msgid"`
 	ChannelContext sql.NullString `json:"channel_context"`
+	Plaintext      sql.NullString `json:"plaintext"`
+	Account        sql.NullString `json:"account"`
+	Filtered       bool           `json:"filtered"`
+	Encrypted      bool           `json:"encrypted"`
 }
 
 type MessagesFt struct {
-	Message string `json:"message"`
-	User    string `json:"user"`
+	Plaintext string `json:"plaintext"`
+	User      string `json:"user"`
 }
 
 type MonitoredNick struct {
@@ -131,10 +204,20 @@ type Network struct {
 	Color            sql.NullString `json:"color"`
 	IconPath         sql.NullString `json:"icon_path"`
 	SortOrder        int64          `json:"sort_order"`
+	FloodProfile     string         `json:"flood_profile"`
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 }
 
+type PerformStep struct {
+	ID        int64  `json:"id"`
+	NetworkID int64  `json:"network_id"`
+	Position  int64  `json:"position"`
+	Kind      string `json:"kind"`
+	Value     string `json:"value"`
+	Seconds   int64  `json:"seconds"`
+}
+
 type PinnedMessage struct {
 	MessageID int64         `json:"message_id"`
 	NetworkID int64         `json:"network_id"`
@@ -161,11 +244,36 @@ type PrivateMessageConversation struct {
 	UpdatedAt  sql.NullTime `json:"updated_at"`
 }
 
+type ScheduledMessage struct {
+	ID        int64  `json:"id"`
+	NetworkID int64  `json:"network_id"`
+	Target    string `json:"target"`
+	Message   string `json:"message"`
+	SendAt    int64  `json:"send_at"`
+	Status    string `json:"status"`
+	Attempts  int64  `json:"attempts"`
+	LastError string `json:"last_error"`
+	CreatedAt int64  `json:"created_at"`
+}
+
 type ScriptState struct {
 	ScriptID string `json:"script_id"`
 	Enabled  int64  `json:"enabled"`
 }
 
+type SeenNick struct {
+	NetworkID int64  `json:"network_id"`
+	NickKey   string `json:"nick_key"`
+	Nick      string `json:"nick"`
+	Action    string `json:"action"`
+	Channel   string `json:"channel"`
+	Target    string `json:"target"`
+	Message   string `json:"message"`
+	Host      string `json:"host"`
+	Account   string `json:"account"`
+	SeenAt    int64  `json:"seen_at"`
+}
+
 type Server struct {
 	ID        int64     `json:"id"`
 	NetworkID int64     `json:"network_id"`
@@ -21,9 +21,9 @@ func (q *Queries) ClearNetworkIcon(ctx context.Context, id int64) error {
 }
 
 const createNetwork = `-- name: CreateNetwork :one
-INSERT INTO networks (name, address, port, tls, nickname, username, realname, password, sasl_enabled, sasl_mechanism, sasl_username, sasl_password, sasl_external_cert, auto_connect, identify_as_bot, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, name, address, port, tls, nickname, username, realname, password, sasl_enabled, sasl_mechanism, sasl_username, sasl_password, sasl_external_cert, auto_connect, identify_as_bot, color, icon_path, sort_order, created_at, updated_at
+INSERT INTO networks (name, address, port, tls, nickname, username, realname, password, sasl_enabled, sasl_mechanism, sasl_username, sasl_password, sasl_external_cert, auto_connect, identify_as_bot, flood_profile, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, name, address, port, tls, nickname, username, realname, password, sasl_enabled, sasl_mechanism, sasl_username, sasl_password, sasl_external_cert, auto_connect, identify_as_bot, color, icon_path, sort_order, flood_profile, created_at, updated_at
 `
 
 type CreateNetworkParams struct {
@@ -42,6 +42,7 @@ type CreateNetworkParams struct {
 	SaslExternalCert sql.NullString `json:"sasl_external_cert"`
 	AutoConnect      bool           `json:"auto_connect"`
 	IdentifyAsBot    bool           `json:"identify_as_bot"`
+	FloodProfile     string         `json:"flood_profile"`
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 }
@@ -63,6 +64,7 @@ func (q *Queries) CreateNetwork(ctx context.Context, arg CreateNetworkParams) (N
 		arg.SaslExternalCert,
 		arg.AutoConnect,
 		arg.IdentifyAsBot,
+		arg.FloodProfile,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 	)
@@ -87,6 +89,7 @@ func (q *Queries) CreateNetwork(ctx context.Context, arg CreateNetworkParams) (N
 		&i.Color,
 		&i.IconPath,
 		&i.SortOrder,
+		&i.FloodProfile,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -103,7 +106,7 @@ func (q *Queries) DeleteNetwork(ctx context.Context, id int64) error {
 }
 
 const getNetwork = `-- name: GetNetwork :one
-SELECT id, name, address, port, tls, nickname, username, realname, password, sasl_enabled, sasl_mechanism, sasl_username, sasl_password, sasl_external_cert, auto_connect, identify_as_bot, color, icon_path, sort_order, created_at, updated_at FROM networks WHERE id = ?
+SELECT id, name, address, port, tls, nickname, username, realname, password, sasl_enabled, sasl_mechanism, sasl_username, sasl_password, sasl_external_cert, auto_connect, identify_as_bot, color, icon_path, sort_order, flood_profile, created_at, updated_at FROM networks WHERE id = ?
 `
 
 func (q *Queries) GetNetwork(ctx context.Context, id int64) (Network, error) {
@@ -129,6 +132,7 @@ func (q *Queries) GetNetwork(ctx context.Context, id int64) (Network, error) {
 		&i.Color,
 		&i.IconPath,
 		&i.SortOrder,
+		&i.FloodProfile,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -136,7 +140,7 @@ func (q *Queries) GetNetwork(ctx context.Context, id int64) (Network, error) {
 }
 
 const getNetworks = `-- name: GetNetworks :many
-SELECT id, name, address, port, tls, nickname, username, realname, password, sasl_enabled, sasl_mechanism, sasl_username, sasl_password, sasl_external_cert, auto_connect, identify_as_bot, color, icon_path, sort_order, created_at, updated_at FROM networks ORDER BY sort_order, id
+SELECT id, name, address, port, tls, nickname, username, realname, password, sasl_enabled, sasl_mechanism, sasl_username, sasl_password, sasl_external_cert, auto_connect, identify_as_bot, color, icon_path, sort_order, flood_profile, created_at, updated_at FROM networks ORDER BY sort_order, id
 `
 
 func (q *Queries) GetNetworks(ctx context.Context) ([]Network, error) {
@@ -168,6 +172,7 @@ func (q *Queries) GetNetworks(ctx context.Context) ([]Network, error) {
 			&i.Color,
 			&i.IconPath,
 			&i.SortOrder,
+			&i.FloodProfile,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -190,7 +195,7 @@ SET name = ?, address = ?, port = ?, tls = ?,
     nickname = ?, username = ?, realname = ?,
     password = ?, sasl_enabled = ?, sasl_mechanism = ?,
     sasl_username = ?, sasl_password = ?, sasl_external_cert = ?,
-    auto_connect = ?, identify_as_bot = ?, updated_at = ?
+    auto_connect = ?, identify_as_bot = ?, flood_profile = ?, updated_at = ?
 WHERE id = ?
 `
 
@@ -210,6 +215,7 @@ type UpdateNetworkParams struct {
 	SaslExternalCert sql.NullString `json:"sasl_external_cert"`
 	AutoConnect      bool           `json:"auto_connect"`
 	IdentifyAsBot    bool           `json:"identify_as_bot"`
+	FloodProfile     string         `json:"flood_profile"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 	ID               int64          `json:"id"`
 }
@@ -231,6 +237,7 @@ func (q *Queries) UpdateNetwork(ctx context.Context, arg UpdateNetworkParams) er
 		arg.SaslExternalCert,
 		arg.AutoConnect,
 		arg.IdentifyAsBot,
+		arg.FloodProfile,
 		arg.UpdatedAt,
 		arg.ID,
 	)
@@ -0,0 +1,77 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: perform_steps.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deletePerformSteps = `-- name: DeletePerformSteps :exec
+DELETE FROM perform_steps WHERE network_id = ?
+`
+
+func (q *Queries) DeletePerformSteps(ctx context.Context, networkID int64) error {
+	_, err := q.db.ExecContext(ctx, deletePerformSteps, networkID)
+	return err
+}
+
+const insertPerformStep = `-- name: InsertPerformStep :exec
+INSERT INTO perform_steps (network_id, position, kind, value, seconds)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type InsertPerformStepParams struct {
+	NetworkID int64  `json:"network_id"`
+	Position  int64  `json:"position"`
+	Kind      string `json:"kind"`
+	Value     string `json:"value"`
+	Seconds   int64  `json:"seconds"`
+}
+
+func (q *Queries) InsertPerformStep(ctx context.Context, arg InsertPerformStepParams) error {
+	_, err := q.db.ExecContext(ctx, insertPerformStep,
+		arg.NetworkID,
+		arg.Position,
+		arg.Kind,
+		arg.Value,
+		arg.Seconds,
+	)
+	return err
+}
+
+const listPerformSteps = `-- name: ListPerformSteps :many
+SELECT id, network_id, position, kind, value, seconds FROM perform_steps WHERE network_id = ? ORDER BY position
+`
+
+func (q *Queries) ListPerformSteps(ctx context.Context, networkID int64) ([]PerformStep, error) {
+	rows, err := q.db.QueryContext(ctx, listPerformSteps, networkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PerformStep
+	for rows.Next() {
+		var i PerformStep
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.Position,
+			&i.Kind,
+			&i.Value,
+			&i.Seconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
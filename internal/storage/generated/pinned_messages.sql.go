@@ -11,9 +11,185 @@ import (
 	"time"
 )
 
+const getMessagesAfterTimePM = `-- name: GetMessagesAfterTimePM :many
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
+WHERE network_id = ? AND channel_id IS NULL
+  AND message_type IN ('privmsg', 'action', 'notice', 'marker')
+  AND LOWER(pm_target) = ? AND timestamp > ?
+ORDER BY timestamp ASC, id ASC
+LIMIT ?
+`
+
+type GetMessagesAfterTimePMParams struct {
+	NetworkID int64          `json:"network_id"`
+	PmTarget  sql.NullString `json:"pm_target"`
+	Timestamp time.Time      `json:"timestamp"`
+	Limit     int64          `json:"limit"`
+}
+
+func (q *Queries) GetMessagesAfterTimePM(ctx context.Context, arg GetMessagesAfterTimePMParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, getMessagesAfterTimePM,
+		arg.NetworkID,
+		arg.PmTarget,
+		arg.Timestamp,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.ChannelID,
+			&i.User,
+			&i.Message,
+			&i.MessageType,
+			&i.Timestamp,
+			&i.RawLine,
+			&i.PmTarget,
+			&i.Msgid,
+			&i.ReplyMsgid,
+			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMessagesAfterTimeWithChannel = `-- name: GetMessagesAfterTimeWithChannel :many
+
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
+WHERE network_id = ? AND channel_id = ? AND filtered = 0 AND timestamp > ?
+ORDER BY timestamp ASC, id ASC
+LIMIT ?
+`
+
+type GetMessagesAfterTimeWithChannelParams struct {
+	NetworkID int64         `json:"network_id"`
+	ChannelID sql.NullInt64 `json:"channel_id"`
+	Timestamp time.Time     `json:"timestamp"`
+	Limit     int64         `json:"limit"`
+}
+
+// Timestamp-keyed "after" pagination, the newer-direction counterpart (used to
+// answer a bouncer client's CHATHISTORY AFTER/BETWEEN).
+func (q *Queries) GetMessagesAfterTimeWithChannel(ctx context.Context, arg GetMessagesAfterTimeWithChannelParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, getMessagesAfterTimeWithChannel,
+		arg.NetworkID,
+		arg.ChannelID,
+		arg.Timestamp,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.ChannelID,
+			&i.User,
+			&i.Message,
+			&i.MessageType,
+			&i.Timestamp,
+			&i.RawLine,
+			&i.PmTarget,
+			&i.Msgid,
+			&i.ReplyMsgid,
+			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMessagesAfterTimeWithoutChannel = `-- name: GetMessagesAfterTimeWithoutChannel :many
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
+WHERE network_id = ? AND channel_id IS NULL AND pm_target IS NULL AND timestamp > ?
+ORDER BY timestamp ASC, id ASC
+LIMIT ?
+`
+
+type GetMessagesAfterTimeWithoutChannelParams struct {
+	NetworkID int64     `json:"network_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Limit     int64     `json:"limit"`
+}
+
+func (q *Queries) GetMessagesAfterTimeWithoutChannel(ctx context.Context, arg GetMessagesAfterTimeWithoutChannelParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, getMessagesAfterTimeWithoutChannel, arg.NetworkID, arg.Timestamp, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.ChannelID,
+			&i.User,
+			&i.Message,
+			&i.MessageType,
+			&i.Timestamp,
+			&i.RawLine,
+			&i.PmTarget,
+			&i.Msgid,
+			&i.ReplyMsgid,
+			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMessagesAfterWithChannel = `-- name: GetMessagesAfterWithChannel :many
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages
-WHERE network_id = ? AND channel_id = ? AND id > ?
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
+WHERE network_id = ? AND channel_id = ? AND filtered = 0 AND id > ?
 ORDER BY id ASC
 LIMIT ?
 `
@@ -52,6 +228,10 @@ func (q *Queries) GetMessagesAfterWithChannel(ctx context.Context, arg GetMessag
 			&i.Msgid,
 			&i.ReplyMsgid,
 			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -67,7 +247,7 @@ func (q *Queries) GetMessagesAfterWithChannel(ctx context.Context, arg GetMessag
 }
 
 const getMessagesAfterWithoutChannel = `-- name: GetMessagesAfterWithoutChannel :many
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
 WHERE network_id = ? AND channel_id IS NULL AND pm_target IS NULL AND id > ?
 ORDER BY id ASC
 LIMIT ?
@@ -101,6 +281,10 @@ func (q *Queries) GetMessagesAfterWithoutChannel(ctx context.Context, arg GetMes
 			&i.Msgid,
 			&i.ReplyMsgid,
 			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -116,7 +300,7 @@ func (q *Queries) GetMessagesAfterWithoutChannel(ctx context.Context, arg GetMes
 }
 
 const getMessagesBeforeTimePM = `-- name: GetMessagesBeforeTimePM :many
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
 WHERE network_id = ? AND channel_id IS NULL
   AND message_type IN ('privmsg', 'action', 'notice', 'marker')
   AND LOWER(pm_target) = ? AND timestamp < ?
@@ -158,6 +342,10 @@ func (q *Queries) GetMessagesBeforeTimePM(ctx context.Context, arg GetMessagesBe
 			&i.Msgid,
 			&i.ReplyMsgid,
 			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -174,8 +362,8 @@ func (q *Queries) GetMessagesBeforeTimePM(ctx context.Context, arg GetMessagesBe
 
 const getMessagesBeforeTimeWithChannel = `-- name: GetMessagesBeforeTimeWithChannel :many
 
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages
-WHERE network_id = ? AND channel_id = ? AND timestamp < ?
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
+WHERE network_id = ? AND channel_id = ? AND filtered = 0 AND timestamp < ?
 ORDER BY timestamp DESC, id DESC
 LIMIT ?
 `
@@ -218,6 +406,10 @@ func (q *Queries) GetMessagesBeforeTimeWithChannel(ctx context.Context, arg GetM
 			&i.Msgid,
 			&i.ReplyMsgid,
 			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -233,7 +425,7 @@ func (q *Queries) GetMessagesBeforeTimeWithChannel(ctx context.Context, arg GetM
 }
 
 const getMessagesBeforeTimeWithoutChannel = `-- name: GetMessagesBeforeTimeWithoutChannel :many
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
 WHERE network_id = ? AND channel_id IS NULL AND pm_target IS NULL AND timestamp < ?
 ORDER BY timestamp DESC, id DESC
 LIMIT ?
@@ -267,6 +459,10 @@ func (q *Queries) GetMessagesBeforeTimeWithoutChannel(ctx context.Context, arg G
 			&i.Msgid,
 			&i.ReplyMsgid,
 			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -282,8 +478,8 @@ func (q *Queries) GetMessagesBeforeTimeWithoutChannel(ctx context.Context, arg G
 }
 
 const getMessagesBeforeWithChannel = `-- name: GetMessagesBeforeWithChannel :many
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages
-WHERE network_id = ? AND channel_id = ? AND id <= ?
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
+WHERE network_id = ? AND channel_id = ? AND filtered = 0 AND id <= ?
 ORDER BY id DESC
 LIMIT ?
 `
@@ -322,6 +518,10 @@ func (q *Queries) GetMessagesBeforeWithChannel(ctx context.Context, arg GetMessa
 			&i.Msgid,
 			&i.ReplyMsgid,
 			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -337,7 +537,7 @@ func (q *Queries) GetMessagesBeforeWithChannel(ctx context.Context, arg GetMessa
 }
 
 const getMessagesBeforeWithoutChannel = `-- name: GetMessagesBeforeWithoutChannel :many
-SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context FROM messages
+SELECT id, network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted FROM messages
 WHERE network_id = ? AND channel_id IS NULL AND pm_target IS NULL AND id <= ?
 ORDER BY id DESC
 LIMIT ?
@@ -371,6 +571,10 @@ func (q *Queries) GetMessagesBeforeWithoutChannel(ctx context.Context, arg GetMe
 			&i.Msgid,
 			&i.ReplyMsgid,
 			&i.ChannelContext,
+			&i.Plaintext,
+			&i.Account,
+			&i.Filtered,
+			&i.Encrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -10,9 +10,11 @@ import (
 )
 
 type Querier interface {
+	AddChannelStatsHour(ctx context.Context, arg AddChannelStatsHourParams) error
 	AddChannelUser(ctx context.Context, arg AddChannelUserParams) error
 	AddIgnoredSender(ctx context.Context, arg AddIgnoredSenderParams) error
 	AddMonitoredNick(ctx context.Context, arg AddMonitoredNickParams) error
+	ClearChannelFiltered(ctx context.Context, channelID sql.NullInt64) error
 	ClearChannelUsers(ctx context.Context, channelID int64) error
 	ClearFileTransferHistory(ctx context.Context) error
 	ClearNetworkChannelUsers(ctx context.Context, networkID int64) error
@@ -23,21 +25,31 @@ type Querier interface {
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
 	CreateNetwork(ctx context.Context, arg CreateNetworkParams) (Network, error)
 	CreatePMConversation(ctx context.Context, arg CreatePMConversationParams) (PrivateMessageConversation, error)
+	CreateScheduledMessage(ctx context.Context, arg CreateScheduledMessageParams) (ScheduledMessage, error)
 	CreateServer(ctx context.Context, arg CreateServerParams) (Server, error)
 	DeleteActivityFromSender(ctx context.Context, arg DeleteActivityFromSenderParams) error
 	DeleteActivityItem(ctx context.Context, id int64) error
 	DeleteAllActivityItems(ctx context.Context) error
 	DeleteAllServers(ctx context.Context, networkID int64) error
+	DeleteChannelDirectoryUnseenSince(ctx context.Context, arg DeleteChannelDirectoryUnseenSinceParams) (int64, error)
+	DeleteChannelListEntry(ctx context.Context, arg DeleteChannelListEntryParams) error
+	DeleteCommandAlias(ctx context.Context, arg DeleteCommandAliasParams) error
 	DeleteExpiredInviteActivity(ctx context.Context, expiresAt sql.NullTime) error
 	DeleteFileTransferHistoryEntry(ctx context.Context, transferID string) error
 	DeleteInviteActivity(ctx context.Context, arg DeleteInviteActivityParams) error
 	DeleteInviteActivityFromSender(ctx context.Context, arg DeleteInviteActivityFromSenderParams) error
 	DeleteNetwork(ctx context.Context, id int64) error
+	DeletePerformSteps(ctx context.Context, networkID int64) error
 	DeleteSTSPolicy(ctx context.Context, hostname string) error
+	DeleteScheduledMessage(ctx context.Context, arg DeleteScheduledMessageParams) (int64, error)
 	DeleteSeenActivityItems(ctx context.Context) error
 	DeleteServer(ctx context.Context, id int64) error
+	FailScheduledMessage(ctx context.Context, arg FailScheduledMessageParams) error
 	GetAllPluginConfigs(ctx context.Context) ([]PluginConfig, error)
 	GetChannelByName(ctx context.Context, arg GetChannelByNameParams) (Channel, error)
+	GetChannelDirectoryFetch(ctx context.Context, networkID int64) (ChannelDirectoryFetch, error)
+	GetChannelStatsNick(ctx context.Context, arg GetChannelStatsNickParams) (GetChannelStatsNickRow, error)
+	GetChannelStatsProgress(ctx context.Context) (int64, error)
 	GetChannelUserModes(ctx context.Context, arg GetChannelUserModesParams) (sql.NullString, error)
 	GetChannelUsers(ctx context.Context, channelID int64) ([]ChannelUser, error)
 	GetChannels(ctx context.Context, networkID int64) ([]Channel, error)
@@ -48,6 +60,11 @@ type Querier interface {
 	GetLinkPreview(ctx context.Context, url string) (LinkPreview, error)
 	GetMessageByMsgID(ctx context.Context, arg GetMessageByMsgIDParams) (Message, error)
 	GetMessageIDByMsgID(ctx context.Context, arg GetMessageIDByMsgIDParams) (int64, error)
+	GetMessagesAfterTimePM(ctx context.Context, arg GetMessagesAfterTimePMParams) ([]Message, error)
+	// Timestamp-keyed "after" pagination, the newer-direction counterpart (used to
+	// answer a bouncer client's CHATHISTORY AFTER/BETWEEN).
+	GetMessagesAfterTimeWithChannel(ctx context.Context, arg GetMessagesAfterTimeWithChannelParams) ([]Message, error)
+	GetMessagesAfterTimeWithoutChannel(ctx context.Context, arg GetMessagesAfterTimeWithoutChannelParams) ([]Message, error)
 	GetMessagesAfterWithChannel(ctx context.Context, arg GetMessagesAfterWithChannelParams) ([]Message, error)
 	GetMessagesAfterWithoutChannel(ctx context.Context, arg GetMessagesAfterWithoutChannelParams) ([]Message, error)
 	GetMessagesBeforeTimePM(ctx context.Context, arg GetMessagesBeforeTimePMParams) ([]Message, error)
@@ -59,6 +76,7 @@ type Querier interface {
 	GetMessagesBeforeTimeWithoutChannel(ctx context.Context, arg GetMessagesBeforeTimeWithoutChannelParams) ([]Message, error)
 	GetMessagesBeforeWithChannel(ctx context.Context, arg GetMessagesBeforeWithChannelParams) ([]Message, error)
 	GetMessagesBeforeWithoutChannel(ctx context.Context, arg GetMessagesBeforeWithoutChannelParams) ([]Message, error)
+	GetMessagesByAccount(ctx context.Context, arg GetMessagesByAccountParams) ([]Message, error)
 	GetMessagesWithChannel(ctx context.Context, arg GetMessagesWithChannelParams) ([]Message, error)
 	GetMessagesWithoutChannel(ctx context.Context, arg GetMessagesWithoutChannelParams) ([]Message, error)
 	GetMonitoredNicks(ctx context.Context, networkID int64) ([]MonitoredNick, error)
@@ -75,34 +93,54 @@ type Querier interface {
 	GetPrivateMessages(ctx context.Context, arg GetPrivateMessagesParams) ([]Message, error)
 	GetSTSPolicies(ctx context.Context) ([]StsPolicy, error)
 	GetSTSPolicy(ctx context.Context, hostname string) (StsPolicy, error)
+	GetSeenNick(ctx context.Context, arg GetSeenNickParams) (SeenNick, error)
 	GetServers(ctx context.Context, networkID int64) ([]Server, error)
 	GetSetting(ctx context.Context, key string) (string, error)
+	InsertPerformStep(ctx context.Context, arg InsertPerformStepParams) error
+	ListAccountNicks(ctx context.Context, arg ListAccountNicksParams) ([]ListAccountNicksRow, error)
 	ListActiveFileTransfers(ctx context.Context) ([]FileTransfer, error)
 	ListActivityItems(ctx context.Context, limit int64) ([]ActivityItem, error)
 	ListAllIgnoredSenders(ctx context.Context) ([]ListAllIgnoredSendersRow, error)
+	ListChannelListEntries(ctx context.Context, arg ListChannelListEntriesParams) ([]ChannelListEntry, error)
+	ListChannelStatsHours(ctx context.Context, arg ListChannelStatsHoursParams) ([]ListChannelStatsHoursRow, error)
+	ListChannelStatsNicks(ctx context.Context, arg ListChannelStatsNicksParams) ([]ListChannelStatsNicksRow, error)
+	ListCommandAliases(ctx context.Context) ([]CommandAlias, error)
 	ListDisabledScripts(ctx context.Context) ([]string, error)
+	ListDueScheduledMessages(ctx context.Context, sendAt int64) ([]ScheduledMessage, error)
+	ListExpiredChannelListEntries(ctx context.Context, expiresAt sql.NullTime) ([]ChannelListEntry, error)
 	ListFileTransferHistory(ctx context.Context, arg ListFileTransferHistoryParams) ([]FileTransfer, error)
 	ListFileTransferHistoryAfter(ctx context.Context, arg ListFileTransferHistoryAfterParams) ([]FileTransfer, error)
 	ListIgnoredSendersByNetwork(ctx context.Context, networkID int64) ([]string, error)
 	ListInviteActivity(ctx context.Context, arg ListInviteActivityParams) ([]ActivityItem, error)
+	ListMessagesAfter(ctx context.Context, arg ListMessagesAfterParams) ([]ListMessagesAfterRow, error)
+	ListPerformSteps(ctx context.Context, networkID int64) ([]PerformStep, error)
+	ListScheduledMessages(ctx context.Context, networkID int64) ([]ScheduledMessage, error)
+	ListSeenNicksByIdentity(ctx context.Context, arg ListSeenNicksByIdentityParams) ([]SeenNick, error)
+	ListSettings(ctx context.Context) ([]ListSettingsRow, error)
 	MarkActivityItemSeen(ctx context.Context, id int64) error
 	MarkAllActivityItemsSeen(ctx context.Context) error
 	NetworksWithExpiredInvites(ctx context.Context, expiresAt sql.NullTime) ([]int64, error)
 	PinMessage(ctx context.Context, arg PinMessageParams) error
 	PruneFileTransferHistory(ctx context.Context, finishedAt sql.NullTime) error
 	PruneLinkPreviewsToLimit(ctx context.Context, offset int64) error
+	RecordChannelDirectoryFetch(ctx context.Context, arg RecordChannelDirectoryFetchParams) error
+	RecordScheduledMessageAttempt(ctx context.Context, arg RecordScheduledMessageAttemptParams) error
 	RemoveChannelUser(ctx context.Context, arg RemoveChannelUserParams) error
 	RemoveIgnoredSender(ctx context.Context, arg RemoveIgnoredSenderParams) error
 	RemoveMonitoredNick(ctx context.Context, arg RemoveMonitoredNickParams) error
+	SetChannelListEntryExpiry(ctx context.Context, arg SetChannelListEntryExpiryParams) error
+	SetChannelStatsProgress(ctx context.Context, lastMessageID int64) error
 	SetPluginConfig(ctx context.Context, arg SetPluginConfigParams) error
 	SetPluginConfigSchema(ctx context.Context, arg SetPluginConfigSchemaParams) error
 	SetPluginEnabled(ctx context.Context, arg SetPluginEnabledParams) error
 	SetSetting(ctx context.Context, arg SetSettingParams) error
+	UnfilterLatestJoin(ctx context.Context, arg UnfilterLatestJoinParams) (int64, error)
 	UnpinMessage(ctx context.Context, messageID int64) error
 	UpdateChannelAutoJoin(ctx context.Context, arg UpdateChannelAutoJoinParams) error
 	UpdateChannelIsOpen(ctx context.Context, arg UpdateChannelIsOpenParams) error
 	UpdateChannelKey(ctx context.Context, arg UpdateChannelKeyParams) error
 	UpdateChannelModes(ctx context.Context, arg UpdateChannelModesParams) error
+	UpdateChannelSmartFilter(ctx context.Context, arg UpdateChannelSmartFilterParams) error
 	UpdateChannelTopic(ctx context.Context, arg UpdateChannelTopicParams) error
 	// OR REPLACE so a rename that collides with an existing holder of the new nick
 	// (e.g. a just-freed ghost still listed in a shared channel during a REGAIN)
@@ -115,10 +153,14 @@ type Querier interface {
 	UpdateNetworkSortOrder(ctx context.Context, arg UpdateNetworkSortOrderParams) error
 	UpdatePMConversationIsOpen(ctx context.Context, arg UpdatePMConversationIsOpenParams) error
 	UpdateServer(ctx context.Context, arg UpdateServerParams) error
+	UpsertChannelDirectoryEntry(ctx context.Context, arg UpsertChannelDirectoryEntryParams) error
+	UpsertChannelListEntry(ctx context.Context, arg UpsertChannelListEntryParams) error
+	UpsertCommandAlias(ctx context.Context, arg UpsertCommandAliasParams) error
 	UpsertFileTransfer(ctx context.Context, arg UpsertFileTransferParams) error
 	UpsertLinkPreview(ctx context.Context, arg UpsertLinkPreviewParams) error
 	UpsertSTSPolicy(ctx context.Context, arg UpsertSTSPolicyParams) error
 	UpsertScriptEnabled(ctx context.Context, arg UpsertScriptEnabledParams) error
+	UpsertSeenNick(ctx context.Context, arg UpsertSeenNickParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,170 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: scheduled_messages.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createScheduledMessage = `-- name: CreateScheduledMessage :one
+INSERT INTO scheduled_messages (network_id, target, message, send_at, created_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, network_id, target, message, send_at, status, attempts, last_error, created_at
+`
+
+type CreateScheduledMessageParams struct {
+	NetworkID int64  `json:"network_id"`
+	Target    string `json:"target"`
+	Message   string `json:"message"`
+	SendAt    int64  `json:"send_at"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+func (q *Queries) CreateScheduledMessage(ctx context.Context, arg CreateScheduledMessageParams) (ScheduledMessage, error) {
+	row := q.db.QueryRowContext(ctx, createScheduledMessage,
+		arg.NetworkID,
+		arg.Target,
+		arg.Message,
+		arg.SendAt,
+		arg.CreatedAt,
+	)
+	var i ScheduledMessage
+	err := row.Scan(
+		&i.ID,
+		&i.NetworkID,
+		&i.Target,
+		&i.Message,
+		&i.SendAt,
+		&i.Status,
+		&i.Attempts,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteScheduledMessage = `-- name: DeleteScheduledMessage :execrows
+DELETE FROM scheduled_messages WHERE id = ? AND network_id = ?
+`
+
+type DeleteScheduledMessageParams struct {
+	ID        int64 `json:"id"`
+	NetworkID int64 `json:"network_id"`
+}
+
+func (q *Queries) DeleteScheduledMessage(ctx context.Context, arg DeleteScheduledMessageParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteScheduledMessage, arg.ID, arg.NetworkID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const failScheduledMessage = `-- name: FailScheduledMessage :exec
+UPDATE scheduled_messages SET status = 'failed', last_error = ? WHERE id = ?
+`
+
+type FailScheduledMessageParams struct {
+	LastError string `json:"last_error"`
+	ID        int64  `json:"id"`
+}
+
+func (q *Queries) FailScheduledMessage(ctx context.Context, arg FailScheduledMessageParams) error {
+	_, err := q.db.ExecContext(ctx, failScheduledMessage, arg.LastError, arg.ID)
+	return err
+}
+
+const listDueScheduledMessages = `-- name: ListDueScheduledMessages :many
+SELECT id, network_id, target, message, send_at, status, attempts, last_error, created_at FROM scheduled_messages
+WHERE status = 'pending' AND send_at <= ?
+ORDER BY send_at, id
+`
+
+func (q *Queries) ListDueScheduledMessages(ctx context.Context, sendAt int64) ([]ScheduledMessage, error) {
+	rows, err := q.db.QueryContext(ctx, listDueScheduledMessages, sendAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ScheduledMessage
+	for rows.Next() {
+		var i ScheduledMessage
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.Target,
+			&i.Message,
+			&i.SendAt,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduledMessages = `-- name: ListScheduledMessages :many
+SELECT id, network_id, target, message, send_at, status, attempts, last_error, created_at FROM scheduled_messages
+WHERE network_id = ?
+ORDER BY send_at, id
+`
+
+func (q *Queries) ListScheduledMessages(ctx context.Context, networkID int64) ([]ScheduledMessage, error) {
+	rows, err := q.db.QueryContext(ctx, listScheduledMessages, networkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ScheduledMessage
+	for rows.Next() {
+		var i ScheduledMessage
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.Target,
+			&i.Message,
+			&i.SendAt,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordScheduledMessageAttempt = `-- name: RecordScheduledMessageAttempt :exec
+UPDATE scheduled_messages SET attempts = attempts + 1, last_error = ? WHERE id = ?
+`
+
+type RecordScheduledMessageAttemptParams struct {
+	LastError string `json:"last_error"`
+	ID        int64  `json:"id"`
+}
+
+func (q *Queries) RecordScheduledMessageAttempt(ctx context.Context, arg RecordScheduledMessageAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, recordScheduledMessageAttempt, arg.LastError, arg.ID)
+	return err
+}
@@ -0,0 +1,137 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: seen_nicks.sql
+
+package db
+
+import (
+	"context"
+)
+
+const getSeenNick = `-- name: GetSeenNick :one
+SELECT network_id, nick_key, nick, "action", channel, target, message, host, account, seen_at FROM seen_nicks WHERE network_id = ? AND nick_key = ?
+`
+
+type GetSeenNickParams struct {
+	NetworkID int64  `json:"network_id"`
+	NickKey   string `json:"nick_key"`
+}
+
+func (q *Queries) GetSeenNick(ctx context.Context, arg GetSeenNickParams) (SeenNick, error) {
+	row := q.db.QueryRowContext(ctx, getSeenNick, arg.NetworkID, arg.NickKey)
+	var i SeenNick
+	err := row.Scan(
+		&i.NetworkID,
+		&i.NickKey,
+		&i.Nick,
+		&i.Action,
+		&i.Channel,
+		&i.Target,
+		&i.Message,
+		&i.Host,
+		&i.Account,
+		&i.SeenAt,
+	)
+	return i, err
+}
+
+const listSeenNicksByIdentity = `-- name: ListSeenNicksByIdentity :many
+SELECT network_id, nick_key, nick, "action", channel, target, message, host, account, seen_at FROM seen_nicks
+WHERE network_id = ?1 AND nick_key != ?2
+    AND ((?3 != '' AND account = ?3) OR (?4 != '' AND host = ?4))
+ORDER BY seen_at DESC
+LIMIT ?5
+`
+
+type ListSeenNicksByIdentityParams struct {
+	NetworkID int64       `json:"network_id"`
+	NickKey   string      `json:"nick_key"`
+	Account   interface{} `json:"account"`
+	Host      interface{} `json:"host"`
+	RowLimit  int64       `json:"row_limit"`
+}
+
+func (q *Queries) ListSeenNicksByIdentity(ctx context.Context, arg ListSeenNicksByIdentityParams) ([]SeenNick, error) {
+	rows, err := q.db.QueryContext(ctx, listSeenNicksByIdentity,
+		arg.NetworkID,
+		arg.NickKey,
+		arg.Account,
+		arg.Host,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeenNick
+	for rows.Next() {
+		var i SeenNick
+		if err := rows.Scan(
+			&i.NetworkID,
+			&i.NickKey,
+			&i.Nick,
+			&i.Action,
+			&i.Channel,
+			&i.Target,
+			&i.Message,
+			&i.Host,
+			&i.Account,
+			&i.SeenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertSeenNick = `-- name: UpsertSeenNick :exec
+INSERT INTO seen_nicks (network_id, nick_key, nick, action, channel, target, message, host, account, seen_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(network_id, nick_key) DO UPDATE SET
+    nick = excluded.nick,
+    action = excluded.action,
+    channel = excluded.channel,
+    target = excluded.target,
+    message = excluded.message,
+    host = CASE WHEN excluded.host = '' THEN seen_nicks.host ELSE excluded.host END,
+    account = CASE WHEN excluded.account = '' THEN seen_nicks.account ELSE excluded.account END,
+    seen_at = excluded.seen_at
+WHERE excluded.seen_at >= seen_nicks.seen_at
+`
+
+type UpsertSeenNickParams struct {
+	NetworkID int64  `json:"network_id"`
+	NickKey   string `json:"nick_key"`
+	Nick      string `json:"nick"`
+	Action    string `json:"action"`
+	Channel   string `json:"channel"`
+	Target    string `json:"target"`
+	Message   string `json:"message"`
+	Host      string `json:"host"`
+	Account   string `json:"account"`
+	SeenAt    int64  `json:"seen_at"`
+}
+
+func (q *Queries) UpsertSeenNick(ctx context.Context, arg UpsertSeenNickParams) error {
+	_, err := q.db.ExecContext(ctx, upsertSeenNick,
+		arg.NetworkID,
+		arg.NickKey,
+		arg.Nick,
+		arg.Action,
+		arg.Channel,
+		arg.Target,
+		arg.Message,
+		arg.Host,
+		arg.Account,
+		arg.SeenAt,
+	)
+	return err
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/matt0x6f/irc-client/internal/storage/generated"
+)
+
+// GetAccountMessages returns up to limit of the most recent messages sent
+// while logged in as account, across every nick and buffer on the network, in
+// chronological order. Accounts compare case-insensitively.
+func (s *Storage) GetAccountMessages(networkID int64, account string, limit int) ([]Message, error) {
+	if account == "" {
+		return []Message{}, nil
+	}
+	rows, err := s.queries.GetMessagesByAccount(context.Background(), db.GetMessagesByAccountParams{
+		NetworkID: networkID,
+		Account:   convertToNullString(account),
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get messages for account %s: %w", account, err)
+	}
+	// Query returns DESC (newest first); reverse to ascending (chronological).
+	messages := make([]Message, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		messages = append(messages, convertMessageFromDB(rows[i]))
+	}
+	return messages, nil
+}
+
+// GetAccountNicks returns every nick account has sent stored messages under
+// on the network, most recently used first.
+func (s *Storage) GetAccountNicks(networkID int64, account string) ([]AccountNick, error) {
+	if account == "" {
+		return []AccountNick{}, nil
+	}
+	rows, err := s.queries.ListAccountNicks(context.Background(), db.ListAccountNicksParams{
+		NetworkID: networkID,
+		Account:   convertToNullString(account),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list nicks for account %s: %w", account, err)
+	}
+	nicks := make([]AccountNick, len(rows))
+	for i, r := range rows {
+		nicks[i] = AccountNick{Nick: r.Nick, Messages: r.Messages, FirstSeen: r.FirstSeen, LastSeen: r.LastSeen}
+	}
+	return nicks, nil
+}
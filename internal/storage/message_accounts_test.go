@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccountLinksHistoryAcrossNicks(t *testing.T) {
+	s := newTestStorage(t)
+	net := makeNetwork("AccountNet")
+	if err := s.CreateNetwork(net); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	write := func(i int, user, account, text string) {
+		t.Helper()
+		if err := s.WriteMessageSync(Message{
+			NetworkID:   net.ID,
+			User:        user,
+			Account:     account,
+			Message:     text,
+			MessageType: "privmsg",
+			Timestamp:   base.Add(time.Duration(i) * time.Minute),
+			PMTarget:    "testuser",
+		}); err != nil {
+			t.Fatalf("WriteMessageSync: %v", err)
+		}
+	}
+	write(0, "foo", "Foo", "morning")
+	write(1, "foo|away", "foo", "brb lunch")
+	write(2, "foo_", "foo", "back, lunch was good")
+	write(3, "bar", "", "lunch sounds good")
+	write(4, "foo", "", "logged out now")
+
+	nicks, err := s.GetAccountNicks(net.ID, "FOO")
+	if err != nil {
+		t.Fatalf("GetAccountNicks: %v", err)
+	}
+	if len(nicks) != 3 || nicks[0].Nick != "foo_" || nicks[2].Nick != "foo" {
+		t.Fatalf("GetAccountNicks = %+v, want foo_, foo|away, foo", nicks)
+	}
+	if nicks[2].Messages != 1 || nicks[2].FirstSeen != base.Unix() || nicks[0].LastSeen != base.Add(2*time.Minute).Unix() {
+		t.Fatalf("GetAccountNicks counts/times = %+v", nicks)
+	}
+
+	msgs, err := s.GetAccountMessages(net.ID, "foo", 10)
+	if err != nil {
+		t.Fatalf("GetAccountMessages: %v", err)
+	}
+	if len(msgs) != 3 || msgs[0].Message != "morning" || msgs[2].Account != "foo" {
+		t.Fatalf("GetAccountMessages = %+v, want the three logged-in lines oldest first", msgs)
+	}
+
+	results, err := s.SearchMessages("lunch account:foo", &net.ID, 10)
+	if err != nil {
+		t.Fatalf("SearchMessages: %v", err)
+	}
+	if len(results) != 2 || results[0].User != "foo_" || results[1].User != "foo|away" {
+		t.Fatalf("SearchMessages with account filter = %+v, want foo_ then foo|away", results)
+	}
+	if all, _ := s.SearchMessages("account:foo", nil, 10); len(all) != 3 {
+		t.Fatalf("SearchMessages with only an account filter = %d results, want 3", len(all))
+	}
+}
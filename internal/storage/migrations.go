@@ -8,6 +8,11 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// SchemaVersion identifies the schema Migrate produces. It is recorded in the
+// database's user_version so a restore can refuse a backup taken by a newer
+// Cascade. Bump it whenever a migration is added.
+const SchemaVersion = 12
+
 // Migrate runs all database migrations
 func Migrate(db *sqlx.DB) error {
 	// First, check if we need to run the refactoring migration
@@ -85,6 +90,12 @@ func Migrate(db *sqlx.DB) error {
 		return fmt.Errorf("FTS5 migration failed: %w", err)
 	}
 
+	// Move full-text search onto the formatting-stripped plaintext column
+	// (adds + backfills the column, then reindexes a legacy messages_fts)
+	if err := migrateFTSPlaintext(db); err != nil {
+		return fmt.Errorf("FTS plaintext migration failed: %w", err)
+	}
+
 	// Handle pinned messages table migration
 	if err := migratePinnedMessages(db); err != nil {
 		return fmt.Errorf("pinned messages migration failed: %w", err)
@@ -162,7 +173,89 @@ func Migrate(db *sqlx.DB) error {
 	if err := migrateFileTransfers(db); err != nil {
 		return fmt.Errorf("file transfers migration failed: %w", err)
 	}
+	if err := migrateFileTransferChecksum(db); err != nil {
+		return fmt.Errorf("file transfer checksum migration failed: %w", err)
+	}
+	if err := migrateFileTransferQueuePosition(db); err != nil {
+		return fmt.Errorf("file transfer queue position migration failed: %w", err)
+	}
+
+	// Handle channel list entries table migration (+b/+e/+I lists with expiry)
+	if err := migrateChannelListEntries(db); err != nil {
+		return fmt.Errorf("channel list entries migration failed: %w", err)
+	}
+
+	// Handle command aliases table migration (user-defined slash commands)
+	if err := migrateCommandAliases(db); err != nil {
+		return fmt.Errorf("command aliases migration failed: %w", err)
+	}
+
+	// Handle perform steps table migration (per-network commands run on connect)
+	if err := migratePerformSteps(db); err != nil {
+		return fmt.Errorf("perform steps migration failed: %w", err)
+	}
+
+	// Handle flood_profile field migration (per-network outbound pacing)
+	if err := migrateFloodProfile(db); err != nil {
+		return fmt.Errorf("flood_profile migration failed: %w", err)
+	}
+
+	// Handle channel statistics aggregate tables
+	if err := migrateChannelStats(db); err != nil {
+		return fmt.Errorf("channel stats migration failed: %w", err)
+	}
+
+	// Rebuild channel statistics keyed by CASEMAPPING-folded nicks
+	if err := migrateChannelStatsNickKeys(db); err != nil {
+		return fmt.Errorf("channel stats nick key migration failed: %w", err)
+	}
+
+	// Handle channel directory tables (persistent LIST results with search)
+	if err := migrateChannelDirectory(db); err != nil {
+		return fmt.Errorf("channel directory migration failed: %w", err)
+	}
+
+	// Handle seen database table (last action per nick)
+	if err := migrateSeenNicks(db); err != nil {
+		return fmt.Errorf("seen nicks migration failed: %w", err)
+	}
+
+	// Handle messages.account (sender identity across nick changes)
+	if err := migrateMessageAccount(db); err != nil {
+		return fmt.Errorf("message account migration failed: %w", err)
+	}
+
+	// Handle smart filter columns (messages.filtered, channels.smart_filter_minutes)
+	if err := migrateSmartFilter(db); err != nil {
+		return fmt.Errorf("smart filter migration failed: %w", err)
+	}
+
+	// Handle encrypted column (messages.encrypted)
+	if err := migrateMessageEncrypted(db); err != nil {
+		return fmt.Errorf("message encrypted migration failed: %w", err)
+	}
+
+	// Handle scheduled messages table (/later)
+	if err := migrateScheduledMessages(db); err != nil {
+		return fmt.Errorf("scheduled messages migration failed: %w", err)
+	}
+
+	return recordSchemaVersion(db)
+}
 
+// recordSchemaVersion stamps SchemaVersion into user_version. It never lowers
+// the stamp, so a database last opened by a newer build keeps saying so.
+func recordSchemaVersion(db *sqlx.DB) error {
+	var current int
+	if err := db.Get(&current, "PRAGMA user_version"); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if current >= SchemaVersion {
+		return nil
+	}
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
 	return nil
 }
 
@@ -533,6 +626,27 @@ func migrateIdentifyAsBot(db *sqlx.DB) error {
 	return nil
 }
 
+// migrateFloodProfile adds flood_profile field to networks table if it doesn't exist
+func migrateFloodProfile(db *sqlx.DB) error {
+	var columnExists int
+	err := db.Get(&columnExists,
+		"SELECT COUNT(*) FROM pragma_table_info('networks') WHERE name='flood_profile'")
+	if err != nil {
+		return fmt.Errorf("failed to check for flood_profile column: %w", err)
+	}
+
+	if columnExists == 0 {
+		if _, err := db.Exec("ALTER TABLE networks ADD COLUMN flood_profile TEXT NOT NULL DEFAULT ''"); err != nil {
+			// Ignore "duplicate column" errors
+			if !strings.Contains(err.Error(), "duplicate column") {
+				return fmt.Errorf("failed to add flood_profile column: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // migrateNetworkRailColumns adds the side-rail columns (color, icon_path,
 // sort_order) to the networks table if missing, and backfills sort_order from
 // id so pre-existing rows get a stable, distinct order. Idempotent.
@@ -939,6 +1053,154 @@ func migrateFTS5(db *sqlx.DB) error {
 	return nil
 }
 
+// ftsPlaintextTriggers keep messages_fts in sync with the plaintext column. The
+// 'delete' rows must repeat exactly what was indexed, hence the same COALESCE on
+// both sides; it only matters for a row written by a path that skipped
+// normalizeForStore, which would otherwise index nothing at all.
+var ftsPlaintextTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS messages_ai AFTER INSERT ON messages BEGIN
+		INSERT INTO messages_fts(rowid, plaintext, user) VALUES (new.id, COALESCE(new.plaintext, new.message), new.user);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS messages_ad AFTER DELETE ON messages BEGIN
+		INSERT INTO messages_fts(messages_fts, rowid, plaintext, user) VALUES('delete', old.id, COALESCE(old.plaintext, old.message), old.user);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS messages_au AFTER UPDATE ON messages BEGIN
+		INSERT INTO messages_fts(messages_fts, rowid, plaintext, user) VALUES('delete', old.id, COALESCE(old.plaintext, old.message), old.user);
+		INSERT INTO messages_fts(rowid, plaintext, user) VALUES (new.id, COALESCE(new.plaintext, new.message), new.user);
+	END`,
+}
+
+// plaintextBackfillBatch bounds how many formatted rows are held in memory at
+// once while deriving their plaintext in Go.
+const plaintextBackfillBatch = 2000
+
+// migrateFTSPlaintext moves full-text search from the raw message column onto a
+// formatting-stripped plaintext column. Indexing the raw text meant mIRC colour
+// and bold codes split words ("\x0304build" tokenizes as "04build"), so search
+// missed most coloured bot output.
+//
+// It adds messages.plaintext, backfills it for existing rows, and — when
+// messages_fts still has the legacy (message, user) shape created by migrateFTS5
+// — drops the old triggers and index and rebuilds both against plaintext. Each
+// step is idempotent, so an interrupted run simply resumes on the next start.
+// Once the index is on plaintext, new rows carry their own plaintext and the
+// backfill's table scans are skipped.
+func migrateFTSPlaintext(db *sqlx.DB) error {
+	var columnExists int
+	if err := db.Get(&columnExists,
+		"SELECT COUNT(*) FROM pragma_table_info('messages') WHERE name='plaintext'"); err != nil {
+		return fmt.Errorf("failed to check for plaintext column: %w", err)
+	}
+	if columnExists == 0 {
+		if _, err := db.Exec("ALTER TABLE messages ADD COLUMN plaintext TEXT"); err != nil {
+			if !strings.Contains(err.Error(), "duplicate column") {
+				return fmt.Errorf("failed to add plaintext column: %w", err)
+			}
+		}
+	}
+
+	var indexesPlaintext int
+	if err := db.Get(&indexesPlaintext,
+		"SELECT COUNT(*) FROM pragma_table_info('messages_fts') WHERE name='plaintext'"); err != nil {
+		return fmt.Errorf("failed to inspect messages_fts columns: %w", err)
+	}
+	reindex := indexesPlaintext == 0
+
+	// Drop the legacy triggers before backfilling: they would otherwise
+	// re-index every backfilled row against the raw message column only for
+	// the rebuild below to throw that work away.
+	if reindex {
+		for _, name := range []string{"messages_ai", "messages_ad", "messages_au"} {
+			if _, err := db.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+				return fmt.Errorf("failed to drop legacy FTS trigger %s: %w", name, err)
+			}
+		}
+	}
+
+	if columnExists == 0 || reindex {
+		if err := backfillPlaintext(db); err != nil {
+			return err
+		}
+	}
+
+	if reindex {
+		tx, err := db.Beginx()
+		if err != nil {
+			return fmt.Errorf("failed to begin FTS reindex tx: %w", err)
+		}
+		defer tx.Rollback()
+
+		stmts := []string{
+			`DROP TABLE IF EXISTS messages_fts`,
+			`CREATE VIRTUAL TABLE messages_fts USING fts5(
+				plaintext,
+				user,
+				content='messages',
+				content_rowid='id'
+			)`,
+			`INSERT INTO messages_fts(rowid, plaintext, user)
+			 SELECT id, COALESCE(plaintext, message), user FROM messages`,
+		}
+		for _, stmt := range stmts {
+			if _, err := tx.Exec(stmt); err != nil {
+				return fmt.Errorf("failed to rebuild messages_fts: %w", err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit FTS reindex: %w", err)
+		}
+	}
+
+	for i, trigger := range ftsPlaintextTriggers {
+		if _, err := db.Exec(trigger); err != nil {
+			return fmt.Errorf("FTS plaintext trigger %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// backfillPlaintext fills messages.plaintext for rows written before the column
+// existed. Rows without any formatting code are copied in one UPDATE; only the
+// (usually small) remainder is loaded and stripped in Go, in bounded batches.
+func backfillPlaintext(db *sqlx.DB) error {
+	// char(2,3,15,17,22,29,30,31) is ircFormattingCodes as a GLOB class.
+	const hasCodes = `message GLOB '*[' || char(2,3,15,17,22,29,30,31) || ']*'`
+	if _, err := db.Exec(
+		"UPDATE messages SET plaintext = message WHERE plaintext IS NULL AND NOT (" + hasCodes + ")"); err != nil {
+		return fmt.Errorf("failed to backfill unformatted plaintext: %w", err)
+	}
+
+	type formattedRow struct {
+		ID      int64  `db:"id"`
+		Message string `db:"message"`
+	}
+	for {
+		var rows []formattedRow
+		if err := db.Select(&rows,
+			"SELECT id, message FROM messages WHERE plaintext IS NULL ORDER BY id LIMIT ?",
+			plaintextBackfillBatch); err != nil {
+			return fmt.Errorf("failed to load rows for plaintext backfill: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			return fmt.Errorf("failed to begin plaintext backfill tx: %w", err)
+		}
+		for _, r := range rows {
+			if _, err := tx.Exec("UPDATE messages SET plaintext = ? WHERE id = ?", stripFormatting(r.Message), r.ID); err != nil {
+				_ = tx.Rollback()
+				return fmt.Errorf("failed to backfill plaintext for message %d: %w", r.ID, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit plaintext backfill: %w", err)
+		}
+	}
+}
+
 const createSettingsTable = `
 CREATE TABLE IF NOT EXISTS settings (
     key        TEXT PRIMARY KEY,
@@ -1171,3 +1433,339 @@ func migrateFileTransfers(db *sqlx.DB) error {
 	}
 	return nil
 }
+
+// migrateFileTransferChecksum adds the SHA-256 digest recorded when a transfer
+// completes. Rows from earlier builds keep an empty digest.
+func migrateFileTransferChecksum(db *sqlx.DB) error {
+	var columnExists int
+	if err := db.Get(&columnExists,
+		"SELECT COUNT(*) FROM pragma_table_info('file_transfers') WHERE name='sha256'"); err != nil {
+		return fmt.Errorf("failed to check for sha256 column: %w", err)
+	}
+	if columnExists > 0 {
+		return nil
+	}
+	if _, err := db.Exec("ALTER TABLE file_transfers ADD COLUMN sha256 TEXT NOT NULL DEFAULT ''"); err != nil {
+		if !strings.Contains(err.Error(), "duplicate column") {
+			return fmt.Errorf("failed to add sha256 column: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateFileTransferQueuePosition adds an outgoing file's 1-based place in
+// the send queue. It is 0 once the file leaves the queue, and for rows from
+// earlier builds.
+func migrateFileTransferQueuePosition(db *sqlx.DB) error {
+	var columnExists int
+	if err := db.Get(&columnExists,
+		"SELECT COUNT(*) FROM pragma_table_info('file_transfers') WHERE name='queue_position'"); err != nil {
+		return fmt.Errorf("failed to check for queue_position column: %w", err)
+	}
+	if columnExists > 0 {
+		return nil
+	}
+	if _, err := db.Exec("ALTER TABLE file_transfers ADD COLUMN queue_position INTEGER NOT NULL DEFAULT 0"); err != nil {
+		if !strings.Contains(err.Error(), "duplicate column") {
+			return fmt.Errorf("failed to add queue_position column: %w", err)
+		}
+	}
+	return nil
+}
+
+const createChannelListEntriesTable = `
+CREATE TABLE IF NOT EXISTS channel_list_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER NOT NULL,
+    channel TEXT NOT NULL,
+    channel_key TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    mask TEXT NOT NULL,
+    set_by TEXT NOT NULL DEFAULT '',
+    set_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
+    UNIQUE(network_id, channel_key, mode, mask)
+);
+CREATE INDEX IF NOT EXISTS idx_channel_list_entries_expiry ON channel_list_entries(expires_at) WHERE expires_at IS NOT NULL;
+`
+
+// migrateChannelListEntries creates the channel_list_entries table if it doesn't
+// exist. It holds each channel's ban, exception and invex lists along with any
+// client-side expiry, so scheduled removals survive a restart.
+func migrateChannelListEntries(db *sqlx.DB) error {
+	if _, err := db.Exec(createChannelListEntriesTable); err != nil {
+		return fmt.Errorf("failed to create channel_list_entries table: %w", err)
+	}
+	return nil
+}
+
+const createCommandAliasesTable = `
+CREATE TABLE IF NOT EXISTS command_aliases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER,
+    name TEXT NOT NULL,
+    expansion TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_command_aliases_scope_name ON command_aliases(COALESCE(network_id, 0), name);
+`
+
+// migrateCommandAliases creates the command_aliases table if it doesn't exist.
+// A NULL network_id is a global alias; the unique index folds NULL to 0 so a
+// name is defined at most once per scope.
+func migrateCommandAliases(db *sqlx.DB) error {
+	if _, err := db.Exec(createCommandAliasesTable); err != nil {
+		return fmt.Errorf("failed to create command_aliases table: %w", err)
+	}
+	return nil
+}
+
+const createPerformStepsTable = `
+CREATE TABLE IF NOT EXISTS perform_steps (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    seconds INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
+    UNIQUE(network_id, position)
+);
+`
+
+// migratePerformSteps creates the perform_steps table if it doesn't exist. It
+// holds each network's ordered perform-on-connect list.
+func migratePerformSteps(db *sqlx.DB) error {
+	if _, err := db.Exec(createPerformStepsTable); err != nil {
+		return fmt.Errorf("failed to create perform_steps table: %w", err)
+	}
+	return nil
+}
+
+const createChannelStatsTables = `
+CREATE TABLE IF NOT EXISTS channel_stats_hourly (
+    network_id INTEGER NOT NULL,
+    channel_id INTEGER NOT NULL,
+    nick_key TEXT NOT NULL,
+    nick TEXT NOT NULL,
+    hour INTEGER NOT NULL,
+    lines INTEGER NOT NULL DEFAULT 0,
+    words INTEGER NOT NULL DEFAULT 0,
+    urls INTEGER NOT NULL DEFAULT 0,
+    joins INTEGER NOT NULL DEFAULT 0,
+    parts INTEGER NOT NULL DEFAULT 0,
+    first_seen INTEGER NOT NULL,
+    last_seen INTEGER NOT NULL,
+    PRIMARY KEY (channel_id, nick_key, hour),
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
+    FOREIGN KEY (channel_id) REFERENCES channels(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_channel_stats_hourly_window ON channel_stats_hourly(channel_id, hour);
+CREATE TABLE IF NOT EXISTS channel_stats_progress (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    last_message_id INTEGER NOT NULL
+);
+`
+
+// migrateChannelStats creates the channel statistics aggregate tables if they
+// don't exist. Existing history is folded in by the background refresh, which
+// starts from message id 0 when no progress row is present.
+func migrateChannelStats(db *sqlx.DB) error {
+	if _, err := db.Exec(createChannelStatsTables); err != nil {
+		return fmt.Errorf("failed to create channel stats tables: %w", err)
+	}
+	return nil
+}
+
+// migrateChannelStatsNickKeys empties the channel statistics aggregates of a
+// database stamped before schema version 12, whose nick keys were plain
+// lower case rather than folded with the network's CASEMAPPING. The background
+// refresh rebuilds them from the message log.
+func migrateChannelStatsNickKeys(db *sqlx.DB) error {
+	var version int
+	if err := db.Get(&version, "PRAGMA user_version"); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version >= 12 {
+		return nil
+	}
+	if _, err := db.Exec("DELETE FROM channel_stats_hourly; DELETE FROM channel_stats_progress"); err != nil {
+		return fmt.Errorf("failed to reset channel stats: %w", err)
+	}
+	return nil
+}
+
+const createChannelDirectoryTables = `
+CREATE TABLE IF NOT EXISTS channel_directory (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER NOT NULL,
+    channel_key TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    users INTEGER NOT NULL DEFAULT 0,
+    topic TEXT NOT NULL DEFAULT '',
+    modes TEXT NOT NULL DEFAULT '',
+    first_seen INTEGER NOT NULL,
+    last_seen INTEGER NOT NULL,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
+    UNIQUE(network_id, channel_key)
+);
+CREATE TABLE IF NOT EXISTS channel_directory_fetches (
+    network_id INTEGER PRIMARY KEY,
+    fetched_at INTEGER NOT NULL,
+    previous_fetched_at INTEGER NOT NULL DEFAULT 0,
+    full_fetched_at INTEGER NOT NULL DEFAULT 0,
+    filter TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS channel_directory_fts USING fts5(
+    channel,
+    topic,
+    content='channel_directory',
+    content_rowid='id'
+);
+CREATE TRIGGER IF NOT EXISTS channel_directory_ai AFTER INSERT ON channel_directory BEGIN
+    INSERT INTO channel_directory_fts(rowid, channel, topic) VALUES (new.id, new.channel, new.topic);
+END;
+CREATE TRIGGER IF NOT EXISTS channel_directory_ad AFTER DELETE ON channel_directory BEGIN
+    INSERT INTO channel_directory_fts(channel_directory_fts, rowid, channel, topic) VALUES('delete', old.id, old.channel, old.topic);
+END;
+CREATE TRIGGER IF NOT EXISTS channel_directory_au AFTER UPDATE OF channel, topic ON channel_directory BEGIN
+    INSERT INTO channel_directory_fts(channel_directory_fts, rowid, channel, topic) VALUES('delete', old.id, old.channel, old.topic);
+    INSERT INTO channel_directory_fts(rowid, channel, topic) VALUES (new.id, new.channel, new.topic);
+END;
+`
+
+// migrateChannelDirectory creates the channel directory tables, its FTS5 index
+// and the triggers that keep the index in step, if they don't exist.
+func migrateChannelDirectory(db *sqlx.DB) error {
+	if _, err := db.Exec(createChannelDirectoryTables); err != nil {
+		return fmt.Errorf("failed to create channel directory tables: %w", err)
+	}
+	return nil
+}
+
+const createSeenNicksTable = `
+CREATE TABLE IF NOT EXISTS seen_nicks (
+    network_id INTEGER NOT NULL,
+    nick_key TEXT NOT NULL,
+    nick TEXT NOT NULL,
+    action TEXT NOT NULL,
+    channel TEXT NOT NULL DEFAULT '',
+    target TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    host TEXT NOT NULL DEFAULT '',
+    account TEXT NOT NULL DEFAULT '',
+    seen_at INTEGER NOT NULL,
+    PRIMARY KEY (network_id, nick_key),
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_seen_nicks_account ON seen_nicks(network_id, account) WHERE account != '';
+CREATE INDEX IF NOT EXISTS idx_seen_nicks_host ON seen_nicks(network_id, host) WHERE host != '';
+`
+
+// migrateSeenNicks creates the seen database table and its identity indexes if
+// they don't exist.
+func migrateSeenNicks(db *sqlx.DB) error {
+	if _, err := db.Exec(createSeenNicksTable); err != nil {
+		return fmt.Errorf("failed to create seen_nicks table: %w", err)
+	}
+	return nil
+}
+
+// migrateMessageAccount adds the nullable messages.account column — the
+// services account a line's sender was logged in as — and the partial index
+// behind account-keyed history queries. Existing rows stay NULL: the account
+// a nick used in the past can't be recovered.
+func migrateMessageAccount(db *sqlx.DB) error {
+	var columnExists int
+	if err := db.Get(&columnExists,
+		"SELECT COUNT(*) FROM pragma_table_info('messages') WHERE name='account'"); err != nil {
+		return fmt.Errorf("failed to check for account column: %w", err)
+	}
+	if columnExists == 0 {
+		if _, err := db.Exec("ALTER TABLE messages ADD COLUMN account TEXT"); err != nil {
+			if !strings.Contains(err.Error(), "duplicate column") {
+				return fmt.Errorf("failed to add account column: %w", err)
+			}
+		}
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_messages_network_account
+		ON messages(network_id, account COLLATE NOCASE, timestamp)
+		WHERE account IS NOT NULL`); err != nil {
+		return fmt.Errorf("failed to create account index: %w", err)
+	}
+	return nil
+}
+
+// migrateSmartFilter adds messages.filtered, which marks join/part/quit rows
+// the channel's smart filter hides, and channels.smart_filter_minutes, the
+// per-channel window (0 = off). Existing rows stay unfiltered.
+func migrateSmartFilter(db *sqlx.DB) error {
+	columns := []struct{ table, name, alter string }{
+		{"messages", "filtered", "ALTER TABLE messages ADD COLUMN filtered BOOLEAN NOT NULL DEFAULT 0"},
+		{"channels", "smart_filter_minutes", "ALTER TABLE channels ADD COLUMN smart_filter_minutes INTEGER NOT NULL DEFAULT 0"},
+	}
+	for _, col := range columns {
+		var columnExists int
+		if err := db.Get(&columnExists,
+			"SELECT COUNT(*) FROM pragma_table_info(?) WHERE name=?", col.table, col.name); err != nil {
+			return fmt.Errorf("failed to check for %s.%s column: %w", col.table, col.name, err)
+		}
+		if columnExists == 0 {
+			if _, err := db.Exec(col.alter); err != nil {
+				if !strings.Contains(err.Error(), "duplicate column") {
+					return fmt.Errorf("failed to add %s.%s column: %w", col.table, col.name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// migrateMessageEncrypted adds messages.encrypted, which marks rows that
+// travelled encrypted (an E2E session or a FiSH key). Existing rows were all
+// plaintext.
+func migrateMessageEncrypted(db *sqlx.DB) error {
+	var columnExists int
+	if err := db.Get(&columnExists,
+		"SELECT COUNT(*) FROM pragma_table_info('messages') WHERE name='encrypted'"); err != nil {
+		return fmt.Errorf("failed to check for encrypted column: %w", err)
+	}
+	if columnExists > 0 {
+		return nil
+	}
+	if _, err := db.Exec("ALTER TABLE messages ADD COLUMN encrypted BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		if !strings.Contains(err.Error(), "duplicate column") {
+			return fmt.Errorf("failed to add encrypted column: %w", err)
+		}
+	}
+	return nil
+}
+
+const createScheduledMessagesTable = `
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER NOT NULL,
+    target TEXT NOT NULL,
+    message TEXT NOT NULL,
+    send_at INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_due ON scheduled_messages(send_at) WHERE status = 'pending';
+`
+
+// migrateScheduledMessages creates the scheduled_messages table if it doesn't
+// exist. Queued messages live in the database so they survive a restart.
+func migrateScheduledMessages(db *sqlx.DB) error {
+	if _, err := db.Exec(createScheduledMessagesTable); err != nil {
+		return fmt.Errorf("failed to create scheduled_messages table: %w", err)
+	}
+	return nil
+}
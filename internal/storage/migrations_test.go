@@ -1,6 +1,9 @@
 package storage
 
-import "testing"
+import (
+	"database/sql"
+	"testing"
+)
 
 // TestMigrateReplyAndContextAddsColumns verifies that migrateReplyAndContext
 // adds the reply_msgid and channel_context columns to an existing messages table
@@ -107,3 +110,85 @@ func TestMigratePluginConfigColumnNormalizesLegacyJSON(t *testing.T) {
 		t.Fatalf("TEXT config schema was not preserved: %+v", configs["text-json"].ConfigSchema)
 	}
 }
+
+// TestMigrateFTSPlaintextReindexesLegacyIndex verifies that a database whose
+// messages_fts still indexes the raw message column (the migrateFTS5 shape) is
+// backfilled and reindexed on plaintext, so formatted rows become searchable by
+// their visible words while the stored message keeps its formatting codes.
+func TestMigrateFTSPlaintextReindexesLegacyIndex(t *testing.T) {
+	s := newTestStorage(t)
+	net := makeNetwork("FmtNet")
+	if err := s.CreateNetwork(net); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+
+	// Simulate a legacy DB: raw-message FTS index and triggers, no plaintext values.
+	if _, err := s.db.Exec(`
+		DROP TRIGGER messages_ai;
+		DROP TRIGGER messages_ad;
+		DROP TRIGGER messages_au;
+		DROP TABLE messages_fts;
+	`); err != nil {
+		t.Fatalf("drop current FTS: %v", err)
+	}
+	if err := migrateFTS5(s.db); err != nil {
+		t.Fatalf("recreate legacy FTS: %v", err)
+	}
+	formatted := "\x0304build\x03 \x02failed\x02 on \x1dmain\x1d"
+	if _, err := s.db.Exec(`INSERT INTO messages (network_id, user, message, message_type, timestamp, raw_line)
+		VALUES (?, 'ci-bot', ?, 'privmsg', '2024-01-01 00:00:00+00:00', '')`, net.ID, formatted); err != nil {
+		t.Fatalf("seed legacy row: %v", err)
+	}
+
+	if err := migrateFTSPlaintext(s.db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	if err := migrateFTSPlaintext(s.db); err != nil {
+		t.Fatalf("second migration failed: %v", err)
+	}
+
+	var plaintext, message string
+	if err := s.db.QueryRow("SELECT plaintext, message FROM messages WHERE user = 'ci-bot'").Scan(&plaintext, &message); err != nil {
+		t.Fatal(err)
+	}
+	if plaintext != "build failed on main" {
+		t.Fatalf("plaintext = %q; want formatting stripped", plaintext)
+	}
+	if message != formatted {
+		t.Fatalf("message = %q; the formatted original must be kept for display", message)
+	}
+
+	results, err := s.SearchMessages("build failed", nil, 10)
+	if err != nil {
+		t.Fatalf("SearchMessages: %v", err)
+	}
+	if len(results) != 1 || results[0].Message.Message != formatted {
+		t.Fatalf("search after reindex = %+v; want the formatted row", results)
+	}
+}
+
+// TestMigrateFTSPlaintextSkipsBackfillOnceIndexed verifies that a database
+// already indexing plaintext is not scanned again on every start: a row left
+// without plaintext stays that way.
+func TestMigrateFTSPlaintextSkipsBackfillOnceIndexed(t *testing.T) {
+	s := newTestStorage(t)
+	net := makeNetwork("FmtNet")
+	if err := s.CreateNetwork(net); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO messages (network_id, user, message, message_type, timestamp, raw_line)
+		VALUES (?, 'ci-bot', 'hello', 'privmsg', '2024-01-01 00:00:00+00:00', '')`, net.ID); err != nil {
+		t.Fatalf("seed row: %v", err)
+	}
+
+	if err := migrateFTSPlaintext(s.db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	var plaintext sql.NullString
+	if err := s.db.Get(&plaintext, "SELECT plaintext FROM messages WHERE user = 'ci-bot'"); err != nil {
+		t.Fatal(err)
+	}
+	if plaintext.Valid {
+		t.Fatalf("plaintext = %q; the backfill ran against an index already on plaintext", plaintext.String)
+	}
+}
@@ -26,6 +26,7 @@ type Network struct {
 	SASLExternalCert *string   `db:"sasl_external_cert" json:"sasl_external_cert"`
 	AutoConnect      bool      `db:"auto_connect" json:"auto_connect"`
 	IdentifyAsBot    bool      `db:"identify_as_bot" json:"identify_as_bot"`
+	FloodProfile     string    `db:"flood_profile" json:"flood_profile"` // outbound pacing profile name; empty means the standard one
 	CreatedAt        time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
 
@@ -69,16 +70,17 @@ type STSPolicy struct {
 // Channel represents an IRC channel
 // State: OPEN (dialog open, may or may not be joined), JOINED (dialog open and joined), CLOSED (dialog closed, not joined)
 type Channel struct {
-	ID        int64      `db:"id" json:"id"`
-	NetworkID int64      `db:"network_id" json:"network_id"`
-	Name      string     `db:"name" json:"name"`
-	Topic     string     `db:"topic" json:"topic"`
-	Modes     string     `db:"modes" json:"modes"`
-	Key       string     `db:"key" json:"-"` // channel key (+k) for rejoin; never serialized to the webview
-	AutoJoin  bool       `db:"auto_join" json:"auto_join"`
-	IsOpen    bool       `db:"is_open" json:"is_open"` // Dialog/pane is open (OPEN or JOINED state)
-	CreatedAt time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt *time.Time `db:"updated_at" json:"updated_at"`
+	ID                 int64      `db:"id" json:"id"`
+	NetworkID          int64      `db:"network_id" json:"network_id"`
+	Name               string     `db:"name" json:"name"`
+	Topic              string     `db:"topic" json:"topic"`
+	Modes              string     `db:"modes" json:"modes"`
+	Key                string     `db:"key" json:"-"` // channel key (+k) for rejoin; never serialized to the webview
+	AutoJoin           bool       `db:"auto_join" json:"auto_join"`
+	IsOpen             bool       `db:"is_open" json:"is_open"` // Dialog/pane is open (OPEN or JOINED state)
+	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt          *time.Time `db:"updated_at" json:"updated_at"`
+	SmartFilterMinutes int        `db:"smart_filter_minutes" json:"smart_filter_minutes"` // Hide join/part/quit from nicks silent this long; 0 = off
 }
 
 // ChannelUser represents a user in a channel
@@ -105,6 +107,10 @@ type Message struct {
 	MsgID          string    `db:"msgid" json:"msgid"`                     // IRCv3 message id ("" for legacy/local rows); dedup key for CHATHISTORY
 	ReplyMsgID     string    `db:"reply_msgid" json:"reply_msgid"`         // IRCv3 +draft/reply: msgid of the parent message ("" if not a reply)
 	ChannelContext string    `db:"channel_context" json:"channel_context"` // IRCv3 +draft/channel-context: channel a PM is about ("" otherwise)
+	Account        string    `db:"account" json:"account"`                 // Services account the sender was logged in as ("" if unknown)
+	Filtered       bool      `db:"filtered" json:"filtered"`               // Join/part/quit hidden by the channel's smart filter; channel GetMessages* skip it
+	Encrypted      bool      `db:"encrypted" json:"encrypted"`             // Sent or received encrypted (E2E session or FiSH key); Message holds the decrypted text
+	Plaintext      string    `db:"plaintext" json:"-"`                     // Message with IRC formatting stripped; derived on write (see normalizeForStore) and indexed by messages_fts
 }
 
 // ActivityItem is one attention-inbox row (highlight, keyword, invite, or PM).
@@ -172,6 +178,44 @@ type FileTransfer struct {
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 	FinishedAt       *time.Time
+	SHA256           string
+	QueuePosition    int
+}
+
+// ChannelListEntry is one entry on a channel's ban (b), exception (e) or invite
+// exception (I) list. ChannelKey is the CASEMAPPING-folded channel name used for
+// lookups; Channel keeps the display form.
+type ChannelListEntry struct {
+	ID         int64      `db:"id" json:"id"`
+	NetworkID  int64      `db:"network_id" json:"network_id"`
+	Channel    string     `db:"channel" json:"channel"`
+	ChannelKey string     `db:"channel_key" json:"channel_key"`
+	Mode       string     `db:"mode" json:"mode"`
+	Mask       string     `db:"mask" json:"mask"`
+	SetBy      string     `db:"set_by" json:"set_by"`         // "" when the server did not say
+	SetAt      *time.Time `db:"set_at" json:"set_at"`         // nil when the server did not say
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at"` // nil unless a client-side expiry is scheduled
+}
+
+// CommandAlias is a user-defined slash command. NetworkID is nil for an alias
+// available on every network; a network's own alias of the same name wins.
+type CommandAlias struct {
+	ID        int64  `db:"id" json:"id"`
+	NetworkID *int64 `db:"network_id" json:"network_id"`
+	Name      string `db:"name" json:"name"`           // upper-case, without the slash
+	Expansion string `db:"expansion" json:"expansion"` // one or more commands separated by ';'
+}
+
+// PerformStep is one entry in a network's perform-on-connect list. Kind says
+// how Value and Seconds are read: a command line, a pause of Seconds, or a
+// wait of up to Seconds for a NOTICE matching Value.
+type PerformStep struct {
+	ID        int64  `db:"id" json:"id"`
+	NetworkID int64  `db:"network_id" json:"network_id"`
+	Position  int64  `db:"position" json:"position"`
+	Kind      string `db:"kind" json:"kind"`
+	Value     string `db:"value" json:"value"`
+	Seconds   int64  `db:"seconds" json:"seconds"`
 }
 
 // PluginConfig represents user configuration for a plugin
@@ -183,3 +227,113 @@ type PluginConfig struct {
 	CreatedAt    time.Time              `db:"created_at" json:"created_at"`
 	UpdatedAt    time.Time              `db:"updated_at" json:"updated_at"`
 }
+
+// NickActivity is one nick's channel activity summed over a stats window.
+// FirstSeen and LastSeen are accurate to the message; the window itself is
+// matched to whole hours.
+type NickActivity struct {
+	Nick      string    `json:"nick"`
+	Lines     int64     `json:"lines"`
+	Words     int64     `json:"words"`
+	URLs      int64     `json:"urls"`
+	Joins     int64     `json:"joins"`
+	Parts     int64     `json:"parts"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// HourActivity is a channel's activity in one UTC hour.
+type HourActivity struct {
+	Hour  time.Time `json:"hour"`
+	Lines int64     `json:"lines"`
+	Words int64     `json:"words"`
+	URLs  int64     `json:"urls"`
+	Joins int64     `json:"joins"`
+	Parts int64     `json:"parts"`
+}
+
+// ChannelDirectoryEntry is a channel from a network's LIST replies. Times are
+// Unix milliseconds of the fetches that first and last listed it; New is set
+// when the latest fetch was the first to list it.
+type ChannelDirectoryEntry struct {
+	NetworkID int64  `db:"network_id" json:"network_id"`
+	Channel   string `db:"channel" json:"channel"`
+	Users     int64  `db:"users" json:"users"`
+	Topic     string `db:"topic" json:"topic"`
+	Modes     string `db:"modes" json:"modes"`
+	FirstSeen int64  `db:"first_seen" json:"first_seen"`
+	LastSeen  int64  `db:"last_seen" json:"last_seen"`
+	New       bool   `db:"is_new" json:"new"`
+}
+
+// ChannelDirectoryFetch records a network's latest LIST fetch. Times are Unix
+// milliseconds, zero when there was none; Filter is the LIST argument sent,
+// empty for a full listing.
+type ChannelDirectoryFetch struct {
+	FetchedAt         int64  `json:"fetched_at"`
+	PreviousFetchedAt int64  `json:"previous_fetched_at"`
+	FullFetchedAt     int64  `json:"full_fetched_at"`
+	Filter            string `json:"filter"`
+}
+
+// ChannelDirectoryQuery selects channels from a network's directory. Zero
+// fields are unset. Masks are IRC wildcard masks matched case-insensitively
+// against the name, any one of which may match; Text words must all appear
+// in the name or topic, matched as prefixes.
+type ChannelDirectoryQuery struct {
+	Text         []string
+	MinUsers     int // more than this many users
+	MaxUsers     int // fewer than this many users
+	Masks        []string
+	ExcludeMasks []string
+	NewOnly      bool
+	Limit        int // 0 for no limit
+}
+
+// SeenEntry is the last thing a nick was seen doing on a network. Target is
+// the other nick of a nick change or kick, or the account of an account
+// change; Message is a part, quit or kick reason, or the text of a message.
+// SeenAt is Unix milliseconds.
+type SeenEntry struct {
+	NetworkID int64  `json:"network_id"`
+	Nick      string `json:"nick"`
+	Action    string `json:"action"`
+	Channel   string `json:"channel"`
+	Target    string `json:"target"`
+	Message   string `json:"message"`
+	Host      string `json:"host"`
+	Account   string `json:"account"`
+	SeenAt    int64  `json:"seen_at"`
+}
+
+// AccountNick is one nick a services account has sent messages under, with
+// how many stored lines carry it and when the first and last were sent (Unix
+// seconds).
+type AccountNick struct {
+	Nick      string `json:"nick"`
+	Messages  int64  `json:"messages"`
+	FirstSeen int64  `json:"first_seen"`
+	LastSeen  int64  `json:"last_seen"`
+}
+
+// ScheduledMessage is a message queued to be sent later. SendAt and CreatedAt
+// are Unix milliseconds. Status is ScheduledPending until it goes out, when
+// the row is deleted, or ScheduledFailed once it can't be; LastError says why
+// the latest attempt did not go through.
+type ScheduledMessage struct {
+	ID        int64  `json:"id"`
+	NetworkID int64  `json:"network_id"`
+	Target    string `json:"target"`
+	Message   string `json:"message"`
+	SendAt    int64  `json:"send_at"`
+	Status    string `json:"status"`
+	Attempts  int64  `json:"attempts"`
+	LastError string `json:"last_error"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// Scheduled message statuses.
+const (
+	ScheduledPending = "pending"
+	ScheduledFailed  = "failed"
+)
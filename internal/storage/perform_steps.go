@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/matt0x6f/irc-client/internal/storage/generated"
+)
+
+// ReplacePerformSteps makes steps the network's perform list, in order.
+func (s *Storage) ReplacePerformSteps(networkID int64, steps []PerformStep) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("begin perform update: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	queries := s.queries.WithTx(tx)
+	if err := queries.DeletePerformSteps(context.Background(), networkID); err != nil {
+		return fmt.Errorf("clear perform steps: %w", err)
+	}
+	for i, step := range steps {
+		if err := queries.InsertPerformStep(context.Background(), db.InsertPerformStepParams{
+			NetworkID: networkID,
+			Position:  int64(i),
+			Kind:      step.Kind,
+			Value:     step.Value,
+			Seconds:   step.Seconds,
+		}); err != nil {
+			return fmt.Errorf("insert perform step %d: %w", i+1, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit perform update: %w", err)
+	}
+	return nil
+}
+
+// ListPerformSteps returns the network's perform list in order.
+func (s *Storage) ListPerformSteps(networkID int64) ([]PerformStep, error) {
+	rows, err := s.queries.ListPerformSteps(context.Background(), networkID)
+	if err != nil {
+		return nil, fmt.Errorf("list perform steps: %w", err)
+	}
+	out := make([]PerformStep, len(rows))
+	for i, row := range rows {
+		out[i] = PerformStep(row)
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package storage
+
+import "testing"
+
+// TestReplacePerformStepsKeepsOrder: replacing the list rewrites it wholesale
+// in the given order, and other networks' lists are untouched.
+func TestReplacePerformStepsKeepsOrder(t *testing.T) {
+	s := newTestStorage(t)
+	a, b := makeNetwork("PerformA"), makeNetwork("PerformB")
+	for _, net := range []*Network{a, b} {
+		if err := s.CreateNetwork(net); err != nil {
+			t.Fatalf("CreateNetwork: %v", err)
+		}
+	}
+
+	if err := s.ReplacePerformSteps(b.ID, []PerformStep{{Kind: "command", Value: "/oper me secret"}}); err != nil {
+		t.Fatalf("ReplacePerformSteps(b): %v", err)
+	}
+	for _, steps := range [][]PerformStep{
+		{{Kind: "command", Value: "/mode $me +x"}},
+		{
+			{Kind: "command", Value: "/msg Q@CServe.quakenet.org AUTH me ${secret:q}"},
+			{Kind: "wait", Value: "*now logged in*", Seconds: 20},
+			{Kind: "delay", Seconds: 2},
+		},
+	} {
+		if err := s.ReplacePerformSteps(a.ID, steps); err != nil {
+			t.Fatalf("ReplacePerformSteps(a): %v", err)
+		}
+	}
+
+	got, err := s.ListPerformSteps(a.ID)
+	if err != nil {
+		t.Fatalf("ListPerformSteps: %v", err)
+	}
+	if len(got) != 3 || got[0].Kind != "command" || got[1].Value != "*now logged in*" || got[1].Seconds != 20 || got[2].Kind != "delay" {
+		t.Fatalf("steps = %+v", got)
+	}
+	if other, _ := s.ListPerformSteps(b.ID); len(other) != 1 {
+		t.Errorf("other network's steps = %+v, want 1", other)
+	}
+}
@@ -0,0 +1,24 @@
+-- name: UpsertChannelDirectoryEntry :exec
+INSERT INTO channel_directory (network_id, channel_key, channel, users, topic, modes, first_seen, last_seen)
+VALUES (sqlc.arg(network_id), sqlc.arg(channel_key), sqlc.arg(channel), sqlc.arg(users), sqlc.arg(topic), sqlc.arg(modes), sqlc.arg(seen_at), sqlc.arg(seen_at))
+ON CONFLICT(network_id, channel_key) DO UPDATE SET
+    channel = excluded.channel,
+    users = excluded.users,
+    topic = excluded.topic,
+    modes = excluded.modes,
+    last_seen = excluded.last_seen;
+
+-- name: DeleteChannelDirectoryUnseenSince :execrows
+DELETE FROM channel_directory WHERE network_id = ? AND last_seen < ?;
+
+-- name: GetChannelDirectoryFetch :one
+SELECT * FROM channel_directory_fetches WHERE network_id = ?;
+
+-- name: RecordChannelDirectoryFetch :exec
+INSERT INTO channel_directory_fetches (network_id, fetched_at, previous_fetched_at, full_fetched_at, filter)
+VALUES (sqlc.arg(network_id), sqlc.arg(fetched_at), 0, CASE WHEN sqlc.arg(filter) = '' THEN sqlc.arg(fetched_at) ELSE 0 END, sqlc.arg(filter))
+ON CONFLICT(network_id) DO UPDATE SET
+    previous_fetched_at = channel_directory_fetches.fetched_at,
+    fetched_at = excluded.fetched_at,
+    full_fetched_at = CASE WHEN excluded.filter = '' THEN excluded.fetched_at ELSE channel_directory_fetches.full_fetched_at END,
+    filter = excluded.filter;
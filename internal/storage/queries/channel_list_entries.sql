@@ -0,0 +1,27 @@
+-- name: UpsertChannelListEntry :exec
+INSERT INTO channel_list_entries (network_id, channel, channel_key, mode, mask, set_by, set_at, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(network_id, channel_key, mode, mask) DO UPDATE SET
+    channel = excluded.channel,
+    set_by = CASE WHEN excluded.set_by = '' THEN channel_list_entries.set_by ELSE excluded.set_by END,
+    set_at = COALESCE(excluded.set_at, channel_list_entries.set_at),
+    expires_at = COALESCE(excluded.expires_at, channel_list_entries.expires_at);
+
+-- name: SetChannelListEntryExpiry :exec
+UPDATE channel_list_entries SET expires_at = ?
+WHERE network_id = ? AND channel_key = ? AND mode = ? AND mask = ?;
+
+-- name: DeleteChannelListEntry :exec
+DELETE FROM channel_list_entries
+WHERE network_id = ? AND channel_key = ? AND mode = ? AND mask = ?;
+
+-- name: ListChannelListEntries :many
+SELECT * FROM channel_list_entries
+WHERE network_id = sqlc.arg(network_id) AND channel_key = sqlc.arg(channel_key)
+  AND (sqlc.arg(mode) = '' OR mode = sqlc.arg(mode))
+ORDER BY mode, set_at IS NULL, set_at, mask;
+
+-- name: ListExpiredChannelListEntries :many
+SELECT * FROM channel_list_entries
+WHERE expires_at IS NOT NULL AND expires_at <= ?
+ORDER BY network_id, channel_key, expires_at;
@@ -0,0 +1,72 @@
+-- name: ListMessagesAfter :many
+SELECT id, network_id, channel_id, user, COALESCE(plaintext, message) AS text, message_type, timestamp
+FROM messages
+WHERE id > ?
+ORDER BY id
+LIMIT ?;
+
+-- name: GetChannelStatsProgress :one
+SELECT last_message_id FROM channel_stats_progress WHERE id = 1;
+
+-- name: SetChannelStatsProgress :exec
+INSERT INTO channel_stats_progress (id, last_message_id) VALUES (1, ?)
+ON CONFLICT(id) DO UPDATE SET last_message_id = excluded.last_message_id;
+
+-- name: AddChannelStatsHour :exec
+INSERT INTO channel_stats_hourly (network_id, channel_id, nick_key, nick, hour, lines, words, urls, joins, parts, first_seen, last_seen)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(channel_id, nick_key, hour) DO UPDATE SET
+    nick = excluded.nick,
+    lines = channel_stats_hourly.lines + excluded.lines,
+    words = channel_stats_hourly.words + excluded.words,
+    urls = channel_stats_hourly.urls + excluded.urls,
+    joins = channel_stats_hourly.joins + excluded.joins,
+    parts = channel_stats_hourly.parts + excluded.parts,
+    first_seen = MIN(channel_stats_hourly.first_seen, excluded.first_seen),
+    last_seen = MAX(channel_stats_hourly.last_seen, excluded.last_seen);
+
+-- name: ListChannelStatsNicks :many
+SELECT h.nick_key,
+    CAST((SELECT latest.nick FROM channel_stats_hourly AS latest
+        WHERE latest.channel_id = h.channel_id AND latest.nick_key = h.nick_key
+        ORDER BY latest.hour DESC LIMIT 1) AS TEXT) AS nick,
+    CAST(SUM(lines) AS INTEGER) AS lines,
+    CAST(SUM(words) AS INTEGER) AS words,
+    CAST(SUM(urls) AS INTEGER) AS urls,
+    CAST(SUM(joins) AS INTEGER) AS joins,
+    CAST(SUM(parts) AS INTEGER) AS parts,
+    CAST(MIN(first_seen) AS INTEGER) AS first_seen,
+    CAST(MAX(last_seen) AS INTEGER) AS last_seen
+FROM channel_stats_hourly AS h
+WHERE h.channel_id = sqlc.arg(channel_id) AND h.hour >= sqlc.arg(from_hour) AND h.hour < sqlc.arg(to_hour)
+GROUP BY h.nick_key
+ORDER BY lines DESC, words DESC, h.nick_key;
+
+-- name: GetChannelStatsNick :one
+SELECT h.nick_key,
+    CAST((SELECT latest.nick FROM channel_stats_hourly AS latest
+        WHERE latest.channel_id = h.channel_id AND latest.nick_key = h.nick_key
+        ORDER BY latest.hour DESC LIMIT 1) AS TEXT) AS nick,
+    CAST(SUM(lines) AS INTEGER) AS lines,
+    CAST(SUM(words) AS INTEGER) AS words,
+    CAST(SUM(urls) AS INTEGER) AS urls,
+    CAST(SUM(joins) AS INTEGER) AS joins,
+    CAST(SUM(parts) AS INTEGER) AS parts,
+    CAST(MIN(first_seen) AS INTEGER) AS first_seen,
+    CAST(MAX(last_seen) AS INTEGER) AS last_seen
+FROM channel_stats_hourly AS h
+WHERE h.channel_id = sqlc.arg(channel_id) AND h.nick_key = sqlc.arg(nick_key)
+    AND h.hour >= sqlc.arg(from_hour) AND h.hour < sqlc.arg(to_hour)
+GROUP BY h.nick_key;
+
+-- name: ListChannelStatsHours :many
+SELECT hour,
+    CAST(SUM(lines) AS INTEGER) AS lines,
+    CAST(SUM(words) AS INTEGER) AS words,
+    CAST(SUM(urls) AS INTEGER) AS urls,
+    CAST(SUM(joins) AS INTEGER) AS joins,
+    CAST(SUM(parts) AS INTEGER) AS parts
+FROM channel_stats_hourly
+WHERE channel_id = sqlc.arg(channel_id) AND hour >= sqlc.arg(from_hour) AND hour < sqlc.arg(to_hour)
+GROUP BY hour
+ORDER BY hour;
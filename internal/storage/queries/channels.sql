@@ -25,6 +25,9 @@ UPDATE channels SET modes = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
 -- name: UpdateChannelKey :exec
 UPDATE channels SET "key" = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
 
+-- name: UpdateChannelSmartFilter :exec
+UPDATE channels SET smart_filter_minutes = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
 -- name: UpdateChannelAutoJoin :exec
 UPDATE channels SET auto_join = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
 
@@ -0,0 +1,14 @@
+-- name: UpsertCommandAlias :exec
+INSERT INTO command_aliases (network_id, name, expansion)
+VALUES (?, ?, ?)
+ON CONFLICT(COALESCE(network_id, 0), name) DO UPDATE SET
+    expansion = excluded.expansion,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteCommandAlias :exec
+DELETE FROM command_aliases
+WHERE COALESCE(network_id, 0) = COALESCE(sqlc.narg(network_id), 0) AND name = sqlc.arg(name);
+
+-- name: ListCommandAliases :many
+SELECT * FROM command_aliases
+ORDER BY network_id IS NOT NULL, network_id, name;
@@ -2,8 +2,8 @@
 INSERT INTO file_transfers (
     transfer_id, network_id, network_name, peer, direction, filename,
     local_path, partial_path, size_bytes, transferred_bytes, state, error,
-    resumable, created_at, updated_at, finished_at
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    resumable, created_at, updated_at, finished_at, sha256, queue_position
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(transfer_id) DO UPDATE SET
     network_id = excluded.network_id,
     network_name = excluded.network_name,
@@ -18,7 +18,9 @@ ON CONFLICT(transfer_id) DO UPDATE SET
     error = excluded.error,
     resumable = excluded.resumable,
     updated_at = excluded.updated_at,
-    finished_at = excluded.finished_at;
+    finished_at = excluded.finished_at,
+    sha256 = excluded.sha256,
+    queue_position = excluded.queue_position;
 
 -- name: GetFileTransfer :one
 SELECT * FROM file_transfers WHERE transfer_id = ?;
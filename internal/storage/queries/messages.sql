@@ -1,6 +1,6 @@
 -- name: GetMessagesWithChannel :many
 SELECT * FROM messages 
-WHERE network_id = ? AND channel_id = ? 
+WHERE network_id = ? AND channel_id = ? AND filtered = 0
 ORDER BY timestamp DESC 
 LIMIT ?;
 
@@ -11,8 +11,8 @@ ORDER BY timestamp DESC
 LIMIT ?;
 
 -- name: CreateMessage :one
-INSERT INTO messages (network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO messages (network_id, channel_id, user, message, message_type, timestamp, raw_line, pm_target, msgid, reply_msgid, channel_context, plaintext, account, filtered, encrypted)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: GetMessageByMsgID :one
@@ -29,3 +29,32 @@ WHERE network_id = ? AND channel_id IS NULL AND message_type IN ('privmsg', 'act
 AND LOWER(pm_target) = ?
 ORDER BY timestamp DESC
 LIMIT ?;
+
+-- name: GetMessagesByAccount :many
+SELECT * FROM messages
+WHERE network_id = ? AND account = ? COLLATE NOCASE
+ORDER BY timestamp DESC
+LIMIT ?;
+
+-- name: ListAccountNicks :many
+SELECT user AS nick, COUNT(*) AS messages,
+    CAST(strftime('%s', MIN(timestamp)) AS INTEGER) AS first_seen,
+    CAST(strftime('%s', MAX(timestamp)) AS INTEGER) AS last_seen
+FROM messages
+WHERE network_id = ? AND account = ? COLLATE NOCASE
+GROUP BY user
+ORDER BY MAX(timestamp) DESC;
+
+-- name: UnfilterLatestJoin :one
+UPDATE messages SET filtered = 0
+WHERE id = (
+    SELECT m.id FROM messages m
+    WHERE m.channel_id = ? AND m.user = ? COLLATE NOCASE AND m.message_type = 'join'
+      AND m.filtered = 1 AND m.timestamp >= ?
+    ORDER BY m.timestamp DESC, m.id DESC
+    LIMIT 1
+)
+RETURNING id;
+
+-- name: ClearChannelFiltered :exec
+UPDATE messages SET filtered = 0 WHERE channel_id = ? AND filtered = 1;
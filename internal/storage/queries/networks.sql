@@ -5,8 +5,8 @@ SELECT * FROM networks WHERE id = ?;
 SELECT * FROM networks ORDER BY sort_order, id;
 
 -- name: CreateNetwork :one
-INSERT INTO networks (name, address, port, tls, nickname, username, realname, password, sasl_enabled, sasl_mechanism, sasl_username, sasl_password, sasl_external_cert, auto_connect, identify_as_bot, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO networks (name, address, port, tls, nickname, username, realname, password, sasl_enabled, sasl_mechanism, sasl_username, sasl_password, sasl_external_cert, auto_connect, identify_as_bot, flood_profile, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: UpdateNetwork :exec
@@ -15,7 +15,7 @@ SET name = ?, address = ?, port = ?, tls = ?,
     nickname = ?, username = ?, realname = ?,
     password = ?, sasl_enabled = ?, sasl_mechanism = ?,
     sasl_username = ?, sasl_password = ?, sasl_external_cert = ?,
-    auto_connect = ?, identify_as_bot = ?, updated_at = ?
+    auto_connect = ?, identify_as_bot = ?, flood_profile = ?, updated_at = ?
 WHERE id = ?;
 
 -- name: UpdateNetworkAutoConnect :exec
@@ -0,0 +1,9 @@
+-- name: InsertPerformStep :exec
+INSERT INTO perform_steps (network_id, position, kind, value, seconds)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: DeletePerformSteps :exec
+DELETE FROM perform_steps WHERE network_id = ?;
+
+-- name: ListPerformSteps :many
+SELECT * FROM perform_steps WHERE network_id = ? ORDER BY position;
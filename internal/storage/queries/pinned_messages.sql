@@ -24,13 +24,13 @@ ORDER BY p.pinned_at DESC;
 
 -- name: GetMessagesBeforeWithChannel :many
 SELECT * FROM messages
-WHERE network_id = ? AND channel_id = ? AND id <= ?
+WHERE network_id = ? AND channel_id = ? AND filtered = 0 AND id <= ?
 ORDER BY id DESC
 LIMIT ?;
 
 -- name: GetMessagesAfterWithChannel :many
 SELECT * FROM messages
-WHERE network_id = ? AND channel_id = ? AND id > ?
+WHERE network_id = ? AND channel_id = ? AND filtered = 0 AND id > ?
 ORDER BY id ASC
 LIMIT ?;
 
@@ -53,7 +53,7 @@ LIMIT ?;
 
 -- name: GetMessagesBeforeTimeWithChannel :many
 SELECT * FROM messages
-WHERE network_id = ? AND channel_id = ? AND timestamp < ?
+WHERE network_id = ? AND channel_id = ? AND filtered = 0 AND timestamp < ?
 ORDER BY timestamp DESC, id DESC
 LIMIT ?;
 
@@ -70,3 +70,26 @@ WHERE network_id = ? AND channel_id IS NULL
   AND LOWER(pm_target) = ? AND timestamp < ?
 ORDER BY timestamp DESC, id DESC
 LIMIT ?;
+
+-- Timestamp-keyed "after" pagination, the newer-direction counterpart (used to
+-- answer a bouncer client's CHATHISTORY AFTER/BETWEEN).
+
+-- name: GetMessagesAfterTimeWithChannel :many
+SELECT * FROM messages
+WHERE network_id = ? AND channel_id = ? AND filtered = 0 AND timestamp > ?
+ORDER BY timestamp ASC, id ASC
+LIMIT ?;
+
+-- name: GetMessagesAfterTimeWithoutChannel :many
+SELECT * FROM messages
+WHERE network_id = ? AND channel_id IS NULL AND pm_target IS NULL AND timestamp > ?
+ORDER BY timestamp ASC, id ASC
+LIMIT ?;
+
+-- name: GetMessagesAfterTimePM :many
+SELECT * FROM messages
+WHERE network_id = ? AND channel_id IS NULL
+  AND message_type IN ('privmsg', 'action', 'notice', 'marker')
+  AND LOWER(pm_target) = ? AND timestamp > ?
+ORDER BY timestamp ASC, id ASC
+LIMIT ?;
@@ -0,0 +1,23 @@
+-- name: CreateScheduledMessage :one
+INSERT INTO scheduled_messages (network_id, target, message, send_at, created_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListScheduledMessages :many
+SELECT * FROM scheduled_messages
+WHERE network_id = ?
+ORDER BY send_at, id;
+
+-- name: ListDueScheduledMessages :many
+SELECT * FROM scheduled_messages
+WHERE status = 'pending' AND send_at <= ?
+ORDER BY send_at, id;
+
+-- name: RecordScheduledMessageAttempt :exec
+UPDATE scheduled_messages SET attempts = attempts + 1, last_error = ? WHERE id = ?;
+
+-- name: FailScheduledMessage :exec
+UPDATE scheduled_messages SET status = 'failed', last_error = ? WHERE id = ?;
+
+-- name: DeleteScheduledMessage :execrows
+DELETE FROM scheduled_messages WHERE id = ? AND network_id = ?;
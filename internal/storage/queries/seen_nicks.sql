@@ -0,0 +1,23 @@
+-- name: UpsertSeenNick :exec
+INSERT INTO seen_nicks (network_id, nick_key, nick, action, channel, target, message, host, account, seen_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(network_id, nick_key) DO UPDATE SET
+    nick = excluded.nick,
+    action = excluded.action,
+    channel = excluded.channel,
+    target = excluded.target,
+    message = excluded.message,
+    host = CASE WHEN excluded.host = '' THEN seen_nicks.host ELSE excluded.host END,
+    account = CASE WHEN excluded.account = '' THEN seen_nicks.account ELSE excluded.account END,
+    seen_at = excluded.seen_at
+WHERE excluded.seen_at >= seen_nicks.seen_at;
+
+-- name: GetSeenNick :one
+SELECT * FROM seen_nicks WHERE network_id = ? AND nick_key = ?;
+
+-- name: ListSeenNicksByIdentity :many
+SELECT * FROM seen_nicks
+WHERE network_id = sqlc.arg(network_id) AND nick_key != sqlc.arg(nick_key)
+    AND ((sqlc.arg(account) != '' AND account = sqlc.arg(account)) OR (sqlc.arg(host) != '' AND host = sqlc.arg(host)))
+ORDER BY seen_at DESC
+LIMIT sqlc.arg(row_limit);
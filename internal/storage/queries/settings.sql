@@ -5,3 +5,6 @@ SELECT value FROM settings WHERE key = ?;
 INSERT INTO settings (key, value, updated_at)
 VALUES (?, ?, CURRENT_TIMESTAMP)
 ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP;
+
+-- name: ListSettings :many
+SELECT key, value FROM settings ORDER BY key;
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/matt0x6f/irc-client/internal/storage/generated"
+)
+
+// CreateScheduledMessage queues message for target on the network, to be sent
+// at sendAt, and returns the stored row.
+func (s *Storage) CreateScheduledMessage(networkID int64, target, message string, sendAt time.Time) (ScheduledMessage, error) {
+	if strings.TrimSpace(target) == "" || strings.TrimSpace(message) == "" {
+		return ScheduledMessage{}, fmt.Errorf("scheduled message requires a target and a message")
+	}
+	row, err := s.queries.CreateScheduledMessage(context.Background(), db.CreateScheduledMessageParams{
+		NetworkID: networkID,
+		Target:    target,
+		Message:   message,
+		SendAt:    sendAt.UnixMilli(),
+		CreatedAt: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return ScheduledMessage{}, fmt.Errorf("schedule message to %s: %w", target, err)
+	}
+	return ScheduledMessage(row), nil
+}
+
+// ListScheduledMessages returns the network's pending and failed messages,
+// soonest first.
+func (s *Storage) ListScheduledMessages(networkID int64) ([]ScheduledMessage, error) {
+	rows, err := s.queries.ListScheduledMessages(context.Background(), networkID)
+	if err != nil {
+		return nil, fmt.Errorf("list scheduled messages: %w", err)
+	}
+	return convertScheduledMessagesFromDB(rows), nil
+}
+
+// ListDueScheduledMessages returns every pending message whose time is at or
+// before now, across all networks.
+func (s *Storage) ListDueScheduledMessages(now time.Time) ([]ScheduledMessage, error) {
+	rows, err := s.queries.ListDueScheduledMessages(context.Background(), now.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("list due scheduled messages: %w", err)
+	}
+	return convertScheduledMessagesFromDB(rows), nil
+}
+
+// RecordScheduledMessageAttempt counts a failed attempt to send a message that
+// stays pending.
+func (s *Storage) RecordScheduledMessageAttempt(id int64, reason string) error {
+	if err := s.queries.RecordScheduledMessageAttempt(context.Background(), db.RecordScheduledMessageAttemptParams{
+		LastError: reason, ID: id,
+	}); err != nil {
+		return fmt.Errorf("record scheduled message %d attempt: %w", id, err)
+	}
+	return nil
+}
+
+// FailScheduledMessage gives up on a message. It is kept, marked failed, until
+// the user removes it.
+func (s *Storage) FailScheduledMessage(id int64, reason string) error {
+	if err := s.queries.FailScheduledMessage(context.Background(), db.FailScheduledMessageParams{
+		LastError: reason, ID: id,
+	}); err != nil {
+		return fmt.Errorf("fail scheduled message %d: %w", id, err)
+	}
+	return nil
+}
+
+// DeleteScheduledMessage removes a message from the network's queue, whether
+// it was sent, cancelled or failed. It reports whether there was one.
+func (s *Storage) DeleteScheduledMessage(networkID, id int64) (bool, error) {
+	n, err := s.queries.DeleteScheduledMessage(context.Background(), db.DeleteScheduledMessageParams{
+		ID: id, NetworkID: networkID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("delete scheduled message %d: %w", id, err)
+	}
+	return n > 0, nil
+}
+
+func convertScheduledMessagesFromDB(rows []db.ScheduledMessage) []ScheduledMessage {
+	out := make([]ScheduledMessage, len(rows))
+	for i, row := range rows {
+		out[i] = ScheduledMessage(row)
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+// TestScheduledMessageQueue: only pending messages that are due are returned
+// for sending, failed ones stay listed until removed, and a message can only
+// be removed through its own network.
+func TestScheduledMessageQueue(t *testing.T) {
+	s := newTestStorage(t)
+	a, b := makeNetwork("LaterA"), makeNetwork("LaterB")
+	for _, net := range []*Network{a, b} {
+		if err := s.CreateNetwork(net); err != nil {
+			t.Fatalf("CreateNetwork: %v", err)
+		}
+	}
+
+	now := time.Now()
+	soon, err := s.CreateScheduledMessage(a.ID, "#release", "v2.0 is out", now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("CreateScheduledMessage: %v", err)
+	}
+	if soon.Status != ScheduledPending || soon.SendAt != now.Add(-time.Minute).UnixMilli() {
+		t.Fatalf("created %+v", soon)
+	}
+	later, _ := s.CreateScheduledMessage(a.ID, "bob", "standup in 5", now.Add(time.Hour))
+	if _, err := s.CreateScheduledMessage(a.ID, "#release", " ", now); err == nil {
+		t.Error("an empty message was scheduled")
+	}
+
+	due, err := s.ListDueScheduledMessages(now)
+	if err != nil {
+		t.Fatalf("ListDueScheduledMessages: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != soon.ID {
+		t.Fatalf("due = %+v", due)
+	}
+
+	if err := s.RecordScheduledMessageAttempt(soon.ID, "network not connected"); err != nil {
+		t.Fatalf("RecordScheduledMessageAttempt: %v", err)
+	}
+	if err := s.FailScheduledMessage(soon.ID, "network not connected"); err != nil {
+		t.Fatalf("FailScheduledMessage: %v", err)
+	}
+	if due, _ := s.ListDueScheduledMessages(now.Add(2 * time.Hour)); len(due) != 1 || due[0].ID != later.ID {
+		t.Fatalf("due after failing the first = %+v", due)
+	}
+	listed, err := s.ListScheduledMessages(a.ID)
+	if err != nil {
+		t.Fatalf("ListScheduledMessages: %v", err)
+	}
+	if len(listed) != 2 || listed[0].Status != ScheduledFailed || listed[0].Attempts != 1 || listed[0].LastError != "network not connected" {
+		t.Fatalf("listed = %+v", listed)
+	}
+
+	if removed, _ := s.DeleteScheduledMessage(b.ID, later.ID); removed {
+		t.Error("another network removed the message")
+	}
+	if removed, err := s.DeleteScheduledMessage(a.ID, later.ID); err != nil || !removed {
+		t.Fatalf("DeleteScheduledMessage = %v, %v", removed, err)
+	}
+	if removed, _ := s.DeleteScheduledMessage(a.ID, later.ID); removed {
+		t.Error("a message was removed twice")
+	}
+}
@@ -21,6 +21,7 @@ CREATE TABLE IF NOT EXISTS networks (
     color TEXT,
     icon_path TEXT,
     sort_order INTEGER NOT NULL DEFAULT 0,
+    flood_profile TEXT NOT NULL DEFAULT '',
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
@@ -47,6 +48,7 @@ CREATE TABLE IF NOT EXISTS channels (
     is_open BOOLEAN NOT NULL DEFAULT 0,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP,
+    smart_filter_minutes INTEGER NOT NULL DEFAULT 0, -- hide join/part/quit from nicks silent this long; 0 = off
     FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
     UNIQUE(network_id, name)
 );
@@ -75,6 +77,10 @@ CREATE TABLE IF NOT EXISTS messages (
     msgid TEXT, -- IRCv3 message id (NULL for legacy/local rows); used to dedup CHATHISTORY replays
     reply_msgid TEXT, -- IRCv3 +draft/reply: msgid of the parent message (NULL if not a reply)
     channel_context TEXT, -- IRCv3 +draft/channel-context: channel a private message is about (NULL otherwise)
+    plaintext TEXT, -- message with IRC formatting codes stripped; what messages_fts indexes (NULL only on rows awaiting backfill)
+    account TEXT, -- services account the sender was logged in as, from account-tag / extended-join / the live roster (NULL if unknown)
+    filtered BOOLEAN NOT NULL DEFAULT 0, -- join/part/quit hidden by the channel's smart filter (sender hadn't spoken recently); kept for search and export
+    encrypted BOOLEAN NOT NULL DEFAULT 0, -- sent or received encrypted (E2E session or FiSH key); message holds the decrypted text
     FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
     FOREIGN KEY (channel_id) REFERENCES channels(id) ON DELETE CASCADE
 );
@@ -214,9 +220,140 @@ CREATE TABLE IF NOT EXISTS file_transfers (
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     finished_at TIMESTAMP,
+    sha256 TEXT NOT NULL DEFAULT '', -- hex digest of the completed file; empty until verified
+    queue_position INTEGER NOT NULL DEFAULT 0, -- 1-based place in the outgoing send queue; 0 when not queued
     FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE SET NULL
 );
 
+-- Channel list-mode entries (+b bans, +e exceptions, +I invite exceptions),
+-- refreshed from 367/348/346 replies and kept in step with MODE echoes.
+-- channel_key is the CASEMAPPING-folded channel name. expires_at is a
+-- client-side expiry: the App removes the entry from the channel once it passes.
+CREATE TABLE IF NOT EXISTS channel_list_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER NOT NULL,
+    channel TEXT NOT NULL,
+    channel_key TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    mask TEXT NOT NULL,
+    set_by TEXT NOT NULL DEFAULT '',
+    set_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
+    UNIQUE(network_id, channel_key, mode, mask)
+);
+
+CREATE TABLE IF NOT EXISTS command_aliases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER,
+    name TEXT NOT NULL,
+    expansion TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS perform_steps (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    seconds INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
+    UNIQUE(network_id, position)
+);
+
+-- Hourly per-nick activity aggregates for channel statistics, built
+-- incrementally from messages by a background job. hour is Unix seconds / 3600
+-- (UTC); first_seen and last_seen are Unix seconds.
+CREATE TABLE IF NOT EXISTS channel_stats_hourly (
+    network_id INTEGER NOT NULL,
+    channel_id INTEGER NOT NULL,
+    nick_key TEXT NOT NULL,
+    nick TEXT NOT NULL,
+    hour INTEGER NOT NULL,
+    lines INTEGER NOT NULL DEFAULT 0,
+    words INTEGER NOT NULL DEFAULT 0,
+    urls INTEGER NOT NULL DEFAULT 0,
+    joins INTEGER NOT NULL DEFAULT 0,
+    parts INTEGER NOT NULL DEFAULT 0,
+    first_seen INTEGER NOT NULL,
+    last_seen INTEGER NOT NULL,
+    PRIMARY KEY (channel_id, nick_key, hour),
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
+    FOREIGN KEY (channel_id) REFERENCES channels(id) ON DELETE CASCADE
+);
+
+-- The last message id folded into channel_stats_hourly.
+CREATE TABLE IF NOT EXISTS channel_stats_progress (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    last_message_id INTEGER NOT NULL
+);
+
+-- Channels seen in each network's LIST replies, kept across restarts and
+-- merged fetch by fetch. first_seen and last_seen are the times (Unix
+-- milliseconds) of the fetches that first and last listed the channel;
+-- channel_key is lower-cased.
+CREATE TABLE IF NOT EXISTS channel_directory (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER NOT NULL,
+    channel_key TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    users INTEGER NOT NULL DEFAULT 0,
+    topic TEXT NOT NULL DEFAULT '',
+    modes TEXT NOT NULL DEFAULT '',
+    first_seen INTEGER NOT NULL,
+    last_seen INTEGER NOT NULL,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
+    UNIQUE(network_id, channel_key)
+);
+
+-- When each network's directory was last fetched, and with what LIST filter.
+CREATE TABLE IF NOT EXISTS channel_directory_fetches (
+    network_id INTEGER PRIMARY KEY,
+    fetched_at INTEGER NOT NULL,
+    previous_fetched_at INTEGER NOT NULL DEFAULT 0,
+    full_fetched_at INTEGER NOT NULL DEFAULT 0,
+    filter TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+
+-- The last thing each nick was seen doing on a network, kept across sessions.
+-- nick_key is the nick folded with the network's CASEMAPPING; seen_at is Unix
+-- milliseconds. target is the other nick of a nick change or kick, or the
+-- account of an account change; message is a reason or message text.
+CREATE TABLE IF NOT EXISTS seen_nicks (
+    network_id INTEGER NOT NULL,
+    nick_key TEXT NOT NULL,
+    nick TEXT NOT NULL,
+    action TEXT NOT NULL,
+    channel TEXT NOT NULL DEFAULT '',
+    target TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    host TEXT NOT NULL DEFAULT '',
+    account TEXT NOT NULL DEFAULT '',
+    seen_at INTEGER NOT NULL,
+    PRIMARY KEY (network_id, nick_key),
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+
+-- Messages queued to be sent later (/later). Times are Unix milliseconds. A
+-- row is deleted once sent; status is 'pending' until then, or 'failed' with
+-- last_error when it could not be delivered.
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER NOT NULL,
+    target TEXT NOT NULL,
+    message TEXT NOT NULL,
+    send_at INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+
 CREATE INDEX IF NOT EXISTS idx_messages_network_channel_time ON messages(network_id, channel_id, timestamp);
 CREATE INDEX IF NOT EXISTS idx_messages_timestamp ON messages(timestamp);
 -- Per-conversation dedup: a broadcast event (one QUIT/one msgid) fans out to one
@@ -226,16 +363,28 @@ CREATE INDEX IF NOT EXISTS idx_messages_timestamp ON messages(timestamp);
 CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_conv_msgid
   ON messages(network_id, COALESCE(channel_id, 0), COALESCE(pm_target, ''), msgid)
   WHERE msgid IS NOT NULL;
+-- Account-keyed history: "all messages from account X" and "nicks used by X".
+CREATE INDEX IF NOT EXISTS idx_messages_network_account
+  ON messages(network_id, account COLLATE NOCASE, timestamp)
+  WHERE account IS NOT NULL;
 CREATE INDEX IF NOT EXISTS idx_servers_network_order ON servers(network_id, "order");
 CREATE INDEX IF NOT EXISTS idx_pinned_network_channel ON pinned_messages(network_id, channel_id);
 CREATE INDEX IF NOT EXISTS idx_activity_items_seen_time ON activity_items(seen, timestamp);
 CREATE INDEX IF NOT EXISTS idx_activity_items_network ON activity_items(network_id);
 CREATE INDEX IF NOT EXISTS idx_file_transfers_active ON file_transfers(finished_at, created_at);
 CREATE INDEX IF NOT EXISTS idx_file_transfers_history ON file_transfers(finished_at DESC, transfer_id DESC);
+CREATE INDEX IF NOT EXISTS idx_channel_list_entries_expiry ON channel_list_entries(expires_at) WHERE expires_at IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_command_aliases_scope_name ON command_aliases(COALESCE(network_id, 0), name);
+CREATE INDEX IF NOT EXISTS idx_channel_stats_hourly_window ON channel_stats_hourly(channel_id, hour);
+CREATE INDEX IF NOT EXISTS idx_seen_nicks_account ON seen_nicks(network_id, account) WHERE account != '';
+CREATE INDEX IF NOT EXISTS idx_seen_nicks_host ON seen_nicks(network_id, host) WHERE host != '';
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_due ON scheduled_messages(send_at) WHERE status = 'pending';
 
--- FTS5 full-text search index for messages
+-- FTS5 full-text search index for messages. It indexes the formatting-stripped
+-- plaintext column rather than the raw message, so mIRC colour/bold codes never
+-- split words. COALESCE covers legacy rows whose plaintext is not yet backfilled.
 CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
-    message,
+    plaintext,
     user,
     content='messages',
     content_rowid='id'
@@ -243,14 +392,35 @@ CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
 
 -- Triggers to keep FTS5 index in sync with messages table
 CREATE TRIGGER IF NOT EXISTS messages_ai AFTER INSERT ON messages BEGIN
-    INSERT INTO messages_fts(rowid, message, user) VALUES (new.id, new.message, new.user);
+    INSERT INTO messages_fts(rowid, plaintext, user) VALUES (new.id, COALESCE(new.plaintext, new.message), new.user);
 END;
 
 CREATE TRIGGER IF NOT EXISTS messages_ad AFTER DELETE ON messages BEGIN
-    INSERT INTO messages_fts(messages_fts, rowid, message, user) VALUES('delete', old.id, old.message, old.user);
+    INSERT INTO messages_fts(messages_fts, rowid, plaintext, user) VALUES('delete', old.id, COALESCE(old.plaintext, old.message), old.user);
 END;
 
 CREATE TRIGGER IF NOT EXISTS messages_au AFTER UPDATE ON messages BEGIN
-    INSERT INTO messages_fts(messages_fts, rowid, message, user) VALUES('delete', old.id, old.message, old.user);
-    INSERT INTO messages_fts(rowid, message, user) VALUES (new.id, new.message, new.user);
+    INSERT INTO messages_fts(messages_fts, rowid, plaintext, user) VALUES('delete', old.id, COALESCE(old.plaintext, old.message), old.user);
+    INSERT INTO messages_fts(rowid, plaintext, user) VALUES (new.id, COALESCE(new.plaintext, new.message), new.user);
+END;
+
+-- FTS5 index over channel directory names and topics.
+CREATE VIRTUAL TABLE IF NOT EXISTS channel_directory_fts USING fts5(
+    channel,
+    topic,
+    content='channel_directory',
+    content_rowid='id'
+);
+
+CREATE TRIGGER IF NOT EXISTS channel_directory_ai AFTER INSERT ON channel_directory BEGIN
+    INSERT INTO channel_directory_fts(rowid, channel, topic) VALUES (new.id, new.channel, new.topic);
+END;
+
+CREATE TRIGGER IF NOT EXISTS channel_directory_ad AFTER DELETE ON channel_directory BEGIN
+    INSERT INTO channel_directory_fts(channel_directory_fts, rowid, channel, topic) VALUES('delete', old.id, old.channel, old.topic);
+END;
+
+CREATE TRIGGER IF NOT EXISTS channel_directory_au AFTER UPDATE OF channel, topic ON channel_directory BEGIN
+    INSERT INTO channel_directory_fts(channel_directory_fts, rowid, channel, topic) VALUES('delete', old.id, old.channel, old.topic);
+    INSERT INTO channel_directory_fts(rowid, channel, topic) VALUES (new.id, new.channel, new.topic);
 END;
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	db "github.com/matt0x6f/irc-client/internal/storage/generated"
+)
+
+// RecordSeen stores entry as the latest sighting of the nick whose folded form
+// is nickKey. An entry older than the stored one is ignored, so replayed
+// history never hides a newer sighting, and an empty Host or Account keeps the
+// last one known.
+func (s *Storage) RecordSeen(networkID int64, nickKey string, entry SeenEntry) error {
+	if nickKey == "" || entry.Nick == "" || entry.Action == "" {
+		return fmt.Errorf("seen entry requires a nick and an action")
+	}
+	if err := s.queries.UpsertSeenNick(context.Background(), db.UpsertSeenNickParams{
+		NetworkID: networkID,
+		NickKey:   nickKey,
+		Nick:      entry.Nick,
+		Action:    entry.Action,
+		Channel:   entry.Channel,
+		Target:    entry.Target,
+		Message:   entry.Message,
+		Host:      entry.Host,
+		Account:   entry.Account,
+		SeenAt:    entry.SeenAt,
+	}); err != nil {
+		return fmt.Errorf("record seen %s: %w", entry.Nick, err)
+	}
+	return nil
+}
+
+// GetSeen returns the latest sighting of the nick whose folded form is
+// nickKey, or nil if it has never been seen on the network.
+func (s *Storage) GetSeen(networkID int64, nickKey string) (*SeenEntry, error) {
+	row, err := s.queries.GetSeenNick(context.Background(), db.GetSeenNickParams{NetworkID: networkID, NickKey: nickKey})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get seen %s: %w", nickKey, err)
+	}
+	entry := convertSeenNickFromDB(row)
+	return &entry, nil
+}
+
+// SeenWithIdentity returns up to limit other nicks last seen with the given
+// account or user@host, most recent first. Empty values match nothing.
+func (s *Storage) SeenWithIdentity(networkID int64, nickKey, account, host string, limit int) ([]SeenEntry, error) {
+	if account == "" && host == "" {
+		return []SeenEntry{}, nil
+	}
+	rows, err := s.queries.ListSeenNicksByIdentity(context.Background(), db.ListSeenNicksByIdentityParams{
+		NetworkID: networkID,
+		NickKey:   nickKey,
+		Account:   account,
+		Host:      host,
+		RowLimit:  int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list nicks seen as %s: %w", nickKey, err)
+	}
+	entries := make([]SeenEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = convertSeenNickFromDB(row)
+	}
+	return entries, nil
+}
+
+func convertSeenNickFromDB(row db.SeenNick) SeenEntry {
+	return SeenEntry{
+		NetworkID: row.NetworkID,
+		Nick:      row.Nick,
+		Action:    row.Action,
+		Channel:   row.Channel,
+		Target:    row.Target,
+		Message:   row.Message,
+		Host:      row.Host,
+		Account:   row.Account,
+		SeenAt:    row.SeenAt,
+	}
+}
@@ -0,0 +1,49 @@
+package storage
+
+import "testing"
+
+func TestSeenKeepsTheLatestSighting(t *testing.T) {
+	s := newTestStorage(t)
+	net := makeNetwork("SeenNet")
+	if err := s.CreateNetwork(net); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	if got, err := s.GetSeen(net.ID, "alice"); err != nil || got != nil {
+		t.Fatalf("GetSeen before any sighting = %+v, %v; want nil", got, err)
+	}
+
+	record := func(key string, e SeenEntry) {
+		t.Helper()
+		if err := s.RecordSeen(net.ID, key, e); err != nil {
+			t.Fatalf("RecordSeen(%s): %v", key, err)
+		}
+	}
+	record("alice", SeenEntry{Nick: "Alice", Action: "join", Channel: "#go", Host: "a@home", Account: "alice", SeenAt: 2000})
+	// Replayed history older than the stored sighting is ignored.
+	record("alice", SeenEntry{Nick: "Alice", Action: "message", Channel: "#old", SeenAt: 1000})
+	// A newer sighting without host or account keeps the ones already known.
+	record("alice", SeenEntry{Nick: "ALICE", Action: "part", Channel: "#go", Message: "bye", SeenAt: 3000})
+
+	got, err := s.GetSeen(net.ID, "alice")
+	if err != nil || got == nil {
+		t.Fatalf("GetSeen = %+v, %v", got, err)
+	}
+	want := SeenEntry{NetworkID: net.ID, Nick: "ALICE", Action: "part", Channel: "#go", Message: "bye", Host: "a@home", Account: "alice", SeenAt: 3000}
+	if *got != want {
+		t.Fatalf("GetSeen = %+v, want %+v", *got, want)
+	}
+
+	record("alice_", SeenEntry{Nick: "alice_", Action: "quit", Account: "alice", SeenAt: 4000})
+	record("al", SeenEntry{Nick: "al", Action: "join", Host: "a@home", SeenAt: 5000})
+	record("bob", SeenEntry{Nick: "bob", Action: "join", Host: "b@work", Account: "bob", SeenAt: 6000})
+	others, err := s.SeenWithIdentity(net.ID, "alice", "alice", "a@home", 10)
+	if err != nil {
+		t.Fatalf("SeenWithIdentity: %v", err)
+	}
+	if len(others) != 2 || others[0].Nick != "al" || others[1].Nick != "alice_" {
+		t.Fatalf("SeenWithIdentity = %+v, want al then alice_", others)
+	}
+	if none, _ := s.SeenWithIdentity(net.ID, "alice", "", "", 10); len(none) != 0 {
+		t.Fatalf("SeenWithIdentity with no identity = %+v, want none", none)
+	}
+}
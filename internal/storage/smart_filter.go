@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/matt0x6f/irc-client/internal/storage/generated"
+)
+
+// UpdateChannelSmartFilter sets how many minutes a nick must have been silent
+// for its join/part/quit rows in the channel to be filtered. Turning the filter
+// off (0) also un-hides the rows it had filtered.
+func (s *Storage) UpdateChannelSmartFilter(channelID int64, minutes int) error {
+	if minutes < 0 {
+		return fmt.Errorf("smart filter window must not be negative")
+	}
+	ctx := context.Background()
+	if err := s.queries.UpdateChannelSmartFilter(ctx, db.UpdateChannelSmartFilterParams{
+		SmartFilterMinutes: int64(minutes),
+		ID:                 channelID,
+	}); err != nil {
+		return fmt.Errorf("update smart filter: %w", err)
+	}
+	if minutes == 0 {
+		if err := s.queries.ClearChannelFiltered(ctx, sql.NullInt64{Int64: channelID, Valid: true}); err != nil {
+			return fmt.Errorf("clear filtered rows: %w", err)
+		}
+	}
+	return nil
+}
+
+// UnfilterLatestJoin un-hides nick's most recent filtered join row in the
+// channel stored at or after since, returning its id. ok is false when there
+// is no such row.
+func (s *Storage) UnfilterLatestJoin(channelID int64, nick string, since time.Time) (id int64, ok bool, err error) {
+	id, err = s.queries.UnfilterLatestJoin(context.Background(), db.UnfilterLatestJoinParams{
+		ChannelID: sql.NullInt64{Int64: channelID, Valid: true},
+		User:      nick,
+		Timestamp: since.UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("unfilter join: %w", err)
+	}
+	return id, true, nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSmartFilteredRowsAreHiddenFromChannelHistory(t *testing.T) {
+	s := newTestStorage(t)
+	net := makeNetwork("FilterNet")
+	if err := s.CreateNetwork(net); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	ch := &Channel{NetworkID: net.ID, Name: "#busy", CreatedAt: time.Now()}
+	if err := s.CreateChannel(ch); err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	if err := s.UpdateChannelSmartFilter(ch.ID, 5); err != nil {
+		t.Fatalf("UpdateChannelSmartFilter: %v", err)
+	}
+	got, err := s.GetChannelByName(net.ID, "#busy")
+	if err != nil || got.SmartFilterMinutes != 5 {
+		t.Fatalf("GetChannelByName = %+v, %v; want a 5 minute window", got, err)
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	write := func(i int, user, kind, text string, filtered bool) {
+		t.Helper()
+		if err := s.WriteMessageSync(Message{
+			NetworkID:   net.ID,
+			ChannelID:   &ch.ID,
+			User:        user,
+			Message:     text,
+			MessageType: kind,
+			Timestamp:   base.Add(time.Duration(i) * time.Minute),
+			Filtered:    filtered,
+		}); err != nil {
+			t.Fatalf("WriteMessageSync: %v", err)
+		}
+	}
+	write(0, "lurker", "join", "lurker joined the channel", true)
+	write(1, "alice", "privmsg", "hi", false)
+	write(2, "ghost", "quit", "ghost quit", true)
+
+	texts := func() []string {
+		t.Helper()
+		msgs, err := s.GetMessages(net.ID, &ch.ID, 10)
+		if err != nil {
+			t.Fatalf("GetMessages: %v", err)
+		}
+		var out []string
+		for _, m := range msgs {
+			out = append(out, m.Message)
+		}
+		return out
+	}
+	if got := texts(); len(got) != 1 || got[0] != "hi" {
+		t.Fatalf("GetMessages = %q, want only the unfiltered line", got)
+	}
+
+	if _, ok, err := s.UnfilterLatestJoin(ch.ID, "LURKER", base.Add(time.Minute)); err != nil || ok {
+		t.Fatalf("UnfilterLatestJoin outside the window = %v, %v; want no row", ok, err)
+	}
+	if _, ok, err := s.UnfilterLatestJoin(ch.ID, "LURKER", base); err != nil || !ok {
+		t.Fatalf("UnfilterLatestJoin = %v, %v; want the join un-hidden", ok, err)
+	}
+	if got := texts(); len(got) != 2 || got[0] != "lurker joined the channel" {
+		t.Fatalf("GetMessages after unfilter = %q, want the join back", got)
+	}
+
+	if err := s.UpdateChannelSmartFilter(ch.ID, 0); err != nil {
+		t.Fatalf("UpdateChannelSmartFilter(0): %v", err)
+	}
+	if got := texts(); len(got) != 3 {
+		t.Fatalf("GetMessages with the filter off = %q, want every row", got)
+	}
+}
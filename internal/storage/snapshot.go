@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Snapshot writes a consistent copy of the database to path while the app
+// keeps using it. Buffered messages are flushed first so the copy has them.
+// path must not exist yet.
+func (s *Storage) Snapshot(path string) error {
+	s.flushBuffer(false)
+	if _, err := s.db.Exec("VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
+}
+
+// CheckSnapshot opens the database file at path read-only, verifies it is
+// intact and returns the schema version it was written with.
+func CheckSnapshot(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, err
+	}
+	conn, err := sqlx.Connect("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer conn.Close()
+
+	var result string
+	if err := conn.Get(&result, "PRAGMA quick_check"); err != nil {
+		return 0, fmt.Errorf("failed to check snapshot: %w", err)
+	}
+	if result != "ok" {
+		return 0, fmt.Errorf("snapshot is damaged: %s", result)
+	}
+	var version int
+	if err := conn.Get(&version, "PRAGMA user_version"); err != nil {
+		return 0, fmt.Errorf("failed to read snapshot schema version: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotIsAReadableCopyWithSchemaVersion(t *testing.T) {
+	s := newTestStorage(t)
+	if err := s.SetSetting("theme", "dark"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := s.Snapshot(path); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	version, err := CheckSnapshot(path)
+	if err != nil {
+		t.Fatalf("CheckSnapshot: %v", err)
+	}
+	if version != SchemaVersion {
+		t.Errorf("schema version = %d, want %d", version, SchemaVersion)
+	}
+
+	restored, err := NewStorage(path, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+	if v, _ := restored.GetSetting("theme"); v != "dark" {
+		t.Errorf("snapshot setting = %q, want dark", v)
+	}
+}
+
+func TestMigrateNeverLowersSchemaVersion(t *testing.T) {
+	s := newTestStorage(t)
+	if _, err := s.db.Exec("PRAGMA user_version = 9999"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(s.db); err != nil {
+		t.Fatal(err)
+	}
+	var version int
+	if err := s.db.Get(&version, "PRAGMA user_version"); err != nil {
+		t.Fatal(err)
+	}
+	if version != 9999 {
+		t.Errorf("user_version = %d after Migrate, want 9999", version)
+	}
+}
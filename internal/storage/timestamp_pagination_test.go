@@ -158,3 +158,36 @@ func TestMigrateNormalizesLegacyMixedTimezoneTimestamps(t *testing.T) {
 		t.Fatalf("PST row not converted to 2026-01-11 02:00 UTC (matches=%d)", pst)
 	}
 }
+
+func TestGetMessagesAfterTimeIsExclusiveAndAscending(t *testing.T) {
+	s := newTestStorage(t)
+	networkID, channelID := testChannel(t, s)
+	base := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		for _, pm := range []string{"", "Alice"} {
+			msg := Message{NetworkID: networkID, User: "u", Message: "m", MessageType: "privmsg", Timestamp: base.Add(time.Duration(i) * time.Minute), PMTarget: pm}
+			if pm == "" {
+				msg.ChannelID = &channelID
+			}
+			if err := s.WriteMessageSync(msg); err != nil {
+				t.Fatalf("WriteMessageSync: %v", err)
+			}
+		}
+	}
+
+	got, err := s.GetMessagesAfterTime(networkID, &channelID, "", base.Add(time.Minute), 2)
+	if err != nil {
+		t.Fatalf("GetMessagesAfterTime: %v", err)
+	}
+	if len(got) != 2 || !got[0].Timestamp.Equal(base.Add(2*time.Minute)) || !got[1].Timestamp.Equal(base.Add(3*time.Minute)) {
+		t.Fatalf("channel page = %+v, want minutes 2 and 3", got)
+	}
+
+	got, err = s.GetMessagesAfterTime(networkID, nil, "alice", base.Add(3*time.Minute), 10)
+	if err != nil {
+		t.Fatalf("GetMessagesAfterTime PM: %v", err)
+	}
+	if len(got) != 1 || got[0].PMTarget == "" || !got[0].Timestamp.Equal(base.Add(4*time.Minute)) {
+		t.Fatalf("PM page = %+v, want only minute 4", got)
+	}
+}
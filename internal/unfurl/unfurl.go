@@ -178,20 +178,41 @@ func fetchBounded(ctx context.Context, c *http.Client, rawURL, wantType string,
 	return body, resp.Request.URL.String(), nil
 }
 
+// FetchImage downloads the image at rawURL through the same SSRF guard, size
+// cap and payload sniffing as link previews, for callers that process the
+// bytes themselves (user avatars).
+func FetchImage(ctx context.Context, rawURL string) ([]byte, error) {
+	return fetchImageWith(ctx, rawURL, defaultIPGuard)
+}
+
+func fetchImageWith(ctx context.Context, rawURL string, guard ipGuard) ([]byte, error) {
+	body, _, err := fetchImageBytes(ctx, newGuardedClient(fetchTimeout, maxRedirects, guard), rawURL)
+	return body, err
+}
+
 func fetchImage(ctx context.Context, c *http.Client, imgURL string) (string, error) {
+	body, mime, err := fetchImageBytes(ctx, c, imgURL)
+	if err != nil {
+		return "", err
+	}
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(body), nil
+}
+
+// fetchImageBytes GETs an image and returns it with its sniffed mime type.
+func fetchImageBytes(ctx context.Context, c *http.Client, imgURL string) ([]byte, string, error) {
 	// Explicit http(s) scheme allowlist: reject file://, ftp://, data:, and other dangerous schemes.
 	u, err := url.Parse(imgURL)
 	if err != nil {
-		return "", fmt.Errorf("unfurl: bad image url: %w", err)
+		return nil, "", fmt.Errorf("unfurl: bad image url: %w", err)
 	}
 	if u.Scheme != "http" && u.Scheme != "https" {
-		return "", fmt.Errorf("%w: image scheme %q", ErrBlocked, u.Scheme)
+		return nil, "", fmt.Errorf("%w: image scheme %q", ErrBlocked, u.Scheme)
 	}
 
 	// fetchBounded enforces status==200 and server Content-Type prefix "image/".
 	body, _, err := fetchBounded(ctx, c, imgURL, "image/", maxImgBytes)
 	if err != nil {
-		return "", err
+		return nil, "", err
 	}
 
 	// DetectContentType is the sole authority for the data-URI mime type.
@@ -199,9 +220,9 @@ func fetchImage(ctx context.Context, c *http.Client, imgURL string) (string, err
 	// server cannot inject an arbitrary mime type.
 	mime := http.DetectContentType(body)
 	if !strings.HasPrefix(mime, "image/") {
-		return "", fmt.Errorf("unfurl: payload is not an image (%s)", mime)
+		return nil, "", fmt.Errorf("unfurl: payload is not an image (%s)", mime)
 	}
-	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(body), nil
+	return body, mime, nil
 }
 
 func resolveURL(base, ref string) string {
@@ -156,3 +156,27 @@ func TestFetchImageRejectsNonHTTPScheme(t *testing.T) {
 		t.Errorf("expected empty ImageDataURI (blocked scheme), got %q", p.ImageDataURI)
 	}
 }
+
+func TestFetchImageWithReturnsSniffedBytes(t *testing.T) {
+	png := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		if r.URL.Path == "/fake.png" {
+			w.Write([]byte("<html>not an image</html>"))
+			return
+		}
+		w.Write(png)
+	}))
+	defer srv.Close()
+
+	body, err := fetchImageWith(context.Background(), srv.URL+"/avatar.png", permissiveGuard)
+	if err != nil || string(body) != string(png) {
+		t.Fatalf("fetchImageWith = %v, %v", body, err)
+	}
+	if _, err := fetchImageWith(context.Background(), srv.URL+"/fake.png", permissiveGuard); err == nil {
+		t.Error("a non-image payload was accepted")
+	}
+	if _, err := FetchImage(context.Background(), srv.URL+"/avatar.png"); err == nil {
+		t.Error("the production guard allowed a loopback address")
+	}
+}
@@ -37,6 +37,16 @@ func main() {
 		return
 	}
 
+	// Core mode (core_server.go / core_desktop.go): the headless build may serve
+	// an authenticated listener for remote GUIs, and the windowed build may
+	// attach its window to such a core instead of running networks itself.
+	windowURL, backendGuard, stopCore, err := setupCore(ircApp)
+	if err != nil {
+		println("Error starting core:", err.Error())
+		return
+	}
+	defer stopCore()
+
 	// Native notifications are gated behind a build tag (notify.go /
 	// notify_server.go): the headless `server` build returns nil and skips both
 	// registering the service and wiring it. macOS notifications require a valid
@@ -58,7 +68,8 @@ func main() {
 		Description: "Modern multi-platform IRC client",
 		Services:    services,
 		Assets: application.AssetOptions{
-			Handler: application.BundledAssetFileServer(assets),
+			Handler:    application.BundledAssetFileServer(assets),
+			Middleware: backendGuard,
 		},
 		SingleInstance: &application.SingleInstanceOptions{
 			UniqueID: "com.mattouille.cascade",
@@ -117,6 +128,7 @@ func main() {
 		MinWidth:         940,
 		MinHeight:        600,
 		BackgroundColour: application.NewRGB(27, 38, 54),
+		URL:              windowURL,
 	})
 
 	// Wire native notifications now that the service and window exist. Routed
@@ -174,6 +186,7 @@ func buildMenu(app *application.App, ircApp *App) *application.Menu {
 	// File contains document/window lifecycle commands. Settings belongs in the
 	// application menu on macOS and in File on Windows/Linux.
 	fileMenu := menu.AddSubmenu("File")
+	addCoreMenuItems(fileMenu, ircApp)
 	if runtime.GOOS == "darwin" {
 		fileMenu.AddRole(application.CloseWindow)
 	} else {
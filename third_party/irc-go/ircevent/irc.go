@@ -158,6 +158,9 @@ func (irc *Connection) readLoop() {
 
 			parsedMsg, err := ircmsg.ParseLine(msg)
 			if err == nil {
+				if irc.OnRead != nil {
+					irc.OnRead(parsedMsg)
+				}
 				irc.runCallbacks(parsedMsg)
 			} else {
 				irc.Log.Printf("invalid message from server: %v\n", err)
@@ -939,6 +942,18 @@ CAPLOOP:
 
 	irc.processAckedCaps(acknowledgedCaps)
 
+	if capsRequested && irc.BeforeCapEnd != nil {
+		irc.stateMutex.Lock()
+		advertised := make(map[string]string, len(irc.capsAdvertised))
+		for name, value := range irc.capsAdvertised {
+			advertised[name] = value
+		}
+		irc.stateMutex.Unlock()
+		if err := irc.BeforeCapEnd(advertised); err != nil {
+			return err
+		}
+	}
+
 	saslSucceeded := false
 	var saslError error
 
@@ -169,3 +169,80 @@ func TestHandshakeNoCapReqWhenNothingAdvertised(t *testing.T) {
 	default:
 	}
 }
+
+// TestHandshakeRunsBeforeCapEnd: the BeforeCapEnd hook sees the advertised
+// caps with their values, and what it sends reaches the server before CAP END.
+func TestHandshakeRunsBeforeCapEnd(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	order := make(chan string, 8)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		write := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "CAP LS"):
+				write(":mock CAP * LS :server-time draft/account-registration=before-connect,email-required")
+			case strings.HasPrefix(line, "CAP REQ"):
+				write(":mock CAP * ACK :server-time")
+			case strings.HasPrefix(line, "REGISTER"):
+				order <- "REGISTER"
+				write(":mock REGISTER SUCCESS tester :Account created")
+			case strings.HasPrefix(line, "CAP END"):
+				order <- "CAP END"
+				write(":mock 001 tester :Welcome")
+				write(":mock 376 tester :End of /MOTD command.")
+			case strings.HasPrefix(line, "QUIT"):
+				return
+			}
+		}
+	}()
+
+	var got string
+	irc := &Connection{
+		Server:      ln.Addr().String(),
+		Nick:        "tester",
+		User:        "tester",
+		RealName:    "Tester",
+		RequestCaps: []string{"server-time"},
+		Timeout:     5 * time.Second,
+		KeepAlive:   5 * time.Second,
+	}
+	irc.BeforeCapEnd = func(advertised map[string]string) error {
+		got = advertised["draft/account-registration"]
+		return irc.Send("REGISTER", "*", "me@example.org", "hunter2")
+	}
+	debugTest(irc)
+	if err := irc.Connect(); err != nil {
+		t.Fatalf("Connect() failed: %v", err)
+	}
+	defer irc.Quit()
+
+	if got != "before-connect,email-required" {
+		t.Errorf("advertised value = %q", got)
+	}
+	for _, want := range []string{"REGISTER", "CAP END"} {
+		select {
+		case line := <-order:
+			if line != want {
+				t.Fatalf("server saw %q, want %q next", line, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("server never saw %s", want)
+		}
+	}
+}
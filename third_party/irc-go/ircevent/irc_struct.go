@@ -80,6 +80,16 @@ type Connection struct {
 	AllowTruncation bool // if set, truncate lines exceeding MaxLineLen and send them
 	// set this to configure how the connection is made (e.g. via a proxy server):
 	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// OnRead, when set, is called from the read loop with every parsed inbound
+	// line (batch markers and batched lines included) before any callback runs.
+	// It must not block.
+	OnRead Callback
+	// BeforeCapEnd, when set, runs during registration once capability
+	// negotiation is done and before SASL and CAP END, with the capabilities
+	// the server advertised (name -> value). It may send commands and block for
+	// their replies, within Timeout; an error aborts the connection. It is how a
+	// client registers an account before connecting (draft/account-registration).
+	BeforeCapEnd func(advertised map[string]string) error
 
 	// networking and synchronization
 	stateMutex sync.Mutex     // innermost mutex: don't block while holding this